@@ -0,0 +1,85 @@
+package h5p
+
+import (
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/grokify/h5p-go/schemas"
+)
+
+var htmlTagPattern = regexp.MustCompile(`<(/?)([a-zA-Z0-9]*)[^>]*>`)
+
+// inlineTags are the HTML elements that do not separate words, such as
+// the emphasis in "<em>F</em>rance".
+var inlineTags = map[string]bool{
+	"a": true, "abbr": true, "b": true, "code": true, "em": true, "i": true, "mark": true,
+	"s": true, "small": true, "span": true, "strong": true, "sub": true, "sup": true, "u": true,
+}
+
+// plainText strips HTML tags and entities and collapses whitespace, for
+// comparing text fields by what learners see.
+func plainText(s string) string {
+	s = htmlTagPattern.ReplaceAllStringFunc(s, func(tag string) string {
+		if inlineTags[strings.ToLower(htmlTagPattern.FindStringSubmatch(tag)[2])] {
+			return ""
+		}
+		return " "
+	})
+	return strings.Join(strings.Fields(html.UnescapeString(s)), " ")
+}
+
+// checkAnswers applies RuleDuplicateAnswer and RuleConflictingAnswers to a
+// question.
+func (p ValidationProfile) checkAnswers(r *ValidationResult, path string, q *Question) {
+	if params, ok, err := q.multiChoiceParams(); ok && err == nil {
+		texts := make([]string, len(params.Answers))
+		correct := 0
+		for i, a := range params.Answers {
+			texts[i] = a.Text
+			if a.Correct {
+				correct++
+			}
+		}
+		p.checkDuplicates(r, schemas.JoinPath(path, "params.answers"), texts)
+		if params.Behaviour != nil && params.Behaviour.Type == "single" && correct > 1 {
+			p.check(r, RuleConflictingAnswers, schemas.JoinPath(path, "params.behaviour.type"),
+				"single answer question marks %d answers correct", correct)
+		}
+		return
+	}
+
+	if !strings.HasPrefix(q.Library, "H5P.SingleChoiceSet ") {
+		return
+	}
+	params, _ := q.Params.(map[string]any)
+	choices, _ := params["choices"].([]any)
+	for i, c := range choices {
+		choice, _ := c.(map[string]any)
+		answers, _ := choice["answers"].([]any)
+		texts := make([]string, 0, len(answers))
+		for _, a := range answers {
+			s, _ := a.(string)
+			texts = append(texts, s)
+		}
+		p.checkDuplicates(r, schemas.JoinPath(path, schemas.JoinPath(schemas.IndexPath("params.choices", i), "answers")), texts)
+	}
+}
+
+// checkDuplicates reports answers whose plain text, compared
+// case-insensitively, repeats an earlier answer of the list at path.
+func (p ValidationProfile) checkDuplicates(r *ValidationResult, path string, texts []string) {
+	seen := map[string]int{}
+	for i, text := range texts {
+		key := strings.ToLower(plainText(text))
+		if key == "" {
+			continue
+		}
+		if first, ok := seen[key]; ok {
+			p.check(r, RuleDuplicateAnswer, schemas.IndexPath(path, i),
+				"answer %q duplicates answer %d", plainText(text), first)
+			continue
+		}
+		seen[key] = i
+	}
+}
@@ -0,0 +1,155 @@
+package h5p
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// AssetReference is a file path referenced from content params.
+type AssetReference struct {
+	Path     string `json:"path"`
+	JSONPath string `json:"jsonPath"`
+}
+
+// AssetReport lists content assets referenced from params but missing from
+// the content folder, and content folder files no params refer to.
+type AssetReport struct {
+	References []AssetReference `json:"references"`
+	Missing    []AssetReference `json:"missing,omitempty"`
+	Orphaned   []string         `json:"orphaned,omitempty"`
+}
+
+// OK reports whether there are no missing or orphaned assets.
+func (r *AssetReport) OK() bool {
+	return len(r.Missing) == 0 && len(r.Orphaned) == 0
+}
+
+// CheckAssetReferences walks the content params for media "path" references
+// (images, audio, video, files) and compares them with the content folder.
+// External URLs are ignored.
+func (pkg *H5PPackage) CheckAssetReferences() (*AssetReport, error) {
+	report := &AssetReport{}
+	if pkg.Content != nil {
+		params, err := toGenericJSON(pkg.Content)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read content params: %w", err)
+		}
+		report.References = findAssetReferences(params, "")
+	}
+
+	used := map[string]bool{}
+	for _, ref := range report.References {
+		used[ref.Path] = true
+		if _, ok := pkg.ContentFiles[ref.Path]; !ok {
+			report.Missing = append(report.Missing, ref)
+		}
+	}
+	for _, name := range pkg.ContentFileNames() {
+		if !used[name] {
+			report.Orphaned = append(report.Orphaned, name)
+		}
+	}
+	return report, nil
+}
+
+// toGenericJSON converts typed values into the map/slice form produced by
+// decoding JSON into interface{}.
+func toGenericJSON(v any) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var out any
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// retypeParams decodes generic JSON params into a new value of the type
+// of typed, such as *schemas.MultiChoiceParams, returning generic as it is
+// if typed is not a pointer or the params do not decode into its type.
+func retypeParams(generic, typed any) any {
+	t := reflect.TypeOf(typed)
+	if t == nil || t.Kind() != reflect.Pointer {
+		return generic
+	}
+	data, err := json.Marshal(generic)
+	if err != nil {
+		return generic
+	}
+	v := reflect.New(t.Elem())
+	if err := json.Unmarshal(data, v.Interface()); err != nil {
+		return generic
+	}
+	return v.Interface()
+}
+
+func findAssetReferences(v any, jsonPath string) []AssetReference {
+	var refs []AssetReference
+	switch t := v.(type) {
+	case map[string]any:
+		if p, ok := t["path"].(string); ok {
+			if local, ok := localAssetPath(p); ok {
+				refs = append(refs, AssetReference{Path: local, JSONPath: joinJSONPath(jsonPath, "path")})
+			}
+		}
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			refs = append(refs, findAssetReferences(t[k], joinJSONPath(jsonPath, k))...)
+		}
+	case []any:
+		for i, item := range t {
+			refs = append(refs, findAssetReferences(item, jsonPath+"["+strconv.Itoa(i)+"]")...)
+		}
+	}
+	return refs
+}
+
+// renameAssetPaths replaces the local asset paths in renamed by their new
+// names in generic JSON, returning the number of references changed.
+func renameAssetPaths(v any, renamed map[string]string) int {
+	n := 0
+	switch t := v.(type) {
+	case map[string]any:
+		if p, ok := t["path"].(string); ok {
+			if local, ok := localAssetPath(p); ok && renamed[local] != "" {
+				t["path"] = renamed[local]
+				n++
+			}
+		}
+		for _, child := range t {
+			n += renameAssetPaths(child, renamed)
+		}
+	case []any:
+		for _, child := range t {
+			n += renameAssetPaths(child, renamed)
+		}
+	}
+	return n
+}
+
+// localAssetPath normalizes a params path to one relative to the content
+// folder, rejecting external URLs. The editor's "#tmp" suffix is removed.
+func localAssetPath(p string) (string, bool) {
+	p = strings.TrimSuffix(p, "#tmp")
+	if p == "" || strings.Contains(p, "://") || strings.HasPrefix(p, "//") || strings.HasPrefix(p, "data:") {
+		return "", false
+	}
+	return strings.TrimPrefix(p, ContentDir+"/"), true
+}
+
+func joinJSONPath(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
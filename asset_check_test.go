@@ -0,0 +1,57 @@
+package h5p
+
+import (
+	"testing"
+)
+
+func TestCheckAssetReferences(t *testing.T) {
+	qs, err := NewQuestionSetBuilder().
+		SetTitle("Assets").
+		SetBackgroundImage("images/background.jpg", "image/jpeg").
+		AddMultipleChoiceQuestion("Question?", []Answer{CreateAnswer("Yes", true)}).
+		Build()
+	if err != nil {
+		t.Fatalf("Failed to build question set: %v", err)
+	}
+
+	pkg := NewH5PPackage()
+	pkg.SetContent(&Content{
+		QuestionSet: qs,
+		Params: map[string]any{
+			"media": map[string]any{
+				"params": map[string]any{
+					"file": map[string]any{"path": "images/photo.png#tmp", "mime": "image/png"},
+				},
+			},
+			"video": []any{
+				map[string]any{"path": "https://example.com/video.mp4"},
+			},
+		},
+	})
+	if err := pkg.AddContentAsset("images/photo.png", []byte("png"), ""); err != nil {
+		t.Fatalf("Failed to add asset: %v", err)
+	}
+	if err := pkg.AddContentAsset("images/unused.gif", []byte("gif"), ""); err != nil {
+		t.Fatalf("Failed to add asset: %v", err)
+	}
+
+	report, err := pkg.CheckAssetReferences()
+	if err != nil {
+		t.Fatalf("Failed to check asset references: %v", err)
+	}
+	if report.OK() {
+		t.Fatal("Expected missing and orphaned assets")
+	}
+	if len(report.References) != 2 {
+		t.Errorf("Expected 2 local references, got %d: %v", len(report.References), report.References)
+	}
+	if len(report.Missing) != 1 || report.Missing[0].Path != "images/background.jpg" {
+		t.Errorf("Expected missing background image, got %v", report.Missing)
+	}
+	if report.Missing[0].JSONPath != "questionSet.backgroundImage.path" {
+		t.Errorf("Unexpected JSON path '%s'", report.Missing[0].JSONPath)
+	}
+	if len(report.Orphaned) != 1 || report.Orphaned[0] != "images/unused.gif" {
+		t.Errorf("Expected orphaned unused.gif, got %v", report.Orphaned)
+	}
+}
@@ -0,0 +1,270 @@
+package h5p
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/grokify/h5p-go/licenses"
+)
+
+// Kinds of AttributionEntry.
+const (
+	AttributionContent = "content"
+	AttributionImage   = "image"
+	AttributionAudio   = "audio"
+	AttributionVideo   = "video"
+	AttributionFile    = "file"
+)
+
+// AttributionEntry credits a work used by a package: the package itself,
+// sub-content with license metadata, or a media file with copyright
+// metadata.
+type AttributionEntry struct {
+	// Kind is AttributionContent or the kind of media, e.g.
+	// AttributionImage.
+	Kind string `json:"kind"`
+	// Path is the content folder path or URL of a media file.
+	Path           string   `json:"path,omitempty"`
+	Title          string   `json:"title,omitempty"`
+	Authors        []string `json:"authors,omitempty"`
+	Years          string   `json:"years,omitempty"`
+	Source         string   `json:"source,omitempty"`
+	License        string   `json:"license,omitempty"`
+	LicenseVersion string   `json:"licenseVersion,omitempty"`
+	// LicenseLabel and LicenseURL name and link the license, e.g. "CC BY
+	// 4.0" and its deed.
+	LicenseLabel string `json:"licenseLabel,omitempty"`
+	LicenseURL   string `json:"licenseUrl,omitempty"`
+	// Attribution is the credit line, see licenses.Attribution.
+	Attribution string `json:"attribution"`
+	// UsedBy lists where the work is used: "h5p.json" or JSON paths in
+	// the content params.
+	UsedBy []string `json:"usedBy"`
+}
+
+// AttributionReport collects the credits for the works a package uses, as
+// institutions publishing open educational resources must list them.
+type AttributionReport struct {
+	Title   string             `json:"title,omitempty"`
+	Entries []AttributionEntry `json:"entries"`
+	// Uncredited lists media files without copyright metadata.
+	Uncredited []AssetReference `json:"uncredited,omitempty"`
+}
+
+// AttributionReport walks h5p.json and the content params for license and
+// copyright metadata: the metadata of sub-content and the copyright of
+// images, audio and video sources and other files, e.g. background
+// images and answer media. Media used several times with the same
+// copyright are listed once.
+func (pkg *H5PPackage) AttributionReport() (*AttributionReport, error) {
+	r := &AttributionReport{}
+	if def := pkg.PackageDefinition; def != nil {
+		r.Title = def.Title
+		r.add(AttributionEntry{Kind: AttributionContent, UsedBy: []string{"h5p.json"}}, def.LicenseMetadata())
+	}
+	if pkg.Content != nil {
+		params, err := toGenericJSON(pkg.Content)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read content params: %w", err)
+		}
+		if err := r.walk(params, ""); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+func (r *AttributionReport) walk(v any, jsonPath string) error {
+	switch t := v.(type) {
+	case map[string]any:
+		if err := r.visit(t, jsonPath); err != nil {
+			return err
+		}
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+		for _, k := range keys {
+			if err := r.walk(t[k], joinJSONPath(jsonPath, k)); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for i, item := range t {
+			if err := r.walk(item, jsonPath+"["+strconv.Itoa(i)+"]"); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// visit adds the credits of a params object: the metadata of sub-content
+// and the copyright of a file.
+func (r *AttributionReport) visit(obj map[string]any, jsonPath string) error {
+	if _, ok := obj["library"].(string); ok && obj["metadata"] != nil {
+		var m ContentMetadata
+		if err := decodeGeneric(obj["metadata"], &m); err != nil {
+			return fmt.Errorf("invalid metadata at %s: %w", jsonPath, err)
+		}
+		if (m.License != "" && m.License != licenses.Undisclosed) || len(m.Authors) > 0 {
+			r.add(AttributionEntry{Kind: AttributionContent, UsedBy: []string{joinJSONPath(jsonPath, "metadata")}}, m.LicenseMetadata())
+		}
+	}
+
+	p, ok := obj["path"].(string)
+	if !ok || p == "" {
+		return nil
+	}
+	var c Copyright
+	if obj["copyright"] != nil {
+		if err := decodeGeneric(obj["copyright"], &c); err != nil {
+			return fmt.Errorf("invalid copyright at %s: %w", jsonPath, err)
+		}
+	}
+	if local, ok := localAssetPath(p); ok {
+		p = local
+	}
+	if c.IsZero() {
+		r.Uncredited = append(r.Uncredited, AssetReference{Path: p, JSONPath: joinJSONPath(jsonPath, "path")})
+		return nil
+	}
+	m := CopyrightLicenseMetadata(&c)
+	mimeType, _ := obj["mime"].(string)
+	if mimeType == "" {
+		mimeType = mimeTypeByPath(p)
+	}
+	r.add(AttributionEntry{Kind: mediaKind(mimeType), Path: p, UsedBy: []string{jsonPath}}, m)
+	return nil
+}
+
+// add fills e from m and adds it, or adds its uses to an equal entry.
+func (r *AttributionReport) add(e AttributionEntry, m licenses.Metadata) {
+	e.Title = m.Title
+	for _, a := range m.Authors {
+		if a.Name != "" {
+			e.Authors = append(e.Authors, a.Name)
+		}
+	}
+	switch {
+	case m.YearFrom > 0 && m.YearTo > 0 && m.YearTo != m.YearFrom:
+		e.Years = fmt.Sprintf("%d-%d", m.YearFrom, m.YearTo)
+	case m.YearFrom > 0:
+		e.Years = strconv.Itoa(m.YearFrom)
+	case m.YearTo > 0:
+		e.Years = strconv.Itoa(m.YearTo)
+	}
+	e.Source = m.Source
+	e.License, e.LicenseVersion = m.License, m.LicenseVersion
+	if l, ok := licenses.Lookup(m.License); ok {
+		e.LicenseLabel = l.Label(m.LicenseVersion)
+		e.LicenseURL = l.URL(m.LicenseVersion)
+	}
+	e.Attribution = licenses.Attribution(m)
+
+	for i := range r.Entries {
+		if r.Entries[i].Kind == e.Kind && r.Entries[i].Path == e.Path && r.Entries[i].Attribution == e.Attribution {
+			r.Entries[i].UsedBy = append(r.Entries[i].UsedBy, e.UsedBy...)
+			return
+		}
+	}
+	r.Entries = append(r.Entries, e)
+}
+
+func mediaKind(mimeType string) string {
+	switch kind, _, _ := strings.Cut(mimeType, "/"); kind {
+	case "image":
+		return AttributionImage
+	case "audio":
+		return AttributionAudio
+	case "video":
+		return AttributionVideo
+	}
+	return AttributionFile
+}
+
+// decodeGeneric decodes a generic JSON value into v.
+func decodeGeneric(generic, v any) error {
+	data, err := json.Marshal(generic)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+var attributionTemplate = template.Must(template.New("attribution").Parse(`<div class="h5p-attribution">
+<h2>{{if .Title}}Credits: {{.Title}}{{else}}Credits{{end}}</h2>
+<ul>
+{{- range .Entries}}
+<li class="h5p-attribution-{{.Kind}}">
+{{- if .Title}}<cite>{{.Title}}</cite>{{else if .Path}}<code>{{.Path}}</code>{{else}}This work{{end}}
+{{- if .Authors}} by {{range $i, $a := .Authors}}{{if $i}}, {{end}}{{$a}}{{end}}{{end}}
+{{- if .Years}} ({{.Years}}){{end}}
+{{- if eq .License "C"}}, all rights reserved
+{{- else if and .LicenseLabel (ne .License "U")}}, licensed under {{if .LicenseURL}}<a href="{{.LicenseURL}}" rel="license">{{.LicenseLabel}}</a>{{else}}{{.LicenseLabel}}{{end}}
+{{- end}}.
+{{- if .Source}} Source: <a href="{{.Source}}">{{.Source}}</a>{{end}}</li>
+{{- end}}
+</ul>
+</div>
+`))
+
+// WriteHTML writes the report as an HTML fragment, a list of credits with
+// links to the licenses and sources.
+func (r *AttributionReport) WriteHTML(w io.Writer) error {
+	return attributionTemplate.Execute(w, r)
+}
+
+// WriteMarkdown writes the report as a Markdown list of credits.
+func (r *AttributionReport) WriteMarkdown(w io.Writer) error {
+	var b strings.Builder
+	if r.Title != "" {
+		fmt.Fprintf(&b, "## Credits: %s\n\n", r.Title)
+	} else {
+		b.WriteString("## Credits\n\n")
+	}
+	for _, e := range r.Entries {
+		b.WriteString("- ")
+		switch {
+		case e.Title != "":
+			fmt.Fprintf(&b, "*%s*", markdownEscaper.Replace(e.Title))
+		case e.Path != "":
+			fmt.Fprintf(&b, "`%s`", e.Path)
+		default:
+			b.WriteString("This work")
+		}
+		if len(e.Authors) > 0 {
+			b.WriteString(" by " + markdownEscaper.Replace(strings.Join(e.Authors, ", ")))
+		}
+		if e.Years != "" {
+			b.WriteString(" (" + e.Years + ")")
+		}
+		switch {
+		case e.License == licenses.Copyright:
+			b.WriteString(", all rights reserved")
+		case e.LicenseLabel != "" && e.License != licenses.Undisclosed:
+			if e.LicenseURL != "" {
+				fmt.Fprintf(&b, ", licensed under [%s](%s)", e.LicenseLabel, e.LicenseURL)
+			} else {
+				b.WriteString(", licensed under " + e.LicenseLabel)
+			}
+		}
+		b.WriteString(".")
+		if e.Source != "" {
+			fmt.Fprintf(&b, " Source: <%s>", e.Source)
+		}
+		b.WriteString("\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// markdownEscaper escapes the characters that start Markdown emphasis and
+// links in credit texts.
+var markdownEscaper = strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`, "`", "\\`")
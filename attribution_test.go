@@ -0,0 +1,63 @@
+package h5p
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAttributionReport(t *testing.T) {
+	pkg := NewH5PPackage()
+	pkg.SetPackageDefinition(&PackageDefinition{Title: "Birds", MainLibrary: "H5P.QuestionSet", License: "CC BY", LicenseVersion: "4.0", Authors: []Author{{Name: "Ada", Role: "Author"}}})
+	var params map[string]any
+	if err := json.Unmarshal([]byte(`{
+		"backgroundImage": {"path": "images/sky.jpg", "mime": "image/jpeg", "copyright": {"title": "Sky", "author": "Bo", "license": "CC BY-SA", "version": "4.0", "source": "https://example.com/sky"}},
+		"questions": [
+			{"library": "H5P.MultiChoice 1.16", "metadata": {"title": "Q1", "license": "CC0 1.0", "authors": [{"name": "Cy", "role": "Author"}]},
+			 "params": {"media": {"type": {"library": "H5P.Image 1.1", "metadata": {"license": "U"}, "params": {"file": {"path": "images/sky.jpg", "copyright": {"title": "Sky", "author": "Bo", "license": "CC BY-SA", "version": "4.0", "source": "https://example.com/sky"}}}}}}},
+			{"library": "H5P.Video 1.6", "params": {"sources": [{"path": "https://example.com/bird.mp4", "mime": "video/mp4", "copyright": {"license": "C", "author": "Di", "year": "2024"}}, {"path": "videos/bird.webm"}]}}
+		]
+	}`), &params); err != nil {
+		t.Fatal(err)
+	}
+	pkg.SetContent(&Content{Params: params})
+
+	r, err := pkg.AttributionReport()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Entries) != 4 {
+		t.Fatalf("Expected 4 entries, got %+v", r.Entries)
+	}
+	if e := r.Entries[0]; e.Kind != AttributionContent || e.Title != "Birds" || e.LicenseLabel != "CC BY 4.0" || e.UsedBy[0] != "h5p.json" {
+		t.Errorf("Unexpected package entry %+v", e)
+	}
+	if e := r.Entries[1]; e.Kind != AttributionImage || e.Path != "images/sky.jpg" || !reflect.DeepEqual(e.UsedBy, []string{"backgroundImage", "questions[0].params.media.type.params.file"}) || e.LicenseURL == "" {
+		t.Errorf("Expected the sky image credited once, got %+v", e)
+	}
+	if e := r.Entries[2]; e.Kind != AttributionContent || e.Title != "Q1" || !reflect.DeepEqual(e.Authors, []string{"Cy"}) {
+		t.Errorf("Unexpected content entry %+v", e)
+	}
+	if e := r.Entries[3]; e.Kind != AttributionVideo || e.License != "C" {
+		t.Errorf("Unexpected video entry %+v", e)
+	}
+	if !reflect.DeepEqual(r.Uncredited, []AssetReference{{Path: "videos/bird.webm", JSONPath: "questions[1].params.sources[1].path"}}) {
+		t.Errorf("Unexpected uncredited media %+v", r.Uncredited)
+	}
+
+	var html, md bytes.Buffer
+	if err := r.WriteHTML(&html); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(html.String(), `<cite>Sky</cite> by Bo, licensed under <a href="https://creativecommons.org/licenses/by-sa/4.0/" rel="license">`) {
+		t.Errorf("Unexpected HTML:\n%s", html.String())
+	}
+	if err := r.WriteMarkdown(&md); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(md.String(), "## Credits: Birds\n") || !strings.Contains(md.String(), "`https://example.com/bird.mp4` by Di (2024), all rights reserved.") {
+		t.Errorf("Unexpected Markdown:\n%s", md.String())
+	}
+}
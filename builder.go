@@ -3,20 +3,48 @@ package h5p
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
+	"strconv"
 
 	"github.com/grokify/h5p-go/schemas"
 )
 
+var (
+	ErrUnknownContentType = errors.New("unknown content type")
+	ErrQuestionIndex      = errors.New("question index out of range")
+)
+
 type QuestionSetBuilder struct {
 	questionSet *QuestionSet
+	// err is the first error of the added questions, returned by Build.
+	err error
+	// behaviour is applied to all questions by Build, with the overrides
+	// of questions by sub-content ID on top.
+	behaviour QuestionBehaviour
+	overrides map[string]QuestionBehaviour
+	// translations, if set, are applied to the questions by Build.
+	translations *UITranslationSet
 }
 
-func NewQuestionSetBuilder() *QuestionSetBuilder {
-	return &QuestionSetBuilder{
+// NewQuestionSetBuilder returns a builder of an empty question set,
+// configured by opts; see QuestionSetBuilder.With.
+func NewQuestionSetBuilder(opts ...BuilderOption) *QuestionSetBuilder {
+	b := &QuestionSetBuilder{
 		questionSet: &QuestionSet{
 			Questions: make([]Question, 0),
 		},
 	}
+	return b.With(opts...)
+}
+
+// NewQuestionSetBuilderFrom returns a builder starting from a deep copy of
+// qs, for editing existing quizzes; qs itself is not modified.
+func NewQuestionSetBuilderFrom(qs *QuestionSet) *QuestionSetBuilder {
+	c := qs.Clone()
+	if c.Questions == nil {
+		c.Questions = make([]Question, 0)
+	}
+	return &QuestionSetBuilder{questionSet: c}
 }
 
 func (b *QuestionSetBuilder) SetProgressType(progressType string) *QuestionSetBuilder {
@@ -29,6 +57,116 @@ func (b *QuestionSetBuilder) SetPassPercentage(percentage int) *QuestionSetBuild
 	return b
 }
 
+// SetRandomQuestions sets whether the questions are shown in random order.
+func (b *QuestionSetBuilder) SetRandomQuestions(random bool) *QuestionSetBuilder {
+	b.questionSet.RandomQuestions = random
+	return b
+}
+
+// SetPoolSize shows a random batch of n of the questions; 0 shows all.
+func (b *QuestionSetBuilder) SetPoolSize(n int) *QuestionSetBuilder {
+	b.questionSet.PoolSize = n
+	return b
+}
+
+// SetTexts sets the labels of the navigation.
+func (b *QuestionSetBuilder) SetTexts(texts *QuestionSetTexts) *QuestionSetBuilder {
+	b.questionSet.Texts = texts
+	return b
+}
+
+// SetEndGame replaces the result page settings.
+func (b *QuestionSetBuilder) SetEndGame(endGame *EndGame) *QuestionSetBuilder {
+	b.questionSet.EndGame = endGame
+	return b
+}
+
+// endGame returns the result page settings, starting from NewEndGame.
+func (b *QuestionSetBuilder) endGame() *EndGame {
+	if b.questionSet.EndGame == nil {
+		b.questionSet.EndGame = NewEndGame()
+	}
+	return b.questionSet.EndGame
+}
+
+// SetShowResultPage sets whether the results are shown when the quiz is
+// finished, or only the no-result message.
+func (b *QuestionSetBuilder) SetShowResultPage(show bool, noResultMessage string) *QuestionSetBuilder {
+	eg := b.endGame()
+	eg.ShowResultPage = show
+	if noResultMessage != "" {
+		eg.NoResultMessage = noResultMessage
+	}
+	return b
+}
+
+// SetResultButtons sets whether the result page offers to show the
+// solutions and to retry the quiz.
+func (b *QuestionSetBuilder) SetResultButtons(showSolution, showRetry bool) *QuestionSetBuilder {
+	eg := b.endGame()
+	eg.ShowSolutionButton = showSolution
+	eg.ShowRetryButton = showRetry
+	return b
+}
+
+// SetResultButtonTexts sets the button labels of the end of the quiz.
+// Empty texts are left unchanged.
+func (b *QuestionSetBuilder) SetResultButtonTexts(solution, retry, finish, submit string) *QuestionSetBuilder {
+	eg := b.endGame()
+	if solution != "" {
+		eg.SolutionButtonText = solution
+	}
+	if retry != "" {
+		eg.RetryButtonText = retry
+	}
+	if finish != "" {
+		eg.FinishButtonText = finish
+	}
+	if submit != "" {
+		eg.SubmitButtonText = submit
+	}
+	return b
+}
+
+// SetResultFeedback sets the feedback of the result page per score range.
+func (b *QuestionSetBuilder) SetResultFeedback(ranges []schemas.FeedbackRange) *QuestionSetBuilder {
+	b.endGame().OverallFeedback = &schemas.OverallFeedback{OverallFeedback: ranges}
+	return b
+}
+
+// SetSuccessMessage sets the greeting and comment shown to users who
+// passed.
+func (b *QuestionSetBuilder) SetSuccessMessage(greeting, comment string) *QuestionSetBuilder {
+	eg := b.endGame()
+	eg.SuccessGreeting = greeting
+	eg.SuccessComment = comment
+	return b
+}
+
+// SetFailMessage sets the greeting and comment shown to users who failed.
+func (b *QuestionSetBuilder) SetFailMessage(greeting, comment string) *QuestionSetBuilder {
+	eg := b.endGame()
+	eg.FailGreeting = greeting
+	eg.FailComment = comment
+	return b
+}
+
+// SetSuccessVideo plays a video to users who passed before the results.
+func (b *QuestionSetBuilder) SetSuccessVideo(path, mime string) *QuestionSetBuilder {
+	eg := b.endGame()
+	eg.SuccessVideo = []schemas.VideoFile{{Path: path, Mime: mime}}
+	eg.ShowAnimations = true
+	return b
+}
+
+// SetFailVideo plays a video to users who failed before the results.
+func (b *QuestionSetBuilder) SetFailVideo(path, mime string) *QuestionSetBuilder {
+	eg := b.endGame()
+	eg.FailVideo = []schemas.VideoFile{{Path: path, Mime: mime}}
+	eg.ShowAnimations = true
+	return b
+}
+
 func (b *QuestionSetBuilder) SetTitle(title string) *QuestionSetBuilder {
 	b.questionSet.Title = title
 	return b
@@ -72,26 +210,198 @@ func (b *QuestionSetBuilder) AddMultipleChoiceQuestion(question string, answers
 	}
 
 	q := Question{
-		Library: "H5P.MultiChoice 1.16",
-		Params:  params,
+		Library:      "H5P.MultiChoice 1.16",
+		Params:       params,
+		SubContentID: NewSubContentID(),
+		Metadata:     newMultiChoiceMetadata(),
+	}
+
+	b.questionSet.Questions = append(b.questionSet.Questions, q)
+	return b
+}
+
+// AddQuestion adds a question of any content type with typed params. The
+// library version is that of the content type registered in package
+// schemas or, if it has none, the newest version listed in
+// LatestLibraryVersions, and the metadata is titled after the registered
+// content type. Build fails if neither gives a version.
+func (b *QuestionSetBuilder) AddQuestion(params schemas.ContentTypeParams) *QuestionSetBuilder {
+	machineName := params.MachineName()
+	ct, registered := schemas.LookupContentType(machineName)
+	library, ok := LatestLibraryString(machineName)
+	if registered && ct.Version != "" {
+		dep, err := ParseLibraryString(machineName + " " + ct.Version)
+		if err != nil {
+			return b.fail(fmt.Errorf("%w: %s: %v", ErrUnknownContentType, machineName, err))
+		}
+		library, ok = dep.String(), true
+	}
+	if !ok {
+		return b.fail(fmt.Errorf("%w: %s", ErrUnknownContentType, machineName))
+	}
+	title := machineName
+	if registered && ct.Title != "" {
+		title = ct.Title
+	}
+
+	q := Question{
+		Library:      library,
+		Params:       params,
+		SubContentID: NewSubContentID(),
+		Metadata: &ContentMetadata{
+			Title:       "Untitled " + title,
+			ContentType: title,
+			License:     "U",
+		},
 	}
 
 	b.questionSet.Questions = append(b.questionSet.Questions, q)
 	return b
 }
 
+// AddTrueFalseQuestion adds a TrueFalse question whose answer is correct.
+// behaviour may be nil to use the defaults of the content type.
+func (b *QuestionSetBuilder) AddTrueFalseQuestion(question string, correct bool, behaviour *schemas.TrueFalseBehaviour) *QuestionSetBuilder {
+	return b.AddQuestion(&schemas.TrueFalseParams{
+		Question:  question,
+		Correct:   strconv.FormatBool(correct),
+		Behaviour: behaviour,
+	})
+}
+
+// AddEssayQuestion adds an Essay question whose answers are scored by the
+// keywords they contain. behaviour may be nil to use the defaults of the
+// content type.
+func (b *QuestionSetBuilder) AddEssayQuestion(taskDescription string, keywords []schemas.EssayKeyword, behaviour *schemas.EssayBehaviour) *QuestionSetBuilder {
+	return b.AddQuestion(&schemas.EssayParams{
+		TaskDescription: taskDescription,
+		Keywords:        keywords,
+		Behaviour:       behaviour,
+	})
+}
+
+// SetDefaultBehaviour sets the behaviour Build applies to all questions,
+// including those added before, over the behaviour of their params.
+func (b *QuestionSetBuilder) SetDefaultBehaviour(behaviour QuestionBehaviour) *QuestionSetBuilder {
+	b.behaviour = behaviour
+	return b
+}
+
+// OverrideBehaviour sets the behaviour of the last added question, over
+// the default behaviour.
+func (b *QuestionSetBuilder) OverrideBehaviour(behaviour QuestionBehaviour) *QuestionSetBuilder {
+	q, err := b.last()
+	if err != nil {
+		return b.fail(err)
+	}
+	if q.SubContentID == "" {
+		q.SubContentID = NewSubContentID()
+	}
+	if b.overrides == nil {
+		b.overrides = map[string]QuestionBehaviour{}
+	}
+	b.overrides[q.SubContentID] = b.overrides[q.SubContentID].Merge(behaviour)
+	return b
+}
+
+// InsertQuestion inserts q before the question at index i; i equal to the
+// number of questions appends it.
+func (b *QuestionSetBuilder) InsertQuestion(i int, q Question) *QuestionSetBuilder {
+	return b.fail(b.questionSet.InsertQuestion(i, q))
+}
+
+// ReplaceQuestion replaces the question at index i.
+func (b *QuestionSetBuilder) ReplaceQuestion(i int, q Question) *QuestionSetBuilder {
+	return b.fail(b.questionSet.ReplaceQuestion(i, q))
+}
+
+// RemoveQuestion removes the question at index i.
+func (b *QuestionSetBuilder) RemoveQuestion(i int) *QuestionSetBuilder {
+	return b.fail(b.questionSet.RemoveQuestion(i))
+}
+
+// MoveQuestion moves the question at index from to index to.
+func (b *QuestionSetBuilder) MoveQuestion(from, to int) *QuestionSetBuilder {
+	return b.fail(b.questionSet.MoveQuestion(from, to))
+}
+
+// fail records the first error for Build; nil errors are ignored.
+func (b *QuestionSetBuilder) fail(err error) *QuestionSetBuilder {
+	if b.err == nil {
+		b.err = err
+	}
+	return b
+}
+
+// SetQuestionTags sets the tags of the last added question, for
+// GroupByTag and AssembleQuestionSet.
+func (b *QuestionSetBuilder) SetQuestionTags(tags ...string) *QuestionSetBuilder {
+	q, err := b.last()
+	if err != nil {
+		return b.fail(err)
+	}
+	q.metadata().Tags = tags
+	return b
+}
+
+// SetQuestionDifficulty sets the difficulty of the last added question,
+// e.g. DifficultyEasy.
+func (b *QuestionSetBuilder) SetQuestionDifficulty(difficulty string) *QuestionSetBuilder {
+	q, err := b.last()
+	if err != nil {
+		return b.fail(err)
+	}
+	q.metadata().Difficulty = difficulty
+	return b
+}
+
+// last returns the last added question.
+func (b *QuestionSetBuilder) last() (*Question, error) {
+	questions := b.questionSet.Questions
+	if len(questions) == 0 {
+		return nil, errors.New("no question added")
+	}
+	return &questions[len(questions)-1], nil
+}
+
 func (b *QuestionSetBuilder) AddOverallFeedback(ranges []FeedbackRange) *QuestionSetBuilder {
 	b.questionSet.OverallFeedback = ranges
 	return b
 }
 
 func (b *QuestionSetBuilder) Build() (*QuestionSet, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
 	if len(b.questionSet.Questions) == 0 {
 		return nil, errors.New("question set must have at least one question")
 	}
+	b.questionSet.EnsureSubContentIDs()
+	if b.translations != nil {
+		b.translations.Apply(b.questionSet)
+	}
+	for i := range b.questionSet.Questions {
+		q := &b.questionSet.Questions[i]
+		if err := b.behaviour.Merge(b.overrides[q.SubContentID]).Apply(q); err != nil {
+			return nil, fmt.Errorf("question %d: %w", i, err)
+		}
+	}
 	return b.questionSet, nil
 }
 
+// BuildAndValidate builds the question set and validates it, returning
+// the *ValidationResult of Validate as the error if it has problems.
+func (b *QuestionSetBuilder) BuildAndValidate() (*QuestionSet, error) {
+	qs, err := b.Build()
+	if err != nil {
+		return nil, err
+	}
+	if err := qs.Validate(); err != nil {
+		return nil, err
+	}
+	return qs, nil
+}
+
 func CreateAnswer(text string, correct bool) Answer {
 	return Answer{
 		Text:    text,
@@ -128,20 +438,64 @@ func FromJSON(data []byte) (*QuestionSet, error) {
 	return &qs, nil
 }
 
+// Validate checks the question set and its MultiChoice questions. The
+// returned error is a *ValidationResult listing every problem.
 func (qs *QuestionSet) Validate() error {
+	return qs.ValidateAll().Err()
+}
+
+// ValidateAll checks the question set and returns all problems found, with
+// JSON paths such as "questions[2].params.answers[0].text". Quality rules
+// are not applied; see ValidateWithProfile.
+func (qs *QuestionSet) ValidateAll() *ValidationResult {
+	r := &ValidationResult{}
 	if len(qs.Questions) == 0 {
-		return errors.New("question set must have at least one question")
+		r.AddError("questions", schemas.CodeRequired, "question set must have at least one question")
 	}
 
 	if qs.PassPercentage < 0 || qs.PassPercentage > 100 {
-		return errors.New("pass percentage must be between 0 and 100")
+		r.AddError("passPercentage", schemas.CodeOutOfRange, "pass percentage must be between 0 and 100")
+	}
+
+	if qs.PoolSize < 0 {
+		r.AddError("poolSize", schemas.CodeOutOfRange, "pool size must not be negative")
+	} else if qs.PoolSize > len(qs.Questions) && len(qs.Questions) > 0 {
+		r.AddWarning("poolSize", schemas.CodeOutOfRange,
+			"pool size %d exceeds the %d questions; all are shown", qs.PoolSize, len(qs.Questions))
 	}
 
 	for i, feedback := range qs.OverallFeedback {
 		if feedback.From > feedback.To {
-			return errors.New("feedback range 'from' cannot be greater than 'to' at index " + string(rune(i)))
+			r.AddError(schemas.IndexPath("overallFeedback", i), schemas.CodeInvalidRange,
+				"feedback range 'from' (%d) cannot be greater than 'to' (%d)", feedback.From, feedback.To)
+		}
+	}
+
+	if qs.EndGame != nil && qs.EndGame.OverallFeedback != nil {
+		for i, feedback := range qs.EndGame.OverallFeedback.OverallFeedback {
+			if feedback.From > feedback.To {
+				r.AddError(schemas.IndexPath("endGame.overallFeedback.overallFeedback", i), schemas.CodeInvalidRange,
+					"feedback range 'from' (%d) cannot be greater than 'to' (%d)", feedback.From, feedback.To)
+			}
+		}
+	}
+
+	for i := range qs.Questions {
+		q := &qs.Questions[i]
+		path := schemas.IndexPath("questions", i)
+		if q.Library == "" {
+			r.AddError(schemas.JoinPath(path, "library"), schemas.CodeRequired, "library is required")
+		}
+		params, ok, err := q.multiChoiceParams()
+		switch {
+		case err != nil:
+			r.AddError(schemas.JoinPath(path, "params"), schemas.CodeInvalidValue, "invalid MultiChoice params: %v", err)
+		case ok:
+			r.Merge(schemas.JoinPath(path, "params"), params.ValidateAll())
 		}
 	}
+	qs.validateQuestionLanguages(r)
+	qs.validateTextDirection(r)
 
-	return nil
+	return r
 }
@@ -0,0 +1,121 @@
+package h5p
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Names of the BuilderProfiles defined by this package.
+const (
+	ProfileExam     = "exam"
+	ProfilePractice = "practice"
+)
+
+var ErrUnknownProfile = errors.New("unknown builder profile")
+
+// BuilderOption configures a QuestionSetBuilder, see NewQuestionSetBuilder
+// and QuestionSetBuilder.With.
+type BuilderOption func(b *QuestionSetBuilder)
+
+// BuilderProfiles are named sets of options for common kinds of quizzes,
+// applied with WithProfile. Entries may be added or replaced.
+var BuilderProfiles = map[string][]BuilderOption{
+	// Exams are taken once: questions come in random order, and neither
+	// questions nor the result page allow retrying or revealing solutions.
+	ProfileExam: {
+		WithProgressType("textual"),
+		WithRandomQuestions(true),
+		WithBehaviour(behaviourFlags(false, false)),
+		WithResultButtons(false, false),
+	},
+	// Practice quizzes let users retry and look at the solutions.
+	ProfilePractice: {
+		WithProgressType("dots"),
+		WithBehaviour(behaviourFlags(true, true)),
+		WithResultButtons(true, true),
+	},
+}
+
+func behaviourFlags(retry, solutions bool) QuestionBehaviour {
+	return QuestionBehaviour{EnableRetry: &retry, EnableSolutionsButton: &solutions}
+}
+
+// With applies options to the builder in order. Options change the
+// settings of the question set like the setter methods, overriding
+// earlier settings; the settings of questions, such as WithBehaviour and
+// WithUITranslations, are applied by Build to all questions, including
+// those added later.
+func (b *QuestionSetBuilder) With(opts ...BuilderOption) *QuestionSetBuilder {
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+// WithProfile applies the options of a named profile in BuilderProfiles,
+// e.g. ProfileExam. Options given after it override its settings.
+func WithProfile(name string) BuilderOption {
+	return func(b *QuestionSetBuilder) {
+		opts, ok := BuilderProfiles[name]
+		if !ok {
+			b.fail(fmt.Errorf("%w: %q", ErrUnknownProfile, name))
+			return
+		}
+		b.With(opts...)
+	}
+}
+
+// WithPassPercentage sets the percentage of the score needed to pass.
+func WithPassPercentage(percentage int) BuilderOption {
+	return func(b *QuestionSetBuilder) { b.SetPassPercentage(percentage) }
+}
+
+// WithProgressType sets the progress indicator, "dots" or "textual".
+func WithProgressType(progressType string) BuilderOption {
+	return func(b *QuestionSetBuilder) { b.SetProgressType(progressType) }
+}
+
+// WithIntroPage shows an introduction page with a start button before
+// the questions; an empty startButtonText keeps the default.
+func WithIntroPage(introduction, startButtonText string) BuilderOption {
+	return func(b *QuestionSetBuilder) {
+		b.SetIntroduction(introduction)
+		if startButtonText != "" {
+			b.SetStartButtonText(startButtonText)
+		}
+	}
+}
+
+// WithRandomQuestions sets whether the questions are shown in random
+// order.
+func WithRandomQuestions(random bool) BuilderOption {
+	return func(b *QuestionSetBuilder) { b.SetRandomQuestions(random) }
+}
+
+// WithBehaviour sets the default behaviour of the questions, see
+// QuestionSetBuilder.SetDefaultBehaviour. Settings left nil keep those of
+// earlier options.
+func WithBehaviour(behaviour QuestionBehaviour) BuilderOption {
+	return func(b *QuestionSetBuilder) { b.SetDefaultBehaviour(b.behaviour.Merge(behaviour)) }
+}
+
+// WithResultButtons sets whether the result page offers to show the
+// solutions and to retry the quiz.
+func WithResultButtons(showSolution, showRetry bool) BuilderOption {
+	return func(b *QuestionSetBuilder) { b.SetResultButtons(showSolution, showRetry) }
+}
+
+// WithUITranslations sets the user interface texts of the question set
+// and of its questions that have none to DefaultUITranslations of locale,
+// e.g. "de". Build fails with ErrNoTranslations for locales
+// without translations.
+func WithUITranslations(locale string) BuilderOption {
+	return func(b *QuestionSetBuilder) {
+		t, err := DefaultUITranslations(locale)
+		if err != nil {
+			b.fail(err)
+			return
+		}
+		b.translations = t
+	}
+}
@@ -0,0 +1,78 @@
+package h5p
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/grokify/h5p-go/schemas"
+)
+
+func TestQuestionSetBuilderOptions(t *testing.T) {
+	qs, err := NewQuestionSetBuilder(
+		WithProfile(ProfileExam),
+		WithPassPercentage(75),
+		WithIntroPage("Welcome", "Los"),
+		WithUITranslations("de_AT"),
+	).
+		AddMultipleChoiceQuestion("2 + 2?", []Answer{CreateAnswer("4", true)}).
+		AddTrueFalseQuestion("Der Himmel ist blau.", true, nil).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !qs.RandomQuestions || qs.ProgressType != "textual" || qs.PassPercentage != 75 {
+		t.Errorf("Unexpected settings %+v", qs)
+	}
+	if !qs.ShowIntroPage || qs.Introduction != "Welcome" || qs.StartButtonText != "Los" {
+		t.Errorf("Unexpected intro page %+v", qs)
+	}
+	if eg := qs.EndGame; eg == nil || eg.ShowSolutionButton || eg.ShowRetryButton {
+		t.Errorf("Expected the exam result page without buttons, got %+v", eg)
+	}
+	if qs.Texts == nil || qs.Texts.PrevButton != "Vorherige Frage" {
+		t.Errorf("Expected German texts, got %+v", qs.Texts)
+	}
+
+	data, err := json.Marshal(qs.Questions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"enableRetry":false`, `"enableSolutionsButton":false`, `"checkAnswerButton":"Überprüfen"`, `"trueText":"Richtig"`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("Expected %s in questions %s", want, data)
+		}
+	}
+}
+
+func TestQuestionSetBuilderOptionsOverride(t *testing.T) {
+	qs, err := NewQuestionSetBuilder(WithProfile(ProfileExam)).
+		With(WithRandomQuestions(false)).
+		SetProgressType("dots").
+		AddQuestion(&schemas.MultiChoiceParams{
+			Question: "2 + 2?",
+			UI:       &schemas.UITranslations{CheckAnswerButton: "Check"},
+		}).
+		With(WithUITranslations("de")).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if qs.RandomQuestions || qs.ProgressType != "dots" {
+		t.Errorf("Expected later settings to override the profile, got %+v", qs)
+	}
+	var params schemas.MultiChoiceParams
+	if err := qs.Questions[0].DecodeParams(&params); err != nil {
+		t.Fatal(err)
+	}
+	if params.UI == nil || params.UI.CheckAnswerButton != "Check" {
+		t.Errorf("Expected UI texts kept, got %+v", params.UI)
+	}
+
+	for _, opt := range []BuilderOption{WithProfile("homework"), WithUITranslations("tlh")} {
+		if _, err := NewQuestionSetBuilder(opt).Build(); !errors.Is(err, ErrUnknownProfile) && !errors.Is(err, ErrNoTranslations) {
+			t.Errorf("Expected an error for an unknown profile or locale, got %v", err)
+		}
+	}
+}
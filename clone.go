@@ -0,0 +1,93 @@
+package h5p
+
+import "reflect"
+
+// Clone returns a deep copy of the package, including library file maps,
+// semantics and content params, so variants can be derived without
+// mutating shared state. Lazily loaded files still read from the archive
+// the original was loaded from, which stays owned by the original: Close on
+// the clone is a no-op and the original must stay open while the clone
+// reads those files.
+func (pkg *H5PPackage) Clone() *H5PPackage {
+	if pkg == nil {
+		return nil
+	}
+	clone := deepCopy(pkg)
+	clone.closers = nil
+	clone.indexLibraries()
+	return clone
+}
+
+// Clone returns a deep copy of the question set, including question params.
+func (qs *QuestionSet) Clone() *QuestionSet {
+	return deepCopy(qs)
+}
+
+// Clone returns a deep copy of the library, including its file map,
+// definition and semantics.
+func (lib *Library) Clone() *Library {
+	return deepCopy(lib)
+}
+
+// deepCopy copies v recursively. Exported fields, maps, slices, pointers and
+// interface values are duplicated; unexported fields and funcs are copied
+// as-is.
+func deepCopy[T any](v T) T {
+	out := copyValue(reflect.ValueOf(&v).Elem())
+	return out.Interface().(T)
+}
+
+func copyValue(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.New(v.Type().Elem())
+		out.Elem().Set(copyValue(v.Elem()))
+		return out
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.New(v.Type()).Elem()
+		out.Set(copyValue(v.Elem()))
+		return out
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			out.SetMapIndex(iter.Key(), copyValue(iter.Value()))
+		}
+		return out
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			out.Index(i).Set(copyValue(v.Index(i)))
+		}
+		return out
+	case reflect.Array:
+		out := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			out.Index(i).Set(copyValue(v.Index(i)))
+		}
+		return out
+	case reflect.Struct:
+		out := reflect.New(v.Type()).Elem()
+		out.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if f := out.Field(i); f.CanSet() {
+				f.Set(copyValue(v.Field(i)))
+			}
+		}
+		return out
+	default:
+		return v
+	}
+}
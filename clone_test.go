@@ -0,0 +1,70 @@
+package h5p
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/grokify/h5p-go/schemas"
+)
+
+func TestH5PPackageClone(t *testing.T) {
+	pkg := loadTestPackage(t)
+	pkg.Content.Params = map[string]any{"title": "Original", "tags": []any{"a"}}
+	if err := pkg.AddContentAsset("images/photo.png", []byte("png"), ""); err != nil {
+		t.Fatalf("Failed to add asset: %v", err)
+	}
+
+	clone := pkg.Clone()
+	if !reflect.DeepEqual(pkg.PackageDefinition, clone.PackageDefinition) || !reflect.DeepEqual(pkg.Content, clone.Content) {
+		t.Fatal("Clone differs from original")
+	}
+
+	clone.PackageDefinition.Title = "Changed"
+	clone.PackageDefinition.PreloadedDependencies[0].MinorVersion = 99
+	clone.Content.Params.(map[string]any)["title"] = "Changed"
+	clone.Content.Params.(map[string]any)["tags"].([]any)[0] = "b"
+	clone.Libraries[0].Files["js/multichoice.js"][0] = 'X'
+	clone.Libraries[0].Definition.Title = "Changed"
+	clone.ContentFiles["images/photo.png"].Data[0] = 'X'
+	clone.AddLibrary(&Library{MachineName: "H5P.Extra-1.0"})
+
+	if pkg.PackageDefinition.Title == "Changed" || pkg.PackageDefinition.PreloadedDependencies[0].MinorVersion == 99 {
+		t.Error("Modifying clone changed original package definition")
+	}
+	params := pkg.Content.Params.(map[string]any)
+	if params["title"] != "Original" || params["tags"].([]any)[0] != "a" {
+		t.Error("Modifying clone changed original params")
+	}
+	if string(pkg.Libraries[0].Files["js/multichoice.js"][:2]) != "//" || pkg.Libraries[0].Definition.Title == "Changed" {
+		t.Error("Modifying clone changed original library")
+	}
+	if string(pkg.ContentFiles["images/photo.png"].Data) != "png" {
+		t.Error("Modifying clone changed original content file")
+	}
+	if len(pkg.Libraries) != 1 {
+		t.Error("Adding a library to the clone changed original")
+	}
+}
+
+func TestQuestionSetClone(t *testing.T) {
+	params := &schemas.MultiChoiceParams{
+		Question: "Capital of France?",
+		Answers:  []schemas.AnswerOption{{Text: "Paris", Correct: true}},
+	}
+	qs := &QuestionSet{
+		Title:     "Quiz",
+		Questions: []Question{*NewMultiChoiceQuestion(params).ToQuestion()},
+	}
+
+	clone := qs.Clone()
+	cloned, ok := clone.Questions[0].Params.(*schemas.MultiChoiceParams)
+	if !ok {
+		t.Fatalf("Expected typed params to be preserved, got %T", clone.Questions[0].Params)
+	}
+	cloned.Answers[0].Text = "Lyon"
+	clone.Questions = append(clone.Questions, Question{Library: "H5P.TrueFalse 1.8"})
+
+	if params.Answers[0].Text != "Paris" || len(qs.Questions) != 1 {
+		t.Error("Modifying clone changed original question set")
+	}
+}
@@ -0,0 +1,295 @@
+// Command h5pconvert converts question sets between H5P and the formats of
+// other tools: GIFT, Moodle XML, CSV, Markdown and QTI 2.1 packages, and
+// between question set JSON and full .h5p packages.
+//
+// Formats are detected from the file extensions and can be given with
+// -from and -to, which are required for standard input and output ("-"):
+//
+//	gift      .gift, .txt
+//	moodle    .xml
+//	csv       .csv
+//	tsv       .tsv
+//	markdown  .md, .markdown
+//	qti       .zip
+//	json      .json (question set params, the content.json of a package)
+//	h5p       .h5p
+//
+// Packages written by h5pconvert hold the content only unless libraries
+// are added with -libraries-dir or -from-hub as in h5ppack.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	h5p "github.com/grokify/h5p-go"
+	"github.com/grokify/h5p-go/hub"
+	"github.com/grokify/h5p-go/interop/csvexport"
+	"github.com/grokify/h5p-go/interop/csvimport"
+	"github.com/grokify/h5p-go/interop/gift"
+	"github.com/grokify/h5p-go/interop/markdown"
+	"github.com/grokify/h5p-go/interop/moodlexml"
+	"github.com/grokify/h5p-go/interop/qti"
+)
+
+// Formats.
+const (
+	formatGIFT     = "gift"
+	formatMoodle   = "moodle"
+	formatCSV      = "csv"
+	formatTSV      = "tsv"
+	formatMarkdown = "markdown"
+	formatQTI      = "qti"
+	formatJSON     = "json"
+	formatH5P      = "h5p"
+)
+
+var formats = []string{formatGIFT, formatMoodle, formatCSV, formatTSV, formatMarkdown, formatQTI, formatJSON, formatH5P}
+
+var extensions = map[string]string{
+	".gift":     formatGIFT,
+	".txt":      formatGIFT,
+	".xml":      formatMoodle,
+	".csv":      formatCSV,
+	".tsv":      formatTSV,
+	".md":       formatMarkdown,
+	".markdown": formatMarkdown,
+	".zip":      formatQTI,
+	".json":     formatJSON,
+	".h5p":      formatH5P,
+}
+
+type options struct {
+	title           string
+	category        string
+	skipUnsupported bool
+	librariesDir    string
+	fromHub         bool
+	hubCache        string
+}
+
+func main() {
+	from := flag.String("from", "", "input format (default: from the input extension)")
+	to := flag.String("to", "", "output format (default: from the output extension)")
+	var opts options
+	flag.StringVar(&opts.title, "title", "", "title of the question set (default: from the input)")
+	flag.StringVar(&opts.category, "category", "", "question bank category for GIFT and Moodle XML output")
+	flag.BoolVar(&opts.skipUnsupported, "skip-unsupported", false, "leave out questions the target format cannot hold")
+	flag.StringVar(&opts.librariesDir, "libraries-dir", "", "folder of library folders to add to .h5p output")
+	flag.BoolVar(&opts.fromHub, "from-hub", false, "download the libraries of .h5p output from the H5P Hub")
+	flag.StringVar(&opts.hubCache, "hub-cache", "", "H5P Hub cache directory (default: user cache directory)")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: h5pconvert [-from format] [-to format] [flags] input output\n")
+		fmt.Fprintf(os.Stderr, "formats: %s\n", strings.Join(formats, ", "))
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	input, output := flag.Arg(0), flag.Arg(1)
+	inFormat, inOK := format(*from, input)
+	outFormat, outOK := format(*to, output)
+	if !inOK {
+		fmt.Fprintf(os.Stderr, "h5pconvert: unknown format of %s, use -from\n", input)
+		os.Exit(2)
+	}
+	if !outOK {
+		fmt.Fprintf(os.Stderr, "h5pconvert: unknown format of %s, use -to\n", output)
+		os.Exit(2)
+	}
+	if outFormat == formatH5P && output == "-" {
+		fmt.Fprintf(os.Stderr, "h5pconvert: .h5p output must be written to a file\n")
+		os.Exit(2)
+	}
+
+	qs, err := read(input, inFormat, opts)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if opts.title != "" {
+		qs.Title = opts.title
+	}
+	if outFormat == formatH5P {
+		err = writePackage(output, qs, opts)
+	} else {
+		err = write(output, outFormat, qs, opts)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	if output != "-" {
+		fmt.Fprintf(os.Stderr, "wrote %d question(s) to %s\n", len(qs.Questions), output)
+	}
+}
+
+// format returns the named format, or the format of the file extension of
+// name if none is given.
+func format(named, name string) (string, bool) {
+	if named != "" {
+		return named, slices.Contains(formats, named)
+	}
+	f, ok := extensions[strings.ToLower(filepath.Ext(name))]
+	return f, ok
+}
+
+// read reads the question set in the given format from the file name, or
+// from standard input if name is "-".
+func read(name, inFormat string, opts options) (*h5p.QuestionSet, error) {
+	if inFormat == formatH5P {
+		return readPackage(name)
+	}
+	var data []byte
+	var err error
+	if name == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(name)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch inFormat {
+	case formatGIFT:
+		return gift.Import(bytes.NewReader(data), gift.Options{SkipUnsupported: opts.skipUnsupported})
+	case formatMoodle:
+		return moodlexml.Import(bytes.NewReader(data), moodlexml.Options{SkipUnsupported: opts.skipUnsupported})
+	case formatCSV:
+		return csvimport.Read(bytes.NewReader(data), csvimport.Options{Title: opts.title})
+	case formatTSV:
+		csvOpts := csvimport.TSVOptions()
+		csvOpts.Title = opts.title
+		return csvimport.Read(bytes.NewReader(data), csvOpts)
+	case formatMarkdown:
+		return markdown.Import(bytes.NewReader(data), markdown.Options{SkipUnsupported: opts.skipUnsupported})
+	case formatQTI:
+		return qti.Import(bytes.NewReader(data), int64(len(data)), qti.Options{SkipUnsupported: opts.skipUnsupported})
+	case formatJSON:
+		qs, err := h5p.FromJSON(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid question set JSON %s: %w", name, err)
+		}
+		return qs, nil
+	}
+	return nil, fmt.Errorf("cannot read %s input", inFormat)
+}
+
+// readPackage returns the question set of a .h5p package whose main
+// library is H5P.QuestionSet.
+func readPackage(name string) (*h5p.QuestionSet, error) {
+	pkg, err := h5p.NewPackageLoader().Load(name)
+	if err != nil {
+		return nil, err
+	}
+	defer pkg.Close()
+	qs, err := pkg.QuestionSet()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	if qs.Title == "" {
+		qs.Title = pkg.PackageDefinition.Title
+	}
+	return qs, nil
+}
+
+// write writes qs in the given format to the file name, or to standard
+// output if name is "-".
+func write(name, outFormat string, qs *h5p.QuestionSet, opts options) error {
+	var buf bytes.Buffer
+	var err error
+	switch outFormat {
+	case formatGIFT:
+		err = gift.Write(&buf, qs, gift.Options{Category: opts.category, SkipUnsupported: opts.skipUnsupported})
+	case formatMoodle:
+		err = moodlexml.Export(&buf, qs, moodlexml.Options{Category: opts.category, SkipUnsupported: opts.skipUnsupported})
+	case formatCSV:
+		err = csvexport.Write(&buf, qs, csvexport.Options{SkipUnsupported: opts.skipUnsupported})
+	case formatTSV:
+		err = csvexport.Write(&buf, qs, csvexport.Options{Comma: '\t', SkipUnsupported: opts.skipUnsupported})
+	case formatMarkdown:
+		err = markdown.Write(&buf, qs, markdown.Options{SkipUnsupported: opts.skipUnsupported})
+	case formatQTI:
+		err = qti.Export(&buf, qs, qti.Options{SkipUnsupported: opts.skipUnsupported})
+	case formatJSON:
+		var data []byte
+		if data, err = qs.ToJSON(); err == nil {
+			buf.Write(append(data, '\n'))
+		}
+	default:
+		err = fmt.Errorf("cannot write %s output", outFormat)
+	}
+	if err != nil {
+		return err
+	}
+	if name == "-" {
+		_, err = os.Stdout.Write(buf.Bytes())
+		return err
+	}
+	return os.WriteFile(name, buf.Bytes(), 0644)
+}
+
+// writePackage writes qs as a .h5p package with H5P.QuestionSet as its
+// main library, adding the libraries it needs if asked to.
+func writePackage(name string, qs *h5p.QuestionSet, opts options) error {
+	library, _ := h5p.LatestLibraryString("H5P.QuestionSet")
+	main, err := h5p.ParseLibraryString(library)
+	if err != nil {
+		return err
+	}
+	deps := []h5p.LibraryDependency{main}
+	for _, q := range qs.Questions {
+		if dep, err := h5p.ParseLibraryString(q.Library); err == nil && !slices.Contains(deps, dep) {
+			deps = append(deps, dep)
+		}
+	}
+	title := qs.Title
+	if title == "" {
+		title = strings.TrimSuffix(filepath.Base(name), filepath.Ext(name))
+	}
+
+	pkg := h5p.NewH5PPackage()
+	defer pkg.Close()
+	pkg.SetPackageDefinition(&h5p.PackageDefinition{
+		Title:                 title,
+		Language:              "en",
+		MainLibrary:           main.MachineName,
+		EmbedTypes:            []string{"iframe"},
+		License:               "U",
+		PreloadedDependencies: deps,
+	})
+	pkg.SetContent(&h5p.Content{Params: qs})
+
+	if opts.librariesDir != "" {
+		libs, err := h5p.LoadH5PPackageFS(os.DirFS(opts.librariesDir))
+		if err != nil {
+			return fmt.Errorf("failed to load libraries: %w", err)
+		}
+		defer libs.Close()
+		if _, err := h5p.MergeRequiredLibraries(pkg, libs, h5p.MergeOptions{}); err != nil {
+			return err
+		}
+	}
+	if opts.fromHub {
+		cacheDir := opts.hubCache
+		if cacheDir == "" {
+			if cacheDir, err = hub.DefaultCacheDir(); err != nil {
+				return err
+			}
+		}
+		if _, err := hub.NewClient(cacheDir).InstallDependencies(pkg); err != nil {
+			return err
+		}
+	}
+
+	slices.SortFunc(pkg.Libraries, func(a, b *h5p.Library) int { return strings.Compare(a.MachineName, b.MachineName) })
+	return pkg.CreateZipFileWithOptions(name, h5p.DefaultWriteOptions())
+}
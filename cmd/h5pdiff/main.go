@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	h5p "github.com/grokify/h5p-go"
+)
+
+func main() {
+	asJSON := flag.Bool("json", false, "print the diff as JSON")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: h5pdiff [-json] old.h5p new.h5p\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	a, err := h5p.LoadH5PPackage(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer a.Close()
+	b, err := h5p.LoadH5PPackage(flag.Arg(1))
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer b.Close()
+
+	d, err := h5p.DiffPackages(a, b)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *asJSON {
+		out, err := json.MarshalIndent(d, "", "  ")
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(string(out))
+	} else {
+		printDiff(d)
+	}
+
+	// Like diff(1), exit with status 1 when the packages differ.
+	if !d.Empty() {
+		os.Exit(1)
+	}
+}
+
+func printDiff(d *h5p.PackageDiff) {
+	printValues("h5p.json", d.Metadata)
+	if len(d.Libraries) > 0 {
+		fmt.Println("Libraries:")
+		for _, c := range d.Libraries {
+			switch c.Change {
+			case h5p.ChangeAdded:
+				fmt.Printf("  + %s %s\n", c.MachineName, c.NewVersion)
+			case h5p.ChangeRemoved:
+				fmt.Printf("  - %s %s\n", c.MachineName, c.OldVersion)
+			default:
+				fmt.Printf("  ~ %s %s -> %s\n", c.MachineName, c.OldVersion, c.NewVersion)
+			}
+		}
+	}
+	printValues("Content", d.Content)
+	if len(d.Files) > 0 {
+		fmt.Println("Files:")
+		for _, c := range d.Files {
+			fmt.Printf("  %s %s\n", changeSymbol(c.Change), c.Path)
+		}
+	}
+}
+
+func printValues(title string, changes []h5p.ValueChange) {
+	if len(changes) == 0 {
+		return
+	}
+	fmt.Printf("%s:\n", title)
+	for _, c := range changes {
+		switch c.Change {
+		case h5p.ChangeAdded:
+			fmt.Printf("  + %s: %v\n", c.Path, c.New)
+		case h5p.ChangeRemoved:
+			fmt.Printf("  - %s: %v\n", c.Path, c.Old)
+		default:
+			fmt.Printf("  ~ %s: %v -> %v\n", c.Path, c.Old, c.New)
+		}
+	}
+}
+
+func changeSymbol(change string) string {
+	switch change {
+	case h5p.ChangeAdded:
+		return "+"
+	case h5p.ChangeRemoved:
+		return "-"
+	}
+	return "~"
+}
@@ -0,0 +1,106 @@
+// Command h5pextract unpacks a .h5p file into a working directory for
+// editing and source control. Archives with entries outside the package
+// root, symbolic links or oversized files are rejected, and JSON files are
+// pretty-printed so changes diff cleanly.
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	h5p "github.com/grokify/h5p-go"
+)
+
+func main() {
+	contentOnly := flag.Bool("content-only", false, "extract only h5p.json and the content folder")
+	librariesOnly := flag.Bool("libraries-only", false, "extract only the library folders")
+	pretty := flag.Bool("pretty", true, "pretty-print JSON files")
+	force := flag.Bool("force", false, "extract into a directory that is not empty")
+	quiet := flag.Bool("q", false, "do not list extracted files")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: h5pextract [-content-only | -libraries-only] [-pretty=false] [-force] file.h5p [dir]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() < 1 || flag.NArg() > 2 || (*contentOnly && *librariesOnly) {
+		flag.Usage()
+		os.Exit(2)
+	}
+	file := flag.Arg(0)
+	dir := strings.TrimSuffix(file, filepath.Ext(file))
+	if flag.NArg() == 2 {
+		dir = flag.Arg(1)
+	}
+
+	if entries, err := os.ReadDir(dir); err == nil && len(entries) > 0 && !*force {
+		log.Fatalf("%s is not empty, use -force to extract into it", dir)
+	}
+
+	pkg, err := h5p.NewPackageLoader().Load(file)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer pkg.Close()
+
+	switch {
+	case *contentOnly:
+		pkg.Libraries = nil
+		pkg.ExtraFiles = nil
+		pkg.Manifest = nil
+	case *librariesOnly:
+		pkg.PackageDefinition = nil
+		pkg.Content = nil
+		pkg.ContentFiles = nil
+		pkg.ExtraFiles = nil
+		pkg.Manifest = nil
+	}
+
+	var jsonFiles []string
+	err = pkg.ExtractToDirContext(context.Background(), dir, func(p h5p.Progress) {
+		if !*quiet {
+			fmt.Println(p.Name)
+		}
+		if strings.EqualFold(path.Ext(p.Name), ".json") {
+			jsonFiles = append(jsonFiles, p.Name)
+		}
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *pretty {
+		for _, name := range jsonFiles {
+			if err := prettyPrint(filepath.Join(dir, filepath.FromSlash(name))); err != nil {
+				log.Fatal(err)
+			}
+		}
+	}
+}
+
+// prettyPrint indents the JSON file at name with two spaces, keeping the
+// order of object keys, and ends it with a newline. Files that are not
+// valid JSON are left as they are.
+func prettyPrint(name string) error {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")), "", "  "); err != nil {
+		fmt.Fprintf(os.Stderr, "h5pextract: %s is not valid JSON, left unchanged\n", name)
+		return nil
+	}
+	buf.WriteByte('\n')
+	if bytes.Equal(buf.Bytes(), data) {
+		return nil
+	}
+	return os.WriteFile(name, buf.Bytes(), 0600)
+}
@@ -0,0 +1,82 @@
+// Command h5pfind lists the questions of .h5p files matching a query: text
+// they contain, their content type, how many of their answers are correct,
+// or a JSON path expression selecting a value, optionally equal to a given
+// JSON value. Given directories, it searches the .h5p files below them.
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	h5p "github.com/grokify/h5p-go"
+)
+
+func main() {
+	var q h5p.Query
+	flag.StringVar(&q.Text, "text", "", "match questions containing the text, ignoring case")
+	flag.StringVar(&q.Library, "library", "", "match questions of the content type, e.g. H5P.MultiChoice")
+	answers := flag.String("answers", "", "match questions with none, single, multiple or all answers correct")
+	flag.StringVar(&q.Path, "path", "", "match questions where the JSON path expression selects a value, e.g. params.answers[*].correct")
+	value := flag.String("value", "", "with -path, require the selected value to equal this JSON value")
+	asJSON := flag.Bool("json", false, "print the matches as JSON")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: h5pfind [-text s] [-library name] [-answers pattern] [-path expr [-value json]] [-json] file.h5p|dir...\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	q.Answers = h5p.AnswerPattern(*answers)
+	if *value != "" {
+		if q.Path == "" {
+			log.Fatal("-value requires -path")
+		}
+		if err := json.Unmarshal([]byte(*value), &q.Value); err != nil {
+			log.Fatalf("-value: %v", err)
+		}
+	}
+
+	hits := []h5p.QueryHit{}
+	for _, arg := range flag.Args() {
+		found, err := h5p.FindInDir(arg, q)
+		if err != nil {
+			log.Fatal(err)
+		}
+		hits = append(hits, found...)
+	}
+
+	if *asJSON {
+		out, err := json.MarshalIndent(hits, "", "  ")
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(string(out))
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "FILE\tPATH\tLIBRARY\tTEXT")
+	for _, h := range hits {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", h.File, cmp.Or(h.Path, "-"), h.Library, firstLine(h.Text))
+	}
+	w.Flush()
+	if len(hits) == 0 {
+		os.Exit(1)
+	}
+}
+
+// firstLine returns the first line of s, shortened to fit a table column.
+func firstLine(s string) string {
+	s, _, _ = strings.Cut(s, "\n")
+	if r := []rune(s); len(r) > 60 {
+		return string(r[:59]) + "…"
+	}
+	return s
+}
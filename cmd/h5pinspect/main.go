@@ -0,0 +1,429 @@
+// Command h5pinspect prints a summary of a .h5p file or unpacked package
+// directory: its metadata, main library, dependency tree, library versions,
+// the content types used, file counts and sizes, and validation warnings.
+package main
+
+import (
+	"archive/zip"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	h5p "github.com/grokify/h5p-go"
+)
+
+type summary struct {
+	File         string         `json:"file"`
+	Title        string         `json:"title"`
+	Language     string         `json:"language,omitempty"`
+	License      string         `json:"license,omitempty"`
+	Authors      []h5p.Author   `json:"authors,omitempty"`
+	EmbedTypes   []string       `json:"embedTypes,omitempty"`
+	MainLibrary  string         `json:"mainLibrary"`
+	Dependencies []*node        `json:"dependencies"`
+	Libraries    []libraryInfo  `json:"libraries"`
+	ContentTypes []contentCount `json:"contentTypes"`
+	Files        fileStats      `json:"files"`
+	Warnings     []string       `json:"warnings"`
+}
+
+// node is a library in the dependency tree. Libraries already shown higher
+// up are listed again without their dependencies.
+type node struct {
+	Library  string  `json:"library"`
+	Dynamic  bool    `json:"dynamic,omitempty"`
+	Missing  bool    `json:"missing,omitempty"`
+	Repeated bool    `json:"repeated,omitempty"`
+	Children []*node `json:"children,omitempty"`
+}
+
+type libraryInfo struct {
+	Folder   string `json:"folder"`
+	Title    string `json:"title,omitempty"`
+	Version  string `json:"version,omitempty"`
+	Runnable bool   `json:"runnable,omitempty"`
+	Files    int    `json:"files"`
+	Size     int64  `json:"size"`
+}
+
+type contentCount struct {
+	Library string `json:"library"`
+	Count   int    `json:"count"`
+}
+
+type sizeCount struct {
+	Count int   `json:"count"`
+	Size  int64 `json:"size"`
+}
+
+type fileStats struct {
+	Definitions sizeCount `json:"definitions"`
+	Content     sizeCount `json:"content"`
+	Libraries   sizeCount `json:"libraries"`
+	Other       sizeCount `json:"other"`
+	Total       sizeCount `json:"total"`
+	// Compressed is the size of the .h5p file, 0 for directories.
+	Compressed int64 `json:"compressed,omitempty"`
+}
+
+func main() {
+	asJSON := flag.Bool("json", false, "print the summary as JSON")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: h5pinspect [-json] file.h5p|dir\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	s, err := inspect(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *asJSON {
+		out, err := json.MarshalIndent(s, "", "  ")
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(string(out))
+		return
+	}
+	printSummary(s)
+}
+
+func inspect(name string) (*summary, error) {
+	info, err := os.Stat(name)
+	if err != nil {
+		return nil, err
+	}
+	s := &summary{File: name, Dependencies: []*node{}, Libraries: []libraryInfo{}}
+	var pkg *h5p.H5PPackage
+	var sizes map[string]int64
+	if info.IsDir() {
+		pkg, err = h5p.BuildPackageFromDir(name)
+		if err == nil {
+			sizes, err = dirSizes(name)
+		}
+	} else {
+		loader := h5p.NewPackageLoader()
+		loader.Lazy = true
+		pkg, err = loader.Load(name)
+		if err == nil {
+			sizes, err = zipSizes(name)
+			s.Files.Compressed = info.Size()
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer pkg.Close()
+
+	if def := pkg.PackageDefinition; def != nil {
+		s.Title, s.Language, s.License = def.Title, def.Language, def.License
+		s.Authors, s.EmbedTypes = def.Authors, def.EmbedTypes
+		s.MainLibrary = def.MainLibrary
+		for _, dep := range def.PreloadedDependencies {
+			if dep.MachineName == def.MainLibrary {
+				s.MainLibrary = libraryName(pkg, dep)
+			}
+		}
+		shown := map[*h5p.Library]bool{}
+		for _, dep := range def.PreloadedDependencies {
+			s.Dependencies = append(s.Dependencies, tree(pkg, dep, false, shown))
+		}
+	}
+
+	libraryFiles := map[string]*libraryInfo{}
+	for _, lib := range pkg.Libraries {
+		li := libraryInfo{Folder: lib.MachineName}
+		if d := lib.Definition; d != nil {
+			li.Title, li.Version, li.Runnable = d.Title, d.Version().String(), bool(d.Runnable)
+		}
+		s.Libraries = append(s.Libraries, li)
+	}
+	slices.SortFunc(s.Libraries, func(a, b libraryInfo) int { return strings.Compare(a.Folder, b.Folder) })
+	for i := range s.Libraries {
+		libraryFiles[s.Libraries[i].Folder] = &s.Libraries[i]
+	}
+
+	for name, size := range sizes {
+		top, _, nested := strings.Cut(name, "/")
+		var stats *sizeCount
+		switch {
+		case name == "h5p.json" || name == "content/content.json" || name == h5p.ManifestFile:
+			stats = &s.Files.Definitions
+		case top == h5p.ContentDir && nested:
+			stats = &s.Files.Content
+		case libraryFiles[top] != nil && nested:
+			stats = &s.Files.Libraries
+			libraryFiles[top].Files++
+			libraryFiles[top].Size += size
+		default:
+			stats = &s.Files.Other
+		}
+		stats.Count++
+		stats.Size += size
+		s.Files.Total.Count++
+		s.Files.Total.Size += size
+	}
+
+	s.ContentTypes = contentTypes(pkg)
+	s.Warnings = warnings(pkg)
+	return s, nil
+}
+
+// libraryName returns the library for dep as "Name Major.Minor.Patch", or
+// as the dependency if the package does not contain it.
+func libraryName(pkg *h5p.H5PPackage, dep h5p.LibraryDependency) string {
+	if lib := resolve(pkg, dep); lib != nil && lib.Definition != nil {
+		return lib.Definition.MachineName + " " + lib.Definition.Version().String()
+	}
+	return dep.String()
+}
+
+func resolve(pkg *h5p.H5PPackage, dep h5p.LibraryDependency) *h5p.Library {
+	if lib := pkg.GetLibrary(dep.MachineName, dep.MajorVersion, dep.MinorVersion); lib != nil {
+		return lib
+	}
+	return pkg.FindCompatibleLibrary(dep)
+}
+
+func tree(pkg *h5p.H5PPackage, dep h5p.LibraryDependency, dynamic bool, shown map[*h5p.Library]bool) *node {
+	n := &node{Library: libraryName(pkg, dep), Dynamic: dynamic}
+	lib := resolve(pkg, dep)
+	switch {
+	case lib == nil:
+		n.Missing = true
+		return n
+	case shown[lib]:
+		n.Repeated = true
+		return n
+	}
+	shown[lib] = true
+	if lib.Definition != nil {
+		for _, d := range lib.Definition.Dependencies {
+			n.Children = append(n.Children, tree(pkg, d, false, shown))
+		}
+		for _, d := range lib.Definition.DynamicDependencies {
+			n.Children = append(n.Children, tree(pkg, d, true, shown))
+		}
+	}
+	return n
+}
+
+// contentTypes counts the sub-content of each library in the content
+// params, plus the main content itself.
+func contentTypes(pkg *h5p.H5PPackage) []contentCount {
+	counts := map[string]int{}
+	if pkg.PackageDefinition != nil && pkg.PackageDefinition.MainLibrary != "" {
+		counts[pkg.PackageDefinition.MainLibrary]++
+	}
+	if pkg.Content != nil {
+		var params any
+		if data, err := json.Marshal(pkg.Content); err == nil && json.Unmarshal(data, &params) == nil {
+			countLibraries(params, counts)
+		}
+	}
+	result := make([]contentCount, 0, len(counts))
+	for library, n := range counts {
+		result = append(result, contentCount{Library: library, Count: n})
+	}
+	slices.SortFunc(result, func(a, b contentCount) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), strings.Compare(a.Library, b.Library))
+	})
+	return result
+}
+
+func countLibraries(v any, counts map[string]int) {
+	switch v := v.(type) {
+	case map[string]any:
+		if s, ok := v["library"].(string); ok {
+			if dep, err := h5p.ParseLibraryString(s); err == nil {
+				counts[dep.MachineName]++
+			}
+		}
+		for _, child := range v {
+			countLibraries(child, counts)
+		}
+	case []any:
+		for _, child := range v {
+			countLibraries(child, counts)
+		}
+	}
+}
+
+func warnings(pkg *h5p.H5PPackage) []string {
+	result := []string{}
+	var violations h5p.StructureErrors
+	if err := pkg.ValidateStructure(); errors.As(err, &violations) {
+		for _, v := range violations {
+			result = append(result, v.Error())
+		}
+	} else if err != nil {
+		result = append(result, err.Error())
+	}
+	var depErr *h5p.DependencyError
+	if _, err := pkg.ResolveDependencies(); errors.As(err, &depErr) {
+		for _, p := range depErr.Missing {
+			result = append(result, "missing library: "+p.String())
+		}
+		for _, p := range depErr.Mismatched {
+			result = append(result, "library version mismatch: "+p.String())
+		}
+		for _, c := range depErr.Cycles {
+			result = append(result, "dependency cycle: "+strings.Join(c, " -> "))
+		}
+	}
+	for _, a := range h5p.AuditLibraryVersions(pkg) {
+		result = append(result, a.String())
+	}
+	if pkg.Content != nil && pkg.PackageDefinition != nil {
+		for _, p := range pkg.ValidateParams(pkg.Content).Problems {
+			result = append(result, "content: "+p.Error())
+		}
+	}
+	return result
+}
+
+// zipSizes returns the uncompressed size of each archive entry.
+func zipSizes(name string) (map[string]int64, error) {
+	zr, err := zip.OpenReader(name)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	sizes := map[string]int64{}
+	for _, f := range zr.File {
+		if !f.FileInfo().IsDir() {
+			sizes[f.Name] = int64(f.UncompressedSize64)
+		}
+	}
+	return sizes, nil
+}
+
+// dirSizes returns the size of each file below dir by slash-separated
+// relative path.
+func dirSizes(dir string) (map[string]int64, error) {
+	sizes := map[string]int64{}
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		sizes[filepath.ToSlash(rel)] = info.Size()
+		return nil
+	})
+	return sizes, err
+}
+
+func printSummary(s *summary) {
+	fmt.Printf("%s\n", s.File)
+	fmt.Printf("  Title:        %s\n", s.Title)
+	if s.Language != "" {
+		fmt.Printf("  Language:     %s\n", s.Language)
+	}
+	if s.License != "" {
+		fmt.Printf("  License:      %s\n", s.License)
+	}
+	for _, a := range s.Authors {
+		fmt.Printf("  Author:       %s (%s)\n", a.Name, cmp.Or(a.Role, "Author"))
+	}
+	if len(s.EmbedTypes) > 0 {
+		fmt.Printf("  Embed types:  %s\n", strings.Join(s.EmbedTypes, ", "))
+	}
+	fmt.Printf("  Main library: %s\n", s.MainLibrary)
+
+	fmt.Println("\nDependencies:")
+	for _, n := range s.Dependencies {
+		printNode(n, "  ")
+	}
+
+	fmt.Println("\nLibraries:")
+	for _, l := range s.Libraries {
+		runnable := ""
+		if l.Runnable {
+			runnable = " (runnable)"
+		}
+		fmt.Printf("  %-40s %-10s %4d files %10s%s\n", l.Folder, l.Version, l.Files, formatSize(l.Size), runnable)
+	}
+
+	fmt.Println("\nContent types:")
+	for _, c := range s.ContentTypes {
+		fmt.Printf("  %-40s %d\n", c.Library, c.Count)
+	}
+
+	fmt.Println("\nFiles:")
+	for _, row := range []struct {
+		label string
+		stats sizeCount
+	}{
+		{"Definitions", s.Files.Definitions},
+		{"Content", s.Files.Content},
+		{"Libraries", s.Files.Libraries},
+		{"Other", s.Files.Other},
+		{"Total", s.Files.Total},
+	} {
+		fmt.Printf("  %-12s %5d files %10s\n", row.label, row.stats.Count, formatSize(row.stats.Size))
+	}
+	if s.Files.Compressed > 0 {
+		fmt.Printf("  %-12s %16s\n", "Compressed", formatSize(s.Files.Compressed))
+	}
+
+	if len(s.Warnings) > 0 {
+		fmt.Println("\nWarnings:")
+		for _, w := range s.Warnings {
+			fmt.Printf("  %s\n", w)
+		}
+	}
+}
+
+func printNode(n *node, indent string) {
+	var notes []string
+	if n.Dynamic {
+		notes = append(notes, "dynamic")
+	}
+	if n.Missing {
+		notes = append(notes, "missing")
+	}
+	if n.Repeated {
+		notes = append(notes, "see above")
+	}
+	line := indent + n.Library
+	if len(notes) > 0 {
+		line += " (" + strings.Join(notes, ", ") + ")"
+	}
+	fmt.Println(line)
+	for _, child := range n.Children {
+		printNode(child, indent+"  ")
+	}
+}
+
+func formatSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
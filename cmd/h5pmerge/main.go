@@ -0,0 +1,75 @@
+// Command h5pmerge combines question set packages into one, with the
+// questions of all inputs in order. The settings and h5p.json of the first
+// package are kept, library folders and dependencies are combined keeping
+// the newest versions, and content files whose names clash are renamed.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	h5p "github.com/grokify/h5p-go"
+)
+
+func main() {
+	output := flag.String("o", "merged.h5p", "output .h5p file")
+	title := flag.String("title", "", "title of the merged question set (default: from the first package)")
+	dedupe := flag.Bool("dedupe", false, "store byte-identical media files once")
+	multipleMajors := flag.Bool("allow-multiple-majors", false, "keep libraries with different major versions side by side instead of failing")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: h5pmerge [-o merged.h5p] [-title title] file.h5p file.h5p...\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() < 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var pkgs []*h5p.H5PPackage
+	for _, name := range flag.Args() {
+		pkg, err := h5p.NewPackageLoader().Load(name)
+		if err != nil {
+			log.Fatalf("failed to load %s: %v", name, err)
+		}
+		defer pkg.Close()
+		pkgs = append(pkgs, pkg)
+	}
+
+	merged, err := h5p.MergeQuestionSetPackages(pkgs, h5p.MergeOptions{AllowMultipleMajors: *multipleMajors})
+	if err != nil {
+		log.Fatal(err)
+	}
+	qs, err := merged.QuestionSet()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *title != "" {
+		qs.Title = *title
+		merged.PackageDefinition.Title = *title
+		merged.SetContent(&h5p.Content{Params: qs})
+	}
+	if *dedupe {
+		result, err := merged.DeduplicateFiles()
+		if err != nil {
+			log.Fatal(err)
+		}
+		if result.BytesSaved > 0 {
+			fmt.Printf("removed duplicate media, saving %d bytes\n", result.BytesSaved)
+		}
+	}
+	if _, err := merged.ResolveDependencies(); err != nil && len(merged.Libraries) > 0 {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	}
+
+	slices.SortFunc(merged.Libraries, func(a, b *h5p.Library) int { return strings.Compare(a.MachineName, b.MachineName) })
+	if err := merged.CreateZipFileWithOptions(*output, h5p.DefaultWriteOptions()); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("wrote %d question(s) from %d packages to %s\n", len(qs.Questions), len(pkgs), filepath.Clean(*output))
+}
@@ -0,0 +1,233 @@
+// Command h5ppack builds a .h5p file from an unpacked working directory, or
+// from a content JSON file and a main library, for content-as-code
+// pipelines. Missing libraries are taken from -libraries-dir, a folder of
+// library folders such as an H5P site's libraries directory, and with
+// -from-hub from the H5P Hub.
+//
+// The package is validated before it is written and the archive is
+// deterministic: entries are sorted and carry no timestamps, so unchanged
+// sources produce identical files.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	h5p "github.com/grokify/h5p-go"
+	"github.com/grokify/h5p-go/hub"
+)
+
+func main() {
+	output := flag.String("o", "", "output .h5p file (default: input name with .h5p)")
+	contentFile := flag.String("content", "", "content JSON file to pack instead of a directory")
+	h5pFile := flag.String("h5p", "", "h5p.json to use with -content")
+	library := flag.String("library", "", `main library with -content, e.g. "H5P.QuestionSet 1.20" or "H5P.QuestionSet"`)
+	title := flag.String("title", "", "title with -content (default: content file name)")
+	language := flag.String("language", "en", "language with -content")
+	librariesDir := flag.String("libraries-dir", "", "folder of library folders to take missing libraries from")
+	fromHub := flag.Bool("from-hub", false, "download missing libraries from the H5P Hub")
+	hubCache := flag.String("hub-cache", "", "H5P Hub cache directory (default: user cache directory)")
+	noValidate := flag.Bool("no-validate", false, "write the package even if validation fails or has files with disallowed extensions")
+	allowExt := flag.String("allow-ext", "", "comma-separated file extensions to allow besides the H5P defaults, e.g. \"glb,gltf\"")
+	strict := flag.Bool("strict", false, "treat validation warnings as errors")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: h5ppack [flags] dir\n")
+		fmt.Fprintf(os.Stderr, "       h5ppack [flags] -content content.json (-library name | -h5p h5p.json)\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	var pkg *h5p.H5PPackage
+	var err error
+	switch {
+	case *contentFile != "" && flag.NArg() == 0 && (*library != "" || *h5pFile != ""):
+		pkg, err = fromContent(*contentFile, *h5pFile, *library, *title, *language)
+		if *output == "" {
+			*output = strings.TrimSuffix(*contentFile, filepath.Ext(*contentFile)) + ".h5p"
+		}
+	case *contentFile == "" && flag.NArg() == 1:
+		pkg, err = h5p.BuildPackageFromDir(flag.Arg(0))
+		if *output == "" {
+			*output = filepath.Clean(flag.Arg(0)) + ".h5p"
+		}
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer pkg.Close()
+
+	if *librariesDir != "" {
+		libs, err := h5p.LoadH5PPackageFS(os.DirFS(*librariesDir))
+		if err != nil {
+			log.Fatalf("failed to load libraries: %v", err)
+		}
+		defer libs.Close()
+		result, err := h5p.MergeRequiredLibraries(pkg, libs, h5p.MergeOptions{})
+		if err != nil {
+			log.Fatal(err)
+		}
+		report("added", result.Added)
+	}
+	if *fromHub {
+		cacheDir := *hubCache
+		if cacheDir == "" {
+			if cacheDir, err = hub.DefaultCacheDir(); err != nil {
+				log.Fatal(err)
+			}
+		}
+		result, err := hub.NewClient(cacheDir).InstallDependencies(pkg)
+		report("installed", result.Added)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if ok := validate(pkg, *strict); !ok && !*noValidate {
+		log.Fatal("validation failed, use -no-validate to write the package anyway")
+	}
+
+	// Sort the libraries so the archive does not depend on load order.
+	slices.SortFunc(pkg.Libraries, func(a, b *h5p.Library) int { return strings.Compare(a.MachineName, b.MachineName) })
+	opts := h5p.DefaultWriteOptions()
+	if *allowExt != "" {
+		opts.AllowedExtensions = append(slices.Clone(opts.AllowedExtensions), strings.Split(strings.ToLower(*allowExt), ",")...)
+	}
+	if *noValidate {
+		opts.DisallowedFile = func(name string) {
+			fmt.Fprintf(os.Stderr, "warning: %s: %v\n", name, h5p.ErrFileExtension)
+		}
+	}
+	if err := pkg.CreateZipFileWithOptions(*output, opts); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Fprintf(os.Stderr, "wrote %s\n", *output)
+}
+
+// fromContent builds a package from a content JSON file and either an
+// h5p.json or a main library, listing the libraries the content uses as
+// preloaded dependencies.
+func fromContent(contentFile, h5pFile, library, title, language string) (*h5p.H5PPackage, error) {
+	data, err := os.ReadFile(contentFile)
+	if err != nil {
+		return nil, err
+	}
+	var content h5p.Content
+	if err := json.Unmarshal(data, &content); err != nil {
+		return nil, fmt.Errorf("invalid content JSON %s: %w", contentFile, err)
+	}
+
+	var def h5p.PackageDefinition
+	if h5pFile != "" {
+		data, err := os.ReadFile(h5pFile)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &def); err != nil {
+			return nil, fmt.Errorf("invalid h5p.json %s: %w", h5pFile, err)
+		}
+	} else {
+		if !strings.Contains(library, " ") {
+			latest, ok := h5p.LatestLibraryString(library)
+			if !ok {
+				return nil, fmt.Errorf("unknown library %s, give its version as in %q", library, library+" 1.0")
+			}
+			library = latest
+		}
+		main, err := h5p.ParseLibraryString(library)
+		if err != nil {
+			return nil, err
+		}
+		if title == "" {
+			title = strings.TrimSuffix(filepath.Base(contentFile), filepath.Ext(contentFile))
+		}
+		def = h5p.PackageDefinition{
+			Title:                 title,
+			Language:              language,
+			MainLibrary:           main.MachineName,
+			EmbedTypes:            []string{"iframe"},
+			License:               "U",
+			PreloadedDependencies: append([]h5p.LibraryDependency{main}, contentLibraries(content.Params)...),
+		}
+	}
+
+	pkg := h5p.NewH5PPackage()
+	pkg.SetPackageDefinition(&def)
+	pkg.SetContent(&content)
+	return pkg, nil
+}
+
+// contentLibraries returns the distinct sub-content libraries named in
+// params, in order of first use.
+func contentLibraries(params any) []h5p.LibraryDependency {
+	var deps []h5p.LibraryDependency
+	var walk func(v any)
+	walk = func(v any) {
+		switch v := v.(type) {
+		case map[string]any:
+			if s, ok := v["library"].(string); ok {
+				if dep, err := h5p.ParseLibraryString(s); err == nil && !slices.Contains(deps, dep) {
+					deps = append(deps, dep)
+				}
+			}
+			keys := make([]string, 0, len(v))
+			for k := range v {
+				keys = append(keys, k)
+			}
+			slices.Sort(keys)
+			for _, k := range keys {
+				walk(v[k])
+			}
+		case []any:
+			for _, child := range v {
+				walk(child)
+			}
+		}
+	}
+	walk(params)
+	return deps
+}
+
+// validate prints the problems found in pkg and reports whether it may be
+// written.
+func validate(pkg *h5p.H5PPackage, strict bool) bool {
+	ok := true
+	problem := func(severity h5p.Severity, msg string) {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", severity, msg)
+		if severity == h5p.SeverityError || strict {
+			ok = false
+		}
+	}
+	if err := pkg.ValidateStructure(); err != nil {
+		if violations, isList := err.(h5p.StructureErrors); isList {
+			for _, v := range violations {
+				problem(h5p.SeverityError, v.Error())
+			}
+		} else {
+			problem(h5p.SeverityError, err.Error())
+		}
+	}
+	if _, err := pkg.ResolveDependencies(); err != nil {
+		problem(h5p.SeverityError, err.Error())
+	}
+	if pkg.Content != nil {
+		for _, e := range pkg.ValidateParams(pkg.Content).Problems {
+			problem(e.Severity, "content: "+e.Error())
+		}
+	}
+	return ok
+}
+
+func report(action string, libraries []string) {
+	for _, name := range libraries {
+		fmt.Fprintf(os.Stderr, "%s %s\n", action, name)
+	}
+}
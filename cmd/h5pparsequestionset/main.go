@@ -1,57 +1,191 @@
+// Command h5pparsequestionset reads and validates question sets and
+// reports the questions of each with their answer counts. Inputs are
+// question set JSON or YAML files, or .h5p packages with H5P.QuestionSet as
+// the main library; glob patterns are expanded, so quoted patterns work
+// where the shell does not expand them.
+//
+// The report is printed as a table, or as JSON or YAML with -format. The
+// exit status is 1 if any input cannot be read or has validation errors,
+// so the command can guard scripts and CI jobs.
 package main
 
 import (
+	"encoding/json"
+	"flag"
 	"fmt"
-	"log"
 	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
 
 	h5p "github.com/grokify/h5p-go"
+	"github.com/grokify/h5p-go/internal/yamljson"
+	"github.com/grokify/h5p-go/schemas"
 )
 
+// Formats.
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatYAML  = "yaml"
+)
+
+type report struct {
+	File           string                    `json:"file"`
+	Title          string                    `json:"title,omitempty"`
+	PassPercentage int                       `json:"passPercentage,omitempty"`
+	Questions      []h5p.QuestionStats       `json:"questions"`
+	Valid          bool                      `json:"valid"`
+	Problems       []schemas.ValidationError `json:"problems,omitempty"`
+	// Error is set when the file cannot be read.
+	Error string `json:"error,omitempty"`
+}
+
 func main() {
-	args := os.Args
-	if len(args) < 2 {
-		fmt.Printf("must supply JSON filename")
-		os.Exit(1)
+	format := flag.String("format", formatTable, "output format: table, json or yaml")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: h5pparsequestionset [-format table|json|yaml] file|pattern...\n")
+		flag.PrintDefaults()
 	}
-	// Read JSON file
-	jsonData, err := os.ReadFile(os.Args[1])
-	if err != nil {
-		log.Fatal(err)
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *format != formatTable && *format != formatJSON && *format != formatYAML {
+		fmt.Fprintf(os.Stderr, "h5pparsequestionset: unknown format %q\n", *format)
+		os.Exit(2)
 	}
 
-	// Parse JSON into QuestionSet
-	questionSet, err := h5p.FromJSON(jsonData)
+	files, err := expand(flag.Args())
 	if err != nil {
-		log.Fatal(err)
+		fmt.Fprintf(os.Stderr, "h5pparsequestionset: %v\n", err)
+		os.Exit(2)
+	}
+	reports := make([]report, 0, len(files))
+	ok := true
+	for _, file := range files {
+		r := parse(file)
+		ok = ok && r.Valid
+		reports = append(reports, r)
 	}
 
-	// Validate the loaded question set
-	err = questionSet.Validate()
-	if err != nil {
-		log.Fatal(err)
+	switch *format {
+	case formatJSON:
+		out, err := json.MarshalIndent(reports, "", "  ")
+		if err == nil {
+			fmt.Println(string(out))
+		}
+		exitOnError(err)
+	case formatYAML:
+		out, err := yamljson.Marshal(reports)
+		if err == nil {
+			os.Stdout.Write(out)
+		}
+		exitOnError(err)
+	default:
+		printTable(reports)
 	}
+	if !ok {
+		os.Exit(1)
+	}
+}
 
-	// Use the question set
-	fmt.Printf("Loaded question set: %s\n", questionSet.Title)
-	fmt.Printf("Number of questions: %d\n", len(questionSet.Questions))
-	fmt.Printf("Pass percentage: %d%%\n", questionSet.PassPercentage)
+// expand returns the files matching the patterns in order. Arguments
+// without glob characters are kept as they are, so missing files are
+// reported when they are read.
+func expand(patterns []string) ([]string, error) {
+	var files []string
+	seen := map[string]bool{}
+	for _, pattern := range patterns {
+		matches := []string{pattern}
+		if strings.ContainsAny(pattern, "*?[") {
+			var err error
+			if matches, err = filepath.Glob(pattern); err != nil {
+				return nil, fmt.Errorf("invalid pattern %s: %w", pattern, err)
+			}
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("no files match %s", pattern)
+			}
+		}
+		for _, m := range matches {
+			if !seen[m] {
+				seen[m] = true
+				files = append(files, m)
+			}
+		}
+	}
+	return files, nil
+}
 
-	// Access individual questions
-	for i, question := range questionSet.Questions {
-		fmt.Printf("Question %d Library: %s\n", i+1, question.Library)
+// parse reads and validates the question set in file.
+func parse(file string) report {
+	r := report{File: file, Questions: []h5p.QuestionStats{}}
+	qs, err := read(file)
+	if err != nil {
+		r.Error = err.Error()
+		return r
+	}
+	r.Title, r.PassPercentage = qs.Title, qs.PassPercentage
+	for i := range qs.Questions {
+		r.Questions = append(r.Questions, qs.Questions[i].Stats())
+	}
+	result := qs.ValidateAll()
+	r.Valid, r.Problems = result.Valid(), result.Problems
+	return r
+}
 
-		// For MultiChoice questions, you can access params
-		if paramsMap, ok := question.Params.(map[string]interface{}); ok {
-			if questionText, exists := paramsMap["question"]; exists {
-				fmt.Printf("Question %d Text: %s\n", i+1, questionText)
-			}
+func read(file string) (*h5p.QuestionSet, error) {
+	if strings.EqualFold(filepath.Ext(file), ".h5p") {
+		pkg, err := h5p.NewPackageLoader().Load(file)
+		if err != nil {
+			return nil, err
+		}
+		defer pkg.Close()
+		return pkg.QuestionSet()
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".yaml", ".yml":
+		return h5p.FromYAML(data)
+	}
+	return h5p.FromJSON(data)
+}
 
-			if answers, exists := paramsMap["answers"]; exists {
-				if answersList, ok := answers.([]interface{}); ok {
-					fmt.Printf("Question %d has %d answers\n", i+1, len(answersList))
-				}
+func printTable(reports []report) {
+	for i, r := range reports {
+		if i > 0 {
+			fmt.Println()
+		}
+		if r.Error != "" {
+			fmt.Printf("%s: error: %s\n", r.File, r.Error)
+			continue
+		}
+		status := "valid"
+		if !r.Valid {
+			status = "INVALID"
+		}
+		fmt.Printf("%s: %q, %d question(s), pass %d%%, %s\n", r.File, r.Title, len(r.Questions), r.PassPercentage, status)
+		if len(r.Questions) > 0 {
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "  #\tLIBRARY\tANSWERS\tCORRECT\tTITLE")
+			for j, q := range r.Questions {
+				fmt.Fprintf(w, "  %d\t%s\t%d\t%d\t%s\n", j+1, q.Library, q.Answers, q.Correct, q.Title)
 			}
+			w.Flush()
+		}
+		for _, p := range r.Problems {
+			fmt.Printf("  %s: %s\n", p.Severity, p.Error())
 		}
 	}
 }
+
+func exitOnError(err error) {
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "h5pparsequestionset: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,242 @@
+// Command h5pserve previews a .h5p file or an unpacked package directory in
+// the browser. The package is extracted to a temporary directory and served
+// with the h5p-standalone player; when the source changes it is extracted
+// again and open pages reload, so edits show up as soon as they are saved.
+//
+// The player is loaded from an h5p-standalone release on jsDelivr unless
+// -player names the dist folder of a local copy, for working offline.
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	h5p "github.com/grokify/h5p-go"
+	"github.com/grokify/h5p-go/standalone"
+)
+
+// defaultPlayerURL is the dist folder of the h5p-standalone release used
+// without -player.
+const defaultPlayerURL = "https://cdn.jsdelivr.net/npm/h5p-standalone@3.6.0/dist"
+
+// eventsPath is the server-sent events endpoint pages listen on for
+// reloads.
+const eventsPath = "/_h5pserve/events"
+
+// reloadScript reconnects to the events endpoint and reloads the page when
+// a build newer than the one it shows is ready.
+const reloadScript = `<script>
+new EventSource(%q).onmessage = function () { location.reload(); };
+</script>
+`
+
+func main() {
+	addr := flag.String("addr", "localhost:8080", "address to listen on; use port 0 for any free port")
+	player := flag.String("player", "", "dist folder of a local h5p-standalone copy")
+	playerURL := flag.String("player-url", defaultPlayerURL, "URL of the h5p-standalone dist folder")
+	interval := flag.Duration("poll", 500*time.Millisecond, "how often to check the source for changes")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: h5pserve [-addr host:port] [-player dir] file.h5p|dir\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	opts := standalone.Options{PlayerURL: *playerURL, FullScreen: true}
+	if *player != "" {
+		opts.Player, opts.PlayerURL = os.DirFS(*player), ""
+	}
+	tmp, err := os.MkdirTemp("", "h5pserve-")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	s := &server{source: flag.Arg(0), tmp: tmp, opts: opts}
+	if err := s.build(); err != nil {
+		os.RemoveAll(tmp)
+		log.Fatal(err)
+	}
+
+	ln, err := net.Listen("tcp", *addr)
+	if err != nil {
+		os.RemoveAll(tmp)
+		log.Fatal(err)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	srv := &http.Server{Handler: s, BaseContext: func(net.Listener) context.Context { return ctx }}
+	go func() {
+		<-ctx.Done()
+		srv.Close()
+	}()
+	go s.watch(ctx, *interval)
+
+	fmt.Printf("previewing %s at http://%s/\n", s.source, ln.Addr())
+	if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Print(err)
+	}
+}
+
+type server struct {
+	source string
+	tmp    string
+	opts   standalone.Options
+
+	mu      sync.RWMutex
+	root    string // current build
+	version int
+}
+
+// build extracts the source into a new directory and serves it from then
+// on, so requests never see a half-written build.
+func (s *server) build() error {
+	var pkg *h5p.H5PPackage
+	var err error
+	if info, statErr := os.Stat(s.source); statErr != nil {
+		return statErr
+	} else if info.IsDir() {
+		pkg, err = h5p.BuildPackageFromDir(s.source)
+	} else {
+		pkg, err = h5p.NewPackageLoader().Load(s.source)
+	}
+	if err != nil {
+		return err
+	}
+	defer pkg.Close()
+
+	dir, err := os.MkdirTemp(s.tmp, "build-")
+	if err != nil {
+		return err
+	}
+	if err := standalone.WriteDir(pkg, dir, s.opts); err != nil {
+		os.RemoveAll(dir)
+		return err
+	}
+
+	s.mu.Lock()
+	old := s.root
+	s.root = dir
+	s.version++
+	s.mu.Unlock()
+	if old != "" {
+		os.RemoveAll(old)
+	}
+	return nil
+}
+
+func (s *server) current() (root string, version int) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.root, s.version
+}
+
+// watch rebuilds whenever the fingerprint of the source changes. Builds
+// that fail are reported and the last good one stays in place.
+func (s *server) watch(ctx context.Context, interval time.Duration) {
+	last := fingerprint(s.source)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		fp := fingerprint(s.source)
+		if fp == last {
+			continue
+		}
+		last = fp
+		if err := s.build(); err != nil {
+			fmt.Fprintf(os.Stderr, "h5pserve: %v\n", err)
+			continue
+		}
+		fmt.Printf("%s reloaded\n", time.Now().Format(time.TimeOnly))
+	}
+}
+
+// fingerprint hashes the names, sizes and modification times of the files
+// of the source.
+func fingerprint(source string) uint64 {
+	h := fnv.New64a()
+	_ = filepath.WalkDir(source, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		fmt.Fprintf(h, "%s\x00%d\x00%d\n", name, info.Size(), info.ModTime().UnixNano())
+		return nil
+	})
+	return h.Sum64()
+}
+
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	root, version := s.current()
+	w.Header().Set("Cache-Control", "no-store")
+	switch r.URL.Path {
+	case eventsPath:
+		s.events(w, r)
+	case "/", "/" + standalone.IndexFile:
+		page, err := os.ReadFile(filepath.Join(root, standalone.IndexFile))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		script := fmt.Sprintf(reloadScript, eventsPath+"?v="+strconv.Itoa(version))
+		page = bytes.Replace(page, []byte("</body>"), []byte(script+"</body>"), 1)
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(page)
+	default:
+		http.FileServer(http.Dir(root)).ServeHTTP(w, r)
+	}
+}
+
+// events sends a message once a build newer than the version the page was
+// served with is ready.
+func (s *server) events(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+	seen, _ := strconv.Atoi(r.URL.Query().Get("v"))
+	w.Header().Set("Content-Type", "text/event-stream")
+	fmt.Fprint(w, ": connected\n\n")
+	flusher.Flush()
+
+	ticker := time.NewTicker(250 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+		}
+		if _, version := s.current(); version != seen {
+			fmt.Fprint(w, "data: reload\n\n")
+			flusher.Flush()
+			return
+		}
+	}
+}
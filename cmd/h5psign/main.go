@@ -0,0 +1,324 @@
+// Command h5psign signs .h5p files and verifies their signatures, using the
+// Ed25519-signed integrity manifest (h5p-manifest.json) of the package.
+//
+//	h5psign keygen [-o h5p-signing.key]
+//	h5psign sign -key h5p-signing.key [-o signed.h5p] file.h5p
+//	h5psign verify [-pub h5p-signing.key.pub] [-json] file.h5p...
+//
+// Keys are PEM files, PKCS #8 for the private key and PKIX for the public
+// key, as written by "openssl genpkey -algorithm ed25519". Without -pub,
+// verify checks the file hashes and the signature against the key embedded
+// in the manifest, which proves integrity but not who signed; pass -pub to
+// require a trusted key. verify exits with status 1 if any package fails,
+// and -json prints a result per package for pipelines.
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	h5p "github.com/grokify/h5p-go"
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: h5psign keygen [-o file]\n")
+	fmt.Fprintf(os.Stderr, "       h5psign sign -key file [-o out.h5p] file.h5p\n")
+	fmt.Fprintf(os.Stderr, "       h5psign verify [-pub file] [-json] file.h5p...\n")
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	args := os.Args[2:]
+	switch os.Args[1] {
+	case "keygen":
+		keygen(args)
+	case "sign":
+		sign(args)
+	case "verify":
+		verify(args)
+	default:
+		usage()
+		os.Exit(2)
+	}
+}
+
+func keygen(args []string) {
+	fs := flag.NewFlagSet("keygen", flag.ExitOnError)
+	output := fs.String("o", "h5p-signing.key", "private key file; the public key is written to the same name with .pub added")
+	fs.Parse(args)
+	if fs.NArg() != 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	pub, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		log.Fatal(err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		log.Fatal(err)
+	}
+	pubDER, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := writeNew(*output, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		log.Fatal(err)
+	}
+	if err := writeNew(*output+".pub", pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}), 0644); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("wrote %s and %s.pub\n", *output, *output)
+	fmt.Printf("public key %s\n", fingerprint(pub))
+}
+
+// writeNew writes a file that must not exist yet, so keys are never
+// overwritten by accident.
+func writeNew(name string, data []byte, perm os.FileMode) error {
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func sign(args []string) {
+	fs := flag.NewFlagSet("sign", flag.ExitOnError)
+	keyFile := fs.String("key", "", "PEM private key file (required)")
+	output := fs.String("o", "", "output file (default: overwrite the input)")
+	fs.Parse(args)
+	if fs.NArg() != 1 || *keyFile == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+	input := fs.Arg(0)
+
+	key, err := readPrivateKey(*keyFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	pkg, err := h5p.NewPackageLoader().Load(input)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer pkg.Close()
+	if err := h5p.SignPackage(pkg, key); err != nil {
+		log.Fatal(err)
+	}
+
+	// Write next to the destination and rename, so a failed write leaves
+	// the input intact.
+	out := input
+	if *output != "" {
+		out = *output
+	}
+	tmp := out + ".tmp"
+	if err := pkg.CreateZipFileWithOptions(tmp, h5p.DefaultWriteOptions()); err != nil {
+		os.Remove(tmp)
+		log.Fatal(err)
+	}
+	if err := os.Rename(tmp, out); err != nil {
+		os.Remove(tmp)
+		log.Fatal(err)
+	}
+	digest, err := fileDigest(out)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("signed %s (%d files) with %s\n", out, len(pkg.Manifest.Files), fingerprint(key.Public().(ed25519.PublicKey)))
+	fmt.Printf("sha256:%s  %s\n", digest, out)
+}
+
+// result is the outcome of verifying a package.
+type result struct {
+	File string `json:"file"`
+	// Digest is the SHA-256 of the .h5p file, for pinning it.
+	Digest string `json:"sha256,omitempty"`
+	Files  int    `json:"files"`
+	Signed bool   `json:"signed"`
+	// PublicKey is the fingerprint of the key the package is signed with.
+	PublicKey string `json:"publicKey,omitempty"`
+	// Trusted reports whether the key is the one given with -pub.
+	Trusted bool   `json:"trusted"`
+	Valid   bool   `json:"valid"`
+	Error   string `json:"error,omitempty"`
+}
+
+func verify(args []string) {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	pubFile := fs.String("pub", "", "PEM public or private key file the packages must be signed with")
+	asJSON := fs.Bool("json", false, "print the results as JSON")
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	var trusted ed25519.PublicKey
+	if *pubFile != "" {
+		var err error
+		if trusted, err = readPublicKey(*pubFile); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	results := make([]result, 0, fs.NArg())
+	ok := true
+	for _, file := range fs.Args() {
+		r := verifyFile(file, trusted)
+		ok = ok && r.Valid
+		results = append(results, r)
+	}
+
+	if *asJSON {
+		out, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(string(out))
+	} else {
+		for _, r := range results {
+			switch {
+			case !r.Valid:
+				fmt.Printf("FAIL %s: %s\n", r.File, r.Error)
+			case r.Trusted:
+				fmt.Printf("OK   %s: signed with trusted key %s\n", r.File, r.PublicKey)
+			case r.Signed:
+				fmt.Printf("OK   %s: signed with untrusted key %s\n", r.File, r.PublicKey)
+			default:
+				fmt.Printf("OK   %s: unsigned, %d file hashes match\n", r.File, r.Files)
+			}
+		}
+	}
+	if !ok {
+		os.Exit(1)
+	}
+}
+
+func verifyFile(file string, trusted ed25519.PublicKey) result {
+	r := result{File: file}
+	fail := func(err error) result {
+		r.Error = err.Error()
+		return r
+	}
+	digest, err := fileDigest(file)
+	if err != nil {
+		return fail(err)
+	}
+	r.Digest = digest
+
+	m, err := h5p.VerifyPackageFile(file, nil)
+	if m != nil {
+		r.Files, r.Signed = len(m.Files), len(m.Signature) > 0
+		if len(m.PublicKey) == ed25519.PublicKeySize {
+			r.PublicKey = fingerprint(m.PublicKey)
+		}
+	}
+	if err != nil {
+		return fail(err)
+	}
+
+	key := trusted
+	if key == nil && len(m.PublicKey) == ed25519.PublicKeySize {
+		key = m.PublicKey
+	}
+	switch {
+	case key != nil:
+		if _, err := h5p.VerifyPackageFile(file, key); err != nil {
+			return fail(err)
+		}
+		r.Trusted = trusted != nil
+	case r.Signed:
+		return fail(errors.New("manifest is signed but holds no public key, use -pub"))
+	}
+	r.Valid = true
+	return r
+}
+
+func readPrivateKey(name string) (ed25519.PrivateKey, error) {
+	der, err := readPEM(name, "PRIVATE KEY")
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	ed, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%s: not an Ed25519 key", name)
+	}
+	return ed, nil
+}
+
+// readPublicKey reads a public key, or the public half of a private key.
+func readPublicKey(name string) (ed25519.PublicKey, error) {
+	if key, err := readPrivateKey(name); err == nil {
+		return key.Public().(ed25519.PublicKey), nil
+	}
+	der, err := readPEM(name, "PUBLIC KEY")
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	ed, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("%s: not an Ed25519 key", name)
+	}
+	return ed, nil
+}
+
+func readPEM(name, blockType string) ([]byte, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != blockType {
+		return nil, fmt.Errorf("%s: no PEM %s block", name, blockType)
+	}
+	return block.Bytes, nil
+}
+
+// fingerprint identifies a public key the way OpenSSH does, e.g.
+// "SHA256:uNiVztksCsDhcc0u9e8BujQXVUpKZIDTMczCvj3tD2s".
+func fingerprint(pub ed25519.PublicKey) string {
+	sum := sha256.Sum256(pub)
+	return "SHA256:" + base64.RawStdEncoding.EncodeToString(sum[:])
+}
+
+func fileDigest(name string) (string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
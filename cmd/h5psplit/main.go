@@ -0,0 +1,100 @@
+// Command h5psplit splits a question set package into several smaller
+// ones, either into parts of at most -size questions or by the tags in the
+// question metadata with -by-tag. Each part keeps the settings, h5p.json
+// and libraries of the input and the content files its questions use.
+//
+// Parts are named after the input, e.g. quiz-1.h5p or quiz-algebra.h5p;
+// questions without tags go to quiz-untagged.h5p.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	h5p "github.com/grokify/h5p-go"
+)
+
+func main() {
+	size := flag.Int("size", 0, "maximum number of questions per part")
+	byTag := flag.Bool("by-tag", false, "split by the tags of the questions")
+	outDir := flag.String("o", ".", "output directory")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: h5psplit -size n | -by-tag [-o dir] file.h5p\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 || (*size > 0) == *byTag || *size < 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	input := flag.Arg(0)
+
+	pkg, err := h5p.NewPackageLoader().Load(input)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer pkg.Close()
+	qs, err := pkg.QuestionSet()
+	if err != nil {
+		log.Fatalf("%s: %v", input, err)
+	}
+	if qs.Title == "" {
+		qs.Title = pkg.PackageDefinition.Title
+	}
+
+	var names []string
+	var parts []*h5p.QuestionSet
+	if *byTag {
+		groups := qs.GroupByTag()
+		for _, tag := range slices.Sorted(maps.Keys(groups)) {
+			name := "untagged"
+			if tag != "" {
+				name = slug(tag)
+			}
+			names = append(names, name)
+			parts = append(parts, groups[tag])
+		}
+	} else {
+		for i, part := range qs.Chunk(*size) {
+			names = append(names, strconv.Itoa(i+1))
+			parts = append(parts, part)
+		}
+	}
+
+	if err := os.MkdirAll(*outDir, 0755); err != nil {
+		log.Fatal(err)
+	}
+	slices.SortFunc(pkg.Libraries, func(a, b *h5p.Library) int { return strings.Compare(a.MachineName, b.MachineName) })
+	base := strings.TrimSuffix(filepath.Base(input), filepath.Ext(input))
+	for i, part := range parts {
+		out, err := pkg.WithQuestionSet(part)
+		if err != nil {
+			log.Fatal(err)
+		}
+		name := filepath.Join(*outDir, base+"-"+names[i]+".h5p")
+		if err := out.CreateZipFileWithOptions(name, h5p.DefaultWriteOptions()); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("wrote %d question(s) to %s\n", len(part.Questions), name)
+	}
+}
+
+// slug turns a tag into a file name part, e.g. "Linear Algebra" into
+// "linear-algebra".
+func slug(tag string) string {
+	fields := strings.FieldsFunc(strings.ToLower(tag), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(fields) == 0 {
+		return "tag"
+	}
+	return strings.Join(fields, "-")
+}
@@ -0,0 +1,192 @@
+// Command h5pstats reports content analytics for .h5p files: questions by
+// content type, answers per question, word counts, an estimate of the time
+// to complete the content, the Flesch-Kincaid grade level of the text,
+// media file sizes and the licenses used. Given several files or
+// directories, which are searched for .h5p files, it prints a line per
+// package followed by the totals. With -sections it also prints a line per
+// question or other part of each package.
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	h5p "github.com/grokify/h5p-go"
+)
+
+type packageStats struct {
+	File string `json:"file"`
+	*h5p.ContentStats
+}
+
+type report struct {
+	Packages []packageStats    `json:"packages"`
+	Total    *h5p.ContentStats `json:"total"`
+}
+
+func main() {
+	asJSON := flag.Bool("json", false, "print the stats as JSON")
+	sections := flag.Bool("sections", false, "print the stats of each question or other part of the content")
+	var opts h5p.StatsOptions
+	flag.IntVar(&opts.WordsPerMinute, "wpm", 200, "reading speed in words per minute for the time estimate")
+	flag.DurationVar(&opts.AnswerTime, "answer-time", 5*time.Second, "time to consider each answer for the time estimate")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: h5pstats [-json] [-sections] [-wpm n] [-answer-time d] file.h5p|dir...\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var files []string
+	for _, arg := range flag.Args() {
+		found, err := inputFiles(arg)
+		if err != nil {
+			log.Fatal(err)
+		}
+		files = append(files, found...)
+	}
+
+	r := report{Total: h5p.NewContentStats()}
+	for _, file := range files {
+		s, err := stats(file, opts)
+		if err != nil {
+			log.Fatalf("%s: %v", file, err)
+		}
+		r.Packages = append(r.Packages, packageStats{File: file, ContentStats: s})
+		r.Total.Add(s)
+	}
+
+	if *asJSON {
+		out, err := json.MarshalIndent(r, "", "  ")
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(string(out))
+		return
+	}
+	printReport(r)
+	if *sections {
+		printSections(r)
+	}
+}
+
+// inputFiles returns name if it is a file, or the .h5p files below it if
+// it is a directory.
+func inputFiles(name string) ([]string, error) {
+	info, err := os.Stat(name)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{name}, nil
+	}
+	var files []string
+	err = filepath.WalkDir(name, func(p string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() && strings.EqualFold(filepath.Ext(p), ".h5p") {
+			files = append(files, p)
+		}
+		return err
+	})
+	if err == nil && len(files) == 0 {
+		err = fmt.Errorf("no .h5p files in %s", name)
+	}
+	return files, err
+}
+
+func stats(file string, opts h5p.StatsOptions) (*h5p.ContentStats, error) {
+	loader := h5p.NewPackageLoader()
+	loader.Lazy = true
+	pkg, err := loader.Load(file)
+	if err != nil {
+		return nil, err
+	}
+	defer pkg.Close()
+	return pkg.Stats(opts)
+}
+
+func printReport(r report) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "FILE\tQUESTIONS\tANSWERS/Q\tWORDS\tTIME\tGRADE\tMEDIA")
+	for _, p := range r.Packages {
+		fmt.Fprintf(w, "%s\t%d\t%.1f\t%d\t%s\t%s\t%s\n", p.File, p.Questions, p.AverageAnswers(), p.Words, p.EstimatedTime(), grade(p.Readability), size(p.Media.Size))
+	}
+	if len(r.Packages) > 1 {
+		t := r.Total
+		fmt.Fprintf(w, "total\t%d\t%.1f\t%d\t%s\t%s\t%s\n", t.Questions, t.AverageAnswers(), t.Words, t.EstimatedTime(), grade(t.Readability), size(t.Media.Size))
+	}
+	w.Flush()
+
+	t := r.Total
+	if len(t.QuestionsByLibrary) > 0 {
+		fmt.Println("\nQuestions by content type:")
+		printCounts(t.QuestionsByLibrary)
+	}
+	if t.Media.Files > 0 {
+		fmt.Printf("\nMedia: %d file(s), %s\n", t.Media.Files, size(t.Media.Size))
+		for _, kind := range slices.Sorted(maps.Keys(t.Media.ByType)) {
+			c := t.Media.ByType[kind]
+			fmt.Printf("  %-8s %4d file(s) %10s\n", kind, c.Files, size(c.Size))
+		}
+	}
+	if len(t.Licenses) > 0 {
+		fmt.Println("\nLicenses:")
+		printCounts(t.Licenses)
+	}
+}
+
+// printSections prints a line per part of the content of each package.
+func printSections(r report) {
+	fmt.Println()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "FILE\tPART\tLIBRARY\tANSWERS\tWORDS\tTIME\tGRADE\tEASE")
+	for _, p := range r.Packages {
+		for _, sec := range p.Sections {
+			part := cmp.Or(sec.Path, "-")
+			minutes := time.Duration(sec.EstimatedMinutes * float64(time.Minute)).Round(time.Second)
+			fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%s\t%s\t%.1f\n", p.File, part, cmp.Or(sec.Library, "-"), sec.Answers, sec.Readability.Words, minutes, grade(sec.Readability), sec.Readability.ReadingEase)
+		}
+	}
+	w.Flush()
+}
+
+// grade formats the Flesch-Kincaid grade level, or "-" without text.
+func grade(r h5p.Readability) string {
+	if r.Words == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f", r.GradeLevel)
+}
+
+// printCounts prints counts from the largest down, then by name.
+func printCounts(counts map[string]int) {
+	names := slices.SortedFunc(maps.Keys(counts), func(a, b string) int {
+		return cmp.Or(cmp.Compare(counts[b], counts[a]), strings.Compare(a, b))
+	})
+	for _, name := range names {
+		fmt.Printf("  %4d  %s\n", counts[name], name)
+	}
+}
+
+func size(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MiB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KiB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
@@ -0,0 +1,178 @@
+// Command h5ptranslate helps translating the content of .h5p files.
+//
+// With -extract it prints the translatable strings of a package as JSON,
+// a list of {"key", "text", ...} objects. Translators replace the texts,
+// and -apply writes a copy of the package with them to the -o file.
+//
+// With -report it checks translations. Given a package and its
+// translation it lists the strings of the package the translation lacks
+// or leaves as they are; given a package alone, it reports how complete
+// the -lang language files of its libraries are.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	h5p "github.com/grokify/h5p-go"
+	"github.com/grokify/h5p-go/semantics"
+)
+
+type libraryReport struct {
+	Library string `json:"library"`
+	*h5p.TranslationReport
+}
+
+func main() {
+	extract := flag.Bool("extract", false, "print the translatable strings as JSON")
+	apply := flag.String("apply", "", "write the package with the translated strings of `file`, as printed by -extract")
+	report := flag.Bool("report", false, "report untranslated strings of a translated package, or of library language files")
+	lang := flag.String("lang", "", "language of the translation")
+	output := flag.String("o", "", "output file for -apply")
+	asJSON := flag.Bool("json", false, "print the report as JSON")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: h5ptranslate -extract file.h5p\n")
+		fmt.Fprintf(os.Stderr, "       h5ptranslate -apply strings.json -o out.h5p [-lang code] file.h5p\n")
+		fmt.Fprintf(os.Stderr, "       h5ptranslate -report [-json] file.h5p translated.h5p\n")
+		fmt.Fprintf(os.Stderr, "       h5ptranslate -report -lang code [-json] file.h5p\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	modes := 0
+	for _, set := range []bool{*extract, *apply != "", *report} {
+		if set {
+			modes++
+		}
+	}
+	switch {
+	case modes != 1,
+		*extract && flag.NArg() != 1,
+		*apply != "" && (flag.NArg() != 1 || *output == ""),
+		*report && (flag.NArg() < 1 || flag.NArg() > 2 || (flag.NArg() == 1 && *lang == "")):
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	pkg := load(flag.Arg(0))
+	switch {
+	case *extract:
+		strs, err := pkg.ExtractStrings()
+		if err != nil {
+			log.Fatal(err)
+		}
+		printJSON(strs)
+
+	case *apply != "":
+		translations, err := readStrings(*apply)
+		if err != nil {
+			log.Fatal(err)
+		}
+		params, err := pkg.ApplyStrings(translations)
+		if err != nil {
+			log.Fatal(err)
+		}
+		pkg.SetContent(&h5p.Content{Params: params})
+		if *lang != "" {
+			pkg.PackageDefinition.Language = *lang
+		}
+		if err := pkg.CreateZipFile(*output); err != nil {
+			log.Fatal(err)
+		}
+
+	case flag.NArg() == 2:
+		r, err := pkg.ContentTranslationReport(load(flag.Arg(1)))
+		if err != nil {
+			log.Fatal(err)
+		}
+		if *asJSON {
+			printJSON(r)
+			return
+		}
+		fmt.Printf("%d of %d strings translated (%.0f%%)\n", r.Translated(), r.Total, r.Percent())
+		for _, key := range r.Missing {
+			fmt.Printf("missing: %s\n", key)
+		}
+		for _, key := range r.Untranslated {
+			fmt.Printf("untranslated: %s\n", key)
+		}
+
+	default:
+		reports, err := libraryReports(pkg, *lang)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if *asJSON {
+			printJSON(reports)
+			return
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "LIBRARY\tTEXTS\tMISSING\tUNTRANSLATED\tTRANSLATED")
+		for _, r := range reports {
+			fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%.0f%%\n", r.Library, r.Total, len(r.Missing), len(r.Untranslated), r.Percent())
+		}
+		w.Flush()
+	}
+}
+
+func load(file string) *h5p.H5PPackage {
+	pkg, err := h5p.LoadH5PPackage(file)
+	if err != nil {
+		log.Fatalf("%s: %v", file, err)
+	}
+	return pkg
+}
+
+// readStrings reads the strings printed by -extract, or a JSON object
+// mapping keys to texts.
+func readStrings(file string) (map[string]string, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	translations := map[string]string{}
+	var strs []semantics.TranslatableString
+	if err := json.Unmarshal(data, &strs); err == nil {
+		for _, s := range strs {
+			translations[s.Key] = s.Text
+		}
+		return translations, nil
+	}
+	if err := json.Unmarshal(data, &translations); err != nil {
+		return nil, fmt.Errorf("%s: expected the output of -extract or an object of texts: %w", file, err)
+	}
+	return translations, nil
+}
+
+// libraryReports reports on the language files of lang of the libraries
+// with semantics, sorted by folder name.
+func libraryReports(pkg *h5p.H5PPackage, lang string) ([]libraryReport, error) {
+	libs := slices.Clone(pkg.Libraries)
+	slices.SortFunc(libs, func(a, b *h5p.Library) int { return strings.Compare(a.MachineName, b.MachineName) })
+	var reports []libraryReport
+	for _, lib := range libs {
+		if lib.Semantics == nil {
+			continue
+		}
+		r, err := h5p.LibraryTranslationReport(lib, lang)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", lib.MachineName, err)
+		}
+		reports = append(reports, libraryReport{Library: lib.MachineName, TranslationReport: r})
+	}
+	return reports, nil
+}
+
+func printJSON(v any) {
+	out, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(string(out))
+}
@@ -0,0 +1,222 @@
+// Command h5pupgrade migrates .h5p files to newer content type library
+// versions: the content params are run through the content upgrades
+// between the old and the target versions and the library references in
+// the content and h5p.json are raised. Given a directory, it upgrades every
+// .h5p file in it and its subdirectories.
+//
+// Targets default to the newest known versions and can be set per library
+// with -target. With -dry-run the changes are printed as a diff and no file
+// is written. The new library versions can be added with -libraries-dir or
+// -from-hub as in h5ppack; library folders of the old versions are kept.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	h5p "github.com/grokify/h5p-go"
+	"github.com/grokify/h5p-go/hub"
+)
+
+// targets collects -target flags.
+type targets map[string]h5p.LibraryVersion
+
+func (t targets) String() string {
+	var s []string
+	for name, v := range t {
+		s = append(s, fmt.Sprintf("%s %d.%d", name, v.Major, v.Minor))
+	}
+	slices.Sort(s)
+	return strings.Join(s, ", ")
+}
+
+func (t targets) Set(s string) error {
+	dep, err := h5p.ParseLibraryString(s)
+	if err != nil {
+		return err
+	}
+	t[dep.MachineName] = h5p.LibraryVersion{Major: dep.MajorVersion, Minor: dep.MinorVersion}
+	return nil
+}
+
+type options struct {
+	dryRun       bool
+	output       string
+	librariesDir string
+	fromHub      bool
+	hubCache     string
+}
+
+func main() {
+	target := targets{}
+	var opts options
+	flag.Var(target, "target", `target version of a library, e.g. "H5P.MultiChoice 1.16" (repeatable)`)
+	flag.BoolVar(&opts.dryRun, "dry-run", false, "print the changes without writing files")
+	flag.StringVar(&opts.output, "o", "", "output file for a single input (default: overwrite the input)")
+	flag.StringVar(&opts.librariesDir, "libraries-dir", "", "folder of library folders to take the new library versions from")
+	flag.BoolVar(&opts.fromHub, "from-hub", false, "download the new library versions from the H5P Hub")
+	flag.StringVar(&opts.hubCache, "hub-cache", "", "H5P Hub cache directory (default: user cache directory)")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: h5pupgrade [-target \"Name Major.Minor\"]... [-dry-run] [-o out.h5p] file.h5p|dir\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	files, err := inputFiles(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	if opts.output != "" && (len(files) != 1 || files[0] != flag.Arg(0)) {
+		log.Fatal("-o can only be used with a single .h5p file")
+	}
+
+	var libs *h5p.H5PPackage
+	if opts.librariesDir != "" {
+		if libs, err = h5p.LoadH5PPackageFS(os.DirFS(opts.librariesDir)); err != nil {
+			log.Fatalf("failed to load libraries: %v", err)
+		}
+		defer libs.Close()
+	}
+
+	failed := false
+	for _, file := range files {
+		if err := upgrade(file, h5p.UpgradeOptions{Targets: target}, libs, opts); err != nil {
+			fmt.Fprintf(os.Stderr, "h5pupgrade: %s: %v\n", file, err)
+			failed = true
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// inputFiles returns name if it is a file, or the .h5p files below it if
+// it is a directory.
+func inputFiles(name string) ([]string, error) {
+	info, err := os.Stat(name)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{name}, nil
+	}
+	var files []string
+	err = filepath.WalkDir(name, func(p string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() && strings.EqualFold(filepath.Ext(p), ".h5p") {
+			files = append(files, p)
+		}
+		return err
+	})
+	if err == nil && len(files) == 0 {
+		err = fmt.Errorf("no .h5p files in %s", name)
+	}
+	return files, err
+}
+
+func upgrade(file string, upgradeOpts h5p.UpgradeOptions, libs *h5p.H5PPackage, opts options) error {
+	orig, err := h5p.NewPackageLoader().Load(file)
+	if err != nil {
+		return err
+	}
+	defer orig.Close()
+
+	pkg := orig.Clone()
+	changes, err := pkg.UpgradeContent(upgradeOpts)
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		fmt.Printf("%s: up to date\n", file)
+		return nil
+	}
+
+	fmt.Printf("%s:\n", file)
+	for _, c := range changes {
+		fmt.Printf("  %s\n", c)
+	}
+	if opts.dryRun {
+		d, err := h5p.DiffPackages(orig, pkg)
+		if err != nil {
+			return err
+		}
+		printValues("h5p.json", d.Metadata)
+		printValues("Content", d.Content)
+		return nil
+	}
+
+	if libs != nil {
+		result, err := h5p.MergeRequiredLibraries(pkg, libs, h5p.MergeOptions{})
+		if err != nil {
+			return err
+		}
+		report("added", result.Added)
+	}
+	if opts.fromHub {
+		cacheDir := opts.hubCache
+		if cacheDir == "" {
+			if cacheDir, err = hub.DefaultCacheDir(); err != nil {
+				return err
+			}
+		}
+		result, err := hub.NewClient(cacheDir).InstallDependencies(pkg)
+		report("installed", result.Added)
+		if err != nil {
+			return err
+		}
+	}
+	if _, err := pkg.ResolveDependencies(); err != nil && len(pkg.Libraries) > 0 {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	}
+
+	// Write next to the destination and rename, so a failed write leaves
+	// the input intact.
+	output := file
+	if opts.output != "" {
+		output = opts.output
+	}
+	tmp := output + ".tmp"
+	slices.SortFunc(pkg.Libraries, func(a, b *h5p.Library) int { return strings.Compare(a.MachineName, b.MachineName) })
+	if err := pkg.CreateZipFileWithOptions(tmp, h5p.DefaultWriteOptions()); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, output); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	fmt.Printf("  wrote %s\n", output)
+	return nil
+}
+
+func printValues(title string, changes []h5p.ValueChange) {
+	if len(changes) == 0 {
+		return
+	}
+	fmt.Printf("  %s:\n", title)
+	for _, c := range changes {
+		switch c.Change {
+		case h5p.ChangeAdded:
+			fmt.Printf("    + %s: %v\n", c.Path, c.New)
+		case h5p.ChangeRemoved:
+			fmt.Printf("    - %s: %v\n", c.Path, c.Old)
+		default:
+			fmt.Printf("    ~ %s: %v -> %v\n", c.Path, c.Old, c.New)
+		}
+	}
+}
+
+func report(action string, libraries []string) {
+	for _, name := range libraries {
+		fmt.Fprintf(os.Stderr, "  %s %s\n", action, name)
+	}
+}
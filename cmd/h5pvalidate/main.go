@@ -0,0 +1,228 @@
+// Command h5pvalidate checks .h5p files and unpacked package directories
+// against the H5P package structure rules, the semantics of the main
+// library and the typed params of known content types.
+//
+// It exits with status 0 when every package is valid, 1 when problems were
+// found and 2 on usage errors or unreadable input, so it can gate CI jobs.
+// With -profile strict, warnings and quality rules fail validation too.
+package main
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+
+	h5p "github.com/grokify/h5p-go"
+	"github.com/grokify/h5p-go/schemas"
+)
+
+// Checks reported in the output.
+const (
+	checkLoad      = "load"
+	checkStructure = "structure"
+	checkSemantics = "semantics"
+	checkParams    = "params"
+)
+
+type problem struct {
+	Check    string       `json:"check"`
+	Path     string       `json:"path,omitempty"`
+	Code     string       `json:"code,omitempty"`
+	Message  string       `json:"message"`
+	Severity h5p.Severity `json:"severity"`
+}
+
+type report struct {
+	File     string    `json:"file"`
+	Valid    bool      `json:"valid"`
+	Problems []problem `json:"problems"`
+}
+
+func main() {
+	format := flag.String("format", "text", "output format: text or json")
+	profileName := flag.String("profile", "lenient", "validation profile: strict or lenient")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: h5pvalidate [-format text|json] [-profile strict|lenient] file.h5p|dir ...\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 || (*format != "text" && *format != "json") {
+		flag.Usage()
+		os.Exit(2)
+	}
+	var profile h5p.ValidationProfile
+	switch *profileName {
+	case "strict":
+		profile = h5p.StrictProfile()
+	case "lenient":
+		profile = h5p.LenientProfile()
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+	strict := *profileName == "strict"
+
+	reports := make([]report, 0, flag.NArg())
+	valid := true
+	for _, name := range flag.Args() {
+		rep, err := validate(name, profile, strict)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "h5pvalidate: %v\n", err)
+			os.Exit(2)
+		}
+		valid = valid && rep.Valid
+		reports = append(reports, rep)
+	}
+
+	if *format == "json" {
+		out, err := json.MarshalIndent(reports, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "h5pvalidate: %v\n", err)
+			os.Exit(2)
+		}
+		fmt.Println(string(out))
+	} else {
+		for _, rep := range reports {
+			printReport(rep)
+		}
+	}
+	if !valid {
+		os.Exit(1)
+	}
+}
+
+// validate checks the package at name. Errors are returned only for input
+// that cannot be opened at all; broken packages are reported as problems.
+func validate(name string, profile h5p.ValidationProfile, strict bool) (report, error) {
+	rep := report{File: name, Problems: []problem{}}
+	info, err := os.Stat(name)
+	if err != nil {
+		return rep, err
+	}
+
+	var pkg *h5p.H5PPackage
+	var fsys fs.FS
+	if info.IsDir() {
+		fsys = os.DirFS(name)
+		pkg, err = h5p.BuildPackageFromDir(name)
+	} else {
+		zr, zerr := zip.OpenReader(name)
+		if zerr != nil {
+			rep.add(problem{Check: checkLoad, Message: zerr.Error(), Severity: h5p.SeverityError})
+			return rep, nil
+		}
+		defer zr.Close()
+		fsys = zr
+		pkg, err = h5p.LoadH5PPackage(name)
+	}
+	if err != nil {
+		rep.add(problem{Check: checkLoad, Message: err.Error(), Severity: h5p.SeverityError})
+		return rep, nil
+	}
+	defer pkg.Close()
+
+	if err := pkg.ValidateStructure(); err != nil {
+		if violations, ok := err.(h5p.StructureErrors); ok {
+			for _, v := range violations {
+				rep.add(problem{Check: checkStructure, Path: v.Path, Message: v.Message, Severity: h5p.SeverityError})
+			}
+		} else {
+			rep.add(problem{Check: checkStructure, Message: err.Error(), Severity: h5p.SeverityError})
+		}
+	}
+
+	data, err := fs.ReadFile(fsys, "content/content.json")
+	if err == nil {
+		var params any
+		if err := json.Unmarshal(data, &params); err != nil {
+			rep.add(problem{Check: checkParams, Path: "content/content.json", Message: err.Error(), Severity: h5p.SeverityError})
+		} else {
+			rep.addResult(checkSemantics, pkg.ValidateParams(params))
+			if r := validateParams(pkg, data, profile); r != nil {
+				rep.addResult(checkParams, r)
+			}
+		}
+	}
+
+	rep.Valid = true
+	for _, p := range rep.Problems {
+		if p.Severity == h5p.SeverityError || strict {
+			rep.Valid = false
+		}
+	}
+	return rep, nil
+}
+
+// validateParams runs the typed validation of the main library's params,
+// nil for content types without typed params.
+func validateParams(pkg *h5p.H5PPackage, data []byte, profile h5p.ValidationProfile) *h5p.ValidationResult {
+	if pkg.PackageDefinition == nil {
+		return nil
+	}
+	invalid := func(err error) *h5p.ValidationResult {
+		r := &h5p.ValidationResult{}
+		r.AddError("", schemas.CodeInvalidValue, "%v", err)
+		return r
+	}
+	switch pkg.PackageDefinition.MainLibrary {
+	case "H5P.QuestionSet":
+		qs, err := h5p.FromJSON(data)
+		if err != nil {
+			return invalid(err)
+		}
+		return qs.ValidateWithProfile(profile)
+	case "H5P.MultiChoice":
+		var p schemas.MultiChoiceParams
+		if err := json.Unmarshal(data, &p); err != nil {
+			return invalid(err)
+		}
+		return p.ValidateAll()
+	case "H5P.TrueFalse":
+		var p schemas.TrueFalseParams
+		if err := json.Unmarshal(data, &p); err != nil {
+			return invalid(err)
+		}
+		return p.ValidateAll()
+	}
+	return nil
+}
+
+func (rep *report) add(p problem) {
+	rep.Problems = append(rep.Problems, p)
+}
+
+func (rep *report) addResult(check string, r *h5p.ValidationResult) {
+	for _, e := range r.Problems {
+		rep.add(problem{Check: check, Path: e.Path, Code: e.Code, Message: e.Message, Severity: e.Severity})
+	}
+}
+
+func printReport(rep report) {
+	var errs, warnings int
+	for _, p := range rep.Problems {
+		if p.Severity == h5p.SeverityError {
+			errs++
+		} else {
+			warnings++
+		}
+	}
+	switch {
+	case len(rep.Problems) == 0:
+		fmt.Printf("%s: ok\n", rep.File)
+		return
+	case rep.Valid:
+		fmt.Printf("%s: ok, %d warning(s)\n", rep.File, warnings)
+	default:
+		fmt.Printf("%s: %d error(s), %d warning(s)\n", rep.File, errs, warnings)
+	}
+	for _, p := range rep.Problems {
+		location := p.Message
+		if p.Path != "" {
+			location = p.Path + ": " + p.Message
+		}
+		fmt.Printf("  %-7s %-9s %s\n", p.Severity, p.Check, location)
+	}
+}
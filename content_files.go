@@ -0,0 +1,129 @@
+package h5p
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ContentDir is the package folder holding content.json and its assets.
+const ContentDir = "content"
+
+// ContentFile is an asset stored in the package content folder, such as an
+// image referenced from content params. It is also used for
+// H5PPackage.ExtraFiles.
+type ContentFile struct {
+	Data []byte
+	Mime string
+
+	open FileOpener
+}
+
+// Open returns a reader for the file contents.
+func (cf *ContentFile) Open() (io.ReadCloser, error) {
+	if cf.Data == nil && cf.open != nil {
+		return cf.open()
+	}
+	return io.NopCloser(bytes.NewReader(cf.Data)), nil
+}
+
+// Bytes returns the file contents, reading lazily loaded files into memory.
+func (cf *ContentFile) Bytes() ([]byte, error) {
+	if cf.Data == nil && cf.open != nil {
+		rc, err := cf.open()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		return io.ReadAll(rc)
+	}
+	return cf.Data, nil
+}
+
+// AddContentAsset stores a file in the content folder. The path is relative
+// to the content folder, e.g. "images/photo.jpg", which is also how content
+// params reference it. If mimeType is empty it is derived from the extension.
+func (pkg *H5PPackage) AddContentAsset(assetPath string, data []byte, mimeType string) error {
+	return pkg.addContentFile(assetPath, &ContentFile{Data: data, Mime: mimeType})
+}
+
+// AddContentAssetFile is AddContentAsset for a file on disk. The file is not
+// read until the package is written, so large media such as videos are
+// streamed into the archive rather than buffered.
+func (pkg *H5PPackage) AddContentAssetFile(assetPath, srcPath, mimeType string) error {
+	info, err := os.Stat(srcPath)
+	if err != nil {
+		return fmt.Errorf("failed to open content asset: %w", err)
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("content asset is not a regular file: %s", srcPath)
+	}
+	return pkg.AddContentAssetOpener(assetPath, func() (io.ReadCloser, error) {
+		return os.Open(srcPath)
+	}, mimeType)
+}
+
+// AddContentAssetOpener is AddContentAsset for contents opened on demand,
+// each time the package is written or the file is read.
+func (pkg *H5PPackage) AddContentAssetOpener(assetPath string, open FileOpener, mimeType string) error {
+	return pkg.addContentFile(assetPath, &ContentFile{Mime: mimeType, open: open})
+}
+
+func (pkg *H5PPackage) addContentFile(assetPath string, cf *ContentFile) error {
+	assetPath = path.Clean(strings.TrimPrefix(assetPath, ContentDir+"/"))
+	if !filepath.IsLocal(filepath.FromSlash(assetPath)) || strings.Contains(assetPath, `\`) {
+		return fmt.Errorf("invalid content asset path: %s", assetPath)
+	}
+	if assetPath == "content.json" {
+		return fmt.Errorf("content.json cannot be added as an asset")
+	}
+	if !allowedExtension(assetPath, AllowedContentFileExtensions) {
+		return fmt.Errorf("file extension not allowed for content asset: %s", assetPath)
+	}
+	if cf.Mime == "" {
+		cf.Mime = mimeTypeByPath(assetPath)
+	}
+
+	if pkg.ContentFiles == nil {
+		pkg.ContentFiles = make(map[string]*ContentFile)
+	}
+	pkg.ContentFiles[assetPath] = cf
+	return nil
+}
+
+// ContentFileNames returns the sorted paths of all content assets.
+func (pkg *H5PPackage) ContentFileNames() []string {
+	return sortedFileNames(pkg.ContentFiles)
+}
+
+func sortedFileNames(files map[string]*ContentFile) []string {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// contentFilePath returns the path relative to the content folder if name
+// is a content asset archive path.
+func contentFilePath(name string) (string, bool) {
+	if name == ContentDir+"/content.json" || !strings.HasPrefix(name, ContentDir+"/") {
+		return "", false
+	}
+	return strings.TrimPrefix(name, ContentDir+"/"), true
+}
+
+func mimeTypeByPath(name string) string {
+	mimeType := mime.TypeByExtension(path.Ext(name))
+	if i := strings.Index(mimeType, ";"); i >= 0 {
+		mimeType = mimeType[:i]
+	}
+	return mimeType
+}
@@ -0,0 +1,102 @@
+package h5p
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestContentAssetsRoundTrip(t *testing.T) {
+	pkg := loadTestPackage(t)
+
+	image := []byte("\x89PNG fake image data")
+	if err := pkg.AddContentAsset("images/capital.png", image, ""); err != nil {
+		t.Fatalf("Failed to add content asset: %v", err)
+	}
+	if err := pkg.AddContentAsset("content/audio/intro.mp3", []byte("mp3"), "audio/mpeg"); err != nil {
+		t.Fatalf("Failed to add content asset: %v", err)
+	}
+	if got := pkg.ContentFiles["images/capital.png"].Mime; got != "image/png" {
+		t.Errorf("Expected mime 'image/png', got '%s'", got)
+	}
+
+	var buf bytes.Buffer
+	if _, err := pkg.WriteTo(&buf); err != nil {
+		t.Fatalf("Failed to write package: %v", err)
+	}
+
+	for _, lazy := range []bool{false, true} {
+		loader := NewPackageLoader()
+		loader.Lazy = lazy
+		loaded, err := loader.LoadReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		if err != nil {
+			t.Fatalf("Failed to load package (lazy=%v): %v", lazy, err)
+		}
+		if loaded.Content == nil {
+			t.Errorf("content.json not loaded (lazy=%v)", lazy)
+		}
+		names := loaded.ContentFileNames()
+		if len(names) != 2 || names[0] != "audio/intro.mp3" || names[1] != "images/capital.png" {
+			t.Fatalf("Unexpected content files (lazy=%v): %v", lazy, names)
+		}
+		data, err := loaded.ContentFiles["images/capital.png"].Bytes()
+		if err != nil {
+			t.Fatalf("Failed to read content asset (lazy=%v): %v", lazy, err)
+		}
+		if !bytes.Equal(data, image) {
+			t.Errorf("Content asset changed after round-trip (lazy=%v)", lazy)
+		}
+		if err := loaded.ValidateStructure(); err != nil {
+			t.Errorf("Package with assets failed structure validation: %v", err)
+		}
+	}
+}
+
+func TestAddContentAssetRejectsInvalidPaths(t *testing.T) {
+	pkg := NewH5PPackage()
+	for _, p := range []string{"../escape.png", "/abs/file.png", "content.json", "images/run.exe", `images\x.png`} {
+		if err := pkg.AddContentAsset(p, []byte("x"), ""); err == nil {
+			t.Errorf("Expected error adding content asset %q", p)
+		}
+	}
+}
+
+func TestAddContentAssetFileStreams(t *testing.T) {
+	srcPath := filepath.Join(t.TempDir(), "lecture.mp4")
+	if err := os.WriteFile(srcPath, []byte("first"), 0600); err != nil {
+		t.Fatalf("Failed to write source file: %v", err)
+	}
+
+	pkg := loadTestPackage(t)
+	if err := pkg.AddContentAssetFile("videos/lecture.mp4", srcPath, ""); err != nil {
+		t.Fatalf("Failed to add content asset file: %v", err)
+	}
+	if got := pkg.ContentFiles["videos/lecture.mp4"].Mime; got != "video/mp4" {
+		t.Errorf("Expected mime 'video/mp4', got '%s'", got)
+	}
+
+	// The file is read when the package is written, not when it is added.
+	if err := os.WriteFile(srcPath, []byte("second"), 0600); err != nil {
+		t.Fatalf("Failed to rewrite source file: %v", err)
+	}
+	var buf bytes.Buffer
+	if _, err := pkg.WriteTo(&buf); err != nil {
+		t.Fatalf("Failed to write package: %v", err)
+	}
+	loaded, err := LoadH5PPackageFromReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("Failed to load package: %v", err)
+	}
+	data, err := loaded.ContentFiles["videos/lecture.mp4"].Bytes()
+	if err != nil {
+		t.Fatalf("Failed to read content asset: %v", err)
+	}
+	if string(data) != "second" {
+		t.Errorf("Expected streamed contents 'second', got '%s'", data)
+	}
+
+	if err := pkg.AddContentAssetFile("videos/missing.mp4", filepath.Join(t.TempDir(), "missing.mp4"), ""); err == nil {
+		t.Error("Expected error adding missing file")
+	}
+}
@@ -0,0 +1,274 @@
+package h5p
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/grokify/h5p-go/schemas"
+)
+
+// ErrUpgrade reports content that cannot be upgraded.
+var ErrUpgrade = errors.New("content upgrade failed")
+
+// ContentUpgrade migrates the params of a library's content written for an
+// older version to Version. It is the Go counterpart of an entry in the
+// upgrades.js shipped by the library, and like those entries it changes
+// params in place.
+type ContentUpgrade struct {
+	Version LibraryVersion
+	Upgrade func(params map[string]any) error
+}
+
+// ContentUpgrades lists the params migrations of content type libraries by
+// machine name, in version order. Versions without an entry need no
+// migration beyond the new version number. Callers may add migrations for
+// other libraries. The built-in migrations only touch params still in the
+// old form, so content that was partly migrated by hand is left intact.
+var ContentUpgrades = map[string][]ContentUpgrade{
+	"H5P.MultiChoice": {
+		{Version: LibraryVersion{Major: 1, Minor: 1}, Upgrade: multiChoiceBehaviourGroup},
+		{Version: LibraryVersion{Major: 1, Minor: 4}, Upgrade: multiChoiceTipsAndFeedback},
+		{Version: LibraryVersion{Major: 1, Minor: 5}, Upgrade: multiChoiceBehaviourType},
+	},
+}
+
+// UpgradeOptions controls UpgradeContent.
+type UpgradeOptions struct {
+	// Targets maps machine names to the versions to upgrade to. Libraries
+	// it does not list are upgraded to the version in
+	// LatestLibraryVersions; libraries in neither are left alone.
+	Targets map[string]LibraryVersion
+}
+
+// UpgradeChange is a library reference raised to a newer version.
+type UpgradeChange struct {
+	// Path is "h5p.json" for a dependency declaration, or the params path
+	// of the content or sub-content, e.g. "content.questions[0]".
+	Path        string         `json:"path"`
+	MachineName string         `json:"machineName"`
+	From        LibraryVersion `json:"from"`
+	To          LibraryVersion `json:"to"`
+	// Migrations is the number of ContentUpgrades run on the params.
+	Migrations int `json:"migrations,omitempty"`
+}
+
+func (c UpgradeChange) String() string {
+	s := fmt.Sprintf("%s: %s %d.%d -> %d.%d", c.Path, c.MachineName, c.From.Major, c.From.Minor, c.To.Major, c.To.Minor)
+	if c.Migrations > 0 {
+		s += fmt.Sprintf(" (%d migration(s))", c.Migrations)
+	}
+	return s
+}
+
+// UpgradeContent raises the library versions of the content, its
+// sub-content and the h5p.json dependencies to the target versions, running
+// the ContentUpgrades in between on the params, the way the H5P content
+// upgrade engine does on a site. Library folders are not changed; add the
+// new versions with MergeRequiredLibraries or hub.Client.InstallDependencies.
+// Changes are returned in the order they were made.
+func (pkg *H5PPackage) UpgradeContent(opts UpgradeOptions) ([]UpgradeChange, error) {
+	if pkg.PackageDefinition == nil {
+		return nil, fmt.Errorf("%w: package has no h5p.json", ErrUpgrade)
+	}
+	u := upgrader{opts: opts}
+	def := pkg.PackageDefinition
+
+	if pkg.Content != nil {
+		data, err := json.Marshal(pkg.Content)
+		if err != nil {
+			return nil, err
+		}
+		var params any
+		if err := json.Unmarshal(data, &params); err != nil {
+			return nil, err
+		}
+		obj, ok := params.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("%w: content params must be an object", ErrUpgrade)
+		}
+		i := slices.IndexFunc(def.PreloadedDependencies, func(d LibraryDependency) bool { return d.MachineName == def.MainLibrary })
+		if i < 0 {
+			return nil, fmt.Errorf("%w: main library %s is not a preloaded dependency", ErrUpgrade, def.MainLibrary)
+		}
+		main := def.PreloadedDependencies[i]
+		if _, err := u.upgrade("content", main.MachineName, LibraryVersion{Major: main.MajorVersion, Minor: main.MinorVersion}, obj); err != nil {
+			return nil, err
+		}
+		if err := u.walk("content", obj); err != nil {
+			return nil, err
+		}
+
+		data, err = json.Marshal(obj)
+		if err != nil {
+			return nil, err
+		}
+		var content Content
+		if err := json.Unmarshal(data, &content); err != nil {
+			return nil, err
+		}
+		pkg.Content = &content
+	}
+
+	for i, dep := range def.PreloadedDependencies {
+		from := LibraryVersion{Major: dep.MajorVersion, Minor: dep.MinorVersion}
+		if to, ok := u.target(dep.MachineName, from); ok {
+			def.PreloadedDependencies[i].MajorVersion, def.PreloadedDependencies[i].MinorVersion = to.Major, to.Minor
+			u.changes = append(u.changes, UpgradeChange{Path: "h5p.json", MachineName: dep.MachineName, From: from, To: to})
+		}
+	}
+	return u.changes, nil
+}
+
+type upgrader struct {
+	opts    UpgradeOptions
+	changes []UpgradeChange
+}
+
+// target returns the version to upgrade machineName from to, if newer.
+func (u *upgrader) target(machineName string, from LibraryVersion) (LibraryVersion, bool) {
+	to, ok := u.opts.Targets[machineName]
+	if !ok {
+		to, ok = LatestLibraryVersions[machineName]
+	}
+	to.Patch = 0
+	return to, ok && to.Compare(from) > 0
+}
+
+// upgrade runs the migrations of machineName newer than from up to the
+// target version on params, returning the target version if there is one.
+func (u *upgrader) upgrade(path, machineName string, from LibraryVersion, params map[string]any) (LibraryVersion, error) {
+	to, ok := u.target(machineName, from)
+	if !ok {
+		return from, nil
+	}
+	change := UpgradeChange{Path: path, MachineName: machineName, From: from, To: to}
+	for _, m := range ContentUpgrades[machineName] {
+		if m.Version.Compare(from) <= 0 || m.Version.Compare(to) > 0 {
+			continue
+		}
+		if err := m.Upgrade(params); err != nil {
+			return from, fmt.Errorf("%w: %s: %s %d.%d: %v", ErrUpgrade, path, machineName, m.Version.Major, m.Version.Minor, err)
+		}
+		change.Migrations++
+	}
+	u.changes = append(u.changes, change)
+	return to, nil
+}
+
+// walk upgrades the sub-content found in v, objects with a library string
+// and params, after the content holding it.
+func (u *upgrader) walk(path string, v any) error {
+	switch v := v.(type) {
+	case map[string]any:
+		library, _ := v["library"].(string)
+		params, _ := v["params"].(map[string]any)
+		if dep, err := ParseLibraryString(library); err == nil && params != nil {
+			from := LibraryVersion{Major: dep.MajorVersion, Minor: dep.MinorVersion}
+			to, err := u.upgrade(path, dep.MachineName, from, params)
+			if err != nil {
+				return err
+			}
+			v["library"] = fmt.Sprintf("%s %d.%d", dep.MachineName, to.Major, to.Minor)
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+		for _, k := range keys {
+			childPath := schemas.JoinPath(path, k)
+			if k == "params" {
+				// Sub-content params are reported at the sub-content.
+				childPath = path
+			}
+			if err := u.walk(childPath, v[k]); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for i, child := range v {
+			if err := u.walk(schemas.IndexPath(path, i), child); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// multiChoiceBehaviourGroup moves the behaviour settings of early
+// MultiChoice versions from the top level into the behaviour group.
+func multiChoiceBehaviourGroup(params map[string]any) error {
+	moves := [][2]string{
+		{"tryAgain", "enableRetry"},
+		{"enableSolutionsButton", "enableSolutionsButton"},
+		{"singleAnswer", "singleAnswer"},
+		{"singlePoint", "singlePoint"},
+		{"randomAnswers", "randomAnswers"},
+		{"showSolutionsRequiresInput", "showSolutionsRequiresInput"},
+	}
+	behaviour, _ := params["behaviour"].(map[string]any)
+	for _, m := range moves {
+		value, ok := params[m[0]]
+		if !ok {
+			continue
+		}
+		if behaviour == nil {
+			behaviour = map[string]any{}
+			params["behaviour"] = behaviour
+		}
+		if _, set := behaviour[m[1]]; !set {
+			behaviour[m[1]] = value
+		}
+		delete(params, m[0])
+	}
+	return nil
+}
+
+// multiChoiceTipsAndFeedback moves the tip and feedback of each answer into
+// its tipsAndFeedback group.
+func multiChoiceTipsAndFeedback(params map[string]any) error {
+	answers, _ := params["answers"].([]any)
+	for _, a := range answers {
+		answer, ok := a.(map[string]any)
+		if !ok {
+			continue
+		}
+		group, _ := answer["tipsAndFeedback"].(map[string]any)
+		for _, key := range []string{"tip", "chosenFeedback", "notChosenFeedback"} {
+			value, ok := answer[key]
+			if !ok {
+				continue
+			}
+			if group == nil {
+				group = map[string]any{}
+				answer["tipsAndFeedback"] = group
+			}
+			if _, set := group[key]; !set {
+				group[key] = value
+			}
+			delete(answer, key)
+		}
+	}
+	return nil
+}
+
+// multiChoiceBehaviourType replaces the singleAnswer flag with the
+// behaviour type: "auto" chooses radio buttons when a single answer is
+// correct, as singleAnswer did, and "multi" always uses checkboxes.
+func multiChoiceBehaviourType(params map[string]any) error {
+	behaviour, _ := params["behaviour"].(map[string]any)
+	single, ok := behaviour["singleAnswer"]
+	if !ok {
+		return nil
+	}
+	if _, set := behaviour["type"]; !set {
+		behaviour["type"] = "auto"
+		if single == false {
+			behaviour["type"] = "multi"
+		}
+	}
+	delete(behaviour, "singleAnswer")
+	return nil
+}
@@ -0,0 +1,78 @@
+package h5p
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestUpgradeContent(t *testing.T) {
+	pkg := NewH5PPackage()
+	pkg.SetPackageDefinition(&PackageDefinition{
+		Title:       "Quiz",
+		MainLibrary: "H5P.QuestionSet",
+		PreloadedDependencies: []LibraryDependency{
+			{MachineName: "H5P.QuestionSet", MajorVersion: 1, MinorVersion: 17},
+			{MachineName: "H5P.MultiChoice", MajorVersion: 1, MinorVersion: 0},
+			{MachineName: "H5P.Custom", MajorVersion: 1, MinorVersion: 0},
+		},
+	})
+	pkg.SetContent(&Content{Params: map[string]any{
+		"questions": []any{
+			map[string]any{
+				"library": "H5P.MultiChoice 1.0",
+				"params": map[string]any{
+					"question":     "Pick",
+					"singleAnswer": false,
+					"tryAgain":     true,
+					"answers": []any{
+						map[string]any{"text": "A", "correct": true, "tip": "Hint"},
+					},
+				},
+			},
+			map[string]any{"library": "H5P.Custom 1.0", "params": map[string]any{}},
+		},
+	}})
+
+	changes, err := pkg.UpgradeContent(UpgradeOptions{Targets: map[string]LibraryVersion{"H5P.QuestionSet": {Major: 1, Minor: 18}}})
+	if err != nil {
+		t.Fatalf("UpgradeContent failed: %v", err)
+	}
+	want := []UpgradeChange{
+		{Path: "content", MachineName: "H5P.QuestionSet", From: LibraryVersion{Major: 1, Minor: 17}, To: LibraryVersion{Major: 1, Minor: 18}},
+		{Path: "content.questions[0]", MachineName: "H5P.MultiChoice", From: LibraryVersion{Major: 1}, To: LibraryVersion{Major: 1, Minor: 16}, Migrations: 3},
+		{Path: "h5p.json", MachineName: "H5P.QuestionSet", From: LibraryVersion{Major: 1, Minor: 17}, To: LibraryVersion{Major: 1, Minor: 18}},
+		{Path: "h5p.json", MachineName: "H5P.MultiChoice", From: LibraryVersion{Major: 1}, To: LibraryVersion{Major: 1, Minor: 16}},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("Expected changes\n%v\ngot\n%v", want, changes)
+	}
+
+	questions := pkg.Content.Params.(map[string]any)["questions"].([]any)
+	mc := questions[0].(map[string]any)
+	if mc["library"] != "H5P.MultiChoice 1.16" || questions[1].(map[string]any)["library"] != "H5P.Custom 1.0" {
+		t.Errorf("Unexpected libraries %v, %v", mc["library"], questions[1].(map[string]any)["library"])
+	}
+	wantParams := map[string]any{
+		"question":  "Pick",
+		"behaviour": map[string]any{"enableRetry": true, "type": "multi"},
+		"answers": []any{
+			map[string]any{"text": "A", "correct": true, "tipsAndFeedback": map[string]any{"tip": "Hint"}},
+		},
+	}
+	if !reflect.DeepEqual(mc["params"], wantParams) {
+		t.Errorf("Expected params %v, got %v", wantParams, mc["params"])
+	}
+	if got := pkg.PackageDefinition.PreloadedDependencies[1]; got.MinorVersion != 16 {
+		t.Errorf("Expected MultiChoice dependency 1.16, got %v", got)
+	}
+
+	// Upgrading again changes nothing.
+	if changes, err := pkg.UpgradeContent(UpgradeOptions{Targets: map[string]LibraryVersion{"H5P.QuestionSet": {Major: 1, Minor: 18}}}); err != nil || len(changes) != 0 {
+		t.Errorf("Expected no further changes, got %v, %v", changes, err)
+	}
+
+	if _, err := NewH5PPackage().UpgradeContent(UpgradeOptions{}); !errors.Is(err, ErrUpgrade) {
+		t.Errorf("Expected ErrUpgrade without h5p.json, got %v", err)
+	}
+}
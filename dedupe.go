@@ -0,0 +1,153 @@
+package h5p
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// DuplicateFiles is a set of byte-identical files in a package, named by
+// their package paths, e.g. "content/images/a.png" or
+// "H5P.Image-1.1/image.svg".
+type DuplicateFiles struct {
+	Hash  string   `json:"hash"`
+	Size  int64    `json:"size"`
+	Files []string `json:"files"`
+	// Kept is the content file references were rewritten to, and Removed
+	// the content files it replaced. Library files are never removed, as
+	// library code references them by name.
+	Kept    string   `json:"kept,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+}
+
+// DeduplicationResult lists the duplicate files DeduplicateFiles found and
+// what it removed.
+type DeduplicationResult struct {
+	Duplicates []DuplicateFiles `json:"duplicates,omitempty"`
+	// References is the number of content params references rewritten.
+	References int `json:"references"`
+	// BytesSaved is the total size of the removed files.
+	BytesSaved int64 `json:"bytesSaved"`
+}
+
+// DeduplicateFiles finds byte-identical files among the content files and
+// library files of a package, as merging packages often leaves. Duplicate
+// content files are replaced by a single copy, the first by path, and
+// references to them in the content params are rewritten. Duplicates
+// involving library files are only reported. Empty files are ignored.
+func (pkg *H5PPackage) DeduplicateFiles() (*DeduplicationResult, error) {
+	type file struct {
+		name, content string
+		size          int64
+	}
+	byHash := map[string][]file{}
+	var hashes []string
+	add := func(f file, r io.Reader) error {
+		h, size, err := hashFile(r)
+		if err != nil {
+			return fmt.Errorf("failed to hash %s: %w", f.name, err)
+		}
+		f.size = size
+		if byHash[h] == nil {
+			hashes = append(hashes, h)
+		}
+		byHash[h] = append(byHash[h], f)
+		return nil
+	}
+	for _, name := range pkg.ContentFileNames() {
+		if err := walkContentFile(pkg.ContentFiles[name], func(r io.Reader) error {
+			return add(file{name: ContentDir + "/" + name, content: name}, r)
+		}); err != nil {
+			return nil, err
+		}
+	}
+	for _, lib := range pkg.Libraries {
+		for _, name := range lib.FileNames() {
+			if err := walkLibraryFile(lib, name, func(r io.Reader) error {
+				return add(file{name: lib.MachineName + "/" + name}, r)
+			}); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	result := &DeduplicationResult{}
+	renamed := map[string]string{}
+	sort.Strings(hashes)
+	for _, h := range hashes {
+		files := byHash[h]
+		if len(files) < 2 || files[0].size == 0 {
+			continue
+		}
+		d := DuplicateFiles{Hash: h, Size: files[0].size}
+		var kept string
+		for _, f := range files {
+			d.Files = append(d.Files, f.name)
+			switch {
+			case f.content == "":
+			case kept == "":
+				kept, d.Kept = f.content, f.name
+			default:
+				d.Removed = append(d.Removed, f.name)
+				renamed[f.content] = kept
+				result.BytesSaved += f.size
+			}
+		}
+		result.Duplicates = append(result.Duplicates, d)
+	}
+
+	if len(renamed) > 0 && pkg.Content != nil {
+		n, err := pkg.renameContentAssets(renamed)
+		if err != nil {
+			return nil, err
+		}
+		result.References = n
+	}
+	// Files are removed only once no params refer to them.
+	for name := range renamed {
+		delete(pkg.ContentFiles, name)
+	}
+	return result, nil
+}
+
+// renameContentAssets replaces the media paths in renamed by their new
+// names in the content params, returning the number of references changed.
+func (pkg *H5PPackage) renameContentAssets(renamed map[string]string) (int, error) {
+	params, err := toGenericJSON(pkg.Content)
+	if err != nil {
+		return 0, err
+	}
+	n := renameAssetPaths(params, renamed)
+	if n == 0 {
+		return 0, nil
+	}
+	data, err := json.Marshal(params)
+	if err != nil {
+		return 0, err
+	}
+	var content Content
+	if err := json.Unmarshal(data, &content); err != nil {
+		return 0, err
+	}
+	// Keep typed params typed, as the generic round trip loses their type.
+	content.Params = retypeParams(content.Params, pkg.Content.Params)
+	if old, qs := pkg.Content.QuestionSet, content.QuestionSet; old != nil && qs != nil && len(old.Questions) == len(qs.Questions) {
+		for i := range qs.Questions {
+			qs.Questions[i].Params = retypeParams(qs.Questions[i].Params, old.Questions[i].Params)
+		}
+	}
+	pkg.Content = &content
+	return n, nil
+}
+
+func hashFile(r io.Reader) (string, int64, error) {
+	h := sha256.New()
+	n, err := io.Copy(h, r)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(h.Sum(nil)), n, nil
+}
@@ -0,0 +1,111 @@
+package h5p
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/grokify/h5p-go/schemas"
+)
+
+func TestDeduplicateFiles(t *testing.T) {
+	pkg := NewH5PPackage()
+	for name, data := range map[string]string{
+		"images/a.png":   "png data",
+		"images/a-1.png": "png data",
+		"images/b.png":   "other data",
+		"images/c.png":   "png data",
+		"images/d.svg":   "<svg/>",
+	} {
+		if err := pkg.AddContentAsset(name, []byte(data), ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pkg.AddLibrary(&Library{MachineName: "H5P.Image-1.1", Files: map[string][]byte{"icon.svg": []byte("<svg/>")}})
+	pkg.SetContent(&Content{Params: map[string]any{
+		"image": map[string]any{"path": "images/a.png"},
+		"items": []any{map[string]any{"path": "images/c.png#tmp"}, map[string]any{"path": "images/b.png"}},
+	}})
+
+	result, err := pkg.DeduplicateFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.BytesSaved != 16 || result.References != 2 || len(result.Duplicates) != 2 {
+		t.Fatalf("Unexpected result %+v", result)
+	}
+	for _, d := range result.Duplicates {
+		switch d.Kept {
+		case "content/images/a-1.png":
+			if !reflect.DeepEqual(d.Removed, []string{"content/images/a.png", "content/images/c.png"}) {
+				t.Errorf("Unexpected removed files %v", d.Removed)
+			}
+		case "content/images/d.svg":
+			if len(d.Removed) != 0 || !reflect.DeepEqual(d.Files, []string{"content/images/d.svg", "H5P.Image-1.1/icon.svg"}) {
+				t.Errorf("Expected library duplicates only reported, got %+v", d)
+			}
+		default:
+			t.Errorf("Unexpected duplicates %+v", d)
+		}
+	}
+	if !reflect.DeepEqual(pkg.ContentFileNames(), []string{"images/a-1.png", "images/b.png", "images/d.svg"}) {
+		t.Errorf("Unexpected content files %v", pkg.ContentFileNames())
+	}
+	report, err := pkg.CheckAssetReferences()
+	if err != nil || len(report.Missing) != 0 {
+		t.Errorf("Expected all references to resolve, got %+v: %v", report, err)
+	}
+}
+
+func TestDeduplicateFilesKeepsFilesOnError(t *testing.T) {
+	pkg := NewH5PPackage()
+	for _, name := range []string{"images/a.png", "images/b.png"} {
+		if err := pkg.AddContentAsset(name, []byte("png data"), ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Params that cannot be encoded make renaming the references fail.
+	pkg.SetContent(&Content{Params: map[string]any{"path": "images/b.png", "bad": func() {}}})
+
+	if _, err := pkg.DeduplicateFiles(); err == nil {
+		t.Fatal("Expected an error for params that cannot be encoded")
+	}
+	if !reflect.DeepEqual(pkg.ContentFileNames(), []string{"images/a.png", "images/b.png"}) {
+		t.Errorf("Expected no files removed, got %v", pkg.ContentFileNames())
+	}
+}
+
+func TestDeduplicateFilesKeepsTypedParams(t *testing.T) {
+	pkg := NewH5PPackage()
+	for _, name := range []string{"images/a.png", "images/b.png"} {
+		if err := pkg.AddContentAsset(name, []byte("png data"), ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	media := &schemas.MediaGroup{Type: &schemas.MediaContent{
+		Library: "H5P.Image 1.1",
+		Params:  map[string]any{"file": map[string]any{"path": "images/b.png"}},
+	}}
+	pkg.SetContent(&Content{QuestionSet: &QuestionSet{Questions: []Question{
+		{Library: "H5P.TrueFalse 1.8", Params: &schemas.TrueFalseParams{Question: "True?", Correct: "true", Media: media}},
+		{Library: "H5P.Poll 1.0", Params: map[string]any{"question": "Tea?"}},
+	}}})
+
+	result, err := pkg.DeduplicateFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.References != 1 {
+		t.Fatalf("Expected 1 reference renamed, got %+v", result)
+	}
+	qs := pkg.Content.QuestionSet
+	tf, ok := qs.Questions[0].Params.(*schemas.TrueFalseParams)
+	if !ok {
+		t.Fatalf("Expected typed params kept, got %T", qs.Questions[0].Params)
+	}
+	if file := tf.Media.Type.Params.(map[string]any)["file"].(map[string]any); file["path"] != "images/a.png" {
+		t.Errorf("Expected the reference renamed, got %v", file)
+	}
+	if _, ok := qs.Questions[1].Params.(map[string]any); !ok {
+		t.Errorf("Expected generic params kept generic, got %T", qs.Questions[1].Params)
+	}
+}
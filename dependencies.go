@@ -0,0 +1,178 @@
+package h5p
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DependencyProblem describes a dependency that cannot be satisfied by the
+// libraries in a package. Dependent is empty for dependencies declared in
+// h5p.json.
+type DependencyProblem struct {
+	Dependent  string            `json:"dependent,omitempty"`
+	Dependency LibraryDependency `json:"dependency"`
+	Available  []string          `json:"available,omitempty"`
+}
+
+func (p DependencyProblem) String() string {
+	from := "h5p.json"
+	if p.Dependent != "" {
+		from = p.Dependent
+	}
+	s := fmt.Sprintf("%s requires %s", from, p.Dependency.String())
+	if len(p.Available) > 0 {
+		s += " but package contains " + strings.Join(p.Available, ", ")
+	}
+	return s
+}
+
+// DependencyError reports all problems found while resolving dependencies.
+type DependencyError struct {
+	Missing    []DependencyProblem `json:"missing,omitempty"`
+	Mismatched []DependencyProblem `json:"mismatched,omitempty"`
+	Cycles     [][]string          `json:"cycles,omitempty"`
+}
+
+func (e *DependencyError) Error() string {
+	var parts []string
+	for _, p := range e.Missing {
+		parts = append(parts, "missing: "+p.String())
+	}
+	for _, p := range e.Mismatched {
+		parts = append(parts, "version mismatch: "+p.String())
+	}
+	for _, c := range e.Cycles {
+		parts = append(parts, "cycle: "+strings.Join(c, " -> "))
+	}
+	return "unresolved library dependencies: " + strings.Join(parts, "; ")
+}
+
+func (e *DependencyError) empty() bool {
+	return len(e.Missing) == 0 && len(e.Mismatched) == 0 && len(e.Cycles) == 0
+}
+
+// String returns the dependency in "Name Major.Minor" form.
+func (d LibraryDependency) String() string {
+	return fmt.Sprintf("%s %d.%d", d.MachineName, d.MajorVersion, d.MinorVersion)
+}
+
+// ResolveDependencies builds the dependency graph from the library.json
+// files and returns the libraries reachable from the h5p.json
+// preloadedDependencies in load order, with every library preceded by its
+// own dependencies. Without h5p.json all libraries are used as roots. A
+// dependency uses the exact major.minor version if present, otherwise the
+// highest compatible minor version (see Satisfies). Missing or
+// version-mismatched dependencies and cycles are reported together as a
+// *DependencyError.
+func (pkg *H5PPackage) ResolveDependencies() ([]*Library, error) {
+	r := &dependencyResolver{
+		pkg:   pkg,
+		state: map[*Library]int{},
+		err:   &DependencyError{},
+	}
+
+	if pkg.PackageDefinition != nil {
+		for _, dep := range pkg.PackageDefinition.PreloadedDependencies {
+			r.visitDependency("", dep)
+		}
+	} else {
+		for _, lib := range pkg.Libraries {
+			r.visit(lib)
+		}
+	}
+
+	if !r.err.empty() {
+		return r.order, r.err
+	}
+	return r.order, nil
+}
+
+const (
+	unvisited = iota
+	visiting
+	visited
+)
+
+type dependencyResolver struct {
+	pkg   *H5PPackage
+	state map[*Library]int
+	stack []*Library
+	order []*Library
+	err   *DependencyError
+}
+
+func (r *dependencyResolver) visitDependency(dependent string, dep LibraryDependency) {
+	lib := r.pkg.resolveLibrary(dep)
+	if lib != nil {
+		r.visit(lib)
+		return
+	}
+
+	problem := DependencyProblem{Dependent: dependent, Dependency: dep}
+	for _, other := range r.pkg.Libraries {
+		if name, major, minor, ok := other.identity(); ok && name == dep.MachineName {
+			problem.Available = append(problem.Available, LibraryDependency{
+				MachineName: name, MajorVersion: major, MinorVersion: minor,
+			}.String())
+		}
+	}
+	if len(problem.Available) > 0 {
+		r.err.Mismatched = append(r.err.Mismatched, problem)
+	} else {
+		r.err.Missing = append(r.err.Missing, problem)
+	}
+}
+
+func (r *dependencyResolver) visit(lib *Library) {
+	switch r.state[lib] {
+	case visited:
+		return
+	case visiting:
+		r.recordCycle(lib)
+		return
+	}
+
+	r.state[lib] = visiting
+	r.stack = append(r.stack, lib)
+	if lib.Definition != nil {
+		for _, dep := range lib.Definition.Dependencies {
+			r.visitDependency(lib.MachineName, dep)
+		}
+	}
+	r.stack = r.stack[:len(r.stack)-1]
+	r.state[lib] = visited
+	r.order = append(r.order, lib)
+}
+
+func (r *dependencyResolver) recordCycle(lib *Library) {
+	for i := len(r.stack) - 1; i >= 0; i-- {
+		if r.stack[i] != lib {
+			continue
+		}
+		cycle := make([]string, 0, len(r.stack)-i+1)
+		for _, l := range r.stack[i:] {
+			cycle = append(cycle, l.MachineName)
+		}
+		r.err.Cycles = append(r.err.Cycles, append(cycle, lib.MachineName))
+		return
+	}
+}
+
+// resolveLibrary returns the library with the exact version of dep or,
+// failing that, the highest compatible one.
+func (pkg *H5PPackage) resolveLibrary(dep LibraryDependency) *Library {
+	if lib := pkg.GetLibrary(dep.MachineName, dep.MajorVersion, dep.MinorVersion); lib != nil {
+		return lib
+	}
+	return pkg.FindCompatibleLibrary(dep)
+}
+
+// identity returns the machine name and version of the library, taken from
+// library.json or, failing that, from the folder name.
+func (lib *Library) identity() (machineName string, major, minor int, ok bool) {
+	if lib.Definition != nil && lib.Definition.MachineName != "" {
+		return lib.Definition.MachineName, lib.Definition.MajorVersion, lib.Definition.MinorVersion, true
+	}
+	name, major, minor, err := ParseLibraryFolderName(lib.MachineName)
+	return name, major, minor, err == nil
+}
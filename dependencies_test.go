@@ -0,0 +1,87 @@
+package h5p
+
+import (
+	"errors"
+	"testing"
+)
+
+func newTestLibrary(name string, major, minor int, deps ...LibraryDependency) *Library {
+	def := &LibraryDefinition{
+		Title:        name,
+		MachineName:  name,
+		MajorVersion: major,
+		MinorVersion: minor,
+		Dependencies: deps,
+	}
+	return &Library{MachineName: def.FolderName(), Definition: def}
+}
+
+func dep(name string, major, minor int) LibraryDependency {
+	return LibraryDependency{MachineName: name, MajorVersion: major, MinorVersion: minor}
+}
+
+func TestResolveDependencies(t *testing.T) {
+	pkg := NewH5PPackage()
+	pkg.SetPackageDefinition(&PackageDefinition{
+		MainLibrary:           "H5P.QuestionSet",
+		PreloadedDependencies: []LibraryDependency{dep("H5P.QuestionSet", 1, 20), dep("H5P.MultiChoice", 1, 16)},
+	})
+	pkg.AddLibrary(newTestLibrary("H5P.QuestionSet", 1, 20, dep("H5P.JoubelUI", 1, 3), dep("H5P.Question", 1, 5)))
+	pkg.AddLibrary(newTestLibrary("H5P.MultiChoice", 1, 16, dep("H5P.JoubelUI", 1, 3), dep("H5P.Question", 1, 5)))
+	pkg.AddLibrary(newTestLibrary("H5P.Question", 1, 5, dep("H5P.JoubelUI", 1, 3)))
+	pkg.AddLibrary(newTestLibrary("H5P.JoubelUI", 1, 3, dep("FontAwesome", 4, 5)))
+	pkg.AddLibrary(newTestLibrary("FontAwesome", 4, 5))
+	pkg.AddLibrary(newTestLibrary("H5P.Unused", 1, 0))
+
+	order, err := pkg.ResolveDependencies()
+	if err != nil {
+		t.Fatalf("Failed to resolve dependencies: %v", err)
+	}
+
+	position := map[string]int{}
+	for i, lib := range order {
+		position[lib.Definition.MachineName] = i
+	}
+	if len(order) != 5 {
+		t.Fatalf("Expected 5 libraries in load order, got %d", len(order))
+	}
+	if _, ok := position["H5P.Unused"]; ok {
+		t.Error("Unreachable library should not be in load order")
+	}
+	for _, pair := range [][2]string{
+		{"FontAwesome", "H5P.JoubelUI"},
+		{"H5P.JoubelUI", "H5P.Question"},
+		{"H5P.Question", "H5P.QuestionSet"},
+		{"H5P.Question", "H5P.MultiChoice"},
+	} {
+		if position[pair[0]] > position[pair[1]] {
+			t.Errorf("Expected %s to load before %s", pair[0], pair[1])
+		}
+	}
+}
+
+func TestResolveDependenciesProblems(t *testing.T) {
+	pkg := NewH5PPackage()
+	pkg.SetPackageDefinition(&PackageDefinition{
+		PreloadedDependencies: []LibraryDependency{dep("H5P.A", 1, 0), dep("H5P.Missing", 1, 0)},
+	})
+	pkg.AddLibrary(newTestLibrary("H5P.A", 1, 0, dep("H5P.B", 1, 0), dep("H5P.C", 2, 0)))
+	pkg.AddLibrary(newTestLibrary("H5P.B", 1, 0, dep("H5P.A", 1, 0)))
+	pkg.AddLibrary(newTestLibrary("H5P.C", 1, 3))
+
+	_, err := pkg.ResolveDependencies()
+	var depErr *DependencyError
+	if !errors.As(err, &depErr) {
+		t.Fatalf("Expected *DependencyError, got %v", err)
+	}
+	if len(depErr.Missing) != 1 || depErr.Missing[0].Dependency.MachineName != "H5P.Missing" {
+		t.Errorf("Expected H5P.Missing to be reported missing, got %v", depErr.Missing)
+	}
+	if len(depErr.Mismatched) != 1 || depErr.Mismatched[0].Dependent != "H5P.A-1.0" ||
+		len(depErr.Mismatched[0].Available) != 1 || depErr.Mismatched[0].Available[0] != "H5P.C 1.3" {
+		t.Errorf("Expected H5P.C version mismatch, got %v", depErr.Mismatched)
+	}
+	if len(depErr.Cycles) != 1 || len(depErr.Cycles[0]) != 3 {
+		t.Errorf("Expected one cycle A -> B -> A, got %v", depErr.Cycles)
+	}
+}
@@ -0,0 +1,250 @@
+package h5p
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// Change kinds used in a PackageDiff.
+const (
+	ChangeAdded    = "added"
+	ChangeRemoved  = "removed"
+	ChangeModified = "modified"
+)
+
+// ValueChange is a difference in a JSON value at Path.
+type ValueChange struct {
+	Path   string `json:"path"`
+	Change string `json:"change"`
+	Old    any    `json:"old,omitempty"`
+	New    any    `json:"new,omitempty"`
+}
+
+// LibraryChange is a library added, removed or changed in version.
+type LibraryChange struct {
+	MachineName string `json:"machineName"`
+	Change      string `json:"change"`
+	OldVersion  string `json:"oldVersion,omitempty"`
+	NewVersion  string `json:"newVersion,omitempty"`
+}
+
+// FileChange is a package file added, removed or modified, identified by
+// SHA-256 hashes of its contents.
+type FileChange struct {
+	Path    string `json:"path"`
+	Change  string `json:"change"`
+	OldHash string `json:"oldHash,omitempty"`
+	NewHash string `json:"newHash,omitempty"`
+}
+
+// PackageDiff is a structured comparison of two packages.
+type PackageDiff struct {
+	Metadata  []ValueChange   `json:"metadata,omitempty"`
+	Libraries []LibraryChange `json:"libraries,omitempty"`
+	Content   []ValueChange   `json:"content,omitempty"`
+	Files     []FileChange    `json:"files,omitempty"`
+}
+
+// Empty reports whether the packages are identical.
+func (d *PackageDiff) Empty() bool {
+	return len(d.Metadata) == 0 && len(d.Libraries) == 0 && len(d.Content) == 0 && len(d.Files) == 0
+}
+
+// DiffPackages compares h5p.json metadata, library versions, content params
+// and file contents of two packages.
+func DiffPackages(a, b *H5PPackage) (*PackageDiff, error) {
+	d := &PackageDiff{}
+
+	oldMeta, err := toGenericJSON(a.PackageDefinition)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read h5p.json: %w", err)
+	}
+	newMeta, err := toGenericJSON(b.PackageDefinition)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read h5p.json: %w", err)
+	}
+	d.Metadata = diffValues("", oldMeta, newMeta)
+
+	oldContent, err := toGenericJSON(a.Content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read content: %w", err)
+	}
+	newContent, err := toGenericJSON(b.Content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read content: %w", err)
+	}
+	d.Content = diffValues("", oldContent, newContent)
+
+	d.Libraries = diffLibraries(a, b)
+
+	if d.Files, err = diffFiles(a, b); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// diffValues compares two generic JSON values recursively.
+func diffValues(path string, oldV, newV any) []ValueChange {
+	switch {
+	case oldV == nil && newV == nil:
+		return nil
+	case oldV == nil:
+		return []ValueChange{{Path: path, Change: ChangeAdded, New: newV}}
+	case newV == nil:
+		return []ValueChange{{Path: path, Change: ChangeRemoved, Old: oldV}}
+	}
+
+	oldMap, oldIsMap := oldV.(map[string]any)
+	newMap, newIsMap := newV.(map[string]any)
+	if oldIsMap && newIsMap {
+		keys := map[string]bool{}
+		for k := range oldMap {
+			keys[k] = true
+		}
+		for k := range newMap {
+			keys[k] = true
+		}
+		sorted := make([]string, 0, len(keys))
+		for k := range keys {
+			sorted = append(sorted, k)
+		}
+		sort.Strings(sorted)
+		var changes []ValueChange
+		for _, k := range sorted {
+			changes = append(changes, diffValues(joinJSONPath(path, k), oldMap[k], newMap[k])...)
+		}
+		return changes
+	}
+
+	oldList, oldIsList := oldV.([]any)
+	newList, newIsList := newV.([]any)
+	if oldIsList && newIsList {
+		var changes []ValueChange
+		for i := 0; i < len(oldList) || i < len(newList); i++ {
+			var o, n any
+			if i < len(oldList) {
+				o = oldList[i]
+			}
+			if i < len(newList) {
+				n = newList[i]
+			}
+			changes = append(changes, diffValues(path+"["+strconv.Itoa(i)+"]", o, n)...)
+		}
+		return changes
+	}
+
+	if reflect.DeepEqual(oldV, newV) {
+		return nil
+	}
+	return []ValueChange{{Path: path, Change: ChangeModified, Old: oldV, New: newV}}
+}
+
+func diffLibraries(a, b *H5PPackage) []LibraryChange {
+	oldVersions := libraryVersions(a)
+	newVersions := libraryVersions(b)
+
+	names := map[string]bool{}
+	for n := range oldVersions {
+		names[n] = true
+	}
+	for n := range newVersions {
+		names[n] = true
+	}
+	sorted := make([]string, 0, len(names))
+	for n := range names {
+		sorted = append(sorted, n)
+	}
+	sort.Strings(sorted)
+
+	var changes []LibraryChange
+	for _, name := range sorted {
+		oldV, inOld := oldVersions[name]
+		newV, inNew := newVersions[name]
+		switch {
+		case !inOld:
+			changes = append(changes, LibraryChange{MachineName: name, Change: ChangeAdded, NewVersion: newV})
+		case !inNew:
+			changes = append(changes, LibraryChange{MachineName: name, Change: ChangeRemoved, OldVersion: oldV})
+		case oldV != newV:
+			changes = append(changes, LibraryChange{MachineName: name, Change: ChangeModified, OldVersion: oldV, NewVersion: newV})
+		}
+	}
+	return changes
+}
+
+// libraryVersions maps machine names to "major.minor.patch" versions. Multiple
+// versions of the same library are joined with commas.
+func libraryVersions(pkg *H5PPackage) map[string]string {
+	versions := map[string]string{}
+	for _, lib := range pkg.Libraries {
+		name, major, minor, ok := lib.identity()
+		if !ok {
+			name = lib.MachineName
+		}
+		v := fmt.Sprintf("%d.%d", major, minor)
+		if lib.Definition != nil {
+			v = fmt.Sprintf("%d.%d.%d", major, minor, lib.Definition.PatchVersion)
+		}
+		if existing, ok := versions[name]; ok {
+			v = existing + "," + v
+		}
+		versions[name] = v
+	}
+	return versions
+}
+
+func diffFiles(a, b *H5PPackage) ([]FileChange, error) {
+	oldHashes, err := fileHashes(a)
+	if err != nil {
+		return nil, err
+	}
+	newHashes, err := fileHashes(b)
+	if err != nil {
+		return nil, err
+	}
+
+	names := map[string]bool{}
+	for n := range oldHashes {
+		names[n] = true
+	}
+	for n := range newHashes {
+		names[n] = true
+	}
+	sorted := make([]string, 0, len(names))
+	for n := range names {
+		sorted = append(sorted, n)
+	}
+	sort.Strings(sorted)
+
+	var changes []FileChange
+	for _, name := range sorted {
+		oldH, inOld := oldHashes[name]
+		newH, inNew := newHashes[name]
+		switch {
+		case !inOld:
+			changes = append(changes, FileChange{Path: name, Change: ChangeAdded, NewHash: newH})
+		case !inNew:
+			changes = append(changes, FileChange{Path: name, Change: ChangeRemoved, OldHash: oldH})
+		case oldH != newH:
+			changes = append(changes, FileChange{Path: name, Change: ChangeModified, OldHash: oldH, NewHash: newH})
+		}
+	}
+	return changes, nil
+}
+
+// fileHashes returns the hex SHA-256 of every file in the package archive.
+func fileHashes(pkg *H5PPackage) (map[string]string, error) {
+	hashes := map[string]string{}
+	err := pkg.walkFiles(func(name string, r io.Reader) error {
+		h, err := hashReader(r)
+		if err != nil {
+			return fmt.Errorf("failed to hash %s: %w", name, err)
+		}
+		hashes[name] = h
+		return nil
+	})
+	return hashes, err
+}
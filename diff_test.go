@@ -0,0 +1,77 @@
+package h5p
+
+import (
+	"testing"
+)
+
+func TestDiffPackages(t *testing.T) {
+	a := loadTestPackage(t)
+	b := loadTestPackage(t)
+
+	d, err := DiffPackages(a, b)
+	if err != nil {
+		t.Fatalf("Failed to diff packages: %v", err)
+	}
+	if !d.Empty() {
+		t.Fatalf("Expected identical packages, got %+v", d)
+	}
+
+	b.PackageDefinition.Title = "Changed title"
+	b.Libraries[0].Definition.PatchVersion = 5
+	b.Libraries[0].Files["js/multichoice.js"] = []byte("// changed")
+	b.AddLibrary(newTestLibrary("H5P.JoubelUI", 1, 3))
+	if err := b.AddContentAsset("images/new.png", []byte("png"), ""); err != nil {
+		t.Fatalf("Failed to add asset: %v", err)
+	}
+
+	d, err = DiffPackages(a, b)
+	if err != nil {
+		t.Fatalf("Failed to diff packages: %v", err)
+	}
+
+	if len(d.Metadata) != 1 || d.Metadata[0].Path != "title" || d.Metadata[0].New != "Changed title" {
+		t.Errorf("Unexpected metadata changes %+v", d.Metadata)
+	}
+
+	libChanges := map[string]LibraryChange{}
+	for _, c := range d.Libraries {
+		libChanges[c.MachineName] = c
+	}
+	if c := libChanges["H5P.MultiChoice"]; c.Change != ChangeModified || c.OldVersion != "1.16.4" || c.NewVersion != "1.16.5" {
+		t.Errorf("Unexpected MultiChoice change %+v", c)
+	}
+	if c := libChanges["H5P.JoubelUI"]; c.Change != ChangeAdded {
+		t.Errorf("Expected JoubelUI to be added, got %+v", c)
+	}
+
+	fileChanges := map[string]string{}
+	for _, c := range d.Files {
+		fileChanges[c.Path] = c.Change
+	}
+	for path, change := range map[string]string{
+		"H5P.MultiChoice-1.16/js/multichoice.js": ChangeModified,
+		"content/images/new.png":                 ChangeAdded,
+		"H5P.JoubelUI-1.3/library.json":          ChangeAdded,
+		"h5p.json":                               ChangeModified,
+	} {
+		if fileChanges[path] != change {
+			t.Errorf("Expected %s to be %s, got '%s'", path, change, fileChanges[path])
+		}
+	}
+}
+
+func TestDiffValues(t *testing.T) {
+	oldV := map[string]any{"a": 1.0, "list": []any{"x", "y"}, "gone": true}
+	newV := map[string]any{"a": 2.0, "list": []any{"x"}, "new": "v"}
+
+	changes := diffValues("", oldV, newV)
+	want := map[string]string{"a": ChangeModified, "gone": ChangeRemoved, "list[1]": ChangeRemoved, "new": ChangeAdded}
+	if len(changes) != len(want) {
+		t.Fatalf("Expected %d changes, got %+v", len(want), changes)
+	}
+	for _, c := range changes {
+		if want[c.Path] != c.Change {
+			t.Errorf("Unexpected change %+v", c)
+		}
+	}
+}
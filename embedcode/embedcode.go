@@ -0,0 +1,126 @@
+// Package embedcode generates the HTML snippet that embeds hosted H5P
+// content in another page: an iframe plus the H5P resizer script, which
+// grows the iframe to fit the content. It also produces oEmbed responses for
+// sites that discover embeds automatically.
+package embedcode
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"net/url"
+	"strings"
+
+	h5p "github.com/grokify/h5p-go"
+)
+
+const (
+	// DefaultResizerURL is the resizer script published by h5p.org.
+	DefaultResizerURL = "https://h5p.org/sites/all/modules/h5p/library/js/h5p-resizer.js"
+
+	// DefaultAllow is the iframe permission policy set by H5P core.
+	DefaultAllow = "geolocation *; microphone *; camera *; midi *; encrypted-media *"
+
+	DefaultWidth  = 1090
+	DefaultHeight = 674
+)
+
+var ErrInvalidURL = errors.New("embed URL must be an absolute http or https URL")
+
+// Options describes the content to embed.
+type Options struct {
+	// URL is the embed page of the hosted content, e.g.
+	// https://example.com/h5p/embed/42.
+	URL string
+
+	// Title labels the iframe for screen readers.
+	Title string
+
+	// Width and Height are the initial iframe size in pixels; the resizer
+	// adjusts the height once the content has loaded. They default to
+	// DefaultWidth and DefaultHeight.
+	Width  int
+	Height int
+
+	// ResizerURL defaults to DefaultResizerURL. Serve ResizerScript to host
+	// it yourself.
+	ResizerURL string
+
+	// NoResizer leaves out the resizer script, e.g. when the page already
+	// loads it.
+	NoResizer bool
+
+	// Allow defaults to DefaultAllow.
+	Allow string
+}
+
+// ForPackage returns options for pkg hosted at embedURL, titled after its
+// h5p.json.
+func ForPackage(pkg *h5p.H5PPackage, embedURL string) Options {
+	opts := Options{URL: embedURL}
+	if pkg.PackageDefinition != nil {
+		opts.Title = pkg.PackageDefinition.Title
+	}
+	return opts
+}
+
+// Snippet returns the iframe followed by the resizer script tag, the embed
+// code H5P shows in its "Embed" dialog.
+func Snippet(opts Options) (string, error) {
+	iframe, err := Iframe(opts)
+	if err != nil {
+		return "", err
+	}
+	if opts.NoResizer {
+		return iframe, nil
+	}
+	return iframe + `<script src="` + html.EscapeString(opts.resizerURL()) + `" charset="UTF-8"></script>`, nil
+}
+
+// Iframe returns the iframe element alone.
+func Iframe(opts Options) (string, error) {
+	if err := checkURL(opts.URL); err != nil {
+		return "", err
+	}
+	allow := opts.Allow
+	if allow == "" {
+		allow = DefaultAllow
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, `<iframe src="%s" width="%d" height="%d" frameborder="0" allowfullscreen="allowfullscreen" allow="%s"`,
+		html.EscapeString(opts.URL), opts.width(), opts.height(), html.EscapeString(allow))
+	if opts.Title != "" {
+		fmt.Fprintf(&b, ` title="%s"`, html.EscapeString(opts.Title))
+	}
+	b.WriteString(`></iframe>`)
+	return b.String(), nil
+}
+
+func (opts Options) width() int {
+	if opts.Width <= 0 {
+		return DefaultWidth
+	}
+	return opts.Width
+}
+
+func (opts Options) height() int {
+	if opts.Height <= 0 {
+		return DefaultHeight
+	}
+	return opts.Height
+}
+
+func (opts Options) resizerURL() string {
+	if opts.ResizerURL == "" {
+		return DefaultResizerURL
+	}
+	return opts.ResizerURL
+}
+
+func checkURL(s string) error {
+	u, err := url.Parse(s)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: %q", ErrInvalidURL, s)
+	}
+	return nil
+}
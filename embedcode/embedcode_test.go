@@ -0,0 +1,79 @@
+package embedcode
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	h5p "github.com/grokify/h5p-go"
+)
+
+func TestSnippet(t *testing.T) {
+	pkg := h5p.NewH5PPackage()
+	pkg.SetPackageDefinition(&h5p.PackageDefinition{Title: `Quiz "A" & B`})
+	opts := ForPackage(pkg, "https://example.com/h5p/embed/42?lang=en&x=1")
+
+	got, err := Snippet(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `<iframe src="https://example.com/h5p/embed/42?lang=en&amp;x=1" width="1090" height="674" frameborder="0" allowfullscreen="allowfullscreen" allow="geolocation *; microphone *; camera *; midi *; encrypted-media *" title="Quiz &#34;A&#34; &amp; B"></iframe>` +
+		`<script src="https://h5p.org/sites/all/modules/h5p/library/js/h5p-resizer.js" charset="UTF-8"></script>`
+	if got != want {
+		t.Errorf("Snippet =\n%s\nwant\n%s", got, want)
+	}
+
+	opts.NoResizer, opts.Title, opts.Width, opts.Height = true, "", 640, 480
+	got, err = Snippet(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(got, "<script") || strings.Contains(got, "title=") || !strings.Contains(got, `width="640" height="480"`) {
+		t.Errorf("Snippet = %s", got)
+	}
+
+	for _, u := range []string{"", "/h5p/embed/1", "javascript:alert(1)", "https://"} {
+		if _, err := Snippet(Options{URL: u}); !errors.Is(err, ErrInvalidURL) {
+			t.Errorf("Snippet(%q) error = %v, want ErrInvalidURL", u, err)
+		}
+	}
+}
+
+func TestNewOEmbed(t *testing.T) {
+	opts := Options{URL: "https://example.com/h5p/embed/42", Title: "Quiz", Width: 1000, Height: 600, ResizerURL: "https://example.com/resizer.js"}
+	o, err := NewOEmbed(opts, Provider{Name: "Example", URL: "https://example.com"}, 500, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.Width != 500 || o.Height != 300 || !strings.Contains(o.HTML, `width="500" height="300"`) || !strings.Contains(o.HTML, "https://example.com/resizer.js") {
+		t.Errorf("NewOEmbed = %+v", o)
+	}
+	data, err := json.Marshal(o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"type":"rich"`, `"version":"1.0"`, `"provider_name":"Example"`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("oEmbed JSON lacks %s: %s", want, data)
+		}
+	}
+	if o, _ := NewOEmbed(opts, Provider{}, 0, 300); o.Width != 500 || o.Height != 300 {
+		t.Errorf("maxHeight scaling = %dx%d", o.Width, o.Height)
+	}
+
+	if got := DiscoveryLink("https://example.com/oembed?url=a&b", "Quiz"); got != `<link rel="alternate" type="application/json+oembed" href="https://example.com/oembed?url=a&amp;b" title="Quiz">` {
+		t.Errorf("DiscoveryLink = %s", got)
+	}
+}
+
+func TestResizerHandler(t *testing.T) {
+	rec := httptest.NewRecorder()
+	ResizerHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/h5p-resizer.js", nil))
+	body, _ := io.ReadAll(rec.Body)
+	if !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/javascript") || string(body) != ResizerScript {
+		t.Errorf("ResizerHandler served %q as %s", body, rec.Header().Get("Content-Type"))
+	}
+}
@@ -0,0 +1,53 @@
+package embedcode
+
+// OEmbed is an oEmbed 1.0 "rich" response (https://oembed.com).
+type OEmbed struct {
+	Type            string `json:"type"`
+	Version         string `json:"version"`
+	Title           string `json:"title,omitempty"`
+	AuthorName      string `json:"author_name,omitempty"`
+	AuthorURL       string `json:"author_url,omitempty"`
+	ProviderName    string `json:"provider_name,omitempty"`
+	ProviderURL     string `json:"provider_url,omitempty"`
+	CacheAge        int    `json:"cache_age,omitempty"`
+	ThumbnailURL    string `json:"thumbnail_url,omitempty"`
+	ThumbnailWidth  int    `json:"thumbnail_width,omitempty"`
+	ThumbnailHeight int    `json:"thumbnail_height,omitempty"`
+	HTML            string `json:"html"`
+	Width           int    `json:"width"`
+	Height          int    `json:"height"`
+}
+
+// Provider names the site hosting the content in oEmbed responses.
+type Provider struct {
+	Name string
+	URL  string
+}
+
+// NewOEmbed returns the oEmbed response for the content. maxWidth and
+// maxHeight are the consumer's limits from the request; zero means no
+// limit. The embed keeps its aspect ratio when scaled down.
+func NewOEmbed(opts Options, provider Provider, maxWidth, maxHeight int) (*OEmbed, error) {
+	w, h := opts.width(), opts.height()
+	if maxWidth > 0 && w > maxWidth {
+		w, h = maxWidth, h*maxWidth/w
+	}
+	if maxHeight > 0 && h > maxHeight {
+		w, h = w*maxHeight/h, maxHeight
+	}
+	opts.Width, opts.Height = w, h
+	snippet, err := Snippet(opts)
+	if err != nil {
+		return nil, err
+	}
+	return &OEmbed{
+		Type:         "rich",
+		Version:      "1.0",
+		Title:        opts.Title,
+		ProviderName: provider.Name,
+		ProviderURL:  provider.URL,
+		HTML:         snippet,
+		Width:        w,
+		Height:       h,
+	}, nil
+}
@@ -0,0 +1,89 @@
+package embedcode
+
+import (
+	"html"
+	"net/http"
+)
+
+// ResizerScript is a resizer speaking the postMessage protocol of H5P's
+// h5p-resizer.js: embedded content says "hello", then asks the parent page
+// to resize its iframe whenever the content height changes.
+const ResizerScript = `(function () {
+  if (!window.postMessage || !window.addEventListener || window.h5pResizerInitialized) {
+    return;
+  }
+  window.h5pResizerInitialized = true;
+
+  var handlers = {
+    hello: function (iframe, data, respond) {
+      iframe.style.width = "100%";
+      iframe.getBoundingClientRect();
+      var resize = function () {
+        if (iframe.contentWindow) {
+          respond("resize");
+        } else {
+          window.removeEventListener("resize", resize);
+        }
+      };
+      window.addEventListener("resize", resize, false);
+      respond("hello");
+    },
+    prepareResize: function (iframe, data, respond) {
+      if (iframe.clientHeight !== data.scrollHeight || data.scrollHeight !== data.clientHeight) {
+        iframe.style.height = data.clientHeight + "px";
+        respond("resizePrepared");
+      }
+    },
+    resize: function (iframe, data) {
+      iframe.style.height = data.scrollHeight + "px";
+    }
+  };
+
+  window.addEventListener("message", function (event) {
+    var data = event.data;
+    if (!data || data.context !== "h5p" || !handlers[data.action]) {
+      return;
+    }
+    var iframes = document.getElementsByTagName("iframe");
+    for (var i = 0; i < iframes.length; i++) {
+      var iframe = iframes[i];
+      if (iframe.contentWindow === event.source) {
+        handlers[data.action](iframe, data, function (action, response) {
+          response = response || {};
+          response.action = action;
+          response.context = "h5p";
+          iframe.contentWindow.postMessage(response, event.origin);
+        });
+        return;
+      }
+    }
+  }, false);
+
+  var iframes = document.getElementsByTagName("iframe");
+  for (var i = 0; i < iframes.length; i++) {
+    if (iframes[i].contentWindow) {
+      iframes[i].contentWindow.postMessage({ action: "ready", context: "h5p" }, "*");
+    }
+  }
+})();
+`
+
+// ResizerHandler serves ResizerScript, for use as Options.ResizerURL.
+func ResizerHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/javascript; charset=utf-8")
+		w.Header().Set("Cache-Control", "public, max-age=86400")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		_, _ = w.Write([]byte(ResizerScript))
+	})
+}
+
+// DiscoveryLink returns the <link> element that lets oEmbed consumers find
+// the oEmbed endpoint of a content page.
+func DiscoveryLink(oembedURL, title string) string {
+	s := `<link rel="alternate" type="application/json+oembed" href="` + html.EscapeString(oembedURL) + `"`
+	if title != "" {
+		s += ` title="` + html.EscapeString(title) + `"`
+	}
+	return s + `>`
+}
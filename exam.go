@@ -0,0 +1,110 @@
+package h5p
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Difficulty levels of ContentMetadata.Difficulty. Other values may be
+// used; rules match them by name.
+const (
+	DifficultyEasy   = "easy"
+	DifficultyMedium = "medium"
+	DifficultyHard   = "hard"
+)
+
+var ErrPoolTooSmall = errors.New("not enough questions in pool")
+
+// Tags returns the tags of the question metadata.
+func (q *Question) Tags() []string {
+	if q.Metadata == nil {
+		return nil
+	}
+	return q.Metadata.Tags
+}
+
+// HasTag reports whether the question is tagged with tag, ignoring case.
+func (q *Question) HasTag(tag string) bool {
+	return slices.ContainsFunc(q.Tags(), func(t string) bool { return strings.EqualFold(t, tag) })
+}
+
+// Difficulty returns the difficulty of the question metadata.
+func (q *Question) Difficulty() string {
+	if q.Metadata == nil {
+		return ""
+	}
+	return q.Metadata.Difficulty
+}
+
+// metadata returns the question metadata, creating it if needed.
+func (q *Question) metadata() *ContentMetadata {
+	if q.Metadata == nil {
+		q.Metadata = &ContentMetadata{}
+	}
+	return q.Metadata
+}
+
+// PoolRule selects questions for AssembleQuestionSet: Count questions
+// having all of Tags and, if set, the Difficulty.
+type PoolRule struct {
+	Count      int
+	Difficulty string
+	Tags       []string
+}
+
+// String describes the rule, e.g. "5 easy geography".
+func (r PoolRule) String() string {
+	parts := []string{fmt.Sprint(r.Count)}
+	if r.Difficulty != "" {
+		parts = append(parts, r.Difficulty)
+	}
+	parts = append(parts, r.Tags...)
+	return strings.Join(parts, " ")
+}
+
+// Match reports whether q meets the tags and difficulty of the rule.
+func (r PoolRule) Match(q *Question) bool {
+	if r.Difficulty != "" && !strings.EqualFold(q.Difficulty(), r.Difficulty) {
+		return false
+	}
+	for _, tag := range r.Tags {
+		if !q.HasTag(tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// AssembleQuestionSet builds a question set from a pool of questions by
+// rules such as "5 easy geography" and "3 hard history". The questions of
+// each rule are drawn at random by seed, so the same seed gives the same
+// exam, and follow those of the previous rules. A question is used at
+// most once. The result has the settings of template, if not nil, and
+// copies of the questions; it fails with ErrPoolTooSmall if a rule cannot
+// be met.
+func AssembleQuestionSet(template *QuestionSet, pool []Question, rules []PoolRule, seed int64) (*QuestionSet, error) {
+	r := questionRand(seed)
+	used := make([]bool, len(pool))
+	var questions []Question
+	for _, rule := range rules {
+		var candidates []int
+		for i := range pool {
+			if !used[i] && rule.Match(&pool[i]) {
+				candidates = append(candidates, i)
+			}
+		}
+		if rule.Count < 0 || rule.Count > len(candidates) {
+			return nil, fmt.Errorf("%w: %q matches %d questions", ErrPoolTooSmall, rule, len(candidates))
+		}
+		for _, j := range r.Perm(len(candidates))[:rule.Count] {
+			used[candidates[j]] = true
+			questions = append(questions, pool[candidates[j]])
+		}
+	}
+	if template == nil {
+		template = &QuestionSet{}
+	}
+	return template.withQuestions(template.Title, questions), nil
+}
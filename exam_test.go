@@ -0,0 +1,74 @@
+package h5p
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func examPool(t *testing.T) []Question {
+	t.Helper()
+	b := NewQuestionSetBuilder()
+	for i := range 6 {
+		b.AddTrueFalseQuestion(fmt.Sprintf("geography %d", i), true, nil).SetQuestionTags("Geography")
+		if i < 3 {
+			b.SetQuestionDifficulty(DifficultyEasy)
+		}
+	}
+	for i := range 4 {
+		b.AddTrueFalseQuestion(fmt.Sprintf("history %d", i), true, nil).
+			SetQuestionTags("history", "europe").
+			SetQuestionDifficulty(DifficultyHard)
+	}
+	qs, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return qs.Questions
+}
+
+func TestAssembleQuestionSet(t *testing.T) {
+	pool := examPool(t)
+	rules := []PoolRule{
+		{Count: 2, Difficulty: DifficultyEasy, Tags: []string{"geography"}},
+		{Count: 3, Difficulty: DifficultyHard, Tags: []string{"history"}},
+		{Count: 1, Tags: []string{"geography"}},
+	}
+	if s := rules[0].String(); s != "2 easy geography" {
+		t.Errorf("Unexpected rule description %q", s)
+	}
+
+	template := &QuestionSet{Title: "Exam", PassPercentage: 60}
+	a, err := AssembleQuestionSet(template, pool, rules, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := AssembleQuestionSet(template, pool, rules, 5)
+	if a.Title != "Exam" || a.PassPercentage != 60 || len(a.Questions) != 6 {
+		t.Fatalf("Unexpected exam %+v", a)
+	}
+	ids := func(qs *QuestionSet) []string {
+		var ids []string
+		for _, q := range qs.Questions {
+			ids = append(ids, q.SubContentID)
+		}
+		return ids
+	}
+	if !slices.Equal(ids(a), ids(b)) {
+		t.Error("Expected the same exam for the same seed")
+	}
+	if len(slices.Compact(slices.Sorted(slices.Values(ids(a))))) != 6 {
+		t.Errorf("Expected distinct questions, got %v", ids(a))
+	}
+	for i, rule := range []PoolRule{rules[0], rules[0], rules[1], rules[1], rules[1], rules[2]} {
+		if q := a.Questions[i]; !rule.Match(&q) {
+			t.Errorf("Question %d does not match %q: %+v", i, rule, q.Metadata)
+		}
+	}
+
+	_, err = AssembleQuestionSet(nil, pool, []PoolRule{{Count: 5, Difficulty: DifficultyHard}}, 1)
+	if !errors.Is(err, ErrPoolTooSmall) {
+		t.Errorf("Expected ErrPoolTooSmall, got %v", err)
+	}
+}
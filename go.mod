@@ -1,3 +1,5 @@
 module github.com/grokify/h5p-go
 
 go 1.24.5
+
+require gopkg.in/yaml.v3 v3.0.1
@@ -0,0 +1,304 @@
+// Package grading scores learner responses against question params on the
+// server, the way the H5P content types score them in the browser, so
+// scores reported by the player need not be trusted.
+//
+// Each function takes the typed params of a content type and the response
+// in a plain form: indexes of the selected answer options or words, or the
+// texts entered in gaps and dropped on drop zones, in order.
+package grading
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/grokify/h5p-go/schemas"
+)
+
+var ErrInvalidResponse = errors.New("invalid response")
+
+// Response is a learner's response to a question in the form of its type:
+// Selected for MultiChoice answers and MarkTheWords words, Texts for Blanks
+// gaps and DragText drop zones, Answer for TrueFalse. A zero Response is an
+// unanswered question.
+type Response struct {
+	Selected []int    `json:"selected,omitempty"`
+	Texts    []string `json:"texts,omitempty"`
+	Answer   *bool    `json:"answer,omitempty"`
+}
+
+// Result is the score of a response with the grading of each of its
+// parts.
+type Result struct {
+	Score    int    `json:"score"`
+	MaxScore int    `json:"maxScore"`
+	Items    []Item `json:"items"`
+}
+
+// Scaled returns the score from 0 to 1, or 0 if MaxScore is 0.
+func (r *Result) Scaled() float64 {
+	if r.MaxScore <= 0 {
+		return 0
+	}
+	return float64(r.Score) / float64(r.MaxScore)
+}
+
+// Passed reports whether the response got full marks.
+func (r *Result) Passed() bool {
+	return r.Score == r.MaxScore
+}
+
+// Item is the grading of an answer option or word, or of a gap or drop
+// zone.
+type Item struct {
+	// Text is the option or word, or the text the learner entered in the
+	// gap or dropped on the zone.
+	Text string `json:"text"`
+	// Selected reports whether the learner selected the option or word.
+	Selected bool `json:"selected,omitempty"`
+	// Solution reports whether the option or word is one to select.
+	Solution bool `json:"solution,omitempty"`
+	// Expected lists the answers accepted for the gap or drop zone.
+	Expected []string `json:"expected,omitempty"`
+	// Correct reports whether the item earns a point: a solution that was
+	// selected, or an accepted answer.
+	Correct bool `json:"correct"`
+}
+
+// Wrong reports whether the learner selected an option or word that is
+// not a solution, which costs a point.
+func (it Item) Wrong() bool {
+	return it.Selected && !it.Solution
+}
+
+// selection checks that selected holds distinct indexes below n and
+// returns them as a set.
+func selection(selected []int, n int) (map[int]bool, error) {
+	set := make(map[int]bool, len(selected))
+	for _, i := range selected {
+		if i < 0 || i >= n {
+			return nil, fmt.Errorf("%w: index %d out of range [0, %d)", ErrInvalidResponse, i, n)
+		}
+		if set[i] {
+			return nil, fmt.Errorf("%w: index %d selected twice", ErrInvalidResponse, i)
+		}
+		set[i] = true
+	}
+	return set, nil
+}
+
+// MultiChoice scores the indexes of the selected answers. Each selected
+// correct answer scores a point and each selected wrong answer costs one,
+// down to 0, out of the number of correct answers. Single answer questions,
+// with behaviour type "single" or "auto" and one correct answer, score 1 or
+// 0, as do questions with behaviour singlePoint, which pass when the score
+// reaches passPercentage.
+func MultiChoice(p *schemas.MultiChoiceParams, selected []int) (*Result, error) {
+	set, err := selection(selected, len(p.Answers))
+	if err != nil {
+		return nil, err
+	}
+	r := &Result{}
+	solutions := 0
+	for i, a := range p.Answers {
+		it := Item{Text: plain(a.Text), Selected: set[i], Solution: a.Correct}
+		it.Correct = it.Selected && it.Solution
+		switch {
+		case it.Correct:
+			r.Score++
+		case it.Wrong():
+			r.Score--
+		}
+		if a.Correct {
+			solutions++
+		}
+		r.Items = append(r.Items, it)
+	}
+	r.Score = max(r.Score, 0)
+	raw := r.Score
+
+	b := p.Behaviour
+	if b == nil {
+		b = &schemas.Behaviour{}
+	}
+	single := b.Type == "single" || ((b.Type == "" || b.Type == "auto") && solutions == 1)
+	switch {
+	case single && len(selected) > 1:
+		return nil, fmt.Errorf("%w: %d answers selected for a single answer question", ErrInvalidResponse, len(selected))
+	case solutions == 0:
+		// Without correct answers, selecting none is right.
+		r.MaxScore = 1
+		r.Score = 0
+		if len(selected) == 0 {
+			r.Score = 1
+		}
+	case single:
+		r.MaxScore = 1
+	case b.SinglePoint:
+		pass := b.PassPercentage
+		if pass <= 0 {
+			pass = 100
+		}
+		r.MaxScore = 1
+		r.Score = 0
+		if 100*raw >= pass*solutions {
+			r.Score = 1
+		}
+	default:
+		r.MaxScore = solutions
+	}
+	return r, nil
+}
+
+// TrueFalse scores the answer given, 1 if it is the correct one.
+func TrueFalse(p *schemas.TrueFalseParams, answer bool) *Result {
+	it := Item{Text: fmt.Sprint(answer), Selected: true, Solution: answer == p.IsTrue(), Expected: []string{fmt.Sprint(p.IsTrue())}}
+	it.Correct = it.Solution
+	r := &Result{MaxScore: 1, Items: []Item{it}}
+	if it.Correct {
+		r.Score = 1
+	}
+	return r
+}
+
+// Blanks scores the texts entered in the gaps of all question texts, a
+// point for each accepted answer; nil answers leave all gaps empty. Answers are compared case-sensitively
+// unless behaviour caseSensitive is off; with acceptSpellingErrors, answers
+// longer than 3 characters may be one edit off and those longer than 9
+// characters two, counting a swap of adjacent characters as one edit.
+func Blanks(p *schemas.BlanksParams, answers []string) (*Result, error) {
+	var blanks []schemas.Blank
+	for _, text := range p.Questions {
+		blanks = append(blanks, schemas.ParseBlanks(text)...)
+	}
+	if answers == nil {
+		answers = make([]string, len(blanks))
+	}
+	if len(answers) != len(blanks) {
+		return nil, fmt.Errorf("%w: %d answers for %d gaps", ErrInvalidResponse, len(answers), len(blanks))
+	}
+	caseSensitive := p.Behaviour == nil || p.Behaviour.CaseSensitive
+	spelling := p.Behaviour != nil && p.Behaviour.AcceptSpellingErrors
+	r := &Result{MaxScore: len(blanks)}
+	for i, b := range blanks {
+		it := Item{Text: answers[i]}
+		for _, a := range b.Answers {
+			a = plain(a)
+			it.Expected = append(it.Expected, a)
+			if !it.Correct && acceptBlank(a, answers[i], caseSensitive, spelling) {
+				it.Correct = true
+				r.Score++
+			}
+		}
+		r.Items = append(r.Items, it)
+	}
+	return r, nil
+}
+
+func acceptBlank(want, got string, caseSensitive, spelling bool) bool {
+	want, got = normalizeSpace(want), normalizeSpace(got)
+	if !caseSensitive {
+		want, got = strings.ToLower(want), strings.ToLower(got)
+	}
+	if want == got {
+		return true
+	}
+	if !spelling {
+		return false
+	}
+	n := len([]rune(want))
+	d := editDistance(want, got)
+	return (n > 9 && d <= 2) || (n > 3 && d <= 1)
+}
+
+// editDistance returns the optimal string alignment distance of a and b:
+// the insertions, deletions, substitutions and swaps of adjacent runes
+// turning one into the other.
+func editDistance(a, b string) int {
+	s, t := []rune(a), []rune(b)
+	d := make([][]int, len(s)+1)
+	for i := range d {
+		d[i] = make([]int, len(t)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(s); i++ {
+		for j := 1; j <= len(t); j++ {
+			cost := 1
+			if s[i-1] == t[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && s[i-1] == t[j-2] && s[i-2] == t[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(s)][len(t)]
+}
+
+// DragText scores the words dropped on the drop zones, "" for an empty
+// zone, a point for each zone holding its word; nil leaves all zones empty.
+func DragText(p *schemas.DragTextParams, dropped []string) (*Result, error) {
+	draggables := schemas.ParseDraggables(p.TextField)
+	if dropped == nil {
+		dropped = make([]string, len(draggables))
+	}
+	if len(dropped) != len(draggables) {
+		return nil, fmt.Errorf("%w: %d words for %d drop zones", ErrInvalidResponse, len(dropped), len(draggables))
+	}
+	r := &Result{MaxScore: len(draggables)}
+	for i, d := range draggables {
+		want := plain(d.Answer)
+		it := Item{Text: dropped[i], Expected: []string{want}, Correct: strings.TrimSpace(dropped[i]) == want}
+		if it.Correct {
+			r.Score++
+		}
+		r.Items = append(r.Items, it)
+	}
+	return r, nil
+}
+
+// MarkTheWords scores the indexes of the selected words, as returned by
+// schemas.ParseMarkableWords. Each selected correct word scores a point and
+// each selected wrong word costs one, down to 0, out of the number of
+// correct words.
+func MarkTheWords(p *schemas.MarkTheWordsParams, selected []int) (*Result, error) {
+	words := schemas.ParseMarkableWords(p.TextField)
+	set, err := selection(selected, len(words))
+	if err != nil {
+		return nil, err
+	}
+	r := &Result{}
+	for i, w := range words {
+		it := Item{Text: w.Text, Selected: set[i], Solution: w.Correct}
+		it.Correct = it.Selected && it.Solution
+		switch {
+		case it.Correct:
+			r.Score++
+		case it.Wrong():
+			r.Score--
+		}
+		if w.Correct {
+			r.MaxScore++
+		}
+		r.Items = append(r.Items, it)
+	}
+	r.Score = max(r.Score, 0)
+	return r, nil
+}
+
+var htmlTag = regexp.MustCompile(`<[^>]*>`)
+
+// plain returns the text of answers, which H5P stores as HTML.
+func plain(s string) string {
+	return normalizeSpace(html.UnescapeString(htmlTag.ReplaceAllString(s, " ")))
+}
+
+func normalizeSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,142 @@
+package grading
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/grokify/h5p-go/schemas"
+)
+
+func multiChoice(behaviour *schemas.Behaviour, correct ...bool) *schemas.MultiChoiceParams {
+	p := &schemas.MultiChoiceParams{Question: "Pick", Behaviour: behaviour}
+	for i, c := range correct {
+		p.Answers = append(p.Answers, schemas.AnswerOption{Text: "<div>Option " + string(rune('A'+i)) + "</div>", Correct: c})
+	}
+	return p
+}
+
+func TestMultiChoice(t *testing.T) {
+	tests := []struct {
+		name      string
+		params    *schemas.MultiChoiceParams
+		selected  []int
+		score     int
+		max       int
+		wantError bool
+	}{
+		{"multi all correct", multiChoice(nil, true, true, false), []int{0, 1}, 2, 2, false},
+		{"multi with wrong", multiChoice(nil, true, true, false), []int{0, 2}, 0, 2, false},
+		{"multi not below zero", multiChoice(nil, true, true, false), []int{2}, 0, 2, false},
+		{"single correct", multiChoice(nil, false, true, false), []int{1}, 1, 1, false},
+		{"single wrong", multiChoice(nil, false, true, false), []int{0}, 0, 1, false},
+		{"single two selected", multiChoice(nil, false, true, false), []int{0, 1}, 0, 0, true},
+		{"single point pass", multiChoice(&schemas.Behaviour{SinglePoint: true, PassPercentage: 50}, true, true, false), []int{0}, 1, 1, false},
+		{"single point fail", multiChoice(&schemas.Behaviour{SinglePoint: true}, true, true, false), []int{0}, 0, 1, false},
+		{"none correct", multiChoice(nil, false, false), nil, 1, 1, false},
+		{"out of range", multiChoice(nil, true), []int{3}, 0, 0, true},
+		{"duplicate", multiChoice(&schemas.Behaviour{Type: "multi"}, true), []int{0, 0}, 0, 0, true},
+	}
+	for _, tt := range tests {
+		r, err := MultiChoice(tt.params, tt.selected)
+		if tt.wantError {
+			if !errors.Is(err, ErrInvalidResponse) {
+				t.Errorf("%s: expected ErrInvalidResponse, got %v", tt.name, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if r.Score != tt.score || r.MaxScore != tt.max {
+			t.Errorf("%s: expected %d/%d, got %d/%d", tt.name, tt.score, tt.max, r.Score, r.MaxScore)
+		}
+	}
+
+	r, _ := MultiChoice(multiChoice(nil, true, false), []int{1})
+	if it := r.Items[1]; it.Text != "Option B" || !it.Wrong() || it.Correct {
+		t.Errorf("Unexpected item %+v", it)
+	}
+}
+
+func TestTrueFalse(t *testing.T) {
+	p := &schemas.TrueFalseParams{Correct: "false"}
+	if r := TrueFalse(p, false); r.Score != 1 || r.MaxScore != 1 || !r.Passed() {
+		t.Errorf("Expected full score, got %+v", r)
+	}
+	if r := TrueFalse(p, true); r.Score != 0 || r.Items[0].Expected[0] != "false" {
+		t.Errorf("Expected no score, got %+v", r)
+	}
+}
+
+func TestBlanks(t *testing.T) {
+	p := &schemas.BlanksParams{Questions: []string{"<p>*Paris/Lutetia* is in *France*.</p>", "<p>*Strawberries* are red.</p>"}}
+	r, err := Blanks(p, []string{" Lutetia ", "france", "Strawbery"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Score != 1 || r.MaxScore != 3 || !r.Items[0].Correct || len(r.Items[0].Expected) != 2 {
+		t.Errorf("Expected only the first gap correct, got %+v", r)
+	}
+
+	p.Behaviour = &schemas.BlanksBehaviour{CaseSensitive: false, AcceptSpellingErrors: true}
+	r, _ = Blanks(p, []string{"Prais", "france", "Strawbery"})
+	if r.Score != 2 || r.Items[2].Correct {
+		t.Errorf("Expected case and one spelling error accepted, got %+v", r)
+	}
+	if acceptBlank("red", "rad", false, true) || !acceptBlank("Strawberries", "Strawbries", false, true) {
+		t.Error("Expected no spelling errors in short answers and two in long ones")
+	}
+	if _, err := Blanks(p, []string{"Paris"}); !errors.Is(err, ErrInvalidResponse) {
+		t.Errorf("Expected ErrInvalidResponse, got %v", err)
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	for _, tt := range []struct {
+		a, b string
+		want int
+	}{
+		{"", "abc", 3},
+		{"kitten", "sitting", 3},
+		{"receive", "recieve", 1},
+		{"héllo", "hello", 1},
+	} {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestDragText(t *testing.T) {
+	p := &schemas.DragTextParams{TextField: "Cats *meow*.\nDogs *bark:Loud*."}
+	r, err := DragText(p, []string{"meow", ""})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Score != 1 || r.MaxScore != 2 || r.Items[1].Expected[0] != "bark" {
+		t.Errorf("Unexpected result %+v", r)
+	}
+	if _, err := DragText(p, []string{"meow"}); !errors.Is(err, ErrInvalidResponse) {
+		t.Errorf("Expected ErrInvalidResponse, got %v", err)
+	}
+}
+
+func TestMarkTheWords(t *testing.T) {
+	// Words 0-9: The sun is a star and the moon orbits us.
+	p := &schemas.MarkTheWordsParams{TextField: "<p>The *sun* is a *star* and the moon orbits *us*.</p>"}
+	r, err := MarkTheWords(p, []int{1, 4, 8})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// sun and star score, orbits costs a point.
+	if r.Score != 1 || r.MaxScore != 3 || !r.Items[8].Wrong() {
+		t.Errorf("Unexpected result %+v", r)
+	}
+	if r, _ := MarkTheWords(p, []int{0, 2}); r.Score != 0 {
+		t.Errorf("Expected the score not to go below 0, got %d", r.Score)
+	}
+	if _, err := MarkTheWords(p, []int{10}); !errors.Is(err, ErrInvalidResponse) {
+		t.Errorf("Expected ErrInvalidResponse, got %v", err)
+	}
+}
@@ -2,18 +2,52 @@ package h5p
 
 import (
 	"archive/zip"
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
-	"path/filepath"
+	"path"
 	"strings"
 )
 
 type H5PPackage struct {
 	PackageDefinition *PackageDefinition `json:"-"`
 	Content           *Content           `json:"-"`
-	Libraries         []*Library         `json:"-"`
+	// Libraries holds the library folders of the package. Add libraries
+	// with AddLibrary or by replacing the slice: lookups such as
+	// GetLibrary use an index that does not see libraries assigned to
+	// elements of the slice, or renamed, in place.
+	Libraries []*Library `json:"-"`
+
+	// ContentFiles holds assets from the content folder, keyed by their path
+	// relative to it (e.g. "images/photo.jpg").
+	ContentFiles map[string]*ContentFile `json:"-"`
+
+	// ExtraFiles holds archive entries that are neither definitions, content
+	// nor library files, such as a top-level LICENSE or vendor files, keyed
+	// by archive path. They are written back unchanged.
+	ExtraFiles map[string]*ContentFile `json:"-"`
+
+	// Manifest is the integrity manifest written as h5p-manifest.json, if
+	// any. See EmbedManifest and SignPackage.
+	Manifest *Manifest `json:"-"`
+
+	// Repairs lists the fixes applied to malformed definition files while
+	// loading. See PackageLoader.LenientJSON.
+	Repairs []JSONRepair `json:"-"`
+
+	// closers release archives backing lazily loaded files.
+	closers []io.Closer
+
+	libIndex libraryIndex
+
+	// libraryDirs holds the top-level folders found to contain a
+	// library.json while loading, before that file itself is processed.
+	libraryDirs map[string]bool
 }
 
 type PackageDefinition struct {
@@ -22,8 +56,14 @@ type PackageDefinition struct {
 	MainLibrary           string              `json:"mainLibrary"`
 	EmbedTypes            []string            `json:"embedTypes"`
 	License               string              `json:"license,omitempty"`
+	LicenseVersion        string              `json:"licenseVersion,omitempty"`
+	LicenseExtras         string              `json:"licenseExtras,omitempty"`
+	Source                string              `json:"source,omitempty"`
+	YearFrom              int                 `json:"yearFrom,omitempty"`
+	YearTo                int                 `json:"yearTo,omitempty"`
 	DefaultLanguage       string              `json:"defaultLanguage,omitempty"`
 	Author                string              `json:"author,omitempty"`
+	Authors               []Author            `json:"authors,omitempty"`
 	PreloadedDependencies []LibraryDependency `json:"preloadedDependencies"`
 	EditorDependencies    []LibraryDependency `json:"editorDependencies,omitempty"`
 }
@@ -34,32 +74,103 @@ type LibraryDependency struct {
 	MinorVersion int    `json:"minorVersion"`
 }
 
+// Content is content/content.json. Params holds the params of the main
+// library, written as the file's top-level object; content built around a
+// QuestionSet uses the {"questionSet": ...} form instead.
 type Content struct {
 	QuestionSet *QuestionSet `json:"questionSet,omitempty"`
 	Params      interface{}  `json:",omitempty"`
 }
 
+// contentFields is Content without its JSON methods.
+type contentFields Content
+
+func (c Content) MarshalJSON() ([]byte, error) {
+	if c.QuestionSet == nil && c.Params != nil {
+		return json.Marshal(c.Params)
+	}
+	return json.Marshal(contentFields(c))
+}
+
+// UnmarshalJSON reads content.json, keeping any object other than the
+// {"questionSet": ...} form as generic JSON in Params.
+func (c *Content) UnmarshalJSON(data []byte) error {
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return err
+	}
+	_, hasQuestionSet := keys["questionSet"]
+	_, hasParams := keys["Params"]
+	if len(keys) == 0 || hasQuestionSet || hasParams {
+		return json.Unmarshal(data, (*contentFields)(c))
+	}
+	var params map[string]any
+	if err := json.Unmarshal(data, &params); err != nil {
+		return err
+	}
+	*c = Content{Params: params}
+	return nil
+}
+
 type Library struct {
 	Definition  *LibraryDefinition `json:"-"`
 	Semantics   interface{}        `json:"-"`
 	MachineName string             `json:"-"`
 	Files       map[string][]byte  `json:"-"`
+
+	// LazyFiles holds files whose contents are read on demand, e.g. from the
+	// archive a package was loaded from with PackageLoader.Lazy set.
+	LazyFiles map[string]FileOpener `json:"-"`
+
+	// definitionJSON holds library.json as read from the archive so an
+	// unmodified definition is written back byte-for-byte.
+	definitionJSON []byte
 }
 
 type LibraryDefinition struct {
-	Title          string              `json:"title"`
-	MachineName    string              `json:"machineName"`
-	MajorVersion   int                 `json:"majorVersion"`
-	MinorVersion   int                 `json:"minorVersion"`
-	PatchVersion   int                 `json:"patchVersion"`
-	Runnable       bool                `json:"runnable"`
-	Author         string              `json:"author,omitempty"`
-	License        string              `json:"license,omitempty"`
-	Description    string              `json:"description,omitempty"`
-	PreloadedJs    []FileReference     `json:"preloadedJs,omitempty"`
-	PreloadedCss   []FileReference     `json:"preloadedCss,omitempty"`
-	DropLibraryCss []FileReference     `json:"dropLibraryCss,omitempty"`
-	Dependencies   []LibraryDependency `json:"preloadedDependencies,omitempty"`
+	Title               string              `json:"title"`
+	Description         string              `json:"description,omitempty"`
+	MachineName         string              `json:"machineName"`
+	MajorVersion        int                 `json:"majorVersion"`
+	MinorVersion        int                 `json:"minorVersion"`
+	PatchVersion        int                 `json:"patchVersion"`
+	Runnable            BoolInt             `json:"runnable"`
+	Fullscreen          BoolInt             `json:"fullscreen,omitempty"`
+	Author              string              `json:"author,omitempty"`
+	Authors             []Author            `json:"authors,omitempty"`
+	License             string              `json:"license,omitempty"`
+	CoreAPI             *CoreAPI            `json:"coreApi,omitempty"`
+	EmbedTypes          []string            `json:"embedTypes,omitempty"`
+	PreloadedJs         []FileReference     `json:"preloadedJs,omitempty"`
+	PreloadedCss        []FileReference     `json:"preloadedCss,omitempty"`
+	DropLibraryCss      []LibraryReference  `json:"dropLibraryCss,omitempty"`
+	Dependencies        []LibraryDependency `json:"preloadedDependencies,omitempty"`
+	DynamicDependencies []LibraryDependency `json:"dynamicDependencies,omitempty"`
+	EditorDependencies  []LibraryDependency `json:"editorDependencies,omitempty"`
+	MetadataSettings    *MetadataSettings   `json:"metadataSettings,omitempty"`
+}
+
+// CoreAPI is the minimum H5P core API version a library requires.
+type CoreAPI struct {
+	MajorVersion int `json:"majorVersion"`
+	MinorVersion int `json:"minorVersion"`
+}
+
+// Author is a named contributor with an optional role such as "Author" or "Editor".
+type Author struct {
+	Name string `json:"name"`
+	Role string `json:"role,omitempty"`
+}
+
+// MetadataSettings controls the metadata form the H5P editor shows for a library.
+type MetadataSettings struct {
+	Disable                BoolInt `json:"disable"`
+	DisableExtraTitleField BoolInt `json:"disableExtraTitleField"`
+}
+
+// LibraryReference names a library whose CSS should be dropped.
+type LibraryReference struct {
+	MachineName string `json:"machineName"`
 }
 
 type FileReference struct {
@@ -81,33 +192,72 @@ func (pkg *H5PPackage) SetContent(content *Content) {
 }
 
 func (pkg *H5PPackage) AddLibrary(lib *Library) {
+	fresh := pkg.libIndex.fresh(pkg.Libraries)
 	pkg.Libraries = append(pkg.Libraries, lib)
+	if !fresh {
+		pkg.indexLibraries()
+		return
+	}
+	pkg.indexLibrary(len(pkg.Libraries) - 1)
+	pkg.libIndex.track(pkg.Libraries)
+}
+
+// replaceLibrary puts lib at position i of Libraries, moving the index
+// entries of the library it replaces.
+func (pkg *H5PPackage) replaceLibrary(i int, lib *Library) {
+	pkg.updateLibrary(i, func() { pkg.Libraries[i] = lib })
 }
 
+// CreateZipFile writes the package as a .h5p archive to outputPath, as
+// WriteTo does, so files outside AllowedContentFileExtensions are refused.
 func (pkg *H5PPackage) CreateZipFile(outputPath string) error {
 	file, err := os.Create(outputPath)
 	if err != nil {
 		return fmt.Errorf("failed to create zip file: %w", err)
 	}
-	defer file.Close()
 
-	zipWriter := zip.NewWriter(file)
-	defer zipWriter.Close()
-
-	if err := pkg.writeToZip(zipWriter); err != nil {
-		return fmt.Errorf("failed to write package to zip: %w", err)
+	if _, err := pkg.WriteTo(file); err != nil {
+		file.Close()
+		return err
 	}
 
-	return nil
+	return file.Close()
+}
+
+// WriteTo writes the package as a .h5p archive to w, implementing io.WriterTo.
+// All entries are deflated at the default level, and a package with files
+// outside AllowedContentFileExtensions fails with ErrFileExtension; use
+// WriteToWithOptions to control compression or allow other files.
+func (pkg *H5PPackage) WriteTo(w io.Writer) (int64, error) {
+	return pkg.WriteToWithOptions(w, WriteOptions{AllowedExtensions: AllowedContentFileExtensions})
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
+
+func (pkg *H5PPackage) writeToZip(ctx context.Context, zipWriter *zip.Writer, opts WriteOptions) error {
+	return pkg.walkFilesContext(ctx, opts.Progress, func(name string, r io.Reader) error {
+		return writeEntryToZip(zipWriter, name, opts.method(name), r)
+	})
 }
 
-func (pkg *H5PPackage) writeToZip(zipWriter *zip.Writer) error {
+// walkFiles calls fn for every file of the package archive, using
+// slash-separated paths relative to the package root.
+func (pkg *H5PPackage) walkFiles(fn func(name string, r io.Reader) error) error {
 	if pkg.PackageDefinition != nil {
 		h5pJSON, err := json.MarshalIndent(pkg.PackageDefinition, "", "  ")
 		if err != nil {
 			return fmt.Errorf("failed to marshal h5p.json: %w", err)
 		}
-		if err := writeFileToZip(zipWriter, "h5p.json", h5pJSON); err != nil {
+		if err := fn("h5p.json", bytes.NewReader(h5pJSON)); err != nil {
 			return err
 		}
 	}
@@ -117,19 +267,27 @@ func (pkg *H5PPackage) writeToZip(zipWriter *zip.Writer) error {
 		if err != nil {
 			return fmt.Errorf("failed to marshal content.json: %w", err)
 		}
-		if err := writeFileToZip(zipWriter, "content/content.json", contentJSON); err != nil {
+		if err := fn("content/content.json", bytes.NewReader(contentJSON)); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range pkg.ContentFileNames() {
+		if err := walkContentFile(pkg.ContentFiles[name], func(r io.Reader) error {
+			return fn(ContentDir+"/"+name, r)
+		}); err != nil {
 			return err
 		}
 	}
 
 	for _, lib := range pkg.Libraries {
 		if lib.Definition != nil {
-			libJSON, err := json.MarshalIndent(lib.Definition, "", "  ")
+			libJSON, err := lib.definitionBytes()
 			if err != nil {
 				return fmt.Errorf("failed to marshal library.json: %w", err)
 			}
 			libPath := fmt.Sprintf("%s/library.json", lib.MachineName)
-			if err := writeFileToZip(zipWriter, libPath, libJSON); err != nil {
+			if err := fn(libPath, bytes.NewReader(libJSON)); err != nil {
 				return err
 			}
 		}
@@ -140,93 +298,158 @@ func (pkg *H5PPackage) writeToZip(zipWriter *zip.Writer) error {
 				return fmt.Errorf("failed to marshal semantics.json: %w", err)
 			}
 			semPath := fmt.Sprintf("%s/semantics.json", lib.MachineName)
-			if err := writeFileToZip(zipWriter, semPath, semJSON); err != nil {
+			if err := fn(semPath, bytes.NewReader(semJSON)); err != nil {
 				return err
 			}
 		}
 
-		for filePath, fileData := range lib.Files {
+		for _, filePath := range lib.FileNames() {
 			fullPath := fmt.Sprintf("%s/%s", lib.MachineName, filePath)
-			if err := writeFileToZip(zipWriter, fullPath, fileData); err != nil {
+			if err := walkLibraryFile(lib, filePath, func(r io.Reader) error {
+				return fn(fullPath, r)
+			}); err != nil {
 				return err
 			}
 		}
 	}
 
+	for _, name := range sortedFileNames(pkg.ExtraFiles) {
+		if err := walkContentFile(pkg.ExtraFiles[name], func(r io.Reader) error {
+			return fn(name, r)
+		}); err != nil {
+			return err
+		}
+	}
+
+	if pkg.Manifest != nil {
+		manifestJSON, err := json.MarshalIndent(pkg.Manifest, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal %s: %w", ManifestFile, err)
+		}
+		if err := fn(ManifestFile, bytes.NewReader(manifestJSON)); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
 func writeFileToZip(zipWriter *zip.Writer, filename string, data []byte) error {
-	writer, err := zipWriter.Create(filename)
+	return writeReaderToZip(zipWriter, filename, bytes.NewReader(data))
+}
+
+func writeReaderToZip(zipWriter *zip.Writer, filename string, r io.Reader) error {
+	return writeEntryToZip(zipWriter, filename, zip.Deflate, r)
+}
+
+func writeEntryToZip(zipWriter *zip.Writer, filename string, method uint16, r io.Reader) error {
+	writer, err := zipWriter.CreateHeader(&zip.FileHeader{Name: filename, Method: method})
 	if err != nil {
 		return fmt.Errorf("failed to create zip entry for %s: %w", filename, err)
 	}
 
-	_, err = writer.Write(data)
-	if err != nil {
+	if _, err := io.Copy(writer, r); err != nil {
 		return fmt.Errorf("failed to write data to %s: %w", filename, err)
 	}
 
 	return nil
 }
 
-func LoadH5PPackage(filePath string) (*H5PPackage, error) {
-	reader, err := zip.OpenReader(filePath)
+func walkContentFile(cf *ContentFile, fn func(r io.Reader) error) error {
+	rc, err := cf.Open()
 	if err != nil {
-		return nil, fmt.Errorf("failed to open H5P file: %w", err)
-	}
-	defer reader.Close()
-
-	pkg := NewH5PPackage()
-
-	for _, file := range reader.File {
-		if err := pkg.processZipFile(file); err != nil {
-			return nil, fmt.Errorf("failed to process file %s: %w", file.Name, err)
-		}
+		return err
 	}
-
-	return pkg, nil
+	defer rc.Close()
+	return fn(rc)
 }
 
-func (pkg *H5PPackage) processZipFile(file *zip.File) error {
-	rc, err := file.Open()
+func walkLibraryFile(lib *Library, name string, fn func(r io.Reader) error) error {
+	rc, err := lib.OpenFile(name)
 	if err != nil {
 		return err
 	}
 	defer rc.Close()
+	return fn(rc)
+}
 
-	data, err := io.ReadAll(rc)
-	if err != nil {
-		return err
+// Close releases any archive kept open for lazily loaded files. It is a
+// no-op for packages loaded eagerly or built in memory.
+func (pkg *H5PPackage) Close() error {
+	var errs []error
+	for _, c := range pkg.closers {
+		errs = append(errs, c.Close())
 	}
+	pkg.closers = nil
+	return errors.Join(errs...)
+}
+
+// LoadH5PPackage loads a .h5p file using the default PackageLoader limits.
+func LoadH5PPackage(filePath string) (*H5PPackage, error) {
+	return NewPackageLoader().Load(filePath)
+}
+
+// LoadH5PPackageStrict is LoadH5PPackage that fails with an
+// *UnknownFieldsError if the definition files contain unmodelled fields.
+func LoadH5PPackageStrict(filePath string) (*H5PPackage, error) {
+	loader := NewPackageLoader()
+	loader.Strict = true
+	return loader.Load(filePath)
+}
+
+// LoadH5PPackageContext is LoadH5PPackage that stops reading when ctx is
+// cancelled.
+func LoadH5PPackageContext(ctx context.Context, filePath string) (*H5PPackage, error) {
+	return NewPackageLoader().LoadContext(ctx, filePath)
+}
+
+// LoadH5PPackageFromReader loads a .h5p archive of the given size from r,
+// e.g. an HTTP upload or an object storage download.
+func LoadH5PPackageFromReader(r io.ReaderAt, size int64) (*H5PPackage, error) {
+	return NewPackageLoader().LoadReader(r, size)
+}
+
+// LoadH5PPackageFS loads an unpacked package whose root (containing
+// h5p.json) is the root of fsys.
+func LoadH5PPackageFS(fsys fs.FS) (*H5PPackage, error) {
+	return NewPackageLoader().LoadFS(fsys)
+}
 
+func (pkg *H5PPackage) processFile(name string, data []byte) error {
 	switch {
-	case file.Name == "h5p.json":
+	case name == "h5p.json":
 		var pkgDef PackageDefinition
 		if err := json.Unmarshal(data, &pkgDef); err != nil {
 			return err
 		}
 		pkg.PackageDefinition = &pkgDef
 
-	case file.Name == "content/content.json":
+	case name == "content/content.json":
 		var content Content
 		if err := json.Unmarshal(data, &content); err != nil {
 			return err
 		}
 		pkg.Content = &content
 
-	case strings.HasSuffix(file.Name, "/library.json"):
-		libName := filepath.Dir(file.Name)
+	case name == ManifestFile:
+		var manifest Manifest
+		if err := json.Unmarshal(data, &manifest); err != nil {
+			return err
+		}
+		pkg.Manifest = &manifest
+
+	case isLibraryDefinitionFile(name, "library.json"):
+		libName := path.Dir(name)
 		lib := pkg.findOrCreateLibrary(libName)
 
 		var libDef LibraryDefinition
 		if err := json.Unmarshal(data, &libDef); err != nil {
 			return err
 		}
-		lib.Definition = &libDef
+		pkg.setLibraryDefinition(lib, &libDef, data)
 
-	case strings.HasSuffix(file.Name, "/semantics.json"):
-		libName := filepath.Dir(file.Name)
+	case isLibraryDefinitionFile(name, "semantics.json"):
+		libName := path.Dir(name)
 		lib := pkg.findOrCreateLibrary(libName)
 
 		var semantics interface{}
@@ -236,37 +459,107 @@ func (pkg *H5PPackage) processZipFile(file *zip.File) error {
 		lib.Semantics = semantics
 
 	default:
-		if strings.Contains(file.Name, "/") {
-			libName := strings.Split(file.Name, "/")[0]
-			if pkg.isLibraryDirectory(libName) {
-				lib := pkg.findOrCreateLibrary(libName)
-				if lib.Files == nil {
-					lib.Files = make(map[string][]byte)
-				}
-				relativePath := strings.TrimPrefix(file.Name, libName+"/")
-				lib.Files[relativePath] = data
+		if assetPath, ok := contentFilePath(name); ok {
+			if pkg.ContentFiles == nil {
+				pkg.ContentFiles = make(map[string]*ContentFile)
 			}
+			pkg.ContentFiles[assetPath] = &ContentFile{Data: data, Mime: mimeTypeByPath(assetPath)}
+		} else if libName, relativePath, ok := pkg.libraryFilePath(name); ok {
+			lib := pkg.findOrCreateLibrary(libName)
+			if lib.Files == nil {
+				lib.Files = make(map[string][]byte)
+			}
+			lib.Files[relativePath] = data
+		} else {
+			pkg.addExtraFile(name, &ContentFile{Data: data, Mime: mimeTypeByPath(name)})
 		}
 	}
 
 	return nil
 }
 
-func (pkg *H5PPackage) findOrCreateLibrary(machineName string) *Library {
-	for _, lib := range pkg.Libraries {
-		if lib.MachineName == machineName {
-			return lib
+// isDefinitionFile reports whether name is one of the JSON files parsed into
+// package, content or library definitions.
+func isDefinitionFile(name string) bool {
+	return name == "h5p.json" || name == "content/content.json" || name == ManifestFile ||
+		isLibraryDefinitionFile(name, "library.json") || isLibraryDefinitionFile(name, "semantics.json")
+}
+
+// isLibraryDefinitionFile reports whether name is the given file directly
+// inside a top-level library folder, e.g. "FontAwesome-4.5/library.json".
+func isLibraryDefinitionFile(name, file string) bool {
+	dir, base := path.Split(name)
+	return base == file && dir != "" && dir != ContentDir+"/" && strings.Count(dir, "/") == 1
+}
+
+func (pkg *H5PPackage) addExtraFile(name string, cf *ContentFile) {
+	if pkg.ExtraFiles == nil {
+		pkg.ExtraFiles = make(map[string]*ContentFile)
+	}
+	pkg.ExtraFiles[name] = cf
+}
+
+// addLibraryDirectory marks a top-level folder as a library folder so its
+// files are kept even if they are read before its library.json.
+func (pkg *H5PPackage) addLibraryDirectory(name string) {
+	if pkg.libraryDirs == nil {
+		pkg.libraryDirs = make(map[string]bool)
+	}
+	pkg.libraryDirs[name] = true
+}
+
+// libraryFilePath splits an archive path into its library folder and the
+// path relative to it, if the file belongs to a library.
+func (pkg *H5PPackage) libraryFilePath(name string) (libName, relativePath string, ok bool) {
+	if !strings.Contains(name, "/") {
+		return "", "", false
+	}
+	libName = strings.Split(name, "/")[0]
+	if !pkg.isLibraryDirectory(libName) {
+		return "", "", false
+	}
+	return libName, strings.TrimPrefix(name, libName+"/"), true
+}
+
+// addLazyFile registers a content or library file whose contents are opened
+// on demand.
+func (pkg *H5PPackage) addLazyFile(name string, open FileOpener) {
+	if assetPath, ok := contentFilePath(name); ok {
+		if pkg.ContentFiles == nil {
+			pkg.ContentFiles = make(map[string]*ContentFile)
 		}
+		pkg.ContentFiles[assetPath] = &ContentFile{Mime: mimeTypeByPath(assetPath), open: open}
+		return
+	}
+	libName, relativePath, ok := pkg.libraryFilePath(name)
+	if !ok {
+		pkg.addExtraFile(name, &ContentFile{Mime: mimeTypeByPath(name), open: open})
+		return
+	}
+	lib := pkg.findOrCreateLibrary(libName)
+	if lib.LazyFiles == nil {
+		lib.LazyFiles = make(map[string]FileOpener)
+	}
+	lib.LazyFiles[relativePath] = open
+}
+
+func (pkg *H5PPackage) findOrCreateLibrary(machineName string) *Library {
+	if lib := pkg.libraryByFolder(machineName); lib != nil {
+		return lib
 	}
 
 	lib := &Library{
 		MachineName: machineName,
 		Files:       make(map[string][]byte),
 	}
-	pkg.Libraries = append(pkg.Libraries, lib)
+	pkg.AddLibrary(lib)
 	return lib
 }
 
+// isLibraryDirectory reports whether the top-level folder holds a library:
+// it contains a library.json or was listed in PackageLoader.LibraryDirs.
+// Library folders are not limited to "H5P." names; packages also ship
+// folders such as "FontAwesome-4.5" and "jQuery.ui-1.10".
 func (pkg *H5PPackage) isLibraryDirectory(name string) bool {
-	return strings.HasPrefix(name, "H5P.")
+	return pkg.libraryDirs[name] || pkg.libraryByFolder(name) != nil
 }
@@ -1,10 +1,14 @@
 package h5p
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
+	"testing/fstest"
 )
 
 func TestH5PPackageCreationAndExtraction(t *testing.T) {
@@ -242,3 +246,139 @@ func TestContentValidation(t *testing.T) {
 
 	t.Log("Content validation completed successfully")
 }
+
+func TestH5PPackageWriteToAndLoadFromReader(t *testing.T) {
+	pkg := loadTestPackage(t)
+
+	var buf bytes.Buffer
+	n, err := pkg.WriteTo(&buf)
+	if err != nil {
+		t.Fatalf("Failed to write H5P package: %v", err)
+	}
+	if n != int64(buf.Len()) {
+		t.Errorf("Expected %d bytes written, got %d", buf.Len(), n)
+	}
+
+	loadedPkg, err := LoadH5PPackageFromReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("Failed to load H5P package from reader: %v", err)
+	}
+	if loadedPkg.PackageDefinition == nil || loadedPkg.PackageDefinition.Title != pkg.PackageDefinition.Title {
+		t.Error("Package definition was not loaded from reader")
+	}
+	if len(loadedPkg.Libraries) != 1 || len(loadedPkg.Libraries[0].Files) != 2 {
+		t.Errorf("Expected 1 library with 2 files, got %d libraries", len(loadedPkg.Libraries))
+	}
+}
+
+func TestContentJSON(t *testing.T) {
+	data, err := os.ReadFile("testdata/content.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var content Content
+	if err := json.Unmarshal(data, &content); err != nil {
+		t.Fatal(err)
+	}
+	params, ok := content.Params.(map[string]any)
+	if !ok || params["question"] == nil || content.QuestionSet != nil {
+		t.Fatalf("Expected content.json params, got %+v", content)
+	}
+	out, err := json.Marshal(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want, got any
+	_ = json.Unmarshal(data, &want)
+	_ = json.Unmarshal(out, &got)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("content.json did not round-trip:\n%s", out)
+	}
+
+	qs := Content{QuestionSet: &QuestionSet{Title: "Quiz"}}
+	if out, _ := json.Marshal(qs); !bytes.HasPrefix(out, []byte(`{"questionSet":`)) {
+		t.Errorf("Expected the questionSet form, got %s", out)
+	}
+	var legacy Content
+	if err := json.Unmarshal([]byte(`{"questionSet":{"title":"Quiz"}}`), &legacy); err != nil || legacy.QuestionSet == nil || legacy.Params != nil {
+		t.Errorf("Expected a question set, got %+v, %v", legacy, err)
+	}
+}
+
+func TestLoadH5PPackageFS(t *testing.T) {
+	h5pData, err := os.ReadFile("testdata/h5p.json")
+	if err != nil {
+		t.Fatalf("Failed to read h5p.json: %v", err)
+	}
+	contentData, err := os.ReadFile("testdata/content.json")
+	if err != nil {
+		t.Fatalf("Failed to read content.json: %v", err)
+	}
+	libraryData, err := os.ReadFile("testdata/library.json")
+	if err != nil {
+		t.Fatalf("Failed to read library.json: %v", err)
+	}
+
+	fsys := fstest.MapFS{
+		"h5p.json":                               {Data: h5pData},
+		"content/content.json":                   {Data: contentData},
+		"H5P.MultiChoice-1.16/library.json":      {Data: libraryData},
+		"H5P.MultiChoice-1.16/js/multichoice.js": {Data: []byte("// js")},
+	}
+
+	pkg, err := LoadH5PPackageFS(fsys)
+	if err != nil {
+		t.Fatalf("Failed to load H5P package from FS: %v", err)
+	}
+	if pkg.PackageDefinition == nil || pkg.Content == nil {
+		t.Fatal("Expected package definition and content to be loaded")
+	}
+	if len(pkg.Libraries) != 1 {
+		t.Fatalf("Expected 1 library, got %d", len(pkg.Libraries))
+	}
+	if _, ok := pkg.Libraries[0].Files["js/multichoice.js"]; !ok {
+		t.Error("Library file not loaded from FS")
+	}
+}
+
+func TestExtraFilesRoundTrip(t *testing.T) {
+	zipPath := writeTestZip(t, map[string][]byte{
+		"h5p.json":              []byte(`{"title":"Test"}`),
+		"LICENSE":               []byte("MIT"),
+		"vendor/build-info.txt": []byte("built by vendor"),
+	})
+
+	for _, lazy := range []bool{false, true} {
+		loader := NewPackageLoader()
+		loader.Lazy = lazy
+		loaded, err := loader.Load(zipPath)
+		if err != nil {
+			t.Fatalf("Failed to load package (lazy=%v): %v", lazy, err)
+		}
+		if len(loaded.ExtraFiles) != 2 {
+			t.Fatalf("Expected 2 extra files (lazy=%v), got %d", lazy, len(loaded.ExtraFiles))
+		}
+
+		var buf bytes.Buffer
+		if _, err := loaded.WriteTo(&buf); !errors.Is(err, ErrFileExtension) {
+			t.Errorf("Expected LICENSE refused by WriteTo (lazy=%v), got %v", lazy, err)
+		}
+		buf.Reset()
+		if _, err := loaded.WriteToWithOptions(&buf, WriteOptions{}); err != nil {
+			t.Fatalf("Failed to write package (lazy=%v): %v", lazy, err)
+		}
+		loaded.Close()
+
+		reloaded, err := LoadH5PPackageFromReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		if err != nil {
+			t.Fatalf("Failed to reload package (lazy=%v): %v", lazy, err)
+		}
+		data, err := reloaded.ExtraFiles["vendor/build-info.txt"].Bytes()
+		if err != nil || string(data) != "built by vendor" {
+			t.Errorf("Extra file not preserved (lazy=%v): %q, %v", lazy, data, err)
+		}
+		if string(reloaded.ExtraFiles["LICENSE"].Data) != "MIT" {
+			t.Errorf("LICENSE not preserved (lazy=%v)", lazy)
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package h5p
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// AllowedContentFileExtensions lists the file extensions H5P core accepts in
+// content folders, as defined by the default h5p.org whitelist.
+var AllowedContentFileExtensions = []string{
+	"json", "png", "jpg", "jpeg", "gif", "bmp", "tif", "tiff", "svg", "eot", "ttf", "woff", "woff2", "otf",
+	"webm", "mp4", "ogg", "mp3", "m4a", "wav", "txt", "pdf", "rtf", "doc", "docx", "xls", "xlsx", "ppt",
+	"pptx", "odt", "ods", "odp", "xml", "csv", "diff", "patch", "swf", "md", "textile", "vtt", "webvtt",
+	"gltf", "glb",
+}
+
+// AllowedLibraryFileExtensions lists the additional extensions accepted in
+// library folders on top of AllowedContentFileExtensions.
+var AllowedLibraryFileExtensions = []string{"js", "css"}
+
+// EmbedTypes lists the values H5P platforms accept in embedTypes.
+var EmbedTypes = []string{"div", "iframe"}
+
+var libraryFolderPattern = regexp.MustCompile(`^[\w.-]+-\d+\.\d+$`)
+
+// StructureViolation describes a single breach of the H5P package rules.
+type StructureViolation struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+func (v StructureViolation) Error() string {
+	if v.Path == "" {
+		return v.Message
+	}
+	return v.Path + ": " + v.Message
+}
+
+// StructureErrors collects every violation found by ValidateStructure.
+type StructureErrors []StructureViolation
+
+func (e StructureErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, v := range e {
+		msgs[i] = v.Error()
+	}
+	return fmt.Sprintf("%d package structure violation(s): %s", len(e), strings.Join(msgs, "; "))
+}
+
+// ValidateStructure checks the package against the h5p.org package
+// specification. It returns nil when the package is valid, otherwise a
+// StructureErrors value listing all violations.
+func (pkg *H5PPackage) ValidateStructure() error {
+	return pkg.ValidateStructureContext(context.Background(), nil)
+}
+
+// ValidateStructureContext is ValidateStructure that stops when ctx is
+// cancelled, returning the context error, and calls progress, if set, after
+// each content and library file is checked.
+func (pkg *H5PPackage) ValidateStructureContext(ctx context.Context, progress ProgressFunc) error {
+	tracker := &progressTracker{fn: progress}
+	var errs StructureErrors
+	add := func(p, format string, args ...any) {
+		errs = append(errs, StructureViolation{Path: p, Message: fmt.Sprintf(format, args...)})
+	}
+
+	def := pkg.PackageDefinition
+	if def == nil {
+		add("h5p.json", "file is missing")
+	} else {
+		if def.Title == "" {
+			add("h5p.json", "title is required")
+		}
+		if def.Language == "" {
+			add("h5p.json", "language is required")
+		}
+		for _, e := range def.ValidateLanguages().Errors() {
+			add("h5p.json", "%s: %s", e.Path, e.Message)
+		}
+		if def.MainLibrary == "" {
+			add("h5p.json", "mainLibrary is required")
+		}
+		if len(def.EmbedTypes) == 0 {
+			add("h5p.json", "embedTypes is required")
+		}
+		for _, msg := range invalidEmbedTypes(def.EmbedTypes) {
+			add("h5p.json", "%s", msg)
+		}
+		if len(def.PreloadedDependencies) == 0 {
+			add("h5p.json", "preloadedDependencies is required")
+		}
+		for i, dep := range def.PreloadedDependencies {
+			if dep.MachineName == "" {
+				add("h5p.json", "preloadedDependencies[%d] is missing machineName", i)
+			}
+		}
+		if def.MainLibrary != "" && len(def.PreloadedDependencies) > 0 && !def.declaresDependency(def.MainLibrary) {
+			add("h5p.json", "mainLibrary %s is not declared in preloadedDependencies", def.MainLibrary)
+		}
+		if main := pkg.mainLibrary(); main != nil && main.Definition != nil && !main.Definition.Runnable {
+			add("h5p.json", "mainLibrary %s is not runnable, set runnable to 1 in %s/library.json or use a content type library",
+				def.MainLibrary, main.MachineName)
+		}
+		if def.License != "" || def.LicenseVersion != "" {
+			if err := def.ValidateLicense(); err != nil {
+				add("h5p.json", "%v", err)
+			}
+		}
+	}
+
+	if pkg.Content == nil {
+		add("content/content.json", "file is missing")
+	}
+	for _, name := range pkg.ContentFileNames() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !allowedExtension(name, AllowedContentFileExtensions) {
+			add(ContentDir+"/"+name, "file extension is not allowed")
+		}
+		tracker.add(ContentDir+"/"+name, 0)
+	}
+
+	for _, lib := range pkg.Libraries {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		folder := lib.MachineName
+		if !libraryFolderPattern.MatchString(folder) {
+			add(folder, "library folder name must have the form Name-Major.Minor")
+		}
+		if lib.Definition == nil {
+			add(folder+"/library.json", "file is missing")
+		} else {
+			ld := lib.Definition
+			if ld.Title == "" {
+				add(folder+"/library.json", "title is required")
+			}
+			if ld.MachineName == "" {
+				add(folder+"/library.json", "machineName is required")
+			} else if want := ld.FolderName(); folder != want {
+				add(folder+"/library.json", "machineName and version do not match folder name, expected %s", want)
+			}
+			for _, msg := range invalidEmbedTypes(ld.EmbedTypes) {
+				add(folder+"/library.json", "%s", msg)
+			}
+		}
+		for _, name := range lib.FileNames() {
+			if !allowedExtension(name, AllowedContentFileExtensions, AllowedLibraryFileExtensions) {
+				add(folder+"/"+name, "file extension is not allowed")
+			}
+			tracker.add(folder+"/"+name, 0)
+		}
+	}
+
+	for _, name := range sortedFileNames(pkg.ExtraFiles) {
+		if !allowedExtension(name, AllowedContentFileExtensions) {
+			add(name, "file extension is not allowed")
+		}
+		tracker.add(name, 0)
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// FolderName returns the conventional folder name for the library,
+// e.g. "H5P.MultiChoice-1.16".
+func (ld *LibraryDefinition) FolderName() string {
+	return fmt.Sprintf("%s-%d.%d", ld.MachineName, ld.MajorVersion, ld.MinorVersion)
+}
+
+func (def *PackageDefinition) declaresDependency(machineName string) bool {
+	for _, dep := range def.PreloadedDependencies {
+		if dep.MachineName == machineName {
+			return true
+		}
+	}
+	return false
+}
+
+// mainLibrary returns the library named by mainLibrary at the version
+// declared in preloadedDependencies, or nil.
+func (pkg *H5PPackage) mainLibrary() *Library {
+	def := pkg.PackageDefinition
+	if def == nil || def.MainLibrary == "" {
+		return nil
+	}
+	for _, dep := range def.PreloadedDependencies {
+		if dep.MachineName == def.MainLibrary {
+			return pkg.resolveLibrary(dep)
+		}
+	}
+	return nil
+}
+
+// invalidEmbedTypes describes each entry of embedTypes that is not one of
+// EmbedTypes or is repeated.
+func invalidEmbedTypes(embedTypes []string) []string {
+	var msgs []string
+	seen := map[string]bool{}
+	for i, t := range embedTypes {
+		switch {
+		case !containsString(EmbedTypes, t):
+			msgs = append(msgs, fmt.Sprintf("embedTypes[%d] %q is invalid, must be one of %s", i, t, strings.Join(EmbedTypes, ", ")))
+		case seen[t]:
+			msgs = append(msgs, fmt.Sprintf("embedTypes[%d] %q is repeated", i, t))
+		}
+		seen[t] = true
+	}
+	return msgs
+}
+
+func allowedExtension(name string, lists ...[]string) bool {
+	ext := strings.ToLower(strings.TrimPrefix(path.Ext(name), "."))
+	if ext == "" {
+		return false
+	}
+	for _, list := range lists {
+		for _, allowed := range list {
+			if ext == allowed {
+				return true
+			}
+		}
+	}
+	return false
+}
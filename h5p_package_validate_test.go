@@ -0,0 +1,119 @@
+package h5p
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+)
+
+func loadTestPackage(t *testing.T) *H5PPackage {
+	t.Helper()
+
+	var packageDef PackageDefinition
+	readTestJSON(t, "testdata/h5p.json", &packageDef)
+
+	var libraryDef LibraryDefinition
+	readTestJSON(t, "testdata/library.json", &libraryDef)
+
+	var content Content
+	readTestJSON(t, "testdata/content.json", &content)
+
+	pkg := NewH5PPackage()
+	pkg.SetPackageDefinition(&packageDef)
+	pkg.SetContent(&content)
+	pkg.AddLibrary(&Library{
+		MachineName: "H5P.MultiChoice-1.16",
+		Definition:  &libraryDef,
+		Files: map[string][]byte{
+			"js/multichoice.js":   []byte("// MultiChoice JavaScript code"),
+			"css/multichoice.css": []byte("/* MultiChoice CSS styles */"),
+		},
+	})
+	return pkg
+}
+
+func readTestJSON(t *testing.T, name string, v any) {
+	t.Helper()
+	data, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", name, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		t.Fatalf("Failed to parse %s: %v", name, err)
+	}
+}
+
+func TestValidateStructure(t *testing.T) {
+	pkg := loadTestPackage(t)
+	if err := pkg.ValidateStructure(); err != nil {
+		t.Fatalf("Valid package failed structure validation: %v", err)
+	}
+
+	pkg.PackageDefinition.Title = ""
+	pkg.PackageDefinition.MainLibrary = "H5P.TrueFalse"
+	pkg.Content = nil
+	pkg.Libraries[0].MachineName = "H5P.MultiChoice"
+	pkg.Libraries[0].Files["bin/run.exe"] = []byte{}
+	pkg.ExtraFiles = map[string]*ContentFile{"LICENSE": {Data: []byte("MIT")}, "notes.txt": {Data: []byte("notes")}}
+
+	err := pkg.ValidateStructure()
+	if err == nil {
+		t.Fatal("Expected structure validation errors")
+	}
+
+	var violations StructureErrors
+	if !errors.As(err, &violations) {
+		t.Fatalf("Expected StructureErrors, got %T", err)
+	}
+
+	want := map[string]bool{
+		"h5p.json: title is required": false,
+		"h5p.json: mainLibrary H5P.TrueFalse is not declared in preloadedDependencies": false,
+		"content/content.json: file is missing":                                        false,
+		"H5P.MultiChoice: library folder name must have the form Name-Major.Minor":     false,
+		"H5P.MultiChoice/bin/run.exe: file extension is not allowed":                   false,
+		"LICENSE: file extension is not allowed":                                       false,
+	}
+	for _, v := range violations {
+		if _, ok := want[v.Error()]; ok {
+			want[v.Error()] = true
+		}
+	}
+	for msg, found := range want {
+		if !found {
+			t.Errorf("Expected violation '%s' in %v", msg, violations)
+		}
+	}
+	for _, v := range violations {
+		if v.Path == "notes.txt" {
+			t.Errorf("Unexpected violation %v", v)
+		}
+	}
+}
+
+func TestValidateStructureEmbedTypesAndRunnable(t *testing.T) {
+	pkg := loadTestPackage(t)
+	pkg.PackageDefinition.EmbedTypes = []string{"div", "Iframe", "div"}
+	pkg.Libraries[0].Definition.EmbedTypes = []string{"script"}
+	pkg.Libraries[0].Definition.Runnable = false
+
+	var violations StructureErrors
+	if !errors.As(pkg.ValidateStructure(), &violations) {
+		t.Fatal("Expected StructureErrors")
+	}
+	want := []string{
+		`h5p.json: embedTypes[1] "Iframe" is invalid, must be one of div, iframe`,
+		`h5p.json: embedTypes[2] "div" is repeated`,
+		"h5p.json: mainLibrary H5P.MultiChoice is not runnable, set runnable to 1 in H5P.MultiChoice-1.16/library.json or use a content type library",
+		`H5P.MultiChoice-1.16/library.json: embedTypes[0] "script" is invalid, must be one of div, iframe`,
+	}
+	if len(violations) != len(want) {
+		t.Fatalf("Expected %d violations, got %v", len(want), violations)
+	}
+	for i, v := range violations {
+		if v.Error() != want[i] {
+			t.Errorf("Expected violation %q, got %q", want[i], v.Error())
+		}
+	}
+}
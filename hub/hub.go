@@ -0,0 +1,271 @@
+// Package hub is a client for the H5P Hub API at api.h5p.org, which lists
+// the installable content types. The registry and icons are cached on disk
+// so install and audit tools do not hit the Hub on every run. Content type
+// packages can be downloaded and their libraries installed into an
+// h5p.H5PPackage.
+package hub
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultBaseURL is the H5P Hub API endpoint.
+	DefaultBaseURL = "https://api.h5p.org/v1"
+
+	// DefaultCacheTTL is how long a cached registry is used before it is
+	// fetched again. H5P core refreshes daily as well.
+	DefaultCacheTTL = 24 * time.Hour
+
+	// RegistryCacheFile is the name of the cached registry in CacheDir.
+	RegistryCacheFile = "content-types.json"
+
+	contentTypesPath = "/content-types/"
+	iconCacheDir     = "icons"
+	maxResponseSize  = 64 << 20
+)
+
+var (
+	ErrUnknownContentType = errors.New("content type is not on the Hub")
+	ErrHubStatus          = errors.New("unexpected Hub response status")
+)
+
+// Client fetches the content type registry from the Hub.
+type Client struct {
+	// BaseURL defaults to DefaultBaseURL.
+	BaseURL string
+
+	// HTTPClient defaults to http.DefaultClient.
+	HTTPClient *http.Client
+
+	// CacheDir holds the cached registry and icons. Caching is disabled if
+	// it is empty.
+	CacheDir string
+
+	// CacheTTL defaults to DefaultCacheTTL.
+	CacheTTL time.Duration
+
+	// SiteUUID identifies the site to the Hub, as registered H5P
+	// integrations do. It is optional; see Register.
+	SiteUUID string
+
+	// Site, if set, is sent with registry requests, as H5PCore does.
+	Site *Site
+
+	// Usage, if set, collects the usage statistics sent with registry
+	// requests. Reporting them is optional.
+	Usage func(ctx context.Context) (*Usage, error)
+}
+
+// NewClient returns a client for the public Hub caching in cacheDir; see
+// DefaultCacheDir.
+func NewClient(cacheDir string) *Client {
+	return &Client{CacheDir: cacheDir}
+}
+
+// DefaultCacheDir returns h5p-go/hub in the user cache directory.
+func DefaultCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "h5p-go", "hub"), nil
+}
+
+// ContentTypes returns the registry, from the cache if it is fresh.
+func (c *Client) ContentTypes() (*Registry, error) {
+	return c.ContentTypesContext(context.Background())
+}
+
+// ContentTypesContext is ContentTypes with a context. If the Hub cannot be
+// reached, a stale cached registry is returned along with no error.
+func (c *Client) ContentTypesContext(ctx context.Context) (*Registry, error) {
+	cached, err := c.cachedRegistry()
+	if err == nil && time.Since(cached.FetchedAt) < c.cacheTTL() {
+		return cached, nil
+	}
+	reg, err := c.RefreshContext(ctx)
+	if err != nil && cached != nil && ctx.Err() == nil {
+		return cached, nil
+	}
+	return reg, err
+}
+
+// Refresh fetches the registry from the Hub and updates the cache.
+func (c *Client) Refresh() (*Registry, error) {
+	return c.RefreshContext(context.Background())
+}
+
+// RefreshContext is Refresh with a context.
+func (c *Client) RefreshContext(ctx context.Context) (*Registry, error) {
+	form, err := c.registryForm(ctx)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL()+contentTypesPath, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	data, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	var reg Registry
+	if err := json.Unmarshal(data, &reg); err != nil {
+		return nil, fmt.Errorf("failed to parse Hub registry: %w", err)
+	}
+	reg.FetchedAt = time.Now().UTC()
+	if c.CacheDir != "" {
+		data, err := json.MarshalIndent(&reg, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		if err := writeCacheFile(filepath.Join(c.CacheDir, RegistryCacheFile), data); err != nil {
+			return nil, err
+		}
+	}
+	return &reg, nil
+}
+
+// ContentType returns the registry entry of machineName.
+func (c *Client) ContentType(machineName string) (*ContentType, error) {
+	return c.ContentTypeContext(context.Background(), machineName)
+}
+
+// ContentTypeContext is ContentType with a context.
+func (c *Client) ContentTypeContext(ctx context.Context, machineName string) (*ContentType, error) {
+	reg, err := c.ContentTypesContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ct, ok := reg.ContentType(machineName)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownContentType, machineName)
+	}
+	return ct, nil
+}
+
+// Icon returns the icon image of a content type, cached per version.
+func (c *Client) Icon(ct *ContentType) ([]byte, error) {
+	return c.IconContext(context.Background(), ct)
+}
+
+// IconContext is Icon with a context.
+func (c *Client) IconContext(ctx context.Context, ct *ContentType) ([]byte, error) {
+	if ct.Icon == "" {
+		return nil, fmt.Errorf("content type %s has no icon", ct.ID)
+	}
+	var cacheFile string
+	if c.CacheDir != "" {
+		u, err := url.Parse(ct.Icon)
+		if err != nil {
+			return nil, err
+		}
+		v := ct.Version
+		name := fmt.Sprintf("%s-%d.%d.%d%s", ct.ID, v.Major, v.Minor, v.Patch, path.Ext(u.Path))
+		cacheFile = filepath.Join(c.CacheDir, iconCacheDir, name)
+		if data, err := os.ReadFile(cacheFile); err == nil {
+			return data, nil
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ct.Icon, nil)
+	if err != nil {
+		return nil, err
+	}
+	data, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	if cacheFile != "" {
+		if err := writeCacheFile(cacheFile, data); err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+func (c *Client) cachedRegistry() (*Registry, error) {
+	if c.CacheDir == "" {
+		return nil, os.ErrNotExist
+	}
+	data, err := os.ReadFile(filepath.Join(c.CacheDir, RegistryCacheFile))
+	if err != nil {
+		return nil, err
+	}
+	var reg Registry
+	if err := json.Unmarshal(data, &reg); err != nil {
+		return nil, err
+	}
+	return &reg, nil
+}
+
+func (c *Client) do(req *http.Request) ([]byte, error) {
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: %s %s: %s", ErrHubStatus, req.Method, req.URL, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxResponseSize {
+		return nil, fmt.Errorf("Hub response from %s exceeds %d bytes", req.URL, maxResponseSize)
+	}
+	return data, nil
+}
+
+func (c *Client) baseURL() string {
+	if c.BaseURL == "" {
+		return DefaultBaseURL
+	}
+	return strings.TrimSuffix(c.BaseURL, "/")
+}
+
+func (c *Client) cacheTTL() time.Duration {
+	if c.CacheTTL <= 0 {
+		return DefaultCacheTTL
+	}
+	return c.CacheTTL
+}
+
+// writeCacheFile replaces name atomically so concurrent readers never see a
+// partial file.
+func writeCacheFile(name string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(name), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), name)
+}
@@ -0,0 +1,134 @@
+package hub
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const testRegistry = `{
+  "contentTypes": [
+    {
+      "id": "H5P.MultiChoice",
+      "version": {"major": 1, "minor": 16, "patch": 4},
+      "coreApiVersionNeeded": {"major": 1, "minor": 19},
+      "title": "Multiple Choice",
+      "summary": "Create flexible multiple choice questions",
+      "icon": "%s/icons/multichoice.svg",
+      "isRecommended": true,
+      "popularity": 3,
+      "screenshots": [{"url": "https://h5p.org/s.png", "alt": "Question"}],
+      "license": {"id": "MIT", "attributes": {"useCommercially": true}},
+      "keywords": ["quiz"],
+      "categories": ["Questions"]
+    },
+    {
+      "id": "H5P.TrueFalse",
+      "version": {"major": 1, "minor": 8, "patch": 1},
+      "coreApiVersionNeeded": {"major": 1, "minor": 19},
+      "title": "True/False Question"
+    }
+  ]
+}`
+
+// newTestHub serves testRegistry and the given content type packages.
+func newTestHub(t *testing.T, packages map[string][]byte) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var requests atomic.Int32
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/content-types/":
+			if err := r.ParseForm(); err != nil || r.PostForm.Get("uuid") != "site-1" {
+				http.Error(w, "missing uuid", http.StatusBadRequest)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, testRegistry, srv.URL)
+		case r.Method == http.MethodGet && packages[strings.TrimPrefix(r.URL.Path, "/v1/content-types/")] != nil:
+			w.Write(packages[strings.TrimPrefix(r.URL.Path, "/v1/content-types/")])
+		case r.URL.Path == "/icons/multichoice.svg":
+			w.Write([]byte("<svg/>"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &requests
+}
+
+func TestContentTypesCache(t *testing.T) {
+	srv, requests := newTestHub(t, nil)
+	dir := t.TempDir()
+	c := &Client{BaseURL: srv.URL + "/v1/", CacheDir: dir, SiteUUID: "site-1"}
+
+	reg, err := c.ContentTypes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reg.ContentTypes) != 2 || reg.FetchedAt.IsZero() {
+		t.Fatalf("registry = %+v", reg)
+	}
+	mc, ok := reg.ContentType("H5P.MultiChoice")
+	if !ok || mc.Version.LibraryVersion().String() != "1.16.4" || !mc.License.Attributes["useCommercially"] {
+		t.Errorf("MultiChoice = %+v", mc)
+	}
+	if v := reg.LatestVersions()["H5P.TrueFalse"]; v.Minor != 8 {
+		t.Errorf("LatestVersions TrueFalse = %v", v)
+	}
+
+	// A second client with the same cache does not hit the Hub.
+	c2 := &Client{BaseURL: srv.URL + "/v1", CacheDir: dir}
+	if _, err := c2.ContentType("H5P.TrueFalse"); err != nil {
+		t.Fatal(err)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("Expected 1 Hub request, got %d", n)
+	}
+	if _, err := c2.ContentType("H5P.Nope"); !errors.Is(err, ErrUnknownContentType) {
+		t.Errorf("Expected ErrUnknownContentType, got %v", err)
+	}
+
+	// An expired cache is refreshed; c2 sends no uuid so the Hub refuses,
+	// and the stale registry is used.
+	reg.FetchedAt = time.Now().Add(-2 * time.Hour)
+	c2.CacheTTL = time.Hour
+	data, err := json.Marshal(reg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, RegistryCacheFile), data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	stale, err := c2.ContentTypes()
+	if err != nil || len(stale.ContentTypes) != 2 {
+		t.Errorf("Expected stale registry, got %v, %v", stale, err)
+	}
+	if n := requests.Load(); n != 2 {
+		t.Errorf("Expected a refresh attempt, got %d requests", n)
+	}
+	if _, err := c2.Refresh(); !errors.Is(err, ErrHubStatus) {
+		t.Errorf("Expected ErrHubStatus, got %v", err)
+	}
+
+	icon, err := c.Icon(mc)
+	if err != nil || string(icon) != "<svg/>" {
+		t.Fatalf("Icon = %q, %v", icon, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "icons", "H5P.MultiChoice-1.16.4.svg")); err != nil {
+		t.Errorf("icon not cached: %v", err)
+	}
+	before := requests.Load()
+	if _, err := c.Icon(mc); err != nil || requests.Load() != before {
+		t.Errorf("cached icon refetched: %v", err)
+	}
+}
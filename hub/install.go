@@ -0,0 +1,170 @@
+package hub
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	h5p "github.com/grokify/h5p-go"
+)
+
+const packageCacheDir = "packages"
+
+// ErrLibraryNotInPackage is returned when a Hub package lacks the main
+// library or one of its dependencies.
+var ErrLibraryNotInPackage = errors.New("library missing from Hub package")
+
+// Download returns the .h5p package of a content type, which holds its
+// libraries and their dependencies. Packages are cached per version.
+func (c *Client) Download(machineName string) ([]byte, error) {
+	return c.DownloadContext(context.Background(), machineName)
+}
+
+// DownloadContext is Download with a context.
+func (c *Client) DownloadContext(ctx context.Context, machineName string) ([]byte, error) {
+	ct, err := c.ContentTypeContext(ctx, machineName)
+	if err != nil {
+		return nil, err
+	}
+	var cacheFile string
+	if c.CacheDir != "" {
+		v := ct.Version
+		name := fmt.Sprintf("%s-%d.%d.%d.h5p", ct.ID, v.Major, v.Minor, v.Patch)
+		cacheFile = filepath.Join(c.CacheDir, packageCacheDir, name)
+		if data, err := os.ReadFile(cacheFile); err == nil {
+			return data, nil
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL()+contentTypesPath+url.PathEscape(machineName), nil)
+	if err != nil {
+		return nil, err
+	}
+	data, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	if cacheFile != "" {
+		if err := writeCacheFile(cacheFile, data); err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// InstallLibraryFromHub downloads the content type machineName and adds its
+// main library and every library it depends on to pkg, using the rules of
+// h5p.MergePackages: existing versions are kept unless the Hub has a newer
+// patch, and a different major version is an error.
+func (c *Client) InstallLibraryFromHub(pkg *h5p.H5PPackage, machineName string) (*h5p.MergeResult, error) {
+	return c.InstallLibraryFromHubContext(context.Background(), pkg, machineName)
+}
+
+// InstallLibraryFromHubContext is InstallLibraryFromHub with a context.
+func (c *Client) InstallLibraryFromHubContext(ctx context.Context, pkg *h5p.H5PPackage, machineName string) (*h5p.MergeResult, error) {
+	data, err := c.DownloadContext(ctx, machineName)
+	if err != nil {
+		return nil, err
+	}
+	src, err := h5p.LoadH5PPackageFromReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load Hub package %s: %w", machineName, err)
+	}
+	libs, err := libraryClosure(src, machineName)
+	if err != nil {
+		return nil, err
+	}
+	sub := h5p.NewH5PPackage()
+	for _, lib := range libs {
+		sub.AddLibrary(lib)
+	}
+	return h5p.MergePackages(pkg, sub, h5p.MergeOptions{})
+}
+
+// InstallDependencies installs from the Hub every h5p.json
+// preloadedDependency that pkg does not contain, turning content-only JSON
+// into a self-contained package. The returned error is a
+// *h5p.DependencyError if libraries are still missing afterwards.
+func (c *Client) InstallDependencies(pkg *h5p.H5PPackage) (*h5p.MergeResult, error) {
+	return c.InstallDependenciesContext(context.Background(), pkg)
+}
+
+// InstallDependenciesContext is InstallDependencies with a context. It
+// makes Client a h5p.LibraryInstaller for h5p.WithLibraryInstaller.
+func (c *Client) InstallDependenciesContext(ctx context.Context, pkg *h5p.H5PPackage) (*h5p.MergeResult, error) {
+	result := &h5p.MergeResult{}
+	if pkg.PackageDefinition == nil {
+		return result, nil
+	}
+	for _, dep := range pkg.PackageDefinition.PreloadedDependencies {
+		if findLibrary(pkg, dep) != nil {
+			continue
+		}
+		r, err := c.InstallLibraryFromHubContext(ctx, pkg, dep.MachineName)
+		if err != nil {
+			return result, err
+		}
+		result.Added = append(result.Added, r.Added...)
+		result.Replaced = append(result.Replaced, r.Replaced...)
+		result.Skipped = append(result.Skipped, r.Skipped...)
+	}
+	_, err := pkg.ResolveDependencies()
+	return result, err
+}
+
+// libraryClosure returns the newest library named machineName in src and
+// the libraries its preloaded and dynamic dependencies reach.
+func libraryClosure(src *h5p.H5PPackage, machineName string) ([]*h5p.Library, error) {
+	var main *h5p.Library
+	for _, lib := range src.Libraries {
+		if lib.Definition == nil || lib.Definition.MachineName != machineName {
+			continue
+		}
+		if main == nil || lib.Definition.Version().Compare(main.Definition.Version()) > 0 {
+			main = lib
+		}
+	}
+	if main == nil {
+		return nil, fmt.Errorf("%w: %s", ErrLibraryNotInPackage, machineName)
+	}
+
+	var libs []*h5p.Library
+	seen := map[*h5p.Library]bool{}
+	var visit func(lib *h5p.Library) error
+	visit = func(lib *h5p.Library) error {
+		if seen[lib] {
+			return nil
+		}
+		seen[lib] = true
+		libs = append(libs, lib)
+		if lib.Definition == nil {
+			return nil
+		}
+		deps := append(append([]h5p.LibraryDependency{}, lib.Definition.Dependencies...), lib.Definition.DynamicDependencies...)
+		for _, dep := range deps {
+			found := findLibrary(src, dep)
+			if found == nil {
+				return fmt.Errorf("%w: %s needed by %s", ErrLibraryNotInPackage, dep, lib.MachineName)
+			}
+			if err := visit(found); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := visit(main); err != nil {
+		return nil, err
+	}
+	return libs, nil
+}
+
+func findLibrary(pkg *h5p.H5PPackage, dep h5p.LibraryDependency) *h5p.Library {
+	if lib := pkg.GetLibrary(dep.MachineName, dep.MajorVersion, dep.MinorVersion); lib != nil {
+		return lib
+	}
+	return pkg.FindCompatibleLibrary(dep)
+}
@@ -0,0 +1,117 @@
+package hub
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	h5p "github.com/grokify/h5p-go"
+)
+
+func testLibrary(name string, major, minor, patch int, deps ...h5p.LibraryDependency) *h5p.Library {
+	def := &h5p.LibraryDefinition{
+		Title:        name,
+		MachineName:  name,
+		MajorVersion: major,
+		MinorVersion: minor,
+		PatchVersion: patch,
+		Dependencies: deps,
+	}
+	return &h5p.Library{
+		MachineName: def.FolderName(),
+		Definition:  def,
+		Files:       map[string][]byte{"dist/" + name + ".js": []byte("// " + name)},
+	}
+}
+
+func dep(name string, major, minor int) h5p.LibraryDependency {
+	return h5p.LibraryDependency{MachineName: name, MajorVersion: major, MinorVersion: minor}
+}
+
+// testHubPackage returns a Hub download for H5P.MultiChoice, which also
+// carries an editor library that MultiChoice does not need at runtime.
+func testHubPackage(t *testing.T) []byte {
+	t.Helper()
+	pkg := h5p.NewH5PPackage()
+	pkg.SetPackageDefinition(&h5p.PackageDefinition{
+		Title:                 "Multiple Choice",
+		Language:              "und",
+		MainLibrary:           "H5P.MultiChoice",
+		EmbedTypes:            []string{"iframe"},
+		PreloadedDependencies: []h5p.LibraryDependency{dep("H5P.MultiChoice", 1, 16)},
+	})
+	pkg.AddLibrary(testLibrary("H5P.MultiChoice", 1, 16, 4, dep("H5P.Question", 1, 5), dep("H5P.JoubelUI", 1, 3)))
+	pkg.AddLibrary(testLibrary("H5P.Question", 1, 5, 2, dep("H5P.JoubelUI", 1, 3)))
+	pkg.AddLibrary(testLibrary("H5P.JoubelUI", 1, 3, 9))
+	pkg.AddLibrary(testLibrary("H5PEditor.Wizard", 1, 2, 0))
+	var buf bytes.Buffer
+	if _, err := pkg.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestInstallLibraryFromHub(t *testing.T) {
+	srv, requests := newTestHub(t, map[string][]byte{"H5P.MultiChoice": testHubPackage(t)})
+	c := &Client{BaseURL: srv.URL + "/v1", CacheDir: t.TempDir(), SiteUUID: "site-1"}
+
+	pkg := h5p.NewH5PPackage()
+	pkg.AddLibrary(testLibrary("H5P.JoubelUI", 1, 3, 12))
+	result, err := c.InstallLibraryFromHub(pkg, "H5P.MultiChoice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Added) != 2 || len(result.Skipped) != 1 || result.Skipped[0] != "H5P.JoubelUI-1.3" {
+		t.Errorf("result = %+v", result)
+	}
+	if pkg.GetLibrary("H5PEditor.Wizard", 1, 2) != nil {
+		t.Error("unneeded editor library installed")
+	}
+	lib := pkg.GetLibrary("H5P.MultiChoice", 1, 16)
+	if lib == nil {
+		t.Fatal("H5P.MultiChoice not installed")
+	}
+	if data, err := lib.ReadFile("dist/H5P.MultiChoice.js"); err != nil || string(data) != "// H5P.MultiChoice" {
+		t.Errorf("library file = %q, %v", data, err)
+	}
+
+	// The package is cached: a second install only reuses it.
+	before := requests.Load()
+	if _, err := c.InstallLibraryFromHub(h5p.NewH5PPackage(), "H5P.MultiChoice"); err != nil {
+		t.Fatal(err)
+	}
+	if requests.Load() != before {
+		t.Error("cached Hub package downloaded again")
+	}
+
+	if _, err := c.InstallLibraryFromHub(pkg, "H5P.Unknown"); !errors.Is(err, ErrUnknownContentType) {
+		t.Errorf("Expected ErrUnknownContentType, got %v", err)
+	}
+}
+
+func TestInstallDependencies(t *testing.T) {
+	srv, _ := newTestHub(t, map[string][]byte{"H5P.MultiChoice": testHubPackage(t)})
+	c := &Client{BaseURL: srv.URL + "/v1", SiteUUID: "site-1"}
+
+	pkg := h5p.NewH5PPackage()
+	pkg.SetPackageDefinition(&h5p.PackageDefinition{
+		Title:                 "Quiz",
+		MainLibrary:           "H5P.MultiChoice",
+		PreloadedDependencies: []h5p.LibraryDependency{dep("H5P.MultiChoice", 1, 14)},
+	})
+	result, err := c.InstallDependencies(pkg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Added) != 3 {
+		t.Errorf("result = %+v", result)
+	}
+	if _, err := pkg.ResolveDependencies(); err != nil {
+		t.Errorf("package is not self-contained: %v", err)
+	}
+
+	pkg.PackageDefinition.PreloadedDependencies = append(pkg.PackageDefinition.PreloadedDependencies, dep("H5P.TrueFalse", 1, 8))
+	if _, err := c.InstallDependencies(pkg); err == nil {
+		t.Error("Expected an error for a Hub package that is not served")
+	}
+}
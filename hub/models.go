@@ -0,0 +1,90 @@
+package hub
+
+import (
+	"time"
+
+	h5p "github.com/grokify/h5p-go"
+)
+
+// Registry is the content type registry returned by the Hub.
+type Registry struct {
+	ContentTypes []ContentType `json:"contentTypes"`
+
+	// FetchedAt is when the registry was downloaded. It is not part of the
+	// Hub response and is kept in the cache file.
+	FetchedAt time.Time `json:"fetchedAt,omitempty"`
+}
+
+// ContentType is a content type listed on the Hub.
+type ContentType struct {
+	// ID is the machine name of the main library, e.g. "H5P.MultiChoice".
+	ID                   string       `json:"id"`
+	Version              Version      `json:"version"`
+	CoreAPIVersionNeeded APIVersion   `json:"coreApiVersionNeeded"`
+	Title                string       `json:"title"`
+	Summary              string       `json:"summary,omitempty"`
+	Description          string       `json:"description,omitempty"`
+	Icon                 string       `json:"icon,omitempty"`
+	CreatedAt            string       `json:"createdAt,omitempty"`
+	UpdatedAt            string       `json:"updatedAt,omitempty"`
+	IsRecommended        bool         `json:"isRecommended,omitempty"`
+	Popularity           int          `json:"popularity,omitempty"`
+	Screenshots          []Screenshot `json:"screenshots,omitempty"`
+	License              *License     `json:"license,omitempty"`
+	Owner                string       `json:"owner,omitempty"`
+	Example              string       `json:"example,omitempty"`
+	Tutorial             string       `json:"tutorial,omitempty"`
+	Keywords             []string     `json:"keywords,omitempty"`
+	Categories           []string     `json:"categories,omitempty"`
+}
+
+// Version is a content type version as the Hub reports it.
+type Version struct {
+	Major int `json:"major"`
+	Minor int `json:"minor"`
+	Patch int `json:"patch"`
+}
+
+// LibraryVersion returns v as an h5p.LibraryVersion.
+func (v Version) LibraryVersion() h5p.LibraryVersion {
+	return h5p.LibraryVersion{Major: v.Major, Minor: v.Minor, Patch: v.Patch}
+}
+
+// APIVersion is the H5P core API version a content type needs.
+type APIVersion struct {
+	Major int `json:"major"`
+	Minor int `json:"minor"`
+}
+
+// Screenshot is a content type screenshot.
+type Screenshot struct {
+	URL string `json:"url"`
+	Alt string `json:"alt,omitempty"`
+}
+
+// License is the license of a content type, with attributes such as
+// "canHoldLiable" or "useCommercially".
+type License struct {
+	ID         string          `json:"id"`
+	Attributes map[string]bool `json:"attributes,omitempty"`
+}
+
+// ContentType returns the content type with the given machine name.
+func (r *Registry) ContentType(machineName string) (*ContentType, bool) {
+	for i := range r.ContentTypes {
+		if r.ContentTypes[i].ID == machineName {
+			return &r.ContentTypes[i], true
+		}
+	}
+	return nil, false
+}
+
+// LatestVersions returns the version of every content type, in the form of
+// h5p.LatestLibraryVersions, for auditing packages against the Hub.
+func (r *Registry) LatestVersions() map[string]h5p.LibraryVersion {
+	versions := make(map[string]h5p.LibraryVersion, len(r.ContentTypes))
+	for _, ct := range r.ContentTypes {
+		versions[ct.ID] = ct.Version.LibraryVersion()
+	}
+	return versions
+}
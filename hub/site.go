@@ -0,0 +1,186 @@
+package hub
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	h5p "github.com/grokify/h5p-go"
+)
+
+const sitesPath = "/sites"
+
+// Site types, as reported on registration.
+const (
+	SiteLocal    = "local"
+	SiteNetwork  = "network"
+	SiteInternet = "internet"
+)
+
+var ErrNoSiteUUID = errors.New("Hub did not return a site uuid")
+
+// Site describes the H5P integration to the Hub, as H5PCore does on
+// registration and with each registry request.
+type Site struct {
+	PlatformName    string
+	PlatformVersion string
+	// H5PVersion is the version of the H5P integration, such as that of
+	// this module.
+	H5PVersion string
+	// LocalID tells installations sharing a uuid apart; H5PCore uses a
+	// hash of its install path.
+	LocalID string
+	// Type is SiteLocal, SiteNetwork or SiteInternet; it defaults to
+	// SiteLocal.
+	Type string
+	// CoreAPI is the core API version the platform supports.
+	CoreAPI APIVersion
+	// Disabled reports that the site does not fetch content types.
+	Disabled bool
+}
+
+func (s *Site) form(uuid string) url.Values {
+	siteType := s.Type
+	if siteType == "" {
+		siteType = SiteLocal
+	}
+	disabled := "0"
+	if s.Disabled {
+		disabled = "1"
+	}
+	return url.Values{
+		"uuid":             {uuid},
+		"platform_name":    {s.PlatformName},
+		"platform_version": {s.PlatformVersion},
+		"h5p_version":      {s.H5PVersion},
+		"disabled":         {disabled},
+		"local_id":         {s.LocalID},
+		"type":             {siteType},
+		"core_api_version": {strconv.Itoa(s.CoreAPI.Major) + "." + strconv.Itoa(s.CoreAPI.Minor)},
+	}
+}
+
+// Usage holds the optional usage statistics a site reports with its
+// registry requests, as H5PCore does when send_usage_statistics is on.
+type Usage struct {
+	// NumAuthors is the number of users who have created content.
+	NumAuthors int
+	// Libraries holds the statistics of each library by its
+	// "Name Major.Minor" string.
+	Libraries map[string]*LibraryUsage
+}
+
+// LibraryUsage is the usage of a library version. Zero counts are left
+// out of the report.
+type LibraryUsage struct {
+	// Patch is the installed patch version.
+	Patch *int `json:"patch,omitempty"`
+	// Content counts content using the library as its main library.
+	Content int `json:"content,omitempty"`
+	// The counts below are of logged events: content loaded, created in
+	// the editor, created by upload and deleted, result pages viewed and
+	// shortcodes inserted.
+	Loaded           int `json:"loaded,omitempty"`
+	Created          int `json:"created,omitempty"`
+	CreatedUpload    int `json:"createdUpload,omitempty"`
+	Deleted          int `json:"deleted,omitempty"`
+	ResultViews      int `json:"resultViews,omitempty"`
+	ShortcodeInserts int `json:"shortcodeInserts,omitempty"`
+}
+
+// Library returns the usage of a library version, adding it if needed.
+func (u *Usage) Library(machineName string, major, minor int) *LibraryUsage {
+	if u.Libraries == nil {
+		u.Libraries = map[string]*LibraryUsage{}
+	}
+	key := h5p.LibraryDependency{MachineName: machineName, MajorVersion: major, MinorVersion: minor}.String()
+	lu, ok := u.Libraries[key]
+	if !ok {
+		lu = &LibraryUsage{}
+		u.Libraries[key] = lu
+	}
+	return lu
+}
+
+// AddInstalled records an installed library version.
+func (u *Usage) AddInstalled(def *h5p.LibraryDefinition) {
+	patch := def.PatchVersion
+	u.Library(def.MachineName, def.MajorVersion, def.MinorVersion).Patch = &patch
+}
+
+// AddContent counts content under the version of its main library listed
+// in its preloadedDependencies.
+func (u *Usage) AddContent(def *h5p.PackageDefinition) {
+	for _, dep := range def.PreloadedDependencies {
+		if dep.MachineName == def.MainLibrary {
+			u.Library(dep.MachineName, dep.MajorVersion, dep.MinorVersion).Content++
+			return
+		}
+	}
+}
+
+func (u *Usage) addForm(form url.Values) error {
+	libraries := u.Libraries
+	if libraries == nil {
+		libraries = map[string]*LibraryUsage{}
+	}
+	data, err := json.Marshal(libraries)
+	if err != nil {
+		return err
+	}
+	form.Set("num_authors", strconv.Itoa(u.NumAuthors))
+	form.Set("libraries", string(data))
+	return nil
+}
+
+// Register registers the site with the Hub and sets SiteUUID to the uuid
+// it is given, which the caller should store and set on later clients.
+func (c *Client) Register(ctx context.Context, site *Site) (string, error) {
+	form := site.form("")
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL()+sitesPath, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	data, err := c.do(req)
+	if err != nil {
+		return "", err
+	}
+	var resp struct {
+		UUID string `json:"uuid"`
+	}
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return "", fmt.Errorf("failed to parse Hub registration: %w", err)
+	}
+	if resp.UUID == "" {
+		return "", ErrNoSiteUUID
+	}
+	c.SiteUUID = resp.UUID
+	return resp.UUID, nil
+}
+
+// registryForm returns the form posted for the registry: the site data
+// and usage statistics if set, or else just the uuid.
+func (c *Client) registryForm(ctx context.Context) (url.Values, error) {
+	form := url.Values{}
+	if c.Site != nil {
+		form = c.Site.form(c.SiteUUID)
+	} else if c.SiteUUID != "" {
+		form.Set("uuid", c.SiteUUID)
+	}
+	if c.Usage != nil {
+		u, err := c.Usage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to collect usage statistics: %w", err)
+		}
+		if err := u.addForm(form); err != nil {
+			return nil, err
+		}
+	}
+	return form, nil
+}
@@ -0,0 +1,80 @@
+package hub
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	h5p "github.com/grokify/h5p-go"
+)
+
+func TestRegisterAndUsage(t *testing.T) {
+	var posted url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		posted = r.PostForm
+		switch r.URL.Path {
+		case "/v1/sites":
+			if posted.Get("uuid") != "" {
+				w.Write([]byte(`{}`))
+				return
+			}
+			w.Write([]byte(`{"uuid":"site-9"}`))
+		case "/v1/content-types/":
+			w.Write([]byte(`{"contentTypes":[]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	site := &Site{PlatformName: "gosite", PlatformVersion: "2.0", H5PVersion: "0.9", LocalID: "a1", CoreAPI: APIVersion{Major: 1, Minor: 26}}
+	c := &Client{BaseURL: srv.URL + "/v1"}
+	uuid, err := c.Register(context.Background(), site)
+	if err != nil || uuid != "site-9" || c.SiteUUID != "site-9" {
+		t.Fatalf("Register = %q, %v", uuid, err)
+	}
+	if posted.Get("platform_name") != "gosite" || posted.Get("type") != SiteLocal || posted.Get("core_api_version") != "1.26" || posted.Get("disabled") != "0" {
+		t.Errorf("Unexpected registration %v", posted)
+	}
+
+	c.Site = site
+	c.Usage = func(context.Context) (*Usage, error) {
+		u := &Usage{NumAuthors: 3}
+		u.AddInstalled(&h5p.LibraryDefinition{MachineName: "H5P.MultiChoice", MajorVersion: 1, MinorVersion: 16, PatchVersion: 4})
+		u.AddContent(&h5p.PackageDefinition{
+			MainLibrary:           "H5P.MultiChoice",
+			PreloadedDependencies: []h5p.LibraryDependency{{MachineName: "H5P.MultiChoice", MajorVersion: 1, MinorVersion: 16}},
+		})
+		u.Library("H5P.MultiChoice", 1, 16).Loaded = 12
+		return u, nil
+	}
+	if _, err := c.Refresh(); err != nil {
+		t.Fatal(err)
+	}
+	if posted.Get("uuid") != "site-9" || posted.Get("num_authors") != "3" || posted.Get("h5p_version") != "0.9" {
+		t.Errorf("Unexpected registry request %v", posted)
+	}
+	var libs map[string]map[string]int
+	if err := json.Unmarshal([]byte(posted.Get("libraries")), &libs); err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]int{"patch": 4, "content": 1, "loaded": 12}; len(libs) != 1 || !maps.Equal(libs["H5P.MultiChoice 1.16"], want) {
+		t.Errorf("Unexpected library usage %v", libs)
+	}
+
+	c.Usage = func(context.Context) (*Usage, error) { return nil, errors.New("db down") }
+	if _, err := c.Refresh(); err == nil {
+		t.Error("Expected the usage error reported")
+	}
+
+	srv.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.Write([]byte(`{}`)) })
+	if _, err := c.Register(context.Background(), site); !errors.Is(err, ErrNoSiteUUID) {
+		t.Errorf("Expected ErrNoSiteUUID, got %v", err)
+	}
+}
@@ -0,0 +1,214 @@
+package h5p
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// ManifestFile is the package root file holding the integrity manifest.
+const ManifestFile = "h5p-manifest.json"
+
+// ManifestAlgorithm is the hash algorithm used for manifest entries.
+const ManifestAlgorithm = "sha256"
+
+// maxManifestSize bounds how much of a manifest is read during verification.
+const maxManifestSize = 64 << 20
+
+var (
+	ErrNoManifest       = errors.New("package has no integrity manifest")
+	ErrManifestMismatch = errors.New("package files do not match manifest")
+	ErrUnsigned         = errors.New("package manifest is not signed")
+	ErrInvalidSignature = errors.New("invalid package signature")
+	ErrUnknownPublicKey = errors.New("package signed with a different key")
+)
+
+// Manifest lists the SHA-256 hash of every package file, keyed by archive
+// path, optionally signed with an Ed25519 key.
+type Manifest struct {
+	Algorithm string            `json:"algorithm"`
+	Files     map[string]string `json:"files"`
+	PublicKey []byte            `json:"publicKey,omitempty"`
+	Signature []byte            `json:"signature,omitempty"`
+}
+
+// GenerateManifest hashes every file the package would write, except the
+// manifest itself.
+func (pkg *H5PPackage) GenerateManifest() (*Manifest, error) {
+	hashes, err := fileHashes(pkg)
+	if err != nil {
+		return nil, err
+	}
+	delete(hashes, ManifestFile)
+	return &Manifest{Algorithm: ManifestAlgorithm, Files: hashes}, nil
+}
+
+// EmbedManifest adds an unsigned manifest to the package. Call it after all
+// other changes, as later modifications invalidate the hashes.
+func (pkg *H5PPackage) EmbedManifest() error {
+	m, err := pkg.GenerateManifest()
+	if err != nil {
+		return err
+	}
+	pkg.Manifest = m
+	return nil
+}
+
+// SignPackage embeds a manifest signed with key. Call it after all other
+// changes, as later modifications invalidate the signature.
+func SignPackage(pkg *H5PPackage, key ed25519.PrivateKey) error {
+	if len(key) != ed25519.PrivateKeySize {
+		return fmt.Errorf("invalid Ed25519 private key size: %d", len(key))
+	}
+	m, err := pkg.GenerateManifest()
+	if err != nil {
+		return err
+	}
+	msg, err := m.signedBytes()
+	if err != nil {
+		return err
+	}
+	m.PublicKey = key.Public().(ed25519.PublicKey)
+	m.Signature = ed25519.Sign(key, msg)
+	pkg.Manifest = m
+	return nil
+}
+
+// VerifyPackage checks a .h5p archive of the given size against its embedded
+// manifest, hashing the archive entries as stored. If pub is nil only the
+// hashes are checked; otherwise the manifest must be signed by pub.
+func VerifyPackage(r io.ReaderAt, size int64, pub ed25519.PublicKey) (*Manifest, error) {
+	reader, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open H5P archive: %w", err)
+	}
+	return verifyZip(reader, pub)
+}
+
+// VerifyPackageFile is VerifyPackage for a .h5p file on disk.
+func VerifyPackageFile(filePath string, pub ed25519.PublicKey) (*Manifest, error) {
+	reader, err := zip.OpenReader(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open H5P file: %w", err)
+	}
+	defer reader.Close()
+	return verifyZip(&reader.Reader, pub)
+}
+
+func verifyZip(reader *zip.Reader, pub ed25519.PublicKey) (*Manifest, error) {
+	var m *Manifest
+	hashes := map[string]string{}
+	for _, file := range reader.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+		rc, err := file.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s: %w", file.Name, err)
+		}
+		if file.Name == ManifestFile {
+			m, err = readManifest(rc)
+		} else {
+			hashes[file.Name], err = hashReader(rc)
+		}
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file.Name, err)
+		}
+	}
+	if m == nil {
+		return nil, ErrNoManifest
+	}
+	return m, m.Verify(hashes, pub)
+}
+
+// Verify compares the manifest with the given file hashes and, if pub is
+// not nil, checks that the manifest is signed by pub.
+func (m *Manifest) Verify(hashes map[string]string, pub ed25519.PublicKey) error {
+	if m.Algorithm != ManifestAlgorithm {
+		return fmt.Errorf("unsupported manifest algorithm: %s", m.Algorithm)
+	}
+
+	var problems []string
+	for _, name := range sortedKeys(m.Files) {
+		got, ok := hashes[name]
+		switch {
+		case !ok:
+			problems = append(problems, name+" is missing")
+		case got != m.Files[name]:
+			problems = append(problems, name+" was modified")
+		}
+	}
+	for _, name := range sortedKeys(hashes) {
+		if _, ok := m.Files[name]; !ok {
+			problems = append(problems, name+" is not listed")
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrManifestMismatch, strings.Join(problems, "; "))
+	}
+
+	if pub == nil {
+		return nil
+	}
+	if len(m.Signature) == 0 {
+		return ErrUnsigned
+	}
+	if m.PublicKey != nil && !bytes.Equal(m.PublicKey, pub) {
+		return ErrUnknownPublicKey
+	}
+	msg, err := m.signedBytes()
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(pub, msg, m.Signature) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// signedBytes returns the canonical encoding of the algorithm and file
+// hashes covered by the signature.
+func (m *Manifest) signedBytes() ([]byte, error) {
+	return json.Marshal(struct {
+		Algorithm string            `json:"algorithm"`
+		Files     map[string]string `json:"files"`
+	}{m.Algorithm, m.Files})
+}
+
+func readManifest(r io.Reader) (*Manifest, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxManifestSize))
+	if err != nil {
+		return nil, err
+	}
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func hashReader(r io.Reader) (string, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,128 @@
+package h5p
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/ed25519"
+	"errors"
+	"io"
+	"path/filepath"
+	"testing"
+)
+
+func TestSignAndVerifyPackage(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	pkg := loadTestPackage(t)
+	if err := SignPackage(pkg, priv); err != nil {
+		t.Fatalf("Failed to sign package: %v", err)
+	}
+	if _, ok := pkg.Manifest.Files["h5p.json"]; !ok {
+		t.Error("Expected manifest to list h5p.json")
+	}
+
+	zipPath := filepath.Join(t.TempDir(), "signed.h5p")
+	if err := pkg.CreateZipFile(zipPath); err != nil {
+		t.Fatalf("Failed to write package: %v", err)
+	}
+	m, err := VerifyPackageFile(zipPath, pub)
+	if err != nil {
+		t.Fatalf("Failed to verify package: %v", err)
+	}
+	if !bytes.Equal(m.PublicKey, pub) {
+		t.Error("Expected manifest to carry the signing public key")
+	}
+
+	loaded, err := LoadH5PPackage(zipPath)
+	if err != nil {
+		t.Fatalf("Failed to load signed package: %v", err)
+	}
+	if loaded.Manifest == nil || !bytes.Equal(loaded.Manifest.Signature, pkg.Manifest.Signature) {
+		t.Error("Expected manifest to be loaded with the package")
+	}
+
+	otherPub, _, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	if _, err := VerifyPackageFile(zipPath, otherPub); !errors.Is(err, ErrUnknownPublicKey) {
+		t.Errorf("Expected ErrUnknownPublicKey, got %v", err)
+	}
+
+	pkg.Manifest.PublicKey = nil
+	pkg.Manifest.Signature[0] ^= 0xff
+	var buf bytes.Buffer
+	if _, err := pkg.WriteTo(&buf); err != nil {
+		t.Fatalf("Failed to write package: %v", err)
+	}
+	if _, err := VerifyPackage(bytes.NewReader(buf.Bytes()), int64(buf.Len()), pub); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Expected ErrInvalidSignature, got %v", err)
+	}
+}
+
+func TestVerifyPackageDetectsTampering(t *testing.T) {
+	pkg := loadTestPackage(t)
+	if err := pkg.EmbedManifest(); err != nil {
+		t.Fatalf("Failed to embed manifest: %v", err)
+	}
+	var buf bytes.Buffer
+	if _, err := pkg.WriteTo(&buf); err != nil {
+		t.Fatalf("Failed to write package: %v", err)
+	}
+	if _, err := VerifyPackage(bytes.NewReader(buf.Bytes()), int64(buf.Len()), nil); err != nil {
+		t.Fatalf("Failed to verify unsigned package: %v", err)
+	}
+
+	pub, _, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	if _, err := VerifyPackage(bytes.NewReader(buf.Bytes()), int64(buf.Len()), pub); !errors.Is(err, ErrUnsigned) {
+		t.Errorf("Expected ErrUnsigned, got %v", err)
+	}
+
+	tampered := rewriteZip(t, buf.Bytes(), "h5p.json", []byte(`{"title":"Tampered"}`))
+	if _, err := VerifyPackage(bytes.NewReader(tampered), int64(len(tampered)), nil); !errors.Is(err, ErrManifestMismatch) {
+		t.Errorf("Expected ErrManifestMismatch, got %v", err)
+	}
+
+	var plain bytes.Buffer
+	if _, err := loadTestPackage(t).WriteTo(&plain); err != nil {
+		t.Fatalf("Failed to write package: %v", err)
+	}
+	if _, err := VerifyPackage(bytes.NewReader(plain.Bytes()), int64(plain.Len()), nil); !errors.Is(err, ErrNoManifest) {
+		t.Errorf("Expected ErrNoManifest, got %v", err)
+	}
+}
+
+// rewriteZip copies an archive, replacing the contents of one entry.
+func rewriteZip(t *testing.T, data []byte, name string, replacement []byte) []byte {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("Failed to read zip: %v", err)
+	}
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("Failed to open %s: %v", f.Name, err)
+		}
+		var r io.Reader = rc
+		if f.Name == name {
+			r = bytes.NewReader(replacement)
+		}
+		if err := writeReaderToZip(zw, f.Name, r); err != nil {
+			t.Fatalf("Failed to copy %s: %v", f.Name, err)
+		}
+		rc.Close()
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("Failed to close zip: %v", err)
+	}
+	return buf.Bytes()
+}
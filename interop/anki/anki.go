@@ -0,0 +1,238 @@
+// Package anki converts H5P Dialog Cards and Flashcards to decks Anki can
+// import: a tab-separated notes file with Anki's file headers plus the
+// images and audio the cards use, to be copied into the collection.media
+// folder.
+package anki
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	h5p "github.com/grokify/h5p-go"
+	"github.com/grokify/h5p-go/schemas"
+)
+
+// Machine names of the supported content types.
+const (
+	DialogCardsLibrary = "H5P.Dialogcards"
+	FlashcardsLibrary  = "H5P.Flashcards"
+
+	// DefaultMediaPrefix is prepended to media file names, as Anki keeps
+	// all media of a collection in one folder.
+	DefaultMediaPrefix = "h5p_"
+)
+
+var ErrUnsupportedContent = errors.New("content type cannot be converted to Anki cards")
+
+var htmlTag = regexp.MustCompile(`<[^>]*>`)
+
+// Options controls the conversion.
+type Options struct {
+	// Deck names the deck. It defaults to the content title.
+	Deck string
+
+	// Tags are added to every note.
+	Tags []string
+
+	// Tips adds card tips below the text of the side they belong to.
+	Tips bool
+
+	// MediaPrefix defaults to DefaultMediaPrefix.
+	MediaPrefix string
+}
+
+// Deck is a set of Basic notes.
+type Deck struct {
+	Name  string
+	Cards []Card
+
+	// Media maps the Anki media file names used by the cards to their
+	// paths in the H5P content folder.
+	Media map[string]string
+}
+
+// Card is an Anki note of the Basic type.
+type Card struct {
+	Front string
+	Back  string
+	Tags  []string
+}
+
+// FromPackage converts the main content of pkg.
+func FromPackage(pkg *h5p.H5PPackage, opts Options) (*Deck, error) {
+	if pkg.PackageDefinition == nil || pkg.Content == nil {
+		return nil, fmt.Errorf("%w: package has no content", ErrUnsupportedContent)
+	}
+	if opts.Deck == "" {
+		opts.Deck = pkg.PackageDefinition.Title
+	}
+	return FromContent(pkg.PackageDefinition.MainLibrary, pkg.Content.Params, opts)
+}
+
+// FromContent converts params of the given library, which may be a machine
+// name or a "Name Major.Minor" library string.
+func FromContent(library string, params any, opts Options) (*Deck, error) {
+	machineName, _, _ := strings.Cut(library, " ")
+	switch machineName {
+	case DialogCardsLibrary:
+		var p schemas.DialogCardsParams
+		if err := decode(params, &p); err != nil {
+			return nil, err
+		}
+		return FromDialogCards(&p, opts), nil
+	case FlashcardsLibrary:
+		var p schemas.FlashcardsParams
+		if err := decode(params, &p); err != nil {
+			return nil, err
+		}
+		return FromFlashcards(&p, opts), nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedContent, library)
+}
+
+// FromDialogCards converts Dialog Cards, with card text on the front and the
+// answer on the back. Images go on the front and audio on the back.
+func FromDialogCards(p *schemas.DialogCardsParams, opts Options) *Deck {
+	deck := newDeck(opts, p.Title)
+	for _, d := range p.Dialogs {
+		front, back := d.Text, d.Answer
+		if d.Image != nil && d.Image.Path != "" {
+			front = deck.image(opts, d.Image.Path, d.ImageAltText) + front
+		}
+		for _, a := range d.Audio {
+			if name := deck.media(opts, a.Path); name != "" {
+				back += "[sound:" + name + "]"
+				break
+			}
+		}
+		if opts.Tips && d.Tips != nil {
+			front = withTip(front, d.Tips.Front)
+			back = withTip(back, d.Tips.Back)
+		}
+		deck.Cards = append(deck.Cards, Card{Front: front, Back: back, Tags: opts.Tags})
+	}
+	return deck
+}
+
+// FromFlashcards converts Flashcards. The expected answer is plain text in
+// H5P and is escaped.
+func FromFlashcards(p *schemas.FlashcardsParams, opts Options) *Deck {
+	deck := newDeck(opts, "")
+	for _, c := range p.Cards {
+		front := c.Text
+		if c.Image != nil && c.Image.Path != "" {
+			front = deck.image(opts, c.Image.Path, c.ImageAltText) + front
+		}
+		if opts.Tips {
+			front = withTip(front, c.Tip)
+		}
+		deck.Cards = append(deck.Cards, Card{Front: front, Back: html.EscapeString(c.Answer), Tags: opts.Tags})
+	}
+	return deck
+}
+
+// WriteTSV writes the deck as an Anki notes file with HTML enabled.
+func (d *Deck) WriteTSV(w io.Writer) error {
+	header := "#separator:tab\n#html:true\n#notetype:Basic\n#tags column:3\n"
+	if d.Name != "" {
+		header += "#deck:" + strings.Join(strings.Fields(d.Name), " ") + "\n"
+	}
+	if _, err := io.WriteString(w, header); err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	cw.Comma = '\t'
+	for _, c := range d.Cards {
+		tags := make([]string, len(c.Tags))
+		for i, t := range c.Tags {
+			tags[i] = strings.Join(strings.Fields(t), "_")
+		}
+		if err := cw.Write([]string{c.Front, c.Back, strings.Join(tags, " ")}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteMedia copies the media used by the deck from pkg into dir, usually
+// the collection.media folder of an Anki profile.
+func (d *Deck) WriteMedia(pkg *h5p.H5PPackage, dir string) error {
+	for name, contentPath := range d.Media {
+		cf, ok := pkg.ContentFiles[contentPath]
+		if !ok {
+			return fmt.Errorf("media file %s is not in the package", contentPath)
+		}
+		data, err := cf.Bytes()
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(dir, 0750); err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0600); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newDeck(opts Options, title string) *Deck {
+	name := opts.Deck
+	if name == "" {
+		name = plainText(title)
+	}
+	return &Deck{Name: name, Media: map[string]string{}}
+}
+
+// media registers a content file and returns its Anki name, or "" for
+// external URLs.
+func (d *Deck) media(opts Options, contentPath string) string {
+	if contentPath == "" || strings.Contains(contentPath, "://") {
+		return ""
+	}
+	prefix := opts.MediaPrefix
+	if prefix == "" {
+		prefix = DefaultMediaPrefix
+	}
+	name := prefix + strings.ReplaceAll(path.Clean(contentPath), "/", "_")
+	d.Media[name] = contentPath
+	return name
+}
+
+func (d *Deck) image(opts Options, contentPath, alt string) string {
+	src := d.media(opts, contentPath)
+	if src == "" {
+		src = contentPath
+	}
+	return `<img src="` + html.EscapeString(src) + `" alt="` + html.EscapeString(alt) + `"><br>`
+}
+
+func withTip(text, tip string) string {
+	if plainText(tip) == "" {
+		return text
+	}
+	return text + `<br><small>` + tip + `</small>`
+}
+
+// plainText strips HTML tags and entities, for deck names and empty checks.
+func plainText(s string) string {
+	return strings.TrimSpace(html.UnescapeString(htmlTag.ReplaceAllString(s, "")))
+}
+
+func decode(params any, v any) error {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
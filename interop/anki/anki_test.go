@@ -0,0 +1,107 @@
+package anki
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	h5p "github.com/grokify/h5p-go"
+	"github.com/grokify/h5p-go/schemas"
+)
+
+func TestFromDialogCards(t *testing.T) {
+	params := map[string]any{
+		"title": "<p>French &amp; more</p>",
+		"dialogs": []any{
+			map[string]any{
+				"text":         "<p>chat</p>",
+				"answer":       "<p>cat</p>",
+				"image":        map[string]any{"path": "images/cat.png", "mime": "image/png"},
+				"imageAltText": `A "cat"`,
+				"audio":        []any{map[string]any{"path": "audios/chat.mp3"}},
+				"tips":         map[string]any{"front": "<p>animal</p>", "back": "<p></p>"},
+			},
+			map[string]any{"text": "chien", "answer": "dog\twith tab"},
+		},
+	}
+	deck, err := FromContent("H5P.Dialogcards 1.9", params, Options{Tips: true, Tags: []string{"h5p", "french words"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deck.Name != "French & more" || len(deck.Cards) != 2 {
+		t.Fatalf("deck = %+v", deck)
+	}
+	want := Card{
+		Front: `<img src="h5p_images_cat.png" alt="A &#34;cat&#34;"><br><p>chat</p><br><small><p>animal</p></small>`,
+		Back:  `<p>cat</p>[sound:h5p_audios_chat.mp3]`,
+		Tags:  []string{"h5p", "french words"},
+	}
+	if !reflect.DeepEqual(deck.Cards[0], want) {
+		t.Errorf("card = %+v\nwant %+v", deck.Cards[0], want)
+	}
+	wantMedia := map[string]string{"h5p_images_cat.png": "images/cat.png", "h5p_audios_chat.mp3": "audios/chat.mp3"}
+	if !reflect.DeepEqual(deck.Media, wantMedia) {
+		t.Errorf("media = %v", deck.Media)
+	}
+
+	var buf bytes.Buffer
+	if err := deck.WriteTSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	header, body, _ := strings.Cut(buf.String(), "#deck:French & more\n")
+	if header != "#separator:tab\n#html:true\n#notetype:Basic\n#tags column:3\n" {
+		t.Errorf("header = %q", header)
+	}
+	r := csv.NewReader(strings.NewReader(body))
+	r.Comma = '\t'
+	rows, err := r.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 || rows[1][1] != "dog\twith tab" || rows[0][2] != "h5p french_words" {
+		t.Errorf("rows = %q", rows)
+	}
+}
+
+func TestFromPackageFlashcards(t *testing.T) {
+	pkg := h5p.NewH5PPackage()
+	pkg.SetPackageDefinition(&h5p.PackageDefinition{Title: "Capitals", MainLibrary: FlashcardsLibrary})
+	pkg.SetContent(&h5p.Content{Params: &schemas.FlashcardsParams{
+		Cards: []schemas.Flashcard{
+			{Text: "Capital of France?", Answer: "Paris <Île-de-France>", Tip: "City of light", Image: &schemas.ImageFile{Path: "images/paris.jpg"}},
+		},
+	}})
+	if err := pkg.AddContentAsset("images/paris.jpg", []byte("JPEG"), "image/jpeg"); err != nil {
+		t.Fatal(err)
+	}
+
+	deck, err := FromPackage(pkg, Options{MediaPrefix: "cap_"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deck.Name != "Capitals" || len(deck.Cards) != 1 {
+		t.Fatalf("deck = %+v", deck)
+	}
+	c := deck.Cards[0]
+	if c.Front != `<img src="cap_images_paris.jpg" alt=""><br>Capital of France?` || c.Back != "Paris &lt;Île-de-France&gt;" {
+		t.Errorf("card = %+v", c)
+	}
+
+	dir := t.TempDir()
+	if err := deck.WriteMedia(pkg, dir); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "cap_images_paris.jpg")); err != nil || string(data) != "JPEG" {
+		t.Errorf("media = %q, %v", data, err)
+	}
+
+	pkg.PackageDefinition.MainLibrary = "H5P.MultiChoice"
+	if _, err := FromPackage(pkg, Options{}); !errors.Is(err, ErrUnsupportedContent) {
+		t.Errorf("Expected ErrUnsupportedContent, got %v", err)
+	}
+}
@@ -0,0 +1,147 @@
+// Package csvexport writes H5P question sets as CSV, one row per question,
+// in the column layout read by package csvimport, so quizzes can be
+// reviewed and edited in a spreadsheet and imported again.
+package csvexport
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	h5p "github.com/grokify/h5p-go"
+	"github.com/grokify/h5p-go/interop/csvimport"
+	"github.com/grokify/h5p-go/schemas"
+)
+
+var (
+	ErrUnsupportedQuestion = errors.New("question type is not supported by CSV export")
+	ErrSeparatorInValue    = errors.New("value contains the answer separator")
+)
+
+// Options controls CSV output.
+type Options struct {
+	// Comma is the field delimiter, ',' if zero.
+	Comma rune
+	// AnswerSeparator separates values within a cell, "|" if empty.
+	AnswerSeparator string
+	// SkipUnsupported omits questions other than MultiChoice and TrueFalse
+	// instead of failing with ErrUnsupportedQuestion.
+	SkipUnsupported bool
+}
+
+// Write writes qs to w as CSV with a header row.
+func Write(w io.Writer, qs *h5p.QuestionSet, opts Options) error {
+	records, err := Records(qs, opts)
+	if err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	if opts.Comma != 0 {
+		cw.Comma = opts.Comma
+	}
+	if err := cw.WriteAll(records); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return nil
+}
+
+// Records returns the header row followed by one row per question, with
+// columns in csvimport.DefaultColumns order.
+func Records(qs *h5p.QuestionSet, opts Options) ([][]string, error) {
+	sep := opts.AnswerSeparator
+	if sep == "" {
+		sep = csvimport.DefaultAnswerSeparator
+	}
+	records := [][]string{csvimport.DefaultColumns}
+	for i := range qs.Questions {
+		q := &qs.Questions[i]
+		row, err := record(q, sep)
+		if errors.Is(err, ErrUnsupportedQuestion) && opts.SkipUnsupported {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("question %d: %w", i, err)
+		}
+		records = append(records, row)
+	}
+	return records, nil
+}
+
+// record returns the row for q: type, question, answers, correct, feedback
+// and title.
+func record(q *h5p.Question, sep string) ([]string, error) {
+	title := ""
+	if q.Metadata != nil {
+		title = q.Metadata.Title
+	}
+	switch q.MachineName() {
+	case "H5P.MultiChoice":
+		var p schemas.MultiChoiceParams
+		if err := q.DecodeParams(&p); err != nil {
+			return nil, err
+		}
+		var answers, flags, feedback []string
+		hasFeedback := false
+		for _, a := range p.Answers {
+			answers = append(answers, a.Text)
+			flags = append(flags, flag(a.Correct))
+			fb := ""
+			if a.TipsAndFeedback != nil {
+				fb = a.TipsAndFeedback.ChosenFeedback
+			}
+			hasFeedback = hasFeedback || fb != ""
+			feedback = append(feedback, fb)
+		}
+		if !hasFeedback {
+			feedback = nil
+		}
+		answersCell, err := join(answers, sep)
+		if err != nil {
+			return nil, err
+		}
+		feedbackCell, err := join(feedback, sep)
+		if err != nil {
+			return nil, err
+		}
+		return []string{csvimport.TypeMultiChoice, p.Question, answersCell, strings.Join(flags, sep), feedbackCell, title}, nil
+
+	case "H5P.TrueFalse":
+		var p schemas.TrueFalseParams
+		if err := q.DecodeParams(&p); err != nil {
+			return nil, err
+		}
+		var feedback []string
+		if b := p.Behaviour; b != nil && (b.FeedbackOnCorrect != "" || b.FeedbackOnWrong != "") {
+			feedback = []string{b.FeedbackOnCorrect}
+			if b.FeedbackOnWrong != "" {
+				feedback = append(feedback, b.FeedbackOnWrong)
+			}
+		}
+		feedbackCell, err := join(feedback, sep)
+		if err != nil {
+			return nil, err
+		}
+		return []string{csvimport.TypeTrueFalse, p.Question, "", p.Correct, feedbackCell, title}, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedQuestion, q.Library)
+}
+
+// join joins values with sep, failing if a value contains sep and could
+// not be split again on import.
+func join(values []string, sep string) (string, error) {
+	for _, v := range values {
+		if strings.Contains(v, sep) {
+			return "", fmt.Errorf("%w %q: %q", ErrSeparatorInValue, sep, v)
+		}
+	}
+	return strings.Join(values, sep), nil
+}
+
+func flag(correct bool) string {
+	if correct {
+		return "1"
+	}
+	return "0"
+}
@@ -0,0 +1,87 @@
+package csvexport
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+
+	h5p "github.com/grokify/h5p-go"
+	"github.com/grokify/h5p-go/interop/csvimport"
+	"github.com/grokify/h5p-go/schemas"
+)
+
+func testQuestionSet() *h5p.QuestionSet {
+	return &h5p.QuestionSet{Questions: []h5p.Question{
+		{
+			Library:  "H5P.MultiChoice 1.16",
+			Metadata: &h5p.ContentMetadata{Title: "Arithmetic"},
+			Params: &schemas.MultiChoiceParams{
+				Question: "What is 2+2, roughly?",
+				Answers: []schemas.AnswerOption{
+					{Text: "3"},
+					{Text: "4", Correct: true, TipsAndFeedback: &schemas.AnswerTipsAndFeedback{ChosenFeedback: "Right"}},
+				},
+			},
+		},
+		{
+			Library: "H5P.TrueFalse 1.8",
+			Params: map[string]any{
+				"question":  "The sun is a star.",
+				"correct":   "true",
+				"behaviour": map[string]any{"feedbackOnCorrect": "Yes"},
+			},
+		},
+	}}
+}
+
+func TestRecords(t *testing.T) {
+	got, err := Records(testQuestionSet(), Options{})
+	if err != nil {
+		t.Fatalf("Records failed: %v", err)
+	}
+	want := [][]string{
+		csvimport.DefaultColumns,
+		{"multichoice", "What is 2+2, roughly?", "3|4", "0|1", "|Right", "Arithmetic"},
+		{"truefalse", "The sun is a star.", "", "true", "Yes", ""},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected\n%v\ngot\n%v", want, got)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, testQuestionSet(), Options{Comma: ';'}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	qs, err := csvimport.Read(&buf, csvimport.Options{Comma: ';'})
+	if err != nil {
+		t.Fatalf("Import of exported CSV failed: %v", err)
+	}
+	again, err := Records(qs, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, _ := Records(testQuestionSet(), Options{})
+	if !reflect.DeepEqual(again, first) {
+		t.Errorf("Round trip changed rows:\n%v\n%v", first, again)
+	}
+}
+
+func TestRecordsErrors(t *testing.T) {
+	qs := testQuestionSet()
+	qs.Questions = append(qs.Questions, h5p.Question{Library: "H5P.Essay 1.5", Params: map[string]any{}})
+	if _, err := Records(qs, Options{}); !errors.Is(err, ErrUnsupportedQuestion) {
+		t.Errorf("Expected ErrUnsupportedQuestion, got %v", err)
+	}
+	if records, err := Records(qs, Options{SkipUnsupported: true}); err != nil || len(records) != 3 {
+		t.Errorf("Expected essay to be skipped, got %d records, %v", len(records), err)
+	}
+
+	qs = testQuestionSet()
+	qs.Questions[0].Params.(*schemas.MultiChoiceParams).Answers[0].Text = "A|B"
+	if _, err := Records(qs, Options{}); !errors.Is(err, ErrSeparatorInValue) {
+		t.Errorf("Expected ErrSeparatorInValue, got %v", err)
+	}
+}
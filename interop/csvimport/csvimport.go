@@ -0,0 +1,133 @@
+// Package csvimport builds H5P question sets from spreadsheets saved as CSV
+// or TSV, one question per row.
+//
+// The columns, in default order, are:
+//
+//	type      "multichoice" (the default when empty) or "truefalse"
+//	question  question text, may contain HTML
+//	answers   multichoice answers separated by "|"
+//	correct   multichoice flags per answer separated by "|", each one of
+//	          1/0, true/false, yes/no or x/empty; "true" or "false" for
+//	          truefalse questions
+//	feedback  multichoice feedback per answer separated by "|"; for
+//	          truefalse questions the feedback on a correct and a wrong
+//	          answer
+//	title     optional question title
+//
+// A first row naming the columns is treated as a header and may list them in
+// any order. For example:
+//
+//	type,question,answers,correct,feedback
+//	multichoice,What is 2+2?,3|4|5,0|1|0,Too low|Right|Too high
+//	truefalse,The sun is a star.,,true,Yes|It is
+package csvimport
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	h5p "github.com/grokify/h5p-go"
+)
+
+// Column names.
+const (
+	ColumnType     = "type"
+	ColumnQuestion = "question"
+	ColumnAnswers  = "answers"
+	ColumnCorrect  = "correct"
+	ColumnFeedback = "feedback"
+	ColumnTitle    = "title"
+)
+
+// DefaultColumns is the column order of input without a header row.
+var DefaultColumns = []string{ColumnType, ColumnQuestion, ColumnAnswers, ColumnCorrect, ColumnFeedback, ColumnTitle}
+
+// Question types.
+const (
+	TypeMultiChoice = "multichoice"
+	TypeTrueFalse   = "truefalse"
+)
+
+// DefaultAnswerSeparator separates answers, flags and feedback in a cell.
+const DefaultAnswerSeparator = "|"
+
+// Options controls parsing.
+type Options struct {
+	// Comma is the field delimiter, ',' if zero.
+	Comma rune
+	// Comment, if set, marks lines to ignore.
+	Comment rune
+	// LazyQuotes allows quotes in unquoted fields and non-doubled quotes
+	// in quoted fields, as written by some spreadsheet tools.
+	LazyQuotes bool
+	// AnswerSeparator separates values within a cell, "|" if empty.
+	AnswerSeparator string
+	// Columns is the column order of input without a header row,
+	// DefaultColumns if empty.
+	Columns []string
+	// Title is the title of the question set.
+	Title string
+}
+
+// TSVOptions returns options for tab-separated input.
+func TSVOptions() Options {
+	return Options{Comma: '\t'}
+}
+
+// CellError locates a problem in the input. Row is the 1-based record
+// number, 0 for problems with the question set as a whole.
+type CellError struct {
+	Row     int    `json:"row"`
+	Column  string `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+func (e CellError) Error() string {
+	switch {
+	case e.Row == 0:
+		return e.Message
+	case e.Column == "":
+		return fmt.Sprintf("row %d: %s", e.Row, e.Message)
+	}
+	return fmt.Sprintf("row %d, column %s: %s", e.Row, e.Column, e.Message)
+}
+
+// Errors collects every problem found in the input.
+type Errors []CellError
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, ce := range e {
+		msgs[i] = ce.Error()
+	}
+	return fmt.Sprintf("%d import error(s): %s", len(e), strings.Join(msgs, "; "))
+}
+
+// Read parses CSV from r.
+func Read(r io.Reader, opts Options) (*h5p.QuestionSet, error) {
+	cr := csv.NewReader(r)
+	if opts.Comma != 0 {
+		cr.Comma = opts.Comma
+	}
+	cr.Comment = opts.Comment
+	cr.LazyQuotes = opts.LazyQuotes
+	cr.FieldsPerRecord = -1
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV: %w", err)
+	}
+	return FromRecords(records, opts)
+}
+
+// ReadFile parses the CSV file at path.
+func ReadFile(path string, opts Options) (*h5p.QuestionSet, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Read(f, opts)
+}
@@ -0,0 +1,92 @@
+package csvimport
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/grokify/h5p-go/schemas"
+)
+
+func TestRead(t *testing.T) {
+	input := `Question,Type,Answers,Correct,Feedback,Title
+"What is 2+2?",,3|4|5,0|1|0,Too low|Right|Too high,Arithmetic
+The sun is a star.,truefalse,,TRUE,Yes|It is
+
+"Pick primes, all of them",multichoice,2 | 3 | 4,x|x|,,
+`
+	qs, err := Read(strings.NewReader(input), Options{Title: "Mixed"})
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if qs.Title != "Mixed" || len(qs.Questions) != 3 {
+		t.Fatalf("Expected 3 questions titled Mixed, got %d titled %q", len(qs.Questions), qs.Title)
+	}
+
+	mc := qs.Questions[0]
+	params := mc.Params.(*schemas.MultiChoiceParams)
+	if mc.Library != "H5P.MultiChoice 1.16" || mc.SubContentID == "" || mc.Metadata.Title != "Arithmetic" {
+		t.Errorf("Unexpected question: %+v", mc)
+	}
+	if len(params.Answers) != 3 || !params.Answers[1].Correct || params.Answers[0].Correct ||
+		params.Answers[2].TipsAndFeedback.ChosenFeedback != "Too high" {
+		t.Errorf("Unexpected answers: %+v", params.Answers)
+	}
+
+	tf := qs.Questions[1].Params.(*schemas.TrueFalseParams)
+	if qs.Questions[1].Library != "H5P.TrueFalse 1.8" || !tf.IsTrue() ||
+		tf.Behaviour.FeedbackOnCorrect != "Yes" || tf.Behaviour.FeedbackOnWrong != "It is" {
+		t.Errorf("Unexpected truefalse params: %+v", tf)
+	}
+
+	primes := qs.Questions[2].Params.(*schemas.MultiChoiceParams)
+	var correct []bool
+	for _, a := range primes.Answers {
+		correct = append(correct, a.Correct)
+	}
+	if primes.Question != "Pick primes, all of them" || !reflect.DeepEqual(correct, []bool{true, true, false}) {
+		t.Errorf("Unexpected primes question: %+v", primes)
+	}
+}
+
+func TestReadTSVWithoutHeader(t *testing.T) {
+	input := "multichoice\tCapital of France?\tParis;Lyon\t1;0\n"
+	opts := TSVOptions()
+	opts.AnswerSeparator = ";"
+	qs, err := Read(strings.NewReader(input), opts)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if p := qs.Questions[0].Params.(*schemas.MultiChoiceParams); len(p.Answers) != 2 || !p.Answers[0].Correct {
+		t.Errorf("Unexpected params: %+v", p)
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	input := `type,question,answers,correct
+essay,Write,,
+multichoice,,A|B,0|0|1
+truefalse,Sky is green,,maybe
+multichoice,Q,A|B,1|perhaps
+`
+	_, err := Read(strings.NewReader(input), Options{})
+	var errs Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Expected Errors, got %v", err)
+	}
+	want := []CellError{
+		{Row: 2, Column: ColumnType, Message: `unknown question type "essay", must be multichoice or truefalse`},
+		{Row: 3, Column: ColumnCorrect, Message: "3 correct flags for 2 answers"},
+		{Row: 3, Column: ColumnQuestion, Message: "question text is required"},
+		{Row: 3, Column: ColumnCorrect, Message: "at least one answer must be marked as correct"},
+		{Row: 4, Column: ColumnCorrect, Message: `correct must be "true" or "false", got "maybe"`},
+		{Row: 5, Column: ColumnCorrect, Message: `invalid flag "perhaps" for answer 2`},
+	}
+	if !reflect.DeepEqual([]CellError(errs), want) {
+		t.Errorf("Expected errors\n%v\ngot\n%v", want, errs)
+	}
+	if got := errs[1].Error(); got != "row 3, column correct: 3 correct flags for 2 answers" {
+		t.Errorf("Unexpected error text %q", got)
+	}
+}
@@ -0,0 +1,234 @@
+package csvimport
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	h5p "github.com/grokify/h5p-go"
+	"github.com/grokify/h5p-go/schemas"
+)
+
+// FromRecords builds a question set from parsed rows, e.g. from another
+// spreadsheet reader. Empty rows are skipped. The result is validated; all
+// problems are returned together as Errors.
+func FromRecords(records [][]string, opts Options) (*h5p.QuestionSet, error) {
+	sep := opts.AnswerSeparator
+	if sep == "" {
+		sep = DefaultAnswerSeparator
+	}
+	columns, header := Columns(records, opts)
+	first := 0
+	if header {
+		first = 1
+	}
+
+	qs := &h5p.QuestionSet{Title: opts.Title}
+	var errs Errors
+	for i := first; i < len(records); i++ {
+		rec := records[i]
+		if isEmpty(rec) {
+			continue
+		}
+		row := rowReader{num: i + 1, columns: columns, record: rec, sep: sep}
+		q, ok := row.question()
+		errs = append(errs, row.errs...)
+		if ok {
+			qs.Questions = append(qs.Questions, q)
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	for _, p := range qs.ValidateAll().Errors() {
+		errs = append(errs, CellError{Message: p.Error()})
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	qs.EnsureSubContentIDs()
+	return qs, nil
+}
+
+// Columns returns the column layout of records: the names in the first
+// record if it is a header row, otherwise opts.Columns or DefaultColumns.
+func Columns(records [][]string, opts Options) (columns []string, header bool) {
+	if len(records) > 0 && isHeader(records[0]) {
+		return normalize(records[0]), true
+	}
+	if len(opts.Columns) > 0 {
+		return opts.Columns, false
+	}
+	return DefaultColumns, false
+}
+
+// rowReader converts one row, collecting errors.
+type rowReader struct {
+	num     int
+	columns []string
+	record  []string
+	sep     string
+	errs    Errors
+}
+
+func (r *rowReader) get(column string) string {
+	if i := slices.Index(r.columns, column); i >= 0 && i < len(r.record) {
+		return strings.TrimSpace(r.record[i])
+	}
+	return ""
+}
+
+func (r *rowReader) list(column string) []string {
+	v := r.get(column)
+	if v == "" {
+		return nil
+	}
+	parts := strings.Split(v, r.sep)
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+	return parts
+}
+
+func (r *rowReader) errorf(column, format string, args ...any) {
+	r.errs = append(r.errs, CellError{Row: r.num, Column: column, Message: fmt.Sprintf(format, args...)})
+}
+
+func (r *rowReader) question() (h5p.Question, bool) {
+	var machineName string
+	var params any
+	var result *schemas.ValidationResult
+	switch typ := strings.ToLower(r.get(ColumnType)); typ {
+	case "", TypeMultiChoice:
+		p := r.multiChoice()
+		machineName, params, result = "H5P.MultiChoice", p, p.ValidateAll()
+	case TypeTrueFalse:
+		p := r.trueFalse()
+		machineName, params, result = "H5P.TrueFalse", p, p.ValidateAll()
+	default:
+		r.errorf(ColumnType, "unknown question type %q, must be %s or %s", typ, TypeMultiChoice, TypeTrueFalse)
+		return h5p.Question{}, false
+	}
+	for _, p := range result.Errors() {
+		r.errorf(paramsColumn(p), "%s", p.Message)
+	}
+	if len(r.errs) > 0 {
+		return h5p.Question{}, false
+	}
+
+	library, _ := h5p.LatestLibraryString(machineName)
+	q := h5p.Question{Library: library, Params: params}
+	if title := r.get(ColumnTitle); title != "" {
+		q.Metadata = &h5p.ContentMetadata{Title: title, License: "U"}
+	}
+	return q, true
+}
+
+func (r *rowReader) multiChoice() *schemas.MultiChoiceParams {
+	answers := r.list(ColumnAnswers)
+	flags := r.list(ColumnCorrect)
+	feedback := r.list(ColumnFeedback)
+	if len(flags) > len(answers) {
+		r.errorf(ColumnCorrect, "%d correct flags for %d answers", len(flags), len(answers))
+	}
+	if len(feedback) > len(answers) {
+		r.errorf(ColumnFeedback, "%d feedback texts for %d answers", len(feedback), len(answers))
+	}
+
+	p := &schemas.MultiChoiceParams{Question: r.get(ColumnQuestion)}
+	for i, text := range answers {
+		a := schemas.AnswerOption{Text: text}
+		if i < len(flags) {
+			correct, ok := parseFlag(flags[i])
+			if !ok {
+				r.errorf(ColumnCorrect, "invalid flag %q for answer %d", flags[i], i+1)
+			}
+			a.Correct = correct
+		}
+		if i < len(feedback) && feedback[i] != "" {
+			a.TipsAndFeedback = &schemas.AnswerTipsAndFeedback{ChosenFeedback: feedback[i]}
+		}
+		p.Answers = append(p.Answers, a)
+	}
+	return p
+}
+
+func (r *rowReader) trueFalse() *schemas.TrueFalseParams {
+	p := &schemas.TrueFalseParams{Question: r.get(ColumnQuestion)}
+	if v := r.get(ColumnCorrect); v != "" {
+		// Unparseable values are kept so validation reports them.
+		p.Correct = v
+		if correct, ok := parseFlag(v); ok {
+			p.Correct = strconv.FormatBool(correct)
+		}
+	}
+	if feedback := r.list(ColumnFeedback); len(feedback) > 0 {
+		if len(feedback) > 2 {
+			r.errorf(ColumnFeedback, "truefalse questions take at most 2 feedback texts, got %d", len(feedback))
+		}
+		b := &schemas.TrueFalseBehaviour{FeedbackOnCorrect: feedback[0]}
+		if len(feedback) > 1 {
+			b.FeedbackOnWrong = feedback[1]
+		}
+		p.Behaviour = b
+	}
+	return p
+}
+
+// paramsColumn maps a params validation path to the column it came from.
+func paramsColumn(p schemas.ValidationError) string {
+	switch {
+	case p.Code == schemas.CodeNoCorrect, p.Path == "correct":
+		return ColumnCorrect
+	case strings.HasPrefix(p.Path, "answers"):
+		return ColumnAnswers
+	case p.Path == "question":
+		return ColumnQuestion
+	}
+	return ""
+}
+
+// parseFlag parses a correctness flag. An empty flag is false.
+func parseFlag(s string) (value, ok bool) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "1", "true", "yes", "y", "x", "correct":
+		return true, true
+	case "", "0", "false", "no", "n":
+		return false, true
+	}
+	return false, false
+}
+
+// isHeader reports whether every non-empty cell of rec is a column name,
+// including the question column.
+func isHeader(rec []string) bool {
+	cols := normalize(rec)
+	if !slices.Contains(cols, ColumnQuestion) {
+		return false
+	}
+	for _, c := range cols {
+		if c != "" && !slices.Contains(DefaultColumns, c) {
+			return false
+		}
+	}
+	return true
+}
+
+func normalize(rec []string) []string {
+	cols := make([]string, len(rec))
+	for i, c := range rec {
+		cols[i] = strings.ToLower(strings.TrimSpace(c))
+	}
+	return cols
+}
+
+func isEmpty(rec []string) bool {
+	for _, c := range rec {
+		if strings.TrimSpace(c) != "" {
+			return false
+		}
+	}
+	return true
+}
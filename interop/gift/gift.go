@@ -0,0 +1,167 @@
+// Package gift converts between H5P question sets and Moodle's GIFT text
+// format, so questions can be moved to and from Moodle question banks.
+package gift
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	h5p "github.com/grokify/h5p-go"
+	"github.com/grokify/h5p-go/schemas"
+)
+
+// Machine names of the supported question libraries. Blanks and Essay
+// questions are only read.
+const (
+	MultiChoiceLibrary = "H5P.MultiChoice"
+	TrueFalseLibrary   = "H5P.TrueFalse"
+	BlanksLibrary      = "H5P.Blanks"
+	EssayLibrary       = "H5P.Essay"
+)
+
+var ErrUnsupportedQuestion = errors.New("question type is not supported by GIFT conversion")
+
+// Options controls GIFT conversion. Category only applies to output.
+type Options struct {
+	// Category, if set, is written as a $CATEGORY directive so Moodle
+	// imports the questions into that question bank category, e.g.
+	// "Geography/Capitals".
+	Category string
+
+	// SkipUnsupported omits questions that cannot be converted instead of
+	// failing with ErrUnsupportedQuestion.
+	SkipUnsupported bool
+}
+
+// Marshal returns the GIFT text for qs using default options.
+func Marshal(qs *h5p.QuestionSet) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := Write(&buf, qs, Options{}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Write writes the MultiChoice and TrueFalse questions of qs to w in GIFT
+// format. HTML question text is marked with [html]; question titles are
+// taken from the question metadata.
+func Write(w io.Writer, qs *h5p.QuestionSet, opts Options) error {
+	var buf bytes.Buffer
+	if qs.Title != "" {
+		fmt.Fprintf(&buf, "// %s\n\n", strings.ReplaceAll(qs.Title, "\n", " "))
+	}
+	if opts.Category != "" {
+		fmt.Fprintf(&buf, "$CATEGORY: %s\n\n", opts.Category)
+	}
+
+	for i := range qs.Questions {
+		q := &qs.Questions[i]
+		var err error
+		switch q.MachineName() {
+		case MultiChoiceLibrary:
+			var params schemas.MultiChoiceParams
+			if err = q.DecodeParams(&params); err == nil {
+				writeMultiChoice(&buf, title(q), &params)
+			}
+		case TrueFalseLibrary:
+			var params schemas.TrueFalseParams
+			if err = q.DecodeParams(&params); err == nil {
+				writeTrueFalse(&buf, title(q), &params)
+			}
+		default:
+			if opts.SkipUnsupported {
+				continue
+			}
+			err = fmt.Errorf("%w: %s", ErrUnsupportedQuestion, q.Library)
+		}
+		if err != nil {
+			return fmt.Errorf("question %d: %w", i, err)
+		}
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func title(q *h5p.Question) string {
+	if q.Metadata == nil {
+		return ""
+	}
+	return q.Metadata.Title
+}
+
+func writeMultiChoice(buf *bytes.Buffer, title string, p *schemas.MultiChoiceParams) {
+	writeQuestionText(buf, title, p.Question)
+	buf.WriteString(" {\n")
+
+	correct := 0
+	for _, a := range p.Answers {
+		if a.Correct {
+			correct++
+		}
+	}
+	for _, a := range p.Answers {
+		switch {
+		case correct > 1 && a.Correct:
+			fmt.Fprintf(buf, "\t~%%%s%%", weight(100/float64(correct)))
+		case correct > 1:
+			buf.WriteString("\t~%-100%")
+		case a.Correct:
+			buf.WriteString("\t=")
+		default:
+			buf.WriteString("\t~")
+		}
+		buf.WriteString(escape(a.Text))
+		if a.TipsAndFeedback != nil && a.TipsAndFeedback.ChosenFeedback != "" {
+			buf.WriteString("#" + escape(a.TipsAndFeedback.ChosenFeedback))
+		}
+		buf.WriteString("\n")
+	}
+	buf.WriteString("}\n\n")
+}
+
+func writeTrueFalse(buf *bytes.Buffer, title string, p *schemas.TrueFalseParams) {
+	writeQuestionText(buf, title, p.Question)
+	answer := "FALSE"
+	if p.IsTrue() {
+		answer = "TRUE"
+	}
+	buf.WriteString(" {" + answer)
+	if b := p.Behaviour; b != nil && (b.FeedbackOnWrong != "" || b.FeedbackOnCorrect != "") {
+		// GIFT lists the feedback for a wrong answer first.
+		buf.WriteString("#" + escape(b.FeedbackOnWrong) + "#" + escape(b.FeedbackOnCorrect))
+	}
+	buf.WriteString("}\n\n")
+}
+
+func writeQuestionText(buf *bytes.Buffer, title, text string) {
+	if title != "" {
+		buf.WriteString("::" + escape(title) + "::")
+	}
+	if strings.ContainsAny(text, "<>&") {
+		buf.WriteString("[html]")
+	}
+	buf.WriteString(escape(text))
+}
+
+// weight formats an answer weight percentage with the five decimals Moodle
+// uses, e.g. "33.33333" or "50".
+func weight(w float64) string {
+	s := strconv.FormatFloat(w, 'f', 5, 64)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
+
+var escaper = strings.NewReplacer(
+	`\`, `\\`, `~`, `\~`, `=`, `\=`, `#`, `\#`, `{`, `\{`, `}`, `\}`, `:`, `\:`,
+	"\r\n", `\n`, "\n", `\n`,
+)
+
+// escape backslash-escapes the characters GIFT treats as markup.
+func escape(s string) string {
+	return escaper.Replace(s)
+}
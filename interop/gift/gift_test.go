@@ -0,0 +1,208 @@
+package gift
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	h5p "github.com/grokify/h5p-go"
+	"github.com/grokify/h5p-go/schemas"
+)
+
+func testQuestionSet() *h5p.QuestionSet {
+	return &h5p.QuestionSet{
+		Title: "Geography",
+		Questions: []h5p.Question{
+			{
+				Library:  "H5P.MultiChoice 1.16",
+				Metadata: &h5p.ContentMetadata{Title: "Capitals"},
+				Params: &schemas.MultiChoiceParams{
+					Question: "<p>What is the capital of France?</p>",
+					Answers: []schemas.AnswerOption{
+						{Text: "Paris", Correct: true, TipsAndFeedback: &schemas.AnswerTipsAndFeedback{ChosenFeedback: "Right!"}},
+						{Text: "London"},
+					},
+				},
+			},
+			{
+				// Generic params as loaded from content.json.
+				Library: "H5P.MultiChoice 1.16",
+				Params: map[string]any{
+					"question": "Which are primes? 2+2=4",
+					"answers": []any{
+						map[string]any{"text": "2", "correct": true},
+						map[string]any{"text": "3", "correct": true},
+						map[string]any{"text": "5", "correct": true},
+						map[string]any{"text": "4", "correct": false},
+					},
+				},
+			},
+			{
+				Library: "H5P.TrueFalse 1.8",
+				Params: &schemas.TrueFalseParams{
+					Question:  "Rome is in Italy.",
+					Correct:   "true",
+					Behaviour: &schemas.TrueFalseBehaviour{FeedbackOnCorrect: "Yes", FeedbackOnWrong: "It is"},
+				},
+			},
+			{
+				Library: "H5P.TrueFalse 1.8",
+				Params:  map[string]any{"question": "Line one\nline two {braces}", "correct": "false"},
+			},
+		},
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	got, err := Marshal(testQuestionSet())
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	want := `// Geography
+
+::Capitals::[html]<p>What is the capital of France?</p> {
+	=Paris#Right!
+	~London
+}
+
+Which are primes? 2+2\=4 {
+	~%33.33333%2
+	~%33.33333%3
+	~%33.33333%5
+	~%-100%4
+}
+
+Rome is in Italy. {TRUE#It is#Yes}
+
+Line one\nline two \{braces\} {FALSE}
+
+`
+	if string(got) != want {
+		t.Errorf("Expected GIFT\n%s\ngot\n%s", want, got)
+	}
+}
+
+func TestWriteUnsupported(t *testing.T) {
+	qs := testQuestionSet()
+	qs.Questions = append(qs.Questions, h5p.Question{Library: "H5P.DragText 1.10", Params: map[string]any{}})
+
+	if _, err := Marshal(qs); !errors.Is(err, ErrUnsupportedQuestion) {
+		t.Errorf("Expected ErrUnsupportedQuestion, got %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, qs, Options{Category: "Geography/Europe", SkipUnsupported: true}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if want := "// Geography\n\n$CATEGORY: Geography/Europe\n\n"; !strings.HasPrefix(buf.String(), want) {
+		t.Errorf("Expected output to start with %q, got %q", want, buf.String())
+	}
+}
+
+func TestImportRoundTrip(t *testing.T) {
+	data, err := Marshal(testQuestionSet())
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	qs, err := Unmarshal(data)
+	if err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if qs.Title != "Geography" || len(qs.Questions) != 4 {
+		t.Fatalf("Expected 4 questions titled Geography, got %d titled %q", len(qs.Questions), qs.Title)
+	}
+
+	var mc schemas.MultiChoiceParams
+	if err := qs.Questions[0].DecodeParams(&mc); err != nil {
+		t.Fatal(err)
+	}
+	if qs.Questions[0].Metadata.Title != "Capitals" || mc.Question != "<p>What is the capital of France?</p>" {
+		t.Errorf("Unexpected first question %q: %q", qs.Questions[0].Metadata.Title, mc.Question)
+	}
+	if len(mc.Answers) != 2 || !mc.Answers[0].Correct || mc.Answers[1].Correct ||
+		mc.Answers[0].TipsAndFeedback == nil || mc.Answers[0].TipsAndFeedback.ChosenFeedback != "Right!" {
+		t.Errorf("Unexpected answers %+v", mc.Answers)
+	}
+
+	if err := qs.Questions[1].DecodeParams(&mc); err != nil {
+		t.Fatal(err)
+	}
+	if mc.Question != "Which are primes? 2+2=4" || mc.Behaviour == nil || mc.Behaviour.Type != "multi" {
+		t.Errorf("Unexpected multi-answer question %+v", mc)
+	}
+	if len(mc.Answers) != 4 || !mc.Answers[2].Correct || mc.Answers[3].Correct {
+		t.Errorf("Unexpected multi-answer answers %+v", mc.Answers)
+	}
+
+	var tf schemas.TrueFalseParams
+	if err := qs.Questions[2].DecodeParams(&tf); err != nil {
+		t.Fatal(err)
+	}
+	if !tf.IsTrue() || tf.Behaviour == nil || tf.Behaviour.FeedbackOnCorrect != "Yes" || tf.Behaviour.FeedbackOnWrong != "It is" {
+		t.Errorf("Unexpected true/false question %+v", tf)
+	}
+	if err := qs.Questions[3].DecodeParams(&tf); err != nil {
+		t.Fatal(err)
+	}
+	if tf.IsTrue() || tf.Question != "Line one\nline two {braces}" {
+		t.Errorf("Unexpected false question %+v", tf)
+	}
+}
+
+func TestImport(t *testing.T) {
+	src := `$CATEGORY: $course$/Science/Chemistry
+
+// short answer
+Water is H{=2 =two}O.
+
+::Essay::Describe the water cycle. {}
+
+What is 2 + 2? {#4}
+
+Match the symbols. {=H -> Hydrogen =O -> Oxygen}
+
+Salt & pepper? {~salt ~pepper =both}
+`
+	if _, err := Import(strings.NewReader(src), Options{}); !errors.Is(err, ErrUnsupportedQuestion) {
+		t.Errorf("Expected ErrUnsupportedQuestion, got %v", err)
+	}
+
+	qs, err := Import(strings.NewReader(src), Options{SkipUnsupported: true})
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if qs.Title != "Chemistry" || len(qs.Questions) != 3 {
+		t.Fatalf("Expected 3 questions titled Chemistry, got %d titled %q", len(qs.Questions), qs.Title)
+	}
+	if got := qs.Questions[0].MachineName(); got != BlanksLibrary {
+		t.Errorf("Expected %s, got %s", BlanksLibrary, got)
+	}
+	var blanks schemas.BlanksParams
+	if err := qs.Questions[0].DecodeParams(&blanks); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"Water is H *2/two* O."}; len(blanks.Questions) != 1 || blanks.Questions[0] != want[0] {
+		t.Errorf("Expected %q, got %q", want, blanks.Questions)
+	}
+
+	var essay schemas.EssayParams
+	if err := qs.Questions[1].DecodeParams(&essay); err != nil {
+		t.Fatal(err)
+	}
+	if qs.Questions[1].Metadata.Title != "Essay" || essay.TaskDescription != "Describe the water cycle." {
+		t.Errorf("Unexpected essay %q: %+v", qs.Questions[1].Metadata.Title, essay)
+	}
+
+	var mc schemas.MultiChoiceParams
+	if err := qs.Questions[2].DecodeParams(&mc); err != nil {
+		t.Fatal(err)
+	}
+	if mc.Question != "Salt &amp; pepper?" || len(mc.Answers) != 3 || !mc.Answers[2].Correct {
+		t.Errorf("Unexpected multiple choice %+v", mc)
+	}
+
+	if _, err := Unmarshal([]byte("Unclosed {=a")); !errors.Is(err, ErrSyntax) {
+		t.Errorf("Expected ErrSyntax, got %v", err)
+	}
+}
@@ -0,0 +1,341 @@
+package gift
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+
+	h5p "github.com/grokify/h5p-go"
+	"github.com/grokify/h5p-go/schemas"
+)
+
+// ErrSyntax reports GIFT text that cannot be parsed.
+var ErrSyntax = errors.New("invalid GIFT syntax")
+
+// Unmarshal parses GIFT text into a question set using default options.
+func Unmarshal(data []byte) (*h5p.QuestionSet, error) {
+	return Import(strings.NewReader(string(data)), Options{})
+}
+
+// Import reads GIFT text and converts it to a question set. Multiple choice
+// and true/false questions become H5P.MultiChoice and H5P.TrueFalse, short
+// answer questions H5P.Blanks and essay questions H5P.Essay. Matching and
+// numerical questions fail with ErrUnsupportedQuestion unless
+// opts.SkipUnsupported is set.
+//
+// The title is taken from the last segment of the last $CATEGORY, or else
+// from a comment before the first question as written by Write. Questions
+// get the newest library versions listed in h5p.LatestLibraryVersions.
+func Import(r io.Reader, opts Options) (*h5p.QuestionSet, error) {
+	qs := &h5p.QuestionSet{}
+	var comment, category string
+	var block []string
+	blockLine, n := 0, 0
+
+	flush := func() error {
+		if len(block) == 0 {
+			return nil
+		}
+		text := strings.Join(block, "\n")
+		block = nil
+		q, err := parseQuestion(text)
+		if errors.Is(err, ErrUnsupportedQuestion) && opts.SkipUnsupported {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("line %d: %w", blockLine, err)
+		}
+		qs.Questions = append(qs.Questions, *q)
+		return nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		n++
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if n == 1 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "//"):
+			if comment == "" && len(qs.Questions) == 0 && len(block) == 0 {
+				comment = strings.TrimSpace(strings.TrimPrefix(trimmed, "//"))
+			}
+		case strings.HasPrefix(trimmed, "$CATEGORY:") && len(block) == 0:
+			category = strings.TrimSpace(strings.TrimPrefix(trimmed, "$CATEGORY:"))
+		case trimmed == "":
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		default:
+			if len(block) == 0 {
+				blockLine = n
+			}
+			block = append(block, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	qs.Title = comment
+	if category != "" {
+		qs.Title = path.Base(category)
+	}
+	qs.EnsureSubContentIDs()
+	return qs, nil
+}
+
+// parseQuestion converts the GIFT text of a single question.
+func parseQuestion(s string) (*h5p.Question, error) {
+	var title string
+	if rest, ok := strings.CutPrefix(s, "::"); ok {
+		end := indexUnescaped(rest, "::")
+		if end < 0 {
+			return nil, fmt.Errorf("%w: title is not closed with ::", ErrSyntax)
+		}
+		title = strings.TrimSpace(unescape(rest[:end]))
+		s = strings.TrimLeft(rest[end+2:], " \t\n")
+	}
+
+	isHTML := false
+	if strings.HasPrefix(s, "[") {
+		if end := strings.Index(s, "]"); end > 0 {
+			isHTML = s[1:end] == "html"
+			s = s[end+1:]
+		}
+	}
+	text := func(raw string) string {
+		t := strings.TrimSpace(unescape(raw))
+		if isHTML {
+			return t
+		}
+		return html.EscapeString(t)
+	}
+
+	open := indexUnescaped(s, "{")
+	if open < 0 {
+		return nil, fmt.Errorf("%w: question has no answers in {}", ErrSyntax)
+	}
+	length := indexUnescaped(s[open+1:], "}")
+	if length < 0 {
+		return nil, fmt.Errorf("%w: answers are not closed with }", ErrSyntax)
+	}
+	before, body, after := s[:open], strings.TrimSpace(s[open+1:open+1+length]), s[open+2+length:]
+	// question returns the question text, with gap in place of the answers
+	// of a missing word question.
+	question := func(gap string) string {
+		if strings.TrimSpace(after) == "" {
+			return text(before)
+		}
+		return text(before) + " " + gap + " " + text(after)
+	}
+	if general := indexUnescaped(body, "####"); general >= 0 {
+		body = strings.TrimSpace(body[:general])
+	}
+
+	var machineName string
+	var params any
+	switch {
+	case body == "":
+		machineName = EssayLibrary
+		params = &schemas.EssayParams{TaskDescription: question("")}
+
+	case strings.HasPrefix(body, "#"):
+		return nil, fmt.Errorf("%w: numerical", ErrUnsupportedQuestion)
+
+	case indexUnescaped(body, "->") >= 0:
+		return nil, fmt.Errorf("%w: matching", ErrUnsupportedQuestion)
+
+	case isTrueFalse(body):
+		parts := splitUnescaped(body, "#")
+		p := &schemas.TrueFalseParams{Question: question("_____"), Correct: "false"}
+		if strings.HasPrefix(strings.ToUpper(strings.TrimSpace(parts[0])), "T") {
+			p.Correct = "true"
+		}
+		// GIFT lists the feedback for a wrong answer first.
+		var b schemas.TrueFalseBehaviour
+		if len(parts) > 1 {
+			b.FeedbackOnWrong = text(parts[1])
+		}
+		if len(parts) > 2 {
+			b.FeedbackOnCorrect = text(parts[2])
+		}
+		if b.FeedbackOnWrong != "" || b.FeedbackOnCorrect != "" {
+			p.Behaviour = &b
+		}
+		machineName, params = TrueFalseLibrary, p
+
+	default:
+		answers, err := parseAnswers(body)
+		if err != nil {
+			return nil, err
+		}
+		short := true
+		for _, a := range answers {
+			short = short && a.exact
+		}
+		if short {
+			var blank schemas.Blank
+			for _, a := range answers {
+				blank.Answers = append(blank.Answers, strings.TrimSpace(unescape(a.text)))
+			}
+			text := question(blank.String())
+			if strings.TrimSpace(after) == "" {
+				text += " " + blank.String()
+			}
+			machineName = BlanksLibrary
+			params = &schemas.BlanksParams{Questions: []string{text}}
+			break
+		}
+		p := &schemas.MultiChoiceParams{Question: question("_____")}
+		correct := 0
+		for _, a := range answers {
+			option := schemas.AnswerOption{Text: text(a.text), Correct: a.correct}
+			if a.feedback != "" {
+				option.TipsAndFeedback = &schemas.AnswerTipsAndFeedback{ChosenFeedback: text(a.feedback)}
+			}
+			if a.correct {
+				correct++
+			}
+			p.Answers = append(p.Answers, option)
+		}
+		if correct > 1 {
+			p.Behaviour = &schemas.Behaviour{Type: "multi"}
+		}
+		machineName, params = MultiChoiceLibrary, p
+	}
+
+	library, _ := h5p.LatestLibraryString(machineName)
+	return &h5p.Question{
+		Library:  library,
+		Params:   params,
+		Metadata: &h5p.ContentMetadata{Title: title, License: "U"},
+	}, nil
+}
+
+func isTrueFalse(body string) bool {
+	value, _, _ := strings.Cut(body, "#")
+	switch strings.ToUpper(strings.TrimSpace(value)) {
+	case "T", "TRUE", "F", "FALSE":
+		return true
+	}
+	return false
+}
+
+type answer struct {
+	text, feedback string
+	correct        bool
+	// exact is set for answers marked with =, all of which are accepted
+	// in short answer questions.
+	exact bool
+}
+
+// parseAnswers splits the answers of a multiple choice or short answer
+// question, each starting with = or ~ and optionally weighted as in
+// "~%50%".
+func parseAnswers(body string) ([]answer, error) {
+	var answers []answer
+	start := -1
+	add := func(end int) error {
+		if start < 0 {
+			if strings.TrimSpace(body[:end]) != "" {
+				return fmt.Errorf("%w: answer must start with = or ~", ErrSyntax)
+			}
+			return nil
+		}
+		raw := body[start+1 : end]
+		a := answer{exact: body[start] == '=', correct: body[start] == '='}
+		if weighted, ok := strings.CutPrefix(raw, "%"); ok {
+			w, rest, found := strings.Cut(weighted, "%")
+			f, err := strconv.ParseFloat(w, 64)
+			if !found || err != nil {
+				return fmt.Errorf("%w: invalid answer weight %q", ErrSyntax, w)
+			}
+			a.correct, a.exact, raw = f > 0, a.exact && f == 100, rest
+		}
+		parts := splitUnescaped(raw, "#")
+		a.text = parts[0]
+		if len(parts) > 1 {
+			a.feedback = strings.Join(parts[1:], "#")
+		}
+		answers = append(answers, a)
+		return nil
+	}
+	for i := 0; i < len(body); i++ {
+		switch body[i] {
+		case '\\':
+			i++
+		case '=', '~':
+			if err := add(i); err != nil {
+				return nil, err
+			}
+			start = i
+		}
+	}
+	if err := add(len(body)); err != nil {
+		return nil, err
+	}
+	if len(answers) == 0 {
+		return nil, fmt.Errorf("%w: question has no answers", ErrSyntax)
+	}
+	return answers, nil
+}
+
+// indexUnescaped returns the index of the first sep in s that is not
+// preceded by a backslash, or -1.
+func indexUnescaped(s, sep string) int {
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' {
+			i++
+			continue
+		}
+		if strings.HasPrefix(s[i:], sep) {
+			return i
+		}
+	}
+	return -1
+}
+
+// splitUnescaped splits s around each sep not preceded by a backslash.
+func splitUnescaped(s, sep string) []string {
+	var parts []string
+	for {
+		i := indexUnescaped(s, sep)
+		if i < 0 {
+			return append(parts, s)
+		}
+		parts = append(parts, s[:i])
+		s = s[i+len(sep):]
+	}
+}
+
+// unescape reverses escape: \n becomes a line break and any other escaped
+// character stands for itself.
+func unescape(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+			if s[i] == 'n' {
+				b.WriteByte('\n')
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
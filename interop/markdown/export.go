@@ -0,0 +1,104 @@
+package markdown
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	h5p "github.com/grokify/h5p-go"
+	"github.com/grokify/h5p-go/schemas"
+)
+
+// Marshal returns the Markdown text for qs using default options.
+func Marshal(qs *h5p.QuestionSet) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := Write(&buf, qs, Options{}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Write writes the MultiChoice and TrueFalse questions of qs to w as
+// Markdown. Questions are headed by their metadata title, or "Question n"
+// when they have none.
+func Write(w io.Writer, qs *h5p.QuestionSet, opts Options) error {
+	var buf bytes.Buffer
+	if qs.Title != "" {
+		fmt.Fprintf(&buf, "# %s\n\n", heading(qs.Title))
+	}
+
+	n := 0
+	for i := range qs.Questions {
+		q := &qs.Questions[i]
+		var err error
+		var question string
+		var answers []answer
+		switch q.MachineName() {
+		case MultiChoiceLibrary:
+			var p schemas.MultiChoiceParams
+			if err = q.DecodeParams(&p); err == nil {
+				question = p.Question
+				for _, a := range p.Answers {
+					ans := answer{text: a.Text, correct: a.Correct}
+					if a.TipsAndFeedback != nil {
+						ans.feedback = a.TipsAndFeedback.ChosenFeedback
+					}
+					answers = append(answers, ans)
+				}
+			}
+		case TrueFalseLibrary:
+			var p schemas.TrueFalseParams
+			if err = q.DecodeParams(&p); err == nil {
+				question = p.Question
+				answers = []answer{{text: "True", correct: p.IsTrue()}, {text: "False", correct: !p.IsTrue()}}
+				if b := p.Behaviour; b != nil {
+					// The feedback is listed with the answer that shows it.
+					answers[0].feedback, answers[1].feedback = b.FeedbackOnWrong, b.FeedbackOnCorrect
+					if p.IsTrue() {
+						answers[0].feedback, answers[1].feedback = b.FeedbackOnCorrect, b.FeedbackOnWrong
+					}
+				}
+			}
+		default:
+			if opts.SkipUnsupported {
+				continue
+			}
+			err = fmt.Errorf("%w: %s", ErrUnsupportedQuestion, q.Library)
+		}
+		if err != nil {
+			return fmt.Errorf("question %d: %w", i, err)
+		}
+
+		n++
+		title := fmt.Sprintf("Question %d", n)
+		if q.Metadata != nil && strings.TrimSpace(q.Metadata.Title) != "" {
+			title = q.Metadata.Title
+		}
+		fmt.Fprintf(&buf, "## %s\n\n", heading(title))
+		if text := toMarkdown(question, false); text != "" {
+			buf.WriteString(text + "\n\n")
+		}
+		for _, a := range answers {
+			check := " "
+			if a.correct {
+				check = "x"
+			}
+			fmt.Fprintf(&buf, "- [%s] %s\n", check, toMarkdown(a.text, true))
+			if fb := toMarkdown(a.feedback, true); fb != "" {
+				fmt.Fprintf(&buf, "  > %s\n", fb)
+			}
+		}
+		buf.WriteString("\n")
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// heading returns s on a single line with the characters that would end
+// or format a heading escaped.
+func heading(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	return strings.NewReplacer(`\`, `\\`, `#`, `\#`, `*`, `\*`, `_`, `\_`).Replace(s)
+}
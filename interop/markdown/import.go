@@ -0,0 +1,200 @@
+package markdown
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	h5p "github.com/grokify/h5p-go"
+	"github.com/grokify/h5p-go/schemas"
+)
+
+var (
+	answerLine   = regexp.MustCompile(`^\s*[-*+]\s+\[([ xX])\]\s*(.*)$`)
+	feedbackLine = regexp.MustCompile(`^\s+>\s?(.*)$`)
+)
+
+// answer is a task list item of a question in Markdown form.
+type answer struct {
+	text, feedback string
+	correct        bool
+}
+
+// question collects the lines of a question while it is read.
+type question struct {
+	line    int
+	title   string
+	text    []string
+	answers []answer
+}
+
+// Unmarshal parses Markdown into a question set using default options.
+func Unmarshal(data []byte) (*h5p.QuestionSet, error) {
+	return Import(strings.NewReader(string(data)), Options{})
+}
+
+// Import reads a Markdown quiz as described in the package documentation
+// and converts it to a question set. Text before the first question other
+// than the title is ignored. Questions get the newest library versions
+// listed in h5p.LatestLibraryVersions.
+func Import(r io.Reader, opts Options) (*h5p.QuestionSet, error) {
+	qs := &h5p.QuestionSet{}
+	var q *question
+
+	flush := func() error {
+		if q == nil {
+			return nil
+		}
+		converted, err := q.toQuestion()
+		q = nil
+		if errors.Is(err, ErrInvalidQuestion) && opts.SkipUnsupported {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		qs.Questions = append(qs.Questions, *converted)
+		return nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	n := 0
+	for scanner.Scan() {
+		n++
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if n == 1 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+		switch {
+		case strings.HasPrefix(line, "## "):
+			if err := flush(); err != nil {
+				return nil, err
+			}
+			q = &question{line: n, title: toPlain(strings.TrimSpace(line[3:]))}
+		case strings.HasPrefix(line, "# "):
+			if q == nil && qs.Title == "" {
+				qs.Title = toPlain(strings.TrimSpace(line[2:]))
+			}
+		case q == nil:
+			// Introduction before the first question.
+		case answerLine.MatchString(line):
+			m := answerLine.FindStringSubmatch(line)
+			q.answers = append(q.answers, answer{text: m[2], correct: m[1] != " "})
+		case len(q.answers) > 0 && feedbackLine.MatchString(line):
+			a := &q.answers[len(q.answers)-1]
+			a.feedback = strings.TrimSpace(a.feedback + " " + feedbackLine.FindStringSubmatch(line)[1])
+		case len(q.answers) > 0 && strings.TrimSpace(line) != "":
+			return nil, fmt.Errorf("line %d: %w: text after the answers of %q", n, ErrInvalidQuestion, q.title)
+		case len(q.answers) == 0:
+			q.text = append(q.text, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	qs.EnsureSubContentIDs()
+	return qs, nil
+}
+
+// toQuestion converts q to a TrueFalse question if its answers are True
+// and False, and to a MultiChoice question otherwise.
+func (q *question) toQuestion() (*h5p.Question, error) {
+	if len(q.answers) == 0 {
+		return nil, fmt.Errorf("line %d: %w: %q has no answers", q.line, ErrInvalidQuestion, q.title)
+	}
+	correct := 0
+	for _, a := range q.answers {
+		if a.correct {
+			correct++
+		}
+	}
+	if correct == 0 {
+		return nil, fmt.Errorf("line %d: %w: %q has no correct answer", q.line, ErrInvalidQuestion, q.title)
+	}
+
+	text := paragraphs(q.text)
+	var machineName string
+	var params any
+	if value, ok := q.trueFalse(); ok && correct == 1 {
+		p := &schemas.TrueFalseParams{Question: text, Correct: "false"}
+		var b schemas.TrueFalseBehaviour
+		for _, a := range q.answers {
+			if a.correct {
+				p.Correct = value(a)
+				b.FeedbackOnCorrect = toHTML(a.feedback)
+			} else {
+				b.FeedbackOnWrong = toHTML(a.feedback)
+			}
+		}
+		if b.FeedbackOnCorrect != "" || b.FeedbackOnWrong != "" {
+			p.Behaviour = &b
+		}
+		machineName, params = TrueFalseLibrary, p
+	} else {
+		p := &schemas.MultiChoiceParams{Question: text}
+		for _, a := range q.answers {
+			option := schemas.AnswerOption{Text: toHTML(a.text), Correct: a.correct}
+			if a.feedback != "" {
+				option.TipsAndFeedback = &schemas.AnswerTipsAndFeedback{ChosenFeedback: toHTML(a.feedback)}
+			}
+			p.Answers = append(p.Answers, option)
+		}
+		if correct > 1 {
+			p.Behaviour = &schemas.Behaviour{Type: "multi"}
+		}
+		machineName, params = MultiChoiceLibrary, p
+	}
+
+	library, _ := h5p.LatestLibraryString(machineName)
+	return &h5p.Question{
+		Library:  library,
+		Params:   params,
+		Metadata: &h5p.ContentMetadata{Title: q.title, License: "U"},
+	}, nil
+}
+
+// trueFalse reports whether the answers of q are True and False, returning
+// a function giving the TrueFalse correct value of an answer.
+func (q *question) trueFalse() (func(answer) string, bool) {
+	value := func(a answer) string { return strings.ToLower(strings.TrimSpace(a.text)) }
+	if len(q.answers) != 2 {
+		return nil, false
+	}
+	first, second := value(q.answers[0]), value(q.answers[1])
+	if first == "true" && second == "false" || first == "false" && second == "true" {
+		return value, true
+	}
+	return nil, false
+}
+
+// paragraphs converts the question text lines to HTML paragraphs.
+func paragraphs(lines []string) string {
+	var b strings.Builder
+	var para []string
+	flush := func() {
+		if len(para) > 0 {
+			b.WriteString("<p>" + toHTML(strings.Join(para, "\n")) + "</p>")
+			para = nil
+		}
+	}
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			flush()
+			continue
+		}
+		// Two trailing spaces mark a hard line break like a backslash.
+		if strings.HasSuffix(line, "  ") {
+			line = strings.TrimSpace(line) + `\`
+		}
+		para = append(para, strings.TrimSpace(line))
+	}
+	flush()
+	return b.String()
+}
@@ -0,0 +1,173 @@
+// Package markdown converts between H5P question sets and quizzes written
+// in Markdown, a format easy to keep in source control and review:
+//
+//	# Geography
+//
+//	## Capitals
+//
+//	What is the **capital** of France?
+//
+//	- [x] Paris
+//	  > Right!
+//	- [ ] London
+//
+// Each level 2 heading starts a question and names it; the level 1 heading
+// is the title of the set. The question text is followed by task list items
+// for the answers, checked for the correct ones, each optionally followed
+// by an indented quote with the feedback shown when it is chosen. A
+// question whose answers are True and False is a true/false question.
+//
+// Emphasis, strong emphasis, code spans and hard line breaks are converted
+// to and from HTML; other markup in H5P text is dropped on export.
+package markdown
+
+import (
+	"errors"
+	"html"
+	"regexp"
+	"strings"
+)
+
+// Machine names of the supported question libraries.
+const (
+	MultiChoiceLibrary = "H5P.MultiChoice"
+	TrueFalseLibrary   = "H5P.TrueFalse"
+)
+
+var (
+	ErrUnsupportedQuestion = errors.New("question type is not supported by Markdown conversion")
+	ErrInvalidQuestion     = errors.New("invalid question")
+)
+
+// Options controls Markdown conversion in both directions.
+type Options struct {
+	// SkipUnsupported omits questions other than MultiChoice and TrueFalse
+	// on export, and questions without answers or without a correct answer
+	// on import, instead of failing.
+	SkipUnsupported bool
+}
+
+var (
+	htmlTag    = regexp.MustCompile(`</?([a-zA-Z][a-zA-Z0-9]*)[^>]*>`)
+	blankLines = regexp.MustCompile(`\n{3,}`)
+	blockStart = regexp.MustCompile(`^(#|>|[-+*] |\d+\. )`)
+
+	codeSpan = regexp.MustCompile("`([^`]+)`")
+	strong   = regexp.MustCompile(`\*\*(.+?)\*\*|__(.+?)__`)
+	emphasis = regexp.MustCompile(`\*([^*]+)\*|\b_([^_]+)_\b`)
+)
+
+// toMarkdown converts H5P HTML text to Markdown. With inline set, the
+// result is kept on a single line, as needed for answers.
+func toMarkdown(s string, inline bool) string {
+	var b strings.Builder
+	last := 0
+	for _, m := range htmlTag.FindAllStringSubmatchIndex(s, -1) {
+		b.WriteString(escape(html.UnescapeString(s[last:m[0]])))
+		last = m[1]
+		switch strings.ToLower(s[m[2]:m[3]]) {
+		case "p", "div", "ul", "ol", "li", "h1", "h2", "h3", "h4", "h5", "h6", "blockquote":
+			b.WriteString("\n\n")
+		case "br":
+			b.WriteString("\\\n")
+		case "strong", "b":
+			b.WriteString("**")
+		case "em", "i":
+			b.WriteString("_")
+		case "code":
+			b.WriteString("`")
+		}
+	}
+	b.WriteString(escape(html.UnescapeString(s[last:])))
+
+	text := strings.TrimSpace(blankLines.ReplaceAllString(b.String(), "\n\n"))
+	if inline {
+		return strings.Join(strings.Fields(strings.ReplaceAll(text, "\\\n", " ")), " ")
+	}
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if blockStart.MatchString(line) {
+			line = `\` + line
+		}
+		lines[i] = line
+	}
+	return strings.Join(lines, "\n")
+}
+
+var markdownEscaper = strings.NewReplacer(`\`, `\\`, `*`, `\*`, `_`, `\_`, "`", "\\`", "\n", " ")
+
+// escape backslash-escapes the characters Markdown treats as inline
+// markup.
+func escape(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+// escapeBase maps backslash-escaped punctuation to private use characters
+// so inline markup patterns do not match it; breakMark stands for a hard
+// line break until the text is HTML-escaped.
+const (
+	escapeBase = rune(0xE000)
+	breakMark  = rune(0xE0FF)
+)
+
+// toHTML converts a Markdown paragraph or answer to HTML.
+func toHTML(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == '\\' && i+1 < len(s) && isPunct(s[i+1]) {
+			i++
+			b.WriteRune(escapeBase + rune(s[i]))
+			continue
+		}
+		if c == '\\' && i+1 < len(s) && s[i+1] == '\n' {
+			i++
+			b.WriteRune(breakMark)
+			continue
+		}
+		b.WriteByte(c)
+	}
+	out := strings.ReplaceAll(escapeHTML(b.String()), string(breakMark), "<br>")
+	out = codeSpan.ReplaceAllString(out, "<code>$1</code>")
+	out = strong.ReplaceAllString(out, "<strong>$1$2</strong>")
+	out = emphasis.ReplaceAllString(out, "<em>$1$2</em>")
+	return strings.Map(func(r rune) rune {
+		if r >= escapeBase && r < escapeBase+128 {
+			return r - escapeBase
+		}
+		return r
+	}, restoreEscaped(out))
+}
+
+// restoreEscaped HTML-escapes the escaped punctuation that needs it; the
+// rest is mapped back by toHTML.
+var restoreEscaped = strings.NewReplacer(
+	string(rune(escapeBase+'<')), "&lt;",
+	string(rune(escapeBase+'>')), "&gt;",
+	string(rune(escapeBase+'&')), "&amp;",
+).Replace
+
+var htmlEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// escapeHTML escapes the characters that start HTML markup, keeping quotes
+// readable.
+func escapeHTML(s string) string {
+	return htmlEscaper.Replace(s)
+}
+
+// toPlain removes the backslash escapes of s, for headings.
+func toPlain(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) && isPunct(s[i+1]) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+func isPunct(c byte) bool {
+	return strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", c) >= 0
+}
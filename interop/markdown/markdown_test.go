@@ -0,0 +1,168 @@
+package markdown
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	h5p "github.com/grokify/h5p-go"
+	"github.com/grokify/h5p-go/schemas"
+)
+
+func testQuestionSet() *h5p.QuestionSet {
+	return &h5p.QuestionSet{
+		Title: "Geography",
+		Questions: []h5p.Question{
+			{
+				Library:  "H5P.MultiChoice 1.16",
+				Metadata: &h5p.ContentMetadata{Title: "Capitals"},
+				Params: &schemas.MultiChoiceParams{
+					Question: "<p>What is the <strong>capital</strong> of France?</p><p>Pick one.<br>Only one.</p>",
+					Answers: []schemas.AnswerOption{
+						{Text: "Paris", Correct: true, TipsAndFeedback: &schemas.AnswerTipsAndFeedback{ChosenFeedback: "Right!"}},
+						{Text: "Lyon &amp; <em>Nice</em>"},
+					},
+				},
+			},
+			{
+				// Generic params as loaded from content.json.
+				Library: "H5P.MultiChoice 1.16",
+				Params: map[string]any{
+					"question": "Which are primes? 2*3 is not.",
+					"answers": []any{
+						map[string]any{"text": "2", "correct": true},
+						map[string]any{"text": "3", "correct": true},
+						map[string]any{"text": "4", "correct": false},
+					},
+				},
+			},
+			{
+				Library: "H5P.TrueFalse 1.8",
+				Params: &schemas.TrueFalseParams{
+					Question:  "# Rome is in Italy.",
+					Correct:   "true",
+					Behaviour: &schemas.TrueFalseBehaviour{FeedbackOnCorrect: "Yes", FeedbackOnWrong: "It is"},
+				},
+			},
+		},
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	got, err := Marshal(testQuestionSet())
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	want := `# Geography
+
+## Capitals
+
+What is the **capital** of France?
+
+Pick one.\
+Only one.
+
+- [x] Paris
+  > Right!
+- [ ] Lyon & _Nice_
+
+## Question 2
+
+Which are primes? 2\*3 is not.
+
+- [x] 2
+- [x] 3
+- [ ] 4
+
+## Question 3
+
+\# Rome is in Italy.
+
+- [x] True
+  > Yes
+- [ ] False
+  > It is
+
+`
+	if string(got) != want {
+		t.Errorf("Expected Markdown\n%s\ngot\n%s", want, got)
+	}
+
+	qs := testQuestionSet()
+	qs.Questions = append(qs.Questions, h5p.Question{Library: "H5P.DragText 1.10", Params: map[string]any{}})
+	if _, err := Marshal(qs); !errors.Is(err, ErrUnsupportedQuestion) {
+		t.Errorf("Expected ErrUnsupportedQuestion, got %v", err)
+	}
+}
+
+func TestImportRoundTrip(t *testing.T) {
+	data, err := Marshal(testQuestionSet())
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	qs, err := Unmarshal(data)
+	if err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if qs.Title != "Geography" || len(qs.Questions) != 3 {
+		t.Fatalf("Expected 3 questions titled Geography, got %d titled %q", len(qs.Questions), qs.Title)
+	}
+
+	var mc schemas.MultiChoiceParams
+	if err := qs.Questions[0].DecodeParams(&mc); err != nil {
+		t.Fatal(err)
+	}
+	want := schemas.MultiChoiceParams{
+		Question: "<p>What is the <strong>capital</strong> of France?</p><p>Pick one.<br>Only one.</p>",
+		Answers: []schemas.AnswerOption{
+			{Text: "Paris", Correct: true, TipsAndFeedback: &schemas.AnswerTipsAndFeedback{ChosenFeedback: "Right!"}},
+			{Text: "Lyon &amp; <em>Nice</em>"},
+		},
+	}
+	if qs.Questions[0].Metadata.Title != "Capitals" || !reflect.DeepEqual(mc, want) {
+		t.Errorf("Expected %+v, got %+v", want, mc)
+	}
+
+	if err := qs.Questions[1].DecodeParams(&mc); err != nil {
+		t.Fatal(err)
+	}
+	if mc.Question != "<p>Which are primes? 2*3 is not.</p>" || mc.Behaviour == nil || mc.Behaviour.Type != "multi" {
+		t.Errorf("Unexpected multi-answer question %+v", mc)
+	}
+
+	var tf schemas.TrueFalseParams
+	if err := qs.Questions[2].DecodeParams(&tf); err != nil {
+		t.Fatal(err)
+	}
+	if tf.Question != "<p># Rome is in Italy.</p>" || !tf.IsTrue() ||
+		tf.Behaviour == nil || tf.Behaviour.FeedbackOnCorrect != "Yes" || tf.Behaviour.FeedbackOnWrong != "It is" {
+		t.Errorf("Unexpected true/false question %+v", tf)
+	}
+}
+
+func TestImportErrors(t *testing.T) {
+	src := `Intro text is ignored.
+
+## No answers
+
+Just text.
+
+## Fine
+
+- [ ] a
+- [X] b
+`
+	if _, err := Unmarshal([]byte(src)); !errors.Is(err, ErrInvalidQuestion) {
+		t.Errorf("Expected ErrInvalidQuestion, got %v", err)
+	}
+	qs, err := Import(strings.NewReader(src), Options{SkipUnsupported: true})
+	if err != nil || len(qs.Questions) != 1 || qs.Questions[0].Metadata.Title != "Fine" {
+		t.Fatalf("Expected only the valid question, got %+v, %v", qs, err)
+	}
+
+	src = "## Trailing\n\n- [x] a\n\nMore text\n"
+	if _, err := Unmarshal([]byte(src)); err == nil || !strings.Contains(err.Error(), "line 5") {
+		t.Errorf("Expected error at line 5, got %v", err)
+	}
+}
@@ -0,0 +1,217 @@
+package moodlexml
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	h5p "github.com/grokify/h5p-go"
+	"github.com/grokify/h5p-go/schemas"
+)
+
+// marker matches a Blanks or DragText *answer:tip* marker.
+var marker = regexp.MustCompile(`\*([^*:]+)(:[^*]*)?\*`)
+
+// BlankText replaces blanks and dragged words in exported question text.
+const BlankText = "_____"
+
+// Export converts qs and writes it as Moodle question XML.
+func Export(w io.Writer, qs *h5p.QuestionSet, opts Options) error {
+	quiz, err := FromQuestionSet(qs, opts)
+	if err != nil {
+		return err
+	}
+	return quiz.Write(w)
+}
+
+// FromQuestionSet converts the MultiChoice, TrueFalse, Blanks, DragText and
+// Essay questions of qs to Moodle questions. Each Blanks sentence becomes a
+// shortanswer question and must contain exactly one blank.
+func FromQuestionSet(qs *h5p.QuestionSet, opts Options) (*Quiz, error) {
+	quiz := &Quiz{}
+	if opts.Category != "" {
+		quiz.Questions = append(quiz.Questions, Question{Type: TypeCategory, Category: &Text{Text: opts.Category}})
+	}
+	for i := range qs.Questions {
+		q := &qs.Questions[i]
+		mqs, err := fromQuestion(q)
+		if err != nil {
+			if opts.SkipUnsupported {
+				continue
+			}
+			return nil, fmt.Errorf("question %d: %w", i, err)
+		}
+		name := "Question " + strconv.Itoa(i+1)
+		if q.Metadata != nil && q.Metadata.Title != "" {
+			name = q.Metadata.Title
+		}
+		for j := range mqs {
+			mqs[j].Name = &Text{Text: name}
+			if len(mqs) > 1 {
+				mqs[j].Name.Text += fmt.Sprintf(" (%d)", j+1)
+			}
+		}
+		quiz.Questions = append(quiz.Questions, mqs...)
+	}
+	return quiz, nil
+}
+
+func fromQuestion(q *h5p.Question) ([]Question, error) {
+	switch q.MachineName() {
+	case MultiChoiceLibrary:
+		var p schemas.MultiChoiceParams
+		if err := q.DecodeParams(&p); err != nil {
+			return nil, err
+		}
+		return []Question{fromMultiChoice(&p)}, nil
+	case TrueFalseLibrary:
+		var p schemas.TrueFalseParams
+		if err := q.DecodeParams(&p); err != nil {
+			return nil, err
+		}
+		return []Question{fromTrueFalse(&p)}, nil
+	case BlanksLibrary:
+		var p schemas.BlanksParams
+		if err := q.DecodeParams(&p); err != nil {
+			return nil, err
+		}
+		return fromBlanks(&p)
+	case DragTextLibrary:
+		var p schemas.DragTextParams
+		if err := q.DecodeParams(&p); err != nil {
+			return nil, err
+		}
+		return []Question{fromDragText(&p)}, nil
+	case EssayLibrary:
+		var p schemas.EssayParams
+		if err := q.DecodeParams(&p); err != nil {
+			return nil, err
+		}
+		return []Question{fromEssay(&p)}, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedQuestion, q.Library)
+}
+
+func htmlText(s string) *Text {
+	return &Text{Format: FormatHTML, Text: s}
+}
+
+func fromMultiChoice(p *schemas.MultiChoiceParams) Question {
+	correct := 0
+	for _, a := range p.Answers {
+		if a.Correct {
+			correct++
+		}
+	}
+	mq := Question{
+		Type:         TypeMultiChoice,
+		QuestionText: htmlText(p.Question),
+		Single:       boolString(correct <= 1),
+	}
+	if p.Behaviour != nil {
+		mq.ShuffleAnswers = boolString(p.Behaviour.RandomAnswers)
+	}
+	for _, a := range p.Answers {
+		fraction := "0"
+		switch {
+		case a.Correct:
+			fraction = formatFraction(100 / float64(correct))
+		case correct > 1:
+			fraction = "-100"
+		}
+		answer := Answer{Fraction: fraction, Format: FormatHTML, Text: a.Text}
+		if a.TipsAndFeedback != nil && a.TipsAndFeedback.ChosenFeedback != "" {
+			answer.Feedback = htmlText(a.TipsAndFeedback.ChosenFeedback)
+		}
+		mq.Answers = append(mq.Answers, answer)
+	}
+	return mq
+}
+
+func fromTrueFalse(p *schemas.TrueFalseParams) Question {
+	var onCorrect, onWrong string
+	if p.Behaviour != nil {
+		onCorrect, onWrong = p.Behaviour.FeedbackOnCorrect, p.Behaviour.FeedbackOnWrong
+	}
+	answer := func(value bool) Answer {
+		a := Answer{Fraction: "0", Format: FormatMoodle, Text: boolString(value)}
+		fb := onWrong
+		if value == p.IsTrue() {
+			a.Fraction, fb = "100", onCorrect
+		}
+		if fb != "" {
+			a.Feedback = htmlText(fb)
+		}
+		return a
+	}
+	return Question{
+		Type:         TypeTrueFalse,
+		QuestionText: htmlText(p.Question),
+		Answers:      []Answer{answer(true), answer(false)},
+	}
+}
+
+func fromBlanks(p *schemas.BlanksParams) ([]Question, error) {
+	// H5P Blanks defaults to case sensitive answers.
+	caseSensitive := p.Behaviour == nil || p.Behaviour.CaseSensitive
+	useCase := "0"
+	if caseSensitive {
+		useCase = "1"
+	}
+	var mqs []Question
+	for i, sentence := range p.Questions {
+		blanks := schemas.ParseBlanks(sentence)
+		if len(blanks) != 1 {
+			return nil, fmt.Errorf("%w: Blanks sentence %d has %d blanks, shortanswer supports one",
+				ErrUnsupportedQuestion, i, len(blanks))
+		}
+		mq := Question{
+			Type:         TypeShortAnswer,
+			QuestionText: htmlText(p.Text + marker.ReplaceAllString(sentence, BlankText)),
+			UseCase:      useCase,
+		}
+		for _, a := range blanks[0].Answers {
+			mq.Answers = append(mq.Answers, Answer{Fraction: "100", Format: FormatMoodle, Text: a})
+		}
+		mqs = append(mqs, mq)
+	}
+	return mqs, nil
+}
+
+// fromDragText turns each dragged word into a matching pair whose question
+// is its line with the word blanked out and other words filled in.
+func fromDragText(p *schemas.DragTextParams) Question {
+	mq := Question{
+		Type:           TypeMatching,
+		QuestionText:   htmlText(p.TaskDescription),
+		ShuffleAnswers: "true",
+	}
+	for _, line := range strings.Split(p.TextField, "\n") {
+		for _, m := range marker.FindAllStringSubmatchIndex(line, -1) {
+			text := marker.ReplaceAllString(line[:m[0]], "$1") + BlankText + marker.ReplaceAllString(line[m[1]:], "$1")
+			mq.SubQuestions = append(mq.SubQuestions, SubQuestion{
+				Format: FormatHTML,
+				Text:   strings.TrimSpace(text),
+				Answer: Text{Text: strings.TrimSpace(line[m[2]:m[3]])},
+			})
+		}
+	}
+	return mq
+}
+
+func fromEssay(p *schemas.EssayParams) Question {
+	mq := Question{
+		Type:           TypeEssay,
+		QuestionText:   htmlText(p.TaskDescription),
+		ResponseFormat: "editor",
+	}
+	if p.Solution != nil && p.Solution.Sample != "" {
+		mq.GraderInfo = htmlText(p.Solution.Sample)
+	}
+	if p.PlaceholderText != "" {
+		mq.ResponseTemplate = htmlText(p.PlaceholderText)
+	}
+	return mq
+}
@@ -0,0 +1,180 @@
+package moodlexml
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+
+	h5p "github.com/grokify/h5p-go"
+	"github.com/grokify/h5p-go/schemas"
+)
+
+// Machine names of the H5P libraries questions are converted to.
+const (
+	MultiChoiceLibrary = "H5P.MultiChoice"
+	TrueFalseLibrary   = "H5P.TrueFalse"
+	BlanksLibrary      = "H5P.Blanks"
+	DragTextLibrary    = "H5P.DragText"
+	EssayLibrary       = "H5P.Essay"
+)
+
+var (
+	underscoreBlank = regexp.MustCompile(`_{3,}`)
+	htmlTag         = regexp.MustCompile(`<[^>]*>`)
+)
+
+// Import reads Moodle question XML and converts it to a question set.
+func Import(r io.Reader, opts Options) (*h5p.QuestionSet, error) {
+	quiz, err := Read(r)
+	if err != nil {
+		return nil, err
+	}
+	return quiz.ToQuestionSet(opts)
+}
+
+// ToQuestionSet converts the quiz questions to H5P questions. The title is
+// taken from the last segment of the last category, if any. Questions get
+// the newest library versions listed in h5p.LatestLibraryVersions.
+func (quiz *Quiz) ToQuestionSet(opts Options) (*h5p.QuestionSet, error) {
+	qs := &h5p.QuestionSet{}
+	for i, mq := range quiz.Questions {
+		if mq.Type == TypeCategory {
+			if category := strings.TrimSpace(mq.Category.text()); category != "" {
+				qs.Title = path.Base(category)
+			}
+			continue
+		}
+		machineName, params, err := mq.toParams()
+		if err != nil {
+			if opts.SkipUnsupported {
+				continue
+			}
+			return nil, fmt.Errorf("question %d: %w", i, err)
+		}
+		library, _ := h5p.LatestLibraryString(machineName)
+		qs.Questions = append(qs.Questions, h5p.Question{
+			Library: library,
+			Params:  params,
+			Metadata: &h5p.ContentMetadata{
+				Title:   strings.TrimSpace(mq.Name.text()),
+				License: "U",
+			},
+		})
+	}
+	qs.EnsureSubContentIDs()
+	return qs, nil
+}
+
+// toParams returns the H5P library machine name and typed params for mq.
+func (mq *Question) toParams() (string, any, error) {
+	switch mq.Type {
+	case TypeMultiChoice:
+		return MultiChoiceLibrary, mq.multiChoiceParams(), nil
+	case TypeTrueFalse:
+		return TrueFalseLibrary, mq.trueFalseParams(), nil
+	case TypeShortAnswer:
+		return BlanksLibrary, mq.blanksParams(), nil
+	case TypeMatching:
+		return DragTextLibrary, mq.dragTextParams(), nil
+	case TypeEssay:
+		return EssayLibrary, mq.essayParams(), nil
+	}
+	return "", nil, fmt.Errorf("%w: %s", ErrUnsupportedQuestion, mq.Type)
+}
+
+func (mq *Question) multiChoiceParams() *schemas.MultiChoiceParams {
+	typ := "multi"
+	if isTrue(mq.Single) {
+		typ = "single"
+	}
+	p := &schemas.MultiChoiceParams{
+		Question:  mq.QuestionText.text(),
+		Behaviour: &schemas.Behaviour{Type: typ, RandomAnswers: isTrue(mq.ShuffleAnswers)},
+	}
+	for _, a := range mq.Answers {
+		answer := schemas.AnswerOption{Text: a.Text, Correct: a.fractionValue() > 0}
+		if fb := a.Feedback.text(); fb != "" {
+			answer.TipsAndFeedback = &schemas.AnswerTipsAndFeedback{ChosenFeedback: fb}
+		}
+		p.Answers = append(p.Answers, answer)
+	}
+	return p
+}
+
+func (mq *Question) trueFalseParams() *schemas.TrueFalseParams {
+	p := &schemas.TrueFalseParams{Question: mq.QuestionText.text(), Correct: "false"}
+	var b schemas.TrueFalseBehaviour
+	for _, a := range mq.Answers {
+		if a.fractionValue() > 0 {
+			if isTrue(a.Text) {
+				p.Correct = "true"
+			}
+			b.FeedbackOnCorrect = a.Feedback.text()
+		} else {
+			b.FeedbackOnWrong = a.Feedback.text()
+		}
+	}
+	if b.FeedbackOnCorrect != "" || b.FeedbackOnWrong != "" {
+		p.Behaviour = &b
+	}
+	return p
+}
+
+// blanksParams places the accepted answers in a blank replacing the first
+// run of underscores in the question text, or at its end.
+func (mq *Question) blanksParams() *schemas.BlanksParams {
+	var blank schemas.Blank
+	for _, a := range mq.Answers {
+		if a.fractionValue() > 0 {
+			blank.Answers = append(blank.Answers, strings.TrimSpace(a.Text))
+		}
+	}
+	text := mq.QuestionText.text()
+	if loc := underscoreBlank.FindStringIndex(text); loc != nil {
+		text = text[:loc[0]] + blank.String() + text[loc[1]:]
+	} else if trimmed := strings.TrimRight(text, " \n"); strings.HasSuffix(trimmed, "</p>") {
+		text = strings.TrimSuffix(trimmed, "</p>") + " " + blank.String() + "</p>"
+	} else {
+		text = trimmed + " " + blank.String()
+	}
+	return &schemas.BlanksParams{
+		Questions: []string{text},
+		Behaviour: &schemas.BlanksBehaviour{CaseSensitive: isTrue(mq.UseCase)},
+	}
+}
+
+// dragTextParams writes each matching pair as a line "question *answer*".
+// Answers without a question, used as distractors in Moodle, are dropped.
+func (mq *Question) dragTextParams() *schemas.DragTextParams {
+	var lines []string
+	for _, sq := range mq.SubQuestions {
+		q := plainText(sq.Text)
+		if q == "" {
+			continue
+		}
+		lines = append(lines, q+" *"+plainText(sq.Answer.Text)+"*")
+	}
+	return &schemas.DragTextParams{
+		TaskDescription: mq.QuestionText.text(),
+		TextField:       strings.Join(lines, "\n"),
+	}
+}
+
+func (mq *Question) essayParams() *schemas.EssayParams {
+	p := &schemas.EssayParams{
+		TaskDescription: mq.QuestionText.text(),
+		PlaceholderText: plainText(mq.ResponseTemplate.text()),
+	}
+	if sample := mq.GraderInfo.text(); sample != "" {
+		p.Solution = &schemas.EssaySolution{Sample: sample}
+	}
+	return p
+}
+
+// plainText strips HTML tags and entities, as DragText text is not HTML.
+func plainText(s string) string {
+	return strings.TrimSpace(html.UnescapeString(htmlTag.ReplaceAllString(s, "")))
+}
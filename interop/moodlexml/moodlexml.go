@@ -0,0 +1,153 @@
+// Package moodlexml converts between H5P question sets and Moodle's question
+// XML format. Moodle multichoice, truefalse, shortanswer, matching and essay
+// questions map to H5P.MultiChoice, H5P.TrueFalse, H5P.Blanks, H5P.DragText
+// and H5P.Essay respectively.
+package moodlexml
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Moodle question types.
+const (
+	TypeCategory    = "category"
+	TypeMultiChoice = "multichoice"
+	TypeTrueFalse   = "truefalse"
+	TypeShortAnswer = "shortanswer"
+	TypeMatching    = "matching"
+	TypeEssay       = "essay"
+)
+
+// Moodle text formats.
+const (
+	FormatHTML   = "html"
+	FormatPlain  = "plain_text"
+	FormatMoodle = "moodle_auto_format"
+)
+
+var ErrUnsupportedQuestion = errors.New("question type is not supported")
+
+// Options controls conversion in both directions.
+type Options struct {
+	// Category, if set, is written as a category question on export so
+	// Moodle imports into that question bank category, e.g.
+	// "$course$/Geography".
+	Category string
+
+	// SkipUnsupported omits questions that cannot be converted instead of
+	// failing with ErrUnsupportedQuestion.
+	SkipUnsupported bool
+}
+
+// Quiz is the root element of a Moodle question XML file.
+type Quiz struct {
+	XMLName   xml.Name   `xml:"quiz"`
+	Questions []Question `xml:"question"`
+}
+
+// Question is a Moodle question. Only the elements used by the supported
+// question types are modelled.
+type Question struct {
+	Type             string        `xml:"type,attr"`
+	Category         *Text         `xml:"category,omitempty"`
+	Name             *Text         `xml:"name,omitempty"`
+	QuestionText     *Text         `xml:"questiontext,omitempty"`
+	GeneralFeedback  *Text         `xml:"generalfeedback,omitempty"`
+	DefaultGrade     string        `xml:"defaultgrade,omitempty"`
+	Single           string        `xml:"single,omitempty"`
+	ShuffleAnswers   string        `xml:"shuffleanswers,omitempty"`
+	UseCase          string        `xml:"usecase,omitempty"`
+	Answers          []Answer      `xml:"answer"`
+	SubQuestions     []SubQuestion `xml:"subquestion"`
+	ResponseFormat   string        `xml:"responseformat,omitempty"`
+	GraderInfo       *Text         `xml:"graderinfo,omitempty"`
+	ResponseTemplate *Text         `xml:"responsetemplate,omitempty"`
+}
+
+// Text is a Moodle text element with an optional format attribute.
+type Text struct {
+	Format string `xml:"format,attr,omitempty"`
+	Text   string `xml:"text"`
+}
+
+// Answer is a Moodle answer. Fraction is the percentage of the grade it
+// earns, e.g. "100", "33.33333" or "-100".
+type Answer struct {
+	Fraction string `xml:"fraction,attr"`
+	Format   string `xml:"format,attr,omitempty"`
+	Text     string `xml:"text"`
+	Feedback *Text  `xml:"feedback,omitempty"`
+}
+
+// SubQuestion is a matching question pair.
+type SubQuestion struct {
+	Format string `xml:"format,attr,omitempty"`
+	Text   string `xml:"text"`
+	Answer Text   `xml:"answer"`
+}
+
+// Read parses a Moodle question XML document.
+func Read(r io.Reader) (*Quiz, error) {
+	var quiz Quiz
+	if err := xml.NewDecoder(r).Decode(&quiz); err != nil {
+		return nil, fmt.Errorf("failed to parse Moodle XML: %w", err)
+	}
+	return &quiz, nil
+}
+
+// Write writes the quiz as an indented XML document.
+func (quiz *Quiz) Write(w io.Writer) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(quiz); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// text returns the text of t, or "" if t is nil.
+func (t *Text) text() string {
+	if t == nil {
+		return ""
+	}
+	return t.Text
+}
+
+// fractionValue parses an answer fraction, treating malformed values as 0.
+func (a Answer) fractionValue() float64 {
+	f, err := strconv.ParseFloat(strings.TrimSpace(a.Fraction), 64)
+	if err != nil {
+		return 0
+	}
+	return f
+}
+
+// formatFraction formats a fraction with the five decimals Moodle uses,
+// e.g. "33.33333" or "100".
+func formatFraction(f float64) string {
+	s := strconv.FormatFloat(f, 'f', 5, 64)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
+
+// isTrue parses a Moodle boolean, written as "1"/"0" or "true"/"false".
+func isTrue(s string) bool {
+	s = strings.TrimSpace(s)
+	return s == "1" || strings.EqualFold(s, "true")
+}
+
+func boolString(b bool) string {
+	if b {
+		return "true"
+	}
+	return "false"
+}
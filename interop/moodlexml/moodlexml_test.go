@@ -0,0 +1,160 @@
+package moodlexml
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	h5p "github.com/grokify/h5p-go"
+	"github.com/grokify/h5p-go/schemas"
+)
+
+func importTestQuiz(t *testing.T, opts Options) (*h5p.QuestionSet, error) {
+	t.Helper()
+	f, err := os.Open("testdata/quiz.xml")
+	if err != nil {
+		t.Fatalf("Failed to open quiz: %v", err)
+	}
+	defer f.Close()
+	return Import(f, opts)
+}
+
+func TestImport(t *testing.T) {
+	if _, err := importTestQuiz(t, Options{}); !errors.Is(err, ErrUnsupportedQuestion) {
+		t.Fatalf("Expected ErrUnsupportedQuestion for numerical question, got %v", err)
+	}
+
+	qs, err := importTestQuiz(t, Options{SkipUnsupported: true})
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if qs.Title != "Europe" {
+		t.Errorf("Expected title from category, got %q", qs.Title)
+	}
+	var libraries []string
+	for _, q := range qs.Questions {
+		libraries = append(libraries, q.Library)
+		if q.SubContentID == "" {
+			t.Errorf("Expected subContentId for %s", q.Library)
+		}
+	}
+	wantLibraries := []string{"H5P.MultiChoice 1.16", "H5P.TrueFalse 1.8", "H5P.Blanks 1.14", "H5P.DragText 1.10", "H5P.Essay 1.5"}
+	if !reflect.DeepEqual(libraries, wantLibraries) {
+		t.Fatalf("Expected libraries %v, got %v", wantLibraries, libraries)
+	}
+
+	mc := qs.Questions[0].Params.(*schemas.MultiChoiceParams)
+	if mc.Question != "<p>What is the capital of France?</p>" || mc.Behaviour.Type != "single" || !mc.Behaviour.RandomAnswers ||
+		len(mc.Answers) != 2 || !mc.Answers[0].Correct || mc.Answers[0].TipsAndFeedback.ChosenFeedback != "Correct!" {
+		t.Errorf("Unexpected MultiChoice params: %+v", mc)
+	}
+	if qs.Questions[0].Metadata.Title != "Capital of France" {
+		t.Errorf("Expected question title, got %q", qs.Questions[0].Metadata.Title)
+	}
+
+	tf := qs.Questions[1].Params.(*schemas.TrueFalseParams)
+	if tf.IsTrue() || tf.Behaviour.FeedbackOnCorrect != "Right." || tf.Behaviour.FeedbackOnWrong != "No, Italy." {
+		t.Errorf("Unexpected TrueFalse params: %+v", tf)
+	}
+
+	blanks := qs.Questions[2].Params.(*schemas.BlanksParams)
+	if want := []string{"<p>The capital of Germany is *Berlin/berlin city*.</p>"}; !reflect.DeepEqual(blanks.Questions, want) ||
+		blanks.Behaviour.CaseSensitive {
+		t.Errorf("Unexpected Blanks params: %+v", blanks)
+	}
+
+	dragText := qs.Questions[3].Params.(*schemas.DragTextParams)
+	if want := "Paris & Seine *France*\nDanube *Austria*"; dragText.TextField != want {
+		t.Errorf("Expected DragText text %q, got %q", want, dragText.TextField)
+	}
+
+	essay := qs.Questions[4].Params.(*schemas.EssayParams)
+	if essay.TaskDescription != "Describe the EU." || essay.PlaceholderText != "The EU is..." ||
+		essay.Solution.Sample != "Mentions 27 members." {
+		t.Errorf("Unexpected Essay params: %+v", essay)
+	}
+}
+
+func TestExportRoundTrip(t *testing.T) {
+	qs, err := importTestQuiz(t, Options{SkipUnsupported: true})
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := Export(&buf, qs, Options{Category: "$course$/Geography/Europe"}); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "<?xml") {
+		t.Errorf("Expected XML header, got %q", buf.String()[:20])
+	}
+
+	again, err := Import(&buf, Options{})
+	if err != nil {
+		t.Fatalf("Re-import failed: %v", err)
+	}
+	if again.Title != "Europe" || len(again.Questions) != len(qs.Questions) {
+		t.Fatalf("Expected %d questions titled Europe, got %d titled %q", len(qs.Questions), len(again.Questions), again.Title)
+	}
+	for i := range qs.Questions {
+		qs.Questions[i].SubContentID = again.Questions[i].SubContentID
+	}
+	if !reflect.DeepEqual(again.Questions[:3], qs.Questions[:3]) {
+		t.Errorf("MultiChoice, TrueFalse and Blanks did not round-trip:\n%+v\n%+v", qs.Questions[:3], again.Questions[:3])
+	}
+	// Matching pairs come back with the dragged word blanked out.
+	if got := again.Questions[3].Params.(*schemas.DragTextParams).TextField; got != "Paris & Seine _____ *France*\nDanube _____ *Austria*" {
+		t.Errorf("Unexpected DragText round trip %q", got)
+	}
+}
+
+func TestFromQuestionSet(t *testing.T) {
+	qs := &h5p.QuestionSet{Questions: []h5p.Question{
+		{
+			Library: "H5P.MultiChoice 1.16",
+			Params: map[string]any{
+				"question": "Primes?",
+				"answers": []any{
+					map[string]any{"text": "2", "correct": true},
+					map[string]any{"text": "3", "correct": true},
+					map[string]any{"text": "4", "correct": false},
+				},
+			},
+		},
+		{
+			Library: "H5P.Blanks 1.14",
+			Params:  map[string]any{"questions": []any{"*A* and *B*"}},
+		},
+	}}
+
+	if _, err := FromQuestionSet(qs, Options{}); !errors.Is(err, ErrUnsupportedQuestion) {
+		t.Errorf("Expected ErrUnsupportedQuestion for two blanks, got %v", err)
+	}
+
+	quiz, err := FromQuestionSet(qs, Options{SkipUnsupported: true})
+	if err != nil {
+		t.Fatalf("FromQuestionSet failed: %v", err)
+	}
+	mq := quiz.Questions[0]
+	var fractions []string
+	for _, a := range mq.Answers {
+		fractions = append(fractions, a.Fraction)
+	}
+	if mq.Name.Text != "Question 1" || mq.Single != "false" || !reflect.DeepEqual(fractions, []string{"50", "50", "-100"}) {
+		t.Errorf("Unexpected multichoice: %+v", mq)
+	}
+}
+
+func TestFromDragText(t *testing.T) {
+	mq := fromDragText(&schemas.DragTextParams{TextField: "*Oslo* is in *Norway:north*"})
+	want := []SubQuestion{
+		{Format: FormatHTML, Text: "_____ is in Norway", Answer: Text{Text: "Oslo"}},
+		{Format: FormatHTML, Text: "Oslo is in _____", Answer: Text{Text: "Norway"}},
+	}
+	if !reflect.DeepEqual(mq.SubQuestions, want) {
+		t.Errorf("Expected %+v, got %+v", want, mq.SubQuestions)
+	}
+}
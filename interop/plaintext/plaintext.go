@@ -0,0 +1,457 @@
+// Package plaintext imports question banks typed by hand, as found in
+// legacy Word documents and text files, as H5P question sets.
+//
+// The importer is forgiving and recognizes the common conventions:
+//
+//	Chapter 3 Quiz
+//
+//	1. What is the capital of France?
+//	a) Lyon
+//	*b) Paris
+//	c) Nice
+//
+//	Q2: Which of these are prime numbers?
+//	A. 2
+//	B. 4
+//	C. 5
+//	Answer: A, C
+//	Explanation: 4 is divisible by 2.
+//
+//	3) The sun is a star.
+//	Answer: True
+//
+// Questions are numbered with "1.", "1)", "Q1:" or "Question 1." or, in
+// files without numbers, separated by blank lines. Options are lettered
+// "a)", "A.", or "(a)". Correct options are marked with a leading or
+// trailing "*" or listed on an "Answer:", "Ans:", "Correct answer:" or
+// "Key:" line by letter or text. An "Explanation:", "Feedback:" or
+// "Rationale:" line becomes the question feedback. Questions answered true
+// or false, or with exactly the options True and False, become TrueFalse
+// questions; all others become MultiChoice questions. A heading before the
+// first question is used as the title.
+//
+// The text is plain text and is escaped for H5P's HTML fields. Lines the
+// importer has to guess about, such as wrapped option text, options out of
+// sequence or answer lines that contradict the marked options, and
+// questions it has to skip are reported as Warnings.
+package plaintext
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	h5p "github.com/grokify/h5p-go"
+	"github.com/grokify/h5p-go/schemas"
+)
+
+var ErrNoQuestions = errors.New("no questions found")
+
+// Options controls the import.
+type Options struct {
+	// Title names the question set. It defaults to a heading before the
+	// first question.
+	Title string
+
+	// Strict fails with the Warnings instead of importing around
+	// ambiguous lines.
+	Strict bool
+}
+
+// Warning reports a line the importer was unsure about or a question it
+// skipped. Line is 1-based.
+type Warning struct {
+	Line    int    `json:"line"`
+	Text    string `json:"text,omitempty"`
+	Message string `json:"message"`
+}
+
+func (w Warning) Error() string {
+	if w.Text == "" {
+		return fmt.Sprintf("line %d: %s", w.Line, w.Message)
+	}
+	return fmt.Sprintf("line %d: %s: %q", w.Line, w.Message, w.Text)
+}
+
+// Warnings lists every problem found in the input.
+type Warnings []Warning
+
+func (w Warnings) Error() string {
+	msgs := make([]string, len(w))
+	for i, e := range w {
+		msgs[i] = e.Error()
+	}
+	return fmt.Sprintf("%d import warning(s): %s", len(w), strings.Join(msgs, "; "))
+}
+
+// Result is an imported question set with the warnings to review.
+type Result struct {
+	QuestionSet *h5p.QuestionSet
+	Warnings    Warnings
+}
+
+var (
+	questionLine = regexp.MustCompile(`^(?:(?i:q(?:uestion)?)\s*(\d+)\s*[.):]?|(\d+)\s*[.):])\s+(\S.*)$`)
+	optionLine   = regexp.MustCompile(`^(\*)?\s*\(?([A-Za-z])\s*[.)]\s+(\S.*?)(\s*\*)?$`)
+	answerLine   = regexp.MustCompile(`^(?i:correct\s+answers?|answers?|ans|key)\s*[:=-]\s*(\S.*)$`)
+	feedbackLine = regexp.MustCompile(`^(?i:explanation|feedback|rationale)\s*[:-]\s*(\S.*)$`)
+
+	answerSplit  = regexp.MustCompile(`(?i)\s*(?:[,;&/]|\s+and\s+|\s+)\s*`)
+	answerLetter = regexp.MustCompile(`^\(?([A-Za-z])\)?[.)]?$`)
+	letterPrefix = regexp.MustCompile(`^\(?([A-Za-z])\s*[.)]\s+`)
+)
+
+// Parse imports the questions in text.
+func Parse(text string, opts Options) (*Result, error) {
+	return Read(strings.NewReader(text), opts)
+}
+
+// ReadFile imports the text file at path.
+func ReadFile(path string, opts Options) (*Result, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Read(f, opts)
+}
+
+// Read imports the questions read from r. Questions that cannot be read
+// unambiguously enough are skipped and reported in Result.Warnings; with
+// Options.Strict any warning fails the import. ErrNoQuestions is returned,
+// joined with the warnings, when nothing could be imported.
+func Read(r io.Reader, opts Options) (*Result, error) {
+	p := parser{title: opts.Title}
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 1<<20)
+	for sc.Scan() {
+		p.line(sc.Text())
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read text: %w", err)
+	}
+	p.flush()
+
+	switch {
+	case opts.Strict && len(p.warnings) > 0:
+		return nil, p.warnings
+	case len(p.questions) == 0 && len(p.warnings) > 0:
+		return nil, errors.Join(ErrNoQuestions, p.warnings)
+	case len(p.questions) == 0:
+		return nil, ErrNoQuestions
+	}
+	qs := &h5p.QuestionSet{Title: p.title, Questions: p.questions}
+	qs.EnsureSubContentIDs()
+	return &Result{QuestionSet: qs, Warnings: p.warnings}, nil
+}
+
+type option struct {
+	text   string
+	marked bool
+}
+
+// block collects the lines of one question.
+type block struct {
+	line       int
+	number     int // 0 for unnumbered questions
+	question   []string
+	options    []option
+	answer     string
+	answerLine int
+	feedback   string
+}
+
+type parser struct {
+	num        int // current line number
+	blank      bool
+	cur        *block
+	lastNumber int
+	blocks     int
+	title      string
+	questions  []h5p.Question
+	warnings   Warnings
+}
+
+func (p *parser) warnf(line int, text, format string, args ...any) {
+	p.warnings = append(p.warnings, Warning{Line: line, Text: text, Message: fmt.Sprintf(format, args...)})
+}
+
+func (p *parser) start(number int, text string) {
+	p.flush()
+	p.cur = &block{line: p.num, number: number, question: []string{text}}
+	p.blocks++
+}
+
+func (p *parser) line(raw string) {
+	p.num++
+	s := strings.TrimPrefix(raw, "\ufeff")
+	s = strings.TrimSpace(strings.ReplaceAll(s, "\u00a0", " "))
+	if s == "" {
+		p.blank = true
+		return
+	}
+	blank := p.blank
+	p.blank = false
+	b := p.cur
+
+	if m := questionLine.FindStringSubmatch(s); m != nil {
+		number := atoi(m[1] + m[2])
+		if p.lastNumber > 0 && number != p.lastNumber+1 {
+			p.warnf(p.num, s, "question %d follows question %d", number, p.lastNumber)
+		}
+		p.lastNumber = number
+		p.start(number, m[3])
+		return
+	}
+
+	if m := answerLine.FindStringSubmatch(s); m != nil {
+		switch {
+		case b == nil:
+			p.warnf(p.num, s, "ignored answer line outside a question")
+		case b.answer != "":
+			p.warnf(p.num, s, "ignored second answer line")
+		default:
+			b.answer, b.answerLine = strings.TrimSpace(m[1]), p.num
+		}
+		return
+	}
+	if m := feedbackLine.FindStringSubmatch(s); m != nil {
+		if b == nil {
+			p.warnf(p.num, s, "ignored feedback line outside a question")
+			return
+		}
+		b.feedback = strings.TrimSpace(strings.Join([]string{b.feedback, m[1]}, " "))
+		return
+	}
+
+	if m := optionLine.FindStringSubmatch(s); m != nil {
+		if b == nil || b.answer != "" {
+			p.warnf(p.num, s, "ignored option outside a question")
+			return
+		}
+		letter := strings.ToLower(m[2])
+		if want := string(rune('a' + len(b.options))); letter != want {
+			p.warnf(p.num, s, "option %s out of sequence, read as option %s", m[2], want)
+		}
+		b.options = append(b.options, option{text: m[3], marked: m[1] != "" || m[4] != ""})
+		return
+	}
+
+	switch {
+	case b == nil:
+		p.start(0, s)
+	case b.answer != "" || b.feedback != "":
+		if !blank {
+			p.warnf(p.num, s, "text after the answer read as a new question")
+		} else if b.number > 0 {
+			p.warnf(p.num, s, "unnumbered question")
+		}
+		p.start(0, s)
+	case len(b.options) > 0 && blank:
+		if b.number > 0 {
+			p.warnf(p.num, s, "unnumbered question")
+		}
+		p.start(0, s)
+	case len(b.options) > 0:
+		o := &b.options[len(b.options)-1]
+		p.warnf(p.num, s, "line read as a continuation of option %c", 'a'+len(b.options)-1)
+		o.text += " " + s
+	default:
+		b.question = append(b.question, s)
+	}
+}
+
+// flush converts the current block to a question.
+func (p *parser) flush() {
+	b := p.cur
+	p.cur = nil
+	if b == nil {
+		return
+	}
+	text := strings.Join(b.question, " ")
+	if len(b.options) == 0 && b.answer == "" {
+		if p.blocks == 1 && b.number == 0 && len(b.question) == 1 && b.feedback == "" && p.title == "" {
+			p.title = text
+			return
+		}
+		if p.blocks == 1 && b.number == 0 {
+			p.warnf(b.line, text, "ignored text before the first question")
+			return
+		}
+		p.warnf(b.line, text, "question has no options or answer, skipped")
+		return
+	}
+
+	correct, ok := p.correct(b)
+	if !ok {
+		return
+	}
+
+	var machineName string
+	var params any
+	var result *schemas.ValidationResult
+	if answer, isTF := trueFalse(b, correct); isTF {
+		tf := &schemas.TrueFalseParams{Question: html.EscapeString(text), Correct: answer}
+		if b.feedback != "" {
+			fb := html.EscapeString(b.feedback)
+			tf.Behaviour = &schemas.TrueFalseBehaviour{FeedbackOnCorrect: fb, FeedbackOnWrong: fb}
+		}
+		machineName, params, result = "H5P.TrueFalse", tf, tf.ValidateAll()
+	} else {
+		mc := &schemas.MultiChoiceParams{Question: html.EscapeString(text)}
+		for i, o := range b.options {
+			mc.Answers = append(mc.Answers, schemas.AnswerOption{Text: html.EscapeString(o.text), Correct: correct[i]})
+		}
+		if count(correct) > 1 {
+			mc.Behaviour = &schemas.Behaviour{Type: "multi"}
+		}
+		if b.feedback != "" {
+			mc.OverallFeedback = &schemas.OverallFeedback{OverallFeedback: []schemas.FeedbackRange{
+				{From: 0, To: 100, Feedback: html.EscapeString(b.feedback)},
+			}}
+		}
+		machineName, params, result = "H5P.MultiChoice", mc, mc.ValidateAll()
+	}
+	if errs := result.Errors(); len(errs) > 0 {
+		for _, e := range errs {
+			p.warnf(b.line, text, "%s, skipped", e.Error())
+		}
+		return
+	}
+	library, _ := h5p.LatestLibraryString(machineName)
+	p.questions = append(p.questions, h5p.Question{Library: library, Params: params})
+}
+
+// correct returns the correct flag of each option, from the answer line
+// if there is one and from the "*" markers otherwise.
+func (p *parser) correct(b *block) ([]bool, bool) {
+	marked := make([]bool, len(b.options))
+	for i, o := range b.options {
+		marked[i] = o.marked
+	}
+	if b.answer == "" {
+		if count(marked) == 0 {
+			p.warnf(b.line, strings.Join(b.question, " "), "no correct answer marked, skipped")
+			return nil, false
+		}
+		return marked, true
+	}
+	if len(b.options) == 0 {
+		if isTrueFalse(b.answer) {
+			return nil, true
+		}
+		p.warnf(b.answerLine, b.answer, "answer without options, skipped")
+		return nil, false
+	}
+
+	flags, ok := answerFlags(b.answer, b.options)
+	if !ok {
+		p.warnf(b.answerLine, b.answer, "answer matches no option, skipped")
+		return nil, false
+	}
+	if count(marked) > 0 && !slices.Equal(marked, flags) {
+		p.warnf(b.answerLine, b.answer, "answer line disagrees with the marked options, using the answer line")
+	}
+	return flags, true
+}
+
+// answerFlags resolves an answer line to option flags. The answer is a
+// list of letters, a letter followed by the option text or the option
+// text itself.
+func answerFlags(answer string, options []option) ([]bool, bool) {
+	flags := make([]bool, len(options))
+	answer = strings.TrimSuffix(answer, ".")
+	if i := findOption(answer, options); i >= 0 {
+		flags[i] = true
+		return flags, true
+	}
+
+	var letters []int
+	for _, f := range answerSplit.Split(answer, -1) {
+		if f == "" {
+			continue
+		}
+		m := answerLetter.FindStringSubmatch(f)
+		if m == nil {
+			letters = nil
+			break
+		}
+		letters = append(letters, int(strings.ToLower(m[1])[0]-'a'))
+	}
+	if letters == nil {
+		if m := letterPrefix.FindStringSubmatch(answer); m != nil {
+			letters = []int{int(strings.ToLower(m[1])[0] - 'a')}
+		}
+	}
+	for _, i := range letters {
+		if i >= len(options) {
+			return nil, false
+		}
+		flags[i] = true
+	}
+	return flags, len(letters) > 0
+}
+
+func findOption(text string, options []option) int {
+	for i, o := range options {
+		if strings.EqualFold(strings.TrimSuffix(o.text, "."), text) {
+			return i
+		}
+	}
+	return -1
+}
+
+// trueFalse reports whether b is a true/false question and its answer.
+func trueFalse(b *block, correct []bool) (string, bool) {
+	if len(b.options) == 0 {
+		if isTrueFalse(b.answer) {
+			return normalizeTrueFalse(b.answer), true
+		}
+		return "", false
+	}
+	if len(b.options) != 2 || !isTrueFalse(b.options[0].text) || !isTrueFalse(b.options[1].text) || count(correct) != 1 {
+		return "", false
+	}
+	if correct[0] {
+		return normalizeTrueFalse(b.options[0].text), true
+	}
+	return normalizeTrueFalse(b.options[1].text), true
+}
+
+func isTrueFalse(s string) bool {
+	return normalizeTrueFalse(s) != ""
+}
+
+// normalizeTrueFalse returns "true" or "false" for the spellings of a
+// true/false answer and "" for anything else.
+func normalizeTrueFalse(s string) string {
+	switch strings.ToLower(strings.TrimSuffix(strings.TrimSpace(s), ".")) {
+	case "true", "t":
+		return "true"
+	case "false", "f":
+		return "false"
+	}
+	return ""
+}
+
+func count(flags []bool) int {
+	n := 0
+	for _, f := range flags {
+		if f {
+			n++
+		}
+	}
+	return n
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
@@ -0,0 +1,184 @@
+package plaintext
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/grokify/h5p-go/schemas"
+)
+
+const bank = "\ufeffChapter 3 Quiz\r\n" + `
+1. What is the capital
+of France?
+a) Lyon
+*b) Paris
+c) Nice
+
+Q2: Which of these are prime numbers?
+A. 2
+B. 4
+C. 5
+Answer: A, C
+Explanation: 4 is divisible by 2.
+
+3) The sun is a star.
+Answer: True
+
+Question 4. Pick the noble gas.
+(a) Oxygen
+(b) Neon *
+Ans: b) Neon
+
+5. Is 1 < 2?
+a. True
+b. False
+Key: a
+`
+
+func TestParse(t *testing.T) {
+	res, err := Parse(bank, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Warnings) != 0 {
+		t.Errorf("unexpected warnings: %v", res.Warnings)
+	}
+	qs := res.QuestionSet
+	if qs.Title != "Chapter 3 Quiz" || len(qs.Questions) != 5 {
+		t.Fatalf("title %q, %d questions", qs.Title, len(qs.Questions))
+	}
+	for _, q := range qs.Questions {
+		if q.SubContentID == "" {
+			t.Errorf("question %q has no subContentId", q.Library)
+		}
+	}
+
+	var mc schemas.MultiChoiceParams
+	if err := qs.Questions[0].DecodeParams(&mc); err != nil {
+		t.Fatal(err)
+	}
+	if mc.Question != "What is the capital of France?" || len(mc.Answers) != 3 || !mc.Answers[1].Correct || mc.Answers[0].Correct {
+		t.Errorf("question 1 = %+v", mc)
+	}
+
+	mc = schemas.MultiChoiceParams{}
+	if err := qs.Questions[1].DecodeParams(&mc); err != nil {
+		t.Fatal(err)
+	}
+	if !mc.Answers[0].Correct || mc.Answers[1].Correct || !mc.Answers[2].Correct || mc.Behaviour == nil || mc.Behaviour.Type != "multi" {
+		t.Errorf("question 2 = %+v", mc)
+	}
+	if mc.OverallFeedback == nil || mc.OverallFeedback.OverallFeedback[0].Feedback != "4 is divisible by 2." {
+		t.Errorf("question 2 feedback = %+v", mc.OverallFeedback)
+	}
+
+	for i, want := range map[int]string{2: "true", 4: "true"} {
+		var tf schemas.TrueFalseParams
+		if qs.Questions[i].MachineName() != "H5P.TrueFalse" {
+			t.Fatalf("question %d is %s", i+1, qs.Questions[i].Library)
+		}
+		if err := qs.Questions[i].DecodeParams(&tf); err != nil || tf.Correct != want {
+			t.Errorf("question %d = %+v, %v", i+1, tf, err)
+		}
+	}
+	var tf schemas.TrueFalseParams
+	if err := qs.Questions[4].DecodeParams(&tf); err != nil || tf.Question != "Is 1 &lt; 2?" {
+		t.Errorf("question 5 text = %q, %v", tf.Question, err)
+	}
+}
+
+func TestParseUnnumbered(t *testing.T) {
+	res, err := Parse(`What colour is the sky?
+a) Blue *
+b) Green
+
+What colour is grass?
+a) Blue
+b) Green
+Answer: Green
+`, Options{Title: "Colours"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.QuestionSet.Title != "Colours" || len(res.QuestionSet.Questions) != 2 || len(res.Warnings) != 0 {
+		t.Errorf("got %d questions, warnings %v", len(res.QuestionSet.Questions), res.Warnings)
+	}
+}
+
+const ambiguous = `1. Largest ocean?
+a) Atlantic
+c) Pacific
+Answer: b
+
+3. Smallest planet?
+a) Mercury
+that is closest to the sun
+*b) Mars
+Answer: a
+
+4. No answer here?
+a) Yes
+b) No
+
+5. Answer out of range?
+a) One
+Answer: d
+d) Four
+`
+
+var ambiguousWarnings = []struct {
+	line    int
+	message string
+}{
+	{3, "option c out of sequence, read as option b"},
+	{6, "question 3 follows question 1"},
+	{8, "line read as a continuation of option a"},
+	{10, "answer line disagrees with the marked options, using the answer line"},
+	{12, "no correct answer marked, skipped"},
+	{19, "ignored option outside a question"},
+	{18, "answer matches no option, skipped"},
+}
+
+func TestParseWarnings(t *testing.T) {
+	res, err := Parse(ambiguous, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(res.QuestionSet.Questions); n != 2 {
+		t.Errorf("got %d questions, want 2", n)
+	}
+	if len(res.Warnings) != len(ambiguousWarnings) {
+		t.Fatalf("got warnings %v", res.Warnings)
+	}
+	for i, want := range ambiguousWarnings {
+		if w := res.Warnings[i]; w.Line != want.line || w.Message != want.message {
+			t.Errorf("warning %d = %v, want line %d: %s", i, w, want.line, want.message)
+		}
+	}
+
+	var mc schemas.MultiChoiceParams
+	if err := res.QuestionSet.Questions[1].DecodeParams(&mc); err != nil {
+		t.Fatal(err)
+	}
+	if mc.Answers[0].Text != "Mercury that is closest to the sun" || !mc.Answers[0].Correct || mc.Answers[1].Correct {
+		t.Errorf("question 3 = %+v", mc.Answers)
+	}
+
+	_, err = Parse(ambiguous, Options{Strict: true})
+	var warnings Warnings
+	if !errors.As(err, &warnings) || len(warnings) != len(ambiguousWarnings) {
+		t.Errorf("Expected Warnings in strict mode, got %v", err)
+	}
+}
+
+func TestParseNoQuestions(t *testing.T) {
+	if _, err := Parse("", Options{}); !errors.Is(err, ErrNoQuestions) {
+		t.Errorf("Expected ErrNoQuestions, got %v", err)
+	}
+	_, err := Parse("Notes\n\nSome text\nAnswer: c\n", Options{})
+	var warnings Warnings
+	if !errors.Is(err, ErrNoQuestions) || !errors.As(err, &warnings) || !strings.Contains(err.Error(), "answer without options") {
+		t.Errorf("Expected ErrNoQuestions with warnings, got %v", err)
+	}
+}
@@ -0,0 +1,374 @@
+package qti
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	h5p "github.com/grokify/h5p-go"
+	"github.com/grokify/h5p-go/schemas"
+)
+
+// ErrInvalidPackage reports a content package or item that cannot be read.
+var ErrInvalidPackage = errors.New("invalid QTI package")
+
+// Interactions the supported question types are read from.
+const (
+	choiceInteraction       = "choiceInteraction"
+	textEntryInteraction    = "textEntryInteraction"
+	extendedTextInteraction = "extendedTextInteraction"
+)
+
+// Import reads a zipped QTI 2.1 content package from r and converts its
+// items to a question set. See Package.ToQuestionSet.
+func Import(r io.ReaderAt, size int64, opts Options) (*h5p.QuestionSet, error) {
+	pkg, err := Read(r, size)
+	if err != nil {
+		return nil, err
+	}
+	return pkg.ToQuestionSet(opts)
+}
+
+// ImportFile is Import from the file at path.
+func ImportFile(path string, opts Options) (*h5p.QuestionSet, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return Import(f, info.Size(), opts)
+}
+
+// Read reads a zipped QTI 2.1 content package. The items are the item
+// resources of the manifest, in manifest order; the test is the first test
+// resource, if any.
+func Read(r io.ReaderAt, size int64) (*Package, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPackage, err)
+	}
+	pkg := &Package{Manifest: &Manifest{}}
+	if err := readXML(zr, ManifestFile, pkg.Manifest); err != nil {
+		return nil, err
+	}
+	for _, res := range pkg.Manifest.Resources {
+		href := res.Href
+		if href == "" && len(res.Files) > 0 {
+			href = res.Files[0].Href
+		}
+		switch {
+		case strings.HasPrefix(res.Type, "imsqti_item"):
+			item := &AssessmentItem{}
+			if err := readXML(zr, href, item); err != nil {
+				return nil, err
+			}
+			pkg.Items = append(pkg.Items, item)
+		case strings.HasPrefix(res.Type, "imsqti_test") && pkg.Test == nil:
+			pkg.Test = &AssessmentTest{}
+			if err := readXML(zr, href, pkg.Test); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return pkg, nil
+}
+
+func readXML(fsys fs.FS, name string, v any) error {
+	data, err := fs.ReadFile(fsys, path.Clean(name))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPackage, err)
+	}
+	if err := xml.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrInvalidPackage, name, err)
+	}
+	return nil
+}
+
+// ToQuestionSet converts the items to H5P questions: items with a single
+// choice interaction become H5P.MultiChoice, or H5P.TrueFalse for a
+// true/false choice, items with text entries H5P.Blanks and items with an
+// extended text interaction H5P.Essay. Other items fail with
+// ErrUnsupportedQuestion unless opts.SkipUnsupported is set. Questions get
+// the newest library versions listed in h5p.LatestLibraryVersions.
+func (p *Package) ToQuestionSet(opts Options) (*h5p.QuestionSet, error) {
+	qs := &h5p.QuestionSet{}
+	if p.Test != nil && p.Test.Title != p.Test.Identifier {
+		qs.Title = p.Test.Title
+	}
+	for i, item := range p.Items {
+		machineName, params, err := item.toParams()
+		if err != nil {
+			if opts.SkipUnsupported && errors.Is(err, ErrUnsupportedQuestion) {
+				continue
+			}
+			return nil, fmt.Errorf("item %d: %w", i, err)
+		}
+		library, _ := h5p.LatestLibraryString(machineName)
+		qs.Questions = append(qs.Questions, h5p.Question{
+			Library:  library,
+			Params:   params,
+			Metadata: &h5p.ContentMetadata{Title: item.Title, License: "U"},
+		})
+	}
+	qs.EnsureSubContentIDs()
+	return qs, nil
+}
+
+// interaction is an interaction element of an item body.
+type interaction struct {
+	XMLName            xml.Name
+	ResponseIdentifier string       `xml:"responseIdentifier,attr"`
+	Shuffle            bool         `xml:"shuffle,attr"`
+	MaxChoices         int          `xml:"maxChoices,attr"`
+	PlaceholderText    string       `xml:"placeholderText,attr"`
+	Prompt             *ItemBody    `xml:"prompt"`
+	Choices            []choiceItem `xml:"simpleChoice"`
+}
+
+type choiceItem struct {
+	Identifier string `xml:"identifier,attr"`
+	XML        string `xml:",innerxml"`
+}
+
+// segment is a run of item body markup or an interaction.
+type segment struct {
+	markup      string
+	interaction *interaction
+}
+
+// toParams returns the H5P library machine name and typed params for the
+// item.
+func (item *AssessmentItem) toParams() (string, any, error) {
+	blocks, err := parseBody(item.ItemBody.XML)
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: item %s: %v", ErrInvalidPackage, item.Identifier, err)
+	}
+	var interactions []*interaction
+	for _, b := range blocks {
+		for _, s := range b {
+			if s.interaction != nil {
+				interactions = append(interactions, s.interaction)
+			}
+		}
+	}
+	if len(interactions) == 0 {
+		return "", nil, fmt.Errorf("%w: item %s has no interaction", ErrUnsupportedQuestion, item.Identifier)
+	}
+	kind := interactions[0].XMLName.Local
+	for _, in := range interactions {
+		if in.XMLName.Local != kind || (kind != textEntryInteraction && len(interactions) > 1) {
+			return "", nil, fmt.Errorf("%w: item %s has several interactions", ErrUnsupportedQuestion, item.Identifier)
+		}
+	}
+
+	switch kind {
+	case choiceInteraction:
+		machineName, params := item.choiceParams(blocks, interactions[0])
+		return machineName, params, nil
+	case textEntryInteraction:
+		params, err := item.blanksParams(blocks)
+		return BlanksLibrary, params, err
+	case extendedTextInteraction:
+		return EssayLibrary, &schemas.EssayParams{
+			TaskDescription: text(blocks, nil),
+			PlaceholderText: interactions[0].PlaceholderText,
+		}, nil
+	}
+	return "", nil, fmt.Errorf("%w: %s", ErrUnsupportedQuestion, kind)
+}
+
+func (item *AssessmentItem) choiceParams(blocks [][]segment, in *interaction) (string, any) {
+	question := text(blocks, nil)
+	if in.Prompt != nil {
+		question += strings.TrimSpace(in.Prompt.XML)
+	}
+	var correct []string
+	cardinality := ""
+	if decl := item.response(in.ResponseIdentifier); decl != nil {
+		cardinality = decl.Cardinality
+		if decl.CorrectResponse != nil {
+			correct = decl.CorrectResponse.Values
+		}
+	}
+
+	if len(in.Choices) == 2 && cardinality != "multiple" {
+		first, second := choiceValue(in.Choices[0]), choiceValue(in.Choices[1])
+		if first == "true" && second == "false" || first == "false" && second == "true" {
+			p := &schemas.TrueFalseParams{Question: question, Correct: "false"}
+			for _, c := range in.Choices {
+				if choiceValue(c) == "true" && slices.Contains(correct, c.Identifier) {
+					p.Correct = "true"
+				}
+			}
+			return TrueFalseLibrary, p
+		}
+	}
+
+	p := &schemas.MultiChoiceParams{Question: question}
+	for _, c := range in.Choices {
+		p.Answers = append(p.Answers, schemas.AnswerOption{
+			Text:    strings.TrimSpace(c.XML),
+			Correct: slices.Contains(correct, c.Identifier),
+		})
+	}
+	typ := "single"
+	if cardinality == "multiple" || in.MaxChoices != 1 {
+		typ = "multi"
+	}
+	p.Behaviour = &schemas.Behaviour{Type: typ, RandomAnswers: in.Shuffle}
+	return MultiChoiceLibrary, p
+}
+
+// choiceValue returns "true" or "false" for the choices of a true/false
+// question, identified by their identifier or text.
+func choiceValue(c choiceItem) string {
+	for _, s := range []string{c.Identifier, plainText(c.XML)} {
+		switch v := strings.ToLower(strings.TrimSpace(s)); v {
+		case "true", "false":
+			return v
+		}
+	}
+	return ""
+}
+
+// blanksParams turns body blocks with text entries into Blanks questions,
+// and the blocks before them into the task text.
+func (item *AssessmentItem) blanksParams(blocks [][]segment) (*schemas.BlanksParams, error) {
+	p := &schemas.BlanksParams{Behaviour: &schemas.BlanksBehaviour{CaseSensitive: true}}
+	var err error
+	marker := func(in *interaction) string {
+		var blank schemas.Blank
+		decl := item.response(in.ResponseIdentifier)
+		if decl != nil && decl.Mapping != nil {
+			for _, e := range decl.Mapping.Entries {
+				if !slices.Contains(blank.Answers, e.MapKey) {
+					blank.Answers = append(blank.Answers, e.MapKey)
+				}
+				p.Behaviour.CaseSensitive = e.CaseSensitive
+			}
+		}
+		if len(blank.Answers) == 0 && decl != nil && decl.CorrectResponse != nil {
+			blank.Answers = decl.CorrectResponse.Values
+		}
+		if len(blank.Answers) == 0 && err == nil {
+			err = fmt.Errorf("%w: item %s: text entry %s has no correct answer", ErrInvalidPackage, item.Identifier, in.ResponseIdentifier)
+		}
+		return blank.String()
+	}
+	for _, b := range blocks {
+		hasEntry := slices.ContainsFunc(b, func(s segment) bool { return s.interaction != nil })
+		switch {
+		case hasEntry:
+			p.Questions = append(p.Questions, text([][]segment{b}, marker))
+		case len(p.Questions) == 0:
+			p.Text += text([][]segment{b}, nil)
+		default:
+			// Markup after the first sentence stays in the question text.
+			p.Questions[len(p.Questions)-1] += text([][]segment{b}, nil)
+		}
+	}
+	return p, err
+}
+
+func (item *AssessmentItem) response(id string) *ResponseDeclaration {
+	for i := range item.ResponseDeclarations {
+		if item.ResponseDeclarations[i].Identifier == id {
+			return &item.ResponseDeclarations[i]
+		}
+	}
+	return nil
+}
+
+// text joins the markup of blocks, each without the div Export wraps it
+// in. Interactions are replaced by the result of replace, or dropped when
+// replace is nil.
+func text(blocks [][]segment, replace func(*interaction) string) string {
+	var b strings.Builder
+	for _, block := range blocks {
+		var s strings.Builder
+		for _, seg := range block {
+			switch {
+			case seg.interaction == nil:
+				s.WriteString(seg.markup)
+			case replace != nil:
+				s.WriteString(replace(seg.interaction))
+			}
+		}
+		t := strings.TrimSpace(s.String())
+		if inner, ok := strings.CutPrefix(t, "<div>"); ok && strings.HasSuffix(inner, "</div>") {
+			t = strings.TrimSpace(strings.TrimSuffix(inner, "</div>"))
+		}
+		b.WriteString(t)
+	}
+	return b.String()
+}
+
+// parseBody splits item body markup into its top-level blocks, each a list
+// of markup runs and the interactions between them.
+func parseBody(body string) ([][]segment, error) {
+	dec := xml.NewDecoder(strings.NewReader(body))
+	dec.Entity = xml.HTMLEntity
+	var blocks [][]segment
+	var current []segment
+	var last int64
+	depth := 0
+	addMarkup := func(end int64) {
+		if s := body[last:end]; strings.TrimSpace(s) != "" {
+			current = append(current, segment{markup: s})
+		}
+		last = end
+	}
+	endBlock := func() {
+		if len(current) > 0 {
+			blocks = append(blocks, current)
+			current = nil
+		}
+	}
+	for {
+		start := dec.InputOffset()
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if !strings.HasSuffix(t.Name.Local, "Interaction") {
+				depth++
+				continue
+			}
+			addMarkup(start)
+			in := &interaction{}
+			if err := dec.DecodeElement(in, &t); err != nil {
+				return nil, err
+			}
+			current = append(current, segment{interaction: in})
+			last = dec.InputOffset()
+			if depth == 0 {
+				endBlock()
+			}
+		case xml.EndElement:
+			depth--
+			if depth == 0 {
+				addMarkup(dec.InputOffset())
+				endBlock()
+			}
+		}
+	}
+	addMarkup(int64(len(body)))
+	endBlock()
+	return blocks, nil
+}
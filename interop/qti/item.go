@@ -0,0 +1,324 @@
+package qti
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	h5p "github.com/grokify/h5p-go"
+	"github.com/grokify/h5p-go/schemas"
+)
+
+// Machine names of the supported question libraries.
+const (
+	MultiChoiceLibrary = "H5P.MultiChoice"
+	TrueFalseLibrary   = "H5P.TrueFalse"
+	BlanksLibrary      = "H5P.Blanks"
+	EssayLibrary       = "H5P.Essay"
+)
+
+var (
+	blankMarker = regexp.MustCompile(`\*[^*]+\*`)
+	htmlTag     = regexp.MustCompile(`<[^>]*>`)
+	voidBreak   = regexp.MustCompile(`<br\s*>`)
+)
+
+// AssessmentItem is a QTI 2.1 assessment item holding one question.
+type AssessmentItem struct {
+	XMLName              xml.Name              `xml:"assessmentItem"`
+	Xmlns                string                `xml:"xmlns,attr"`
+	XmlnsXSI             string                `xml:"xmlns:xsi,attr"`
+	SchemaLocation       string                `xml:"xsi:schemaLocation,attr"`
+	Identifier           string                `xml:"identifier,attr"`
+	Title                string                `xml:"title,attr"`
+	Adaptive             bool                  `xml:"adaptive,attr"`
+	TimeDependent        bool                  `xml:"timeDependent,attr"`
+	ResponseDeclarations []ResponseDeclaration `xml:"responseDeclaration"`
+	OutcomeDeclarations  []OutcomeDeclaration  `xml:"outcomeDeclaration"`
+	ItemBody             ItemBody              `xml:"itemBody"`
+	ResponseProcessing   *ResponseProcessing   `xml:"responseProcessing"`
+}
+
+// ResponseDeclaration declares a response variable and how it is scored.
+type ResponseDeclaration struct {
+	Identifier      string   `xml:"identifier,attr"`
+	Cardinality     string   `xml:"cardinality,attr"`
+	BaseType        string   `xml:"baseType,attr"`
+	CorrectResponse *Values  `xml:"correctResponse"`
+	Mapping         *Mapping `xml:"mapping"`
+}
+
+// OutcomeDeclaration declares an outcome variable such as SCORE.
+type OutcomeDeclaration struct {
+	Identifier   string  `xml:"identifier,attr"`
+	Cardinality  string  `xml:"cardinality,attr"`
+	BaseType     string  `xml:"baseType,attr"`
+	DefaultValue *Values `xml:"defaultValue"`
+}
+
+// Values is a list of value elements.
+type Values struct {
+	Values []string `xml:"value"`
+}
+
+// Mapping maps responses to scores.
+type Mapping struct {
+	DefaultValue string     `xml:"defaultValue,attr"`
+	Entries      []MapEntry `xml:"mapEntry"`
+}
+
+// MapEntry scores a single response value.
+type MapEntry struct {
+	MapKey        string `xml:"mapKey,attr"`
+	MappedValue   string `xml:"mappedValue,attr"`
+	CaseSensitive bool   `xml:"caseSensitive,attr"`
+}
+
+// ItemBody holds the XHTML content and interactions of an item.
+type ItemBody struct {
+	XML string `xml:",innerxml"`
+}
+
+// ResponseProcessing scores the responses, either with a standard template
+// or with the given rules.
+type ResponseProcessing struct {
+	Template string `xml:"template,attr,omitempty"`
+	XML      string `xml:",innerxml"`
+}
+
+func (item *AssessmentItem) href() string {
+	return "items/" + item.Identifier + ".xml"
+}
+
+// newItem converts the question at index i.
+func newItem(q *h5p.Question, i int) (*AssessmentItem, error) {
+	item := &AssessmentItem{
+		Xmlns:          NamespaceQTI,
+		XmlnsXSI:       NamespaceXSI,
+		SchemaLocation: SchemaQTI,
+		Identifier:     itemIdentifier(q, i),
+		Title:          "Question " + strconv.Itoa(i+1),
+		OutcomeDeclarations: []OutcomeDeclaration{{
+			Identifier:   "SCORE",
+			Cardinality:  "single",
+			BaseType:     "float",
+			DefaultValue: &Values{Values: []string{"0"}},
+		}},
+	}
+	if q.Metadata != nil && q.Metadata.Title != "" {
+		item.Title = q.Metadata.Title
+	}
+
+	var err error
+	switch q.MachineName() {
+	case MultiChoiceLibrary:
+		var p schemas.MultiChoiceParams
+		if err = q.DecodeParams(&p); err == nil {
+			item.setMultiChoice(&p)
+		}
+	case TrueFalseLibrary:
+		var p schemas.TrueFalseParams
+		if err = q.DecodeParams(&p); err == nil {
+			item.setTrueFalse(&p)
+		}
+	case BlanksLibrary:
+		var p schemas.BlanksParams
+		if err = q.DecodeParams(&p); err == nil {
+			item.setBlanks(&p)
+		}
+	case EssayLibrary:
+		var p schemas.EssayParams
+		if err = q.DecodeParams(&p); err == nil {
+			item.setEssay(&p)
+		}
+	default:
+		err = fmt.Errorf("%w: %s", ErrUnsupportedQuestion, q.Library)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("question %d: %w", i, err)
+	}
+	return item, nil
+}
+
+func (item *AssessmentItem) setMultiChoice(p *schemas.MultiChoiceParams) {
+	var correct []string
+	for i, a := range p.Answers {
+		if a.Correct {
+			correct = append(correct, choiceIdentifier(i))
+		}
+	}
+	cardinality, maxChoices := "single", 1
+	if len(correct) > 1 || (p.Behaviour != nil && p.Behaviour.Type == "multi") {
+		cardinality, maxChoices = "multiple", 0
+	}
+	shuffle := p.Behaviour != nil && p.Behaviour.RandomAnswers
+
+	var body strings.Builder
+	body.WriteString(block(p.Question))
+	fmt.Fprintf(&body, `<choiceInteraction responseIdentifier="RESPONSE" shuffle="%t" maxChoices="%d">`, shuffle, maxChoices)
+	for i, a := range p.Answers {
+		fmt.Fprintf(&body, `<simpleChoice identifier="%s">%s</simpleChoice>`, choiceIdentifier(i), xhtml(a.Text))
+	}
+	body.WriteString(`</choiceInteraction>`)
+
+	item.setChoice(cardinality, correct, body.String())
+}
+
+func (item *AssessmentItem) setTrueFalse(p *schemas.TrueFalseParams) {
+	trueText, falseText := "True", "False"
+	if p.L10n != nil {
+		trueText = cmp.Or(p.L10n.TrueText, trueText)
+		falseText = cmp.Or(p.L10n.FalseText, falseText)
+	}
+
+	var body strings.Builder
+	body.WriteString(block(p.Question))
+	body.WriteString(`<choiceInteraction responseIdentifier="RESPONSE" shuffle="false" maxChoices="1">`)
+	fmt.Fprintf(&body, `<simpleChoice identifier="true">%s</simpleChoice>`, escape(trueText))
+	fmt.Fprintf(&body, `<simpleChoice identifier="false">%s</simpleChoice>`, escape(falseText))
+	body.WriteString(`</choiceInteraction>`)
+
+	item.setChoice("single", []string{strconv.FormatBool(p.IsTrue())}, body.String())
+}
+
+func (item *AssessmentItem) setChoice(cardinality string, correct []string, body string) {
+	item.ResponseDeclarations = []ResponseDeclaration{{
+		Identifier:      "RESPONSE",
+		Cardinality:     cardinality,
+		BaseType:        "identifier",
+		CorrectResponse: &Values{Values: correct},
+	}}
+	item.ItemBody.XML = body
+	item.ResponseProcessing = &ResponseProcessing{Template: MatchCorrect}
+}
+
+// setBlanks turns each blank into a text entry scored one point for any of
+// its accepted answers.
+func (item *AssessmentItem) setBlanks(p *schemas.BlanksParams) {
+	caseSensitive := p.Behaviour == nil || p.Behaviour.CaseSensitive
+
+	var body strings.Builder
+	if p.Text != "" {
+		body.WriteString(block(p.Text))
+	}
+	var sum strings.Builder
+	n := 0
+	for _, sentence := range p.Questions {
+		var parts []string // text, interaction, text, ...
+		last := 0
+		for _, m := range blankMarker.FindAllStringIndex(sentence, -1) {
+			blank := schemas.ParseBlanks(sentence[m[0]:m[1]])[0]
+			n++
+			id := "RESPONSE_" + strconv.Itoa(n)
+			decl := ResponseDeclaration{
+				Identifier:      id,
+				Cardinality:     "single",
+				BaseType:        "string",
+				CorrectResponse: &Values{Values: blank.Answers[:1]},
+				Mapping:         &Mapping{DefaultValue: "0"},
+			}
+			expected := 0
+			for _, a := range blank.Answers {
+				decl.Mapping.Entries = append(decl.Mapping.Entries, MapEntry{MapKey: a, MappedValue: "1", CaseSensitive: caseSensitive})
+				expected = max(expected, len([]rune(a)))
+			}
+			item.ResponseDeclarations = append(item.ResponseDeclarations, decl)
+			fmt.Fprintf(&sum, `<mapResponse identifier="%s"/>`, id)
+			parts = append(parts, sentence[last:m[0]],
+				fmt.Sprintf(`<textEntryInteraction responseIdentifier="%s" expectedLength="%d"/>`, id, expected))
+			last = m[1]
+		}
+		parts = append(parts, sentence[last:])
+		body.WriteString(inlineBlock(parts))
+	}
+	item.ItemBody.XML = body.String()
+	item.ResponseProcessing = &ResponseProcessing{
+		XML: `<setOutcomeValue identifier="SCORE"><sum>` + sum.String() + `</sum></setOutcomeValue>`,
+	}
+}
+
+// setEssay adds a free text response left to manual scoring.
+func (item *AssessmentItem) setEssay(p *schemas.EssayParams) {
+	item.ResponseDeclarations = []ResponseDeclaration{{
+		Identifier:  "RESPONSE",
+		Cardinality: "single",
+		BaseType:    "string",
+	}}
+	var body strings.Builder
+	body.WriteString(block(p.TaskDescription))
+	body.WriteString(`<extendedTextInteraction responseIdentifier="RESPONSE"`)
+	if p.PlaceholderText != "" {
+		fmt.Fprintf(&body, ` placeholderText="%s"`, escape(p.PlaceholderText))
+	}
+	body.WriteString(`/>`)
+	item.ItemBody.XML = body.String()
+}
+
+// choiceIdentifier returns the identifier of the i-th answer choice.
+func choiceIdentifier(i int) string {
+	return "choice-" + strconv.Itoa(i+1)
+}
+
+// block returns HTML text as an XHTML div for the item body.
+func block(s string) string {
+	return "<div>" + xhtml(s) + "</div>"
+}
+
+// xhtml returns s if it is well-formed XHTML, after converting non-breaking
+// space entities and <br> tags. Anything else, such as HTML using named
+// entities or unclosed tags, is reduced to escaped plain text.
+func xhtml(s string) string {
+	s = voidBreak.ReplaceAllString(strings.ReplaceAll(s, "&nbsp;", "&#160;"), "<br/>")
+	if wellFormed(s) {
+		return s
+	}
+	return escape(plainText(s))
+}
+
+// inlineBlock joins text parts alternating with interaction markup into a
+// div, keeping the text markup only if the whole is well-formed.
+func inlineBlock(parts []string) string {
+	joined := strings.Join(parts, "")
+	joined = voidBreak.ReplaceAllString(strings.ReplaceAll(joined, "&nbsp;", "&#160;"), "<br/>")
+	if wellFormed(joined) {
+		return "<div>" + joined + "</div>"
+	}
+	var b strings.Builder
+	b.WriteString("<div><p>")
+	for i, part := range parts {
+		if i%2 == 1 {
+			b.WriteString(part)
+		} else {
+			b.WriteString(escape(plainText(part)))
+		}
+	}
+	b.WriteString("</p></div>")
+	return b.String()
+}
+
+func wellFormed(s string) bool {
+	dec := xml.NewDecoder(strings.NewReader("<div>" + s + "</div>"))
+	for {
+		if _, err := dec.Token(); err == io.EOF {
+			return true
+		} else if err != nil {
+			return false
+		}
+	}
+}
+
+func plainText(s string) string {
+	return html.UnescapeString(htmlTag.ReplaceAllString(s, ""))
+}
+
+func escape(s string) string {
+	var buf bytes.Buffer
+	_ = xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
@@ -0,0 +1,147 @@
+package qti
+
+import (
+	"encoding/xml"
+	"strconv"
+
+	h5p "github.com/grokify/h5p-go"
+)
+
+// Manifest is an IMS Content Packaging imsmanifest.xml.
+type Manifest struct {
+	XMLName        xml.Name   `xml:"manifest"`
+	Xmlns          string     `xml:"xmlns,attr"`
+	XmlnsXSI       string     `xml:"xmlns:xsi,attr"`
+	SchemaLocation string     `xml:"xsi:schemaLocation,attr"`
+	Identifier     string     `xml:"identifier,attr"`
+	Metadata       CPMetadata `xml:"metadata"`
+	Organizations  struct{}   `xml:"organizations"`
+	Resources      []Resource `xml:"resources>resource"`
+}
+
+// CPMetadata identifies the package schema.
+type CPMetadata struct {
+	Schema        string `xml:"schema"`
+	SchemaVersion string `xml:"schemaversion"`
+}
+
+// Resource is a manifest resource: an item or the test.
+type Resource struct {
+	Identifier   string       `xml:"identifier,attr"`
+	Type         string       `xml:"type,attr"`
+	Href         string       `xml:"href,attr"`
+	Files        []File       `xml:"file"`
+	Dependencies []Dependency `xml:"dependency"`
+}
+
+// File is a file belonging to a resource.
+type File struct {
+	Href string `xml:"href,attr"`
+}
+
+// Dependency references another resource.
+type Dependency struct {
+	IdentifierRef string `xml:"identifierref,attr"`
+}
+
+// AssessmentTest presents the items in order in a single section.
+type AssessmentTest struct {
+	XMLName        xml.Name `xml:"assessmentTest"`
+	Xmlns          string   `xml:"xmlns,attr"`
+	XmlnsXSI       string   `xml:"xmlns:xsi,attr"`
+	SchemaLocation string   `xml:"xsi:schemaLocation,attr"`
+	Identifier     string   `xml:"identifier,attr"`
+	Title          string   `xml:"title,attr"`
+	TestPart       TestPart `xml:"testPart"`
+}
+
+// TestPart groups the test sections.
+type TestPart struct {
+	Identifier     string            `xml:"identifier,attr"`
+	NavigationMode string            `xml:"navigationMode,attr"`
+	SubmissionMode string            `xml:"submissionMode,attr"`
+	Section        AssessmentSection `xml:"assessmentSection"`
+}
+
+// AssessmentSection lists item references.
+type AssessmentSection struct {
+	Identifier string    `xml:"identifier,attr"`
+	Title      string    `xml:"title,attr"`
+	Visible    bool      `xml:"visible,attr"`
+	ItemRefs   []ItemRef `xml:"assessmentItemRef"`
+}
+
+// ItemRef references an item file.
+type ItemRef struct {
+	Identifier string `xml:"identifier,attr"`
+	Href       string `xml:"href,attr"`
+}
+
+// FromQuestionSet converts the supported questions of qs to assessment
+// items and builds the test and manifest referencing them.
+func FromQuestionSet(qs *h5p.QuestionSet, opts Options) (*Package, error) {
+	id := opts.Identifier
+	if id == "" {
+		id = defaultPackage
+	}
+	title := qs.Title
+	if title == "" {
+		title = id
+	}
+
+	pkg := &Package{}
+	for i := range qs.Questions {
+		item, err := newItem(&qs.Questions[i], i)
+		if err != nil {
+			if opts.SkipUnsupported {
+				continue
+			}
+			return nil, err
+		}
+		pkg.Items = append(pkg.Items, item)
+	}
+
+	pkg.Test = &AssessmentTest{
+		Xmlns:          NamespaceQTI,
+		XmlnsXSI:       NamespaceXSI,
+		SchemaLocation: SchemaQTI,
+		Identifier:     id,
+		Title:          title,
+		TestPart: TestPart{
+			Identifier:     "part-1",
+			NavigationMode: "linear",
+			SubmissionMode: "individual",
+			Section:        AssessmentSection{Identifier: "section-1", Title: title, Visible: true},
+		},
+	}
+	pkg.Manifest = &Manifest{
+		Xmlns:          NamespaceCP,
+		XmlnsXSI:       NamespaceXSI,
+		SchemaLocation: SchemaCP,
+		Identifier:     "MANIFEST-" + id,
+		Metadata:       CPMetadata{Schema: "QTIv2.1 Package", SchemaVersion: "1.0.0"},
+	}
+	test := Resource{Identifier: id, Type: TestResource, Href: TestFile, Files: []File{{Href: TestFile}}}
+	for _, item := range pkg.Items {
+		href := item.href()
+		pkg.Test.TestPart.Section.ItemRefs = append(pkg.Test.TestPart.Section.ItemRefs, ItemRef{Identifier: item.Identifier, Href: href})
+		pkg.Manifest.Resources = append(pkg.Manifest.Resources, Resource{
+			Identifier: item.Identifier,
+			Type:       ItemResource,
+			Href:       href,
+			Files:      []File{{Href: href}},
+		})
+		test.Dependencies = append(test.Dependencies, Dependency{IdentifierRef: item.Identifier})
+	}
+	pkg.Manifest.Resources = append(pkg.Manifest.Resources, test)
+	return pkg, nil
+}
+
+// itemIdentifier returns an XML NCName for the question at index i,
+// based on its subContentId when set.
+func itemIdentifier(q *h5p.Question, i int) string {
+	if q.SubContentID != "" {
+		return "item-" + q.SubContentID
+	}
+	return "item-" + strconv.Itoa(i+1)
+}
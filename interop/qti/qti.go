@@ -0,0 +1,106 @@
+// Package qti converts between H5P question sets and IMS QTI 2.1 content
+// packages: a zip archive holding an imsmanifest.xml, an assessment test and
+// one assessment item per question, accepted by LMSs that cannot play H5P.
+package qti
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	h5p "github.com/grokify/h5p-go"
+)
+
+// XML namespaces and schema locations of QTI 2.1 and IMS Content Packaging.
+const (
+	NamespaceQTI   = "http://www.imsglobal.org/xsd/imsqti_v2p1"
+	SchemaQTI      = "http://www.imsglobal.org/xsd/imsqti_v2p1 http://www.imsglobal.org/xsd/qti/qtiv2p1/imsqti_v2p1.xsd"
+	NamespaceCP    = "http://www.imsglobal.org/xsd/imscp_v1p1"
+	SchemaCP       = "http://www.imsglobal.org/xsd/imscp_v1p1 http://www.imsglobal.org/xsd/imscp_v1p1.xsd"
+	NamespaceXSI   = "http://www.w3.org/2001/XMLSchema-instance"
+	ManifestFile   = "imsmanifest.xml"
+	TestFile       = "assessment.xml"
+	ItemResource   = "imsqti_item_xmlv2p1"
+	TestResource   = "imsqti_test_xmlv2p1"
+	MatchCorrect   = "http://www.imsglobal.org/question/qti_v2p1/rptemplates/match_correct"
+	defaultPackage = "h5p-questionset"
+)
+
+var ErrUnsupportedQuestion = errors.New("question type is not supported by QTI conversion")
+
+// Options controls QTI conversion. Identifier only applies to output.
+type Options struct {
+	// Identifier names the assessment test and manifest. It defaults to
+	// "h5p-questionset".
+	Identifier string
+
+	// SkipUnsupported omits questions other than MultiChoice, TrueFalse,
+	// Blanks and Essay, and items without a matching interaction, instead
+	// of failing with ErrUnsupportedQuestion.
+	SkipUnsupported bool
+}
+
+// Package is a QTI 2.1 content package.
+type Package struct {
+	Manifest *Manifest
+	Test     *AssessmentTest
+	Items    []*AssessmentItem
+}
+
+// Export converts qs and writes it to w as a zipped content package.
+func Export(w io.Writer, qs *h5p.QuestionSet, opts Options) error {
+	pkg, err := FromQuestionSet(qs, opts)
+	if err != nil {
+		return err
+	}
+	return pkg.Write(w)
+}
+
+// ExportFile is Export to a new file at path.
+func ExportFile(path string, qs *h5p.QuestionSet, opts Options) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := Export(f, qs, opts); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Write writes the package to w as a zip archive.
+func (p *Package) Write(w io.Writer) error {
+	zw := zip.NewWriter(w)
+	if err := writeXML(zw, ManifestFile, p.Manifest); err != nil {
+		return err
+	}
+	if err := writeXML(zw, TestFile, p.Test); err != nil {
+		return err
+	}
+	for _, item := range p.Items {
+		if err := writeXML(zw, item.href(), item); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func writeXML(zw *zip.Writer, name string, v any) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
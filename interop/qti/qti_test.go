@@ -0,0 +1,218 @@
+package qti
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	h5p "github.com/grokify/h5p-go"
+	"github.com/grokify/h5p-go/schemas"
+)
+
+func testQuestionSet() *h5p.QuestionSet {
+	return &h5p.QuestionSet{
+		Title: "Geography",
+		Questions: []h5p.Question{
+			{
+				Library:      "H5P.MultiChoice 1.16",
+				SubContentID: "3f2c",
+				Metadata:     &h5p.ContentMetadata{Title: "Capitals"},
+				Params: &schemas.MultiChoiceParams{
+					Question: "<p>Capital of France?</p>",
+					Answers: []schemas.AnswerOption{
+						{Text: "Paris", Correct: true},
+						{Text: "Lyon &amp; <b>Nice"},
+					},
+					Behaviour: &schemas.Behaviour{RandomAnswers: true},
+				},
+			},
+			{
+				Library: "H5P.TrueFalse 1.8",
+				Params:  map[string]any{"question": "Rome is in Italy.", "correct": "true"},
+			},
+			{
+				Library: "H5P.Blanks 1.14",
+				Params: &schemas.BlanksParams{
+					Text:      "Fill in the capitals.",
+					Questions: []string{"<p>Germany: *Berlin*, Austria: *Vienna/Wien*</p>"},
+					Behaviour: &schemas.BlanksBehaviour{CaseSensitive: false},
+				},
+			},
+			{
+				Library: "H5P.Essay 1.5",
+				Params:  &schemas.EssayParams{TaskDescription: "Describe the EU.", PlaceholderText: "The EU..."},
+			},
+			{Library: "H5P.DragText 1.10", Params: map[string]any{}},
+		},
+	}
+}
+
+func TestFromQuestionSet(t *testing.T) {
+	if _, err := FromQuestionSet(testQuestionSet(), Options{}); !errors.Is(err, ErrUnsupportedQuestion) {
+		t.Fatalf("Expected ErrUnsupportedQuestion for DragText, got %v", err)
+	}
+
+	pkg, err := FromQuestionSet(testQuestionSet(), Options{Identifier: "geo", SkipUnsupported: true})
+	if err != nil {
+		t.Fatalf("FromQuestionSet failed: %v", err)
+	}
+	var ids []string
+	for _, item := range pkg.Items {
+		ids = append(ids, item.Identifier)
+	}
+	if want := []string{"item-3f2c", "item-2", "item-3", "item-4"}; !reflect.DeepEqual(ids, want) {
+		t.Fatalf("Expected items %v, got %v", want, ids)
+	}
+	if pkg.Items[0].Title != "Capitals" || pkg.Test.Title != "Geography" || pkg.Manifest.Identifier != "MANIFEST-geo" {
+		t.Errorf("Unexpected titles or identifiers: %q %q %q", pkg.Items[0].Title, pkg.Test.Title, pkg.Manifest.Identifier)
+	}
+
+	res := pkg.Manifest.Resources
+	if len(res) != 5 || res[4].Type != TestResource || len(res[4].Dependencies) != 4 || res[0].Href != "items/item-3f2c.xml" {
+		t.Errorf("Unexpected manifest resources: %+v", res)
+	}
+
+	mc := pkg.Items[0]
+	if got := mc.ResponseDeclarations[0].CorrectResponse.Values; !reflect.DeepEqual(got, []string{"choice-1"}) {
+		t.Errorf("Expected choice-1 correct, got %v", got)
+	}
+	for _, want := range []string{`shuffle="true" maxChoices="1"`, "<div><p>Capital of France?</p></div>",
+		`<simpleChoice identifier="choice-2">Lyon &amp; Nice</simpleChoice>`} {
+		if !strings.Contains(mc.ItemBody.XML, want) {
+			t.Errorf("Expected MultiChoice body to contain %q, got %s", want, mc.ItemBody.XML)
+		}
+	}
+
+	if got := pkg.Items[1].ResponseDeclarations[0].CorrectResponse.Values; !reflect.DeepEqual(got, []string{"true"}) {
+		t.Errorf("Expected TrueFalse correct response true, got %v", got)
+	}
+
+	blanks := pkg.Items[2]
+	if len(blanks.ResponseDeclarations) != 2 || len(blanks.ResponseDeclarations[1].Mapping.Entries) != 2 ||
+		blanks.ResponseDeclarations[1].Mapping.Entries[1] != (MapEntry{MapKey: "Wien", MappedValue: "1"}) {
+		t.Errorf("Unexpected Blanks responses: %+v", blanks.ResponseDeclarations)
+	}
+	if want := `<p>Germany: <textEntryInteraction responseIdentifier="RESPONSE_1" expectedLength="6"/>, Austria: ` +
+		`<textEntryInteraction responseIdentifier="RESPONSE_2" expectedLength="6"/></p>`; !strings.Contains(blanks.ItemBody.XML, want) {
+		t.Errorf("Unexpected Blanks body %s", blanks.ItemBody.XML)
+	}
+
+	if !strings.Contains(pkg.Items[3].ItemBody.XML, `placeholderText="The EU..."`) || pkg.Items[3].ResponseProcessing != nil {
+		t.Errorf("Unexpected Essay item: %+v", pkg.Items[3])
+	}
+}
+
+func TestExport(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Export(&buf, testQuestionSet(), Options{SkipUnsupported: true}); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("Failed to open package: %v", err)
+	}
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		dec := xml.NewDecoder(rc)
+		for {
+			if _, err := dec.Token(); err == io.EOF {
+				break
+			} else if err != nil {
+				t.Errorf("%s is not well-formed XML: %v", f.Name, err)
+				break
+			}
+		}
+		rc.Close()
+	}
+	want := []string{ManifestFile, TestFile, "items/item-3f2c.xml", "items/item-2.xml", "items/item-3.xml", "items/item-4.xml"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("Expected files %v, got %v", want, names)
+	}
+}
+
+func TestImportRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Export(&buf, testQuestionSet(), Options{SkipUnsupported: true}); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	qs, err := Import(bytes.NewReader(buf.Bytes()), int64(buf.Len()), Options{})
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if qs.Title != "Geography" || len(qs.Questions) != 4 {
+		t.Fatalf("Expected 4 questions titled Geography, got %d titled %q", len(qs.Questions), qs.Title)
+	}
+	var names []string
+	for _, q := range qs.Questions {
+		names = append(names, q.MachineName())
+	}
+	if want := []string{MultiChoiceLibrary, TrueFalseLibrary, BlanksLibrary, EssayLibrary}; !reflect.DeepEqual(names, want) {
+		t.Errorf("Expected libraries %v, got %v", want, names)
+	}
+
+	var mc schemas.MultiChoiceParams
+	if err := qs.Questions[0].DecodeParams(&mc); err != nil {
+		t.Fatal(err)
+	}
+	want := schemas.MultiChoiceParams{
+		Question:  "<p>Capital of France?</p>",
+		Answers:   []schemas.AnswerOption{{Text: "Paris", Correct: true}, {Text: "Lyon &amp; Nice"}},
+		Behaviour: &schemas.Behaviour{Type: "single", RandomAnswers: true},
+	}
+	if qs.Questions[0].Metadata.Title != "Capitals" || !reflect.DeepEqual(mc, want) {
+		t.Errorf("Expected %+v, got %+v", want, mc)
+	}
+
+	var tf schemas.TrueFalseParams
+	if err := qs.Questions[1].DecodeParams(&tf); err != nil {
+		t.Fatal(err)
+	}
+	if tf.Question != "Rome is in Italy." || !tf.IsTrue() {
+		t.Errorf("Unexpected true/false question %+v", tf)
+	}
+
+	var blanks schemas.BlanksParams
+	if err := qs.Questions[2].DecodeParams(&blanks); err != nil {
+		t.Fatal(err)
+	}
+	wantBlanks := []string{"<p>Germany: *Berlin*, Austria: *Vienna/Wien*</p>"}
+	if blanks.Text != "Fill in the capitals." || !reflect.DeepEqual(blanks.Questions, wantBlanks) || blanks.Behaviour.CaseSensitive {
+		t.Errorf("Unexpected blanks %+v", blanks)
+	}
+
+	var essay schemas.EssayParams
+	if err := qs.Questions[3].DecodeParams(&essay); err != nil {
+		t.Fatal(err)
+	}
+	if essay.TaskDescription != "Describe the EU." || essay.PlaceholderText != "The EU..." {
+		t.Errorf("Unexpected essay %+v", essay)
+	}
+}
+
+func TestImportUnsupported(t *testing.T) {
+	pkg := &Package{Items: []*AssessmentItem{
+		{Identifier: "order", ItemBody: ItemBody{XML: `<div>Sort</div><orderInteraction responseIdentifier="RESPONSE"/>`}},
+		{Identifier: "text", ItemBody: ItemBody{XML: `<p>No interaction</p>`}},
+	}}
+	if _, err := pkg.ToQuestionSet(Options{}); !errors.Is(err, ErrUnsupportedQuestion) {
+		t.Errorf("Expected ErrUnsupportedQuestion, got %v", err)
+	}
+	qs, err := pkg.ToQuestionSet(Options{SkipUnsupported: true})
+	if err != nil || len(qs.Questions) != 0 {
+		t.Errorf("Expected no questions, got %v, %v", qs, err)
+	}
+
+	if _, err := Import(strings.NewReader("not a zip"), 9, Options{}); !errors.Is(err, ErrInvalidPackage) {
+		t.Errorf("Expected ErrInvalidPackage, got %v", err)
+	}
+}
@@ -0,0 +1,343 @@
+// Package quizgame imports the quiz spreadsheets of Kahoot and Quizizz as
+// H5P question sets of MultiChoice questions.
+//
+// Kahoot's quiz template has a header row with "Question", "Answer 1" to
+// "Answer 4", "Time limit (sec)" and "Correct answer(s)" columns, below a
+// few rows of instructions. Quizizz's template starts with a "Question
+// Text", "Question Type", "Option 1" to "Option 5", "Correct Answer", "Time
+// in seconds", "Image Link" and "Answer explanation" header. Correct answers
+// are 1-based option numbers separated by commas.
+//
+// H5P MultiChoice has no time limit, so limits are returned in
+// Quiz.TimeLimits for players or wrappers that enforce them. The all-or-
+// nothing scoring of both games is kept with behaviour.singlePoint.
+package quizgame
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	h5p "github.com/grokify/h5p-go"
+	"github.com/grokify/h5p-go/interop/xlsximport"
+	"github.com/grokify/h5p-go/schemas"
+)
+
+// Spreadsheet formats.
+const (
+	FormatKahoot  = "kahoot"
+	FormatQuizizz = "quizizz"
+)
+
+// Quizizz question types.
+const (
+	TypeMultipleChoice = "multiple choice"
+	TypeCheckbox       = "checkbox"
+)
+
+// ImageLibrary is the library used for Quizizz image links.
+const ImageLibrary = "H5P.Image 1.1"
+
+// headerSearchRows is how far down the header row is looked for.
+const headerSearchRows = 30
+
+var (
+	ErrUnknownFormat       = errors.New("spreadsheet is neither a Kahoot nor a Quizizz quiz")
+	ErrUnsupportedQuestion = errors.New("question type is not supported")
+)
+
+// Options controls the import.
+type Options struct {
+	// Format is FormatKahoot or FormatQuizizz; it is detected from the
+	// header row if empty.
+	Format string
+
+	// Title names the question set. It defaults to the sheet name.
+	Title string
+
+	// SkipUnsupported leaves out Quizizz questions other than multiple
+	// choice and checkbox instead of failing with ErrUnsupportedQuestion.
+	SkipUnsupported bool
+}
+
+// Quiz is an imported quiz.
+type Quiz struct {
+	Format      string
+	QuestionSet *h5p.QuestionSet
+
+	// TimeLimits maps question subContentIds to their time limit.
+	TimeLimits map[string]time.Duration
+}
+
+// ReadFile imports the workbook at path.
+func ReadFile(path string, opts Options) (*Quiz, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return Read(f, info.Size(), opts)
+}
+
+// Read imports the first worksheet of an .xlsx workbook that has a
+// recognized header row.
+func Read(r io.ReaderAt, size int64, opts Options) (*Quiz, error) {
+	sheets, err := xlsximport.ReadSheets(r, size)
+	if err != nil {
+		return nil, err
+	}
+	for _, sheet := range sheets {
+		if _, _, ok := findHeader(sheet.Rows, opts.Format); ok {
+			return FromRows(sheet.Name, sheet.Rows, opts)
+		}
+	}
+	return nil, ErrUnknownFormat
+}
+
+// FromRows imports the rows of one sheet. Problems are returned together as
+// xlsximport.Errors.
+func FromRows(sheet string, rows [][]string, opts Options) (*Quiz, error) {
+	format, header, ok := findHeader(rows, opts.Format)
+	if !ok {
+		return nil, ErrUnknownFormat
+	}
+	title := opts.Title
+	if title == "" {
+		title = sheet
+	}
+	imp := importer{sheet: sheet, format: format, columns: mapColumns(rows[header], format), opts: opts}
+
+	qs := &h5p.QuestionSet{Title: title}
+	var limits []time.Duration
+	for i := header + 1; i < len(rows); i++ {
+		q, limit, ok := imp.row(i+1, rows[i])
+		if ok {
+			qs.Questions = append(qs.Questions, q)
+			limits = append(limits, limit)
+		}
+	}
+	if len(imp.errs) > 0 {
+		return nil, imp.errs
+	}
+	if len(qs.Questions) == 0 {
+		return nil, xlsximport.Errors{{Sheet: sheet, Message: "no questions found"}}
+	}
+	qs.EnsureSubContentIDs()
+
+	quiz := &Quiz{Format: format, QuestionSet: qs, TimeLimits: map[string]time.Duration{}}
+	for i, limit := range limits {
+		if limit > 0 {
+			quiz.TimeLimits[qs.Questions[i].SubContentID] = limit
+		}
+	}
+	return quiz, nil
+}
+
+// Field names of the columns.
+const (
+	fieldQuestion    = "question"
+	fieldType        = "type"
+	fieldCorrect     = "correct"
+	fieldTime        = "time"
+	fieldImage       = "image"
+	fieldExplanation = "explanation"
+	fieldAnswer      = "answer"
+)
+
+// columns maps fields to column indexes; answers holds the answer columns
+// in option order.
+type columns struct {
+	fields  map[string]int
+	answers []int
+}
+
+// findHeader returns the format and index of the header row.
+func findHeader(rows [][]string, format string) (string, int, bool) {
+	for i := 0; i < len(rows) && i < headerSearchRows; i++ {
+		if f := headerFormat(rows[i]); f != "" && (format == "" || format == f) {
+			return f, i, true
+		}
+	}
+	return "", 0, false
+}
+
+func headerFormat(row []string) string {
+	var question, kahootAnswer, quizizzOption bool
+	for _, cell := range row {
+		c := normalize(cell)
+		switch {
+		case c == "question text":
+			question = true
+		case strings.HasPrefix(c, "question"):
+			question = question || !strings.HasPrefix(c, "question type")
+		case strings.HasPrefix(c, "answer 1"):
+			kahootAnswer = true
+		case c == "option 1":
+			quizizzOption = true
+		}
+	}
+	switch {
+	case question && quizizzOption:
+		return FormatQuizizz
+	case question && kahootAnswer:
+		return FormatKahoot
+	}
+	return ""
+}
+
+func mapColumns(header []string, format string) columns {
+	cols := columns{fields: map[string]int{}}
+	set := func(field string, i int) {
+		if _, ok := cols.fields[field]; !ok {
+			cols.fields[field] = i
+		}
+	}
+	for i, cell := range header {
+		c := normalize(cell)
+		switch {
+		case strings.HasPrefix(c, "question type"):
+			set(fieldType, i)
+		case strings.HasPrefix(c, "question"):
+			set(fieldQuestion, i)
+		case strings.HasPrefix(c, "answer explanation"):
+			set(fieldExplanation, i)
+		case strings.HasPrefix(c, "correct answer"):
+			set(fieldCorrect, i)
+		case strings.HasPrefix(c, "time"):
+			set(fieldTime, i)
+		case strings.HasPrefix(c, "image"):
+			set(fieldImage, i)
+		case format == FormatKahoot && strings.HasPrefix(c, "answer "),
+			format == FormatQuizizz && strings.HasPrefix(c, "option "):
+			cols.answers = append(cols.answers, i)
+		}
+	}
+	return cols
+}
+
+// normalize lowercases a header cell and collapses whitespace.
+func normalize(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+}
+
+type importer struct {
+	sheet   string
+	format  string
+	columns columns
+	opts    Options
+	errs    xlsximport.Errors
+}
+
+func (imp *importer) errorf(row, col int, field, format string, args ...any) {
+	e := xlsximport.CellError{Sheet: imp.sheet, Row: row, Field: field, Message: fmt.Sprintf(format, args...)}
+	if col >= 0 {
+		e.Column = xlsximport.ColumnName(col)
+	}
+	imp.errs = append(imp.errs, e)
+}
+
+// row converts one data row. Rows without question text are skipped.
+func (imp *importer) row(num int, record []string) (h5p.Question, time.Duration, bool) {
+	get := func(field string) (string, int) {
+		i, ok := imp.columns.fields[field]
+		if !ok {
+			return "", -1
+		}
+		if i < len(record) {
+			return strings.TrimSpace(record[i]), i
+		}
+		return "", i
+	}
+	question, qcol := get(fieldQuestion)
+	if question == "" {
+		return h5p.Question{}, 0, false
+	}
+	nerrs := len(imp.errs)
+
+	if imp.format == FormatQuizizz {
+		typ, col := get(fieldType)
+		switch normalize(typ) {
+		case "", TypeMultipleChoice, TypeCheckbox:
+		default:
+			if imp.opts.SkipUnsupported {
+				return h5p.Question{}, 0, false
+			}
+			imp.errorf(num, col, fieldType, "%v: %s", ErrUnsupportedQuestion, typ)
+			return h5p.Question{}, 0, false
+		}
+	}
+
+	p := &schemas.MultiChoiceParams{Question: question}
+	optionIndex := map[int]int{} // option number to answer index
+	for n, i := range imp.columns.answers {
+		if i < len(record) && strings.TrimSpace(record[i]) != "" {
+			optionIndex[n+1] = len(p.Answers)
+			p.Answers = append(p.Answers, schemas.AnswerOption{Text: strings.TrimSpace(record[i])})
+		}
+	}
+
+	correct, ccol := get(fieldCorrect)
+	numCorrect := 0
+	for _, s := range strings.FieldsFunc(correct, func(r rune) bool { return r == ',' || r == ';' || r == ' ' }) {
+		n, err := strconv.Atoi(s)
+		idx, ok := optionIndex[n]
+		if err != nil || !ok {
+			imp.errorf(num, ccol, fieldCorrect, "%q is not the number of a filled-in answer", s)
+			continue
+		}
+		if !p.Answers[idx].Correct {
+			p.Answers[idx].Correct = true
+			numCorrect++
+		}
+	}
+	p.Behaviour = &schemas.Behaviour{Type: "single", SinglePoint: true}
+	if numCorrect > 1 {
+		p.Behaviour.Type = "multi"
+	}
+
+	if image, _ := get(fieldImage); image != "" {
+		p.Media = &schemas.MediaGroup{Type: &schemas.MediaContent{
+			Library: ImageLibrary,
+			Params:  &schemas.ImageParams{File: &schemas.ImageFile{Path: image}, Alt: question},
+		}}
+	}
+	if explanation, _ := get(fieldExplanation); explanation != "" {
+		p.OverallFeedback = &schemas.OverallFeedback{OverallFeedback: []schemas.FeedbackRange{
+			{From: 0, To: 100, Feedback: explanation},
+		}}
+	}
+
+	var limit time.Duration
+	if t, col := get(fieldTime); t != "" {
+		seconds, err := strconv.ParseFloat(t, 64)
+		if err != nil || seconds <= 0 {
+			imp.errorf(num, col, fieldTime, "invalid time limit %q", t)
+		} else {
+			limit = time.Duration(seconds * float64(time.Second))
+		}
+	}
+
+	for _, e := range p.ValidateAll().Errors() {
+		field, col := fieldAnswer, -1
+		switch {
+		case e.Code == schemas.CodeNoCorrect:
+			field, col = fieldCorrect, ccol
+		case e.Path == "question":
+			field, col = fieldQuestion, qcol
+		}
+		imp.errorf(num, col, field, "%s", e.Message)
+	}
+	if len(imp.errs) > nerrs {
+		return h5p.Question{}, 0, false
+	}
+	library, _ := h5p.LatestLibraryString("H5P.MultiChoice")
+	return h5p.Question{Library: library, Params: p}, limit, true
+}
@@ -0,0 +1,172 @@
+package quizgame
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grokify/h5p-go/interop/xlsximport"
+	"github.com/grokify/h5p-go/schemas"
+)
+
+var kahootRows = [][]string{
+	{"Quiz template"},
+	{"Add questions, at least two answer alternatives, time limit and choose correct answers (at least one)."},
+	nil,
+	{"", "Question - max 120 characters", "Answer 1 - max 75 characters", "Answer 2 - max 75 characters", "Answer 3 - max 75 characters", "Answer 4 - max 75 characters", "Time limit (sec) – 5, 10, 20, 30, 60, 90, 120, or 240 secs", "Correct answer(s) - choose at least one"},
+	{"1", "Capital of France?", "Lyon", "Paris", "", "Nice", "20", "2"},
+	{"2", "Prime numbers?", "2", "4", "5", "9", "30", "1,3"},
+	{"3"},
+}
+
+var quizizzRows = [][]string{
+	{"Question Text", "Question Type", "Option 1", "Option 2", "Option 3", "Option 4", "Option 5", "Correct Answer", "Time in seconds", "Image Link", "Answer explanation"},
+	{"Largest planet?", "Multiple Choice", "Mars", "Jupiter", "", "", "", "2", "30", "https://example.com/jupiter.png", "Jupiter is a gas giant."},
+	{"Favourite planet?", "Poll", "Mars", "Venus", "", "", "", "", "10", "", ""},
+	{"Gas giants?", "Checkbox", "Jupiter", "Earth", "Saturn", "", "", "1, 3", "", "", ""},
+}
+
+func multiChoice(t *testing.T, q any) *schemas.MultiChoiceParams {
+	t.Helper()
+	p, ok := q.(*schemas.MultiChoiceParams)
+	if !ok {
+		t.Fatalf("params are %T", q)
+	}
+	return p
+}
+
+func TestKahoot(t *testing.T) {
+	quiz, err := FromRows("Sheet1", kahootRows, Options{Title: "Geography"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	qs := quiz.QuestionSet
+	if quiz.Format != FormatKahoot || qs.Title != "Geography" || len(qs.Questions) != 2 {
+		t.Fatalf("quiz = %+v", quiz)
+	}
+	p := multiChoice(t, qs.Questions[0].Params)
+	if len(p.Answers) != 3 || p.Answers[1].Text != "Paris" || !p.Answers[1].Correct || p.Answers[0].Correct || p.Behaviour.Type != "single" || !p.Behaviour.SinglePoint {
+		t.Errorf("question 1 = %+v", p)
+	}
+	p = multiChoice(t, qs.Questions[1].Params)
+	if !p.Answers[0].Correct || !p.Answers[2].Correct || p.Behaviour.Type != "multi" {
+		t.Errorf("question 2 = %+v", p)
+	}
+	if got := quiz.TimeLimits[qs.Questions[1].SubContentID]; got != 30*time.Second {
+		t.Errorf("time limit = %v", got)
+	}
+	if qs.Questions[0].Library != "H5P.MultiChoice 1.16" {
+		t.Errorf("library = %q", qs.Questions[0].Library)
+	}
+}
+
+func TestQuizizz(t *testing.T) {
+	_, err := FromRows("Quiz", quizizzRows, Options{})
+	var errs xlsximport.Errors
+	if !errors.As(err, &errs) || len(errs) != 1 || errs[0].Column != "B" || errs[0].Row != 3 {
+		t.Fatalf("Expected an unsupported question error at B3, got %v", err)
+	}
+
+	quiz, err := FromRows("Quiz", quizizzRows, Options{SkipUnsupported: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	qs := quiz.QuestionSet
+	if quiz.Format != FormatQuizizz || qs.Title != "Quiz" || len(qs.Questions) != 2 {
+		t.Fatalf("quiz = %+v", quiz)
+	}
+	p := multiChoice(t, qs.Questions[0].Params)
+	img := p.Media.Type.Params.(*schemas.ImageParams)
+	if img.File.Path != "https://example.com/jupiter.png" || p.OverallFeedback.OverallFeedback[0].Feedback != "Jupiter is a gas giant." {
+		t.Errorf("question 1 = %+v", p)
+	}
+	if p = multiChoice(t, qs.Questions[1].Params); p.Behaviour.Type != "multi" {
+		t.Errorf("question 2 = %+v", p)
+	}
+	if len(quiz.TimeLimits) != 1 {
+		t.Errorf("time limits = %v", quiz.TimeLimits)
+	}
+}
+
+func TestFromRowsErrors(t *testing.T) {
+	rows := [][]string{
+		kahootRows[3],
+		{"1", "No answer marked?", "A", "B", "", "", "20", ""},
+		{"2", "Bad refs", "A", "B", "", "", "soon", "3,x"},
+	}
+	_, err := FromRows("Sheet1", rows, Options{})
+	var errs xlsximport.Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Expected xlsximport.Errors, got %v", err)
+	}
+	var got []string
+	for _, e := range errs {
+		got = append(got, fmt.Sprintf("%s%d %s", e.Column, e.Row, e.Field))
+	}
+	want := "H2 correct|H3 correct|H3 correct|G3 time|H3 correct"
+	if strings.Join(got, "|") != want {
+		t.Errorf("errors at %v, want %s\n%v", got, want, err)
+	}
+
+	if _, err := FromRows("Sheet1", [][]string{{"a", "b"}}, Options{}); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("Expected ErrUnknownFormat, got %v", err)
+	}
+	if _, err := FromRows("Sheet1", kahootRows, Options{Format: FormatQuizizz}); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("Expected ErrUnknownFormat for a forced format, got %v", err)
+	}
+}
+
+// inlineWorkbook returns an .xlsx workbook with inline string cells.
+func inlineWorkbook(t *testing.T, sheets map[string][][]string, order []string) *bytes.Reader {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	write := func(name, content string) {
+		w, err := zw.Create(name)
+		if err == nil {
+			_, err = w.Write([]byte(content))
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	var wb, rels strings.Builder
+	for i, name := range order {
+		fmt.Fprintf(&wb, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, name, i+1, i+1)
+		fmt.Fprintf(&rels, `<Relationship Id="rId%d" Target="worksheets/sheet%d.xml"/>`, i+1, i+1)
+		var ws strings.Builder
+		for r, row := range sheets[name] {
+			fmt.Fprintf(&ws, `<row r="%d">`, r+1)
+			for c, cell := range row {
+				fmt.Fprintf(&ws, `<c r="%s%d" t="inlineStr"><is><t>%s</t></is></c>`, xlsximport.ColumnName(c), r+1, html.EscapeString(cell))
+			}
+			ws.WriteString(`</row>`)
+		}
+		write(fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`+ws.String()+`</sheetData></worksheet>`)
+	}
+	write("xl/workbook.xml", `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`+wb.String()+`</sheets></workbook>`)
+	write("xl/_rels/workbook.xml.rels", `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`+rels.String()+`</Relationships>`)
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return bytes.NewReader(buf.Bytes())
+}
+
+func TestRead(t *testing.T) {
+	r := inlineWorkbook(t, map[string][][]string{
+		"Notes":   {{"Exported from Kahoot"}},
+		"Kahoot!": kahootRows,
+	}, []string{"Notes", "Kahoot!"})
+	quiz, err := Read(r, r.Size(), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if quiz.QuestionSet.Title != "Kahoot!" || len(quiz.QuestionSet.Questions) != 2 {
+		t.Errorf("quiz = %+v", quiz.QuestionSet)
+	}
+}
@@ -0,0 +1,204 @@
+package xlsximport
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// maxPartSize limits the decompressed size of each workbook part read.
+const maxPartSize = 256 << 20
+
+// Sheet is a worksheet read as rows of cell text. Rows[i] holds sheet row
+// i+1; missing rows and cells are empty.
+type Sheet struct {
+	Name string
+	Rows [][]string
+}
+
+type workbookXML struct {
+	Sheets []struct {
+		Name string `xml:"name,attr"`
+		ID   string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type relationshipsXML struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type richTextXML struct {
+	T    string `xml:"t"`
+	Runs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (rt richTextXML) text() string {
+	if len(rt.Runs) == 0 {
+		return rt.T
+	}
+	var b strings.Builder
+	for _, r := range rt.Runs {
+		b.WriteString(r.T)
+	}
+	return b.String()
+}
+
+type sharedStringsXML struct {
+	Items []richTextXML `xml:"si"`
+}
+
+type worksheetXML struct {
+	Rows []struct {
+		R     int `xml:"r,attr"`
+		Cells []struct {
+			R      string      `xml:"r,attr"`
+			T      string      `xml:"t,attr"`
+			V      string      `xml:"v"`
+			Inline richTextXML `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// readSheets reads every worksheet of the workbook in zr, in workbook order.
+func readSheets(zr *zip.Reader) ([]Sheet, error) {
+	files := map[string]*zip.File{}
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	var wb workbookXML
+	if err := decodePart(files, "xl/workbook.xml", &wb); err != nil {
+		return nil, err
+	}
+	var rels relationshipsXML
+	if err := decodePart(files, "xl/_rels/workbook.xml.rels", &rels); err != nil {
+		return nil, err
+	}
+	targets := map[string]string{}
+	for _, r := range rels.Relationships {
+		target := r.Target
+		if strings.HasPrefix(target, "/") {
+			target = strings.TrimPrefix(target, "/")
+		} else {
+			target = path.Join("xl", target)
+		}
+		targets[r.ID] = target
+	}
+	var shared sharedStringsXML
+	if _, ok := files["xl/sharedStrings.xml"]; ok {
+		if err := decodePart(files, "xl/sharedStrings.xml", &shared); err != nil {
+			return nil, err
+		}
+	}
+
+	var sheets []Sheet
+	for _, s := range wb.Sheets {
+		target, ok := targets[s.ID]
+		if !ok {
+			return nil, fmt.Errorf("sheet %q: missing relationship %s", s.Name, s.ID)
+		}
+		var ws worksheetXML
+		if err := decodePart(files, target, &ws); err != nil {
+			return nil, fmt.Errorf("sheet %q: %w", s.Name, err)
+		}
+		rows, err := ws.rows(shared)
+		if err != nil {
+			return nil, fmt.Errorf("sheet %q: %w", s.Name, err)
+		}
+		sheets = append(sheets, Sheet{Name: s.Name, Rows: rows})
+	}
+	return sheets, nil
+}
+
+// rows returns the cell text of the worksheet, resolving shared strings,
+// inline strings and booleans.
+func (ws *worksheetXML) rows(shared sharedStringsXML) ([][]string, error) {
+	var rows [][]string
+	for _, row := range ws.Rows {
+		r := row.R
+		if r == 0 {
+			r = len(rows) + 1
+		}
+		for len(rows) < r {
+			rows = append(rows, nil)
+		}
+		var cells []string
+		for _, c := range row.Cells {
+			col := len(cells)
+			if c.R != "" {
+				var err error
+				if col, err = columnIndex(c.R); err != nil {
+					return nil, err
+				}
+			}
+			var text string
+			switch c.T {
+			case "s":
+				i, err := strconv.Atoi(strings.TrimSpace(c.V))
+				if err != nil || i < 0 || i >= len(shared.Items) {
+					return nil, fmt.Errorf("cell %s: invalid shared string index %q", c.R, c.V)
+				}
+				text = shared.Items[i].text()
+			case "inlineStr":
+				text = c.Inline.text()
+			case "b":
+				text = strconv.FormatBool(strings.TrimSpace(c.V) == "1")
+			default:
+				text = c.V
+			}
+			for len(cells) <= col {
+				cells = append(cells, "")
+			}
+			cells[col] = text
+		}
+		rows[r-1] = cells
+	}
+	return rows, nil
+}
+
+// columnIndex returns the 0-based column of a cell reference such as "C12".
+func columnIndex(ref string) (int, error) {
+	col := 0
+	i := 0
+	for ; i < len(ref) && ref[i] >= 'A' && ref[i] <= 'Z'; i++ {
+		col = col*26 + int(ref[i]-'A'+1)
+	}
+	if i == 0 || col > 1<<14 {
+		return 0, fmt.Errorf("invalid cell reference %q", ref)
+	}
+	return col - 1, nil
+}
+
+// ColumnName returns the letters of the 0-based column index, e.g. "C".
+func ColumnName(index int) string {
+	var b []byte
+	for index++; index > 0; index = (index - 1) / 26 {
+		b = append([]byte{byte('A' + (index-1)%26)}, b...)
+	}
+	return string(b)
+}
+
+func decodePart(files map[string]*zip.File, name string, v any) error {
+	f, ok := files[name]
+	if !ok {
+		return fmt.Errorf("%w: missing %s", ErrInvalidWorkbook, name)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	if err := xml.NewDecoder(io.LimitReader(rc, maxPartSize)).Decode(v); err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrInvalidWorkbook, name, err)
+	}
+	return nil
+}
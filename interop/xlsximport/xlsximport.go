@@ -0,0 +1,174 @@
+// Package xlsximport builds H5P question sets from Excel .xlsx workbooks,
+// one question set per worksheet, using the column layout of package
+// csvimport. Problems are reported per cell with sheet, row and column.
+package xlsximport
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	h5p "github.com/grokify/h5p-go"
+	"github.com/grokify/h5p-go/interop/csvimport"
+)
+
+var (
+	ErrInvalidWorkbook = errors.New("invalid xlsx workbook")
+	ErrUnknownSheet    = errors.New("unknown worksheet")
+)
+
+// Options controls the import.
+type Options struct {
+	// Sheets lists the worksheets to import, all if empty.
+	Sheets []string
+	// Headers maps header cell text to csvimport column names, e.g.
+	// {"Frage": "question"}, for header rows using other names. Keys are
+	// matched ignoring case.
+	Headers map[string]string
+	// AnswerSeparator separates values within a cell, "|" if empty.
+	AnswerSeparator string
+	// Columns is the column order of sheets without a header row,
+	// csvimport.DefaultColumns if empty.
+	Columns []string
+}
+
+// Quiz is the question set read from one worksheet, titled after it.
+type Quiz struct {
+	Sheet       string
+	QuestionSet *h5p.QuestionSet
+}
+
+// CellError locates a problem in the workbook. Row is the 1-based sheet row
+// and Column the column letters; both are empty for problems with the
+// sheet as a whole. Field is the csvimport column name.
+type CellError struct {
+	Sheet   string `json:"sheet"`
+	Row     int    `json:"row,omitempty"`
+	Column  string `json:"column,omitempty"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+func (e CellError) Error() string {
+	switch {
+	case e.Row == 0:
+		return fmt.Sprintf("%s: %s", e.Sheet, e.Message)
+	case e.Column == "":
+		return fmt.Sprintf("%s row %d: %s", e.Sheet, e.Row, e.Message)
+	}
+	return fmt.Sprintf("%s!%s%d (%s): %s", e.Sheet, e.Column, e.Row, e.Field, e.Message)
+}
+
+// Errors collects every problem found in the workbook.
+type Errors []CellError
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, ce := range e {
+		msgs[i] = ce.Error()
+	}
+	return fmt.Sprintf("%d import error(s): %s", len(e), strings.Join(msgs, "; "))
+}
+
+// ReadFile imports the workbook at path.
+func ReadFile(path string, opts Options) ([]Quiz, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return Read(f, info.Size(), opts)
+}
+
+// Read imports a workbook of the given size from r. All problems across the
+// selected sheets are returned together as Errors.
+func Read(r io.ReaderAt, size int64, opts Options) ([]Quiz, error) {
+	sheets, err := ReadSheets(r, size)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range opts.Sheets {
+		if !slices.ContainsFunc(sheets, func(s Sheet) bool { return s.Name == name }) {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownSheet, name)
+		}
+	}
+
+	var quizzes []Quiz
+	var errs Errors
+	for _, sheet := range sheets {
+		if len(opts.Sheets) > 0 && !slices.Contains(opts.Sheets, sheet.Name) {
+			continue
+		}
+		qs, sheetErrs := importSheet(sheet, opts)
+		errs = append(errs, sheetErrs...)
+		if qs != nil {
+			quizzes = append(quizzes, Quiz{Sheet: sheet.Name, QuestionSet: qs})
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return quizzes, nil
+}
+
+// ReadSheets returns the cell text of every worksheet, in workbook order,
+// for importers of other spreadsheet layouts.
+func ReadSheets(r io.ReaderAt, size int64) ([]Sheet, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWorkbook, err)
+	}
+	return readSheets(zr)
+}
+
+func importSheet(sheet Sheet, opts Options) (*h5p.QuestionSet, Errors) {
+	records := sheet.Rows
+	if len(records) > 0 && len(opts.Headers) > 0 {
+		records = slices.Clone(records)
+		records[0] = mapHeader(records[0], opts.Headers)
+	}
+	csvOpts := csvimport.Options{
+		AnswerSeparator: opts.AnswerSeparator,
+		Columns:         opts.Columns,
+		Title:           sheet.Name,
+	}
+	qs, err := csvimport.FromRecords(records, csvOpts)
+	if err == nil {
+		return qs, nil
+	}
+
+	var csvErrs csvimport.Errors
+	if !errors.As(err, &csvErrs) {
+		return nil, Errors{{Sheet: sheet.Name, Message: err.Error()}}
+	}
+	columns, _ := csvimport.Columns(records, csvOpts)
+	errs := make(Errors, len(csvErrs))
+	for i, ce := range csvErrs {
+		errs[i] = CellError{Sheet: sheet.Name, Row: ce.Row, Field: ce.Column, Message: ce.Message}
+		if idx := slices.Index(columns, ce.Column); idx >= 0 && ce.Row > 0 {
+			errs[i].Column = ColumnName(idx)
+		}
+	}
+	return nil, errs
+}
+
+// mapHeader renames header cells found in headers.
+func mapHeader(row []string, headers map[string]string) []string {
+	mapped := slices.Clone(row)
+	for i, cell := range row {
+		for from, to := range headers {
+			if strings.EqualFold(strings.TrimSpace(cell), from) {
+				mapped[i] = to
+			}
+		}
+	}
+	return mapped
+}
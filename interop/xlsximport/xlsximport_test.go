@@ -0,0 +1,163 @@
+package xlsximport
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/grokify/h5p-go/schemas"
+)
+
+// testWorkbook builds an .xlsx with one worksheet per entry of sheets. Cells
+// are written as shared strings, except "TRUE"/"FALSE" which become boolean
+// cells and values prefixed with "=" which become inline strings.
+func testWorkbook(t *testing.T, names []string, sheets [][][]string) *bytes.Reader {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	write := func(name, content string) {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var shared []string
+	var wb, rels strings.Builder
+	wb.WriteString(`<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`)
+	rels.WriteString(`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+	for i, rows := range sheets {
+		fmt.Fprintf(&wb, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, names[i], i+1, i+1)
+		fmt.Fprintf(&rels, `<Relationship Id="rId%d" Type="worksheet" Target="worksheets/sheet%d.xml"/>`, i+1, i+1)
+
+		var ws strings.Builder
+		ws.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+		for r, row := range rows {
+			if row == nil {
+				continue
+			}
+			fmt.Fprintf(&ws, `<row r="%d">`, r+1)
+			for c, cell := range row {
+				ref := fmt.Sprintf("%s%d", ColumnName(c), r+1)
+				switch {
+				case cell == "":
+				case cell == "TRUE" || cell == "FALSE":
+					v := 0
+					if cell == "TRUE" {
+						v = 1
+					}
+					fmt.Fprintf(&ws, `<c r="%s" t="b"><v>%d</v></c>`, ref, v)
+				case strings.HasPrefix(cell, "="):
+					fmt.Fprintf(&ws, `<c r="%s" t="inlineStr"><is><t>%s</t></is></c>`, ref, cell[1:])
+				default:
+					fmt.Fprintf(&ws, `<c r="%s" t="s"><v>%d</v></c>`, ref, len(shared))
+					shared = append(shared, cell)
+				}
+			}
+			ws.WriteString(`</row>`)
+		}
+		ws.WriteString(`</sheetData></worksheet>`)
+		write(fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), ws.String())
+	}
+	wb.WriteString(`</sheets></workbook>`)
+	rels.WriteString(`</Relationships>`)
+
+	var ss strings.Builder
+	ss.WriteString(`<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	for _, s := range shared {
+		fmt.Fprintf(&ss, `<si><t>%s</t></si>`, s)
+	}
+	ss.WriteString(`</sst>`)
+
+	write("xl/workbook.xml", wb.String())
+	write("xl/_rels/workbook.xml.rels", rels.String())
+	write("xl/sharedStrings.xml", ss.String())
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return bytes.NewReader(buf.Bytes())
+}
+
+func TestRead(t *testing.T) {
+	r := testWorkbook(t, []string{"Capitals", "Facts"}, [][][]string{
+		{
+			{"Frage", "Answers", "Correct"},
+			{"Capital of France?", "Paris|Lyon", "1|0"},
+			nil,
+			{"=Capital of Spain?", "Madrid|Porto", "x|"},
+		},
+		{
+			{"truefalse", "The sun is a star.", "", "TRUE"},
+		},
+	})
+	quizzes, err := Read(r, r.Size(), Options{Headers: map[string]string{"frage": "question"}})
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if len(quizzes) != 2 || quizzes[0].Sheet != "Capitals" || quizzes[0].QuestionSet.Title != "Capitals" {
+		t.Fatalf("Unexpected quizzes: %+v", quizzes)
+	}
+	capitals := quizzes[0].QuestionSet.Questions
+	if len(capitals) != 2 {
+		t.Fatalf("Expected 2 questions, got %d", len(capitals))
+	}
+	if p := capitals[1].Params.(*schemas.MultiChoiceParams); p.Question != "Capital of Spain?" || !p.Answers[0].Correct {
+		t.Errorf("Unexpected params: %+v", p)
+	}
+	if p := quizzes[1].QuestionSet.Questions[0].Params.(*schemas.TrueFalseParams); !p.IsTrue() {
+		t.Errorf("Expected boolean cell to mark true, got %+v", p)
+	}
+
+	quizzes, err = Read(r, r.Size(), Options{Sheets: []string{"Facts"}})
+	if err != nil || len(quizzes) != 1 || quizzes[0].Sheet != "Facts" {
+		t.Errorf("Expected only Facts sheet, got %+v, %v", quizzes, err)
+	}
+	if _, err := Read(r, r.Size(), Options{Sheets: []string{"Missing"}}); !errors.Is(err, ErrUnknownSheet) {
+		t.Errorf("Expected ErrUnknownSheet, got %v", err)
+	}
+}
+
+func TestReadCellErrors(t *testing.T) {
+	r := testWorkbook(t, []string{"Quiz"}, [][][]string{{
+		{"question", "type", "answers", "correct"},
+		{"Q1", "multichoice", "A|B", "1|maybe"},
+		{"", "truefalse", "", "TRUE"},
+	}})
+	_, err := Read(r, r.Size(), Options{})
+	var errs Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Expected Errors, got %v", err)
+	}
+	want := Errors{
+		{Sheet: "Quiz", Row: 2, Column: "D", Field: "correct", Message: `invalid flag "maybe" for answer 2`},
+		{Sheet: "Quiz", Row: 3, Column: "A", Field: "question", Message: "question text is required"},
+	}
+	if !reflect.DeepEqual(errs, want) {
+		t.Errorf("Expected %v, got %v", want, errs)
+	}
+	if got := errs[0].Error(); got != `Quiz!D2 (correct): invalid flag "maybe" for answer 2` {
+		t.Errorf("Unexpected error text %q", got)
+	}
+
+	if _, err := Read(bytes.NewReader([]byte("not a zip")), 9, Options{}); !errors.Is(err, ErrInvalidWorkbook) {
+		t.Errorf("Expected ErrInvalidWorkbook, got %v", err)
+	}
+}
+
+func TestColumnName(t *testing.T) {
+	for i, want := range map[int]string{0: "A", 25: "Z", 26: "AA", 701: "ZZ", 702: "AAA"} {
+		if got := ColumnName(i); got != want {
+			t.Errorf("ColumnName(%d) = %q, want %q", i, got, want)
+		}
+		if got, err := columnIndex(want + "1"); err != nil || got != i {
+			t.Errorf("columnIndex(%q) = %d, %v, want %d", want+"1", got, err, i)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package h5p
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/grokify/h5p-go/schemas"
+)
+
+// Language validation problem codes.
+const (
+	CodeInvalidLanguage     = "invalid_language"
+	CodeUnsupportedLanguage = "unsupported_language"
+)
+
+// LanguageNeutral is the code H5P uses for content without a language.
+const LanguageNeutral = "und"
+
+// CoreLanguages lists the language codes H5P core ships translations for.
+var CoreLanguages = []string{
+	"af", "ar", "bg", "bs", "ca", "cs", "da", "de", "el", "en", "es", "es-mx", "et", "eu",
+	"fa", "fi", "fr", "gl", "he", "hr", "hu", "is", "it", "ja", "km", "ko", "lt", "lv",
+	"mn", "nb", "nl", "nn", "pl", "pt", "pt-br", "ro", "ru", "sk", "sl", "sma", "sme",
+	"smj", "sr", "sv", "sw", "te", "th", "tr", "uk", "vi", "zh", "zh-hans", "zh-hant", "zh-tw",
+}
+
+var ErrInvalidLanguage = errors.New("invalid language code")
+
+// bcp47Pattern matches language[-script][-region][-variant]* tags.
+var bcp47Pattern = regexp.MustCompile(`^[a-zA-Z]{2,3}(-[a-zA-Z]{4})?(-([a-zA-Z]{2}|[0-9]{3}))?(-([a-zA-Z0-9]{5,8}|[0-9][a-zA-Z0-9]{3}))*$`)
+
+// ValidateLanguageCode checks that code is a syntactically valid BCP-47
+// language tag. The error suggests a correction for near-misses such as
+// "en_US".
+func ValidateLanguageCode(code string) error {
+	if bcp47Pattern.MatchString(code) {
+		return nil
+	}
+	if s := SuggestLanguageCode(code); s != "" {
+		return fmt.Errorf("%w: %q, did you mean %q?", ErrInvalidLanguage, code, s)
+	}
+	return fmt.Errorf("%w: %q", ErrInvalidLanguage, code)
+}
+
+// SuggestLanguageCode returns the canonical BCP-47 form of code, e.g.
+// "en-US" for "en_US" or "EN-us", or "" if no valid tag can be derived.
+func SuggestLanguageCode(code string) string {
+	parts := strings.FieldsFunc(strings.TrimSpace(code), func(r rune) bool { return r == '-' || r == '_' })
+	for i, p := range parts {
+		switch {
+		case i == 0:
+			parts[i] = strings.ToLower(p)
+		case len(p) == 4 && isLetters(p):
+			parts[i] = strings.ToUpper(p[:1]) + strings.ToLower(p[1:])
+		case len(p) == 2:
+			parts[i] = strings.ToUpper(p)
+		default:
+			parts[i] = strings.ToLower(p)
+		}
+	}
+	s := strings.Join(parts, "-")
+	if s == "" || !bcp47Pattern.MatchString(s) {
+		return ""
+	}
+	return s
+}
+
+// IsCoreLanguage reports whether H5P core ships translations for code,
+// comparing case-insensitively.
+func IsCoreLanguage(code string) bool {
+	for _, lang := range CoreLanguages {
+		if strings.EqualFold(lang, code) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateLanguages checks language and defaultLanguage: invalid tags are
+// errors and languages H5P core has no translations for are warnings.
+func (def *PackageDefinition) ValidateLanguages() *ValidationResult {
+	r := &ValidationResult{}
+	checkLanguage(r, "language", def.Language)
+	checkLanguage(r, "defaultLanguage", def.DefaultLanguage)
+	return r
+}
+
+func checkLanguage(r *ValidationResult, path, code string) {
+	if code == "" || code == LanguageNeutral {
+		return
+	}
+	if err := ValidateLanguageCode(code); err != nil {
+		r.AddError(path, CodeInvalidLanguage, "%v", err)
+		return
+	}
+	if !IsCoreLanguage(code) && !IsCoreLanguage(strings.SplitN(code, "-", 2)[0]) {
+		r.AddWarning(path, CodeUnsupportedLanguage, "H5P core has no translations for %q", code)
+	}
+}
+
+// validateQuestionLanguages checks the defaultLanguage of question metadata.
+func (qs *QuestionSet) validateQuestionLanguages(r *ValidationResult) {
+	for i, q := range qs.Questions {
+		if q.Metadata != nil {
+			path := schemas.JoinPath(schemas.IndexPath("questions", i), "metadata.defaultLanguage")
+			checkLanguage(r, path, q.Metadata.DefaultLanguage)
+		}
+	}
+}
+
+func isLetters(s string) bool {
+	for _, r := range s {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') {
+			return false
+		}
+	}
+	return true
+}
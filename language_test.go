@@ -0,0 +1,66 @@
+package h5p
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidateLanguageCode(t *testing.T) {
+	var languageTests = []struct {
+		code    string
+		valid   bool
+		suggest string
+	}{
+		{"en", true, "en"},
+		{"pt-BR", true, "pt-BR"},
+		{"zh-Hant-TW", true, "zh-Hant-TW"},
+		{"es-419", true, "es-419"},
+		{"en_US", false, "en-US"},
+		{"EN_us", false, "en-US"},
+		{"zh_hant", false, "zh-Hant"},
+		{"english", false, ""},
+		{"", false, ""},
+	}
+
+	for _, tt := range languageTests {
+		err := ValidateLanguageCode(tt.code)
+		if (err == nil) != tt.valid {
+			t.Errorf("ValidateLanguageCode(%q) = %v, want valid=%v", tt.code, err, tt.valid)
+		}
+		if err != nil && !errors.Is(err, ErrInvalidLanguage) {
+			t.Errorf("Expected ErrInvalidLanguage for %q, got %v", tt.code, err)
+		}
+		if got := SuggestLanguageCode(tt.code); got != tt.suggest {
+			t.Errorf("SuggestLanguageCode(%q) = %q, want %q", tt.code, got, tt.suggest)
+		}
+	}
+
+	if err := ValidateLanguageCode("en_US"); err == nil || !strings.Contains(err.Error(), `did you mean "en-US"`) {
+		t.Errorf("Expected suggestion in error, got %v", err)
+	}
+}
+
+func TestPackageDefinitionValidateLanguages(t *testing.T) {
+	def := &PackageDefinition{Language: "pt-BR", DefaultLanguage: "tlh"}
+	r := def.ValidateLanguages()
+	if !r.Valid() {
+		t.Errorf("Expected valid languages, got %v", r.Errors())
+	}
+	if w := r.Warnings(); len(w) != 1 || w[0].Path != "defaultLanguage" || w[0].Code != CodeUnsupportedLanguage {
+		t.Errorf("Expected warning for language without core translations, got %v", w)
+	}
+
+	pkg := loadTestPackage(t)
+	pkg.PackageDefinition.Language = "en_US"
+	err := pkg.ValidateStructure()
+	if err == nil || !strings.Contains(err.Error(), `language: invalid language code: "en_US", did you mean "en-US"?`) {
+		t.Errorf("Expected invalid language in structure validation, got %v", err)
+	}
+
+	qs := &QuestionSet{Questions: []Question{{Library: "H5P.TrueFalse 1.8", Metadata: &ContentMetadata{DefaultLanguage: "fr_FR"}}}}
+	errs := qs.ValidateAll().Errors()
+	if len(errs) != 1 || errs[0].Path != "questions[0].metadata.defaultLanguage" {
+		t.Errorf("Expected invalid question language, got %v", errs)
+	}
+}
@@ -0,0 +1,127 @@
+package h5p
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// Kinds of JSONRepair.
+const (
+	RepairBOM           = "byte order mark"
+	RepairComment       = "comment"
+	RepairTrailingComma = "trailing comma"
+)
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// JSONRepair records a fix applied to a malformed JSON file so it could be
+// decoded.
+type JSONRepair struct {
+	// File is the archive path of the repaired file, empty for RepairJSON.
+	File string
+	// Line is the 1-based line of the removed input.
+	Line int
+	// Kind is one of RepairBOM, RepairComment or RepairTrailingComma.
+	Kind string
+}
+
+func (r JSONRepair) String() string {
+	if r.File == "" {
+		return fmt.Sprintf("line %d: removed %s", r.Line, r.Kind)
+	}
+	return fmt.Sprintf("%s:%d: removed %s", r.File, r.Line, r.Kind)
+}
+
+// RepairJSON strips a leading UTF-8 byte order mark from data. With lenient
+// set it also removes // and /* */ comments and trailing commas before a
+// closing bracket, as written by some editors and buggy plugins. data is
+// returned unchanged if nothing needed repairing.
+func RepairJSON(data []byte, lenient bool) ([]byte, []JSONRepair) {
+	var repairs []JSONRepair
+	if bytes.HasPrefix(data, utf8BOM) {
+		data = data[len(utf8BOM):]
+		repairs = append(repairs, JSONRepair{Line: 1, Kind: RepairBOM})
+	}
+	if !lenient || !bytes.ContainsAny(data, "/,") {
+		return data, repairs
+	}
+
+	var out []byte
+	line := 1
+	last := 0 // start of data not yet copied to out
+	remove := func(start, end int, kind string) {
+		out = append(out, data[last:start]...)
+		last = end
+		repairs = append(repairs, JSONRepair{Line: line, Kind: kind})
+	}
+	inString := false
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		if c == '\n' {
+			line++
+		}
+		if inString {
+			switch c {
+			case '\\':
+				i++
+			case '"':
+				inString = false
+			}
+			continue
+		}
+		switch c {
+		case '"':
+			inString = true
+		case '/':
+			if end := commentEnd(data, i); end > i {
+				remove(i, end, RepairComment)
+				line += bytes.Count(data[i:end], []byte("\n"))
+				i = end - 1
+			}
+		case ',':
+			j := i + 1
+			for j < len(data) {
+				if isJSONSpace(data[j]) {
+					j++
+				} else if end := commentEnd(data, j); end > j {
+					j = end
+				} else {
+					break
+				}
+			}
+			if j < len(data) && (data[j] == '}' || data[j] == ']') {
+				remove(i, i+1, RepairTrailingComma)
+			}
+		}
+	}
+	if out == nil {
+		return data, repairs
+	}
+	return append(out, data[last:]...), repairs
+}
+
+// commentEnd returns the end offset of the comment starting at i, or i if
+// there is none. Line comments end before their newline; an unterminated
+// block comment runs to the end of data.
+func commentEnd(data []byte, i int) int {
+	if i+1 >= len(data) || data[i] != '/' {
+		return i
+	}
+	switch data[i+1] {
+	case '/':
+		if n := bytes.IndexByte(data[i:], '\n'); n >= 0 {
+			return i + n
+		}
+		return len(data)
+	case '*':
+		if n := bytes.Index(data[i+2:], []byte("*/")); n >= 0 {
+			return i + 2 + n + 2
+		}
+		return len(data)
+	}
+	return i
+}
+
+func isJSONSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
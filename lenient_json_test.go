@@ -0,0 +1,84 @@
+package h5p
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+var repairJSONTests = []struct {
+	name    string
+	input   string
+	lenient bool
+	want    string
+	repairs []JSONRepair
+}{
+	{"clean", `{"a":[1,2]}`, true, `{"a":[1,2]}`, nil},
+	{"bom", "\xEF\xBB\xBF{\"a\":1}", false, `{"a":1}`,
+		[]JSONRepair{{Line: 1, Kind: RepairBOM}}},
+	{"strict keeps comments", "{\"a\":1, // c\n}", false, "{\"a\":1, // c\n}", nil},
+	{"line comment", "{\n// note\n\"a\":1}", true, "{\n\n\"a\":1}",
+		[]JSONRepair{{Line: 2, Kind: RepairComment}}},
+	{"block comment", "{/* a\nb */\"a\":1}", true, `{"a":1}`,
+		[]JSONRepair{{Line: 1, Kind: RepairComment}}},
+	{"trailing commas", "{\"a\":[1,2,],\n\"b\":3,\n}", true, "{\"a\":[1,2],\n\"b\":3\n}",
+		[]JSONRepair{{Line: 1, Kind: RepairTrailingComma}, {Line: 2, Kind: RepairTrailingComma}}},
+	{"comma before comment", "[1, /* x */ ]", true, "[1  ]",
+		[]JSONRepair{{Line: 1, Kind: RepairTrailingComma}, {Line: 1, Kind: RepairComment}}},
+	{"strings untouched", `{"url":"http://x/*y*/","s":"a,]","q":"\",}"}`, true,
+		`{"url":"http://x/*y*/","s":"a,]","q":"\",}"}`, nil},
+}
+
+func TestRepairJSON(t *testing.T) {
+	for _, tt := range repairJSONTests {
+		got, repairs := RepairJSON([]byte(tt.input), tt.lenient)
+		if string(got) != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.want, got)
+		}
+		if !reflect.DeepEqual(repairs, tt.repairs) {
+			t.Errorf("%s: expected repairs %v, got %v", tt.name, tt.repairs, repairs)
+		}
+		if tt.lenient && !json.Valid(got) {
+			t.Errorf("%s: repaired output is not valid JSON: %s", tt.name, got)
+		}
+	}
+}
+
+func TestPackageLoaderLenientJSON(t *testing.T) {
+	zipPath := writeTestZip(t, map[string][]byte{
+		"h5p.json":                   []byte("\xEF\xBB\xBF{\"title\":\"Test\",\"mainLibrary\":\"H5P.Foo\"}"),
+		"H5P.Foo-1.0/semantics.json": []byte("[\n  // fields\n  {\"name\":\"text\",\"type\":\"text\"},\n]"),
+	})
+
+	if _, err := LoadH5PPackage(zipPath); err == nil {
+		t.Error("Expected comments in semantics.json to fail without LenientJSON")
+	}
+
+	loader := NewPackageLoader()
+	loader.LenientJSON = true
+	pkg, err := loader.Load(zipPath)
+	if err != nil {
+		t.Fatalf("Failed to load lenient package: %v", err)
+	}
+	if pkg.PackageDefinition.Title != "Test" {
+		t.Errorf("Expected title Test, got %q", pkg.PackageDefinition.Title)
+	}
+	if lib := pkg.libraryByFolder("H5P.Foo-1.0"); lib == nil || lib.Semantics == nil {
+		t.Error("Expected semantics to be decoded")
+	}
+
+	var got []string
+	for _, r := range pkg.Repairs {
+		got = append(got, r.String())
+	}
+	want := []string{
+		"h5p.json:1: removed byte order mark",
+		"H5P.Foo-1.0/semantics.json:2: removed comment",
+		"H5P.Foo-1.0/semantics.json:3: removed trailing comma",
+	}
+	for _, w := range want {
+		if !containsString(got, w) {
+			t.Errorf("Expected repair %q in %v", w, got)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package h5p
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// BoolInt is a boolean that H5P serializes as 0 or 1, as used by runnable,
+// fullscreen and similar library.json flags. It also accepts JSON booleans.
+type BoolInt bool
+
+func (b BoolInt) MarshalJSON() ([]byte, error) {
+	if b {
+		return []byte("1"), nil
+	}
+	return []byte("0"), nil
+}
+
+func (b *BoolInt) UnmarshalJSON(data []byte) error {
+	switch strings.Trim(string(data), `"`) {
+	case "1", "true":
+		*b = true
+	case "0", "false", "null", "":
+		*b = false
+	default:
+		return fmt.Errorf("invalid boolean value: %s", string(data))
+	}
+	return nil
+}
+
+// FileOpener opens the contents of a lazily loaded file.
+type FileOpener func() (io.ReadCloser, error)
+
+// FileNames returns the sorted names of all library files, both in-memory
+// and lazily loaded.
+func (lib *Library) FileNames() []string {
+	names := make([]string, 0, len(lib.Files)+len(lib.LazyFiles))
+	for name := range lib.Files {
+		names = append(names, name)
+	}
+	for name := range lib.LazyFiles {
+		if _, ok := lib.Files[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// OpenFile opens a library file by its path relative to the library folder.
+// In-memory contents take precedence over lazily loaded ones.
+func (lib *Library) OpenFile(name string) (io.ReadCloser, error) {
+	if data, ok := lib.Files[name]; ok {
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+	if open, ok := lib.LazyFiles[name]; ok {
+		return open()
+	}
+	return nil, fmt.Errorf("library %s has no file %s: %w", lib.MachineName, name, fs.ErrNotExist)
+}
+
+// ReadFile returns the contents of a library file, reading lazily loaded
+// files into memory.
+func (lib *Library) ReadFile(name string) ([]byte, error) {
+	rc, err := lib.OpenFile(name)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+// ParseLibraryFolderName splits a library folder name such as
+// "H5P.MultiChoice-1.16" into its machine name and major/minor version.
+func ParseLibraryFolderName(folder string) (machineName string, major, minor int, err error) {
+	idx := strings.LastIndex(folder, "-")
+	if idx <= 0 {
+		return "", 0, 0, fmt.Errorf("library folder %q must have the form Name-Major.Minor", folder)
+	}
+	version := strings.SplitN(folder[idx+1:], ".", 2)
+	if len(version) != 2 {
+		return "", 0, 0, fmt.Errorf("library folder %q must have the form Name-Major.Minor", folder)
+	}
+	if major, err = strconv.Atoi(version[0]); err != nil {
+		return "", 0, 0, fmt.Errorf("invalid major version in library folder %q: %w", folder, err)
+	}
+	if minor, err = strconv.Atoi(version[1]); err != nil {
+		return "", 0, 0, fmt.Errorf("invalid minor version in library folder %q: %w", folder, err)
+	}
+	return folder[:idx], major, minor, nil
+}
+
+// ParseLibraryString parses a library string as used in content params,
+// e.g. "H5P.MultiChoice 1.16", into a dependency.
+func ParseLibraryString(s string) (LibraryDependency, error) {
+	fields := strings.Fields(s)
+	if len(fields) != 2 {
+		return LibraryDependency{}, fmt.Errorf("library string %q must have the form \"Name Major.Minor\"", s)
+	}
+	version := strings.SplitN(fields[1], ".", 2)
+	if len(version) != 2 {
+		return LibraryDependency{}, fmt.Errorf("library string %q must have the form \"Name Major.Minor\"", s)
+	}
+	major, err := strconv.Atoi(version[0])
+	if err != nil {
+		return LibraryDependency{}, fmt.Errorf("invalid major version in library string %q: %w", s, err)
+	}
+	minor, err := strconv.Atoi(version[1])
+	if err != nil {
+		return LibraryDependency{}, fmt.Errorf("invalid minor version in library string %q: %w", s, err)
+	}
+	return LibraryDependency{MachineName: fields[0], MajorVersion: major, MinorVersion: minor}, nil
+}
+
+// FolderMatchesDefinition reports whether the library folder name agrees with
+// the machine name and version declared in library.json.
+func (lib *Library) FolderMatchesDefinition() bool {
+	return lib.Definition != nil && lib.MachineName == lib.Definition.FolderName()
+}
+
+// definitionBytes returns the library.json contents to write. The original
+// bytes are reused when the definition has not been modified since loading.
+func (lib *Library) definitionBytes() ([]byte, error) {
+	out, err := json.MarshalIndent(lib.Definition, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if lib.definitionJSON != nil {
+		var orig LibraryDefinition
+		if err := json.Unmarshal(lib.definitionJSON, &orig); err == nil {
+			if origOut, err := json.MarshalIndent(&orig, "", "  "); err == nil && bytes.Equal(origOut, out) {
+				return lib.definitionJSON, nil
+			}
+		}
+	}
+	return out, nil
+}
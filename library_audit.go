@@ -0,0 +1,151 @@
+package h5p
+
+import (
+	"cmp"
+	"fmt"
+	"path"
+	"slices"
+
+	"github.com/grokify/h5p-go/schemas"
+)
+
+// Library audit statuses.
+const (
+	// LibraryDeprecated marks a version older than listed in
+	// MinimumLibraryVersions.
+	LibraryDeprecated = "deprecated"
+	// LibraryOutdated marks a supported version older than listed in
+	// LatestLibraryVersions.
+	LibraryOutdated = "outdated"
+)
+
+// UpgradeScript is the file in which a library ships the content upgrade
+// routines that the H5P content upgrade engine runs to migrate params
+// written for older versions.
+const UpgradeScript = "upgrades.js"
+
+// LatestLibraryVersions lists the newest known major.minor version of common
+// content type libraries, used by AuditLibraryVersions.
+var LatestLibraryVersions = map[string]LibraryVersion{
+	"H5P.Blanks":               {Major: 1, Minor: 14},
+	"H5P.CoursePresentation":   {Major: 1, Minor: 26},
+	"H5P.Dialogcards":          {Major: 1, Minor: 9},
+	"H5P.DragQuestion":         {Major: 1, Minor: 14},
+	"H5P.DragText":             {Major: 1, Minor: 10},
+	"H5P.Essay":                {Major: 1, Minor: 5},
+	"H5P.Flashcards":           {Major: 1, Minor: 7},
+	"H5P.Image":                {Major: 1, Minor: 1},
+	"H5P.ImageHotspotQuestion": {Major: 1, Minor: 8},
+	"H5P.InteractiveVideo":     {Major: 1, Minor: 27},
+	"H5P.MarkTheWords":         {Major: 1, Minor: 11},
+	"H5P.MultiChoice":          {Major: 1, Minor: 16},
+	"H5P.QuestionSet":          {Major: 1, Minor: 20},
+	"H5P.SingleChoiceSet":      {Major: 1, Minor: 11},
+	"H5P.Summary":              {Major: 1, Minor: 10},
+	"H5P.TrueFalse":            {Major: 1, Minor: 8},
+}
+
+// LibraryAudit reports a content type library version used by a package
+// that is deprecated or outdated.
+type LibraryAudit struct {
+	MachineName string
+	Version     LibraryVersion
+	// Status is LibraryDeprecated or LibraryOutdated.
+	Status string
+	// Latest is the newest known version to upgrade to.
+	Latest LibraryVersion
+	// UsedBy lists where the version is referenced, e.g. a library folder,
+	// "h5p.json" or "content.questions[0]".
+	UsedBy []string
+	// UpgradeScript is the archive path of the upgrades.js shipped by a
+	// newer version of the library in the package, if any. The H5P
+	// content upgrade engine runs it to migrate content to that version.
+	UpgradeScript string
+}
+
+func (a LibraryAudit) String() string {
+	s := fmt.Sprintf("%s %d.%d is %s, latest is %d.%d",
+		a.MachineName, a.Version.Major, a.Version.Minor, a.Status, a.Latest.Major, a.Latest.Minor)
+	if a.UpgradeScript != "" {
+		s += fmt.Sprintf(" (upgrade content with %s)", a.UpgradeScript)
+	}
+	return s
+}
+
+// AuditLibraryVersions returns the deprecated and outdated versions of
+// known content types used by the package's library folders, h5p.json
+// dependencies and question set questions, sorted by machine name and
+// version. Libraries missing from LatestLibraryVersions are not reported.
+func AuditLibraryVersions(pkg *H5PPackage) []LibraryAudit {
+	audits := map[LibraryDependency]*LibraryAudit{}
+	use := func(name string, major, minor int, usedBy string) {
+		latest, ok := LatestLibraryVersions[name]
+		if !ok {
+			return
+		}
+		v := LibraryVersion{Major: major, Minor: minor}
+		status := LibraryOutdated
+		if minVersion, ok := MinimumLibraryVersions[name]; ok && v.Compare(minVersion) < 0 {
+			status = LibraryDeprecated
+		} else if v.Compare(latest) >= 0 {
+			return
+		}
+		key := LibraryDependency{MachineName: name, MajorVersion: major, MinorVersion: minor}
+		a := audits[key]
+		if a == nil {
+			a = &LibraryAudit{MachineName: name, Version: v, Status: status, Latest: latest}
+			audits[key] = a
+		}
+		if !slices.Contains(a.UsedBy, usedBy) {
+			a.UsedBy = append(a.UsedBy, usedBy)
+		}
+	}
+
+	for _, lib := range pkg.Libraries {
+		if name, major, minor, ok := lib.identity(); ok {
+			use(name, major, minor, lib.MachineName)
+		}
+	}
+	if pkg.PackageDefinition != nil {
+		for _, dep := range pkg.PackageDefinition.PreloadedDependencies {
+			use(dep.MachineName, dep.MajorVersion, dep.MinorVersion, "h5p.json")
+		}
+	}
+	if pkg.Content != nil && pkg.Content.QuestionSet != nil {
+		for i, q := range pkg.Content.QuestionSet.Questions {
+			if dep, err := ParseLibraryString(q.Library); err == nil {
+				use(dep.MachineName, dep.MajorVersion, dep.MinorVersion, schemas.IndexPath("content.questions", i))
+			}
+		}
+	}
+
+	result := make([]LibraryAudit, 0, len(audits))
+	for _, a := range audits {
+		a.UpgradeScript = pkg.upgradeScript(a.MachineName, a.Version)
+		result = append(result, *a)
+	}
+	slices.SortFunc(result, func(a, b LibraryAudit) int {
+		return cmp.Or(cmp.Compare(a.MachineName, b.MachineName), a.Version.Compare(b.Version))
+	})
+	return result
+}
+
+// upgradeScript returns the archive path of the upgrades.js of the newest
+// version of machineName in the package if it is newer than v, or "".
+func (pkg *H5PPackage) upgradeScript(machineName string, v LibraryVersion) string {
+	var newest *Library
+	var newestVersion LibraryVersion
+	for _, lib := range pkg.Libraries {
+		def, ok := lib.versionDefinition()
+		if !ok || def.MachineName != machineName {
+			continue
+		}
+		if newest == nil || def.Version().Compare(newestVersion) > 0 {
+			newest, newestVersion = lib, def.Version()
+		}
+	}
+	if newest == nil || newestVersion.Compare(v) <= 0 || !slices.Contains(newest.FileNames(), UpgradeScript) {
+		return ""
+	}
+	return path.Join(newest.MachineName, UpgradeScript)
+}
@@ -0,0 +1,62 @@
+package h5p
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAuditLibraryVersions(t *testing.T) {
+	pkg := NewH5PPackage()
+	pkg.SetPackageDefinition(&PackageDefinition{
+		MainLibrary:           "H5P.QuestionSet",
+		PreloadedDependencies: []LibraryDependency{dep("H5P.QuestionSet", 1, 20), dep("H5P.MultiChoice", 1, 14)},
+	})
+	pkg.SetContent(&Content{QuestionSet: &QuestionSet{Questions: []Question{
+		{Library: "H5P.MultiChoice 1.14"},
+		{Library: "H5P.TrueFalse 1.8"},
+		{Library: "H5P.Custom 0.1"},
+	}}})
+	pkg.AddLibrary(newTestLibrary("H5P.QuestionSet", 1, 20))
+	pkg.AddLibrary(newTestLibrary("H5P.MultiChoice", 1, 14))
+	newer := newTestLibrary("H5P.MultiChoice", 1, 15)
+	newer.Files = map[string][]byte{UpgradeScript: []byte("// upgrades")}
+	pkg.AddLibrary(newer)
+
+	saved := LatestLibraryVersions["H5P.QuestionSet"]
+	LatestLibraryVersions["H5P.QuestionSet"] = LibraryVersion{Major: 1, Minor: 21}
+	defer func() { LatestLibraryVersions["H5P.QuestionSet"] = saved }()
+
+	want := []LibraryAudit{
+		{
+			MachineName:   "H5P.MultiChoice",
+			Version:       LibraryVersion{Major: 1, Minor: 14},
+			Status:        LibraryDeprecated,
+			Latest:        LibraryVersion{Major: 1, Minor: 16},
+			UsedBy:        []string{"H5P.MultiChoice-1.14", "h5p.json", "content.questions[0]"},
+			UpgradeScript: "H5P.MultiChoice-1.15/upgrades.js",
+		},
+		{
+			MachineName: "H5P.MultiChoice",
+			Version:     LibraryVersion{Major: 1, Minor: 15},
+			Status:      LibraryDeprecated,
+			Latest:      LibraryVersion{Major: 1, Minor: 16},
+			UsedBy:      []string{"H5P.MultiChoice-1.15"},
+		},
+		{
+			MachineName: "H5P.QuestionSet",
+			Version:     LibraryVersion{Major: 1, Minor: 20},
+			Status:      LibraryOutdated,
+			Latest:      LibraryVersion{Major: 1, Minor: 21},
+			UsedBy:      []string{"H5P.QuestionSet-1.20", "h5p.json"},
+		},
+	}
+	got := AuditLibraryVersions(pkg)
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Expected audit\n%+v\ngot\n%+v", want, got)
+	}
+
+	wantString := "H5P.MultiChoice 1.14 is deprecated, latest is 1.16 (upgrade content with H5P.MultiChoice-1.15/upgrades.js)"
+	if s := got[0].String(); s != wantString {
+		t.Errorf("Expected %q, got %q", wantString, s)
+	}
+}
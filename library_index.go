@@ -0,0 +1,159 @@
+package h5p
+
+import (
+	"fmt"
+	"slices"
+)
+
+// libraryIndex maps library folder names and "Name Major.Minor" keys to
+// the positions in H5PPackage.Libraries of the libraries having them, in
+// ascending order so the first library with a key wins, as in a linear
+// scan. The methods of H5PPackage keep it up to date. Callers may also
+// replace or reslice Libraries directly, which the index detects by the
+// length and first element of the slice it was built for, rebuilding
+// itself on the next lookup; a hit pointing at a library changed in place
+// also rebuilds it.
+type libraryIndex struct {
+	byFolder  map[string][]int
+	byVersion map[string][]int
+	n         int
+	first     **Library
+}
+
+func libraryVersionKey(machineName string, major, minor int) string {
+	return fmt.Sprintf("%s %d.%d", machineName, major, minor)
+}
+
+// GetLibrary returns the library with the given machine name and
+// major/minor version, taken from library.json or the folder name, or nil.
+func (pkg *H5PPackage) GetLibrary(machineName string, major, minor int) *Library {
+	return pkg.lookupLibrary(func(ix *libraryIndex) []int {
+		return ix.byVersion[libraryVersionKey(machineName, major, minor)]
+	}, func(lib *Library) bool {
+		name, ma, mi, ok := lib.identity()
+		return ok && name == machineName && ma == major && mi == minor
+	})
+}
+
+// libraryByFolder returns the library stored in the given folder, or nil.
+func (pkg *H5PPackage) libraryByFolder(folder string) *Library {
+	return pkg.lookupLibrary(func(ix *libraryIndex) []int {
+		return ix.byFolder[folder]
+	}, func(lib *Library) bool {
+		return lib.MachineName == folder
+	})
+}
+
+// lookupLibrary returns the first library at the positions the index gives
+// for a key, rebuilding the index once if the library there no longer
+// matches.
+func (pkg *H5PPackage) lookupLibrary(positions func(*libraryIndex) []int, match func(*Library) bool) *Library {
+	if !pkg.libIndex.fresh(pkg.Libraries) {
+		pkg.indexLibraries()
+	}
+	p := positions(&pkg.libIndex)
+	if len(p) == 0 {
+		return nil
+	}
+	if !match(pkg.Libraries[p[0]]) {
+		pkg.indexLibraries()
+		if p = positions(&pkg.libIndex); len(p) == 0 {
+			return nil
+		}
+	}
+	return pkg.Libraries[p[0]]
+}
+
+// fresh reports whether the index was built for libs.
+func (ix *libraryIndex) fresh(libs []*Library) bool {
+	if ix.byFolder == nil || ix.n != len(libs) {
+		return false
+	}
+	return len(libs) == 0 || ix.first == &libs[0]
+}
+
+// track records libs as the slice the index is built for.
+func (ix *libraryIndex) track(libs []*Library) {
+	ix.n, ix.first = len(libs), nil
+	if len(libs) > 0 {
+		ix.first = &libs[0]
+	}
+}
+
+// indexLibraries rebuilds the library index from scratch.
+func (pkg *H5PPackage) indexLibraries() {
+	pkg.libIndex = libraryIndex{
+		byFolder:  make(map[string][]int, len(pkg.Libraries)),
+		byVersion: make(map[string][]int, len(pkg.Libraries)),
+	}
+	for i := range pkg.Libraries {
+		pkg.indexLibrary(i)
+	}
+	pkg.libIndex.track(pkg.Libraries)
+}
+
+// updateLibrary applies change to the library at position i, moving its
+// index entries to the folder name and identity it has afterwards.
+func (pkg *H5PPackage) updateLibrary(i int, change func()) {
+	if !pkg.libIndex.fresh(pkg.Libraries) {
+		change()
+		pkg.indexLibraries()
+		return
+	}
+	pkg.unindexLibrary(i)
+	change()
+	pkg.indexLibrary(i)
+}
+
+// indexLibrary adds the keys of the library at position i to the index.
+func (pkg *H5PPackage) indexLibrary(i int) {
+	lib := pkg.Libraries[i]
+	addPosition(pkg.libIndex.byFolder, lib.MachineName, i)
+	if name, major, minor, ok := lib.identity(); ok {
+		addPosition(pkg.libIndex.byVersion, libraryVersionKey(name, major, minor), i)
+	}
+}
+
+// unindexLibrary removes the keys of the library at position i from the
+// index.
+func (pkg *H5PPackage) unindexLibrary(i int) {
+	lib := pkg.Libraries[i]
+	removePosition(pkg.libIndex.byFolder, lib.MachineName, i)
+	if name, major, minor, ok := lib.identity(); ok {
+		removePosition(pkg.libIndex.byVersion, libraryVersionKey(name, major, minor), i)
+	}
+}
+
+func addPosition(index map[string][]int, key string, i int) {
+	p := index[key]
+	if j, found := slices.BinarySearch(p, i); !found {
+		index[key] = slices.Insert(p, j, i)
+	}
+}
+
+func removePosition(index map[string][]int, key string, i int) {
+	p := index[key]
+	if j, found := slices.BinarySearch(p, i); found {
+		if p = slices.Delete(p, j, j+1); len(p) == 0 {
+			delete(index, key)
+		} else {
+			index[key] = p
+		}
+	}
+}
+
+// setLibraryDefinition sets the library.json of lib.
+func (pkg *H5PPackage) setLibraryDefinition(lib *Library, def *LibraryDefinition, data []byte) {
+	set := func() {
+		lib.Definition = def
+		lib.definitionJSON = data
+	}
+	for _, i := range pkg.libIndex.byFolder[lib.MachineName] {
+		if pkg.libIndex.fresh(pkg.Libraries) && pkg.Libraries[i] == lib {
+			pkg.updateLibrary(i, set)
+			return
+		}
+	}
+	set()
+	pkg.indexLibraries()
+}
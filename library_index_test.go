@@ -0,0 +1,66 @@
+package h5p
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestGetLibrary(t *testing.T) {
+	pkg := NewH5PPackage()
+	for i := 0; i < 50; i++ {
+		pkg.AddLibrary(newTestLibrary(fmt.Sprintf("H5P.Lib%d", i), 1, i))
+	}
+	pkg.AddLibrary(&Library{MachineName: "FontAwesome-4.5"})
+
+	if lib := pkg.GetLibrary("H5P.Lib42", 1, 42); lib == nil || lib.MachineName != "H5P.Lib42-1.42" {
+		t.Errorf("Expected H5P.Lib42-1.42, got %v", lib)
+	}
+	if lib := pkg.GetLibrary("FontAwesome", 4, 5); lib == nil {
+		t.Error("Expected library without library.json to be found by folder name")
+	}
+	if lib := pkg.GetLibrary("H5P.Lib42", 1, 41); lib != nil {
+		t.Errorf("Expected no library for wrong version, got %s", lib.MachineName)
+	}
+
+	// Direct modifications of Libraries must not return stale results.
+	pkg.Libraries = pkg.Libraries[1:]
+	if lib := pkg.GetLibrary("H5P.Lib0", 1, 0); lib != nil {
+		t.Error("Expected removed library not to be found")
+	}
+	if lib := pkg.GetLibrary("H5P.Lib42", 1, 42); lib == nil || lib.MachineName != "H5P.Lib42-1.42" {
+		t.Errorf("Expected H5P.Lib42-1.42 after removal, got %v", lib)
+	}
+	if lib := pkg.findOrCreateLibrary("H5P.Lib7-1.7"); lib.Definition == nil || len(pkg.Libraries) != 50 {
+		t.Error("Expected existing library to be found instead of created")
+	}
+
+	// A hit on a library replaced in place rebuilds the index.
+	other := newTestLibrary("H5P.Other", 1, 0)
+	pkg.Libraries[4] = other
+	if lib := pkg.GetLibrary("H5P.Lib5", 1, 5); lib != nil {
+		t.Errorf("Expected replaced library not to be found, got %s", lib.MachineName)
+	}
+	if lib := pkg.GetLibrary("H5P.Other", 1, 0); lib != other {
+		t.Errorf("Expected the replacing library after the rebuild, got %v", lib)
+	}
+	pkg.AddLibrary(newTestLibrary("H5P.Lib5", 1, 5))
+	if lib := pkg.libraryByFolder("H5P.Lib5-1.5"); lib == nil || lib != pkg.Libraries[len(pkg.Libraries)-1] {
+		t.Errorf("Expected the added library, got %v", lib)
+	}
+}
+
+func TestLibraryIndexLoad(t *testing.T) {
+	pkg := NewH5PPackage()
+	for i := 0; i < 3; i++ {
+		data := fmt.Sprintf(`{"machineName":"H5P.Lib%d","majorVersion":1,"minorVersion":%d}`, i, i)
+		if err := pkg.processFile(fmt.Sprintf("lib%d/library.json", i), []byte(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 0; i < 3; i++ {
+		key := libraryVersionKey(fmt.Sprintf("H5P.Lib%d", i), 1, i)
+		if p := pkg.libIndex.byVersion[key]; len(p) != 1 || p[0] != i {
+			t.Errorf("Expected %s indexed at %d, got %v", key, i, p)
+		}
+	}
+}
@@ -0,0 +1,306 @@
+package h5p
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/grokify/h5p-go/semantics"
+)
+
+var ErrTranslationPath = errors.New("no semantics field for translation path")
+
+// languageDir is the library folder holding translations of semantics.
+const languageDir = "language/"
+
+// LibraryTranslation is a library language file, language/<code>.json.
+// Its semantics mirror semantics.json field by field, in the same order,
+// holding the translated labels, descriptions and default texts.
+type LibraryTranslation struct {
+	Semantics []TranslationField `json:"semantics"`
+}
+
+// TranslationField holds the translated attributes of a semantics field.
+// Fields with nothing to translate are empty.
+type TranslationField struct {
+	Label       string                `json:"label,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Default     any                   `json:"default,omitempty"`
+	Placeholder string                `json:"placeholder,omitempty"`
+	Entity      string                `json:"entity,omitempty"`
+	Example     string                `json:"example,omitempty"`
+	Important   *TranslationImportant `json:"important,omitempty"`
+	Options     []TranslationOption   `json:"options,omitempty"`
+	// Fields translates the fields of a group, Field the item of a list.
+	Fields []TranslationField `json:"fields,omitempty"`
+	Field  *TranslationField  `json:"field,omitempty"`
+
+	// Extra holds attributes not listed above, such as those of editor
+	// widgets, so they are written back as read.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// TranslationImportant translates the "important" hint of a field.
+type TranslationImportant struct {
+	Description string `json:"description,omitempty"`
+	Example     string `json:"example,omitempty"`
+}
+
+// TranslationOption translates the label of a select option.
+type TranslationOption struct {
+	Label string `json:"label,omitempty"`
+}
+
+// translationFields is TranslationField without its JSON methods.
+type translationFields TranslationField
+
+// translationKeys are the attributes of TranslationField.
+var translationKeys = []string{"label", "description", "default", "placeholder", "entity", "example", "important", "options", "fields", "field"}
+
+func (f TranslationField) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(translationFields(f))
+	if err != nil || len(f.Extra) == 0 {
+		return data, err
+	}
+	var attrs map[string]json.RawMessage
+	if err := json.Unmarshal(data, &attrs); err != nil {
+		return nil, err
+	}
+	for k, v := range f.Extra {
+		if _, ok := attrs[k]; !ok {
+			attrs[k] = v
+		}
+	}
+	return json.Marshal(attrs)
+}
+
+func (f *TranslationField) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, (*translationFields)(f)); err != nil {
+		return err
+	}
+	var attrs map[string]json.RawMessage
+	if err := json.Unmarshal(data, &attrs); err != nil {
+		return err
+	}
+	for _, k := range translationKeys {
+		delete(attrs, k)
+	}
+	if len(attrs) > 0 {
+		f.Extra = attrs
+	}
+	return nil
+}
+
+// NewLibraryTranslation returns the translation holding the texts of sd
+// itself, the starting point for translating a library into a new
+// language.
+func NewLibraryTranslation(sd semantics.SemanticDefinition) *LibraryTranslation {
+	return &LibraryTranslation{Semantics: sourceTranslations(sd)}
+}
+
+func sourceTranslations(fields []semantics.Field) []TranslationField {
+	out := make([]TranslationField, len(fields))
+	for i := range fields {
+		out[i] = sourceTranslation(&fields[i])
+	}
+	return out
+}
+
+func sourceTranslation(f *semantics.Field) TranslationField {
+	t := TranslationField{
+		Label:       f.Label,
+		Description: f.Description,
+		Placeholder: f.Placeholder,
+		Entity:      f.Entity,
+	}
+	if s, ok := f.Default.(string); ok && (f.Type == "text" || f.Type == "html") {
+		t.Default = s
+	}
+	if f.Type == "select" {
+		for _, o := range f.GetSelectOptions() {
+			t.Options = append(t.Options, TranslationOption{Label: o.Label})
+		}
+	}
+	if len(f.Fields) > 0 {
+		t.Fields = sourceTranslations(f.Fields)
+	}
+	if f.Field != nil {
+		item := sourceTranslation(f.Field)
+		t.Field = &item
+	}
+	return t
+}
+
+// Languages returns the sorted codes of the library's language files, e.g.
+// "de" for language/de.json. The .en.json template some libraries ship is
+// left out.
+func (lib *Library) Languages() []string {
+	var codes []string
+	for _, name := range lib.FileNames() {
+		dir, file := path.Split(name)
+		code, ok := strings.CutSuffix(file, ".json")
+		if ok && dir == languageDir && code != "" && !strings.HasPrefix(code, ".") {
+			codes = append(codes, code)
+		}
+	}
+	return codes
+}
+
+// Translation returns the language file of code. The error wraps
+// fs.ErrNotExist if the library has none.
+func (lib *Library) Translation(code string) (*LibraryTranslation, error) {
+	data, err := lib.ReadFile(languageDir + code + ".json")
+	if err != nil {
+		return nil, err
+	}
+	var t LibraryTranslation
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, fmt.Errorf("invalid translation %s of %s: %w", code, lib.MachineName, err)
+	}
+	return &t, nil
+}
+
+// Translations returns the language files of the library keyed by code.
+func (lib *Library) Translations() (map[string]*LibraryTranslation, error) {
+	translations := map[string]*LibraryTranslation{}
+	for _, code := range lib.Languages() {
+		t, err := lib.Translation(code)
+		if err != nil {
+			return nil, err
+		}
+		translations[code] = t
+	}
+	return translations, nil
+}
+
+// SetTranslation writes t as the language file of code, replacing any.
+func (lib *Library) SetTranslation(code string, t *LibraryTranslation) error {
+	if err := ValidateLanguageCode(code); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return err
+	}
+	if lib.Files == nil {
+		lib.Files = make(map[string][]byte)
+	}
+	lib.Files[languageDir+code+".json"] = append(data, '\n')
+	return nil
+}
+
+// MergeTranslation patches the language file of locale with overrides, or
+// adds one starting from the texts of semantics.json if there is none.
+// Overrides are keyed by the dot-path of a semantics field, as in
+// semantics.SemanticDefinition.FieldByPath, and the attribute to set, e.g.
+// "UI.checkAnswerButton.default" or "question.label". The item of a list
+// is named by its own name, e.g. "answers.answer.label", and
+// "<path>.options.<value>" sets the label of a select option. Unknown
+// paths fail with ErrTranslationPath and leave the library unchanged.
+func MergeTranslation(lib *Library, locale string, overrides map[string]string) error {
+	sd, err := lib.SemanticDefinition()
+	if err != nil {
+		return err
+	}
+	t, err := lib.Translation(locale)
+	if err != nil {
+		t = NewLibraryTranslation(sd)
+	}
+	for key, value := range overrides {
+		if err := t.set(sd, key, value); err != nil {
+			return fmt.Errorf("%s of %s: %w", locale, lib.MachineName, err)
+		}
+	}
+	return lib.SetTranslation(locale, t)
+}
+
+// set sets the attribute of the field named by key, see MergeTranslation.
+func (t *LibraryTranslation) set(sd semantics.SemanticDefinition, key, value string) error {
+	names := strings.Split(key, semantics.PathSeparator)
+	if len(names) < 2 {
+		return fmt.Errorf("%w: %q", ErrTranslationPath, key)
+	}
+	attr := names[len(names)-1]
+	names = names[:len(names)-1]
+	var option string
+	if len(names) > 1 && names[len(names)-1] == "options" {
+		option, names = attr, names[:len(names)-1]
+		attr = "options"
+	}
+	f, tf := translationField(sd, &t.Semantics, names)
+	if f == nil {
+		return fmt.Errorf("%w: %q", ErrTranslationPath, key)
+	}
+	switch attr {
+	case "label":
+		tf.Label = value
+	case "description":
+		tf.Description = value
+	case "default":
+		tf.Default = value
+	case "placeholder":
+		tf.Placeholder = value
+	case "entity":
+		tf.Entity = value
+	case "example":
+		tf.Example = value
+	case "options":
+		i := -1
+		for j, o := range f.GetSelectOptions() {
+			if o.Value == option {
+				i = j
+			}
+		}
+		if i < 0 {
+			return fmt.Errorf("%w: %q", ErrTranslationPath, key)
+		}
+		for len(tf.Options) <= i {
+			tf.Options = append(tf.Options, TranslationOption{})
+		}
+		tf.Options[i].Label = value
+	default:
+		return fmt.Errorf("%w: %q", ErrTranslationPath, key)
+	}
+	return nil
+}
+
+// translationField returns the semantics field named by the path names
+// and its translation in translations, adding empty translations for the
+// fields before it if needed. List fields are traversed like in
+// FieldByPath; the item of a list is named by its own name.
+func translationField(fields []semantics.Field, translations *[]TranslationField, names []string) (*semantics.Field, *TranslationField) {
+	i := -1
+	for j := range fields {
+		if fields[j].Name == names[0] {
+			i = j
+			break
+		}
+	}
+	if i < 0 {
+		return nil, nil
+	}
+	for len(*translations) <= i {
+		*translations = append(*translations, TranslationField{})
+	}
+	f, t := &fields[i], &(*translations)[i]
+	if len(names) == 1 {
+		return f, t
+	}
+	switch {
+	case f.Type == "group":
+		return translationField(f.Fields, &t.Fields, names[1:])
+	case f.Type == "list" && f.Field != nil:
+		if t.Field == nil {
+			t.Field = &TranslationField{}
+		}
+		if len(names) == 2 && names[1] == f.Field.Name {
+			return f.Field, t.Field
+		}
+		if f.Field.Type == "group" {
+			return translationField(f.Field.Fields, &t.Field.Fields, names[1:])
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,124 @@
+package h5p
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testLanguageSemantics = `[
+  {"name": "question", "type": "text", "label": "Question"},
+  {"name": "answers", "type": "list", "label": "Answers", "entity": "answer",
+   "field": {"name": "answer", "type": "group", "label": "Answer", "fields": [
+     {"name": "text", "type": "text", "label": "Text"}]}},
+  {"name": "keywords", "type": "list", "label": "Keywords",
+   "field": {"name": "keyword", "type": "text", "label": "Keyword"}},
+  {"name": "behaviour", "type": "group", "label": "Behaviour", "fields": [
+    {"name": "type", "type": "select", "label": "Type", "default": "auto",
+     "options": [{"value": "auto", "label": "Automatic"}, {"value": "single", "label": "Single"}]}]},
+  {"name": "UI", "type": "group", "label": "Texts", "fields": [
+    {"name": "checkAnswerButton", "type": "text", "label": "Check button", "default": "Check"},
+    {"name": "tryAgainButton", "type": "text", "label": "Retry button", "default": "Retry"}]}
+]`
+
+const testLanguageDE = `{
+  "semantics": [
+    {"label": "Frage"},
+    {},
+    {},
+    {},
+    {"label": "Texte", "widgets": [{"label": "Standard"}], "fields": [
+      {"label": "Überprüfen-Button", "default": "Überprüfen"}]}
+  ]
+}`
+
+func newTestLanguageLibrary(t *testing.T) *Library {
+	t.Helper()
+	lib := newTestLibrary("H5P.MultiChoice", 1, 16)
+	if err := json.Unmarshal([]byte(testLanguageSemantics), &lib.Semantics); err != nil {
+		t.Fatal(err)
+	}
+	lib.Files = map[string][]byte{
+		"language/de.json":  []byte(testLanguageDE),
+		"language/.en.json": []byte(`{"semantics": []}`),
+		"js/multichoice.js": nil,
+	}
+	return lib
+}
+
+func TestLibraryTranslations(t *testing.T) {
+	lib := newTestLanguageLibrary(t)
+	if langs := lib.Languages(); !reflect.DeepEqual(langs, []string{"de"}) {
+		t.Errorf("Expected languages [de], got %v", langs)
+	}
+	translations, err := lib.Translations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	de := translations["de"]
+	if de == nil || len(de.Semantics) != 5 || de.Semantics[0].Label != "Frage" || de.Semantics[4].Fields[0].Default != "Überprüfen" {
+		t.Fatalf("Unexpected translation %+v", de)
+	}
+	data, err := json.Marshal(de)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"widgets":[{"label":"Standard"}]`) {
+		t.Errorf("Expected unknown attributes kept, got %s", data)
+	}
+	if _, err := lib.Translation("fr"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected fs.ErrNotExist, got %v", err)
+	}
+}
+
+func TestMergeTranslation(t *testing.T) {
+	lib := newTestLanguageLibrary(t)
+	err := MergeTranslation(lib, "de", map[string]string{
+		"UI.checkAnswerButton.default":  "Prüfen",
+		"UI.tryAgainButton.default":     "Nochmal",
+		"answers.text.label":            "Antworttext",
+		"keywords.keyword.label":        "Schlüsselwort",
+		"behaviour.type.options.single": "Einfach",
+		"question.description":          "Die Frage",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	de, err := lib.Translation("de")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ui := de.Semantics[4]
+	if ui.Label != "Texte" || ui.Fields[0].Default != "Prüfen" || ui.Fields[1].Default != "Nochmal" || ui.Extra["widgets"] == nil {
+		t.Errorf("Unexpected UI translation %+v", ui)
+	}
+	if de.Semantics[0].Label != "Frage" || de.Semantics[0].Description != "Die Frage" {
+		t.Errorf("Unexpected question translation %+v", de.Semantics[0])
+	}
+	if de.Semantics[1].Field.Fields[0].Label != "Antworttext" || de.Semantics[2].Field.Label != "Schlüsselwort" {
+		t.Errorf("Unexpected list translations %+v %+v", de.Semantics[1], de.Semantics[2])
+	}
+	if opts := de.Semantics[3].Fields[0].Options; len(opts) != 2 || opts[1].Label != "Einfach" {
+		t.Errorf("Unexpected options %+v", opts)
+	}
+
+	if err := MergeTranslation(lib, "fr", map[string]string{"UI.checkAnswerButton.default": "Vérifier"}); err != nil {
+		t.Fatal(err)
+	}
+	fr, err := lib.Translation("fr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fr.Semantics[0].Label != "Question" || fr.Semantics[4].Fields[0].Default != "Vérifier" || fr.Semantics[4].Fields[1].Default != "Retry" {
+		t.Errorf("Expected a new translation from the semantics, got %+v", fr)
+	}
+
+	for _, key := range []string{"UI.missing.default", "UI.checkAnswerButton.tooltip", "behaviour.type.options.multi", "question"} {
+		if err := MergeTranslation(lib, "de", map[string]string{key: "x"}); !errors.Is(err, ErrTranslationPath) {
+			t.Errorf("Expected ErrTranslationPath for %s, got %v", key, err)
+		}
+	}
+}
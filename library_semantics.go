@@ -0,0 +1,111 @@
+package h5p
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/grokify/h5p-go/schemas"
+	"github.com/grokify/h5p-go/semantics"
+)
+
+var ErrNoSemantics = errors.New("main library semantics not available")
+
+// SemanticDefinition returns the library's semantics.json, nil if it has
+// none.
+func (lib *Library) SemanticDefinition() (semantics.SemanticDefinition, error) {
+	if lib.Semantics == nil {
+		return nil, nil
+	}
+	if sd, ok := lib.Semantics.(semantics.SemanticDefinition); ok {
+		return sd, nil
+	}
+	data, err := json.Marshal(lib.Semantics)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode semantics of %s: %w", lib.MachineName, err)
+	}
+	var sd semantics.SemanticDefinition
+	if err := json.Unmarshal(data, &sd); err != nil {
+		return nil, fmt.Errorf("invalid semantics of %s: %w", lib.MachineName, err)
+	}
+	return sd, nil
+}
+
+// LibrarySemantics returns the semantics of the package library named by a
+// library string such as "H5P.Image 1.1". ok is false if the package does
+// not contain the library or its semantics cannot be read. It fits
+// semantics.Validator.Resolve.
+func (pkg *H5PPackage) LibrarySemantics(library string) (sd semantics.SemanticDefinition, ok bool) {
+	dep, err := ParseLibraryString(library)
+	if err != nil {
+		return nil, false
+	}
+	lib := pkg.GetLibrary(dep.MachineName, dep.MajorVersion, dep.MinorVersion)
+	if lib == nil {
+		return nil, false
+	}
+	sd, err = lib.SemanticDefinition()
+	return sd, err == nil && sd != nil
+}
+
+// ValidateParams checks content params, such as the decoded
+// content/content.json, against the semantics of the main library,
+// following library fields into the sub-content libraries of the package.
+// See semantics.Validator.
+func (pkg *H5PPackage) ValidateParams(params any) *ValidationResult {
+	main := pkg.mainLibrary()
+	if main == nil {
+		r := &ValidationResult{}
+		r.AddError("", schemas.CodeRequired, "main library is not in the package")
+		return r
+	}
+	sd, err := main.SemanticDefinition()
+	if err != nil || sd == nil {
+		r := &ValidationResult{}
+		r.AddError("", schemas.CodeRequired, "main library %s has no readable semantics.json", main.MachineName)
+		return r
+	}
+	return semantics.Validator{Resolve: pkg.LibrarySemantics}.Validate(sd, params)
+}
+
+// ExtractStrings returns the translatable strings of content/content.json,
+// following library fields into the sub-content libraries of the package.
+// See semantics.StringExtractor.
+func (pkg *H5PPackage) ExtractStrings() ([]semantics.TranslatableString, error) {
+	e, sd, err := pkg.stringExtractor()
+	if err != nil {
+		return nil, err
+	}
+	return e.Extract(sd, pkg.Content)
+}
+
+// ApplyStrings returns a copy of the params of content/content.json with
+// the strings keyed in translations, as returned by ExtractStrings,
+// replaced by their translation. Set the result as the Params of a Content
+// for the translated package.
+func (pkg *H5PPackage) ApplyStrings(translations map[string]string) (map[string]any, error) {
+	e, sd, err := pkg.stringExtractor()
+	if err != nil {
+		return nil, err
+	}
+	return e.Apply(sd, pkg.Content, translations)
+}
+
+func (pkg *H5PPackage) stringExtractor() (semantics.StringExtractor, semantics.SemanticDefinition, error) {
+	main := pkg.mainLibrary()
+	if main == nil {
+		return semantics.StringExtractor{}, nil, fmt.Errorf("%w: main library is not in the package", ErrNoSemantics)
+	}
+	sd, err := main.SemanticDefinition()
+	if err != nil {
+		return semantics.StringExtractor{}, nil, err
+	}
+	if sd == nil {
+		return semantics.StringExtractor{}, nil, fmt.Errorf("%w: %s has no semantics.json", ErrNoSemantics, main.MachineName)
+	}
+	library := main.MachineName
+	if name, major, minor, ok := main.identity(); ok {
+		library = fmt.Sprintf("%s %d.%d", name, major, minor)
+	}
+	return semantics.StringExtractor{Resolve: pkg.LibrarySemantics, Library: library}, sd, nil
+}
@@ -0,0 +1,86 @@
+package h5p
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestValidateParams(t *testing.T) {
+	pkg := loadTestPackage(t)
+	var params map[string]any
+	readTestJSON(t, "testdata/content.json", &params)
+
+	if r := pkg.ValidateParams(params); r.Valid() {
+		t.Error("Expected an error for a main library without semantics")
+	}
+
+	data, err := os.ReadFile("schemas/multichoice_semantics.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lib := pkg.GetLibrary("H5P.MultiChoice", 1, 16)
+	if err := json.Unmarshal(data, &lib.Semantics); err != nil {
+		t.Fatal(err)
+	}
+	if sd, ok := pkg.LibrarySemantics("H5P.MultiChoice 1.16"); !ok || sd.FieldByPath("answers.text") == nil {
+		t.Error("Expected the MultiChoice semantics")
+	}
+	if _, ok := pkg.LibrarySemantics("H5P.Image 1.1"); ok {
+		t.Error("Expected no semantics for a library not in the package")
+	}
+
+	if r := pkg.ValidateParams(params); !r.Valid() {
+		t.Errorf("Expected valid params, got %v", r.Err())
+	}
+	params["answers"] = "none"
+	if r := pkg.ValidateParams(params); r.Valid() || r.Errors()[0].Path != "answers" {
+		t.Errorf("Expected an error for answers, got %v", r.Problems)
+	}
+}
+
+func TestPackageStrings(t *testing.T) {
+	pkg := loadTestPackage(t)
+	readTestJSON(t, "testdata/content.json", &pkg.Content)
+	if _, err := pkg.ExtractStrings(); !errors.Is(err, ErrNoSemantics) {
+		t.Errorf("Expected ErrNoSemantics, got %v", err)
+	}
+
+	data, err := os.ReadFile("schemas/multichoice_semantics.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lib := pkg.GetLibrary("H5P.MultiChoice", 1, 16)
+	if err := json.Unmarshal(data, &lib.Semantics); err != nil {
+		t.Fatal(err)
+	}
+	found, err := pkg.ExtractStrings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	translations := map[string]string{}
+	for _, s := range found {
+		if s.Library != "H5P.MultiChoice 1.16" {
+			t.Errorf("Unexpected library of %+v", s)
+		}
+		translations[s.Key] = "[" + s.Text + "]"
+	}
+	if len(found) == 0 || translations["question"] == "" {
+		t.Fatalf("Expected the question among %+v", found)
+	}
+	params, err := pkg.ApplyStrings(translations)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg.SetContent(&Content{Params: params})
+	translated, err := pkg.ExtractStrings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, s := range translated {
+		if s.Key != found[i].Key || s.Text != "["+found[i].Text+"]" {
+			t.Errorf("Expected %s translated, got %q", s.Key, s.Text)
+		}
+	}
+}
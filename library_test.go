@@ -0,0 +1,136 @@
+package h5p
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLibraryDefinitionRoundTrip(t *testing.T) {
+	original, err := os.ReadFile("testdata/library_full.json")
+	if err != nil {
+		t.Fatalf("Failed to read library_full.json: %v", err)
+	}
+
+	var def LibraryDefinition
+	if err := json.Unmarshal(original, &def); err != nil {
+		t.Fatalf("Failed to parse library_full.json: %v", err)
+	}
+	if !def.Runnable || def.Fullscreen {
+		t.Errorf("Expected runnable=1 and fullscreen=0, got %v and %v", def.Runnable, def.Fullscreen)
+	}
+	if def.CoreAPI == nil || def.CoreAPI.MinorVersion != 19 {
+		t.Errorf("Expected coreApi 1.19, got %+v", def.CoreAPI)
+	}
+	if len(def.DynamicDependencies) != 1 || len(def.EditorDependencies) != 1 {
+		t.Errorf("Expected dynamic and editor dependencies to be loaded")
+	}
+	if def.MetadataSettings == nil || !def.MetadataSettings.DisableExtraTitleField {
+		t.Errorf("Expected metadataSettings.disableExtraTitleField to be set")
+	}
+
+	firstFile := writeTestZip(t, map[string][]byte{
+		"H5P.QuestionSet-1.20/library.json": original,
+	})
+	loaded, err := LoadH5PPackage(firstFile)
+	if err != nil {
+		t.Fatalf("Failed to load H5P package: %v", err)
+	}
+
+	secondFile := filepath.Join(t.TempDir(), "second.h5p")
+	if err := loaded.CreateZipFile(secondFile); err != nil {
+		t.Fatalf("Failed to create H5P package: %v", err)
+	}
+
+	written := readZipEntry(t, secondFile, "H5P.QuestionSet-1.20/library.json")
+	if !bytes.Equal(written, original) {
+		t.Errorf("library.json was not preserved byte-for-byte:\n%s", written)
+	}
+
+	loaded.Libraries[0].Definition.PatchVersion = 4
+	thirdFile := filepath.Join(t.TempDir(), "third.h5p")
+	if err := loaded.CreateZipFile(thirdFile); err != nil {
+		t.Fatalf("Failed to create H5P package: %v", err)
+	}
+	if bytes.Equal(readZipEntry(t, thirdFile, "H5P.QuestionSet-1.20/library.json"), original) {
+		t.Error("Modified library.json should not reuse the original bytes")
+	}
+}
+
+func TestParseLibraryFolderName(t *testing.T) {
+	var folderTests = []struct {
+		folder string
+		name   string
+		major  int
+		minor  int
+		valid  bool
+	}{
+		{"H5P.MultiChoice-1.16", "H5P.MultiChoice", 1, 16, true},
+		{"jQuery.ui-1.10", "jQuery.ui", 1, 10, true},
+		{"FontAwesome-4.5", "FontAwesome", 4, 5, true},
+		{"H5P.MultiChoice", "", 0, 0, false},
+		{"H5P.MultiChoice-1", "", 0, 0, false},
+		{"H5P.MultiChoice-a.b", "", 0, 0, false},
+	}
+
+	for _, tt := range folderTests {
+		name, major, minor, err := ParseLibraryFolderName(tt.folder)
+		if (err == nil) != tt.valid {
+			t.Errorf("ParseLibraryFolderName(%q) error = %v, want valid %v", tt.folder, err, tt.valid)
+			continue
+		}
+		if name != tt.name || major != tt.major || minor != tt.minor {
+			t.Errorf("ParseLibraryFolderName(%q) = %s %d.%d", tt.folder, name, major, minor)
+		}
+	}
+}
+
+func readZipEntry(t *testing.T, zipPath, name string) []byte {
+	t.Helper()
+	r, err := zip.OpenReader(zipPath)
+	if err != nil {
+		t.Fatalf("Failed to open %s: %v", zipPath, err)
+	}
+	defer r.Close()
+	for _, f := range r.File {
+		if f.Name != name {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("Failed to open %s: %v", name, err)
+		}
+		defer rc.Close()
+		data, err := io.ReadAll(rc)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", name, err)
+		}
+		return data
+	}
+	t.Fatalf("Entry %s not found in %s", name, zipPath)
+	return nil
+}
+
+func writeTestZip(t *testing.T, files map[string][]byte) string {
+	t.Helper()
+	zipPath := filepath.Join(t.TempDir(), "test.h5p")
+	file, err := os.Create(zipPath)
+	if err != nil {
+		t.Fatalf("Failed to create %s: %v", zipPath, err)
+	}
+	defer file.Close()
+	zw := zip.NewWriter(file)
+	for name, data := range files {
+		if err := writeFileToZip(zw, name, data); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("Failed to close zip: %v", err)
+	}
+	return zipPath
+}
@@ -0,0 +1,78 @@
+package h5p
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/grokify/h5p-go/licenses"
+)
+
+// ValidateLicense checks that License is an official H5P license identifier
+// and LicenseVersion is valid for it. An empty License is treated as
+// undisclosed.
+func (def *PackageDefinition) ValidateLicense() error {
+	license := def.License
+	if license == "" {
+		license = licenses.Undisclosed
+	}
+	if err := licenses.Validate(license, def.LicenseVersion); err != nil {
+		return fmt.Errorf("invalid license: %w", err)
+	}
+	return nil
+}
+
+// LicenseMetadata returns the license metadata of h5p.json. The legacy
+// author field is used when authors is empty.
+func (def *PackageDefinition) LicenseMetadata() licenses.Metadata {
+	m := licenses.Metadata{
+		Title:          def.Title,
+		Source:         def.Source,
+		YearFrom:       def.YearFrom,
+		YearTo:         def.YearTo,
+		License:        def.License,
+		LicenseVersion: def.LicenseVersion,
+	}
+	for _, a := range def.Authors {
+		m.Authors = append(m.Authors, licenses.Author{Name: a.Name, Role: a.Role})
+	}
+	if len(m.Authors) == 0 && def.Author != "" {
+		m.Authors = []licenses.Author{{Name: def.Author}}
+	}
+	return m
+}
+
+// Attribution returns attribution text for the package, see
+// licenses.Attribution.
+func (def *PackageDefinition) Attribution() string {
+	return licenses.Attribution(def.LicenseMetadata())
+}
+
+// CopyrightAttribution returns attribution text for media copyright
+// metadata, see licenses.Attribution.
+func CopyrightAttribution(c *Copyright) string {
+	return licenses.Attribution(CopyrightLicenseMetadata(c))
+}
+
+// CopyrightLicenseMetadata returns the license metadata of media copyright
+// metadata. A year such as "2021" or "2019-2021" gives the year range;
+// other year texts are left out.
+func CopyrightLicenseMetadata(c *Copyright) licenses.Metadata {
+	m := licenses.Metadata{
+		Title:          c.Title,
+		Source:         c.Source,
+		License:        c.License,
+		LicenseVersion: c.Version,
+	}
+	if c.Author != "" {
+		m.Authors = []licenses.Author{{Name: c.Author}}
+	}
+	from, to, _ := strings.Cut(strings.TrimSpace(c.Year), "-")
+	if y, err := strconv.Atoi(strings.TrimSpace(from)); err == nil {
+		m.YearFrom = y
+	}
+	if y, err := strconv.Atoi(strings.TrimSpace(to)); err == nil {
+		m.YearTo = y
+	}
+	return m
+}
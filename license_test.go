@@ -0,0 +1,42 @@
+package h5p
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPackageDefinitionLicense(t *testing.T) {
+	pkg := loadTestPackage(t)
+	def := pkg.PackageDefinition
+	if err := def.ValidateLicense(); err != nil {
+		t.Errorf("Expected undisclosed license to be valid: %v", err)
+	}
+
+	def.License = "CC BY"
+	def.LicenseVersion = "4.0"
+	def.YearFrom = 2024
+	if got, want := def.Attribution(), `"Geography Quiz - Capital of France" by Test Author (2024), licensed under CC BY 4.0`; !strings.HasPrefix(got, want) {
+		t.Errorf("Expected attribution to start with %q, got %q", want, got)
+	}
+
+	def.LicenseVersion = "v3"
+	if err := def.ValidateLicense(); err == nil {
+		t.Error("Expected error for CC BY v3")
+	}
+	if err := pkg.ValidateStructure(); err == nil || !strings.Contains(err.Error(), "invalid license") {
+		t.Errorf("Expected structure validation to report the license, got %v", err)
+	}
+
+	c := &Copyright{Title: "Eiffel Tower", Author: "Ann", License: "CC BY-SA", Version: "2.0"}
+	if got := CopyrightAttribution(c); !strings.Contains(got, "licensed under CC BY-SA 2.0") {
+		t.Errorf("Unexpected copyright attribution: %q", got)
+	}
+	c.Year = "2019 - 2021"
+	if got := CopyrightAttribution(c); !strings.Contains(got, "by Ann (2019-2021), licensed") {
+		t.Errorf("Expected the year range in the attribution, got %q", got)
+	}
+	c.Year = "circa 1900"
+	if m := CopyrightLicenseMetadata(c); m.YearFrom != 0 || m.YearTo != 0 {
+		t.Errorf("Expected free text years left out, got %+v", m)
+	}
+}
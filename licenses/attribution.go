@@ -0,0 +1,83 @@
+package licenses
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Author is a named contributor with an optional role such as "Author" or
+// "Editor".
+type Author struct {
+	Name string
+	Role string
+}
+
+// Metadata is the license metadata of a package or media file.
+type Metadata struct {
+	Title          string
+	Authors        []Author
+	Source         string
+	YearFrom       int
+	YearTo         int
+	License        string
+	LicenseVersion string
+}
+
+// Attribution returns attribution text for the metadata, e.g.
+// `"Geography Quiz" by Jane Doe (2023), licensed under CC BY-SA 4.0
+// (https://creativecommons.org/licenses/by-sa/4.0/). Source: https://example.com`.
+func Attribution(m Metadata) string {
+	var b strings.Builder
+	if m.Title != "" {
+		fmt.Fprintf(&b, "%q", m.Title)
+	} else {
+		b.WriteString("This work")
+	}
+
+	names := make([]string, 0, len(m.Authors))
+	for _, a := range m.Authors {
+		if a.Name == "" {
+			continue
+		}
+		if a.Role != "" && a.Role != "Author" {
+			names = append(names, fmt.Sprintf("%s (%s)", a.Name, a.Role))
+		} else {
+			names = append(names, a.Name)
+		}
+	}
+	if len(names) > 0 {
+		b.WriteString(" by " + strings.Join(names, ", "))
+	}
+	if years := yearRange(m.YearFrom, m.YearTo); years != "" {
+		b.WriteString(" (" + years + ")")
+	}
+
+	if l, ok := Lookup(m.License); ok && m.License != Undisclosed {
+		if m.License == Copyright {
+			b.WriteString(", all rights reserved")
+		} else {
+			b.WriteString(", licensed under " + l.Label(m.LicenseVersion))
+			if u := l.URL(m.LicenseVersion); u != "" {
+				b.WriteString(" (" + u + ")")
+			}
+		}
+	}
+	b.WriteString(".")
+
+	if m.Source != "" {
+		b.WriteString(" Source: " + m.Source)
+	}
+	return b.String()
+}
+
+func yearRange(from, to int) string {
+	switch {
+	case from > 0 && to > 0 && to != from:
+		return fmt.Sprintf("%d-%d", from, to)
+	case from > 0:
+		return fmt.Sprint(from)
+	case to > 0:
+		return fmt.Sprint(to)
+	}
+	return ""
+}
@@ -0,0 +1,147 @@
+// Package licenses provides the license identifiers H5P uses in h5p.json and
+// media copyright metadata, validation of license and version combinations,
+// and attribution text generation.
+package licenses
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Official H5P license identifiers.
+const (
+	Undisclosed  = "U"
+	CCBY         = "CC BY"
+	CCBYSA       = "CC BY-SA"
+	CCBYND       = "CC BY-ND"
+	CCBYNC       = "CC BY-NC"
+	CCBYNCSA     = "CC BY-NC-SA"
+	CCBYNCND     = "CC BY-NC-ND"
+	CC0          = "CC0 1.0"
+	GPL          = "GNU GPL"
+	PublicDomain = "PD"
+	ODCPDDL      = "ODC PDDL"
+	CCPDM        = "CC PDM"
+	Copyright    = "C"
+)
+
+var (
+	ErrUnknownLicense = errors.New("unknown license")
+	ErrInvalidVersion = errors.New("invalid license version")
+)
+
+// License describes an H5P license and the versions it may be combined with.
+type License struct {
+	ID       string
+	Name     string
+	Versions []string
+
+	// urls maps versions to license deed URLs; "" is the unversioned URL.
+	urls map[string]string
+}
+
+var ccVersions = []string{"4.0", "3.0", "2.5", "2.0", "1.0"}
+
+func ccURLs(code string) map[string]string {
+	urls := make(map[string]string, len(ccVersions))
+	for _, v := range ccVersions {
+		urls[v] = fmt.Sprintf("https://creativecommons.org/licenses/%s/%s/", code, v)
+	}
+	return urls
+}
+
+var all = []License{
+	{ID: Undisclosed, Name: "Undisclosed"},
+	{ID: CCBY, Name: "Attribution", Versions: ccVersions, urls: ccURLs("by")},
+	{ID: CCBYSA, Name: "Attribution-ShareAlike", Versions: ccVersions, urls: ccURLs("by-sa")},
+	{ID: CCBYND, Name: "Attribution-NoDerivs", Versions: ccVersions, urls: ccURLs("by-nd")},
+	{ID: CCBYNC, Name: "Attribution-NonCommercial", Versions: ccVersions, urls: ccURLs("by-nc")},
+	{ID: CCBYNCSA, Name: "Attribution-NonCommercial-ShareAlike", Versions: ccVersions, urls: ccURLs("by-nc-sa")},
+	{ID: CCBYNCND, Name: "Attribution-NonCommercial-NoDerivs", Versions: ccVersions, urls: ccURLs("by-nc-nd")},
+	{ID: CC0, Name: "Public Domain Dedication", urls: map[string]string{"": "https://creativecommons.org/publicdomain/zero/1.0/"}},
+	{ID: GPL, Name: "General Public License", Versions: []string{"v3", "v2", "v1"}, urls: map[string]string{
+		"v3": "https://www.gnu.org/licenses/gpl-3.0",
+		"v2": "https://www.gnu.org/licenses/old-licenses/gpl-2.0",
+		"v1": "https://www.gnu.org/licenses/old-licenses/gpl-1.0",
+	}},
+	{ID: PublicDomain, Name: "Public Domain", Versions: []string{"-", CC0, CCPDM}, urls: map[string]string{
+		CC0:   "https://creativecommons.org/publicdomain/zero/1.0/",
+		CCPDM: "https://creativecommons.org/publicdomain/mark/1.0/",
+	}},
+	{ID: ODCPDDL, Name: "Public Domain Dedication and Licence", urls: map[string]string{"": "https://opendatacommons.org/licenses/pddl/1-0/"}},
+	{ID: CCPDM, Name: "Public Domain Mark", urls: map[string]string{"": "https://creativecommons.org/publicdomain/mark/1.0/"}},
+	{ID: Copyright, Name: "Copyright"},
+}
+
+// All returns every official H5P license.
+func All() []License {
+	out := make([]License, len(all))
+	copy(out, all)
+	return out
+}
+
+// Lookup returns the license with the given identifier.
+func Lookup(id string) (License, bool) {
+	for _, l := range all {
+		if l.ID == id {
+			return l, true
+		}
+	}
+	return License{}, false
+}
+
+// Validate checks that id is an H5P license and version is one of its
+// versions. Licenses without versions accept only an empty version; an
+// empty version is also accepted for versioned licenses, as H5P then uses
+// the latest one.
+func Validate(id, version string) error {
+	l, ok := Lookup(id)
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownLicense, id)
+	}
+	if version == "" {
+		return nil
+	}
+	for _, v := range l.Versions {
+		if v == version {
+			return nil
+		}
+	}
+	if len(l.Versions) == 0 {
+		return fmt.Errorf("%w: %s does not take a version, got %q", ErrInvalidVersion, id, version)
+	}
+	return fmt.Errorf("%w: %s %q, expected one of %s", ErrInvalidVersion, id, version, strings.Join(l.Versions, ", "))
+}
+
+// URL returns the license deed URL for the given version, or "" if there
+// is none. An empty version uses the latest one.
+func (l License) URL(version string) string {
+	if version == "" && len(l.Versions) > 0 {
+		version = l.Versions[0]
+	}
+	if u, ok := l.urls[version]; ok {
+		return u
+	}
+	return l.urls[""]
+}
+
+// Label returns the license as shown to users, e.g. "CC BY-SA 4.0" or
+// "Undisclosed". An empty version uses the latest one.
+func (l License) Label(version string) string {
+	if version == "" && len(l.Versions) > 0 {
+		version = l.Versions[0]
+	}
+	switch {
+	case l.ID == Undisclosed || l.ID == Copyright:
+		return l.Name
+	case l.ID == PublicDomain && version != "-":
+		return version
+	case l.ID == PublicDomain:
+		return l.Name
+	case version == "":
+		return l.ID
+	default:
+		return l.ID + " " + version
+	}
+}
@@ -0,0 +1,79 @@
+package licenses
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	var validateTests = []struct {
+		id      string
+		version string
+		wantErr error
+	}{
+		{CCBYSA, "4.0", nil},
+		{CCBY, "", nil},
+		{GPL, "v3", nil},
+		{PublicDomain, CCPDM, nil},
+		{Undisclosed, "", nil},
+		{Copyright, "", nil},
+		{CCBY, "5.0", ErrInvalidVersion},
+		{GPL, "3.0", ErrInvalidVersion},
+		{Copyright, "1.0", ErrInvalidVersion},
+		{"MIT", "", ErrUnknownLicense},
+		{"cc by", "4.0", ErrUnknownLicense},
+	}
+
+	for _, tt := range validateTests {
+		err := Validate(tt.id, tt.version)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("Validate(%q, %q) = %v, want %v", tt.id, tt.version, err, tt.wantErr)
+		}
+	}
+}
+
+func TestLicenseLabelAndURL(t *testing.T) {
+	l, _ := Lookup(CCBYNCSA)
+	if got := l.Label(""); got != "CC BY-NC-SA 4.0" {
+		t.Errorf("Expected latest version label, got %q", got)
+	}
+	if got := l.URL("3.0"); got != "https://creativecommons.org/licenses/by-nc-sa/3.0/" {
+		t.Errorf("Unexpected URL: %q", got)
+	}
+	pd, _ := Lookup(PublicDomain)
+	if got := pd.Label(CC0); got != CC0 {
+		t.Errorf("Expected %q, got %q", CC0, got)
+	}
+	c, _ := Lookup(Copyright)
+	if got := c.URL(""); got != "" {
+		t.Errorf("Expected no URL for copyright, got %q", got)
+	}
+}
+
+func TestAttribution(t *testing.T) {
+	var attributionTests = []struct {
+		m    Metadata
+		want string
+	}{
+		{
+			Metadata{
+				Title:          "Geography Quiz",
+				Authors:        []Author{{Name: "Jane Doe", Role: "Author"}, {Name: "John Roe", Role: "Editor"}},
+				YearFrom:       2020,
+				YearTo:         2023,
+				License:        CCBYSA,
+				LicenseVersion: "4.0",
+				Source:         "https://example.com/quiz",
+			},
+			`"Geography Quiz" by Jane Doe, John Roe (Editor) (2020-2023), licensed under CC BY-SA 4.0 (https://creativecommons.org/licenses/by-sa/4.0/). Source: https://example.com/quiz`,
+		},
+		{Metadata{Title: "Photo", Authors: []Author{{Name: "Ann"}}, License: Copyright, YearFrom: 2021}, `"Photo" by Ann (2021), all rights reserved.`},
+		{Metadata{License: Undisclosed}, "This work."},
+	}
+
+	for _, tt := range attributionTests {
+		if got := Attribution(tt.m); got != tt.want {
+			t.Errorf("Attribution() = %q, want %q", got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package h5p
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	_ "image/gif"  // register GIF decoder for dimensions
+	_ "image/jpeg" // register JPEG decoder for dimensions
+	_ "image/png"  // register PNG decoder for dimensions
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/grokify/h5p-go/schemas"
+)
+
+// ImageLibrary is the library string used for image media.
+const ImageLibrary = "H5P.Image 1.1"
+
+// ImageReference is an image stored in the package content folder, ready to
+// be referenced from params.
+type ImageReference struct {
+	Path      string
+	Mime      string
+	Width     int
+	Height    int
+	Copyright *Copyright
+}
+
+// AddImageToContent copies the image at localPath into content/images/ of
+// the package, detecting its mime type and dimensions. A numeric suffix is
+// added if a file with the same name already exists. Options can limit the
+// image size and convert its format, see ImageOption; they need a JPEG,
+// PNG or GIF image.
+func AddImageToContent(pkg *H5PPackage, localPath string, copyright *Copyright, opts ...ImageOption) (*ImageReference, error) {
+	data, err := os.ReadFile(localPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image: %w", err)
+	}
+
+	mimeType := mimeTypeByPath(localPath)
+	if !strings.HasPrefix(mimeType, "image/") {
+		return nil, fmt.Errorf("not an image file: %s", localPath)
+	}
+	name := filepath.Base(localPath)
+
+	ref := &ImageReference{Mime: mimeType, Copyright: copyright}
+	if len(opts) > 0 {
+		var o imageOptions
+		for _, opt := range opts {
+			opt(&o)
+		}
+		var format string
+		data, format, ref.Width, ref.Height, err = o.process(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to process image %s: %w", localPath, err)
+		}
+		name = withImageExt(name, format)
+		_, ref.Mime = imageFormatExt(format)
+	} else if cfg, _, err := image.DecodeConfig(bytes.NewReader(data)); err == nil {
+		ref.Width = cfg.Width
+		ref.Height = cfg.Height
+	}
+
+	ref.Path = pkg.uniqueContentPath("images", name)
+	if err := pkg.AddContentAsset(ref.Path, data, ref.Mime); err != nil {
+		return nil, err
+	}
+	return ref, nil
+}
+
+// BackgroundImage returns the reference as a QuestionSet background image.
+func (ref *ImageReference) BackgroundImage() *BackgroundImage {
+	return &BackgroundImage{
+		Path:      ref.Path,
+		Mime:      ref.Mime,
+		Copyright: ref.Copyright,
+		Width:     ref.Width,
+		Height:    ref.Height,
+	}
+}
+
+// ImageFile returns the reference as an image field value.
+func (ref *ImageReference) ImageFile() *schemas.ImageFile {
+	return &schemas.ImageFile{
+		Path:      ref.Path,
+		Mime:      ref.Mime,
+		Copyright: ref.Copyright,
+		Width:     ref.Width,
+		Height:    ref.Height,
+	}
+}
+
+// MediaGroup returns the reference as question media, e.g. for
+// MultiChoiceParams.Media, with alt as the alternative text.
+func (ref *ImageReference) MediaGroup(alt string) *schemas.MediaGroup {
+	return &schemas.MediaGroup{
+		Type: &schemas.MediaContent{
+			Library: ImageLibrary,
+			Params: &schemas.ImageParams{
+				ContentName: "Image",
+				File:        ref.ImageFile(),
+				Alt:         alt,
+			},
+		},
+	}
+}
+
+// uniqueContentPath returns dir/name, adding a numeric suffix when a content
+// file with that path already exists.
+func (pkg *H5PPackage) uniqueContentPath(dir, name string) string {
+	ext := path.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	candidate := path.Join(dir, name)
+	for i := 1; ; i++ {
+		if _, exists := pkg.ContentFiles[candidate]; !exists {
+			return candidate
+		}
+		candidate = path.Join(dir, fmt.Sprintf("%s-%d%s", base, i, ext))
+	}
+}
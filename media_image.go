@@ -0,0 +1,346 @@
+package h5p
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"path"
+	"strings"
+)
+
+// Image formats for WithImageFormat and EncodeImage.
+const (
+	ImageFormatJPEG = "jpeg"
+	ImageFormatPNG  = "png"
+)
+
+var (
+	// ErrImageTooLarge is returned when an image exceeds the maximum size
+	// set with WithMaxImageSize.
+	ErrImageTooLarge = errors.New("image exceeds maximum size")
+	// ErrImageFormat is returned for an image format that cannot be
+	// decoded or encoded.
+	ErrImageFormat = errors.New("unsupported image format")
+)
+
+// ImageOption configures how AddImageToContent processes an image.
+type ImageOption func(*imageOptions)
+
+type imageOptions struct {
+	maxWidth, maxHeight int
+	fit                 bool
+	format              string
+	quality             int
+}
+
+// WithMaxImageSize rejects images wider or taller than the given size with
+// ErrImageTooLarge. A zero width or height is unbounded.
+func WithMaxImageSize(width, height int) ImageOption {
+	return func(o *imageOptions) {
+		o.maxWidth, o.maxHeight, o.fit = width, height, false
+	}
+}
+
+// WithImageFit scales images wider or taller than the given size down to
+// fit it, keeping their aspect ratio. A zero width or height is unbounded.
+func WithImageFit(width, height int) ImageOption {
+	return func(o *imageOptions) {
+		o.maxWidth, o.maxHeight, o.fit = width, height, true
+	}
+}
+
+// WithImageFormat converts images to ImageFormatJPEG or ImageFormatPNG,
+// e.g. to store photos as JPEG rather than large PNG files. Transparent
+// areas are made white in JPEG images.
+func WithImageFormat(format string) ImageOption {
+	return func(o *imageOptions) {
+		o.format = format
+	}
+}
+
+// WithJPEGQuality sets the quality, 1 to 100, of JPEG images that are
+// encoded. The default is jpeg.DefaultQuality.
+func WithJPEGQuality(quality int) ImageOption {
+	return func(o *imageOptions) {
+		o.quality = quality
+	}
+}
+
+// process applies the options to the image data, returning the data to
+// store, its format and dimensions. Images are only re-encoded when they
+// are resized or converted.
+func (o *imageOptions) process(data []byte) (out []byte, format string, width, height int, err error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", 0, 0, fmt.Errorf("%w: %v", ErrImageFormat, err)
+	}
+	width, height = cfg.Width, cfg.Height
+	w, h := fitImageSize(width, height, o.maxWidth, o.maxHeight)
+	resize := w != width || h != height
+	if resize && !o.fit {
+		return nil, "", 0, 0, fmt.Errorf("%w: %dx%d is larger than %dx%d", ErrImageTooLarge, width, height, o.maxWidth, o.maxHeight)
+	}
+	target := format
+	if o.format != "" {
+		target = o.format
+	}
+	if !resize && target == format {
+		return data, format, width, height, nil
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", 0, 0, fmt.Errorf("%w: %v", ErrImageFormat, err)
+	}
+	if resize {
+		img = ResizeImage(img, w, h)
+	}
+	if target == "gif" {
+		// Resized GIF images keep only their first frame, so they are
+		// stored as PNG.
+		target = ImageFormatPNG
+	}
+	var buf bytes.Buffer
+	if err := EncodeImage(&buf, img, target, o.quality); err != nil {
+		return nil, "", 0, 0, err
+	}
+	return buf.Bytes(), target, w, h, nil
+}
+
+// fitImageSize returns the size of a width x height image scaled down to
+// fit maxWidth x maxHeight, keeping its aspect ratio. A zero maximum is
+// unbounded.
+func fitImageSize(width, height, maxWidth, maxHeight int) (int, int) {
+	scale := 1.0
+	if maxWidth > 0 && width > maxWidth {
+		scale = float64(maxWidth) / float64(width)
+	}
+	if maxHeight > 0 && height > maxHeight {
+		scale = min(scale, float64(maxHeight)/float64(height))
+	}
+	if scale == 1 {
+		return width, height
+	}
+	return max(1, int(float64(width)*scale+0.5)), max(1, int(float64(height)*scale+0.5))
+}
+
+// ResizeImage returns img scaled to width x height. Each pixel is the
+// average of the source pixels it covers, which keeps detail when
+// scaling down, e.g. for thumbnails.
+func ResizeImage(img image.Image, width, height int) *image.RGBA {
+	b := img.Bounds()
+	src, ok := img.(*image.RGBA)
+	if !ok || b.Min != (image.Point{}) {
+		src = image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+		draw.Draw(src, src.Bounds(), img, b.Min, draw.Src)
+	}
+	sw, sh := src.Bounds().Dx(), src.Bounds().Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := range height {
+		y0, y1 := y*sh/height, max((y+1)*sh/height, y*sh/height+1)
+		for x := range width {
+			x0, x1 := x*sw/width, max((x+1)*sw/width, x*sw/width+1)
+			var sum [4]int
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride+x0*4 : sy*src.Stride+x1*4]
+				for i := 0; i < len(row); i += 4 {
+					sum[0] += int(row[i])
+					sum[1] += int(row[i+1])
+					sum[2] += int(row[i+2])
+					sum[3] += int(row[i+3])
+				}
+			}
+			n := (x1 - x0) * (y1 - y0)
+			i := dst.PixOffset(x, y)
+			for c := range sum {
+				dst.Pix[i+c] = uint8((sum[c] + n/2) / n)
+			}
+		}
+	}
+	return dst
+}
+
+// EncodeImage writes img in ImageFormatJPEG or ImageFormatPNG. A zero
+// quality uses jpeg.DefaultQuality.
+func EncodeImage(w io.Writer, img image.Image, format string, quality int) error {
+	switch format {
+	case ImageFormatJPEG:
+		if quality == 0 {
+			quality = jpeg.DefaultQuality
+		}
+		// JPEG has no transparency, so draw the image on white rather than
+		// letting transparent pixels turn black.
+		opaque := image.NewRGBA(img.Bounds())
+		draw.Draw(opaque, opaque.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+		draw.Draw(opaque, opaque.Bounds(), img, img.Bounds().Min, draw.Over)
+		return jpeg.Encode(w, opaque, &jpeg.Options{Quality: quality})
+	case ImageFormatPNG:
+		return png.Encode(w, img)
+	case "gif":
+		return gif.Encode(w, img, nil)
+	}
+	return fmt.Errorf("%w: %q", ErrImageFormat, format)
+}
+
+// imageFormatExt returns the file extension and mime type of an image
+// format as image.Decode names it.
+func imageFormatExt(format string) (ext, mimeType string) {
+	switch format {
+	case ImageFormatJPEG:
+		return ".jpg", "image/jpeg"
+	case ImageFormatPNG:
+		return ".png", "image/png"
+	case "gif":
+		return ".gif", "image/gif"
+	}
+	return "", ""
+}
+
+// withImageExt returns name with the extension of format, keeping
+// extensions such as ".jpeg" that already match it.
+func withImageExt(name, format string) string {
+	ext, mimeType := imageFormatExt(format)
+	if ext == "" || mimeTypeByPath(name) == mimeType {
+		return name
+	}
+	return strings.TrimSuffix(name, path.Ext(name)) + ext
+}
+
+// AddImageVariant stores a copy of the image at ref scaled down to fit
+// maxWidth x maxHeight next to it, named with the suffix, e.g.
+// "images/paris-thumb.png" for the suffix "thumb". The variant has the
+// copyright of ref. Images that already fit are copied unchanged.
+func (pkg *H5PPackage) AddImageVariant(ref *ImageReference, suffix string, maxWidth, maxHeight int, opts ...ImageOption) (*ImageReference, error) {
+	cf, ok := pkg.ContentFiles[ref.Path]
+	if !ok {
+		return nil, fmt.Errorf("image not found in content folder: %s", ref.Path)
+	}
+	data, err := cf.Bytes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image: %w", err)
+	}
+	o := imageOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	o.maxWidth, o.maxHeight, o.fit = maxWidth, maxHeight, true
+	data, format, width, height, err := o.process(data)
+	if err != nil {
+		return nil, err
+	}
+
+	ext := path.Ext(ref.Path)
+	name := withImageExt(strings.TrimSuffix(path.Base(ref.Path), ext)+"-"+suffix+ext, format)
+	_, mimeType := imageFormatExt(format)
+	variantPath := pkg.uniqueContentPath(path.Dir(ref.Path), name)
+	if err := pkg.AddContentAsset(variantPath, data, mimeType); err != nil {
+		return nil, err
+	}
+	return &ImageReference{
+		Path:      variantPath,
+		Mime:      mimeType,
+		Width:     width,
+		Height:    height,
+		Copyright: ref.Copyright,
+	}, nil
+}
+
+// FillImageDimensions sets the width and height the player uses for layout
+// on image references in the content params that lack them, reading the
+// dimensions from the images in the content folder. It returns the number
+// of references changed. Content params are rewritten in their generic
+// JSON form when a reference changes.
+func (pkg *H5PPackage) FillImageDimensions() (int, error) {
+	if pkg.Content == nil {
+		return 0, nil
+	}
+	params, err := toGenericJSON(pkg.Content)
+	if err != nil {
+		return 0, err
+	}
+	n, err := pkg.fillImageDimensions(params)
+	if err != nil || n == 0 {
+		return n, err
+	}
+
+	data, err := json.Marshal(params)
+	if err != nil {
+		return 0, err
+	}
+	var content Content
+	if err := json.Unmarshal(data, &content); err != nil {
+		return 0, err
+	}
+	pkg.Content = &content
+	return n, nil
+}
+
+func (pkg *H5PPackage) fillImageDimensions(v any) (int, error) {
+	n := 0
+	switch t := v.(type) {
+	case map[string]any:
+		if p, ok := t["path"].(string); ok && (t["width"] == nil || t["height"] == nil) {
+			changed, err := pkg.setImageDimensions(t, p)
+			if err != nil {
+				return n, err
+			}
+			if changed {
+				n++
+			}
+		}
+		for _, child := range t {
+			c, err := pkg.fillImageDimensions(child)
+			n += c
+			if err != nil {
+				return n, err
+			}
+		}
+	case []any:
+		for _, item := range t {
+			c, err := pkg.fillImageDimensions(item)
+			n += c
+			if err != nil {
+				return n, err
+			}
+		}
+	}
+	return n, nil
+}
+
+// setImageDimensions sets width and height on the reference obj if p is an
+// image in the content folder.
+func (pkg *H5PPackage) setImageDimensions(obj map[string]any, p string) (bool, error) {
+	local, ok := localAssetPath(p)
+	if !ok {
+		return false, nil
+	}
+	mimeType, _ := obj["mime"].(string)
+	if mimeType == "" {
+		mimeType = mimeTypeByPath(local)
+	}
+	cf, ok := pkg.ContentFiles[local]
+	if !ok || !strings.HasPrefix(mimeType, "image/") {
+		return false, nil
+	}
+	rc, err := cf.Open()
+	if err != nil {
+		return false, fmt.Errorf("failed to read image %s: %w", local, err)
+	}
+	defer rc.Close()
+	cfg, _, err := image.DecodeConfig(rc)
+	if err != nil {
+		// Formats without a registered decoder, such as SVG, have no
+		// dimensions to read.
+		return false, nil
+	}
+	obj["width"], obj["height"] = cfg.Width, cfg.Height
+	return true, nil
+}
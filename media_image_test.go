@@ -0,0 +1,97 @@
+package h5p
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestAddImageToContentOptions(t *testing.T) {
+	imgPath := writeTestPNG(t, 400, 200)
+
+	pkg := NewH5PPackage()
+	if _, err := AddImageToContent(pkg, imgPath, nil, WithMaxImageSize(300, 300)); !errors.Is(err, ErrImageTooLarge) {
+		t.Errorf("Expected ErrImageTooLarge, got %v", err)
+	}
+	if len(pkg.ContentFiles) != 0 {
+		t.Error("Expected a rejected image not to be stored")
+	}
+
+	ref, err := AddImageToContent(pkg, imgPath, nil, WithImageFit(100, 100), WithImageFormat(ImageFormatJPEG))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ref.Path != "images/paris.jpg" || ref.Mime != "image/jpeg" || ref.Width != 100 || ref.Height != 50 {
+		t.Errorf("Unexpected reference %+v", ref)
+	}
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(pkg.ContentFiles[ref.Path].Data))
+	if err != nil || format != "jpeg" || cfg.Width != 100 || cfg.Height != 50 {
+		t.Errorf("Unexpected stored image %s %+v: %v", format, cfg, err)
+	}
+
+	ref, err = AddImageToContent(pkg, imgPath, nil, WithImageFit(1000, 1000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ref.Path != "images/paris.png" || ref.Width != 400 {
+		t.Errorf("Expected a fitting image stored unchanged, got %+v", ref)
+	}
+
+	thumb, err := pkg.AddImageVariant(ref, "thumb", 40, 40)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if thumb.Path != "images/paris-thumb.png" || thumb.Width != 40 || thumb.Height != 20 || thumb.Mime != "image/png" {
+		t.Errorf("Unexpected thumbnail %+v", thumb)
+	}
+}
+
+func TestResizeImage(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	for x := range 2 {
+		for y := range 2 {
+			img.Set(x, y, color.White)
+		}
+	}
+	got := ResizeImage(img, 2, 1)
+	if c := got.RGBAAt(0, 0); c != (color.RGBA{255, 255, 255, 255}) {
+		t.Errorf("Expected a white left pixel, got %v", c)
+	}
+	if c := got.RGBAAt(1, 0); c != (color.RGBA{}) {
+		t.Errorf("Expected a transparent right pixel, got %v", c)
+	}
+	if got := ResizeImage(img, 8, 4); got.RGBAAt(3, 3) != (color.RGBA{255, 255, 255, 255}) {
+		t.Error("Expected scaling up to repeat pixels")
+	}
+
+	if w, h := fitImageSize(300, 100, 150, 0); w != 150 || h != 50 {
+		t.Errorf("Expected 150x50, got %dx%d", w, h)
+	}
+}
+
+func TestFillImageDimensions(t *testing.T) {
+	imgPath := writeTestPNG(t, 64, 32)
+	pkg := NewH5PPackage()
+	ref, err := AddImageToContent(pkg, imgPath, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg.SetContent(&Content{Params: map[string]any{
+		"media": map[string]any{"library": ImageLibrary, "params": map[string]any{"file": map[string]any{"path": ref.Path, "mime": "image/png"}}},
+		"video": map[string]any{"path": "https://example.com/a.png"},
+	}})
+
+	n, err := pkg.FillImageDimensions()
+	if err != nil || n != 1 {
+		t.Fatalf("Expected one reference filled, got %d: %v", n, err)
+	}
+	file := pkg.Content.Params.(map[string]any)["media"].(map[string]any)["params"].(map[string]any)["file"].(map[string]any)
+	if file["width"] != float64(64) || file["height"] != float64(32) {
+		t.Errorf("Unexpected dimensions %v", file)
+	}
+	if n, err := pkg.FillImageDimensions(); err != nil || n != 0 {
+		t.Errorf("Expected nothing left to fill, got %d: %v", n, err)
+	}
+}
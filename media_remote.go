@@ -0,0 +1,257 @@
+package h5p
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/grokify/h5p-go/schemas"
+)
+
+// DefaultRemoteAssetMaxSize is the download size limit of
+// AddContentAssetFromURL when RemoteAssetOptions.MaxSize is zero.
+const DefaultRemoteAssetMaxSize = 64 << 20
+
+var (
+	// ErrMediaType is returned for a download whose media type is not
+	// allowed.
+	ErrMediaType = errors.New("media type not allowed")
+	// ErrDownloadStatus is returned when a download responds with a status
+	// other than 200 OK.
+	ErrDownloadStatus = errors.New("unexpected download response status")
+)
+
+// RemoteAssetOptions controls AddContentAssetFromURL.
+type RemoteAssetOptions struct {
+	// HTTPClient defaults to http.DefaultClient.
+	HTTPClient *http.Client
+	// MaxSize is the largest download in bytes, DefaultRemoteAssetMaxSize
+	// if zero. Larger files fail with ErrFileTooLarge.
+	MaxSize int64
+	// AllowedTypes lists the allowed mime types; entries ending in "/"
+	// allow a whole type, e.g. "image/". The default allows images, audio
+	// and video.
+	AllowedTypes []string
+	// Dir is the content folder directory to store the file in. The
+	// default is "images", "audios", "videos" or "files" by media type,
+	// as the H5P editor uses.
+	Dir string
+	// Copyright is set on the reference. Its Source defaults to the URL.
+	Copyright *Copyright
+	// Image options are applied to downloaded images, see ImageOption.
+	Image []ImageOption
+}
+
+var defaultRemoteAssetTypes = []string{"image/", "audio/", "video/"}
+
+// MediaReference is a media file stored in the package content folder,
+// ready to be referenced from params.
+type MediaReference struct {
+	Path string
+	Mime string
+	// Size is the file size in bytes.
+	Size int64
+	// Width and Height are set for images.
+	Width     int
+	Height    int
+	Copyright *Copyright
+	// SourceURL is the URL the file was downloaded from.
+	SourceURL string
+}
+
+// Image returns the reference as an image reference.
+func (ref *MediaReference) Image() *ImageReference {
+	return &ImageReference{
+		Path:      ref.Path,
+		Mime:      ref.Mime,
+		Width:     ref.Width,
+		Height:    ref.Height,
+		Copyright: ref.Copyright,
+	}
+}
+
+// ImageFile returns the reference as an image field value.
+func (ref *MediaReference) ImageFile() *schemas.ImageFile {
+	return ref.Image().ImageFile()
+}
+
+// AudioFile returns the reference as a source of an audio field value.
+func (ref *MediaReference) AudioFile() schemas.AudioFile {
+	return schemas.AudioFile{Path: ref.Path, Mime: ref.Mime, Copyright: ref.Copyright}
+}
+
+// VideoFile returns the reference as a source of a video field value.
+func (ref *MediaReference) VideoFile() schemas.VideoFile {
+	return schemas.VideoFile{Path: ref.Path, Mime: ref.Mime, Copyright: ref.Copyright}
+}
+
+// AddContentAssetFromURL downloads a remote image, audio or video file and
+// stores it in the content folder, named after the URL with a numeric
+// suffix if a file with that name already exists. The mime type is taken
+// from the response, the URL extension or the file contents, in that
+// order, and images get their dimensions.
+func (pkg *H5PPackage) AddContentAssetFromURL(ctx context.Context, rawURL string, opts RemoteAssetOptions) (*MediaReference, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid media URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid media URL %q: scheme must be http or https", rawURL)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	httpClient := opts.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: GET %s: %s", ErrDownloadStatus, u, resp.Status)
+	}
+
+	maxSize := opts.MaxSize
+	if maxSize <= 0 {
+		maxSize = DefaultRemoteAssetMaxSize
+	}
+	if resp.ContentLength > maxSize {
+		return nil, fmt.Errorf("%w: %s", ErrFileTooLarge, u)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", u, err)
+	}
+	if int64(len(data)) > maxSize {
+		return nil, fmt.Errorf("%w: %s", ErrFileTooLarge, u)
+	}
+
+	mimeType := remoteMimeType(resp.Header.Get("Content-Type"), u.Path, data)
+	allowed := opts.AllowedTypes
+	if allowed == nil {
+		allowed = defaultRemoteAssetTypes
+	}
+	if !mimeTypeAllowed(mimeType, allowed) {
+		return nil, fmt.Errorf("%w: %s is %s", ErrMediaType, u, mimeType)
+	}
+
+	ref := &MediaReference{Mime: mimeType, SourceURL: u.String()}
+	if opts.Copyright != nil {
+		c := *opts.Copyright
+		if c.Source == "" {
+			c.Source = ref.SourceURL
+		}
+		ref.Copyright = &c
+	}
+	name := remoteFileName(u.Path, mimeType)
+	if strings.HasPrefix(mimeType, "image/") {
+		if len(opts.Image) > 0 {
+			var o imageOptions
+			for _, opt := range opts.Image {
+				opt(&o)
+			}
+			var format string
+			data, format, ref.Width, ref.Height, err = o.process(data)
+			if err != nil {
+				return nil, fmt.Errorf("failed to process image %s: %w", u, err)
+			}
+			name = withImageExt(name, format)
+			_, ref.Mime = imageFormatExt(format)
+		} else if cfg, _, err := image.DecodeConfig(bytes.NewReader(data)); err == nil {
+			ref.Width, ref.Height = cfg.Width, cfg.Height
+		}
+	}
+
+	dir := opts.Dir
+	if dir == "" {
+		dir = mediaDir(ref.Mime)
+	}
+	ref.Path = pkg.uniqueContentPath(dir, name)
+	ref.Size = int64(len(data))
+	if err := pkg.AddContentAsset(ref.Path, data, ref.Mime); err != nil {
+		return nil, err
+	}
+	return ref, nil
+}
+
+// remoteMimeType returns the mime type of a download from its Content-Type
+// header, falling back to the extension of urlPath and then to sniffing
+// data when the header is missing or generic.
+func remoteMimeType(contentType, urlPath string, data []byte) string {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil && mediaType != "application/octet-stream" {
+		return mediaType
+	}
+	if mimeType := mimeTypeByPath(urlPath); mimeType != "" {
+		return mimeType
+	}
+	mediaType, _, _ := mime.ParseMediaType(http.DetectContentType(data))
+	return mediaType
+}
+
+func mimeTypeAllowed(mimeType string, allowed []string) bool {
+	for _, a := range allowed {
+		if mimeType == a || (strings.HasSuffix(a, "/") && strings.HasPrefix(mimeType, a)) {
+			return true
+		}
+	}
+	return false
+}
+
+// remoteFileName returns a file name for a download from the last element
+// of its URL path, adding an extension for the mime type when it has none.
+func remoteFileName(urlPath, mimeType string) string {
+	name := path.Base(urlPath)
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		}
+		return '-'
+	}, name)
+	name = strings.TrimLeft(name, ".-")
+	if name == "" {
+		name = "media"
+	}
+	if path.Ext(name) == "" {
+		if ext, ok := mediaExts[mimeType]; ok {
+			name += ext
+		} else if exts, _ := mime.ExtensionsByType(mimeType); len(exts) > 0 {
+			name += exts[0]
+		}
+	}
+	return name
+}
+
+// mediaExts are the usual extensions of mime types for which
+// mime.ExtensionsByType lists a rare one first.
+var mediaExts = map[string]string{
+	"image/jpeg": ".jpg",
+	"audio/mpeg": ".mp3",
+	"video/mp4":  ".mp4",
+}
+
+// mediaDir returns the content folder directory the H5P editor stores files
+// of a mime type in.
+func mediaDir(mimeType string) string {
+	switch kind, _, _ := strings.Cut(mimeType, "/"); kind {
+	case "image":
+		return "images"
+	case "audio":
+		return "audios"
+	case "video":
+		return "videos"
+	}
+	return "files"
+}
@@ -0,0 +1,85 @@
+package h5p
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestAddContentAssetFromURL(t *testing.T) {
+	png, err := os.ReadFile(writeTestPNG(t, 64, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/photos/paris":
+			w.Header().Set("Content-Type", "application/octet-stream")
+			w.Write(png)
+		case "/clip.mp4":
+			w.Header().Set("Content-Type", "video/mp4")
+			w.Write([]byte("not really a video"))
+		case "/page.html":
+			w.Write([]byte("<html><body>hello</body></html>"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+
+	pkg := NewH5PPackage()
+	ref, err := pkg.AddContentAssetFromURL(ctx, srv.URL+"/photos/paris", RemoteAssetOptions{Copyright: &Copyright{Author: "Ann", License: "CC BY"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ref.Path != "images/paris.png" || ref.Mime != "image/png" || ref.Width != 64 || ref.Height != 32 || ref.Size != int64(len(png)) {
+		t.Errorf("Unexpected reference %+v", ref)
+	}
+	if ref.Copyright.Source != srv.URL+"/photos/paris" || ref.ImageFile().Copyright.Author != "Ann" {
+		t.Errorf("Expected the URL as copyright source, got %+v", ref.Copyright)
+	}
+	if _, ok := pkg.ContentFiles[ref.Path]; !ok {
+		t.Error("Image not stored in content folder")
+	}
+
+	ref, err = pkg.AddContentAssetFromURL(ctx, srv.URL+"/photos/paris", RemoteAssetOptions{Image: []ImageOption{WithImageFit(32, 32)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ref.Path != "images/paris-1.png" || ref.Width != 32 || ref.Height != 16 {
+		t.Errorf("Unexpected resized reference %+v", ref)
+	}
+
+	video, err := pkg.AddContentAssetFromURL(ctx, srv.URL+"/clip.mp4", RemoteAssetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := video.VideoFile(); v.Path != "videos/clip.mp4" || v.Mime != "video/mp4" {
+		t.Errorf("Unexpected video %+v", v)
+	}
+
+	for _, tc := range []struct {
+		url  string
+		opts RemoteAssetOptions
+		err  error
+	}{
+		{"/page.html", RemoteAssetOptions{}, ErrMediaType},
+		{"/clip.mp4", RemoteAssetOptions{AllowedTypes: []string{"image/"}}, ErrMediaType},
+		{"/clip.mp4", RemoteAssetOptions{MaxSize: 4}, ErrFileTooLarge},
+		{"/missing.png", RemoteAssetOptions{}, ErrDownloadStatus},
+	} {
+		if _, err := pkg.AddContentAssetFromURL(ctx, srv.URL+tc.url, tc.opts); !errors.Is(err, tc.err) {
+			t.Errorf("%s: expected %v, got %v", tc.url, tc.err, err)
+		}
+	}
+	if _, err := pkg.AddContentAssetFromURL(ctx, "file:///etc/passwd", RemoteAssetOptions{}); err == nil {
+		t.Error("Expected an error for a file URL")
+	}
+	if len(pkg.ContentFiles) != 3 {
+		t.Errorf("Expected 3 content files, got %v", pkg.ContentFileNames())
+	}
+}
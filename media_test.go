@@ -0,0 +1,79 @@
+package h5p
+
+import (
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grokify/h5p-go/schemas"
+)
+
+func writeTestPNG(t *testing.T, width, height int) string {
+	t.Helper()
+	imgPath := filepath.Join(t.TempDir(), "paris.png")
+	f, err := os.Create(imgPath)
+	if err != nil {
+		t.Fatalf("Failed to create image: %v", err)
+	}
+	defer f.Close()
+	if err := png.Encode(f, image.NewRGBA(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatalf("Failed to encode image: %v", err)
+	}
+	return imgPath
+}
+
+func TestAddImageToContent(t *testing.T) {
+	imgPath := writeTestPNG(t, 64, 32)
+	pkg := NewH5PPackage()
+	copyright := &Copyright{Author: "Jane Doe", License: "CC BY"}
+
+	ref, err := AddImageToContent(pkg, imgPath, copyright)
+	if err != nil {
+		t.Fatalf("Failed to add image: %v", err)
+	}
+	if ref.Path != "images/paris.png" || ref.Mime != "image/png" {
+		t.Errorf("Unexpected reference %+v", ref)
+	}
+	if ref.Width != 64 || ref.Height != 32 {
+		t.Errorf("Expected 64x32, got %dx%d", ref.Width, ref.Height)
+	}
+	if _, ok := pkg.ContentFiles["images/paris.png"]; !ok {
+		t.Error("Image not stored in content folder")
+	}
+
+	second, err := AddImageToContent(pkg, imgPath, nil)
+	if err != nil {
+		t.Fatalf("Failed to add image twice: %v", err)
+	}
+	if second.Path != "images/paris-1.png" {
+		t.Errorf("Expected deduplicated path, got '%s'", second.Path)
+	}
+
+	bg := ref.BackgroundImage()
+	if bg.Path != ref.Path || bg.Copyright.Author != "Jane Doe" {
+		t.Errorf("Unexpected background image %+v", bg)
+	}
+
+	media := ref.MediaGroup("Eiffel tower")
+	params, ok := media.Type.Params.(*schemas.ImageParams)
+	if media.Type.Library != ImageLibrary || !ok || params.File.Path != ref.Path || params.Alt != "Eiffel tower" {
+		t.Errorf("Unexpected media group %+v", media.Type)
+	}
+
+	pkg.SetContent(&Content{Params: &schemas.MultiChoiceParams{Media: media}})
+	report, err := pkg.CheckAssetReferences()
+	if err != nil {
+		t.Fatalf("Failed to check asset references: %v", err)
+	}
+	if len(report.Missing) != 0 {
+		t.Errorf("Expected image reference to resolve, missing %v", report.Missing)
+	}
+}
+
+func TestAddImageToContentRejectsNonImages(t *testing.T) {
+	if _, err := AddImageToContent(NewH5PPackage(), "testdata/h5p.json", nil); err == nil {
+		t.Error("Expected error adding a non-image file")
+	}
+}
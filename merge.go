@@ -0,0 +1,121 @@
+package h5p
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrIncompatibleMajor is returned by MergePackages when both packages
+// contain a library with different major versions.
+var ErrIncompatibleMajor = errors.New("incompatible library major versions")
+
+// MergeOptions controls how MergePackages combines libraries.
+type MergeOptions struct {
+	// AllowMultipleMajors keeps libraries of the same name with different
+	// major versions side by side instead of failing.
+	AllowMultipleMajors bool
+}
+
+// MergeResult lists the library folders affected by MergePackages.
+type MergeResult struct {
+	Added    []string `json:"added,omitempty"`
+	Replaced []string `json:"replaced,omitempty"`
+	Skipped  []string `json:"skipped,omitempty"`
+}
+
+// MergePackages copies the libraries of src into dst, e.g. to assemble an
+// all-in-one package. When both contain the same major.minor version of a
+// library the one with the highest patch version is kept. Different minor
+// versions are kept side by side; different major versions are an error
+// wrapping ErrIncompatibleMajor unless opts.AllowMultipleMajors is set. The
+// h5p.json and content of dst are left unchanged, and dst is not modified
+// when an error is returned. Libraries are shared, not copied, so src must
+// stay open while dst uses lazily loaded files from it.
+func MergePackages(dst, src *H5PPackage, opts MergeOptions) (*MergeResult, error) {
+	if !opts.AllowMultipleMajors {
+		for _, lib := range src.Libraries {
+			name, major, _, ok := lib.identity()
+			if !ok {
+				continue
+			}
+			for _, other := range dst.Libraries {
+				if oname, omajor, _, ok := other.identity(); ok && oname == name && omajor != major {
+					return nil, fmt.Errorf("%w: %s and %s", ErrIncompatibleMajor, other.MachineName, lib.MachineName)
+				}
+			}
+		}
+	}
+
+	result := &MergeResult{}
+	for _, lib := range src.Libraries {
+		name, major, minor, ok := lib.identity()
+		existing := -1
+		if ok {
+			for i, other := range dst.Libraries {
+				if oname, omajor, ominor, ok := other.identity(); ok && oname == name && omajor == major && ominor == minor {
+					existing = i
+					break
+				}
+			}
+		}
+
+		switch {
+		case existing < 0:
+			dst.AddLibrary(lib)
+			result.Added = append(result.Added, lib.MachineName)
+		case lib.patchVersion() > dst.Libraries[existing].patchVersion():
+			dst.replaceLibrary(existing, lib)
+			result.Replaced = append(result.Replaced, lib.MachineName)
+		default:
+			result.Skipped = append(result.Skipped, lib.MachineName)
+		}
+	}
+	return result, nil
+}
+
+// patchVersion returns the patch version from library.json, or 0.
+func (lib *Library) patchVersion() int {
+	if lib.Definition == nil {
+		return 0
+	}
+	return lib.Definition.PatchVersion
+}
+
+// MergeRequiredLibraries is MergePackages limited to the libraries dst is
+// missing: those named by its h5p.json preloadedDependencies and content
+// params and, in turn, their preloaded and dynamic dependencies. It suits a
+// library repository such as the libraries folder of an H5P site as src.
+// Libraries src does not have either are left for ResolveDependencies to
+// report.
+func MergeRequiredLibraries(dst, src *H5PPackage, opts MergeOptions) (*MergeResult, error) {
+	var queue []LibraryDependency
+	if dst.PackageDefinition != nil {
+		queue = append(queue, dst.PackageDefinition.PreloadedDependencies...)
+	}
+	queue = append(queue, dst.contentLibraryReferences()...)
+
+	sub := NewH5PPackage()
+	seen := map[*Library]bool{}
+	for len(queue) > 0 {
+		dep := queue[0]
+		queue = queue[1:]
+		lib := dst.resolveLibrary(dep)
+		if lib == nil {
+			if lib = src.resolveLibrary(dep); lib == nil {
+				continue
+			}
+			if !seen[lib] {
+				sub.AddLibrary(lib)
+			}
+		}
+		if seen[lib] {
+			continue
+		}
+		seen[lib] = true
+		if lib.Definition != nil {
+			queue = append(queue, lib.Definition.Dependencies...)
+			queue = append(queue, lib.Definition.DynamicDependencies...)
+		}
+	}
+	return MergePackages(dst, sub, opts)
+}
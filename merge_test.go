@@ -0,0 +1,107 @@
+package h5p
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestMergePackages(t *testing.T) {
+	dst := NewH5PPackage()
+	dst.AddLibrary(newTestLibrary("H5P.MultiChoice", 1, 16))
+	dst.AddLibrary(newTestLibrary("H5P.JoubelUI", 1, 3))
+	dst.Libraries[1].Definition.PatchVersion = 9
+
+	src := NewH5PPackage()
+	newer := newTestLibrary("H5P.MultiChoice", 1, 16)
+	newer.Definition.PatchVersion = 4
+	src.AddLibrary(newer)
+	src.AddLibrary(newTestLibrary("H5P.JoubelUI", 1, 3))
+	src.AddLibrary(newTestLibrary("H5P.JoubelUI", 1, 4))
+	src.AddLibrary(newTestLibrary("H5P.TrueFalse", 1, 8))
+
+	result, err := MergePackages(dst, src, MergeOptions{})
+	if err != nil {
+		t.Fatalf("Failed to merge packages: %v", err)
+	}
+
+	want := &MergeResult{
+		Added:    []string{"H5P.JoubelUI-1.4", "H5P.TrueFalse-1.8"},
+		Replaced: []string{"H5P.MultiChoice-1.16"},
+		Skipped:  []string{"H5P.JoubelUI-1.3"},
+	}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("Expected %+v, got %+v", want, result)
+	}
+	if len(dst.Libraries) != 4 {
+		t.Fatalf("Expected 4 libraries, got %d", len(dst.Libraries))
+	}
+	if dst.Libraries[0] != newer {
+		t.Error("Expected MultiChoice to be replaced by the higher patch version")
+	}
+	if p := dst.libIndex.byFolder[newer.MachineName]; len(p) != 1 || p[0] != 0 || dst.GetLibrary("H5P.MultiChoice", 1, 16) != newer {
+		t.Error("Expected the index to point at the replacing library")
+	}
+	if dst.Libraries[1].Definition.PatchVersion != 9 {
+		t.Error("Expected existing JoubelUI with higher patch version to be kept")
+	}
+}
+
+func TestMergePackagesIncompatibleMajor(t *testing.T) {
+	dst := NewH5PPackage()
+	dst.AddLibrary(newTestLibrary("H5P.MultiChoice", 1, 16))
+
+	src := NewH5PPackage()
+	src.AddLibrary(newTestLibrary("H5P.TrueFalse", 1, 8))
+	src.AddLibrary(newTestLibrary("H5P.MultiChoice", 2, 0))
+
+	if _, err := MergePackages(dst, src, MergeOptions{}); !errors.Is(err, ErrIncompatibleMajor) {
+		t.Fatalf("Expected ErrIncompatibleMajor, got %v", err)
+	}
+	if len(dst.Libraries) != 1 {
+		t.Errorf("Expected destination to be unchanged, got %d libraries", len(dst.Libraries))
+	}
+
+	result, err := MergePackages(dst, src, MergeOptions{AllowMultipleMajors: true})
+	if err != nil {
+		t.Fatalf("Failed to merge packages: %v", err)
+	}
+	if len(result.Added) != 2 || len(dst.Libraries) != 3 {
+		t.Errorf("Expected both libraries to be added, got %+v", result)
+	}
+}
+
+func TestMergeRequiredLibraries(t *testing.T) {
+	dst := NewH5PPackage()
+	dst.SetPackageDefinition(&PackageDefinition{
+		MainLibrary:           "H5P.QuestionSet",
+		PreloadedDependencies: []LibraryDependency{dep("H5P.QuestionSet", 1, 20)},
+	})
+	dst.SetContent(&Content{Params: map[string]any{
+		"questions": []any{map[string]any{"library": "H5P.TrueFalse 1.8", "params": map[string]any{}}},
+	}})
+	dst.AddLibrary(newTestLibrary("H5P.QuestionSet", 1, 20, dep("H5P.JoubelUI", 1, 3)))
+
+	src := NewH5PPackage()
+	for _, lib := range []*Library{
+		newTestLibrary("H5P.JoubelUI", 1, 3, dep("FontAwesome", 4, 5)),
+		newTestLibrary("FontAwesome", 4, 5),
+		newTestLibrary("H5P.TrueFalse", 1, 8, dep("H5P.Question", 1, 5)),
+		newTestLibrary("H5P.Question", 1, 5),
+		newTestLibrary("H5P.MultiChoice", 1, 16),
+	} {
+		src.AddLibrary(lib)
+	}
+
+	result, err := MergeRequiredLibraries(dst, src, MergeOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"H5P.TrueFalse-1.8", "H5P.JoubelUI-1.3", "H5P.Question-1.5", "FontAwesome-4.5"}
+	if !reflect.DeepEqual(result.Added, want) {
+		t.Errorf("Expected %v added, got %v", want, result.Added)
+	}
+	if _, err := dst.ResolveDependencies(); err != nil {
+		t.Errorf("Expected resolvable dependencies, got %v", err)
+	}
+}
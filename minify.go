@@ -0,0 +1,190 @@
+package h5p
+
+import (
+	"path"
+	"sort"
+	"strings"
+)
+
+// EditorLibraryPrefix is the machine name prefix of H5P editor widgets.
+const EditorLibraryPrefix = "H5PEditor."
+
+// NonEssentialFileExtensions lists library file extensions that players do
+// not need, removed by Minify when RemoveNonEssentialFiles is set.
+var NonEssentialFileExtensions = []string{".map", ".md"}
+
+// MinifyOptions controls what Minify removes.
+type MinifyOptions struct {
+	// RemoveNonEssentialFiles drops library files with extensions in
+	// NonEssentialFileExtensions, such as source maps and documentation.
+	RemoveNonEssentialFiles bool
+}
+
+// MinifyResult lists what Minify removed.
+type MinifyResult struct {
+	RemovedLibraries []string `json:"removedLibraries,omitempty"`
+	RemovedFiles     []string `json:"removedFiles,omitempty"`
+}
+
+// StripEditorLibraries removes libraries only needed by the H5P editor:
+// H5PEditor.* widgets and libraries reachable solely via editorDependencies.
+// Editor dependency declarations are cleared from h5p.json and library.json.
+// It returns the folder names of the removed libraries.
+func (pkg *H5PPackage) StripEditorLibraries() []string {
+	player := pkg.reachableLibraries()
+	editor := pkg.editorDependencies()
+
+	var removed []string
+	kept := make([]*Library, 0, len(pkg.Libraries))
+	for _, lib := range pkg.Libraries {
+		name, major, minor, ok := lib.identity()
+		if strings.HasPrefix(name, EditorLibraryPrefix) || (ok && !player[lib] && editor[libraryVersionKey(name, major, minor)]) {
+			removed = append(removed, lib.MachineName)
+			continue
+		}
+		kept = append(kept, lib)
+	}
+	for _, lib := range kept {
+		if lib.Definition != nil {
+			lib.Definition.EditorDependencies = nil
+		}
+	}
+	pkg.Libraries = kept
+	pkg.indexLibraries()
+	if pkg.PackageDefinition != nil {
+		pkg.PackageDefinition.EditorDependencies = nil
+	}
+	return removed
+}
+
+// Minify shrinks a package for publishing by stripping editor libraries,
+// removing libraries not reachable from the main library and content params,
+// and optionally removing non-essential files.
+func (pkg *H5PPackage) Minify(opts MinifyOptions) *MinifyResult {
+	result := &MinifyResult{RemovedLibraries: pkg.StripEditorLibraries()}
+
+	if pkg.PackageDefinition != nil {
+		reachable := pkg.reachableLibraries()
+		kept := pkg.Libraries[:0]
+		for _, lib := range pkg.Libraries {
+			if !reachable[lib] {
+				result.RemovedLibraries = append(result.RemovedLibraries, lib.MachineName)
+				continue
+			}
+			kept = append(kept, lib)
+		}
+		pkg.Libraries = kept
+		pkg.indexLibraries()
+	}
+
+	if opts.RemoveNonEssentialFiles {
+		for _, lib := range pkg.Libraries {
+			for _, name := range lib.FileNames() {
+				if isNonEssentialFile(name) {
+					delete(lib.Files, name)
+					delete(lib.LazyFiles, name)
+					result.RemovedFiles = append(result.RemovedFiles, lib.MachineName+"/"+name)
+				}
+			}
+		}
+	}
+
+	sort.Strings(result.RemovedLibraries)
+	return result
+}
+
+// reachableLibraries returns the libraries a player needs: those reachable
+// from the h5p.json preloadedDependencies and from library strings in the
+// content params, following preloaded and dynamic dependencies.
+func (pkg *H5PPackage) reachableLibraries() map[*Library]bool {
+	reachable := map[*Library]bool{}
+	var visit func(dep LibraryDependency)
+	visit = func(dep LibraryDependency) {
+		lib := pkg.resolveLibrary(dep)
+		if lib == nil || reachable[lib] {
+			return
+		}
+		reachable[lib] = true
+		if lib.Definition != nil {
+			for _, d := range lib.Definition.Dependencies {
+				visit(d)
+			}
+			for _, d := range lib.Definition.DynamicDependencies {
+				visit(d)
+			}
+		}
+	}
+
+	if pkg.PackageDefinition != nil {
+		for _, dep := range pkg.PackageDefinition.PreloadedDependencies {
+			visit(dep)
+		}
+	}
+	for _, dep := range pkg.contentLibraryReferences() {
+		visit(dep)
+	}
+	return reachable
+}
+
+// editorDependencies returns the version keys of the libraries listed in
+// the editorDependencies of h5p.json or any library.
+func (pkg *H5PPackage) editorDependencies() map[string]bool {
+	keys := map[string]bool{}
+	add := func(deps []LibraryDependency) {
+		for _, d := range deps {
+			keys[libraryVersionKey(d.MachineName, d.MajorVersion, d.MinorVersion)] = true
+		}
+	}
+	if pkg.PackageDefinition != nil {
+		add(pkg.PackageDefinition.EditorDependencies)
+	}
+	for _, lib := range pkg.Libraries {
+		if lib.Definition != nil {
+			add(lib.Definition.EditorDependencies)
+		}
+	}
+	return keys
+}
+
+// contentLibraryReferences returns the sub-content libraries named by
+// "library" strings in the content params, e.g. QuestionSet questions.
+func (pkg *H5PPackage) contentLibraryReferences() []LibraryDependency {
+	if pkg.Content == nil {
+		return nil
+	}
+	params, err := toGenericJSON(pkg.Content)
+	if err != nil {
+		return nil
+	}
+	var deps []LibraryDependency
+	var walk func(v any)
+	walk = func(v any) {
+		switch t := v.(type) {
+		case map[string]any:
+			if s, ok := t["library"].(string); ok {
+				if dep, err := ParseLibraryString(s); err == nil {
+					deps = append(deps, dep)
+				}
+			}
+			for _, child := range t {
+				walk(child)
+			}
+		case []any:
+			for _, child := range t {
+				walk(child)
+			}
+		}
+	}
+	walk(params)
+	return deps
+}
+
+func isNonEssentialFile(name string) bool {
+	ext := strings.ToLower(path.Ext(name))
+	for _, e := range NonEssentialFileExtensions {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,104 @@
+package h5p
+
+import (
+	"testing"
+)
+
+func newMinifyTestPackage() *H5PPackage {
+	pkg := NewH5PPackage()
+	pkg.SetPackageDefinition(&PackageDefinition{
+		MainLibrary:           "H5P.QuestionSet",
+		PreloadedDependencies: []LibraryDependency{dep("H5P.QuestionSet", 1, 20)},
+		EditorDependencies:    []LibraryDependency{dep("H5PEditor.QuestionSetTextualEditor", 1, 3)},
+	})
+	pkg.SetContent(&Content{Params: map[string]any{
+		"questions": []any{map[string]any{"library": "H5P.MultiChoice 1.16"}},
+	}})
+
+	qs := newTestLibrary("H5P.QuestionSet", 1, 20, dep("H5P.JoubelUI", 1, 3))
+	qs.Definition.EditorDependencies = []LibraryDependency{dep("H5PEditor.ShowWhen", 1, 0)}
+	pkg.AddLibrary(qs)
+	pkg.AddLibrary(newTestLibrary("H5P.JoubelUI", 1, 3))
+	mc := newTestLibrary("H5P.MultiChoice", 1, 16)
+	mc.Files = map[string][]byte{
+		"js/multichoice.js":     []byte("js"),
+		"js/multichoice.js.map": []byte("map"),
+		"README.md":             []byte("docs"),
+	}
+	pkg.AddLibrary(mc)
+	pkg.AddLibrary(newTestLibrary("H5PEditor.QuestionSetTextualEditor", 1, 3))
+	pkg.AddLibrary(newTestLibrary("H5PEditor.ShowWhen", 1, 0))
+	pkg.AddLibrary(newTestLibrary("H5P.TrueFalse", 1, 8))
+	return pkg
+}
+
+func TestStripEditorLibraries(t *testing.T) {
+	pkg := newMinifyTestPackage()
+
+	removed := pkg.StripEditorLibraries()
+	if len(removed) != 2 {
+		t.Errorf("Expected 2 editor libraries removed, got %v", removed)
+	}
+	if len(pkg.Libraries) != 4 {
+		t.Errorf("Expected 4 libraries left, got %d", len(pkg.Libraries))
+	}
+	if pkg.PackageDefinition.EditorDependencies != nil {
+		t.Error("Expected h5p.json editorDependencies to be cleared")
+	}
+	if pkg.Libraries[0].Definition.EditorDependencies != nil {
+		t.Error("Expected library editorDependencies to be cleared")
+	}
+}
+
+func TestMinify(t *testing.T) {
+	pkg := newMinifyTestPackage()
+
+	result := pkg.Minify(MinifyOptions{RemoveNonEssentialFiles: true})
+	want := []string{"H5P.TrueFalse-1.8", "H5PEditor.QuestionSetTextualEditor-1.3", "H5PEditor.ShowWhen-1.0"}
+	if len(result.RemovedLibraries) != len(want) {
+		t.Fatalf("Expected removed libraries %v, got %v", want, result.RemovedLibraries)
+	}
+	for i := range want {
+		if result.RemovedLibraries[i] != want[i] {
+			t.Errorf("Expected removed library %s, got %s", want[i], result.RemovedLibraries[i])
+		}
+	}
+	if len(result.RemovedFiles) != 2 {
+		t.Errorf("Expected 2 removed files, got %v", result.RemovedFiles)
+	}
+
+	if pkg.GetLibrary("H5P.MultiChoice", 1, 16) == nil {
+		t.Error("Library referenced from content params should be kept")
+	}
+	if _, err := pkg.ResolveDependencies(); err != nil {
+		t.Errorf("Minified package has unresolved dependencies: %v", err)
+	}
+}
+
+func TestStripEditorLibrariesOrder(t *testing.T) {
+	for _, fooFirst := range []bool{false, true} {
+		a := newTestLibrary("H5P.A", 1, 0)
+		a.Definition.EditorDependencies = []LibraryDependency{dep("Foo", 1, 0)}
+		foo := newTestLibrary("Foo", 1, 0)
+		libs := []*Library{a, foo}
+		if fooFirst {
+			libs = []*Library{foo, a}
+		}
+		pkg := NewH5PPackage()
+		pkg.SetPackageDefinition(&PackageDefinition{
+			MainLibrary:           "H5P.A",
+			PreloadedDependencies: []LibraryDependency{dep("H5P.A", 1, 0)},
+		})
+		for _, lib := range libs {
+			pkg.AddLibrary(lib)
+		}
+
+		removed := pkg.StripEditorLibraries()
+		if len(removed) != 1 || removed[0] != "Foo-1.0" || len(pkg.Libraries) != 1 || pkg.Libraries[0] != a {
+			t.Errorf("Foo first %v: expected Foo-1.0 removed, got %v", fooFirst, removed)
+		}
+		if a.Definition.EditorDependencies != nil {
+			t.Errorf("Foo first %v: expected editorDependencies cleared", fooFirst)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package h5p
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// ExtractToDir writes the package as an unpacked working directory, the
+// layout used by h5p-cli and LMS installs. The directory is created if needed.
+func (pkg *H5PPackage) ExtractToDir(dir string) error {
+	return pkg.ExtractToDirContext(context.Background(), dir, nil)
+}
+
+// ExtractToDirContext is ExtractToDir that stops when ctx is cancelled and
+// calls progress, if set, after each file is written.
+func (pkg *H5PPackage) ExtractToDirContext(ctx context.Context, dir string, progress ProgressFunc) error {
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", dir, err)
+	}
+
+	return pkg.walkFilesContext(ctx, progress, func(name string, r io.Reader) error {
+		rel := filepath.FromSlash(name)
+		if !filepath.IsLocal(rel) {
+			return fmt.Errorf("refusing to write file outside of %s: %s", dir, name)
+		}
+		target := filepath.Join(dir, rel)
+		if err := os.MkdirAll(filepath.Dir(target), 0750); err != nil {
+			return fmt.Errorf("failed to create directory for %s: %w", name, err)
+		}
+		return writeFile(target, r)
+	})
+}
+
+// BuildPackageFromDir loads an unpacked working directory as a package, ready
+// to be written as a .h5p archive with CreateZipFile or WriteTo.
+func BuildPackageFromDir(dir string) (*H5PPackage, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("not a directory: %s", dir)
+	}
+
+	return LoadH5PPackageFS(os.DirFS(dir))
+}
+
+func writeFile(name string, r io.Reader) error {
+	f, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", name, err)
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return f.Close()
+}
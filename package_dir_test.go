@@ -0,0 +1,59 @@
+package h5p
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExtractToDirAndBuildPackageFromDir(t *testing.T) {
+	pkg := loadTestPackage(t)
+
+	dir := t.TempDir()
+	if err := pkg.ExtractToDir(dir); err != nil {
+		t.Fatalf("Failed to extract package: %v", err)
+	}
+
+	for _, name := range []string{
+		"h5p.json",
+		"content/content.json",
+		"H5P.MultiChoice-1.16/library.json",
+		"H5P.MultiChoice-1.16/js/multichoice.js",
+	} {
+		if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(name))); err != nil {
+			t.Errorf("Expected extracted file %s: %v", name, err)
+		}
+	}
+
+	rebuilt, err := BuildPackageFromDir(dir)
+	if err != nil {
+		t.Fatalf("Failed to build package from directory: %v", err)
+	}
+	if rebuilt.PackageDefinition == nil || rebuilt.PackageDefinition.Title != pkg.PackageDefinition.Title {
+		t.Error("Package definition not rebuilt from directory")
+	}
+	if len(rebuilt.Libraries) != 1 || len(rebuilt.Libraries[0].Files) != 2 {
+		t.Errorf("Expected 1 library with 2 files after rebuild")
+	}
+	if err := rebuilt.ValidateStructure(); err != nil {
+		t.Errorf("Rebuilt package failed structure validation: %v", err)
+	}
+}
+
+func TestExtractToDirRejectsTraversal(t *testing.T) {
+	pkg := NewH5PPackage()
+	pkg.AddLibrary(&Library{
+		MachineName: "H5P.Evil-1.0",
+		Files:       map[string][]byte{"../../escape.js": []byte("x")},
+	})
+
+	if err := pkg.ExtractToDir(t.TempDir()); err == nil {
+		t.Error("Expected error for path traversal file name")
+	}
+}
+
+func TestBuildPackageFromDirNotADirectory(t *testing.T) {
+	if _, err := BuildPackageFromDir("testdata/h5p.json"); err == nil {
+		t.Error("Expected error when building from a file")
+	}
+}
@@ -0,0 +1,377 @@
+package h5p
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Default limits applied by NewPackageLoader.
+const (
+	DefaultMaxFiles     = 10000
+	DefaultMaxFileSize  = 512 << 20 // 512 MiB
+	DefaultMaxTotalSize = 2 << 30   // 2 GiB
+
+	// DefaultLargeMaxFiles is the entry limit applied by
+	// NewLargePackageLoader, well above the 65,535 entries of a classic
+	// (non-Zip64) archive.
+	DefaultLargeMaxFiles = 1 << 20
+)
+
+var (
+	ErrUnsafePath      = errors.New("unsafe file path")
+	ErrSymlink         = errors.New("symbolic links are not allowed")
+	ErrTooManyFiles    = errors.New("too many files")
+	ErrFileTooLarge    = errors.New("file exceeds maximum size")
+	ErrPackageTooLarge = errors.New("package exceeds maximum total size")
+)
+
+// PackageLoader loads H5P packages while guarding against malicious archives:
+// path traversal and absolute entry names, symbolic links, decompression
+// bombs and excessive file counts. A zero limit disables that check.
+type PackageLoader struct {
+	MaxFiles     int
+	MaxFileSize  int64
+	MaxTotalSize int64
+
+	// Lazy defers reading library files until they are opened, keeping
+	// large assets such as videos out of memory. Packages loaded lazily from
+	// a file path keep the archive open until H5PPackage.Close is called.
+	Lazy bool
+
+	// LibraryDirs lists additional top-level folders to load as libraries
+	// even though they contain no library.json. Folders with a library.json
+	// are always loaded as libraries.
+	LibraryDirs []string
+
+	// Progress, if set, is called after each file is loaded. Bytes counts
+	// only files read into memory, not lazily loaded ones.
+	Progress ProgressFunc
+
+	// Strict fails the load with an *UnknownFieldsError if h5p.json,
+	// content.json, a library.json or the manifest contain fields the
+	// package types do not model, which would be dropped on write.
+	Strict bool
+
+	// LenientJSON accepts comments and trailing commas in definition files.
+	// A leading byte order mark is always stripped. Repairs are listed in
+	// H5PPackage.Repairs.
+	LenientJSON bool
+}
+
+// NewPackageLoader returns a loader with the default limits.
+func NewPackageLoader() *PackageLoader {
+	return &PackageLoader{
+		MaxFiles:     DefaultMaxFiles,
+		MaxFileSize:  DefaultMaxFileSize,
+		MaxTotalSize: DefaultMaxTotalSize,
+	}
+}
+
+// NewLargePackageLoader returns a lazy loader for packages larger than 4 GB
+// or with more than 65,535 entries, such as interactive video courses. Zip64
+// archives are read transparently. Only definition files are held in memory,
+// so the per-file and total size limits are disabled.
+func NewLargePackageLoader() *PackageLoader {
+	return &PackageLoader{
+		MaxFiles: DefaultLargeMaxFiles,
+		Lazy:     true,
+	}
+}
+
+// Load reads a .h5p file from disk.
+func (l *PackageLoader) Load(filePath string) (*H5PPackage, error) {
+	return l.LoadContext(context.Background(), filePath)
+}
+
+// LoadContext is Load that stops reading when ctx is cancelled, returning
+// the context error.
+func (l *PackageLoader) LoadContext(ctx context.Context, filePath string) (*H5PPackage, error) {
+	reader, err := zip.OpenReader(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open H5P file: %w", err)
+	}
+
+	pkg, err := l.loadZip(ctx, &reader.Reader)
+	if err != nil || !l.Lazy {
+		reader.Close()
+		return pkg, err
+	}
+	pkg.closers = append(pkg.closers, reader)
+	return pkg, nil
+}
+
+// LoadReader reads a .h5p archive of the given size from r. With Lazy set, r
+// must remain readable for as long as library files are accessed.
+func (l *PackageLoader) LoadReader(r io.ReaderAt, size int64) (*H5PPackage, error) {
+	return l.LoadReaderContext(context.Background(), r, size)
+}
+
+// LoadReaderContext is LoadReader that stops reading when ctx is cancelled.
+func (l *PackageLoader) LoadReaderContext(ctx context.Context, r io.ReaderAt, size int64) (*H5PPackage, error) {
+	reader, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open H5P archive: %w", err)
+	}
+
+	return l.loadZip(ctx, reader)
+}
+
+// LoadFS reads an unpacked package whose root is the root of fsys.
+func (l *PackageLoader) LoadFS(fsys fs.FS) (*H5PPackage, error) {
+	return l.LoadFSContext(context.Background(), fsys)
+}
+
+// LoadFSContext is LoadFS that stops reading when ctx is cancelled.
+func (l *PackageLoader) LoadFSContext(ctx context.Context, fsys fs.FS) (*H5PPackage, error) {
+	pkg := l.newPackage()
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		if _, err := fs.Stat(fsys, entry.Name()+"/library.json"); err == nil {
+			pkg.addLibraryDirectory(entry.Name())
+		}
+	}
+	tracker := &progressTracker{fn: l.Progress}
+	var count int
+	var total int64
+	var unknown []string
+
+	err = fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if d.Type()&fs.ModeSymlink != 0 {
+			return fmt.Errorf("%w: %s", ErrSymlink, name)
+		}
+		count++
+		if err := l.checkCount(count); err != nil {
+			return err
+		}
+		if l.Lazy && !isDefinitionFile(name) {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			if err := l.checkDeclaredSize(name, info.Size(), &total); err != nil {
+				return err
+			}
+			pkg.addLazyFile(name, func() (io.ReadCloser, error) { return fsys.Open(name) })
+			tracker.add(name, 0)
+			return nil
+		}
+		f, err := fsys.Open(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		data, err := l.readLimited(ctx, f, name, &total)
+		if err != nil {
+			return err
+		}
+		if err := l.processFile(pkg, name, data, &unknown); err != nil {
+			return fmt.Errorf("failed to process file %s: %w", name, err)
+		}
+		tracker.add(name, int64(len(data)))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return l.finish(pkg, unknown)
+}
+
+func (l *PackageLoader) loadZip(ctx context.Context, reader *zip.Reader) (*H5PPackage, error) {
+	if err := l.checkCount(len(reader.File)); err != nil {
+		return nil, err
+	}
+
+	pkg := l.newPackage()
+	for _, file := range reader.File {
+		if isLibraryDefinitionFile(file.Name, "library.json") {
+			pkg.addLibraryDirectory(path.Dir(file.Name))
+		}
+	}
+	tracker := &progressTracker{fn: l.Progress}
+	var total int64
+	var unknown []string
+
+	for _, file := range reader.File {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if err := checkEntryName(file.Name); err != nil {
+			return nil, err
+		}
+		if file.Mode()&fs.ModeSymlink != 0 {
+			return nil, fmt.Errorf("%w: %s", ErrSymlink, file.Name)
+		}
+		if file.FileInfo().IsDir() {
+			continue
+		}
+		if l.Lazy && !isDefinitionFile(file.Name) {
+			// archive/zip fails reads that exceed the declared size, so the
+			// header can be trusted for lazily opened entries.
+			if err := l.checkDeclaredSize(file.Name, int64(file.UncompressedSize64), &total); err != nil {
+				return nil, err
+			}
+			pkg.addLazyFile(file.Name, file.Open)
+			tracker.add(file.Name, 0)
+			continue
+		}
+		n, err := l.processZipFile(ctx, pkg, file, &total, &unknown)
+		if err != nil {
+			return nil, fmt.Errorf("failed to process file %s: %w", file.Name, err)
+		}
+		tracker.add(file.Name, n)
+	}
+
+	return l.finish(pkg, unknown)
+}
+
+// finish completes a load, failing in strict mode if unknown fields were
+// found.
+func (l *PackageLoader) finish(pkg *H5PPackage, unknown []string) (*H5PPackage, error) {
+	pkg.libraryDirs = nil
+	if len(unknown) > 0 {
+		return nil, &UnknownFieldsError{Fields: unknown}
+	}
+	return pkg, nil
+}
+
+// newPackage returns an empty package with the configured LibraryDirs
+// marked as library folders.
+func (l *PackageLoader) newPackage() *H5PPackage {
+	pkg := NewH5PPackage()
+	for _, dir := range l.LibraryDirs {
+		pkg.addLibraryDirectory(dir)
+	}
+	return pkg
+}
+
+// processZipFile loads a single archive entry and returns its size.
+func (l *PackageLoader) processZipFile(ctx context.Context, pkg *H5PPackage, file *zip.File, total *int64, unknown *[]string) (int64, error) {
+	rc, err := file.Open()
+	if err != nil {
+		return 0, err
+	}
+	defer rc.Close()
+
+	data, err := l.readLimited(ctx, rc, file.Name, total)
+	if err != nil {
+		return 0, err
+	}
+
+	return int64(len(data)), l.processFile(pkg, file.Name, data, unknown)
+}
+
+// processFile repairs definition files, hands the file to pkg and, in
+// strict mode, appends the fields its definition type does not model to
+// unknown.
+func (l *PackageLoader) processFile(pkg *H5PPackage, name string, data []byte, unknown *[]string) error {
+	if isDefinitionFile(name) {
+		var repairs []JSONRepair
+		data, repairs = RepairJSON(data, l.LenientJSON)
+		for _, r := range repairs {
+			r.File = name
+			pkg.Repairs = append(pkg.Repairs, r)
+		}
+	}
+	if err := pkg.processFile(name, data); err != nil {
+		return err
+	}
+	if !l.Strict {
+		return nil
+	}
+	t := definitionType(name)
+	if t == nil {
+		return nil
+	}
+	if name == "content/content.json" && (pkg.Content == nil || pkg.Content.QuestionSet == nil) {
+		// Params of other main libraries are kept verbatim.
+		return nil
+	}
+	fields, err := unknownFields(data, t)
+	if err != nil {
+		return err
+	}
+	for _, f := range fields {
+		*unknown = append(*unknown, name+": "+f)
+	}
+	return nil
+}
+
+// readLimited reads r while enforcing the per-file and total size limits on
+// the actual decompressed bytes rather than trusting archive headers.
+func (l *PackageLoader) readLimited(ctx context.Context, r io.Reader, name string, total *int64) ([]byte, error) {
+	r = &contextReader{ctx: ctx, r: r}
+	limit := int64(-1)
+	if l.MaxFileSize > 0 {
+		limit = l.MaxFileSize
+	}
+	if l.MaxTotalSize > 0 {
+		if remaining := l.MaxTotalSize - *total; limit < 0 || remaining < limit {
+			limit = remaining
+		}
+	}
+	if limit >= 0 {
+		r = io.LimitReader(r, limit+1)
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	size := int64(len(data))
+	if l.MaxFileSize > 0 && size > l.MaxFileSize {
+		return nil, fmt.Errorf("%w: %s", ErrFileTooLarge, name)
+	}
+	*total += size
+	if l.MaxTotalSize > 0 && *total > l.MaxTotalSize {
+		return nil, ErrPackageTooLarge
+	}
+	return data, nil
+}
+
+func (l *PackageLoader) checkDeclaredSize(name string, size int64, total *int64) error {
+	if size < 0 || (l.MaxFileSize > 0 && size > l.MaxFileSize) {
+		return fmt.Errorf("%w: %s", ErrFileTooLarge, name)
+	}
+	*total += size
+	if l.MaxTotalSize > 0 && *total > l.MaxTotalSize {
+		return ErrPackageTooLarge
+	}
+	return nil
+}
+
+func (l *PackageLoader) checkCount(n int) error {
+	if l.MaxFiles > 0 && n > l.MaxFiles {
+		return fmt.Errorf("%w: more than %d entries", ErrTooManyFiles, l.MaxFiles)
+	}
+	return nil
+}
+
+// checkEntryName rejects archive entry names that would escape the package
+// root when extracted.
+func checkEntryName(name string) error {
+	if name == "" || strings.HasPrefix(name, "/") || strings.Contains(name, `\`) ||
+		!filepath.IsLocal(filepath.FromSlash(strings.TrimSuffix(name, "/"))) {
+		return fmt.Errorf("%w: %s", ErrUnsafePath, name)
+	}
+	return nil
+}
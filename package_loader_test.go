@@ -0,0 +1,254 @@
+package h5p
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestPackageLoaderRejectsUnsafeEntries(t *testing.T) {
+	var unsafeNameTests = []string{
+		"../evil.js",
+		"H5P.Foo-1.0/../../evil.js",
+		"/etc/passwd",
+		`H5P.Foo-1.0\..\evil.js`,
+	}
+
+	for _, name := range unsafeNameTests {
+		zipPath := writeTestZip(t, map[string][]byte{name: []byte("x")})
+		_, err := LoadH5PPackage(zipPath)
+		if !errors.Is(err, ErrUnsafePath) {
+			t.Errorf("Expected ErrUnsafePath for %q, got %v", name, err)
+		}
+	}
+}
+
+func TestPackageLoaderRejectsSymlinks(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	header := &zip.FileHeader{Name: "H5P.Foo-1.0/link.js"}
+	header.SetMode(fs.ModeSymlink | 0777)
+	w, err := zw.CreateHeader(header)
+	if err != nil {
+		t.Fatalf("Failed to create symlink entry: %v", err)
+	}
+	if _, err := w.Write([]byte("/etc/passwd")); err != nil {
+		t.Fatalf("Failed to write symlink entry: %v", err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("Failed to close zip: %v", err)
+	}
+
+	_, err = LoadH5PPackageFromReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if !errors.Is(err, ErrSymlink) {
+		t.Errorf("Expected ErrSymlink, got %v", err)
+	}
+}
+
+func TestPackageLoaderLimits(t *testing.T) {
+	zipPath := writeTestZip(t, map[string][]byte{
+		"H5P.Foo-1.0/a.js": []byte(strings.Repeat("a", 100)),
+		"H5P.Foo-1.0/b.js": []byte(strings.Repeat("b", 100)),
+		"H5P.Foo-1.0/c.js": []byte(strings.Repeat("c", 100)),
+	})
+
+	var limitTests = []struct {
+		loader *PackageLoader
+		want   error
+	}{
+		{&PackageLoader{MaxFiles: 2}, ErrTooManyFiles},
+		{&PackageLoader{MaxFileSize: 99}, ErrFileTooLarge},
+		{&PackageLoader{MaxTotalSize: 250}, ErrPackageTooLarge},
+		{&PackageLoader{MaxFiles: 3, MaxFileSize: 100, MaxTotalSize: 300}, nil},
+		{&PackageLoader{}, nil},
+	}
+
+	for _, tt := range limitTests {
+		_, err := tt.loader.Load(zipPath)
+		if tt.want == nil && err != nil {
+			t.Errorf("Loader %+v: unexpected error %v", *tt.loader, err)
+		} else if !errors.Is(err, tt.want) {
+			t.Errorf("Loader %+v: expected %v, got %v", *tt.loader, tt.want, err)
+		}
+	}
+}
+
+func TestPackageLoaderFSLimits(t *testing.T) {
+	fsys := fstest.MapFS{
+		"H5P.Foo-1.0/a.js": {Data: []byte("aaaa")},
+		"H5P.Foo-1.0/b.js": {Data: []byte("bbbb")},
+	}
+
+	if _, err := (&PackageLoader{MaxFiles: 1}).LoadFS(fsys); !errors.Is(err, ErrTooManyFiles) {
+		t.Errorf("Expected ErrTooManyFiles, got %v", err)
+	}
+	if _, err := (&PackageLoader{MaxFileSize: 3}).LoadFS(fsys); !errors.Is(err, ErrFileTooLarge) {
+		t.Errorf("Expected ErrFileTooLarge, got %v", err)
+	}
+	if _, err := NewPackageLoader().LoadFS(fsys); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestPackageLoaderLazy(t *testing.T) {
+	pkg := loadTestPackage(t)
+	zipPath := filepath.Join(t.TempDir(), "lazy.h5p")
+	if err := pkg.CreateZipFile(zipPath); err != nil {
+		t.Fatalf("Failed to create H5P package: %v", err)
+	}
+
+	loader := NewPackageLoader()
+	loader.Lazy = true
+	lazyPkg, err := loader.Load(zipPath)
+	if err != nil {
+		t.Fatalf("Failed to load package lazily: %v", err)
+	}
+	defer lazyPkg.Close()
+
+	if lazyPkg.PackageDefinition == nil || lazyPkg.Libraries[0].Definition == nil {
+		t.Fatal("Definitions should be loaded eagerly")
+	}
+	lib := lazyPkg.Libraries[0]
+	if len(lib.Files) != 0 || len(lib.LazyFiles) != 2 {
+		t.Fatalf("Expected 0 eager and 2 lazy files, got %d and %d", len(lib.Files), len(lib.LazyFiles))
+	}
+
+	data, err := lib.ReadFile("js/multichoice.js")
+	if err != nil {
+		t.Fatalf("Failed to read lazy file: %v", err)
+	}
+	if string(data) != "// MultiChoice JavaScript code" {
+		t.Errorf("Unexpected lazy file contents: %s", data)
+	}
+	if _, err := lib.ReadFile("missing.js"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected fs.ErrNotExist for missing file, got %v", err)
+	}
+
+	var buf bytes.Buffer
+	if _, err := lazyPkg.WriteTo(&buf); err != nil {
+		t.Fatalf("Failed to write lazily loaded package: %v", err)
+	}
+	rewritten, err := LoadH5PPackageFromReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("Failed to reload package: %v", err)
+	}
+	if got := string(rewritten.Libraries[0].Files["css/multichoice.css"]); got != "/* MultiChoice CSS styles */" {
+		t.Errorf("Lazy file not preserved on write, got %q", got)
+	}
+
+	if err := lazyPkg.Close(); err != nil {
+		t.Errorf("Failed to close package: %v", err)
+	}
+}
+
+func TestPackageLoaderLazyEnforcesLimits(t *testing.T) {
+	zipPath := writeTestZip(t, map[string][]byte{
+		"H5P.Foo-1.0/video.mp4": []byte(strings.Repeat("v", 100)),
+	})
+
+	loader := &PackageLoader{MaxFileSize: 50, Lazy: true}
+	if _, err := loader.Load(zipPath); !errors.Is(err, ErrFileTooLarge) {
+		t.Errorf("Expected ErrFileTooLarge, got %v", err)
+	}
+}
+
+func TestLargePackageLoaderManyEntries(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping large archive test in short mode")
+	}
+
+	// More entries than a classic zip can index forces a Zip64 directory.
+	const numFiles = 1<<16 + 10
+	pkg := loadTestPackage(t)
+	for i := 0; i < numFiles; i++ {
+		name := fmt.Sprintf("images/%05d.png", i)
+		if err := pkg.AddContentAsset(name, []byte{byte(i)}, "image/png"); err != nil {
+			t.Fatalf("Failed to add %s: %v", name, err)
+		}
+	}
+
+	zipPath := filepath.Join(t.TempDir(), "large.h5p")
+	if err := pkg.CreateZipFileWithOptions(zipPath, WriteOptions{StoreAll: true}); err != nil {
+		t.Fatalf("Failed to write package: %v", err)
+	}
+
+	if _, err := LoadH5PPackage(zipPath); !errors.Is(err, ErrTooManyFiles) {
+		t.Errorf("Expected ErrTooManyFiles from default loader, got %v", err)
+	}
+
+	loaded, err := NewLargePackageLoader().Load(zipPath)
+	if err != nil {
+		t.Fatalf("Failed to load large package: %v", err)
+	}
+	defer loaded.Close()
+	if got := len(loaded.ContentFiles); got != numFiles {
+		t.Errorf("Expected %d content files, got %d", numFiles, got)
+	}
+	data, err := loaded.ContentFiles["images/65545.png"].Bytes()
+	if err != nil {
+		t.Fatalf("Failed to read content file: %v", err)
+	}
+	if len(data) != 1 || data[0] != byte(65545%256) {
+		t.Errorf("Unexpected content file data: %v", data)
+	}
+}
+
+func TestPackageLoaderDetectsLibrariesByLibraryJSON(t *testing.T) {
+	files := map[string][]byte{
+		"h5p.json": []byte(`{"title":"Test"}`),
+		"FontAwesome-4.5/h5p-font-awesome.min.css": []byte("/* fa */"),
+		"FontAwesome-4.5/library.json":             []byte(`{"machineName":"FontAwesome","majorVersion":4,"minorVersion":5}`),
+		"jQuery.ui-1.10/h5p-jquery-ui.js":          []byte("// jquery ui"),
+		"jQuery.ui-1.10/library.json":              []byte(`{"machineName":"jQuery.ui","majorVersion":1,"minorVersion":10}`),
+		"Tether-1.0/scripts/tether.min.js":         []byte("// tether"),
+		"stray/notes.txt":                          []byte("not a library"),
+	}
+
+	check := func(pkg *H5PPackage, wantTether bool) {
+		t.Helper()
+		fa := pkg.GetLibrary("FontAwesome", 4, 5)
+		if fa == nil || string(fa.Files["h5p-font-awesome.min.css"]) != "/* fa */" {
+			t.Errorf("Expected FontAwesome-4.5 with its CSS file, got %v", fa)
+		}
+		if lib := pkg.GetLibrary("jQuery.ui", 1, 10); lib == nil || len(lib.Files) != 1 {
+			t.Errorf("Expected jQuery.ui-1.10 with its script, got %v", lib)
+		}
+		if got := pkg.GetLibrary("Tether", 1, 0) != nil; got != wantTether {
+			t.Errorf("Expected Tether-1.0 loaded=%v, got %v", wantTether, got)
+		}
+		if pkg.libraryByFolder("stray") != nil {
+			t.Error("Folder without library.json should not be loaded as a library")
+		}
+	}
+
+	zipPath := writeTestZip(t, files)
+	pkg, err := LoadH5PPackage(zipPath)
+	if err != nil {
+		t.Fatalf("Failed to load package: %v", err)
+	}
+	check(pkg, false)
+
+	fsys := fstest.MapFS{}
+	for name, data := range files {
+		fsys[name] = &fstest.MapFile{Data: data}
+	}
+	pkg, err = LoadH5PPackageFS(fsys)
+	if err != nil {
+		t.Fatalf("Failed to load package from FS: %v", err)
+	}
+	check(pkg, false)
+
+	loader := NewPackageLoader()
+	loader.LibraryDirs = []string{"Tether-1.0"}
+	pkg, err = loader.Load(zipPath)
+	if err != nil {
+		t.Fatalf("Failed to load package: %v", err)
+	}
+	check(pkg, true)
+}
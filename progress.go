@@ -0,0 +1,69 @@
+package h5p
+
+import (
+	"context"
+	"io"
+)
+
+// Progress reports how far a long-running package operation has got.
+type Progress struct {
+	// Files is the number of files processed so far.
+	Files int
+	// Bytes is the number of uncompressed bytes read or written so far.
+	Bytes int64
+	// Name is the archive path of the file just processed.
+	Name string
+}
+
+// ProgressFunc is called after each file an operation processes. It runs on
+// the calling goroutine and should return quickly.
+type ProgressFunc func(Progress)
+
+// progressTracker accumulates progress and reports it to an optional
+// callback.
+type progressTracker struct {
+	fn ProgressFunc
+	p  Progress
+}
+
+func (t *progressTracker) add(name string, n int64) {
+	t.p.Files++
+	t.p.Bytes += n
+	t.p.Name = name
+	if t.fn != nil {
+		t.fn(t.p)
+	}
+}
+
+// contextReader fails reads once ctx is done, so copying a large file can be
+// cancelled part way through, and counts the bytes read.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+	n   int64
+}
+
+func (cr *contextReader) Read(p []byte) (int, error) {
+	if err := cr.ctx.Err(); err != nil {
+		return 0, err
+	}
+	n, err := cr.r.Read(p)
+	cr.n += int64(n)
+	return n, err
+}
+
+// walkFilesContext is walkFiles with cancellation and progress reporting.
+func (pkg *H5PPackage) walkFilesContext(ctx context.Context, progress ProgressFunc, fn func(name string, r io.Reader) error) error {
+	tracker := &progressTracker{fn: progress}
+	return pkg.walkFiles(func(name string, r io.Reader) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		cr := &contextReader{ctx: ctx, r: r}
+		if err := fn(name, cr); err != nil {
+			return err
+		}
+		tracker.add(name, cr.n)
+		return nil
+	})
+}
@@ -0,0 +1,84 @@
+package h5p
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestWriteAndLoadProgress(t *testing.T) {
+	pkg := loadTestPackage(t)
+	if err := pkg.AddContentAsset("images/photo.png", bytes.Repeat([]byte("a"), 1000), ""); err != nil {
+		t.Fatalf("Failed to add asset: %v", err)
+	}
+
+	var written []Progress
+	var buf bytes.Buffer
+	opts := WriteOptions{Progress: func(p Progress) { written = append(written, p) }}
+	if _, err := pkg.WriteToContext(context.Background(), &buf, opts); err != nil {
+		t.Fatalf("Failed to write package: %v", err)
+	}
+	if len(written) == 0 {
+		t.Fatal("Expected write progress to be reported")
+	}
+	last := written[len(written)-1]
+	if last.Files != len(written) || last.Bytes < 1000 {
+		t.Errorf("Unexpected final write progress: %+v", last)
+	}
+
+	var loaded []Progress
+	loader := NewPackageLoader()
+	loader.Progress = func(p Progress) { loaded = append(loaded, p) }
+	if _, err := loader.LoadReaderContext(context.Background(), bytes.NewReader(buf.Bytes()), int64(buf.Len())); err != nil {
+		t.Fatalf("Failed to load package: %v", err)
+	}
+	if len(loaded) != len(written) {
+		t.Errorf("Expected %d load progress calls, got %d", len(written), len(loaded))
+	}
+	if got := loaded[len(loaded)-1].Bytes; got != last.Bytes {
+		t.Errorf("Expected %d bytes loaded, got %d", last.Bytes, got)
+	}
+}
+
+func TestOperationsCancelled(t *testing.T) {
+	pkg := loadTestPackage(t)
+	var buf bytes.Buffer
+	if _, err := pkg.WriteTo(&buf); err != nil {
+		t.Fatalf("Failed to write package: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := pkg.WriteToContext(ctx, &bytes.Buffer{}, WriteOptions{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected write to be cancelled, got %v", err)
+	}
+	if _, err := NewPackageLoader().LoadReaderContext(ctx, bytes.NewReader(buf.Bytes()), int64(buf.Len())); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected load to be cancelled, got %v", err)
+	}
+	if err := pkg.ExtractToDirContext(ctx, t.TempDir(), nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected extraction to be cancelled, got %v", err)
+	}
+	if err := pkg.ValidateStructureContext(ctx, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected validation to be cancelled, got %v", err)
+	}
+}
+
+func TestWriteCancelledDuringProgress(t *testing.T) {
+	pkg := loadTestPackage(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var calls int
+	opts := WriteOptions{Progress: func(Progress) {
+		calls++
+		cancel()
+	}}
+	if _, err := pkg.WriteToContext(ctx, &bytes.Buffer{}, opts); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected write to be cancelled, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("Expected writing to stop after the first file, got %d progress calls", calls)
+	}
+}
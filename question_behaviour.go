@@ -0,0 +1,135 @@
+package h5p
+
+import (
+	"github.com/grokify/h5p-go/schemas"
+)
+
+// QuestionBehaviour holds the behaviour settings shared by question content
+// types. Nil fields leave the setting of a question as it is.
+type QuestionBehaviour struct {
+	EnableRetry           *bool
+	EnableSolutionsButton *bool
+	// PassPercentage is the score needed to pass, for the content types
+	// that have one, e.g. passPercentage of MultiChoice and
+	// percentagePassing of Essay.
+	PassPercentage *int
+}
+
+// passPercentageKeys are the behaviour fields holding the pass percentage
+// of content types.
+var passPercentageKeys = map[string]string{
+	"H5P.MultiChoice": "passPercentage",
+	"H5P.Essay":       "percentagePassing",
+}
+
+// Merge returns b with the fields set in o replacing its own.
+func (b QuestionBehaviour) Merge(o QuestionBehaviour) QuestionBehaviour {
+	if o.EnableRetry != nil {
+		b.EnableRetry = o.EnableRetry
+	}
+	if o.EnableSolutionsButton != nil {
+		b.EnableSolutionsButton = o.EnableSolutionsButton
+	}
+	if o.PassPercentage != nil {
+		b.PassPercentage = o.PassPercentage
+	}
+	return b
+}
+
+// IsZero reports whether no setting is set.
+func (b QuestionBehaviour) IsZero() bool {
+	return b.EnableRetry == nil && b.EnableSolutionsButton == nil && b.PassPercentage == nil
+}
+
+// Apply sets the behaviour in the params of q. Typed params of this
+// package omit false flags from their JSON, which the player reads as the
+// default of true, so params that cannot hold the settings are replaced by
+// their generic form, as DecodeParams into a map gives.
+func (b QuestionBehaviour) Apply(q *Question) error {
+	if b.IsZero() || b.applyTyped(q.Params) {
+		return nil
+	}
+	params, ok := q.Params.(map[string]any)
+	if !ok {
+		if err := q.DecodeParams(&params); err != nil {
+			return err
+		}
+	}
+	if params == nil {
+		params = map[string]any{}
+	}
+	behaviour, _ := params["behaviour"].(map[string]any)
+	if behaviour == nil {
+		behaviour = map[string]any{}
+		params["behaviour"] = behaviour
+	}
+	if b.EnableRetry != nil {
+		behaviour["enableRetry"] = *b.EnableRetry
+	}
+	if b.EnableSolutionsButton != nil {
+		behaviour["enableSolutionsButton"] = *b.EnableSolutionsButton
+	}
+	if key, ok := passPercentageKeys[q.MachineName()]; ok && b.PassPercentage != nil {
+		behaviour[key] = *b.PassPercentage
+	}
+	q.Params = params
+	return nil
+}
+
+// applyTyped sets the behaviour in typed params, reporting false if their
+// type is unknown or cannot hold a setting.
+func (b QuestionBehaviour) applyTyped(params any) bool {
+	if (b.EnableRetry != nil && !*b.EnableRetry) || (b.EnableSolutionsButton != nil && !*b.EnableSolutionsButton) {
+		return false
+	}
+	// Only true flags are left to set.
+	flags := func(retry, solutions *bool) {
+		if b.EnableRetry != nil {
+			*retry = true
+		}
+		if b.EnableSolutionsButton != nil && solutions != nil {
+			*solutions = true
+		}
+	}
+	switch p := params.(type) {
+	case *schemas.MultiChoiceParams:
+		if p.Behaviour == nil {
+			p.Behaviour = &schemas.Behaviour{}
+		}
+		flags(&p.Behaviour.EnableRetry, &p.Behaviour.EnableSolutionsButton)
+		if b.PassPercentage != nil {
+			p.Behaviour.PassPercentage = *b.PassPercentage
+		}
+	case *schemas.TrueFalseParams:
+		if p.Behaviour == nil {
+			p.Behaviour = &schemas.TrueFalseBehaviour{}
+		}
+		flags(&p.Behaviour.EnableRetry, &p.Behaviour.EnableSolutionsButton)
+	case *schemas.BlanksParams:
+		if p.Behaviour == nil {
+			p.Behaviour = &schemas.BlanksBehaviour{}
+		}
+		flags(&p.Behaviour.EnableRetry, &p.Behaviour.EnableSolutionsButton)
+	case *schemas.DragTextParams:
+		if p.Behaviour == nil {
+			p.Behaviour = &schemas.DragTextBehaviour{}
+		}
+		flags(&p.Behaviour.EnableRetry, &p.Behaviour.EnableSolutionsButton)
+	case *schemas.MarkTheWordsParams:
+		if p.Behaviour == nil {
+			p.Behaviour = &schemas.MarkTheWordsBehaviour{}
+		}
+		flags(&p.Behaviour.EnableRetry, &p.Behaviour.EnableSolutionsButton)
+	case *schemas.EssayParams:
+		if p.Behaviour == nil {
+			p.Behaviour = &schemas.EssayBehaviour{}
+		}
+		flags(&p.Behaviour.EnableRetry, nil)
+		if b.PassPercentage != nil {
+			p.Behaviour.PercentagePassing = *b.PassPercentage
+		}
+	default:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,47 @@
+package h5p
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/grokify/h5p-go/schemas"
+)
+
+func TestQuestionSetBuilderBehaviour(t *testing.T) {
+	yes, no, pass := true, false, 80
+	qs, err := NewQuestionSetBuilder().
+		AddMultipleChoiceQuestion("2 + 2?", []Answer{CreateAnswer("4", true)}).
+		SetDefaultBehaviour(QuestionBehaviour{EnableRetry: &yes, PassPercentage: &pass}).
+		AddTrueFalseQuestion("Sky is blue.", true, nil).
+		OverrideBehaviour(QuestionBehaviour{EnableRetry: &no}).
+		AddEssayQuestion("Why?", nil, nil).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mc := qs.Questions[0].Params.(*schemas.MultiChoiceParams)
+	if !mc.Behaviour.EnableRetry || mc.Behaviour.PassPercentage != 80 {
+		t.Errorf("Expected the default behaviour applied to earlier questions, got %+v", mc.Behaviour)
+	}
+	essay := qs.Questions[2].Params.(*schemas.EssayParams)
+	if !essay.Behaviour.EnableRetry || essay.Behaviour.PercentagePassing != 80 {
+		t.Errorf("Expected the pass percentage as percentagePassing, got %+v", essay.Behaviour)
+	}
+
+	// Disabling retry cannot be expressed by typed params.
+	tf, ok := qs.Questions[1].Params.(map[string]any)
+	if !ok {
+		t.Fatalf("Expected generic params for a false flag, got %T", qs.Questions[1].Params)
+	}
+	data, _ := json.Marshal(tf)
+	if !strings.Contains(string(data), `"enableRetry":false`) || !strings.Contains(string(data), `"question":"Sky is blue."`) ||
+		strings.Contains(string(data), "passPercentage") {
+		t.Errorf("Unexpected params %s", data)
+	}
+
+	if _, err := NewQuestionSetBuilder().OverrideBehaviour(QuestionBehaviour{EnableRetry: &no}).Build(); err == nil {
+		t.Error("Expected an error for an override without a question")
+	}
+}
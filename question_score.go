@@ -0,0 +1,153 @@
+package h5p
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/grokify/h5p-go/grading"
+	"github.com/grokify/h5p-go/schemas"
+)
+
+var ErrNotGradable = errors.New("question type is not graded")
+
+// DefaultPassPercentage is the pass percentage H5P.QuestionSet uses when
+// its params leave passPercentage out.
+const DefaultPassPercentage = 50
+
+// Grade scores a response to the question with the grading package.
+// MultiChoice, TrueFalse, Blanks, DragText and MarkTheWords questions are
+// graded; others return ErrNotGradable.
+func (q *Question) Grade(r grading.Response) (*grading.Result, error) {
+	if params, ok, err := q.multiChoiceParams(); ok {
+		if err != nil {
+			return nil, err
+		}
+		return grading.MultiChoice(params, r.Selected)
+	}
+
+	switch q.MachineName() {
+	case "H5P.TrueFalse":
+		var params schemas.TrueFalseParams
+		if err := q.DecodeParams(&params); err != nil {
+			return nil, err
+		}
+		if r.Answer == nil {
+			return &grading.Result{MaxScore: 1}, nil
+		}
+		return grading.TrueFalse(&params, *r.Answer), nil
+	case "H5P.Blanks":
+		var params schemas.BlanksParams
+		if err := q.DecodeParams(&params); err != nil {
+			return nil, err
+		}
+		return grading.Blanks(&params, r.Texts)
+	case "H5P.DragText":
+		var params schemas.DragTextParams
+		if err := q.DecodeParams(&params); err != nil {
+			return nil, err
+		}
+		return grading.DragText(&params, r.Texts)
+	case "H5P.MarkTheWords":
+		var params schemas.MarkTheWordsParams
+		if err := q.DecodeParams(&params); err != nil {
+			return nil, err
+		}
+		return grading.MarkTheWords(&params, r.Selected)
+	}
+	return nil, fmt.Errorf("%w: %s", ErrNotGradable, q.Library)
+}
+
+// QuestionSetScore is the score of responses to a question set.
+type QuestionSetScore struct {
+	// Score and MaxScore add up the points of the graded questions, so
+	// each question weighs by its maximum score, as in H5P.QuestionSet.
+	Score    int `json:"score"`
+	MaxScore int `json:"maxScore"`
+	// Percentage is the score in percent, rounded.
+	Percentage int  `json:"percentage"`
+	Passed     bool `json:"passed"`
+	// Feedback is the text of the overall feedback range Percentage falls
+	// in, or "".
+	Feedback  string          `json:"feedback,omitempty"`
+	Questions []QuestionScore `json:"questions"`
+}
+
+// QuestionScore is the grading of a question of a question set.
+type QuestionScore struct {
+	// Key identifies the question in the responses, see QuestionSet.Score.
+	Key      string `json:"key"`
+	Library  string `json:"library"`
+	Answered bool   `json:"answered"`
+	// Graded is false for question types the grading package does not
+	// score, which are left out of the totals.
+	Graded bool            `json:"graded"`
+	Result *grading.Result `json:"result,omitempty"`
+}
+
+// Score grades responses to the questions, keyed by subContentId, or by
+// the question index for questions without one, and evaluates the total
+// against the pass percentage and the overall feedback ranges. Unanswered
+// questions score 0.
+func (qs *QuestionSet) Score(responses map[string]grading.Response) (*QuestionSetScore, error) {
+	s := &QuestionSetScore{}
+	for i := range qs.Questions {
+		q := &qs.Questions[i]
+		key := q.SubContentID
+		if key == "" {
+			key = strconv.Itoa(i)
+		}
+		r, answered := responses[key]
+		qsc := QuestionScore{Key: key, Library: q.Library, Answered: answered}
+		result, err := q.Grade(r)
+		switch {
+		case errors.Is(err, ErrNotGradable):
+		case err != nil:
+			return nil, fmt.Errorf("question %d: %w", i+1, err)
+		default:
+			qsc.Graded, qsc.Result = true, result
+			s.Score += result.Score
+			s.MaxScore += result.MaxScore
+		}
+		s.Questions = append(s.Questions, qsc)
+	}
+
+	if s.MaxScore > 0 {
+		s.Percentage = int(math.Round(100 * float64(s.Score) / float64(s.MaxScore)))
+	}
+	pass := qs.PassPercentage
+	if pass <= 0 {
+		pass = DefaultPassPercentage
+	}
+	s.Passed = s.MaxScore > 0 && 100*s.Score >= pass*s.MaxScore
+	for _, fr := range qs.OverallFeedback {
+		if fr.From <= s.Percentage && s.Percentage <= fr.To {
+			s.Feedback = fr.Text
+			break
+		}
+	}
+	return s, nil
+}
+
+// Weighted returns the mean scaled score of the graded questions, each
+// weighing by weights[key], or 1 if it is not listed, instead of by its
+// maximum score. It returns 0 if no question carries weight.
+func (s *QuestionSetScore) Weighted(weights map[string]float64) float64 {
+	var sum, total float64
+	for _, q := range s.Questions {
+		if !q.Graded {
+			continue
+		}
+		w, ok := weights[q.Key]
+		if !ok {
+			w = 1
+		}
+		sum += w * q.Result.Scaled()
+		total += w
+	}
+	if total <= 0 {
+		return 0
+	}
+	return sum / total
+}
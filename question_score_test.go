@@ -0,0 +1,79 @@
+package h5p
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/grokify/h5p-go/grading"
+	"github.com/grokify/h5p-go/schemas"
+)
+
+func scoreTestSet() *QuestionSet {
+	return &QuestionSet{
+		PassPercentage: 60,
+		OverallFeedback: []FeedbackRange{
+			{From: 0, To: 59, Text: "Try again"},
+			{From: 60, To: 100, Text: "Well done"},
+		},
+		Questions: []Question{
+			{Library: "H5P.MultiChoice 1.16", SubContentID: "mc", Params: &schemas.MultiChoiceParams{
+				Question: "Primes?",
+				Answers:  []schemas.AnswerOption{{Text: "2", Correct: true}, {Text: "3", Correct: true}, {Text: "4"}},
+			}},
+			{Library: "H5P.TrueFalse 1.8", SubContentID: "tf", Params: map[string]any{"question": "Sun is a star", "correct": "true"}},
+			{Library: "H5P.Blanks 1.14", Params: map[string]any{"questions": []any{"*Paris* is in *France*."}}},
+			{Library: "H5P.Essay 1.5", SubContentID: "essay", Params: map[string]any{"taskDescription": "Discuss"}},
+		},
+	}
+}
+
+func TestQuestionSetScore(t *testing.T) {
+	qs := scoreTestSet()
+	yes := true
+	s, err := qs.Score(map[string]grading.Response{
+		"mc": {Selected: []int{0, 1}},
+		"tf": {Answer: &yes},
+		"2":  {Texts: []string{"Paris", "Spain"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 2/2 + 1/1 + 1/2, the essay is not graded.
+	if s.Score != 4 || s.MaxScore != 5 || s.Percentage != 80 || !s.Passed || s.Feedback != "Well done" {
+		t.Errorf("Unexpected score %+v", s)
+	}
+	if q := s.Questions[3]; q.Graded || q.Answered || q.Key != "essay" {
+		t.Errorf("Expected the essay ungraded, got %+v", q)
+	}
+	if w := s.Weighted(nil); math.Abs(w-2.5/3) > 1e-9 {
+		t.Errorf("Expected equal weights to give %v, got %v", 2.5/3, w)
+	}
+	if w := s.Weighted(map[string]float64{"mc": 0, "tf": 0}); w != 0.5 {
+		t.Errorf("Expected only the blanks to weigh, got %v", w)
+	}
+
+	s, err = qs.Score(map[string]grading.Response{"tf": {Answer: &yes}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Score != 1 || s.MaxScore != 5 || s.Passed || s.Feedback != "Try again" || s.Questions[0].Answered {
+		t.Errorf("Expected unanswered questions to score 0, got %+v", s)
+	}
+
+	if _, err := qs.Score(map[string]grading.Response{"mc": {Selected: []int{7}}}); !errors.Is(err, grading.ErrInvalidResponse) {
+		t.Errorf("Expected ErrInvalidResponse, got %v", err)
+	}
+}
+
+func TestQuestionGrade(t *testing.T) {
+	q := Question{Library: "H5P.MarkTheWords 1.11", Params: map[string]any{"textField": "The *sun* is hot."}}
+	r, err := q.Grade(grading.Response{Selected: []int{1}})
+	if err != nil || r.Score != 1 || r.MaxScore != 1 {
+		t.Errorf("Unexpected result %+v, %v", r, err)
+	}
+	q = Question{Library: "H5P.Essay 1.5", Params: map[string]any{}}
+	if _, err := q.Grade(grading.Response{}); !errors.Is(err, ErrNotGradable) {
+		t.Errorf("Expected ErrNotGradable, got %v", err)
+	}
+}
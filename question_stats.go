@@ -0,0 +1,72 @@
+package h5p
+
+import (
+	"github.com/grokify/h5p-go/schemas"
+)
+
+// QuestionStats summarizes the answers of a question.
+type QuestionStats struct {
+	Library string `json:"library"`
+	Title   string `json:"title,omitempty"`
+	// Answers is the number of answer options, or of gaps and drop zones
+	// for questions learners fill in. Correct is how many of them count as
+	// correct, all of them for gaps. Both are 0 for question types without
+	// automatic scoring, such as Essay, and for unknown types.
+	Answers int `json:"answers"`
+	Correct int `json:"correct"`
+}
+
+// Stats counts the answers of the question. Params that cannot be decoded
+// count as having no answers.
+func (q *Question) Stats() QuestionStats {
+	s := QuestionStats{Library: q.Library}
+	if q.Metadata != nil {
+		s.Title = q.Metadata.Title
+	}
+	if params, ok, err := q.multiChoiceParams(); ok {
+		if err == nil {
+			s.Answers = len(params.Answers)
+			for _, a := range params.Answers {
+				if a.Correct {
+					s.Correct++
+				}
+			}
+		}
+		return s
+	}
+
+	switch q.MachineName() {
+	case "H5P.TrueFalse":
+		s.Answers, s.Correct = 2, 1
+	case "H5P.Blanks":
+		var params schemas.BlanksParams
+		if q.DecodeParams(&params) == nil {
+			for _, text := range params.Questions {
+				s.Answers += len(schemas.ParseBlanks(text))
+			}
+			s.Correct = s.Answers
+		}
+	case "H5P.DragText":
+		var params schemas.DragTextParams
+		if q.DecodeParams(&params) == nil {
+			s.Answers = len(schemas.ParseDraggables(params.TextField))
+			s.Correct = s.Answers
+		}
+	case "H5P.SingleChoiceSet":
+		// The first answer of each choice is the correct one.
+		var params struct {
+			Choices []struct {
+				Answers []string `json:"answers"`
+			} `json:"choices"`
+		}
+		if q.DecodeParams(&params) == nil {
+			for _, c := range params.Choices {
+				s.Answers += len(c.Answers)
+				if len(c.Answers) > 0 {
+					s.Correct++
+				}
+			}
+		}
+	}
+	return s
+}
@@ -0,0 +1,67 @@
+package h5p
+
+import (
+	"testing"
+
+	"github.com/grokify/h5p-go/schemas"
+)
+
+func TestQuestionStats(t *testing.T) {
+	tests := []struct {
+		name     string
+		question Question
+		want     QuestionStats
+	}{
+		{
+			name: "typed MultiChoice",
+			question: *NewMultiChoiceQuestion(&schemas.MultiChoiceParams{Answers: []schemas.AnswerOption{
+				{Text: "A", Correct: true}, {Text: "B", Correct: true}, {Text: "C"},
+			}}).ToQuestion(),
+			want: QuestionStats{Library: "H5P.MultiChoice 1.16", Title: "Untitled Multiple Choice", Answers: 3, Correct: 2},
+		},
+		{
+			name:     "TrueFalse",
+			question: Question{Library: "H5P.TrueFalse 1.8", Params: map[string]any{"correct": "false"}},
+			want:     QuestionStats{Library: "H5P.TrueFalse 1.8", Answers: 2, Correct: 1},
+		},
+		{
+			name: "Blanks",
+			question: Question{Library: "H5P.Blanks 1.14", Params: map[string]any{
+				"questions": []any{"<p>*Paris* is in *France/FR*.</p>", "<p>*Rome*</p>"},
+			}},
+			want: QuestionStats{Library: "H5P.Blanks 1.14", Answers: 3, Correct: 3},
+		},
+		{
+			name:     "DragText",
+			question: Question{Library: "H5P.DragText 1.10", Params: map[string]any{"textField": "*A* and *B*\n*C*"}},
+			want:     QuestionStats{Library: "H5P.DragText 1.10", Answers: 3, Correct: 3},
+		},
+		{
+			name: "SingleChoiceSet",
+			question: Question{Library: "H5P.SingleChoiceSet 1.11", Params: map[string]any{
+				"choices": []any{
+					map[string]any{"answers": []any{"right", "wrong", "wrong"}},
+					map[string]any{"answers": []any{"right", "wrong"}},
+				},
+			}},
+			want: QuestionStats{Library: "H5P.SingleChoiceSet 1.11", Answers: 5, Correct: 2},
+		},
+		{
+			name:     "Essay",
+			question: Question{Library: "H5P.Essay 1.5", Params: map[string]any{"taskDescription": "Discuss."}},
+			want:     QuestionStats{Library: "H5P.Essay 1.5"},
+		},
+		{
+			name:     "invalid MultiChoice params",
+			question: Question{Library: "H5P.MultiChoice 1.16", Params: map[string]any{"answers": "none"}},
+			want:     QuestionStats{Library: "H5P.MultiChoice 1.16"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.question.Stats(); got != tt.want {
+				t.Errorf("Expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
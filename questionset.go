@@ -1,6 +1,10 @@
 package h5p
 
 import (
+	"encoding/json"
+	"fmt"
+	"slices"
+
 	"github.com/grokify/h5p-go/schemas"
 )
 
@@ -17,46 +21,222 @@ type QuestionSet struct {
 	Message            string           `json:"message,omitempty"`
 	SolutionButtonText string           `json:"solutionButtonText,omitempty"`
 	OverallFeedback    []FeedbackRange  `json:"overallFeedback,omitempty"`
+	// RandomQuestions shows the questions in random order.
+	RandomQuestions bool `json:"randomQuestions,omitempty"`
+	// PoolSize, if set, shows a random batch of that many questions; 0
+	// shows all.
+	PoolSize int `json:"poolSize,omitempty"`
+	// Texts are the labels of the navigation.
+	Texts *QuestionSetTexts `json:"texts,omitempty"`
+	// EndGame configures the result page. It supersedes the loose
+	// ShowResultPage, Message, SolutionButtonText and OverallFeedback
+	// fields, which are kept for compatibility.
+	EndGame *EndGame `json:"endGame,omitempty"`
+}
+
+// QuestionSetTexts is the "texts" group of the QuestionSet semantics.
+// Empty texts get the defaults of the content type.
+type QuestionSetTexts struct {
+	PrevButton             string `json:"prevButton,omitempty"`
+	NextButton             string `json:"nextButton,omitempty"`
+	FinishButton           string `json:"finishButton,omitempty"`
+	SubmitButton           string `json:"submitButton,omitempty"`
+	TextualProgress        string `json:"textualProgress,omitempty"`
+	JumpToQuestion         string `json:"jumpToQuestion,omitempty"`
+	QuestionLabel          string `json:"questionLabel,omitempty"`
+	ReadSpeakerProgress    string `json:"readSpeakerProgress,omitempty"`
+	UnansweredText         string `json:"unansweredText,omitempty"`
+	AnsweredText           string `json:"answeredText,omitempty"`
+	CurrentQuestionText    string `json:"currentQuestionText,omitempty"`
+	NavigationLabel        string `json:"navigationLabel,omitempty"`
+	QuestionSetInstruction string `json:"questionSetInstruction,omitempty"`
+}
+
+// EndGame is the "endGame" group of the QuestionSet semantics, shown when
+// the quiz is finished. The flags are always written, so a zero EndGame
+// hides the result page and its buttons; NewEndGame returns the defaults
+// of the H5P editor.
+type EndGame struct {
+	ShowResultPage     bool `json:"showResultPage"`
+	ShowSolutionButton bool `json:"showSolutionButton"`
+	ShowRetryButton    bool `json:"showRetryButton"`
+	// NoResultMessage is shown instead of the result page if
+	// ShowResultPage is false.
+	NoResultMessage string `json:"noResultMessage,omitempty"`
+	// Message is the heading of the score.
+	Message         string                   `json:"message,omitempty"`
+	ScoreBarLabel   string                   `json:"scoreBarLabel,omitempty"`
+	OverallFeedback *schemas.OverallFeedback `json:"overallFeedback,omitempty"`
+
+	SolutionButtonText string `json:"solutionButtonText,omitempty"`
+	RetryButtonText    string `json:"retryButtonText,omitempty"`
+	FinishButtonText   string `json:"finishButtonText,omitempty"`
+	SubmitButtonText   string `json:"submitButtonText,omitempty"`
+
+	// ShowAnimations plays SuccessVideo or FailVideo before the results.
+	ShowAnimations bool   `json:"showAnimations,omitempty"`
+	Skippable      bool   `json:"skippable,omitempty"`
+	SkipButtonText string `json:"skipButtonText,omitempty"`
+
+	SuccessGreeting string              `json:"successGreeting,omitempty"`
+	SuccessComment  string              `json:"successComment,omitempty"`
+	SuccessVideo    []schemas.VideoFile `json:"successVideo,omitempty"`
+	FailGreeting    string              `json:"failGreeting,omitempty"`
+	FailComment     string              `json:"failComment,omitempty"`
+	FailVideo       []schemas.VideoFile `json:"failVideo,omitempty"`
+}
+
+// NewEndGame returns an EndGame with the defaults of the H5P editor.
+func NewEndGame() *EndGame {
+	return &EndGame{
+		ShowResultPage:     true,
+		ShowSolutionButton: true,
+		ShowRetryButton:    true,
+		NoResultMessage:    "Finished",
+		Message:            "Your result:",
+		ScoreBarLabel:      "You got @finals out of @totals points",
+		SolutionButtonText: "Show solution",
+		RetryButtonText:    "Retry",
+		FinishButtonText:   "Finish",
+		SubmitButtonText:   "Submit",
+		SkipButtonText:     "Skip video",
+	}
 }
 
 type BackgroundImage struct {
 	Path      string     `json:"path"`
 	Mime      string     `json:"mime"`
 	Copyright *Copyright `json:"copyright,omitempty"`
+	Width     int        `json:"width,omitempty"`
+	Height    int        `json:"height,omitempty"`
 }
 
-type Copyright struct {
-	Title   string `json:"title,omitempty"`
-	Author  string `json:"author,omitempty"`
-	License string `json:"license,omitempty"`
-	Version string `json:"version,omitempty"`
-	Source  string `json:"source,omitempty"`
-}
+type Copyright = schemas.Copyright
 
 type Question struct {
-	Library string      `json:"library"`
-	Params  interface{} `json:"params"`
+	Library      string           `json:"library"`
+	Params       interface{}      `json:"params"`
+	SubContentID string           `json:"subContentId,omitempty"`
+	Metadata     *ContentMetadata `json:"metadata,omitempty"`
+}
+
+// checkQuestionIndex returns ErrQuestionIndex unless 0 <= i < n.
+func checkQuestionIndex(i, n int) error {
+	if i < 0 || i >= n {
+		return fmt.Errorf("%w: %d not in [0, %d)", ErrQuestionIndex, i, n)
+	}
+	return nil
+}
+
+// InsertQuestion inserts q before the question at index i; i equal to the
+// number of questions appends it.
+func (qs *QuestionSet) InsertQuestion(i int, q Question) error {
+	if err := checkQuestionIndex(i, len(qs.Questions)+1); err != nil {
+		return err
+	}
+	qs.Questions = slices.Insert(qs.Questions, i, q)
+	return nil
+}
+
+// ReplaceQuestion replaces the question at index i.
+func (qs *QuestionSet) ReplaceQuestion(i int, q Question) error {
+	if err := checkQuestionIndex(i, len(qs.Questions)); err != nil {
+		return err
+	}
+	qs.Questions[i] = q
+	return nil
+}
+
+// RemoveQuestion removes the question at index i.
+func (qs *QuestionSet) RemoveQuestion(i int) error {
+	if err := checkQuestionIndex(i, len(qs.Questions)); err != nil {
+		return err
+	}
+	qs.Questions = slices.Delete(qs.Questions, i, i+1)
+	return nil
+}
+
+// MoveQuestion moves the question at index from to index to, shifting the
+// questions in between.
+func (qs *QuestionSet) MoveQuestion(from, to int) error {
+	if err := checkQuestionIndex(from, len(qs.Questions)); err != nil {
+		return err
+	}
+	if err := checkQuestionIndex(to, len(qs.Questions)); err != nil {
+		return err
+	}
+	q := qs.Questions[from]
+	qs.Questions = slices.Insert(slices.Delete(qs.Questions, from, from+1), to, q)
+	return nil
+}
+
+// FilterQuestions keeps the questions keep reports true for, in order.
+func (qs *QuestionSet) FilterQuestions(keep func(q *Question) bool) {
+	qs.Questions = slices.DeleteFunc(qs.Questions, func(q Question) bool { return !keep(&q) })
+}
+
+// MapQuestions replaces each question by the result of fn.
+func (qs *QuestionSet) MapQuestions(fn func(q Question) Question) {
+	for i, q := range qs.Questions {
+		qs.Questions[i] = fn(q)
+	}
+}
+
+// MachineName returns the machine name of the question's library, e.g.
+// "H5P.MultiChoice", or "" if Library is not of the form "Name Major.Minor".
+func (q *Question) MachineName() string {
+	dep, err := ParseLibraryString(q.Library)
+	if err != nil {
+		return ""
+	}
+	return dep.MachineName
+}
+
+// DecodeParams decodes the question params into v, a pointer to typed
+// params such as *schemas.TrueFalseParams. It accepts both generic params
+// as loaded from content.json and typed params.
+func (q *Question) DecodeParams(v any) error {
+	data, err := json.Marshal(q.Params)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
 }
 
 // MultiChoiceQuestion represents a typed H5P MultiChoice question
 type MultiChoiceQuestion struct {
-	Library string                     `json:"library"`
-	Params  *schemas.MultiChoiceParams `json:"params"`
+	Library      string                     `json:"library"`
+	Params       *schemas.MultiChoiceParams `json:"params"`
+	SubContentID string                     `json:"subContentId,omitempty"`
+	Metadata     *ContentMetadata           `json:"metadata,omitempty"`
 }
 
 // ToQuestion converts a MultiChoiceQuestion to a generic Question
 func (mcq *MultiChoiceQuestion) ToQuestion() *Question {
 	return &Question{
-		Library: mcq.Library,
-		Params:  mcq.Params,
+		Library:      mcq.Library,
+		Params:       mcq.Params,
+		SubContentID: mcq.SubContentID,
+		Metadata:     mcq.Metadata,
 	}
 }
 
-// NewMultiChoiceQuestion creates a new typed MultiChoice question
+// NewMultiChoiceQuestion creates a new typed MultiChoice question with a
+// fresh subContentId
 func NewMultiChoiceQuestion(params *schemas.MultiChoiceParams) *MultiChoiceQuestion {
 	return &MultiChoiceQuestion{
-		Library: "H5P.MultiChoice 1.16",
-		Params:  params,
+		Library:      "H5P.MultiChoice 1.16",
+		Params:       params,
+		SubContentID: NewSubContentID(),
+		Metadata:     newMultiChoiceMetadata(),
+	}
+}
+
+func newMultiChoiceMetadata() *ContentMetadata {
+	return &ContentMetadata{
+		Title:       "Untitled Multiple Choice",
+		ContentType: "Multiple Choice",
+		License:     "U",
 	}
 }
 
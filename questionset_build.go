@@ -0,0 +1,161 @@
+package h5p
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+)
+
+// LibraryInstaller adds the libraries a package depends on but does not
+// contain, e.g. a *hub.Client downloading them from the H5P Hub.
+type LibraryInstaller interface {
+	InstallDependenciesContext(ctx context.Context, pkg *H5PPackage) (*MergeResult, error)
+}
+
+// PackageOption configures BuildQuestionSetPackage.
+type PackageOption func(o *packageOptions)
+
+type packageOptions struct {
+	language    string
+	direction   string
+	license     string
+	authors     []Author
+	libraryDirs []string
+	installer   LibraryInstaller
+}
+
+// WithPackageLanguage sets the language of h5p.json, e.g. "de". The
+// default is "und". The user interface texts the question set and its
+// questions do not set are taken from DefaultUITranslations of language,
+// if there are any.
+func WithPackageLanguage(language string) PackageOption {
+	return func(o *packageOptions) { o.language = language }
+}
+
+// WithTextDirection sets the direction of the texts, DirectionLTR or
+// DirectionRTL. The default is the direction of the package language, see
+// LanguageDirection. HTML texts of right-to-left packages are wrapped in
+// an element with dir="rtl", see QuestionSet.WithTextDirection.
+func WithTextDirection(dir string) PackageOption {
+	return func(o *packageOptions) { o.direction = dir }
+}
+
+// WithPackageLicense sets the license of h5p.json, e.g. "CC BY 4.0". The
+// default is "U", undisclosed.
+func WithPackageLicense(license string) PackageOption {
+	return func(o *packageOptions) { o.license = license }
+}
+
+// WithPackageAuthors sets the authors of h5p.json.
+func WithPackageAuthors(authors ...Author) PackageOption {
+	return func(o *packageOptions) { o.authors = authors }
+}
+
+// WithLibraryDir bundles the required libraries found in dir, a folder of
+// unpacked libraries such as the libraries folder of an H5P site. Several
+// folders are searched in the order given.
+func WithLibraryDir(dir string) PackageOption {
+	return func(o *packageOptions) { o.libraryDirs = append(o.libraryDirs, dir) }
+}
+
+// WithLibraryInstaller bundles the required libraries not found in the
+// folders given by WithLibraryDir using installer.
+func WithLibraryInstaller(installer LibraryInstaller) PackageOption {
+	return func(o *packageOptions) { o.installer = installer }
+}
+
+// BuildQuestionSetPackage returns a package with qs as its content, ready
+// to be written with CreateZipFile or WriteTo. Its h5p.json names
+// H5P.QuestionSet as the main library and lists the libraries of the
+// questions as dependencies, at the versions used by qs and, for the
+// question set, the version in LatestLibraryVersions.
+//
+// Without WithLibraryDir or WithLibraryInstaller the package holds no
+// libraries, which suits H5P sites that have them installed. Otherwise the
+// libraries are bundled, and a *DependencyError reports those not found.
+func BuildQuestionSetPackage(qs *QuestionSet, opts ...PackageOption) (*H5PPackage, error) {
+	return BuildQuestionSetPackageContext(context.Background(), qs, opts...)
+}
+
+// BuildQuestionSetPackageContext is BuildQuestionSetPackage with a context
+// for the library installer.
+func BuildQuestionSetPackageContext(ctx context.Context, qs *QuestionSet, opts ...PackageOption) (*H5PPackage, error) {
+	o := packageOptions{language: "und", license: "U"}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if err := qs.Validate(); err != nil {
+		return nil, err
+	}
+	qs = qs.Clone()
+	qs.EnsureSubContentIDs()
+	if t, err := DefaultUITranslations(o.language); err == nil {
+		t.Apply(qs)
+	}
+	if o.direction == "" {
+		o.direction = LanguageDirection(o.language)
+	}
+	if err := checkDirection(o.direction); err != nil {
+		return nil, err
+	}
+	if o.direction == DirectionRTL {
+		rtl, err := qs.WithTextDirection(DirectionRTL)
+		if err != nil {
+			return nil, err
+		}
+		qs = rtl
+	}
+
+	library, _ := LatestLibraryString(questionSetLibrary)
+	main, err := ParseLibraryString(library)
+	if err != nil {
+		return nil, err
+	}
+	title := qs.Title
+	if title == "" {
+		title = "Untitled"
+	}
+	pkg := NewH5PPackage()
+	pkg.SetContent(&Content{Params: qs})
+	deps := []LibraryDependency{main}
+	for _, dep := range pkg.contentLibraryReferences() {
+		if !slices.Contains(deps, dep) {
+			deps = append(deps, dep)
+		}
+	}
+	pkg.SetPackageDefinition(&PackageDefinition{
+		Title:                 title,
+		Language:              o.language,
+		MainLibrary:           questionSetLibrary,
+		EmbedTypes:            []string{"iframe"},
+		License:               o.license,
+		Authors:               o.authors,
+		PreloadedDependencies: deps,
+	})
+
+	if len(o.libraryDirs) == 0 && o.installer == nil {
+		return pkg, nil
+	}
+	for _, dir := range o.libraryDirs {
+		src, err := LoadH5PPackageFS(os.DirFS(dir))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load libraries from %s: %w", dir, err)
+		}
+		if _, err := MergeRequiredLibraries(pkg, src, MergeOptions{}); err != nil {
+			return nil, err
+		}
+	}
+	if o.installer != nil {
+		if _, err := o.installer.InstallDependenciesContext(ctx, pkg); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := pkg.ResolveDependencies(); err != nil {
+		return nil, err
+	}
+	if lib := pkg.GetLibrary(main.MachineName, main.MajorVersion, main.MinorVersion); lib != nil && lib.Definition != nil && len(lib.Definition.EmbedTypes) > 0 {
+		pkg.PackageDefinition.EmbedTypes = lib.Definition.EmbedTypes
+	}
+	return pkg, nil
+}
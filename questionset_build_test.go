@@ -0,0 +1,92 @@
+package h5p
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// stubInstaller adds the libraries it has for missing dependencies.
+type stubInstaller struct {
+	libraries *H5PPackage
+}
+
+func (s stubInstaller) InstallDependenciesContext(ctx context.Context, pkg *H5PPackage) (*MergeResult, error) {
+	return MergeRequiredLibraries(pkg, s.libraries, MergeOptions{})
+}
+
+func TestBuildQuestionSetPackage(t *testing.T) {
+	qs, err := NewQuestionSetBuilder().
+		SetTitle("Capitals").
+		AddMultipleChoiceQuestion("Capital of France?", []Answer{CreateAnswer("Paris", true)}).
+		AddTrueFalseQuestion("Berlin is in Germany.", true, nil).
+		AddMultipleChoiceQuestion("Capital of Spain?", []Answer{CreateAnswer("Madrid", true)}).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pkg, err := BuildQuestionSetPackage(qs, WithPackageLanguage("en"), WithPackageAuthors(Author{Name: "Ada", Role: "Author"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	def := pkg.PackageDefinition
+	if def.Title != "Capitals" || def.Language != "en" || def.MainLibrary != "H5P.QuestionSet" || def.License != "U" || len(def.Authors) != 1 {
+		t.Errorf("Unexpected h5p.json %+v", def)
+	}
+	want := []LibraryDependency{dep("H5P.QuestionSet", 1, 20), dep("H5P.MultiChoice", 1, 16), dep("H5P.TrueFalse", 1, 8)}
+	if !reflect.DeepEqual(def.PreloadedDependencies, want) {
+		t.Errorf("Expected dependencies %v, got %v", want, def.PreloadedDependencies)
+	}
+	if len(pkg.Libraries) != 0 {
+		t.Errorf("Expected no libraries, got %d", len(pkg.Libraries))
+	}
+	loaded, err := pkg.QuestionSet()
+	if err != nil || len(loaded.Questions) != 3 {
+		t.Fatalf("Expected the question set as content, got %v", err)
+	}
+
+	if _, err := BuildQuestionSetPackage(&QuestionSet{PassPercentage: 150, Questions: qs.Questions}); err == nil {
+		t.Error("Expected an invalid question set to fail")
+	}
+}
+
+func TestBuildQuestionSetPackageLibraries(t *testing.T) {
+	qs, err := NewQuestionSetBuilder().
+		SetTitle("Facts").
+		AddTrueFalseQuestion("Water is wet.", true, nil).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	site := NewH5PPackage()
+	site.AddLibrary(newTestLibrary("H5P.QuestionSet", 1, 20, dep("H5P.JoubelUI", 1, 3)))
+	site.AddLibrary(newTestLibrary("H5P.JoubelUI", 1, 3))
+	site.AddLibrary(newTestLibrary("H5P.MultiChoice", 1, 16))
+	dir := t.TempDir()
+	if err := site.ExtractToDir(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = BuildQuestionSetPackage(qs, WithLibraryDir(dir))
+	var depErr *DependencyError
+	if !errors.As(err, &depErr) || len(depErr.Missing) != 1 || depErr.Missing[0].Dependency != dep("H5P.TrueFalse", 1, 8) {
+		t.Fatalf("Expected H5P.TrueFalse missing, got %v", err)
+	}
+
+	hub := NewH5PPackage()
+	hub.AddLibrary(newTestLibrary("H5P.TrueFalse", 1, 8, dep("H5P.JoubelUI", 1, 3)))
+	pkg, err := BuildQuestionSetPackage(qs, WithLibraryDir(dir), WithLibraryInstaller(stubInstaller{hub}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, lib := range pkg.Libraries {
+		names = append(names, lib.MachineName)
+	}
+	want := []string{"H5P.QuestionSet-1.20", "H5P.JoubelUI-1.3", "H5P.TrueFalse-1.8"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("Expected libraries %v, got %v", want, names)
+	}
+}
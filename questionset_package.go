@@ -0,0 +1,273 @@
+package h5p
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+)
+
+// ErrNotQuestionSet is returned for packages whose main library is not
+// H5P.QuestionSet.
+var ErrNotQuestionSet = errors.New("not a question set package")
+
+const questionSetLibrary = "H5P.QuestionSet"
+
+// QuestionSet returns a copy of the content of a package whose main
+// library is H5P.QuestionSet, whether it is held as generic params or in
+// Content.QuestionSet.
+func (pkg *H5PPackage) QuestionSet() (*QuestionSet, error) {
+	if pkg.PackageDefinition == nil || pkg.PackageDefinition.MainLibrary != questionSetLibrary {
+		return nil, ErrNotQuestionSet
+	}
+	if pkg.Content == nil {
+		return nil, fmt.Errorf("%w: package has no content", ErrNotQuestionSet)
+	}
+	if pkg.Content.QuestionSet != nil {
+		return pkg.Content.QuestionSet.Clone(), nil
+	}
+	data, err := json.Marshal(pkg.Content)
+	if err != nil {
+		return nil, err
+	}
+	qs, err := FromJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid content: %v", ErrNotQuestionSet, err)
+	}
+	return qs, nil
+}
+
+// MergeQuestionSetPackages combines question set packages into a new one
+// holding the questions of all of them in order. The settings, title and
+// h5p.json of the first package are kept; the preloaded dependencies are
+// the union of all, keeping the highest minor version, and libraries are
+// combined as by MergePackages. Content files are copied, and a file whose
+// path is taken by a different file is renamed, with the params that refer
+// to it updated. Questions whose subContentId is missing or already used
+// get a new one.
+//
+// Libraries and content files are shared, not copied, so the packages must
+// stay open while the result uses lazily loaded files from them.
+func MergeQuestionSetPackages(pkgs []*H5PPackage, opts MergeOptions) (*H5PPackage, error) {
+	if len(pkgs) == 0 {
+		return nil, fmt.Errorf("%w: no packages to merge", ErrNotQuestionSet)
+	}
+	merged := NewH5PPackage()
+	merged.ContentFiles = map[string]*ContentFile{}
+	def := deepCopy(pkgs[0].PackageDefinition)
+	var qs *QuestionSet
+
+	for i, pkg := range pkgs {
+		set, err := pkg.QuestionSet()
+		if err != nil {
+			return nil, fmt.Errorf("package %d: %w", i+1, err)
+		}
+		renamed, err := mergeContentFiles(merged, pkg)
+		if err != nil {
+			return nil, fmt.Errorf("package %d: %w", i+1, err)
+		}
+		if len(renamed) > 0 {
+			if set, err = renameAssetReferences(set, renamed); err != nil {
+				return nil, fmt.Errorf("package %d: %w", i+1, err)
+			}
+		}
+		if i == 0 {
+			qs = set
+		} else {
+			qs.Questions = append(qs.Questions, set.Questions...)
+			deps, err := mergeDependencies(def.PreloadedDependencies, pkg.PackageDefinition.PreloadedDependencies, opts)
+			if err != nil {
+				return nil, fmt.Errorf("package %d: %w", i+1, err)
+			}
+			def.PreloadedDependencies = deps
+		}
+		if _, err := MergePackages(merged, pkg, opts); err != nil {
+			return nil, fmt.Errorf("package %d: %w", i+1, err)
+		}
+	}
+
+	seen := map[string]bool{}
+	for i := range qs.Questions {
+		q := &qs.Questions[i]
+		if q.SubContentID == "" || seen[q.SubContentID] {
+			q.SubContentID = NewSubContentID()
+		}
+		seen[q.SubContentID] = true
+	}
+	merged.SetPackageDefinition(def)
+	merged.SetContent(&Content{Params: qs})
+	return merged, nil
+}
+
+// mergeContentFiles adds the content files of src to dst, returning the
+// new paths of the files renamed because their path was taken.
+func mergeContentFiles(dst, src *H5PPackage) (map[string]string, error) {
+	renamed := map[string]string{}
+	for _, name := range src.ContentFileNames() {
+		cf := src.ContentFiles[name]
+		if existing, ok := dst.ContentFiles[name]; ok {
+			same, err := sameContentFile(existing, cf)
+			if err != nil {
+				return nil, err
+			}
+			if same {
+				continue
+			}
+			newName := dst.uniqueContentPath(path.Dir(name), path.Base(name))
+			renamed[name] = newName
+			name = newName
+		}
+		dst.ContentFiles[name] = cf
+	}
+	return renamed, nil
+}
+
+func sameContentFile(a, b *ContentFile) (bool, error) {
+	if a == b {
+		return true, nil
+	}
+	da, err := a.Bytes()
+	if err != nil {
+		return false, err
+	}
+	db, err := b.Bytes()
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(da, db), nil
+}
+
+// renameAssetReferences returns qs with the media paths in renamed
+// replaced by their new names.
+func renameAssetReferences(qs *QuestionSet, renamed map[string]string) (*QuestionSet, error) {
+	params, err := toGenericJSON(qs)
+	if err != nil {
+		return nil, err
+	}
+	renameAssetPaths(params, renamed)
+	data, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+	return FromJSON(data)
+}
+
+// mergeDependencies adds the dependencies of src missing from dst, raising
+// the minor version of those present in both to the higher one.
+func mergeDependencies(dst, src []LibraryDependency, opts MergeOptions) ([]LibraryDependency, error) {
+	for _, dep := range src {
+		i := slices.IndexFunc(dst, func(d LibraryDependency) bool {
+			return d.MachineName == dep.MachineName && (d.MajorVersion == dep.MajorVersion || !opts.AllowMultipleMajors)
+		})
+		switch {
+		case i < 0:
+			dst = append(dst, dep)
+		case dst[i].MajorVersion != dep.MajorVersion:
+			return nil, fmt.Errorf("%w: %s and %s", ErrIncompatibleMajor, dst[i], dep)
+		case dep.MinorVersion > dst[i].MinorVersion:
+			dst[i].MinorVersion = dep.MinorVersion
+		}
+	}
+	return dst, nil
+}
+
+// Chunk splits the questions of qs into sets of at most size questions,
+// each with the settings of qs and its title numbered, e.g. "Quiz (2/3)".
+// A size below 1 puts all questions in a single set.
+func (qs *QuestionSet) Chunk(size int) []*QuestionSet {
+	if size < 1 {
+		size = max(len(qs.Questions), 1)
+	}
+	n := max((len(qs.Questions)+size-1)/size, 1)
+	parts := make([]*QuestionSet, 0, n)
+	for i := 0; i < n; i++ {
+		questions := qs.Questions[i*size : min((i+1)*size, len(qs.Questions))]
+		title := qs.Title
+		if n > 1 {
+			title = fmt.Sprintf("%s (%d/%d)", qs.Title, i+1, n)
+		}
+		parts = append(parts, qs.withQuestions(title, questions))
+	}
+	return parts
+}
+
+// GroupByTag splits the questions of qs by the tags in their metadata,
+// returning a set for each tag with the settings of qs and the tag added to
+// its title, e.g. "Quiz: algebra". A question with several tags is in the
+// set of each; untagged questions are in the set for "".
+func (qs *QuestionSet) GroupByTag() map[string]*QuestionSet {
+	groups := map[string][]Question{}
+	for _, q := range qs.Questions {
+		var tags []string
+		if q.Metadata != nil {
+			tags = q.Metadata.Tags
+		}
+		if len(tags) == 0 {
+			tags = []string{""}
+		}
+		seen := map[string]bool{}
+		for _, tag := range tags {
+			if !seen[tag] {
+				seen[tag] = true
+				groups[tag] = append(groups[tag], q)
+			}
+		}
+	}
+	sets := make(map[string]*QuestionSet, len(groups))
+	for tag, questions := range groups {
+		title := qs.Title
+		if tag != "" {
+			title = fmt.Sprintf("%s: %s", qs.Title, tag)
+		}
+		sets[tag] = qs.withQuestions(title, questions)
+	}
+	return sets
+}
+
+// withQuestions returns a copy of qs with the given title and a copy of
+// questions.
+func (qs *QuestionSet) withQuestions(title string, questions []Question) *QuestionSet {
+	rest := *qs
+	rest.Questions = nil
+	part := rest.Clone()
+	part.Title = title
+	part.Questions = deepCopy(slices.Clone(questions))
+	return part
+}
+
+// WithQuestionSet returns a package with the h5p.json and libraries of pkg
+// and qs as its content, e.g. for a part returned by QuestionSet.Chunk. It
+// holds only the content files qs refers to. The title is taken from qs
+// when set. Libraries and content files are shared with pkg, which must
+// stay open while the result uses lazily loaded files from it.
+func (pkg *H5PPackage) WithQuestionSet(qs *QuestionSet) (*H5PPackage, error) {
+	if pkg.PackageDefinition == nil || pkg.PackageDefinition.MainLibrary != questionSetLibrary {
+		return nil, ErrNotQuestionSet
+	}
+	part := NewH5PPackage()
+	def := deepCopy(pkg.PackageDefinition)
+	if qs.Title != "" {
+		def.Title = qs.Title
+	}
+	part.SetPackageDefinition(def)
+	part.SetContent(&Content{Params: qs})
+	for _, lib := range pkg.Libraries {
+		part.AddLibrary(lib)
+	}
+
+	params, err := toGenericJSON(qs)
+	if err != nil {
+		return nil, err
+	}
+	for _, ref := range findAssetReferences(params, "") {
+		if cf, ok := pkg.ContentFiles[ref.Path]; ok {
+			if part.ContentFiles == nil {
+				part.ContentFiles = map[string]*ContentFile{}
+			}
+			part.ContentFiles[ref.Path] = cf
+		}
+	}
+	return part, nil
+}
@@ -0,0 +1,181 @@
+package h5p
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func newTestQuestionSetPackage(title string, deps []LibraryDependency, questions ...Question) *H5PPackage {
+	pkg := NewH5PPackage()
+	pkg.SetPackageDefinition(&PackageDefinition{
+		Title:                 title,
+		MainLibrary:           "H5P.QuestionSet",
+		PreloadedDependencies: deps,
+	})
+	pkg.SetContent(&Content{Params: &QuestionSet{Title: title, PassPercentage: 50, Questions: questions}})
+	return pkg
+}
+
+func imageQuestion(id, image string, tags ...string) Question {
+	return Question{
+		Library:      "H5P.MultiChoice 1.16",
+		SubContentID: id,
+		Params: map[string]any{
+			"question": id,
+			"media":    map[string]any{"type": map[string]any{"params": map[string]any{"file": map[string]any{"path": image}}}},
+		},
+		Metadata: &ContentMetadata{Title: id, Tags: tags},
+	}
+}
+
+func TestMergeQuestionSetPackages(t *testing.T) {
+	a := newTestQuestionSetPackage("A", []LibraryDependency{dep("H5P.QuestionSet", 1, 17), dep("H5P.MultiChoice", 1, 14)},
+		imageQuestion("q1", "images/cat.png"))
+	a.AddLibrary(newTestLibrary("H5P.MultiChoice", 1, 14))
+	a.AddContentAsset("images/cat.png", []byte("cat"), "")
+
+	b := newTestQuestionSetPackage("B", []LibraryDependency{dep("H5P.QuestionSet", 1, 20), dep("H5P.TrueFalse", 1, 8)},
+		imageQuestion("q1", "images/cat.png"), imageQuestion("q2", "images/dog.png"))
+	b.AddLibrary(newTestLibrary("H5P.TrueFalse", 1, 8))
+	b.AddContentAsset("images/cat.png", []byte("another cat"), "")
+	b.AddContentAsset("images/dog.png", []byte("dog"), "")
+
+	merged, err := MergeQuestionSetPackages([]*H5PPackage{a, b}, MergeOptions{})
+	if err != nil {
+		t.Fatalf("Failed to merge: %v", err)
+	}
+
+	wantDeps := []LibraryDependency{dep("H5P.QuestionSet", 1, 20), dep("H5P.MultiChoice", 1, 14), dep("H5P.TrueFalse", 1, 8)}
+	if !reflect.DeepEqual(merged.PackageDefinition.PreloadedDependencies, wantDeps) {
+		t.Errorf("Expected dependencies %v, got %v", wantDeps, merged.PackageDefinition.PreloadedDependencies)
+	}
+	if merged.PackageDefinition.Title != "A" || a.PackageDefinition.PreloadedDependencies[0].MinorVersion != 17 {
+		t.Error("Expected the first h5p.json to be copied, not modified")
+	}
+	if len(merged.Libraries) != 2 {
+		t.Errorf("Expected 2 libraries, got %d", len(merged.Libraries))
+	}
+	if got := merged.ContentFileNames(); !reflect.DeepEqual(got, []string{"images/cat-1.png", "images/cat.png", "images/dog.png"}) {
+		t.Errorf("Unexpected content files %v", got)
+	}
+
+	qs, err := merged.QuestionSet()
+	if err != nil {
+		t.Fatalf("Failed to read merged question set: %v", err)
+	}
+	if qs.Title != "A" || qs.PassPercentage != 50 || len(qs.Questions) != 3 {
+		t.Fatalf("Unexpected merged question set %+v", qs)
+	}
+	if qs.Questions[0].SubContentID != "q1" || qs.Questions[1].SubContentID == "q1" || qs.Questions[2].SubContentID != "q2" {
+		t.Errorf("Expected only the duplicate subContentId to be replaced, got %q, %q, %q",
+			qs.Questions[0].SubContentID, qs.Questions[1].SubContentID, qs.Questions[2].SubContentID)
+	}
+	report, err := merged.CheckAssetReferences()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.OK() || report.References[1].Path != "images/cat-1.png" {
+		t.Errorf("Expected renamed image to be referenced, got %+v", report)
+	}
+}
+
+func TestMergeQuestionSetPackagesErrors(t *testing.T) {
+	a := newTestQuestionSetPackage("A", []LibraryDependency{dep("H5P.MultiChoice", 1, 14)})
+	b := newTestQuestionSetPackage("B", []LibraryDependency{dep("H5P.MultiChoice", 2, 0)})
+	if _, err := MergeQuestionSetPackages([]*H5PPackage{a, b}, MergeOptions{}); !errors.Is(err, ErrIncompatibleMajor) {
+		t.Errorf("Expected ErrIncompatibleMajor, got %v", err)
+	}
+
+	c := newTestQuestionSetPackage("C", nil)
+	c.PackageDefinition.MainLibrary = "H5P.Blanks"
+	if _, err := MergeQuestionSetPackages([]*H5PPackage{a, c}, MergeOptions{}); !errors.Is(err, ErrNotQuestionSet) {
+		t.Errorf("Expected ErrNotQuestionSet, got %v", err)
+	}
+}
+
+func TestQuestionSetChunk(t *testing.T) {
+	qs := &QuestionSet{Title: "Quiz", PassPercentage: 60}
+	for _, id := range []string{"q1", "q2", "q3", "q4", "q5"} {
+		qs.Questions = append(qs.Questions, imageQuestion(id, ""))
+	}
+
+	parts := qs.Chunk(2)
+	if len(parts) != 3 {
+		t.Fatalf("Expected 3 parts, got %d", len(parts))
+	}
+	for i, want := range []int{2, 2, 1} {
+		if len(parts[i].Questions) != want {
+			t.Errorf("Part %d: expected %d questions, got %d", i+1, want, len(parts[i].Questions))
+		}
+		if parts[i].PassPercentage != 60 {
+			t.Errorf("Part %d: expected settings to be kept", i+1)
+		}
+	}
+	if parts[2].Title != "Quiz (3/3)" || parts[2].Questions[0].SubContentID != "q5" {
+		t.Errorf("Unexpected last part %+v", parts[2])
+	}
+	parts[0].Questions[0].Metadata.Title = "changed"
+	if qs.Questions[0].Metadata.Title != "q1" {
+		t.Error("Expected parts to hold copies of the questions")
+	}
+
+	if parts := qs.Chunk(10); len(parts) != 1 || parts[0].Title != "Quiz" {
+		t.Errorf("Expected a single part with the original title, got %d", len(parts))
+	}
+}
+
+func TestQuestionSetGroupByTag(t *testing.T) {
+	qs := &QuestionSet{Title: "Quiz", Questions: []Question{
+		imageQuestion("q1", "", "algebra"),
+		imageQuestion("q2", "", "algebra", "geometry", "algebra"),
+		imageQuestion("q3", ""),
+	}}
+
+	sets := qs.GroupByTag()
+	ids := func(set *QuestionSet) []string {
+		var ids []string
+		for _, q := range set.Questions {
+			ids = append(ids, q.SubContentID)
+		}
+		return ids
+	}
+	want := map[string][]string{"algebra": {"q1", "q2"}, "geometry": {"q2"}, "": {"q3"}}
+	if len(sets) != len(want) {
+		t.Fatalf("Expected %d sets, got %d", len(want), len(sets))
+	}
+	for tag, wantIDs := range want {
+		if got := ids(sets[tag]); !reflect.DeepEqual(got, wantIDs) {
+			t.Errorf("Tag %q: expected %v, got %v", tag, wantIDs, got)
+		}
+	}
+	if sets["geometry"].Title != "Quiz: geometry" || sets[""].Title != "Quiz" {
+		t.Errorf("Unexpected titles %q and %q", sets["geometry"].Title, sets[""].Title)
+	}
+}
+
+func TestWithQuestionSet(t *testing.T) {
+	pkg := newTestQuestionSetPackage("Quiz", []LibraryDependency{dep("H5P.QuestionSet", 1, 20)},
+		imageQuestion("q1", "images/cat.png"), imageQuestion("q2", "images/dog.png"))
+	pkg.AddLibrary(newTestLibrary("H5P.QuestionSet", 1, 20))
+	pkg.AddContentAsset("images/cat.png", []byte("cat"), "")
+	pkg.AddContentAsset("images/dog.png", []byte("dog"), "")
+
+	qs, err := pkg.QuestionSet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	part, err := pkg.WithQuestionSet(qs.Chunk(1)[1])
+	if err != nil {
+		t.Fatalf("Failed to create part: %v", err)
+	}
+	if part.PackageDefinition.Title != "Quiz (2/2)" || pkg.PackageDefinition.Title != "Quiz" {
+		t.Errorf("Unexpected titles %q and %q", part.PackageDefinition.Title, pkg.PackageDefinition.Title)
+	}
+	if got := part.ContentFileNames(); !reflect.DeepEqual(got, []string{"images/dog.png"}) {
+		t.Errorf("Expected only the referenced image, got %v", got)
+	}
+	if len(part.Libraries) != 1 {
+		t.Errorf("Expected libraries to be kept, got %d", len(part.Libraries))
+	}
+}
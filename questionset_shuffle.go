@@ -0,0 +1,43 @@
+package h5p
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+)
+
+// questionRand returns the random source of Shuffle and Sample. PCG gives
+// the same sequence for a seed on every platform and Go release, so
+// variants can be regenerated from their seed.
+func questionRand(seed int64) *rand.Rand {
+	return rand.New(rand.NewPCG(uint64(seed), 0))
+}
+
+// Shuffle returns a deep copy of the question set with the questions in an
+// order determined by seed; the same seed gives the same order.
+func (qs *QuestionSet) Shuffle(seed int64) *QuestionSet {
+	c := qs.Clone()
+	r := questionRand(seed)
+	r.Shuffle(len(c.Questions), func(i, j int) {
+		c.Questions[i], c.Questions[j] = c.Questions[j], c.Questions[i]
+	})
+	return c
+}
+
+// Sample returns a deep copy of the question set with n of its questions,
+// chosen by seed and kept in their original order; the same seed gives
+// the same questions. Shuffle the result to also vary the order.
+func (qs *QuestionSet) Sample(n int, seed int64) (*QuestionSet, error) {
+	if n < 0 || n > len(qs.Questions) {
+		return nil, fmt.Errorf("%w: cannot sample %d of %d questions", ErrQuestionIndex, n, len(qs.Questions))
+	}
+	picked := questionRand(seed).Perm(len(qs.Questions))[:n]
+	slices.Sort(picked)
+	c := qs.Clone()
+	questions := make([]Question, 0, n)
+	for _, i := range picked {
+		questions = append(questions, c.Questions[i])
+	}
+	c.Questions = questions
+	return c, nil
+}
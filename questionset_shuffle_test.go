@@ -0,0 +1,72 @@
+package h5p
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/grokify/h5p-go/schemas"
+)
+
+func numberedQuestionSet(n int) *QuestionSet {
+	qs := &QuestionSet{}
+	for i := range n {
+		qs.Questions = append(qs.Questions, Question{
+			Library:      "H5P.TrueFalse 1.8",
+			Params:       &schemas.TrueFalseParams{Question: string(rune('a' + i)), Correct: "true"},
+			SubContentID: NewSubContentID(),
+		})
+	}
+	return qs
+}
+
+func questionTexts(qs *QuestionSet) []string {
+	var texts []string
+	for _, q := range qs.Questions {
+		texts = append(texts, q.Params.(*schemas.TrueFalseParams).Question)
+	}
+	return texts
+}
+
+func TestQuestionSetShuffle(t *testing.T) {
+	qs := numberedQuestionSet(10)
+	original := questionTexts(qs)
+
+	a, b := qs.Shuffle(42), qs.Shuffle(42)
+	if !slices.Equal(questionTexts(a), questionTexts(b)) {
+		t.Errorf("Expected the same order for a seed, got %v and %v", questionTexts(a), questionTexts(b))
+	}
+	if slices.Equal(questionTexts(a), original) {
+		t.Errorf("Expected a different order, got %v", questionTexts(a))
+	}
+	if slices.Equal(questionTexts(a), questionTexts(qs.Shuffle(7))) {
+		t.Error("Expected different orders for different seeds")
+	}
+	sorted := slices.Sorted(slices.Values(questionTexts(a)))
+	if !slices.Equal(sorted, original) || !slices.Equal(questionTexts(qs), original) {
+		t.Errorf("Expected a permutation of the unchanged original, got %v", questionTexts(a))
+	}
+}
+
+func TestQuestionSetSample(t *testing.T) {
+	qs := numberedQuestionSet(10)
+	a, err := qs.Sample(4, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := qs.Sample(4, 1)
+	texts := questionTexts(a)
+	if len(texts) != 4 || !slices.Equal(texts, questionTexts(b)) || !slices.IsSorted(texts) {
+		t.Errorf("Expected 4 reproducible questions in original order, got %v and %v", texts, questionTexts(b))
+	}
+	if a.Questions[0].Params == qs.Questions[0].Params {
+		t.Error("Expected a deep copy")
+	}
+
+	if all, err := qs.Sample(10, 3); err != nil || len(all.Questions) != 10 {
+		t.Errorf("Expected all questions, got %v", err)
+	}
+	if _, err := qs.Sample(11, 1); !errors.Is(err, ErrQuestionIndex) {
+		t.Errorf("Expected an error sampling more questions than there are, got %v", err)
+	}
+}
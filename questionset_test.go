@@ -1,8 +1,12 @@
 package h5p
 
 import (
+	"errors"
 	"os"
+	"strings"
 	"testing"
+
+	"github.com/grokify/h5p-go/schemas"
 )
 
 func TestQuestionSetBuilder(t *testing.T) {
@@ -36,6 +40,98 @@ func TestQuestionSetBuilder(t *testing.T) {
 	}
 }
 
+func TestQuestionSetBuilderMixedTypes(t *testing.T) {
+	qs, err := NewQuestionSetBuilder().
+		AddMultipleChoiceQuestion("2 + 2?", []Answer{CreateAnswer("4", true), CreateAnswer("5", false)}).
+		AddTrueFalseQuestion("The earth is flat.", false, &schemas.TrueFalseBehaviour{EnableRetry: true}).
+		AddEssayQuestion("Describe the water cycle.", []schemas.EssayKeyword{{Keyword: "evaporation"}}, nil).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(qs.Questions) != 3 {
+		t.Fatalf("Expected 3 questions, got %d", len(qs.Questions))
+	}
+
+	tf := qs.Questions[1]
+	params, ok := tf.Params.(*schemas.TrueFalseParams)
+	if tf.Library != "H5P.TrueFalse 1.8" || !ok || params.IsTrue() || !params.Behaviour.EnableRetry {
+		t.Errorf("Unexpected TrueFalse question %+v", tf)
+	}
+	if err := params.Validate(); err != nil {
+		t.Errorf("Expected valid TrueFalse params, got %v", err)
+	}
+
+	essay := qs.Questions[2]
+	var ep schemas.EssayParams
+	if err := essay.DecodeParams(&ep); err != nil {
+		t.Fatal(err)
+	}
+	if essay.Library != "H5P.Essay 1.5" || ep.TaskDescription != "Describe the water cycle." || len(ep.Keywords) != 1 || ep.Behaviour != nil {
+		t.Errorf("Unexpected Essay question %+v", essay)
+	}
+	if essay.SubContentID == "" || essay.SubContentID == tf.SubContentID || essay.Metadata.ContentType != "Essay" {
+		t.Errorf("Expected distinct sub-content IDs and metadata, got %+v", essay)
+	}
+}
+
+// pollParams are typed params of a content type not in the registry.
+type pollParams struct {
+	Question string `json:"question"`
+}
+
+func (p *pollParams) MachineName() string { return "H5P.Poll" }
+
+func TestQuestionSetBuilderAddQuestion(t *testing.T) {
+	qs, err := NewQuestionSetBuilder().
+		AddQuestion(&schemas.BlanksParams{Questions: []string{"<p>2 + 2 = *4*</p>"}}).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := qs.Questions[0]
+	if q.Library != "H5P.Blanks 1.14" || q.SubContentID == "" {
+		t.Errorf("Unexpected question %+v", q)
+	}
+	if q.Metadata.Title != "Untitled Fill in the Blanks" || q.Metadata.ContentType != "Fill in the Blanks" {
+		t.Errorf("Unexpected metadata %+v", q.Metadata)
+	}
+
+	_, err = NewQuestionSetBuilder().
+		AddQuestion(&pollParams{Question: "Tea or coffee?"}).
+		AddTrueFalseQuestion("Tea is a drink.", true, nil).
+		Build()
+	if !errors.Is(err, ErrUnknownContentType) || !strings.Contains(err.Error(), "H5P.Poll") {
+		t.Errorf("Expected ErrUnknownContentType for H5P.Poll, got %v", err)
+	}
+}
+
+// checklistParams are typed params of a content type registered with its
+// own version.
+type checklistParams struct {
+	Items []string `json:"items"`
+}
+
+func (p *checklistParams) MachineName() string { return "H5P.Checklist" }
+
+func TestQuestionSetBuilderAddQuestionRegistered(t *testing.T) {
+	schemas.RegisterContentType(schemas.ContentType{
+		MachineName: "H5P.Checklist",
+		Title:       "Checklist",
+		Version:     "1.3",
+		New:         func() schemas.ContentTypeParams { return &checklistParams{} },
+	})
+	qs, err := NewQuestionSetBuilder().
+		AddQuestion(&checklistParams{Items: []string{"Tea"}}).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q := qs.Questions[0]; q.Library != "H5P.Checklist 1.3" || q.Metadata.ContentType != "Checklist" {
+		t.Errorf("Unexpected question %+v", q)
+	}
+}
+
 func TestQuestionSetValidation(t *testing.T) {
 	qs := &QuestionSet{
 		PassPercentage: 150,
@@ -172,3 +268,217 @@ func TestReadSampleQuestionSetJSON(t *testing.T) {
 	t.Logf("Successfully parsed question set with %d questions (%d single-answer, %d multi-answer)",
 		len(questionSet.Questions), singleAnswerCount, multiAnswerCount)
 }
+
+func TestQuestionSetValidateAll(t *testing.T) {
+	qs := &QuestionSet{
+		PassPercentage: 150,
+		Questions: []Question{
+			*NewMultiChoiceQuestion(&schemas.MultiChoiceParams{
+				Question: "Valid?",
+				Answers:  []schemas.AnswerOption{{Text: "Yes", Correct: true}},
+			}).ToQuestion(),
+			{Library: "H5P.MultiChoice 1.16", Params: map[string]any{
+				"question": "Generic params?",
+				"answers":  []any{map[string]any{"text": "", "correct": true}},
+			}},
+			{Params: map[string]any{}},
+		},
+		OverallFeedback: []FeedbackRange{{From: 0, To: 50}, {From: 80, To: 60}},
+	}
+
+	result := qs.ValidateAll()
+	want := map[string]string{
+		"passPercentage":                      schemas.CodeOutOfRange,
+		"overallFeedback[1]":                  schemas.CodeInvalidRange,
+		"questions[1].params.answers[0].text": schemas.CodeRequired,
+		"questions[2].library":                schemas.CodeRequired,
+	}
+	if len(result.Problems) != len(want) {
+		t.Errorf("Expected %d problems, got %v", len(want), result.Problems)
+	}
+	for _, p := range result.Problems {
+		if code, ok := want[p.Path]; !ok || code != p.Code || p.Severity != SeverityError {
+			t.Errorf("Unexpected problem %+v", p)
+		}
+	}
+
+	err := qs.Validate()
+	var vr *ValidationResult
+	if !errors.As(err, &vr) || len(vr.Errors()) != len(want) {
+		t.Errorf("Expected *ValidationResult error, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "overallFeedback[1]: feedback range 'from' (80) cannot be greater than 'to' (60)") {
+		t.Errorf("Unexpected error message: %v", err)
+	}
+}
+
+func TestQuestionSetPoolSize(t *testing.T) {
+	qs, err := NewQuestionSetBuilder().
+		SetRandomQuestions(true).
+		SetPoolSize(2).
+		AddTrueFalseQuestion("One?", true, nil).
+		AddTrueFalseQuestion("Two?", true, nil).
+		AddTrueFalseQuestion("Three?", false, nil).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := qs.ToJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"randomQuestions": true`) || !strings.Contains(string(data), `"poolSize": 2`) {
+		t.Errorf("Expected randomization settings in JSON, got %s", data)
+	}
+	if result := qs.ValidateAll(); len(result.Problems) != 0 {
+		t.Errorf("Expected no problems, got %v", result.Problems)
+	}
+
+	qs.PoolSize = 5
+	if w := qs.ValidateAll().Warnings(); len(w) != 1 || w[0].Path != "poolSize" {
+		t.Errorf("Expected a poolSize warning, got %v", w)
+	}
+	qs.PoolSize = -1
+	if errs := qs.ValidateAll().Errors(); len(errs) != 1 || errs[0].Code != schemas.CodeOutOfRange {
+		t.Errorf("Expected a poolSize error, got %v", errs)
+	}
+}
+
+func TestQuestionSetEndGame(t *testing.T) {
+	qs, err := NewQuestionSetBuilder().
+		SetTexts(&QuestionSetTexts{PrevButton: "Back", NextButton: "Next"}).
+		SetResultButtons(false, true).
+		SetResultButtonTexts("", "Try again", "", "Hand in").
+		SetResultFeedback([]schemas.FeedbackRange{{From: 0, To: 49, Feedback: "Keep going"}, {From: 90, To: 50}}).
+		SetSuccessMessage("Well done!", "You passed.").
+		SetFailVideo("videos/fail.mp4", "video/mp4").
+		AddTrueFalseQuestion("Done?", true, nil).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	eg := qs.EndGame
+	if !eg.ShowResultPage || eg.ShowSolutionButton || !eg.ShowRetryButton {
+		t.Errorf("Unexpected flags %+v", eg)
+	}
+	if eg.RetryButtonText != "Try again" || eg.SubmitButtonText != "Hand in" || eg.SolutionButtonText != "Show solution" {
+		t.Errorf("Unexpected button texts %+v", eg)
+	}
+	if eg.SuccessGreeting != "Well done!" || !eg.ShowAnimations || len(eg.FailVideo) != 1 || eg.FailVideo[0].Mime != "video/mp4" {
+		t.Errorf("Unexpected messages %+v", eg)
+	}
+
+	data, err := qs.ToJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"prevButton": "Back"`, `"showSolutionButton": false`, `"failVideo": [`, `"feedback": "Keep going"`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("Expected %s in JSON", want)
+		}
+	}
+	if _, err := FromJSONStrict(data); err != nil {
+		t.Errorf("Expected the endGame group modelled, got %v", err)
+	}
+
+	errs := qs.ValidateAll().Errors()
+	if len(errs) != 1 || errs[0].Path != "endGame.overallFeedback.overallFeedback[1]" {
+		t.Errorf("Expected the inverted range reported, got %v", errs)
+	}
+}
+
+func TestQuestionSetBuilderFrom(t *testing.T) {
+	original, err := NewQuestionSetBuilder().
+		SetTitle("Quiz").
+		AddTrueFalseQuestion("One?", true, nil).
+		AddTrueFalseQuestion("Two?", true, nil).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mc := *NewMultiChoiceQuestion(&schemas.MultiChoiceParams{
+		Question: "Three?",
+		Answers:  []schemas.AnswerOption{{Text: "Yes", Correct: true}},
+	}).ToQuestion()
+	edited, err := NewQuestionSetBuilderFrom(original).
+		SetTitle("Edited").
+		InsertQuestion(0, mc).
+		RemoveQuestion(1).
+		ReplaceQuestion(1, mc).
+		AddEssayQuestion("Why?", nil, nil).
+		BuildAndValidate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if edited.Title != "Edited" || len(edited.Questions) != 3 || edited.Questions[2].MachineName() != "H5P.Essay" {
+		t.Errorf("Unexpected edited set %+v", edited)
+	}
+	if original.Title != "Quiz" || len(original.Questions) != 2 || original.Questions[0].Params.(*schemas.TrueFalseParams).Question != "One?" {
+		t.Errorf("Expected the original unchanged, got %+v", original)
+	}
+
+	_, err = NewQuestionSetBuilderFrom(original).RemoveQuestion(2).Build()
+	if !errors.Is(err, ErrQuestionIndex) {
+		t.Errorf("Expected ErrQuestionIndex, got %v", err)
+	}
+	_, err = NewQuestionSetBuilderFrom(original).
+		ReplaceQuestion(0, Question{Library: "H5P.MultiChoice 1.16", Params: &schemas.MultiChoiceParams{}}).
+		BuildAndValidate()
+	var vr *ValidationResult
+	if !errors.As(err, &vr) {
+		t.Errorf("Expected a *ValidationResult, got %v", err)
+	}
+}
+
+func TestQuestionSetEditing(t *testing.T) {
+	tf := func(text string) Question {
+		return Question{Library: "H5P.TrueFalse 1.8", Params: &schemas.TrueFalseParams{Question: text, Correct: "true"}}
+	}
+	texts := func(qs *QuestionSet) string {
+		var s []string
+		for _, q := range qs.Questions {
+			s = append(s, q.Params.(*schemas.TrueFalseParams).Question)
+		}
+		return strings.Join(s, ",")
+	}
+	qs := &QuestionSet{}
+	for _, text := range []string{"a", "b", "c", "d"} {
+		if err := qs.InsertQuestion(len(qs.Questions), tf(text)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := qs.MoveQuestion(0, 2); err != nil || texts(qs) != "b,c,a,d" {
+		t.Errorf("Unexpected move result %s, %v", texts(qs), err)
+	}
+	if err := qs.MoveQuestion(3, 0); err != nil || texts(qs) != "d,b,c,a" {
+		t.Errorf("Unexpected move result %s, %v", texts(qs), err)
+	}
+	if err := qs.RemoveQuestion(1); err != nil || texts(qs) != "d,c,a" {
+		t.Errorf("Unexpected remove result %s, %v", texts(qs), err)
+	}
+	qs.FilterQuestions(func(q *Question) bool { return q.Params.(*schemas.TrueFalseParams).Question != "c" })
+	if texts(qs) != "d,a" {
+		t.Errorf("Unexpected filter result %s", texts(qs))
+	}
+	qs.MapQuestions(func(q Question) Question {
+		return tf(strings.ToUpper(q.Params.(*schemas.TrueFalseParams).Question))
+	})
+	if texts(qs) != "D,A" {
+		t.Errorf("Unexpected map result %s", texts(qs))
+	}
+
+	for name, err := range map[string]error{
+		"insert":  qs.InsertQuestion(3, tf("x")),
+		"replace": qs.ReplaceQuestion(2, tf("x")),
+		"remove":  qs.RemoveQuestion(-1),
+		"move":    qs.MoveQuestion(0, 2),
+	} {
+		if !errors.Is(err, ErrQuestionIndex) {
+			t.Errorf("Expected ErrQuestionIndex from %s, got %v", name, err)
+		}
+	}
+	if texts(qs) != "D,A" {
+		t.Errorf("Expected failed edits to leave the set unchanged, got %s", texts(qs))
+	}
+}
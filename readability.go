@@ -0,0 +1,97 @@
+package h5p
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// Readability measures how hard a text is to read with the Flesch reading
+// ease and the Flesch-Kincaid grade level, computed from its sentence,
+// word and syllable counts. Syllables are counted by English spelling
+// rules, so scores of texts in other languages are rough.
+type Readability struct {
+	Sentences int `json:"sentences"`
+	Words     int `json:"words"`
+	Syllables int `json:"syllables"`
+	// ReadingEase is the Flesch reading ease, from about 100 for very easy
+	// texts down to 0 and below for very hard ones.
+	ReadingEase float64 `json:"readingEase"`
+	// GradeLevel is the Flesch-Kincaid grade level, the US school grade
+	// whose students can understand the text.
+	GradeLevel float64 `json:"gradeLevel"`
+}
+
+// MeasureReadability counts the sentences, words and syllables of plain
+// text and scores it. Each line is at least one sentence, as texts from
+// ExtractText hold a field per line, such as an answer option without a
+// full stop.
+func MeasureReadability(text string) Readability {
+	var r Readability
+	for _, line := range strings.Split(text, "\n") {
+		words, sentences := 0, 0
+		ended := false
+		for _, w := range strings.Fields(line) {
+			if !strings.ContainsFunc(w, unicode.IsLetter) {
+				continue
+			}
+			words++
+			r.Syllables += syllables(w)
+			// Closing quotes and brackets may follow the full stop.
+			trimmed := strings.TrimRightFunc(w, unicode.IsPunct)
+			ended = strings.ContainsAny(w[len(trimmed):], ".!?")
+			if ended {
+				sentences++
+			}
+		}
+		if words > 0 && !ended {
+			sentences++
+		}
+		r.Words += words
+		r.Sentences += sentences
+	}
+	r.score()
+	return r
+}
+
+// Add adds the counts of o to r and scores the combined text.
+func (r *Readability) Add(o Readability) {
+	r.Sentences += o.Sentences
+	r.Words += o.Words
+	r.Syllables += o.Syllables
+	r.score()
+}
+
+func (r *Readability) score() {
+	if r.Words == 0 || r.Sentences == 0 {
+		r.ReadingEase, r.GradeLevel = 0, 0
+		return
+	}
+	wordsPerSentence := float64(r.Words) / float64(r.Sentences)
+	syllablesPerWord := float64(r.Syllables) / float64(r.Words)
+	r.ReadingEase = round2(206.835 - 1.015*wordsPerSentence - 84.6*syllablesPerWord)
+	r.GradeLevel = round2(0.39*wordsPerSentence + 11.8*syllablesPerWord - 15.59)
+}
+
+func round2(f float64) float64 {
+	return math.Round(f*100) / 100
+}
+
+// syllables estimates the syllables of an English word as its groups of
+// vowels, not counting a silent final "e".
+func syllables(word string) int {
+	word = strings.ToLower(strings.TrimFunc(word, func(r rune) bool { return !unicode.IsLetter(r) }))
+	n := 0
+	vowel := false
+	for _, c := range word {
+		isVowel := strings.ContainsRune("aeiouyàáâäèéêëìíîïòóôöùúûü", c)
+		if isVowel && !vowel {
+			n++
+		}
+		vowel = isVowel
+	}
+	if n > 1 && strings.HasSuffix(word, "e") && !strings.HasSuffix(word, "le") && !strings.HasSuffix(word, "ee") {
+		n--
+	}
+	return max(n, 1)
+}
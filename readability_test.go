@@ -0,0 +1,32 @@
+package h5p
+
+import "testing"
+
+func TestMeasureReadability(t *testing.T) {
+	r := MeasureReadability("The cat sat on the mat. It was happy!\nParis")
+	if r.Sentences != 3 || r.Words != 10 || r.Syllables != 12 {
+		t.Errorf("Unexpected counts %+v", r)
+	}
+	if r.ReadingEase < 90 || r.GradeLevel > 2 {
+		t.Errorf("Expected an easy text, got %+v", r)
+	}
+
+	hard := MeasureReadability("Photosynthesis converts electromagnetic radiation into chemical energy, sustaining practically every terrestrial ecosystem.")
+	if hard.Sentences != 1 || hard.GradeLevel < 15 || hard.ReadingEase > 20 {
+		t.Errorf("Expected a hard text, got %+v", hard)
+	}
+
+	r.Add(hard)
+	if r.Sentences != 4 || r.Words != 22 || r.GradeLevel <= 2 {
+		t.Errorf("Unexpected combined readability %+v", r)
+	}
+	if z := MeasureReadability(" \n 42 "); z.Words != 0 || z.GradeLevel != 0 {
+		t.Errorf("Expected no words, got %+v", z)
+	}
+
+	for word, want := range map[string]int{"cat": 1, "table": 2, "make": 1, "agree": 2, "beautiful": 3, "rhythm": 1, "Paris,": 2} {
+		if got := syllables(word); got != want {
+			t.Errorf("syllables(%q) = %d, want %d", word, got, want)
+		}
+	}
+}
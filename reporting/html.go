@@ -0,0 +1,80 @@
+package reporting
+
+import (
+	"html/template"
+	"io"
+	"strings"
+)
+
+// Stylesheet styles the HTML of WriteHTML; include it in the page or
+// replace it with rules of your own for the same classes.
+const Stylesheet = `.h5p-report .h5p-reporting-score { font-weight: bold; }
+.h5p-report .h5p-choices-table { border-collapse: collapse; }
+.h5p-report .h5p-choices-table td, .h5p-report .h5p-choices-table th { border: 1px solid #ddd; padding: 0.25em 0.5em; }
+.h5p-report .h5p-response-correct { color: #1a7f37; }
+.h5p-report .h5p-response-wrong { color: #c62828; text-decoration: line-through; }
+.h5p-report .h5p-correct-response { font-weight: bold; }
+.h5p-report .h5p-word-missed { font-weight: bold; text-decoration: underline; }
+`
+
+var funcs = template.FuncMap{
+	"join": strings.Join,
+}
+
+var reportTemplate = template.Must(template.New("report").Funcs(funcs).Parse(`<div class="h5p-report">
+{{- if .Title}}
+<h2>{{.Title}}</h2>
+{{- end}}
+<p class="h5p-reporting-score">{{.Score}} / {{.MaxScore}} ({{.Percentage}}%) {{if .Passed}}passed{{else}}failed{{end}}</p>
+{{- if .Feedback}}
+<p class="h5p-reporting-feedback">{{.Feedback}}</p>
+{{- end}}
+{{- range .Questions}}
+<div class="h5p-reporting-question h5p-reporting-{{.Type}}">
+{{- if .Title}}
+<h3>{{.Title}}</h3>
+{{- end}}
+{{- if .Question}}
+<p class="h5p-reporting-description">{{.Question}}</p>
+{{- end}}
+{{- if .Graded}}
+<p class="h5p-reporting-score">{{.Score}} / {{.MaxScore}}{{if not .Answered}} (not answered){{end}}</p>
+{{- end}}
+{{- if .Choices}}
+<table class="h5p-choices-table">
+<tr><th>Answer</th><th>Your answer</th><th>Correct</th></tr>
+{{- range .Choices}}
+<tr><td{{if and .Selected .Correct}} class="h5p-response-correct"{{else if .Selected}} class="h5p-response-wrong"{{end}}>{{.Text}}</td><td>{{if .Selected}}&#10003;{{end}}</td><td>{{if .Correct}}&#10003;{{end}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- if .Segments}}
+<p class="h5p-reporting-text">
+{{- range .Segments}}
+{{- if eq .Kind "break"}}<br>
+{{- else if eq .Kind "gap"}}
+{{- if .Correct}}<span class="h5p-response-correct">{{.Text}}</span>
+{{- else}}{{if .Text}}<span class="h5p-response-wrong">{{.Text}}</span> {{end}}<span class="h5p-correct-response">{{join .Expected " / "}}</span>
+{{- end}}
+{{- else if eq .Kind "word"}}
+{{- if .Correct}}<span class="h5p-response-correct h5p-correct-response">{{.Text}}</span>
+{{- else if .Selected}}<span class="h5p-response-wrong">{{.Text}}</span>
+{{- else if .Solution}}<span class="h5p-word-missed">{{.Text}}</span>
+{{- else}}{{.Text}}
+{{- end}}{{" "}}
+{{- else}}{{.Text}}
+{{- end}}
+{{- end}}
+</p>
+{{- end}}
+</div>
+{{- end}}
+</div>
+`))
+
+// WriteHTML writes the report as an HTML fragment for an LMS page, styled
+// by Stylesheet. Wrong responses are crossed out, correct answers shown in
+// bold, and words that should have been marked underlined.
+func (r *Report) WriteHTML(w io.Writer) error {
+	return reportTemplate.Execute(w, r)
+}
@@ -0,0 +1,214 @@
+// Package reporting turns graded question set attempts into reports for LMS
+// pages: for each question, the answer given next to the correct answer, in
+// the layout of the H5P reporting module. Wrong responses are crossed out
+// and correct answers shown in bold, inline in the text of Blanks, DragText
+// and MarkTheWords questions. Reports are plain Go data, and WriteHTML
+// renders them.
+package reporting
+
+import (
+	"html"
+	"regexp"
+	"strings"
+
+	h5p "github.com/grokify/h5p-go"
+	"github.com/grokify/h5p-go/grading"
+	"github.com/grokify/h5p-go/schemas"
+)
+
+// Interaction types of questions, as named by xAPI and the H5P reporting
+// module.
+const (
+	TypeChoice     = "choice"
+	TypeTrueFalse  = "true-false"
+	TypeFillIn     = "fill-in"
+	TypeMatching   = "matching"
+	TypeLongChoice = "long-choice"
+	TypeOther      = "other"
+)
+
+var (
+	htmlTag = regexp.MustCompile(`<[^>]*>`)
+	// gapMarker matches the *answer* markers of Blanks and DragText.
+	gapMarker = regexp.MustCompile(`\*[^*]+\*`)
+)
+
+// Report is a graded attempt at a question set.
+type Report struct {
+	Title      string           `json:"title,omitempty"`
+	Score      int              `json:"score"`
+	MaxScore   int              `json:"maxScore"`
+	Percentage int              `json:"percentage"`
+	Passed     bool             `json:"passed"`
+	Feedback   string           `json:"feedback,omitempty"`
+	Questions  []QuestionReport `json:"questions"`
+}
+
+// QuestionReport is the answer to a question. Choice and true-false
+// questions list their Choices; the others hold their text as Segments
+// with the responses inline.
+type QuestionReport struct {
+	Title string `json:"title,omitempty"`
+	// Question is the question or task description as plain text.
+	Question string    `json:"question,omitempty"`
+	Type     string    `json:"type"`
+	Score    int       `json:"score"`
+	MaxScore int       `json:"maxScore"`
+	Answered bool      `json:"answered"`
+	Graded   bool      `json:"graded"`
+	Choices  []Choice  `json:"choices,omitempty"`
+	Segments []Segment `json:"segments,omitempty"`
+}
+
+// Choice is an answer option.
+type Choice struct {
+	Text     string `json:"text"`
+	Selected bool   `json:"selected"`
+	Correct  bool   `json:"correct"`
+}
+
+// Segment kinds.
+const (
+	SegmentText  = "text"
+	SegmentBreak = "break"
+	SegmentGap   = "gap"
+	SegmentWord  = "word"
+)
+
+// Segment is a part of the text of a fill-in, matching or long-choice
+// question: literal text, a line break, a gap or drop zone with the
+// response given, or a word that can be marked.
+type Segment struct {
+	Kind string `json:"kind"`
+	// Text is the literal text, the response given in a gap or the word.
+	Text string `json:"text,omitempty"`
+	// Expected lists the answers accepted for a gap.
+	Expected []string `json:"expected,omitempty"`
+	// Selected and Solution report whether a word was marked and is one
+	// to mark.
+	Selected bool `json:"selected,omitempty"`
+	Solution bool `json:"solution,omitempty"`
+	// Correct reports whether a gap holds an accepted answer or a word was
+	// rightly marked.
+	Correct bool `json:"correct,omitempty"`
+}
+
+// NewReport builds the report of an attempt at qs graded by
+// QuestionSet.Score.
+func NewReport(qs *h5p.QuestionSet, score *h5p.QuestionSetScore) *Report {
+	r := &Report{
+		Title:      qs.Title,
+		Score:      score.Score,
+		MaxScore:   score.MaxScore,
+		Percentage: score.Percentage,
+		Passed:     score.Passed,
+		Feedback:   score.Feedback,
+	}
+	for i := range qs.Questions {
+		if i >= len(score.Questions) {
+			break
+		}
+		r.Questions = append(r.Questions, questionReport(&qs.Questions[i], &score.Questions[i]))
+	}
+	return r
+}
+
+func questionReport(q *h5p.Question, qsc *h5p.QuestionScore) QuestionReport {
+	qr := QuestionReport{Type: TypeOther, Answered: qsc.Answered, Graded: qsc.Graded}
+	if q.Metadata != nil {
+		qr.Title = q.Metadata.Title
+	}
+	result := qsc.Result
+	if result == nil {
+		result = &grading.Result{}
+	}
+	qr.Score, qr.MaxScore = result.Score, result.MaxScore
+
+	switch q.MachineName() {
+	case "H5P.MultiChoice":
+		var p schemas.MultiChoiceParams
+		if q.DecodeParams(&p) == nil {
+			qr.Type, qr.Question = TypeChoice, strings.TrimSpace(plain(p.Question))
+			for _, it := range result.Items {
+				qr.Choices = append(qr.Choices, Choice{Text: it.Text, Selected: it.Selected, Correct: it.Solution})
+			}
+		}
+	case "H5P.TrueFalse":
+		var p schemas.TrueFalseParams
+		if q.DecodeParams(&p) == nil {
+			qr.Type, qr.Question = TypeTrueFalse, strings.TrimSpace(plain(p.Question))
+			given := ""
+			if len(result.Items) > 0 {
+				given = result.Items[0].Text
+			}
+			for _, c := range []Choice{{Text: "True", Correct: p.IsTrue()}, {Text: "False", Correct: !p.IsTrue()}} {
+				c.Selected = given == strings.ToLower(c.Text)
+				qr.Choices = append(qr.Choices, c)
+			}
+		}
+	case "H5P.Blanks":
+		var p schemas.BlanksParams
+		if q.DecodeParams(&p) == nil {
+			qr.Type, qr.Question = TypeFillIn, strings.TrimSpace(plain(p.Text))
+			qr.Segments = gapSegments(p.Questions, result.Items)
+		}
+	case "H5P.DragText":
+		var p schemas.DragTextParams
+		if q.DecodeParams(&p) == nil {
+			qr.Type, qr.Question = TypeMatching, strings.TrimSpace(plain(p.TaskDescription))
+			qr.Segments = gapSegments(strings.Split(p.TextField, "\n"), result.Items)
+		}
+	case "H5P.MarkTheWords":
+		var p schemas.MarkTheWordsParams
+		if q.DecodeParams(&p) == nil {
+			qr.Type, qr.Question = TypeLongChoice, strings.TrimSpace(plain(p.TaskDescription))
+			for i, w := range schemas.ParseMarkableWords(p.TextField) {
+				seg := Segment{Kind: SegmentWord, Text: w.Text, Solution: w.Correct}
+				if i < len(result.Items) {
+					seg.Selected, seg.Correct = result.Items[i].Selected, result.Items[i].Correct
+				}
+				qr.Segments = append(qr.Segments, seg)
+			}
+		}
+	}
+	return qr
+}
+
+// gapSegments splits texts into literal text and gaps, filling the gaps
+// with the graded items in order. Texts are separated by line breaks.
+func gapSegments(texts []string, items []grading.Item) []Segment {
+	var segs []Segment
+	n := 0
+	for i, text := range texts {
+		if i > 0 {
+			segs = append(segs, Segment{Kind: SegmentBreak})
+		}
+		last := 0
+		for _, m := range gapMarker.FindAllStringIndex(text, -1) {
+			if s := plain(text[last:m[0]]); s != "" {
+				segs = append(segs, Segment{Kind: SegmentText, Text: s})
+			}
+			seg := Segment{Kind: SegmentGap}
+			if n < len(items) {
+				seg.Text, seg.Expected, seg.Correct = items[n].Text, items[n].Expected, items[n].Correct
+			}
+			segs = append(segs, seg)
+			n++
+			last = m[1]
+		}
+		if s := plain(text[last:]); s != "" {
+			segs = append(segs, Segment{Kind: SegmentText, Text: s})
+		}
+	}
+	return segs
+}
+
+// plain returns the text of HTML, keeping the spaces around it so literal
+// text joins up with the gaps.
+func plain(s string) string {
+	s = html.UnescapeString(htmlTag.ReplaceAllString(s, ""))
+	if strings.TrimSpace(s) == "" {
+		return ""
+	}
+	return s
+}
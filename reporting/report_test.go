@@ -0,0 +1,99 @@
+package reporting
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	h5p "github.com/grokify/h5p-go"
+	"github.com/grokify/h5p-go/grading"
+	"github.com/grokify/h5p-go/schemas"
+)
+
+func testAttempt(t *testing.T) *Report {
+	t.Helper()
+	qs := &h5p.QuestionSet{
+		Title: "Geography",
+		Questions: []h5p.Question{
+			{Library: "H5P.MultiChoice 1.16", SubContentID: "mc", Metadata: &h5p.ContentMetadata{Title: "Capital"}, Params: &schemas.MultiChoiceParams{
+				Question: "<p>Capital of France?</p>",
+				Answers:  []schemas.AnswerOption{{Text: "Paris", Correct: true}, {Text: "Lyon"}},
+			}},
+			{Library: "H5P.TrueFalse 1.8", SubContentID: "tf", Params: map[string]any{"question": "The Seine flows through Paris.", "correct": "true"}},
+			{Library: "H5P.Blanks 1.14", SubContentID: "bl", Params: map[string]any{
+				"text":      "Fill in",
+				"questions": []any{"<p>*Paris* is in *France*.</p>"},
+			}},
+			{Library: "H5P.MarkTheWords 1.11", SubContentID: "mw", Params: map[string]any{"textField": "<p>The *Loire* and the *Rhône* are rivers.</p>"}},
+		},
+	}
+	no := false
+	score, err := qs.Score(map[string]grading.Response{
+		"mc": {Selected: []int{1}},
+		"tf": {Answer: &no},
+		"bl": {Texts: []string{"Paris", "Spain"}},
+		"mw": {Selected: []int{1, 0}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewReport(qs, score)
+}
+
+func TestNewReport(t *testing.T) {
+	r := testAttempt(t)
+	if r.Title != "Geography" || r.Score != 1 || r.MaxScore != 6 || r.Passed || len(r.Questions) != 4 {
+		t.Fatalf("Unexpected report %+v", r)
+	}
+
+	mc := r.Questions[0]
+	if mc.Type != TypeChoice || mc.Title != "Capital" || mc.Question != "Capital of France?" {
+		t.Errorf("Unexpected choice question %+v", mc)
+	}
+	if len(mc.Choices) != 2 || !mc.Choices[0].Correct || mc.Choices[0].Selected || !mc.Choices[1].Selected {
+		t.Errorf("Unexpected choices %+v", mc.Choices)
+	}
+	tf := r.Questions[1]
+	if tf.Type != TypeTrueFalse || tf.Choices[0] != (Choice{Text: "True", Correct: true}) || tf.Choices[1] != (Choice{Text: "False", Selected: true}) {
+		t.Errorf("Unexpected true/false question %+v", tf)
+	}
+
+	bl := r.Questions[2]
+	want := []Segment{
+		{Kind: SegmentGap, Text: "Paris", Expected: []string{"Paris"}, Correct: true},
+		{Kind: SegmentText, Text: " is in "},
+		{Kind: SegmentGap, Text: "Spain", Expected: []string{"France"}},
+		{Kind: SegmentText, Text: "."},
+	}
+	if bl.Type != TypeFillIn || bl.Score != 1 || len(bl.Segments) != len(want) {
+		t.Fatalf("Unexpected fill-in question %+v", bl)
+	}
+	for i := range want {
+		if got := bl.Segments[i]; got.Kind != want[i].Kind || got.Text != want[i].Text || got.Correct != want[i].Correct || strings.Join(got.Expected, "/") != strings.Join(want[i].Expected, "/") {
+			t.Errorf("Segment %d: expected %+v, got %+v", i, want[i], got)
+		}
+	}
+
+	mw := r.Questions[3]
+	if mw.Type != TypeLongChoice || len(mw.Segments) != 7 || !mw.Segments[1].Correct || !mw.Segments[0].Selected || mw.Segments[4].Selected || !mw.Segments[4].Solution {
+		t.Errorf("Unexpected long-choice question %+v", mw)
+	}
+}
+
+func TestWriteHTML(t *testing.T) {
+	var buf bytes.Buffer
+	if err := testAttempt(t).WriteHTML(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		`<p class="h5p-reporting-score">1 / 6 (17%) failed</p>`,
+		`<td class="h5p-response-wrong">Lyon</td>`,
+		`<span class="h5p-response-correct">Paris</span> is in <span class="h5p-response-wrong">Spain</span> <span class="h5p-correct-response">France</span>.`,
+		`<span class="h5p-response-wrong">The</span> <span class="h5p-response-correct h5p-correct-response">Loire</span> and the <span class="h5p-word-missed">Rhône</span> are rivers. `,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %s in:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,404 @@
+package h5p
+
+import (
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/grokify/h5p-go/schemas"
+)
+
+// DefaultAllowedTags maps the tags H5P text fields accept to the attributes
+// kept on them.
+var DefaultAllowedTags = map[string][]string{
+	"a": {"href", "target"}, "blockquote": nil, "br": nil, "code": nil, "del": nil,
+	"em": nil, "h2": nil, "h3": nil, "h4": nil, "hr": nil, "li": nil, "ol": nil,
+	"p": {"style"}, "pre": nil, "s": nil, "span": {"style"}, "strong": nil,
+	"sub": nil, "sup": nil, "u": nil, "ul": nil,
+}
+
+// droppedContentTags are removed together with everything inside them.
+var droppedContentTags = map[string]bool{
+	"script": true, "style": true, "iframe": true, "object": true, "embed": true,
+	"noscript": true, "template": true, "textarea": true, "title": true, "svg": true, "math": true,
+}
+
+var voidTags = map[string]bool{"br": true, "hr": true}
+
+var (
+	safeStyleProperties = map[string]bool{"color": true, "background-color": true, "text-align": true, "font-size": true}
+	safeStyleValue      = regexp.MustCompile(`^(#[0-9a-fA-F]{3,8}|rgba?\([\d\s.,%]+\)|[\w\s.,%-]+)$`)
+	tagNamePattern      = regexp.MustCompile(`^/?([a-zA-Z][a-zA-Z0-9]*)`)
+	attrPattern         = regexp.MustCompile(`([a-zA-Z_:][-a-zA-Z0-9_:.]*)(?:\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'=<>` + "`" + `]+)))?`)
+)
+
+// HTMLSanitizer strips tags and attributes outside an allowlist from HTML
+// text fields, protecting against script injection from untrusted content.
+type HTMLSanitizer struct {
+	// AllowedTags maps lower-case tag names to the attributes kept on them.
+	AllowedTags map[string][]string
+}
+
+// NewHTMLSanitizer returns a sanitizer allowing DefaultAllowedTags.
+func NewHTMLSanitizer() *HTMLSanitizer {
+	return &HTMLSanitizer{AllowedTags: DefaultAllowedTags}
+}
+
+// SanitizeHTML sanitizes s with the default allowlist.
+func SanitizeHTML(s string) string {
+	return NewHTMLSanitizer().Sanitize(s)
+}
+
+// Sanitize returns s with disallowed tags removed, keeping their text, and
+// script-like elements removed with their contents. Comments are dropped,
+// disallowed or unsafe attributes are removed and stray angle brackets are
+// escaped.
+func (hs *HTMLSanitizer) Sanitize(s string) string {
+	var b strings.Builder
+	var open []string
+	dropUntil := ""
+
+	for len(s) > 0 {
+		lt := strings.IndexByte(s, '<')
+		if lt < 0 {
+			if dropUntil == "" {
+				b.WriteString(escapeText(s))
+			}
+			break
+		}
+		if dropUntil == "" {
+			b.WriteString(escapeText(s[:lt]))
+		}
+		s = s[lt:]
+
+		if strings.HasPrefix(s, "<!--") {
+			end := strings.Index(s, "-->")
+			if end < 0 {
+				break
+			}
+			s = s[end+3:]
+			continue
+		}
+
+		end := tagEnd(s)
+		m := tagNamePattern.FindStringSubmatch(s[1:])
+		if end < 0 || m == nil {
+			if dropUntil == "" {
+				b.WriteString("&lt;")
+			}
+			s = s[1:]
+			continue
+		}
+		tag, rest := s[:end+1], s[end+1:]
+		s = rest
+		name := strings.ToLower(m[1])
+		closing := strings.HasPrefix(m[0], "/")
+
+		if dropUntil != "" {
+			if closing && name == dropUntil {
+				dropUntil = ""
+			}
+			continue
+		}
+		if droppedContentTags[name] {
+			if !closing && !strings.HasSuffix(tag, "/>") {
+				dropUntil = name
+			}
+			continue
+		}
+		attrs, ok := hs.AllowedTags[name]
+		if !ok {
+			continue
+		}
+
+		if closing {
+			// Close the matching open tag and any left open inside it.
+			for i := len(open) - 1; i >= 0; i-- {
+				if open[i] == name {
+					b.WriteString(closeTags(open[i:]))
+					open = open[:i]
+					break
+				}
+			}
+			continue
+		}
+		b.WriteString("<" + name + sanitizeAttrs(name, tag[len(m[0])+1:len(tag)-1], attrs) + ">")
+		if !voidTags[name] {
+			open = append(open, name)
+		}
+	}
+
+	b.WriteString(closeTags(open))
+	return b.String()
+}
+
+// tagEnd returns the index of the '>' closing the tag at the start of s,
+// skipping quoted attribute values, or -1.
+func tagEnd(s string) int {
+	var quote byte
+	for i := 1; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '>':
+			return i
+		case c == '<':
+			return -1
+		}
+	}
+	return -1
+}
+
+// closeTags returns closing tags for open, innermost first.
+func closeTags(open []string) string {
+	var b strings.Builder
+	for i := len(open) - 1; i >= 0; i-- {
+		b.WriteString("</" + open[i] + ">")
+	}
+	return b.String()
+}
+
+func sanitizeAttrs(tag, raw string, allowed []string) string {
+	if len(allowed) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	for _, m := range attrPattern.FindAllStringSubmatch(raw, -1) {
+		name := strings.ToLower(m[1])
+		if !containsString(allowed, name) {
+			continue
+		}
+		value := html.UnescapeString(m[2] + m[3] + m[4])
+		switch name {
+		case "href":
+			if !safeURL(value) {
+				continue
+			}
+		case "style":
+			if value = sanitizeStyle(value); value == "" {
+				continue
+			}
+		case "target":
+			if value != "_blank" && value != "_self" {
+				continue
+			}
+		}
+		b.WriteString(" " + name + `="` + html.EscapeString(value) + `"`)
+		if tag == "a" && name == "target" && value == "_blank" {
+			b.WriteString(` rel="noopener noreferrer"`)
+		}
+	}
+	return b.String()
+}
+
+func safeURL(s string) bool {
+	u, err := url.Parse(strings.TrimSpace(s))
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "", "http", "https", "mailto":
+		return true
+	}
+	return false
+}
+
+func sanitizeStyle(style string) string {
+	var kept []string
+	for _, decl := range strings.Split(style, ";") {
+		prop, value, ok := strings.Cut(decl, ":")
+		prop, value = strings.ToLower(strings.TrimSpace(prop)), strings.TrimSpace(value)
+		if ok && safeStyleProperties[prop] && safeStyleValue.MatchString(value) {
+			kept = append(kept, prop+": "+value)
+		}
+	}
+	return strings.Join(kept, "; ")
+}
+
+// escapeText escapes angle brackets in text while keeping entities intact.
+func escapeText(s string) string {
+	return strings.NewReplacer("<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// htmlFieldNames are the params keys holding HTML text in generic question
+// params, as used by H5P question content types.
+var htmlFieldNames = map[string]bool{
+	"question": true, "text": true, "tip": true, "feedback": true,
+	"chosenFeedback": true, "notChosenFeedback": true, "introduction": true,
+	"description": true, "taskDescription": true,
+}
+
+// SanitizeHTML sanitizes the introduction, result message, overall feedback
+// and the question, answer and feedback text of every question in place.
+// Generic params are sanitized by key, see htmlFieldNames, as are typed
+// params of other content types after converting them to generic JSON.
+func (qs *QuestionSet) SanitizeHTML(hs *HTMLSanitizer) {
+	qs.Introduction = hs.Sanitize(qs.Introduction)
+	qs.Message = hs.Sanitize(qs.Message)
+	for i := range qs.OverallFeedback {
+		qs.OverallFeedback[i].Text = hs.Sanitize(qs.OverallFeedback[i].Text)
+	}
+	for i := range qs.Questions {
+		q := &qs.Questions[i]
+		switch params := q.Params.(type) {
+		case *schemas.MultiChoiceParams:
+			sanitizeMultiChoice(hs, params)
+		case *schemas.TrueFalseParams:
+			sanitizeTrueFalse(hs, params)
+		case *schemas.BlanksParams:
+			sanitizeBlanks(hs, params)
+		case *schemas.DragTextParams:
+			sanitizeDragText(hs, params)
+		case *schemas.MarkTheWordsParams:
+			sanitizeMarkTheWords(hs, params)
+		case *schemas.EssayParams:
+			sanitizeEssay(hs, params)
+		case map[string]any, []any, nil:
+			q.Params = sanitizeParams(hs, params)
+		default:
+			q.Params = sanitizeTypedParams(hs, params)
+		}
+	}
+}
+
+// SanitizeHTML sanitizes the HTML text fields of the package question set
+// in place, e.g. after importing content from an untrusted source.
+func (pkg *H5PPackage) SanitizeHTML(hs *HTMLSanitizer) {
+	if pkg.Content != nil && pkg.Content.QuestionSet != nil {
+		pkg.Content.QuestionSet.SanitizeHTML(hs)
+	}
+}
+
+func sanitizeMultiChoice(hs *HTMLSanitizer, p *schemas.MultiChoiceParams) {
+	if p == nil {
+		return
+	}
+	p.Question = hs.Sanitize(p.Question)
+	for i := range p.Answers {
+		a := &p.Answers[i]
+		a.Text = hs.Sanitize(a.Text)
+		if tf := a.TipsAndFeedback; tf != nil {
+			tf.Tip = hs.Sanitize(tf.Tip)
+			tf.ChosenFeedback = hs.Sanitize(tf.ChosenFeedback)
+			tf.NotChosenFeedback = hs.Sanitize(tf.NotChosenFeedback)
+		}
+	}
+	if p.OverallFeedback != nil {
+		for i := range p.OverallFeedback.OverallFeedback {
+			fr := &p.OverallFeedback.OverallFeedback[i]
+			fr.Feedback = hs.Sanitize(fr.Feedback)
+		}
+	}
+}
+
+func sanitizeTrueFalse(hs *HTMLSanitizer, p *schemas.TrueFalseParams) {
+	if p == nil {
+		return
+	}
+	p.Question = hs.Sanitize(p.Question)
+	if b := p.Behaviour; b != nil {
+		b.FeedbackOnCorrect = hs.Sanitize(b.FeedbackOnCorrect)
+		b.FeedbackOnWrong = hs.Sanitize(b.FeedbackOnWrong)
+	}
+}
+
+func sanitizeBlanks(hs *HTMLSanitizer, p *schemas.BlanksParams) {
+	if p == nil {
+		return
+	}
+	p.Text = hs.Sanitize(p.Text)
+	for i := range p.Questions {
+		p.Questions[i] = hs.Sanitize(p.Questions[i])
+	}
+	sanitizeOverallFeedback(hs, p.OverallFeedback)
+}
+
+func sanitizeDragText(hs *HTMLSanitizer, p *schemas.DragTextParams) {
+	if p == nil {
+		return
+	}
+	p.TaskDescription = hs.Sanitize(p.TaskDescription)
+	p.TextField = hs.Sanitize(p.TextField)
+	sanitizeOverallFeedback(hs, p.OverallFeedback)
+}
+
+func sanitizeMarkTheWords(hs *HTMLSanitizer, p *schemas.MarkTheWordsParams) {
+	if p == nil {
+		return
+	}
+	p.TaskDescription = hs.Sanitize(p.TaskDescription)
+	p.TextField = hs.Sanitize(p.TextField)
+	sanitizeOverallFeedback(hs, p.OverallFeedback)
+}
+
+func sanitizeEssay(hs *HTMLSanitizer, p *schemas.EssayParams) {
+	if p == nil {
+		return
+	}
+	p.TaskDescription = hs.Sanitize(p.TaskDescription)
+	if sol := p.Solution; sol != nil {
+		sol.Introduction = hs.Sanitize(sol.Introduction)
+		sol.Sample = hs.Sanitize(sol.Sample)
+	}
+	for i := range p.Keywords {
+		if o := p.Keywords[i].Options; o != nil {
+			o.FeedbackIncluded = hs.Sanitize(o.FeedbackIncluded)
+			o.FeedbackMissed = hs.Sanitize(o.FeedbackMissed)
+		}
+	}
+	sanitizeOverallFeedback(hs, p.OverallFeedback)
+}
+
+func sanitizeOverallFeedback(hs *HTMLSanitizer, of *schemas.OverallFeedback) {
+	if of == nil {
+		return
+	}
+	for i := range of.OverallFeedback {
+		of.OverallFeedback[i].Feedback = hs.Sanitize(of.OverallFeedback[i].Feedback)
+	}
+}
+
+// sanitizeTypedParams sanitizes typed params without a case of their own
+// as generic JSON, decoding the result back into their type. Params that
+// do not survive the round trip are returned in their sanitized generic
+// form, so they are never left unsanitized.
+func sanitizeTypedParams(hs *HTMLSanitizer, params any) any {
+	generic, err := toGenericJSON(params)
+	if err != nil {
+		// Params that cannot be encoded cannot be written either.
+		return params
+	}
+	return retypeParams(sanitizeParams(hs, generic), params)
+}
+
+func sanitizeParams(hs *HTMLSanitizer, v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, child := range t {
+			if s, ok := child.(string); ok && htmlFieldNames[k] {
+				t[k] = hs.Sanitize(s)
+			} else {
+				t[k] = sanitizeParams(hs, child)
+			}
+		}
+	case []any:
+		for i, child := range t {
+			t[i] = sanitizeParams(hs, child)
+		}
+	}
+	return v
+}
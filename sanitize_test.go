@@ -0,0 +1,114 @@
+package h5p
+
+import (
+	"testing"
+
+	"github.com/grokify/h5p-go/schemas"
+)
+
+func TestSanitizeHTML(t *testing.T) {
+	var sanitizeTests = []struct {
+		in   string
+		want string
+	}{
+		{"<p>Plain <strong>bold</strong> and <em>em</em></p>", "<p>Plain <strong>bold</strong> and <em>em</em></p>"},
+		{"H<sub>2</sub>O<br/>E=mc<sup>2</sup>", "H<sub>2</sub>O<br>E=mc<sup>2</sup>"},
+		{`<p onclick="alert(1)">Hi</p>`, "<p>Hi</p>"},
+		{"Hi<script>alert('x')</script> there", "Hi there"},
+		{"<div><b>kept text</b></div>", "kept text"},
+		{"<!-- comment -->visible", "visible"},
+		{`<a href="javascript:alert(1)">x</a>`, "<a>x</a>"},
+		{`<a href="https://h5p.org" target="_blank">h5p</a>`, `<a href="https://h5p.org" target="_blank" rel="noopener noreferrer">h5p</a>`},
+		{`<span style="color: red; background: url(x)">c</span>`, `<span style="color: red">c</span>`},
+		{`<span style="color: expression(alert(1))">c</span>`, `<span>c</span>`},
+		{"<p>unclosed <em>tags", "<p>unclosed <em>tags</em></p>"},
+		{"</em>stray close", "stray close"},
+		{"1 < 2 &amp; 3 > 2", "1 &lt; 2 &amp; 3 &gt; 2"},
+		{`<img src=x onerror="alert(1)">`, ""},
+		{`<p title="a>b">quoted</p>`, "<p>quoted</p>"},
+	}
+
+	for _, tt := range sanitizeTests {
+		if got := SanitizeHTML(tt.in); got != tt.want {
+			t.Errorf("SanitizeHTML(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestQuestionSetSanitizeHTML(t *testing.T) {
+	qs := &QuestionSet{
+		Introduction:    "<p>Intro<script>x()</script></p>",
+		OverallFeedback: []FeedbackRange{{From: 0, To: 100, Text: `<em onmouseover="x()">Done</em>`}},
+		Questions: []Question{
+			*NewMultiChoiceQuestion(&schemas.MultiChoiceParams{
+				Question: "<p>Q<iframe src=evil></iframe></p>",
+				Answers: []schemas.AnswerOption{{
+					Text:            "<strong>A</strong><script>x()</script>",
+					TipsAndFeedback: &schemas.AnswerTipsAndFeedback{Tip: "<u>tip</u><object></object>"},
+				}},
+			}).ToQuestion(),
+			{Library: "H5P.TrueFalse 1.8", Params: map[string]any{
+				"question": "<p>True?</p><script>x()</script>",
+				"l10n":     map[string]any{"feedback": "<b>f</b>"},
+			}},
+		},
+	}
+
+	qs.SanitizeHTML(NewHTMLSanitizer())
+
+	mc := qs.Questions[0].Params.(*schemas.MultiChoiceParams)
+	generic := qs.Questions[1].Params.(map[string]any)
+	checks := []struct{ got, want string }{
+		{qs.Introduction, "<p>Intro</p>"},
+		{qs.OverallFeedback[0].Text, "<em>Done</em>"},
+		{mc.Question, "<p>Q</p>"},
+		{mc.Answers[0].Text, "<strong>A</strong>"},
+		{mc.Answers[0].TipsAndFeedback.Tip, "<u>tip</u>"},
+		{generic["question"].(string), "<p>True?</p>"},
+		{generic["l10n"].(map[string]any)["feedback"].(string), "f"},
+	}
+	for _, c := range checks {
+		if c.got != c.want {
+			t.Errorf("Expected %q, got %q", c.want, c.got)
+		}
+	}
+}
+
+func TestQuestionSetSanitizeHTMLTyped(t *testing.T) {
+	qs := &QuestionSet{
+		Questions: []Question{
+			{Library: "H5P.TrueFalse 1.8", Params: &schemas.TrueFalseParams{
+				Question:  "<p>True?</p><script>x()</script>",
+				Correct:   "true",
+				Behaviour: &schemas.TrueFalseBehaviour{FeedbackOnWrong: `<a href="javascript:x()">No</a>`},
+			}},
+			{Library: "H5P.Blanks 1.14", Params: &schemas.BlanksParams{
+				Text:            "<p>Fill in<script>x()</script></p>",
+				Questions:       []string{"<p>Paris is in *France*.<script>x()</script></p>"},
+				OverallFeedback: &schemas.OverallFeedback{OverallFeedback: []schemas.FeedbackRange{{To: 100, Feedback: "<img src=x onerror=x()>Done"}}},
+			}},
+			{Library: "H5P.Dialogcards 1.9", Params: &schemas.DialogCardsParams{
+				Description: "<p>Cards</p><script>x()</script>",
+			}},
+		},
+	}
+
+	qs.SanitizeHTML(NewHTMLSanitizer())
+
+	tf := qs.Questions[0].Params.(*schemas.TrueFalseParams)
+	blanks := qs.Questions[1].Params.(*schemas.BlanksParams)
+	cards := qs.Questions[2].Params.(*schemas.DialogCardsParams)
+	checks := []struct{ got, want string }{
+		{tf.Question, "<p>True?</p>"},
+		{tf.Behaviour.FeedbackOnWrong, "<a>No</a>"},
+		{blanks.Text, "<p>Fill in</p>"},
+		{blanks.Questions[0], "<p>Paris is in *France*.</p>"},
+		{blanks.OverallFeedback.OverallFeedback[0].Feedback, "Done"},
+		{cards.Description, "<p>Cards</p>"},
+	}
+	for _, c := range checks {
+		if c.got != c.want {
+			t.Errorf("Expected %q, got %q", c.want, c.got)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package schemas
+
+import "strings"
+
+// BlanksParams represents the parameters for H5P.Blanks (Fill in the Blanks)
+// content type
+// This struct is generated from the official H5P Blanks semantics.json schema
+type BlanksParams struct {
+	Media           *MediaGroup      `json:"media,omitempty"`
+	Text            string           `json:"text,omitempty"`
+	Questions       []string         `json:"questions"` // text with blanks marked as *answer/alternative:tip*
+	OverallFeedback *OverallFeedback `json:"overallFeedback,omitempty"`
+	ShowSolutions   string           `json:"showSolutions,omitempty"`
+	TryAgain        string           `json:"tryAgain,omitempty"`
+	CheckAnswer     string           `json:"checkAnswer,omitempty"`
+	SubmitAnswer    string           `json:"submitAnswer,omitempty"`
+	NotFilledOut    string           `json:"notFilledOut,omitempty"`
+	AnswerIsCorrect string           `json:"answerIsCorrect,omitempty"`
+	AnswerIsWrong   string           `json:"answerIsWrong,omitempty"`
+	SolutionLabel   string           `json:"solutionLabel,omitempty"`
+	TipLabel        string           `json:"tipLabel,omitempty"`
+	ScoreBarLabel   string           `json:"scoreBarLabel,omitempty"`
+	Behaviour       *BlanksBehaviour `json:"behaviour,omitempty"`
+	ConfirmCheck    *ConfirmDialog   `json:"confirmCheck,omitempty"`
+	ConfirmRetry    *ConfirmDialog   `json:"confirmRetry,omitempty"`
+}
+
+// BlanksBehaviour controls how the Blanks question behaves
+type BlanksBehaviour struct {
+	EnableRetry                bool `json:"enableRetry,omitempty"`
+	EnableSolutionsButton      bool `json:"enableSolutionsButton,omitempty"`
+	EnableCheckButton          bool `json:"enableCheckButton,omitempty"`
+	AutoCheck                  bool `json:"autoCheck,omitempty"`
+	CaseSensitive              bool `json:"caseSensitive"`
+	ShowSolutionsRequiresInput bool `json:"showSolutionsRequiresInput,omitempty"`
+	SeparateLines              bool `json:"separateLines,omitempty"`
+	ConfirmCheckDialog         bool `json:"confirmCheckDialog,omitempty"`
+	ConfirmRetryDialog         bool `json:"confirmRetryDialog,omitempty"`
+	AcceptSpellingErrors       bool `json:"acceptSpellingErrors,omitempty"`
+}
+
+// Blank is a gap in Blanks question text, written as *answer/alternative:tip*.
+type Blank struct {
+	Answers []string
+	Tip     string
+}
+
+// String returns the blank in H5P marker syntax.
+func (b Blank) String() string {
+	s := "*" + strings.Join(b.Answers, "/")
+	if b.Tip != "" {
+		s += ":" + b.Tip
+	}
+	return s + "*"
+}
+
+// ParseBlanks returns the blanks marked in a Blanks question text.
+func ParseBlanks(text string) []Blank {
+	var blanks []Blank
+	for _, m := range markers(text) {
+		answers, tip, _ := strings.Cut(text[m[0]+1:m[1]-1], ":")
+		blank := Blank{Tip: strings.TrimSpace(tip)}
+		for _, a := range strings.Split(answers, "/") {
+			blank.Answers = append(blank.Answers, strings.TrimSpace(a))
+		}
+		blanks = append(blanks, blank)
+	}
+	return blanks
+}
+
+// markers returns the start and end offsets of each *marked* span in text,
+// including the asterisks, as used by Blanks and DragText.
+func markers(text string) [][2]int {
+	var spans [][2]int
+	for i := 0; i < len(text); {
+		start := strings.IndexByte(text[i:], '*')
+		if start < 0 {
+			break
+		}
+		start += i
+		end := strings.IndexByte(text[start+1:], '*')
+		if end < 0 {
+			break
+		}
+		end += start + 2
+		if end-start > 2 {
+			spans = append(spans, [2]int{start, end})
+		}
+		i = end
+	}
+	return spans
+}
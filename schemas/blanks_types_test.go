@@ -0,0 +1,46 @@
+package schemas
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseBlanks(t *testing.T) {
+	got := ParseBlanks("<p>Paris is the capital of *France* and Berlin of *Germany/Deutschland:Think beer*.</p>**")
+	want := []Blank{
+		{Answers: []string{"France"}},
+		{Answers: []string{"Germany", "Deutschland"}, Tip: "Think beer"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+	if s := got[1].String(); s != "*Germany/Deutschland:Think beer*" {
+		t.Errorf("Unexpected marker %q", s)
+	}
+}
+
+func TestParseDraggables(t *testing.T) {
+	got := ParseDraggables("Cat: *meow*\nDog: *woof:Loud*\nno markers")
+	want := []Draggable{
+		{Answer: "meow", Line: "Cat: *meow*"},
+		{Answer: "woof", Tip: "Loud", Line: "Dog: *woof:Loud*"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestParseMarkableWords(t *testing.T) {
+	got := ParseMarkableWords("<p>The *sun* is a <strong>star</strong> &ndash; *hot*.</p>")
+	want := []MarkableWord{
+		{Text: "The"},
+		{Text: "sun", Correct: true},
+		{Text: "is"},
+		{Text: "a"},
+		{Text: "star"},
+		{Text: "hot", Correct: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
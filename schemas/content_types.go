@@ -0,0 +1,96 @@
+package schemas
+
+import (
+	"slices"
+	"strings"
+	"sync"
+)
+
+// ContentTypeParams is implemented by the typed params of content types,
+// such as *MultiChoiceParams, so the library of params can be derived
+// from their type.
+type ContentTypeParams interface {
+	// MachineName returns the machine name of the content type library,
+	// e.g. "H5P.MultiChoice".
+	MachineName() string
+}
+
+// ContentType describes a content type with typed params.
+type ContentType struct {
+	MachineName string
+	// Title is the name H5P shows for the content type, e.g. "Multiple
+	// Choice".
+	Title string
+	// Version is the "Major.Minor" version of the library questions of the
+	// content type use, e.g. "1.2". If empty, the newest version known to
+	// package h5p is used.
+	Version string
+	// New returns empty params of the content type.
+	New func() ContentTypeParams
+}
+
+var (
+	contentTypesMu sync.RWMutex
+	contentTypes   = map[string]ContentType{}
+)
+
+func init() {
+	for _, ct := range []ContentType{
+		{"H5P.Audio", "Audio", "", func() ContentTypeParams { return &AudioParams{} }},
+		{"H5P.Blanks", "Fill in the Blanks", "", func() ContentTypeParams { return &BlanksParams{} }},
+		{"H5P.Dialogcards", "Dialog Cards", "", func() ContentTypeParams { return &DialogCardsParams{} }},
+		{"H5P.DragText", "Drag the Words", "", func() ContentTypeParams { return &DragTextParams{} }},
+		{"H5P.Essay", "Essay", "", func() ContentTypeParams { return &EssayParams{} }},
+		{"H5P.Flashcards", "Flashcards", "", func() ContentTypeParams { return &FlashcardsParams{} }},
+		{"H5P.Image", "Image", "", func() ContentTypeParams { return &ImageParams{} }},
+		{"H5P.MarkTheWords", "Mark the Words", "", func() ContentTypeParams { return &MarkTheWordsParams{} }},
+		{"H5P.MultiChoice", "Multiple Choice", "", func() ContentTypeParams { return &MultiChoiceParams{} }},
+		{"H5P.TrueFalse", "True/False Question", "", func() ContentTypeParams { return &TrueFalseParams{} }},
+		{"H5P.Video", "Video", "", func() ContentTypeParams { return &VideoParams{} }},
+	} {
+		RegisterContentType(ct)
+	}
+}
+
+// RegisterContentType adds a content type to the registry, replacing any
+// with the same machine name, so params types defined outside this
+// package can be used like the built-in ones.
+func RegisterContentType(ct ContentType) {
+	contentTypesMu.Lock()
+	defer contentTypesMu.Unlock()
+	contentTypes[ct.MachineName] = ct
+}
+
+// LookupContentType returns the registered content type of a library
+// machine name.
+func LookupContentType(machineName string) (ContentType, bool) {
+	contentTypesMu.RLock()
+	defer contentTypesMu.RUnlock()
+	ct, ok := contentTypes[machineName]
+	return ct, ok
+}
+
+// ContentTypes returns the registered content types sorted by machine
+// name.
+func ContentTypes() []ContentType {
+	contentTypesMu.RLock()
+	defer contentTypesMu.RUnlock()
+	list := make([]ContentType, 0, len(contentTypes))
+	for _, ct := range contentTypes {
+		list = append(list, ct)
+	}
+	slices.SortFunc(list, func(a, b ContentType) int { return strings.Compare(a.MachineName, b.MachineName) })
+	return list
+}
+
+func (p *AudioParams) MachineName() string        { return "H5P.Audio" }
+func (p *BlanksParams) MachineName() string       { return "H5P.Blanks" }
+func (p *DialogCardsParams) MachineName() string  { return "H5P.Dialogcards" }
+func (p *DragTextParams) MachineName() string     { return "H5P.DragText" }
+func (p *EssayParams) MachineName() string        { return "H5P.Essay" }
+func (p *FlashcardsParams) MachineName() string   { return "H5P.Flashcards" }
+func (p *ImageParams) MachineName() string        { return "H5P.Image" }
+func (p *MarkTheWordsParams) MachineName() string { return "H5P.MarkTheWords" }
+func (p *MultiChoiceParams) MachineName() string  { return "H5P.MultiChoice" }
+func (p *TrueFalseParams) MachineName() string    { return "H5P.TrueFalse" }
+func (p *VideoParams) MachineName() string        { return "H5P.Video" }
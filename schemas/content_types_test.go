@@ -0,0 +1,50 @@
+package schemas
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestContentTypes(t *testing.T) {
+	for _, ct := range ContentTypes() {
+		p := ct.New()
+		if p.MachineName() != ct.MachineName {
+			t.Errorf("Expected %T to be %s, got %s", p, ct.MachineName, p.MachineName())
+		}
+		if ct.Title == "" {
+			t.Errorf("Expected a title for %s", ct.MachineName)
+		}
+	}
+	ct, ok := LookupContentType("H5P.TrueFalse")
+	if !ok || ct.Title != "True/False Question" {
+		t.Errorf("Unexpected content type %+v", ct)
+	}
+	if _, ok := LookupContentType("H5P.Missing"); ok {
+		t.Error("Expected no content type for an unknown library")
+	}
+}
+
+func TestMediaParamsCopyright(t *testing.T) {
+	data := []byte(`{"visuals":{"poster":{"path":"images/poster.jpg","copyright":{"title":"Poster","author":"Ann","year":"2021","source":"https://example.com","license":"CC BY","version":"4.0"}}},"sources":[{"path":"videos/a.mp4","mime":"video/mp4","copyright":{"year":"2020","license":"CC0 1.0"}}]}`)
+	var v VideoParams
+	if err := json.Unmarshal(data, &v); err != nil {
+		t.Fatal(err)
+	}
+	if c := v.Visuals.Poster.Copyright; c.Year != "2021" || c.Source != "https://example.com" || c.Version != "4.0" {
+		t.Errorf("Unexpected poster copyright %+v", c)
+	}
+	if c := v.Sources[0].Copyright; c.IsZero() || c.Year != "2020" {
+		t.Errorf("Unexpected source copyright %+v", c)
+	}
+	out, err := json.Marshal(&v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != string(data) {
+		t.Errorf("Expected a lossless round trip, got %s", out)
+	}
+	var missing *Copyright
+	if !missing.IsZero() || !(&Copyright{}).IsZero() {
+		t.Error("Expected nil and empty copyrights to be zero")
+	}
+}
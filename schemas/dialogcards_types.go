@@ -0,0 +1,55 @@
+package schemas
+
+// DialogCardsParams represents the parameters for H5P.Dialogcards content
+// type
+// This struct is generated from the official H5P Dialogcards semantics.json schema
+type DialogCardsParams struct {
+	Title             string                `json:"title,omitempty"`
+	Mode              string                `json:"mode,omitempty"` // "normal" or "repetition"
+	Description       string                `json:"description,omitempty"`
+	Dialogs           []DialogCard          `json:"dialogs"`
+	Behaviour         *DialogCardsBehaviour `json:"behaviour,omitempty"`
+	Answer            string                `json:"answer,omitempty"`
+	Next              string                `json:"next,omitempty"`
+	Prev              string                `json:"prev,omitempty"`
+	Retry             string                `json:"retry,omitempty"`
+	CorrectAnswer     string                `json:"correctAnswer,omitempty"`
+	IncorrectAnswer   string                `json:"incorrectAnswer,omitempty"`
+	Round             string                `json:"round,omitempty"`
+	CardsLeft         string                `json:"cardsLeft,omitempty"`
+	NextRound         string                `json:"nextRound,omitempty"`
+	StartOver         string                `json:"startOver,omitempty"`
+	ShowSummary       string                `json:"showSummary,omitempty"`
+	Summary           string                `json:"summary,omitempty"`
+	ProgressText      string                `json:"progressText,omitempty"`
+	CardFrontLabel    string                `json:"cardFrontLabel,omitempty"`
+	CardBackLabel     string                `json:"cardBackLabel,omitempty"`
+	TipButtonLabel    string                `json:"tipButtonLabel,omitempty"`
+	AudioNotSupported string                `json:"audioNotSupported,omitempty"`
+}
+
+// DialogCard is a card with text on the front and an answer on the back
+type DialogCard struct {
+	Text         string          `json:"text,omitempty"`
+	Answer       string          `json:"answer,omitempty"`
+	Image        *ImageFile      `json:"image,omitempty"`
+	ImageAltText string          `json:"imageAltText,omitempty"`
+	Audio        []AudioFile     `json:"audio,omitempty"`
+	Tips         *DialogCardTips `json:"tips,omitempty"`
+}
+
+// DialogCardTips holds the tips shown on each side of a card
+type DialogCardTips struct {
+	Front string `json:"front,omitempty"`
+	Back  string `json:"back,omitempty"`
+}
+
+// DialogCardsBehaviour controls how the Dialogcards content behaves
+type DialogCardsBehaviour struct {
+	EnableRetry                bool `json:"enableRetry,omitempty"`
+	DisableBackwardsNavigation bool `json:"disableBackwardsNavigation,omitempty"`
+	ScaleTextNotCard           bool `json:"scaleTextNotCard,omitempty"`
+	RandomCards                bool `json:"randomCards,omitempty"`
+	MaxProficiency             int  `json:"maxProficiency,omitempty"`
+	QuickProgression           bool `json:"quickProgression,omitempty"`
+}
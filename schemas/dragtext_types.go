@@ -0,0 +1,56 @@
+package schemas
+
+import "strings"
+
+// DragTextParams represents the parameters for H5P.DragText (Drag the Words)
+// content type
+// This struct is generated from the official H5P DragText semantics.json schema
+type DragTextParams struct {
+	TaskDescription string             `json:"taskDescription,omitempty"`
+	TextField       string             `json:"textField"` // draggable words marked as *answer:tip*
+	OverallFeedback *OverallFeedback   `json:"overallFeedback,omitempty"`
+	CheckAnswer     string             `json:"checkAnswer,omitempty"`
+	SubmitAnswer    string             `json:"submitAnswer,omitempty"`
+	TryAgain        string             `json:"tryAgain,omitempty"`
+	ShowSolution    string             `json:"showSolution,omitempty"`
+	TipLabel        string             `json:"tipLabel,omitempty"`
+	CorrectText     string             `json:"correctText,omitempty"`
+	IncorrectText   string             `json:"incorrectText,omitempty"`
+	CorrectAnswer   string             `json:"correctAnswer,omitempty"`
+	FeedbackHeader  string             `json:"feedbackHeader,omitempty"`
+	ScoreBarLabel   string             `json:"scoreBarLabel,omitempty"`
+	Behaviour       *DragTextBehaviour `json:"behaviour,omitempty"`
+}
+
+// DragTextBehaviour controls how the DragText question behaves
+type DragTextBehaviour struct {
+	EnableRetry           bool `json:"enableRetry,omitempty"`
+	EnableSolutionsButton bool `json:"enableSolutionsButton,omitempty"`
+	EnableCheckButton     bool `json:"enableCheckButton,omitempty"`
+	InstantFeedback       bool `json:"instantFeedback,omitempty"`
+}
+
+// Draggable is a word of DragText text, written as *answer:tip*. Line is
+// the text line containing it.
+type Draggable struct {
+	Answer string
+	Tip    string
+	Line   string
+}
+
+// ParseDraggables returns the draggable words marked in a DragText text
+// field, in order.
+func ParseDraggables(textField string) []Draggable {
+	var draggables []Draggable
+	for _, line := range strings.Split(textField, "\n") {
+		for _, m := range markers(line) {
+			answer, tip, _ := strings.Cut(line[m[0]+1:m[1]-1], ":")
+			draggables = append(draggables, Draggable{
+				Answer: strings.TrimSpace(answer),
+				Tip:    strings.TrimSpace(tip),
+				Line:   line,
+			})
+		}
+	}
+	return draggables
+}
@@ -0,0 +1,56 @@
+package schemas
+
+// EssayParams represents the parameters for H5P.Essay content type
+// This struct is generated from the official H5P Essay semantics.json schema
+type EssayParams struct {
+	Media           *MediaGroup      `json:"media,omitempty"`
+	TaskDescription string           `json:"taskDescription"`
+	PlaceholderText string           `json:"placeholderText,omitempty"`
+	Solution        *EssaySolution   `json:"solution,omitempty"`
+	Keywords        []EssayKeyword   `json:"keywords,omitempty"`
+	OverallFeedback *OverallFeedback `json:"overallFeedback,omitempty"`
+	Behaviour       *EssayBehaviour  `json:"behaviour,omitempty"`
+	CheckAnswer     string           `json:"checkAnswer,omitempty"`
+	SubmitAnswer    string           `json:"submitAnswer,omitempty"`
+	TryAgain        string           `json:"tryAgain,omitempty"`
+	ShowSolution    string           `json:"showSolution,omitempty"`
+	FeedbackHeader  string           `json:"feedbackHeader,omitempty"`
+	SolutionTitle   string           `json:"solutionTitle,omitempty"`
+}
+
+// EssaySolution is the sample solution shown after submitting
+type EssaySolution struct {
+	Introduction string `json:"introduction,omitempty"`
+	Sample       string `json:"sample,omitempty"`
+}
+
+// EssayKeyword is a keyword the answer is scored against
+type EssayKeyword struct {
+	Keyword      string               `json:"keyword"`
+	Alternatives []string             `json:"alternatives,omitempty"`
+	Options      *EssayKeywordOptions `json:"options,omitempty"`
+}
+
+// EssayKeywordOptions controls how a keyword is scored
+type EssayKeywordOptions struct {
+	Points               int    `json:"points,omitempty"`
+	Occurrences          int    `json:"occurrences,omitempty"`
+	CaseSensitive        bool   `json:"caseSensitive"`
+	ForgiveMistakes      bool   `json:"forgiveMistakes,omitempty"`
+	FeedbackIncluded     string `json:"feedbackIncluded,omitempty"`
+	FeedbackMissed       string `json:"feedbackMissed,omitempty"`
+	FeedbackIncludedWord string `json:"feedbackIncludedWord,omitempty"` // "keyword", "alternative", "answer", "none"
+	FeedbackMissedWord   string `json:"feedbackMissedWord,omitempty"`   // "keyword", "none"
+}
+
+// EssayBehaviour controls how the Essay question behaves
+type EssayBehaviour struct {
+	MinimumLength       int    `json:"minimumLength,omitempty"`
+	MaximumLength       int    `json:"maximumLength,omitempty"`
+	InputFieldSize      string `json:"inputFieldSize,omitempty"` // "1", "3", "10"
+	EnableRetry         bool   `json:"enableRetry,omitempty"`
+	IgnoreScoring       bool   `json:"ignoreScoring,omitempty"`
+	PointsHost          int    `json:"pointsHost,omitempty"`
+	PercentagePassing   int    `json:"percentagePassing,omitempty"`
+	PercentageMastering int    `json:"percentageMastering,omitempty"`
+}
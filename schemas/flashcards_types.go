@@ -0,0 +1,32 @@
+package schemas
+
+// FlashcardsParams represents the parameters for H5P.Flashcards content type
+type FlashcardsParams struct {
+	Description                string      `json:"description,omitempty"`
+	Cards                      []Flashcard `json:"cards"`
+	ProgressText               string      `json:"progressText,omitempty"`
+	Next                       string      `json:"next,omitempty"`
+	Previous                   string      `json:"previous,omitempty"`
+	CheckAnswerText            string      `json:"checkAnswerText,omitempty"`
+	ShowSolutionsRequiresInput bool        `json:"showSolutionsRequiresInput,omitempty"`
+	DefaultAnswerText          string      `json:"defaultAnswerText,omitempty"`
+	CorrectAnswerText          string      `json:"correctAnswerText,omitempty"`
+	IncorrectAnswerText        string      `json:"incorrectAnswerText,omitempty"`
+	ShowSolutionText           string      `json:"showSolutionText,omitempty"`
+	Results                    string      `json:"results,omitempty"`
+	OfCorrect                  string      `json:"ofCorrect,omitempty"`
+	ShowResults                string      `json:"showResults,omitempty"`
+	AnswerShortText            string      `json:"answerShortText,omitempty"`
+	Retry                      string      `json:"retry,omitempty"`
+	CaseSensitive              bool        `json:"caseSensitive,omitempty"`
+	RandomCards                bool        `json:"randomCards,omitempty"`
+}
+
+// Flashcard is a card asking for a typed answer
+type Flashcard struct {
+	Text         string     `json:"text,omitempty"`
+	Answer       string     `json:"answer,omitempty"`
+	Image        *ImageFile `json:"image,omitempty"`
+	ImageAltText string     `json:"imageAltText,omitempty"`
+	Tip          string     `json:"tip,omitempty"`
+}
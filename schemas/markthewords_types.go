@@ -0,0 +1,68 @@
+package schemas
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// MarkTheWordsParams represents the parameters for H5P.MarkTheWords content
+// type
+// This struct is generated from the official H5P MarkTheWords semantics.json schema
+type MarkTheWordsParams struct {
+	Media                      *MediaGroup            `json:"media,omitempty"`
+	TaskDescription            string                 `json:"taskDescription,omitempty"`
+	TextField                  string                 `json:"textField"` // correct words marked as *word*
+	OverallFeedback            *OverallFeedback       `json:"overallFeedback,omitempty"`
+	CheckAnswerButton          string                 `json:"checkAnswerButton,omitempty"`
+	SubmitAnswerButton         string                 `json:"submitAnswerButton,omitempty"`
+	TryAgainButton             string                 `json:"tryAgainButton,omitempty"`
+	ShowSolutionButton         string                 `json:"showSolutionButton,omitempty"`
+	Behaviour                  *MarkTheWordsBehaviour `json:"behaviour,omitempty"`
+	CorrectAnswer              string                 `json:"correctAnswer,omitempty"`
+	IncorrectAnswer            string                 `json:"incorrectAnswer,omitempty"`
+	MissedAnswer               string                 `json:"missedAnswer,omitempty"`
+	DisplaySolutionDescription string                 `json:"displaySolutionDescription,omitempty"`
+	ScoreBarLabel              string                 `json:"scoreBarLabel,omitempty"`
+}
+
+// MarkTheWordsBehaviour controls how the MarkTheWords question behaves
+type MarkTheWordsBehaviour struct {
+	EnableRetry           bool `json:"enableRetry,omitempty"`
+	EnableSolutionsButton bool `json:"enableSolutionsButton,omitempty"`
+	EnableCheckButton     bool `json:"enableCheckButton,omitempty"`
+	ShowScorePoints       bool `json:"showScorePoints,omitempty"`
+}
+
+// MarkableWord is a word of MarkTheWords text; Correct words are the ones
+// to mark, written as *word*.
+type MarkableWord struct {
+	Text    string
+	Correct bool
+}
+
+var markupTag = regexp.MustCompile(`<[^>]*>`)
+
+// ParseMarkableWords returns the words of a MarkTheWords text field in
+// order, as the learner can select them. Markup is removed and tokens
+// without letters or digits, such as a dash, are left out.
+func ParseMarkableWords(textField string) []MarkableWord {
+	text := html.UnescapeString(markupTag.ReplaceAllString(textField, " "))
+	var words []MarkableWord
+	addFields := func(s string) {
+		for _, f := range strings.Fields(s) {
+			if strings.IndexFunc(f, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) >= 0 {
+				words = append(words, MarkableWord{Text: f})
+			}
+		}
+	}
+	last := 0
+	for _, m := range markers(text) {
+		addFields(text[last:m[0]])
+		words = append(words, MarkableWord{Text: strings.TrimSpace(text[m[0]+1 : m[1]-1]), Correct: true})
+		last = m[1]
+	}
+	addFields(text[last:])
+	return words
+}
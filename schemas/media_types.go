@@ -0,0 +1,95 @@
+package schemas
+
+// MediaContent is a library-typed value such as the "type" field of a media
+// group, holding the selected library (e.g. "H5P.Image 1.1") and its params.
+type MediaContent struct {
+	Library      string `json:"library"`
+	Params       any    `json:"params"`
+	SubContentID string `json:"subContentId,omitempty"`
+}
+
+// ImageParams represents the parameters for H5P.Image content type
+type ImageParams struct {
+	ContentName   string     `json:"contentName,omitempty"`
+	File          *ImageFile `json:"file,omitempty"`
+	Alt           string     `json:"alt,omitempty"`
+	Title         string     `json:"title,omitempty"`
+	Decorative    bool       `json:"decorative,omitempty"`
+	ExpandImage   string     `json:"expandImage,omitempty"`
+	MinimizeImage string     `json:"minimizeImage,omitempty"`
+}
+
+// ImageFile is an image field value referencing a file in the content folder
+type ImageFile struct {
+	Path      string     `json:"path"`
+	Mime      string     `json:"mime,omitempty"`
+	Copyright *Copyright `json:"copyright,omitempty"`
+	Width     int        `json:"width,omitempty"`
+	Height    int        `json:"height,omitempty"`
+}
+
+// Copyright holds the copyright metadata H5P attaches to media files, the
+// fields of the copyright dialog of the H5P editor
+type Copyright struct {
+	Title  string `json:"title,omitempty"`
+	Author string `json:"author,omitempty"`
+	// Year is free text, e.g. "2021" or "2019-2021".
+	Year    string `json:"year,omitempty"`
+	Source  string `json:"source,omitempty"`
+	License string `json:"license,omitempty"`
+	Version string `json:"version,omitempty"`
+}
+
+// IsZero reports whether no copyright field is set.
+func (c *Copyright) IsZero() bool {
+	return c == nil || *c == Copyright{}
+}
+
+// AudioFile is one source of an audio field value, referencing a file in
+// the content folder or an external URL
+type AudioFile struct {
+	Path      string     `json:"path"`
+	Mime      string     `json:"mime,omitempty"`
+	Copyright *Copyright `json:"copyright,omitempty"`
+}
+
+// VideoFile is one source of a video field value, referencing a file in
+// the content folder or an external URL such as a YouTube link
+type VideoFile struct {
+	Path      string     `json:"path"`
+	Mime      string     `json:"mime,omitempty"`
+	Copyright *Copyright `json:"copyright,omitempty"`
+}
+
+// VideoParams represents the parameters for H5P.Video content type
+type VideoParams struct {
+	Visuals  *VideoVisuals  `json:"visuals,omitempty"`
+	Playback *VideoPlayback `json:"playback,omitempty"`
+	Sources  []VideoFile    `json:"sources,omitempty"`
+}
+
+// VideoVisuals holds the display settings of a video
+type VideoVisuals struct {
+	Fit      bool       `json:"fit,omitempty"`
+	Controls bool       `json:"controls,omitempty"`
+	Poster   *ImageFile `json:"poster,omitempty"`
+}
+
+// VideoPlayback holds the playback settings of a video
+type VideoPlayback struct {
+	Autoplay bool `json:"autoplay,omitempty"`
+	Loop     bool `json:"loop,omitempty"`
+}
+
+// AudioParams represents the parameters for H5P.Audio content type
+type AudioParams struct {
+	ContentName       string      `json:"contentName,omitempty"`
+	PlayerMode        string      `json:"playerMode,omitempty"`
+	FitToWrapper      bool        `json:"fitToWrapper,omitempty"`
+	Controls          bool        `json:"controls,omitempty"`
+	Autoplay          bool        `json:"autoplay,omitempty"`
+	PlayAudio         string      `json:"playAudio,omitempty"`
+	PauseAudio        string      `json:"pauseAudio,omitempty"`
+	AudioNotSupported string      `json:"audioNotSupported,omitempty"`
+	Files             []AudioFile `json:"files,omitempty"`
+}
@@ -1,9 +1,5 @@
 package schemas
 
-import (
-	"fmt"
-)
-
 // MultiChoiceParams represents the parameters for H5P.MultiChoice content type
 // This struct is generated from the official H5P MultiChoice semantics.json schema
 type MultiChoiceParams struct {
@@ -17,8 +13,8 @@ type MultiChoiceParams struct {
 
 // MediaGroup represents optional media content (images, videos)
 type MediaGroup struct {
-	Type                string `json:"type,omitempty"`
-	DisableImageZooming bool   `json:"disableImageZooming,omitempty"`
+	Type                *MediaContent `json:"type,omitempty"`
+	DisableImageZooming bool          `json:"disableImageZooming,omitempty"`
 }
 
 // AnswerOption represents a single answer choice
@@ -73,28 +69,36 @@ type UITranslations struct {
 	CorrectAnswer      string `json:"correctAnswer,omitempty"`
 }
 
-// Validate checks if the MultiChoiceParams are valid according to H5P semantics
+// Validate checks if the MultiChoiceParams are valid according to H5P
+// semantics. The returned error is a *ValidationResult listing every problem.
 func (p *MultiChoiceParams) Validate() error {
+	return p.ValidateAll().Err()
+}
+
+// ValidateAll checks the params and returns all problems found, with JSON
+// paths relative to the params object.
+func (p *MultiChoiceParams) ValidateAll() *ValidationResult {
+	r := &ValidationResult{}
 	if p.Question == "" {
-		return fmt.Errorf("question text is required")
+		r.AddError("question", CodeRequired, "question text is required")
 	}
 
 	if len(p.Answers) < 1 {
-		return fmt.Errorf("at least one answer is required")
+		r.AddError("answers", CodeRequired, "at least one answer is required")
 	}
 
 	correctCount := 0
-	for _, answer := range p.Answers {
+	for i, answer := range p.Answers {
 		if answer.Text == "" {
-			return fmt.Errorf("answer text cannot be empty")
+			r.AddError(JoinPath(IndexPath("answers", i), "text"), CodeRequired, "answer text cannot be empty")
 		}
 		if answer.Correct {
 			correctCount++
 		}
 	}
 
-	if correctCount == 0 {
-		return fmt.Errorf("at least one answer must be marked as correct")
+	if len(p.Answers) > 0 && correctCount == 0 {
+		r.AddError("answers", CodeNoCorrect, "at least one answer must be marked as correct")
 	}
 
 	// Validate behavior settings
@@ -102,14 +106,14 @@ func (p *MultiChoiceParams) Validate() error {
 		if p.Behaviour.Type != "" {
 			validTypes := map[string]bool{"auto": true, "multi": true, "single": true}
 			if !validTypes[p.Behaviour.Type] {
-				return fmt.Errorf("invalid question type: %s", p.Behaviour.Type)
+				r.AddError("behaviour.type", CodeInvalidValue, "invalid question type: %s", p.Behaviour.Type)
 			}
 		}
 
 		if p.Behaviour.PassPercentage < 0 || p.Behaviour.PassPercentage > 100 {
-			return fmt.Errorf("pass percentage must be between 0 and 100")
+			r.AddError("behaviour.passPercentage", CodeOutOfRange, "pass percentage must be between 0 and 100")
 		}
 	}
 
-	return nil
+	return r
 }
@@ -4,11 +4,59 @@ import (
 	_ "embed"
 )
 
+//go:embed accordion_semantics.json
+var AccordionSemanticsBytes []byte
+
+//go:embed blanks_semantics.json
+var BlanksSemanticsBytes []byte
+
+//go:embed column_semantics.json
+var ColumnSemanticsBytes []byte
+
+//go:embed coursepresentation_semantics.json
+var CoursePresentationSemanticsBytes []byte
+
+//go:embed crossword_semantics.json
+var CrosswordSemanticsBytes []byte
+
+//go:embed dialogcards_semantics.json
+var DialogCardsSemanticsBytes []byte
+
+//go:embed dragquestion_semantics.json
+var DragQuestionSemanticsBytes []byte
+
+//go:embed dragtext_semantics.json
+var DragTextSemanticsBytes []byte
+
 //go:embed essay_semantics.json
 var EssaySemanticsBytes []byte
 
+//go:embed imagehotspots_semantics.json
+var ImageHotspotsSemanticsBytes []byte
+
+//go:embed interactivevideo_semantics.json
+var InteractiveVideoSemanticsBytes []byte
+
+//go:embed markthewords_semantics.json
+var MarkTheWordsSemanticsBytes []byte
+
+//go:embed memorygame_semantics.json
+var MemoryGameSemanticsBytes []byte
+
 //go:embed multichoice_semantics.json
 var MultiChoiceSemanticsBytes []byte
 
+//go:embed questionnaire_semantics.json
+var QuestionnaireSemanticsBytes []byte
+
+//go:embed questionset_semantics.json
+var QuestionSetSemanticsBytes []byte
+
+//go:embed singlechoiceset_semantics.json
+var SingleChoiceSetSemanticsBytes []byte
+
+//go:embed summary_semantics.json
+var SummarySemanticsBytes []byte
+
 //go:embed truefalse_semantics.json
 var TrueFalseSemanticsBytes []byte
@@ -1,25 +1,51 @@
-package schemas
+package schemas_test
 
 import (
 	"encoding/json"
 	"testing"
 
+	"github.com/grokify/h5p-go/schemas"
 	"github.com/grokify/h5p-go/semantics"
 )
 
 func TestSchemaSemantics(t *testing.T) {
 	var schemaSemenaticsTests = []struct {
-		v []byte
+		name string
+		v    []byte
+		path string
 	}{
-		{EssaySemanticsBytes},
-		{MultiChoiceSemanticsBytes},
-		{TrueFalseSemanticsBytes}}
+		{"Accordion", schemas.AccordionSemanticsBytes, "panels.title"},
+		{"Blanks", schemas.BlanksSemanticsBytes, "questions"},
+		{"Column", schemas.ColumnSemanticsBytes, "content.content"},
+		{"CoursePresentation", schemas.CoursePresentationSemanticsBytes, "presentation.slides.elements.action"},
+		{"Crossword", schemas.CrosswordSemanticsBytes, "words.answer"},
+		{"DialogCards", schemas.DialogCardsSemanticsBytes, "dialogs.answer"},
+		{"DragQuestion", schemas.DragQuestionSemanticsBytes, "question.task.dropZones.label"},
+		{"DragText", schemas.DragTextSemanticsBytes, "textField"},
+		{"Essay", schemas.EssaySemanticsBytes, "taskDescription"},
+		{"ImageHotspots", schemas.ImageHotspotsSemanticsBytes, "hotspots.position.x"},
+		{"InteractiveVideo", schemas.InteractiveVideoSemanticsBytes, "interactiveVideo.assets.interactions.action"},
+		{"MarkTheWords", schemas.MarkTheWordsSemanticsBytes, "textField"},
+		{"MemoryGame", schemas.MemoryGameSemanticsBytes, "cards.image"},
+		{"MultiChoice", schemas.MultiChoiceSemanticsBytes, "behaviour.passPercentage"},
+		{"Questionnaire", schemas.QuestionnaireSemanticsBytes, "questionnaireElements.library"},
+		{"QuestionSet", schemas.QuestionSetSemanticsBytes, "endGame.showResultPage"},
+		{"SingleChoiceSet", schemas.SingleChoiceSetSemanticsBytes, "choices.answers"},
+		{"Summary", schemas.SummarySemanticsBytes, "summaries.summary"},
+		{"TrueFalse", schemas.TrueFalseSemanticsBytes, "correct"}}
 
 	for _, tt := range schemaSemenaticsTests {
-		try := []semantics.Field{}
+		try := semantics.SemanticDefinition{}
 		err := json.Unmarshal(tt.v, &try)
 		if err != nil {
-			t.Errorf("]semantics.Field unmarshal error %v", err)
+			t.Errorf("%s: []semantics.Field unmarshal error %v", tt.name, err)
+			continue
+		}
+		if len(try) == 0 {
+			t.Errorf("%s: semantics are empty", tt.name)
+		}
+		if try.FieldByPath(tt.path) == nil {
+			t.Errorf("%s: field '%s' not found", tt.name, tt.path)
 		}
 	}
 }
@@ -0,0 +1,74 @@
+package schemas
+
+// TrueFalseParams represents the parameters for H5P.TrueFalse content type
+// This struct is generated from the official H5P TrueFalse semantics.json schema
+type TrueFalseParams struct {
+	Media        *MediaGroup         `json:"media,omitempty"`
+	Question     string              `json:"question"`
+	Correct      string              `json:"correct"` // "true" or "false"
+	L10n         *TrueFalseL10n      `json:"l10n,omitempty"`
+	Behaviour    *TrueFalseBehaviour `json:"behaviour,omitempty"`
+	ConfirmCheck *ConfirmDialog      `json:"confirmCheck,omitempty"`
+	ConfirmRetry *ConfirmDialog      `json:"confirmRetry,omitempty"`
+}
+
+// TrueFalseL10n contains user interface text labels
+type TrueFalseL10n struct {
+	TrueText             string `json:"trueText,omitempty"`
+	FalseText            string `json:"falseText,omitempty"`
+	Score                string `json:"score,omitempty"`
+	CheckAnswer          string `json:"checkAnswer,omitempty"`
+	SubmitAnswer         string `json:"submitAnswer,omitempty"`
+	ShowSolutionButton   string `json:"showSolutionButton,omitempty"`
+	TryAgain             string `json:"tryAgain,omitempty"`
+	WrongAnswerMessage   string `json:"wrongAnswerMessage,omitempty"`
+	CorrectAnswerMessage string `json:"correctAnswerMessage,omitempty"`
+	ScoreBarLabel        string `json:"scoreBarLabel,omitempty"`
+	A11yCheck            string `json:"a11yCheck,omitempty"`
+	A11yShowSolution     string `json:"a11yShowSolution,omitempty"`
+	A11yRetry            string `json:"a11yRetry,omitempty"`
+}
+
+// TrueFalseBehaviour controls how the TrueFalse question behaves
+type TrueFalseBehaviour struct {
+	EnableRetry           bool   `json:"enableRetry,omitempty"`
+	EnableSolutionsButton bool   `json:"enableSolutionsButton,omitempty"`
+	EnableCheckButton     bool   `json:"enableCheckButton,omitempty"`
+	ConfirmCheckDialog    bool   `json:"confirmCheckDialog,omitempty"`
+	ConfirmRetryDialog    bool   `json:"confirmRetryDialog,omitempty"`
+	AutoCheck             bool   `json:"autoCheck,omitempty"`
+	FeedbackOnCorrect     string `json:"feedbackOnCorrect,omitempty"`
+	FeedbackOnWrong       string `json:"feedbackOnWrong,omitempty"`
+}
+
+// ConfirmDialog contains the texts of a confirmation dialog
+type ConfirmDialog struct {
+	Header       string `json:"header,omitempty"`
+	Body         string `json:"body,omitempty"`
+	CancelLabel  string `json:"cancelLabel,omitempty"`
+	ConfirmLabel string `json:"confirmLabel,omitempty"`
+}
+
+// IsTrue reports whether "true" is the correct answer.
+func (p *TrueFalseParams) IsTrue() bool {
+	return p.Correct == "true"
+}
+
+// Validate checks if the TrueFalseParams are valid according to H5P
+// semantics. The returned error is a *ValidationResult listing every problem.
+func (p *TrueFalseParams) Validate() error {
+	return p.ValidateAll().Err()
+}
+
+// ValidateAll checks the params and returns all problems found, with JSON
+// paths relative to the params object.
+func (p *TrueFalseParams) ValidateAll() *ValidationResult {
+	r := &ValidationResult{}
+	if p.Question == "" {
+		r.AddError("question", CodeRequired, "question text is required")
+	}
+	if p.Correct != "true" && p.Correct != "false" {
+		r.AddError("correct", CodeInvalidValue, "correct must be \"true\" or \"false\", got %q", p.Correct)
+	}
+	return r
+}
@@ -0,0 +1,137 @@
+package schemas
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Severity is the level of a validation problem.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Machine-readable validation problem codes.
+const (
+	CodeRequired     = "required"
+	CodeOutOfRange   = "out_of_range"
+	CodeInvalidValue = "invalid_value"
+	CodeInvalidRange = "invalid_range"
+	CodeNoCorrect    = "no_correct_answer"
+)
+
+// ValidationError is a single validation problem located by a JSON path
+// such as "questions[2].params.answers[0].text".
+type ValidationError struct {
+	Path     string   `json:"path"`
+	Code     string   `json:"code"`
+	Message  string   `json:"message"`
+	Severity Severity `json:"severity"`
+}
+
+func (e ValidationError) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return e.Path + ": " + e.Message
+}
+
+// ValidationResult accumulates every problem found during validation rather
+// than stopping at the first one.
+type ValidationResult struct {
+	Problems []ValidationError `json:"problems,omitempty"`
+}
+
+// AddError records an error at path.
+func (r *ValidationResult) AddError(path, code, format string, args ...any) {
+	r.add(path, code, SeverityError, format, args...)
+}
+
+// AddWarning records a warning at path.
+func (r *ValidationResult) AddWarning(path, code, format string, args ...any) {
+	r.add(path, code, SeverityWarning, format, args...)
+}
+
+func (r *ValidationResult) add(path, code string, severity Severity, format string, args ...any) {
+	r.Problems = append(r.Problems, ValidationError{
+		Path:     path,
+		Code:     code,
+		Message:  fmt.Sprintf(format, args...),
+		Severity: severity,
+	})
+}
+
+// Merge appends the problems of other with their paths prefixed by prefix.
+func (r *ValidationResult) Merge(prefix string, other *ValidationResult) {
+	if other == nil {
+		return
+	}
+	for _, p := range other.Problems {
+		p.Path = JoinPath(prefix, p.Path)
+		r.Problems = append(r.Problems, p)
+	}
+}
+
+// Errors returns the problems with error severity.
+func (r *ValidationResult) Errors() []ValidationError {
+	return r.bySeverity(SeverityError)
+}
+
+// Warnings returns the problems with warning severity.
+func (r *ValidationResult) Warnings() []ValidationError {
+	return r.bySeverity(SeverityWarning)
+}
+
+func (r *ValidationResult) bySeverity(severity Severity) []ValidationError {
+	var out []ValidationError
+	for _, p := range r.Problems {
+		if p.Severity == severity {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// Valid reports whether no errors were found. Warnings do not make a result
+// invalid.
+func (r *ValidationResult) Valid() bool {
+	return len(r.Errors()) == 0
+}
+
+// Err returns r as an error if it contains errors, otherwise nil.
+func (r *ValidationResult) Err() error {
+	if r.Valid() {
+		return nil
+	}
+	return r
+}
+
+func (r *ValidationResult) Error() string {
+	errs := r.Errors()
+	msgs := make([]string, len(errs))
+	for i, e := range errs {
+		msgs[i] = e.Error()
+	}
+	return fmt.Sprintf("%d validation error(s): %s", len(errs), strings.Join(msgs, "; "))
+}
+
+// JoinPath appends a field name or "[i]" index to a JSON path.
+func JoinPath(prefix, child string) string {
+	switch {
+	case prefix == "":
+		return child
+	case child == "":
+		return prefix
+	case strings.HasPrefix(child, "["):
+		return prefix + child
+	default:
+		return prefix + "." + child
+	}
+}
+
+// IndexPath returns the JSON path of element i of the array at prefix.
+func IndexPath(prefix string, i int) string {
+	return fmt.Sprintf("%s[%d]", prefix, i)
+}
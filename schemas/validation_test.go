@@ -0,0 +1,56 @@
+package schemas
+
+import "testing"
+
+func TestValidationResult(t *testing.T) {
+	params := &MultiChoiceParams{
+		Answers:   []AnswerOption{{Text: "A"}, {Text: ""}},
+		Behaviour: &Behaviour{Type: "random", PassPercentage: -1},
+	}
+	inner := params.ValidateAll()
+
+	r := &ValidationResult{}
+	r.AddWarning("title", "empty", "title is empty")
+	r.Merge("questions[0].params", inner)
+
+	if r.Valid() {
+		t.Fatal("Expected result with errors to be invalid")
+	}
+	if len(r.Warnings()) != 1 || r.Warnings()[0].Path != "title" {
+		t.Errorf("Expected one warning at title, got %v", r.Warnings())
+	}
+	want := []string{
+		"questions[0].params.question",
+		"questions[0].params.answers[1].text",
+		"questions[0].params.answers",
+		"questions[0].params.behaviour.type",
+		"questions[0].params.behaviour.passPercentage",
+	}
+	errs := r.Errors()
+	if len(errs) != len(want) {
+		t.Fatalf("Expected %d errors, got %v", len(want), errs)
+	}
+	for i, path := range want {
+		if errs[i].Path != path {
+			t.Errorf("Expected error %d at %s, got %s", i, path, errs[i].Path)
+		}
+	}
+
+	warnOnly := &ValidationResult{}
+	warnOnly.AddWarning("", "empty", "nothing to see")
+	if warnOnly.Err() != nil {
+		t.Error("Warnings alone should not produce an error")
+	}
+}
+
+func TestTrueFalseParamsValidate(t *testing.T) {
+	params := &TrueFalseParams{Question: "The sky is blue.", Correct: "true"}
+	if err := params.Validate(); err != nil || !params.IsTrue() {
+		t.Errorf("Expected valid true statement, got %v", err)
+	}
+
+	errs := (&TrueFalseParams{Correct: "yes"}).ValidateAll().Errors()
+	if len(errs) != 2 || errs[0].Path != "question" || errs[1].Code != CodeInvalidValue {
+		t.Errorf("Expected missing question and invalid correct value, got %v", errs)
+	}
+}
@@ -0,0 +1,46 @@
+package schemas
+
+import (
+	"gopkg.in/yaml.v3"
+
+	"github.com/grokify/h5p-go/internal/yamljson"
+)
+
+// The typed params marshal to YAML through their JSON form, so field names,
+// omitempty and BoolInt-style conversions match the content.json encoding.
+
+// MarshalYAML implements yaml.Marshaler.
+func (p MultiChoiceParams) MarshalYAML() (any, error) { return yamljson.Node(p) }
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (p *MultiChoiceParams) UnmarshalYAML(n *yaml.Node) error { return yamljson.DecodeNode(n, p) }
+
+// MarshalYAML implements yaml.Marshaler.
+func (p TrueFalseParams) MarshalYAML() (any, error) { return yamljson.Node(p) }
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (p *TrueFalseParams) UnmarshalYAML(n *yaml.Node) error { return yamljson.DecodeNode(n, p) }
+
+// MarshalYAML implements yaml.Marshaler.
+func (p BlanksParams) MarshalYAML() (any, error) { return yamljson.Node(p) }
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (p *BlanksParams) UnmarshalYAML(n *yaml.Node) error { return yamljson.DecodeNode(n, p) }
+
+// MarshalYAML implements yaml.Marshaler.
+func (p DragTextParams) MarshalYAML() (any, error) { return yamljson.Node(p) }
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (p *DragTextParams) UnmarshalYAML(n *yaml.Node) error { return yamljson.DecodeNode(n, p) }
+
+// MarshalYAML implements yaml.Marshaler.
+func (p EssayParams) MarshalYAML() (any, error) { return yamljson.Node(p) }
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (p *EssayParams) UnmarshalYAML(n *yaml.Node) error { return yamljson.DecodeNode(n, p) }
+
+// MarshalYAML implements yaml.Marshaler.
+func (p MarkTheWordsParams) MarshalYAML() (any, error) { return yamljson.Node(p) }
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (p *MarkTheWordsParams) UnmarshalYAML(n *yaml.Node) error { return yamljson.DecodeNode(n, p) }
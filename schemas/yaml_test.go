@@ -0,0 +1,41 @@
+package schemas
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestParamsYAML(t *testing.T) {
+	tf := TrueFalseParams{
+		Question:  "The sky is blue.",
+		Correct:   "true",
+		Behaviour: &TrueFalseBehaviour{EnableRetry: true},
+	}
+	data, err := yaml.Marshal(tf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Correct is a string in content.json and must stay one.
+	if !strings.Contains(string(data), `correct: "true"`) {
+		t.Errorf("string field not quoted:\n%s", data)
+	}
+	var got TrueFalseParams
+	if err := yaml.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, tf) {
+		t.Errorf("round trip = %+v, want %+v", got, tf)
+	}
+
+	var mc MultiChoiceParams
+	src := "question: Pick\nanswers:\n  - text: A\n    correct: true\n"
+	if err := yaml.Unmarshal([]byte(src), &mc); err != nil {
+		t.Fatal(err)
+	}
+	if mc.Question != "Pick" || len(mc.Answers) != 1 || !mc.Answers[0].Correct {
+		t.Errorf("MultiChoiceParams = %+v", mc)
+	}
+}
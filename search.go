@@ -0,0 +1,384 @@
+package h5p
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/grokify/h5p-go/semantics"
+)
+
+// ErrInvalidQuery is returned for a Query that cannot be evaluated, such
+// as one with a malformed path expression.
+var ErrInvalidQuery = errors.New("invalid query")
+
+// AnswerPattern matches questions by how many of their answers are
+// correct, see QuestionStats.
+type AnswerPattern string
+
+// Answer patterns of Query.
+const (
+	// AnswersNoneCorrect matches questions with answers none of which is
+	// correct, usually a mistake.
+	AnswersNoneCorrect AnswerPattern = "none"
+	// AnswersSingleCorrect matches questions with exactly one correct
+	// answer.
+	AnswersSingleCorrect AnswerPattern = "single"
+	// AnswersMultipleCorrect matches questions with more than one correct
+	// answer.
+	AnswersMultipleCorrect AnswerPattern = "multiple"
+	// AnswersAllCorrect matches questions whose answers are all correct,
+	// such as fill in the blanks.
+	AnswersAllCorrect AnswerPattern = "all"
+)
+
+// Query selects questions: the main content and the sub-content whose
+// library is listed in QuestionLibraries, as counted by Stats. Set fields
+// must all match.
+type Query struct {
+	// Text matches questions whose plain text contains it, compared
+	// case-insensitively. The text is that of the text and html fields
+	// of the semantics, see ExtractText, or of all strings outside
+	// settings and UI labels for content types without known semantics.
+	Text string
+	// Library matches the machine name of the question library, e.g.
+	// "H5P.MultiChoice".
+	Library string
+	// Answers matches the correctness of the answers.
+	Answers AnswerPattern
+	// Path is a JSON path expression evaluated on the question object,
+	// which holds library, params and metadata, e.g.
+	// "params.behaviour.singleAnswer" or "params.answers[*].text". It
+	// matches questions where it selects a value. Names may be "*" and
+	// indexes "[*]" to select every member or item.
+	Path string
+	// Value, if set, requires a value selected by Path to equal it, as
+	// compared in JSON.
+	Value any
+}
+
+// QueryHit is a question matching a Query.
+type QueryHit struct {
+	// File is the package file, set by FindInDir.
+	File string `json:"file,omitempty"`
+	// Path is the JSON path of the question in the content params, e.g.
+	// "questions[2]", or "" for the main content.
+	Path    string `json:"path"`
+	Library string `json:"library"`
+	Title   string `json:"title,omitempty"`
+	// Text is the plain text of the question, one line per field.
+	Text string `json:"text"`
+	// Matches are the JSON paths of the values selected by Query.Path,
+	// relative to the question.
+	Matches []string `json:"matches,omitempty"`
+}
+
+// Find returns the questions of the package matching q, in params order.
+func (pkg *H5PPackage) Find(q Query) ([]QueryHit, error) {
+	switch q.Answers {
+	case "", AnswersNoneCorrect, AnswersSingleCorrect, AnswersMultipleCorrect, AnswersAllCorrect:
+	default:
+		return nil, fmt.Errorf("%w: answer pattern %q", ErrInvalidQuery, q.Answers)
+	}
+	var path []pathToken
+	if q.Path != "" {
+		var err error
+		if path, err = parsePathExpr(q.Path); err != nil {
+			return nil, err
+		}
+	}
+	var want any
+	if q.Value != nil {
+		var err error
+		if want, err = toGenericJSON(q.Value); err != nil {
+			return nil, fmt.Errorf("%w: value: %v", ErrInvalidQuery, err)
+		}
+	}
+	def := pkg.PackageDefinition
+	if def == nil || pkg.Content == nil {
+		return nil, nil
+	}
+	params, err := toGenericJSON(pkg.Content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read content params: %w", err)
+	}
+	obj, ok := params.(map[string]any)
+	if !ok {
+		return nil, nil
+	}
+	main := def.MainLibrary
+	if i := slices.IndexFunc(def.PreloadedDependencies, func(d LibraryDependency) bool { return d.MachineName == main }); i >= 0 {
+		main = def.PreloadedDependencies[i].String()
+	}
+
+	f := finder{pkg: pkg, query: q, path: path, value: want, resolve: pkg.textResolver()}
+	f.content("", "", map[string]any{"library": main, "params": obj})
+	return f.hits, nil
+}
+
+// FindInDir returns the questions matching q in the .h5p files below dir,
+// or in dir if it is a file.
+func FindInDir(dir string, q Query) ([]QueryHit, error) {
+	var hits []QueryHit
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || (p != dir && !strings.EqualFold(filepath.Ext(p), ".h5p")) {
+			return err
+		}
+		loader := NewPackageLoader()
+		loader.Lazy = true
+		pkg, err := loader.Load(p)
+		if err != nil {
+			return fmt.Errorf("%s: %w", p, err)
+		}
+		defer pkg.Close()
+		found, err := pkg.Find(q)
+		if err != nil {
+			return fmt.Errorf("%s: %w", p, err)
+		}
+		for _, h := range found {
+			h.File = p
+			hits = append(hits, h)
+		}
+		return nil
+	})
+	return hits, err
+}
+
+type finder struct {
+	pkg     *H5PPackage
+	query   Query
+	path    []pathToken
+	value   any
+	resolve func(string) (semantics.SemanticDefinition, bool)
+	hits    []QueryHit
+}
+
+// content checks the sub-content obj at jsonPath, with its params at
+// paramsPath, and the questions in its params.
+func (f *finder) content(jsonPath, paramsPath string, obj map[string]any) {
+	library, _ := obj["library"].(string)
+	params, _ := obj["params"].(map[string]any)
+	q := Question{Library: library, Params: params}
+	if name := q.MachineName(); QuestionLibraries[name] {
+		if hit, ok := f.match(jsonPath, obj, &q); ok {
+			f.hits = append(f.hits, hit)
+		}
+	}
+	f.walk(paramsPath, params)
+}
+
+func (f *finder) walk(jsonPath string, v any) {
+	switch t := v.(type) {
+	case map[string]any:
+		if library, ok := t["library"].(string); ok && library != "" {
+			if _, ok := t["params"].(map[string]any); ok {
+				f.content(jsonPath, joinJSONPath(jsonPath, "params"), t)
+				return
+			}
+		}
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+		for _, k := range keys {
+			f.walk(joinJSONPath(jsonPath, k), t[k])
+		}
+	case []any:
+		for i, item := range t {
+			f.walk(jsonPath+"["+strconv.Itoa(i)+"]", item)
+		}
+	}
+}
+
+// match reports whether the question q, with the sub-content object obj,
+// matches the query.
+func (f *finder) match(jsonPath string, obj map[string]any, q *Question) (QueryHit, bool) {
+	hit := QueryHit{Path: jsonPath, Library: q.Library}
+	if f.query.Library != "" && q.MachineName() != f.query.Library {
+		return hit, false
+	}
+	if f.query.Answers != "" && !f.query.Answers.matches(q.Stats()) {
+		return hit, false
+	}
+	if f.path != nil {
+		for _, m := range selectPath(obj, "", f.path) {
+			if f.value == nil || reflect.DeepEqual(m.value, f.value) {
+				hit.Matches = append(hit.Matches, m.path)
+			}
+		}
+		if len(hit.Matches) == 0 {
+			return hit, false
+		}
+	}
+	hit.Text = f.text(q)
+	if f.query.Text != "" && !strings.Contains(strings.ToLower(hit.Text), strings.ToLower(f.query.Text)) {
+		return hit, false
+	}
+	if metadata, ok := obj["metadata"].(map[string]any); ok {
+		hit.Title, _ = metadata["title"].(string)
+	}
+	return hit, true
+}
+
+// text returns the plain text of q, one line per field.
+func (f *finder) text(q *Question) string {
+	var lines []string
+	add := func(s string) {
+		if t := plainText(s); t != "" {
+			lines = append(lines, t)
+		}
+	}
+	if sd, ok := f.resolve(q.Library); ok {
+		e := semantics.StringExtractor{Resolve: f.resolve, Library: q.Library}
+		if strs, err := e.Extract(sd, q.Params); err == nil {
+			for _, s := range strs {
+				if searchableString(s) {
+					add(s.Text)
+				}
+			}
+			return strings.Join(lines, "\n")
+		}
+	}
+	var walk func(v any)
+	walk = func(v any) {
+		switch t := v.(type) {
+		case string:
+			add(t)
+		case []any:
+			for _, item := range t {
+				walk(item)
+			}
+		case map[string]any:
+			keys := make([]string, 0, len(t))
+			for k := range t {
+				if !nonTextKeys[k] {
+					keys = append(keys, k)
+				}
+			}
+			slices.Sort(keys)
+			for _, k := range keys {
+				walk(t[k])
+			}
+		}
+	}
+	walk(q.Params)
+	return strings.Join(lines, "\n")
+}
+
+func (p AnswerPattern) matches(s QuestionStats) bool {
+	switch p {
+	case AnswersNoneCorrect:
+		return s.Answers > 0 && s.Correct == 0
+	case AnswersSingleCorrect:
+		return s.Correct == 1
+	case AnswersMultipleCorrect:
+		return s.Correct > 1
+	case AnswersAllCorrect:
+		return s.Answers > 0 && s.Correct == s.Answers
+	}
+	return false
+}
+
+// pathToken is a step of a path expression: a member name, an index, or
+// every member or item if wildcard is set.
+type pathToken struct {
+	name     string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+// parsePathExpr parses a path expression such as "params.answers[*].text",
+// optionally starting with "$.".
+func parsePathExpr(expr string) ([]pathToken, error) {
+	s := strings.TrimPrefix(strings.TrimPrefix(expr, "$"), ".")
+	var tokens []pathToken
+	for s != "" {
+		switch {
+		case s[0] == '[':
+			end := strings.IndexByte(s, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("%w: unclosed bracket in path %q", ErrInvalidQuery, expr)
+			}
+			inner := s[1:end]
+			if inner == "*" {
+				tokens = append(tokens, pathToken{isIndex: true, wildcard: true})
+			} else {
+				i, err := strconv.Atoi(inner)
+				if err != nil || i < 0 {
+					return nil, fmt.Errorf("%w: invalid index %q in path %q", ErrInvalidQuery, inner, expr)
+				}
+				tokens = append(tokens, pathToken{isIndex: true, index: i})
+			}
+			s = s[end+1:]
+		default:
+			end := strings.IndexAny(s, ".[")
+			if end < 0 {
+				end = len(s)
+			}
+			name := s[:end]
+			if name == "" {
+				return nil, fmt.Errorf("%w: empty name in path %q", ErrInvalidQuery, expr)
+			}
+			tokens = append(tokens, pathToken{name: name, wildcard: name == "*"})
+			s = s[end:]
+		}
+		if strings.HasPrefix(s, ".") {
+			s = s[1:]
+			if s == "" || s[0] == '.' || s[0] == '[' {
+				return nil, fmt.Errorf("%w: empty name in path %q", ErrInvalidQuery, expr)
+			}
+		}
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("%w: empty path", ErrInvalidQuery)
+	}
+	return tokens, nil
+}
+
+type pathMatch struct {
+	path  string
+	value any
+}
+
+// selectPath returns the values of generic JSON v selected by tokens, with
+// their paths below jsonPath.
+func selectPath(v any, jsonPath string, tokens []pathToken) []pathMatch {
+	if len(tokens) == 0 {
+		return []pathMatch{{jsonPath, v}}
+	}
+	t, rest := tokens[0], tokens[1:]
+	var out []pathMatch
+	switch {
+	case t.isIndex:
+		list, _ := v.([]any)
+		for i, item := range list {
+			if t.wildcard || i == t.index {
+				out = append(out, selectPath(item, jsonPath+"["+strconv.Itoa(i)+"]", rest)...)
+			}
+		}
+	default:
+		obj, _ := v.(map[string]any)
+		if !t.wildcard {
+			if child, ok := obj[t.name]; ok {
+				out = selectPath(child, joinJSONPath(jsonPath, t.name), rest)
+			}
+			break
+		}
+		keys := make([]string, 0, len(obj))
+		for k := range obj {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+		for _, k := range keys {
+			out = append(out, selectPath(obj[k], joinJSONPath(jsonPath, k), rest)...)
+		}
+	}
+	return out
+}
@@ -0,0 +1,128 @@
+package h5p
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/grokify/h5p-go/schemas"
+)
+
+func newSearchTestPackage(t *testing.T) *H5PPackage {
+	t.Helper()
+	qs, err := NewQuestionSetBuilder().
+		SetTitle("Capitals").
+		AddMultipleChoiceQuestion("<p>Capital of <em>France</em>?</p>", []Answer{CreateAnswer("Paris", true), CreateAnswer("Lyon", false)}).
+		AddMultipleChoiceQuestion("Which are in Europe?", []Answer{CreateAnswer("Spain", true), CreateAnswer("Italy", true), CreateAnswer("Peru", false)}).
+		AddTrueFalseQuestion("Berlin is in France.", false, nil).
+		AddMultipleChoiceQuestion("Capital of Chile?", []Answer{CreateAnswer("Lima", true), CreateAnswer("Quito", false)}).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg, err := BuildQuestionSetPackage(qs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Validation refuses a question without correct answers, so mark the
+	// last one wrong in the package.
+	qs, err = pkg.QuestionSet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var params schemas.MultiChoiceParams
+	if err := qs.Questions[3].DecodeParams(&params); err != nil {
+		t.Fatal(err)
+	}
+	params.Answers[0].Correct = false
+	qs.Questions[3].Params = &params
+	pkg.SetContent(&Content{Params: qs})
+	return pkg
+}
+
+func hitPaths(hits []QueryHit) []string {
+	var paths []string
+	for _, h := range hits {
+		paths = append(paths, h.Path)
+	}
+	return paths
+}
+
+func TestFind(t *testing.T) {
+	pkg := newSearchTestPackage(t)
+	for _, tc := range []struct {
+		name  string
+		query Query
+		want  []string
+	}{
+		{"text", Query{Text: "france"}, []string{"questions[0]", "questions[2]"}},
+		{"text in answers", Query{Text: "lima"}, []string{"questions[3]"}},
+		{"library", Query{Library: "H5P.TrueFalse"}, []string{"questions[2]"}},
+		{"single correct", Query{Answers: AnswersSingleCorrect, Library: "H5P.MultiChoice"}, []string{"questions[0]"}},
+		{"multiple correct", Query{Answers: AnswersMultipleCorrect}, []string{"questions[1]"}},
+		{"none correct", Query{Answers: AnswersNoneCorrect}, []string{"questions[3]"}},
+		{"path", Query{Path: "params.answers[2]"}, []string{"questions[1]"}},
+		{"path value", Query{Path: "$.params.answers[*].text", Value: "Italy"}, []string{"questions[1]"}},
+		{"combined", Query{Text: "capital", Answers: AnswersNoneCorrect}, []string{"questions[3]"}},
+		{"no match", Query{Text: "Tokyo"}, nil},
+	} {
+		hits, err := pkg.Find(tc.query)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if got := hitPaths(hits); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.want, got)
+		}
+	}
+
+	hits, err := pkg.Find(Query{Path: "params.answers[*].correct", Value: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hits) != 2 || !reflect.DeepEqual(hits[1].Matches, []string{"params.answers[0].correct", "params.answers[1].correct"}) {
+		t.Errorf("Unexpected hits %+v", hits)
+	}
+	if h := hits[0]; h.Text != "Capital of France?\nParis\nLyon" || h.Title == "" || h.Library != "H5P.MultiChoice 1.16" {
+		t.Errorf("Unexpected hit %+v", h)
+	}
+
+	for _, expr := range []string{"params..answers", "params.answers[x]", "params.answers[1", "$"} {
+		if _, err := pkg.Find(Query{Path: expr}); !errors.Is(err, ErrInvalidQuery) {
+			t.Errorf("%q: expected ErrInvalidQuery, got %v", expr, err)
+		}
+	}
+	if _, err := pkg.Find(Query{Answers: "some"}); !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("Expected ErrInvalidQuery for an unknown answer pattern, got %v", err)
+	}
+}
+
+func TestFindInDir(t *testing.T) {
+	dir := t.TempDir()
+	pkg := newSearchTestPackage(t)
+	if err := os.MkdirAll(filepath.Join(dir, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"a.h5p", "sub/b.H5P"} {
+		if err := pkg.CreateZipFile(filepath.Join(dir, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("France"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	hits, err := FindInDir(dir, Query{Library: "H5P.TrueFalse"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hits) != 2 || hits[0].File != filepath.Join(dir, "a.h5p") || hits[1].File != filepath.Join(dir, "sub", "b.H5P") {
+		t.Errorf("Unexpected hits %+v", hits)
+	}
+	hits, err = FindInDir(filepath.Join(dir, "a.h5p"), Query{Text: "Chile"})
+	if err != nil || len(hits) != 1 {
+		t.Errorf("Expected a hit in a single file, got %+v: %v", hits, err)
+	}
+}
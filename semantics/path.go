@@ -0,0 +1,81 @@
+package semantics
+
+import "strings"
+
+// PathSeparator separates field names in a dot-path such as "behaviour.passPercentage".
+const PathSeparator = "."
+
+// FlatField is a leaf field together with its dot-path from the semantics root.
+type FlatField struct {
+	Path  string
+	Field *Field
+}
+
+// FieldByPath returns the field addressed by a dot-path such as
+// "behaviour.passPercentage". List fields are traversed transparently, so
+// "answers.text" addresses the text field of each answer group, matching the
+// layout of content params. Returns nil if no field matches.
+func (sd SemanticDefinition) FieldByPath(path string) *Field {
+	if path == "" {
+		return nil
+	}
+	fields := []Field(sd)
+	var current *Field
+	for _, name := range strings.Split(path, PathSeparator) {
+		current = findField(fields, name)
+		if current == nil {
+			return nil
+		}
+		fields = current.children()
+	}
+	return current
+}
+
+// Flatten returns all leaf fields (fields that are neither groups nor lists of
+// groups) with their dot-paths, in semantics order.
+func (sd SemanticDefinition) Flatten() []FlatField {
+	var out []FlatField
+	flattenFields([]Field(sd), "", &out)
+	return out
+}
+
+// children returns the fields nested directly below f as seen from content
+// params: group fields, or the fields of a list's item group.
+func (f *Field) children() []Field {
+	switch f.Type {
+	case "group":
+		return f.Fields
+	case "list":
+		if f.Field != nil && f.Field.Type == "group" {
+			return f.Field.Fields
+		}
+	}
+	return nil
+}
+
+func findField(fields []Field, name string) *Field {
+	for i := range fields {
+		if fields[i].Name == name {
+			return &fields[i]
+		}
+	}
+	return nil
+}
+
+func flattenFields(fields []Field, prefix string, out *[]FlatField) {
+	for i := range fields {
+		f := &fields[i]
+		path := f.Name
+		if prefix != "" {
+			path = prefix + PathSeparator + f.Name
+		}
+		switch {
+		case f.Type == "group":
+			flattenFields(f.Fields, path, out)
+		case f.Type == "list" && f.Field != nil && f.Field.Type == "group":
+			flattenFields(f.Field.Fields, path, out)
+		default:
+			*out = append(*out, FlatField{Path: path, Field: f})
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package semantics
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+)
+
+func loadMultiChoiceSemantics(t *testing.T) SemanticDefinition {
+	t.Helper()
+	data, err := os.ReadFile("../schemas/multichoice_semantics.json")
+	if err != nil {
+		t.Fatalf("Failed to read multichoice_semantics.json: %v", err)
+	}
+	var sd SemanticDefinition
+	if err := json.Unmarshal(data, &sd); err != nil {
+		t.Fatalf("Failed to parse semantics: %v", err)
+	}
+	return sd
+}
+
+func TestFieldByPath(t *testing.T) {
+	sd := loadMultiChoiceSemantics(t)
+
+	var fieldByPathTests = []struct {
+		path     string
+		wantType string
+	}{
+		{"question", "text"},
+		{"behaviour.passPercentage", "number"},
+		{"answers", "list"},
+		{"answers.text", "text"},
+		{"answers.tipsAndFeedback.tip", "text"},
+		{"overallFeedback.overallFeedback.from", "number"},
+	}
+
+	for _, tt := range fieldByPathTests {
+		f := sd.FieldByPath(tt.path)
+		if f == nil {
+			t.Errorf("FieldByPath(%q) returned nil", tt.path)
+			continue
+		}
+		if f.Type != tt.wantType {
+			t.Errorf("FieldByPath(%q) type: expected '%s', got '%s'", tt.path, tt.wantType, f.Type)
+		}
+	}
+
+	for _, path := range []string{"", "missing", "behaviour.missing", "question.text"} {
+		if f := sd.FieldByPath(path); f != nil {
+			t.Errorf("FieldByPath(%q) expected nil, got field '%s'", path, f.Name)
+		}
+	}
+}
+
+func TestFlatten(t *testing.T) {
+	sd := loadMultiChoiceSemantics(t)
+
+	flat := sd.Flatten()
+	paths := map[string]bool{}
+	for _, ff := range flat {
+		if ff.Field == nil {
+			t.Fatalf("Flatten returned nil field for path '%s'", ff.Path)
+		}
+		if ff.Field.Type == "group" {
+			t.Errorf("Flatten returned group field at path '%s'", ff.Path)
+		}
+		paths[ff.Path] = true
+	}
+
+	for _, want := range []string{"question", "answers.correct", "behaviour.passPercentage", "UI.checkAnswerButton", "media.type"} {
+		if !paths[want] {
+			t.Errorf("Flatten missing path '%s'", want)
+		}
+	}
+
+	for _, ff := range flat {
+		if got := sd.FieldByPath(ff.Path); got != ff.Field {
+			t.Errorf("FieldByPath(%q) does not match flattened field", ff.Path)
+		}
+	}
+}
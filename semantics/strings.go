@@ -0,0 +1,157 @@
+package semantics
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/grokify/h5p-go/schemas"
+)
+
+// TranslatableString is a text of content params for translators.
+type TranslatableString struct {
+	// Key is the path of the text in the params, e.g.
+	// "questions[0].params.answers[1].text". Keys stay the same as long
+	// as the structure of the params does.
+	Key  string `json:"key"`
+	Text string `json:"text"`
+	// Label is the label of the semantics field, as context.
+	Label string `json:"label,omitempty"`
+	// Library is the library of the content or sub-content the text is
+	// part of, if known.
+	Library string `json:"library,omitempty"`
+	// HTML reports markup, from html fields or text fields edited with
+	// the html widget, whose tags are to be kept.
+	HTML bool `json:"html,omitempty"`
+	// Common reports texts of common fields, such as button labels, that
+	// the H5P editor shares by all content of a library.
+	Common bool `json:"common,omitempty"`
+}
+
+// StringExtractor finds the translatable strings of content params: the
+// values of text and html fields of the semantics.
+type StringExtractor struct {
+	// Resolve returns the semantics of a library string such as
+	// "H5P.Image 1.1" so the texts of sub-content are found too. The texts
+	// of sub-content of libraries it does not know are left out.
+	Resolve func(library string) (SemanticDefinition, bool)
+	// Library is the library of the params, reported by the strings
+	// outside sub-content.
+	Library string
+}
+
+// ExtractStrings returns the translatable strings of params without
+// following library fields into sub-content. See StringExtractor.
+func (sd SemanticDefinition) ExtractStrings(params any) ([]TranslatableString, error) {
+	return StringExtractor{}.Extract(sd, params)
+}
+
+// Extract returns the non-empty translatable strings of params, generic
+// JSON or a typed params struct, in semantics order.
+func (e StringExtractor) Extract(sd SemanticDefinition, params any) ([]TranslatableString, error) {
+	obj, err := genericObject(params, false)
+	if err != nil {
+		return nil, err
+	}
+	var out []TranslatableString
+	e.fields("", e.Library, sd, obj, 0, func(s TranslatableString, _ func(string)) {
+		out = append(out, s)
+	})
+	return out, nil
+}
+
+// Apply returns a copy of params as generic JSON with the strings keyed in
+// translations, as returned by Extract, replaced by their translation.
+// Keys of other values are ignored, so params can only change where
+// Extract finds text.
+func (e StringExtractor) Apply(sd SemanticDefinition, params any, translations map[string]string) (map[string]any, error) {
+	obj, err := genericObject(params, true)
+	if err != nil {
+		return nil, err
+	}
+	e.fields("", e.Library, sd, obj, 0, func(s TranslatableString, set func(string)) {
+		if t, ok := translations[s.Key]; ok {
+			set(t)
+		}
+	})
+	return obj, nil
+}
+
+// genericObject returns params as a generic JSON object, copied if
+// requested.
+func genericObject(params any, clone bool) (map[string]any, error) {
+	if obj, ok := params.(map[string]any); ok && !clone {
+		return obj, nil
+	}
+	data, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("params cannot be encoded as JSON: %w", err)
+	}
+	var obj map[string]any
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, fmt.Errorf("params must be an object: %w", err)
+	}
+	return obj, nil
+}
+
+// stringFunc receives a translatable string and a function replacing it.
+type stringFunc func(s TranslatableString, set func(string))
+
+func (e StringExtractor) fields(path, library string, fields []Field, obj map[string]any, depth int, fn stringFunc) {
+	for i := range fields {
+		f := &fields[i]
+		if value, ok := obj[f.Name]; ok && value != nil {
+			e.field(schemas.JoinPath(path, f.Name), library, f, value, func(v any) { obj[f.Name] = v }, depth, fn)
+		}
+	}
+}
+
+func (e StringExtractor) field(path, library string, f *Field, value any, set func(any), depth int, fn stringFunc) {
+	switch f.Type {
+	case "text", "html":
+		if s, ok := value.(string); ok && s != "" {
+			fn(TranslatableString{
+				Key:     path,
+				Text:    s,
+				Label:   f.Label,
+				Library: library,
+				HTML:    f.Type == "html" || f.Widget == "html",
+				Common:  f.Common,
+			}, func(s string) { set(s) })
+		}
+
+	case "list":
+		items, ok := value.([]any)
+		if !ok || f.Field == nil {
+			return
+		}
+		for i, item := range items {
+			if item != nil {
+				e.field(schemas.IndexPath(path, i), library, f.Field, item, func(v any) { items[i] = v }, depth, fn)
+			}
+		}
+
+	case "group":
+		obj, ok := value.(map[string]any)
+		switch {
+		case ok:
+			e.fields(path, library, f.Fields, obj, depth, fn)
+		case len(f.Fields) == 1:
+			// H5P stores the value of a group with a single field directly.
+			e.field(path, library, &f.Fields[0], value, set, depth, fn)
+		}
+
+	case "library":
+		obj, ok := value.(map[string]any)
+		if !ok || e.Resolve == nil || depth >= maxDepth {
+			return
+		}
+		sub, _ := obj["library"].(string)
+		params, ok := obj["params"].(map[string]any)
+		if !ok {
+			return
+		}
+		if sd, ok := e.Resolve(sub); ok {
+			e.fields(schemas.JoinPath(path, "params"), sub, sd, params, depth+1, fn)
+		}
+	}
+}
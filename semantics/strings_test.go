@@ -0,0 +1,91 @@
+package semantics
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+const stringsSemantics = `[
+  {"name": "question", "type": "text", "widget": "html", "label": "Question"},
+  {"name": "answers", "type": "list", "field": {"name": "answer", "type": "group", "fields": [
+    {"name": "text", "type": "text", "label": "Text"},
+    {"name": "correct", "type": "boolean"}]}},
+  {"name": "mode", "type": "select", "options": [{"value": "a", "label": "A"}]},
+  {"name": "single", "type": "group", "fields": [{"name": "hint", "type": "html", "label": "Hint"}]},
+  {"name": "media", "type": "library", "options": ["H5P.Image 1.1"]},
+  {"name": "UI", "type": "group", "common": true, "fields": [
+    {"name": "check", "type": "text", "label": "Check", "common": true}]}
+]`
+
+const stringsParams = `{
+  "question": "<p>Capital of France?</p>",
+  "answers": [{"text": "Paris", "correct": true}, {"text": "", "correct": false}],
+  "mode": "a",
+  "single": "<p>Think of the Eiffel tower</p>",
+  "media": {"library": "H5P.Image 1.1", "params": {"alt": "Map"}, "subContentId": "1"},
+  "UI": {"check": "Check"}
+}`
+
+func TestStringExtractor(t *testing.T) {
+	var sd SemanticDefinition
+	if err := json.Unmarshal([]byte(stringsSemantics), &sd); err != nil {
+		t.Fatal(err)
+	}
+	var params map[string]any
+	if err := json.Unmarshal([]byte(stringsParams), &params); err != nil {
+		t.Fatal(err)
+	}
+	e := StringExtractor{
+		Library: "H5P.MultiChoice 1.16",
+		Resolve: func(library string) (SemanticDefinition, bool) {
+			return SemanticDefinition{{Name: "alt", Type: "text", Label: "Alternative text"}}, library == "H5P.Image 1.1"
+		},
+	}
+
+	got, err := e.Extract(sd, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantStrings := []TranslatableString{
+		{Key: "question", Text: "<p>Capital of France?</p>", Label: "Question", Library: "H5P.MultiChoice 1.16", HTML: true},
+		{Key: "answers[0].text", Text: "Paris", Label: "Text", Library: "H5P.MultiChoice 1.16"},
+		{Key: "single", Text: "<p>Think of the Eiffel tower</p>", Label: "Hint", Library: "H5P.MultiChoice 1.16", HTML: true},
+		{Key: "media.params.alt", Text: "Map", Label: "Alternative text", Library: "H5P.Image 1.1"},
+		{Key: "UI.check", Text: "Check", Label: "Check", Library: "H5P.MultiChoice 1.16", Common: true},
+	}
+	if !reflect.DeepEqual(got, wantStrings) {
+		t.Errorf("Expected %+v, got %+v", wantStrings, got)
+	}
+	if without, _ := sd.ExtractStrings(params); len(without) != 4 {
+		t.Errorf("Expected sub-content skipped without Resolve, got %+v", without)
+	}
+
+	translated, err := e.Apply(sd, params, map[string]string{
+		"question":         "<p>Hauptstadt von Frankreich?</p>",
+		"single":           "<p>Denk an den Eiffelturm</p>",
+		"media.params.alt": "Karte",
+		"UI.check":         "Prüfen",
+		"mode":             "b",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want map[string]any
+	if err := json.Unmarshal([]byte(`{
+  "question": "<p>Hauptstadt von Frankreich?</p>",
+  "answers": [{"text": "Paris", "correct": true}, {"text": "", "correct": false}],
+  "mode": "a",
+  "single": "<p>Denk an den Eiffelturm</p>",
+  "media": {"library": "H5P.Image 1.1", "params": {"alt": "Karte"}, "subContentId": "1"},
+  "UI": {"check": "Prüfen"}
+}`), &want); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(translated, want) {
+		t.Errorf("Expected %v, got %v", want, translated)
+	}
+	if params["question"] != "<p>Capital of France?</p>" || params["media"].(map[string]any)["params"].(map[string]any)["alt"] != "Map" {
+		t.Error("Expected the params left unchanged")
+	}
+}
@@ -0,0 +1,273 @@
+package semantics
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/grokify/h5p-go/schemas"
+)
+
+// CodeUnknownField reports params that no semantics field defines.
+const CodeUnknownField = "unknown_field"
+
+// maxDepth limits how deep library fields are followed into sub-content.
+const maxDepth = 32
+
+// Params keys H5P adds next to the semantics fields of sub-content.
+var libraryKeys = []string{"library", "params", "subContentId", "metadata"}
+
+// Validator checks content params against semantics, the way H5P core's
+// content validator does before saving content.
+type Validator struct {
+	// Resolve returns the semantics of a library string such as
+	// "H5P.Image 1.1" so the params of library fields are checked too.
+	// Sub-content of libraries it does not know is only checked for being
+	// one of the allowed options.
+	Resolve func(library string) (SemanticDefinition, bool)
+}
+
+// Validate checks params against sd without following library fields into
+// sub-content. See Validator.
+func (sd SemanticDefinition) Validate(params any) *schemas.ValidationResult {
+	return Validator{}.Validate(sd, params)
+}
+
+// Validate checks params, either generic JSON values or a typed params
+// struct, against sd. Values of the wrong type, text longer than
+// maxLength, numbers and lists out of range, unknown select values and
+// libraries that are not allowed are errors. Missing mandatory fields,
+// which H5P core tolerates, and params without a field are warnings.
+//
+// A zero minValue, maxValue, min or max is read as not set, as Field
+// cannot tell it from an absent one.
+func (v Validator) Validate(sd SemanticDefinition, params any) *schemas.ValidationResult {
+	r := &schemas.ValidationResult{}
+	generic, err := toGeneric(params)
+	if err != nil {
+		r.AddError("", schemas.CodeInvalidValue, "params cannot be encoded as JSON: %v", err)
+		return r
+	}
+	obj, ok := generic.(map[string]any)
+	if !ok {
+		r.AddError("", schemas.CodeInvalidValue, "params must be an object, got %s", typeName(generic))
+		return r
+	}
+	v.fields(r, "", sd, obj, 0)
+	return r
+}
+
+func toGeneric(v any) (any, error) {
+	switch v.(type) {
+	case map[string]any, []any, string, float64, bool, nil:
+		return v, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var out any
+	err = json.Unmarshal(data, &out)
+	return out, err
+}
+
+func (v Validator) fields(r *schemas.ValidationResult, path string, fields []Field, obj map[string]any, depth int) {
+	known := make(map[string]bool, len(fields))
+	for i := range fields {
+		f := &fields[i]
+		known[f.Name] = true
+		fieldPath := schemas.JoinPath(path, f.Name)
+		value, ok := obj[f.Name]
+		if !ok || value == nil {
+			if !f.Optional && f.Default == nil && f.ShowWhen == nil {
+				r.AddWarning(fieldPath, schemas.CodeRequired, "mandatory field %s has no value", f.Name)
+			}
+			continue
+		}
+		v.field(r, fieldPath, f, value, depth)
+	}
+
+	var unknown []string
+	for name := range obj {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	for _, name := range unknown {
+		r.AddWarning(schemas.JoinPath(path, name), CodeUnknownField, "field is not defined in the semantics")
+	}
+}
+
+func (v Validator) field(r *schemas.ValidationResult, path string, f *Field, value any, depth int) {
+	switch f.Type {
+	case "text", "html":
+		s, ok := value.(string)
+		if !ok {
+			r.AddError(path, schemas.CodeInvalidValue, "expected text, got %s", typeName(value))
+			return
+		}
+		if f.MaxLength > 0 && utf8.RuneCountInString(s) > f.MaxLength {
+			r.AddError(path, schemas.CodeOutOfRange, "text is longer than %d characters", f.MaxLength)
+		}
+
+	case "number":
+		n, ok := value.(float64)
+		if !ok {
+			r.AddError(path, schemas.CodeInvalidValue, "expected a number, got %s", typeName(value))
+			return
+		}
+		if f.MinValue != 0 && n < float64(f.MinValue) {
+			r.AddError(path, schemas.CodeOutOfRange, "%v is less than %d", n, f.MinValue)
+		}
+		if f.MaxValue != 0 && n > float64(f.MaxValue) {
+			r.AddError(path, schemas.CodeOutOfRange, "%v is greater than %d", n, f.MaxValue)
+		}
+
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			r.AddError(path, schemas.CodeInvalidValue, "expected a boolean, got %s", typeName(value))
+		}
+
+	case "select":
+		var s string
+		switch value := value.(type) {
+		case string:
+			s = value
+		case float64:
+			s = fmt.Sprint(value)
+		default:
+			r.AddError(path, schemas.CodeInvalidValue, "expected a select value, got %s", typeName(value))
+			return
+		}
+		options := f.GetSelectOptions()
+		if len(options) > 0 && !slices.ContainsFunc(options, func(o SelectOption) bool { return o.Value == s }) {
+			r.AddError(path, schemas.CodeInvalidValue, "%q is not one of the select options", s)
+		}
+
+	case "list":
+		items, ok := value.([]any)
+		if !ok {
+			r.AddError(path, schemas.CodeInvalidValue, "expected a list, got %s", typeName(value))
+			return
+		}
+		if f.Min > 0 && len(items) < f.Min {
+			r.AddError(path, schemas.CodeOutOfRange, "list has %d items, at least %d required", len(items), f.Min)
+		}
+		if f.Max > 0 && len(items) > f.Max {
+			r.AddError(path, schemas.CodeOutOfRange, "list has %d items, at most %d allowed", len(items), f.Max)
+		}
+		if f.Field == nil {
+			return
+		}
+		for i, item := range items {
+			if item != nil {
+				v.field(r, schemas.IndexPath(path, i), f.Field, item, depth)
+			}
+		}
+
+	case "group":
+		obj, ok := value.(map[string]any)
+		switch {
+		case ok:
+			v.fields(r, path, f.Fields, obj, depth)
+		case len(f.Fields) == 1:
+			// H5P stores the value of a group with a single field directly.
+			v.field(r, path, &f.Fields[0], value, depth)
+		default:
+			r.AddError(path, schemas.CodeInvalidValue, "expected a group, got %s", typeName(value))
+		}
+
+	case "library":
+		v.library(r, path, f, value, depth)
+
+	case "image", "file":
+		v.file(r, path, value)
+
+	case "video", "audio":
+		files, ok := value.([]any)
+		if !ok {
+			r.AddError(path, schemas.CodeInvalidValue, "expected a list of files, got %s", typeName(value))
+			return
+		}
+		for i, file := range files {
+			v.file(r, schemas.IndexPath(path, i), file)
+		}
+	}
+}
+
+func (v Validator) file(r *schemas.ValidationResult, path string, value any) {
+	obj, ok := value.(map[string]any)
+	if !ok {
+		r.AddError(path, schemas.CodeInvalidValue, "expected a file, got %s", typeName(value))
+		return
+	}
+	if p, ok := obj["path"].(string); !ok || p == "" {
+		r.AddError(schemas.JoinPath(path, "path"), schemas.CodeRequired, "file path is required")
+	}
+}
+
+func (v Validator) library(r *schemas.ValidationResult, path string, f *Field, value any, depth int) {
+	obj, ok := value.(map[string]any)
+	if !ok {
+		r.AddError(path, schemas.CodeInvalidValue, "expected sub-content, got %s", typeName(value))
+		return
+	}
+	library, _ := obj["library"].(string)
+	if library == "" {
+		r.AddError(schemas.JoinPath(path, "library"), schemas.CodeRequired, "library is required")
+		return
+	}
+	if options := f.GetLibraryOptions(); len(options) > 0 && !slices.Contains(options, library) {
+		name, _, _ := strings.Cut(library, " ")
+		if slices.ContainsFunc(options, func(o string) bool { return strings.HasPrefix(o, name+" ") }) {
+			r.AddWarning(schemas.JoinPath(path, "library"), schemas.CodeInvalidValue, "%s is not an allowed version", library)
+		} else {
+			r.AddError(schemas.JoinPath(path, "library"), schemas.CodeInvalidValue, "%s is not an allowed library", library)
+			return
+		}
+	}
+	for name := range obj {
+		if !slices.Contains(libraryKeys, name) {
+			r.AddWarning(schemas.JoinPath(path, name), CodeUnknownField, "field is not defined for sub-content")
+		}
+	}
+
+	if v.Resolve == nil || depth >= maxDepth {
+		return
+	}
+	sd, ok := v.Resolve(library)
+	if !ok {
+		return
+	}
+	paramsPath := schemas.JoinPath(path, "params")
+	switch params := obj["params"].(type) {
+	case map[string]any:
+		v.fields(r, paramsPath, sd, params, depth+1)
+	case nil:
+		r.AddError(paramsPath, schemas.CodeRequired, "params are required")
+	default:
+		r.AddError(paramsPath, schemas.CodeInvalidValue, "expected params, got %s", typeName(params))
+	}
+}
+
+func typeName(v any) string {
+	switch v.(type) {
+	case string:
+		return "text"
+	case float64:
+		return "a number"
+	case bool:
+		return "a boolean"
+	case []any:
+		return "a list"
+	case map[string]any:
+		return "an object"
+	case nil:
+		return "null"
+	}
+	return fmt.Sprintf("%T", v)
+}
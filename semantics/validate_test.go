@@ -0,0 +1,104 @@
+package semantics
+
+import (
+	"encoding/json"
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/grokify/h5p-go/schemas"
+)
+
+func TestValidateContent(t *testing.T) {
+	sd := loadMultiChoiceSemantics(t)
+	data, err := os.ReadFile("../testdata/content.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var params map[string]any
+	if err := json.Unmarshal(data, &params); err != nil {
+		t.Fatal(err)
+	}
+	r := sd.Validate(params)
+	for _, p := range r.Problems {
+		t.Logf("%s", p)
+	}
+	if !r.Valid() {
+		t.Errorf("testdata/content.json: %v", r.Err())
+	}
+}
+
+const validatorSemantics = `[
+  {"name": "title", "type": "text", "maxLength": 5},
+  {"name": "count", "type": "number", "minValue": 1, "maxValue": 10},
+  {"name": "enabled", "type": "boolean", "default": true},
+  {"name": "mode", "type": "select", "options": [{"value": "a", "label": "A"}, {"value": "b", "label": "B"}]},
+  {"name": "items", "type": "list", "min": 1, "field": {"name": "item", "type": "text"}},
+  {"name": "single", "type": "group", "optional": true, "fields": [{"name": "text", "type": "text"}]},
+  {"name": "media", "type": "library", "optional": true, "options": ["H5P.Image 1.1", "H5P.Video 1.6"]},
+  {"name": "image", "type": "image", "optional": true}
+]`
+
+func TestValidator(t *testing.T) {
+	var sd SemanticDefinition
+	if err := json.Unmarshal([]byte(validatorSemantics), &sd); err != nil {
+		t.Fatal(err)
+	}
+	image := SemanticDefinition{{Name: "alt", Type: "text"}}
+	v := Validator{Resolve: func(library string) (SemanticDefinition, bool) {
+		return image, library == "H5P.Image 1.1"
+	}}
+
+	var validatorTests = []struct {
+		name   string
+		params string
+		want   []string // "severity path code"
+	}{
+		{"valid", `{"title": "Hi", "count": 3, "mode": "a", "items": ["x"], "single": "one",
+			"media": {"library": "H5P.Image 1.1", "params": {"alt": "Cat"}, "subContentId": "1"}, "image": {"path": "images/a.png"}}`, nil},
+		{"missing", `{"items": ["x"]}`, []string{
+			"warning title required", "warning count required", "warning mode required"}},
+		{"types", `{"title": 1, "count": "3", "enabled": "yes", "mode": "c", "items": [2], "single": {"text": true}}`, []string{
+			"error title invalid_value", "error count invalid_value", "error enabled invalid_value",
+			"error mode invalid_value", "error items[0] invalid_value", "error single.text invalid_value"}},
+		{"ranges", `{"title": "Hello!", "count": 11, "mode": "b", "items": []}`, []string{
+			"error title out_of_range", "error count out_of_range", "error items out_of_range"}},
+		{"unknown", `{"title": "a", "count": 1, "mode": "a", "items": ["x"], "extra": 1}`, []string{
+			"warning extra unknown_field"}},
+		{"library", `{"title": "a", "count": 1, "mode": "a", "items": ["x"],
+			"media": {"library": "H5P.Image 1.1", "params": {"alt": 5}}, "image": {}}`, []string{
+			"error media.params.alt invalid_value", "error image.path required"}},
+		{"library version", `{"title": "a", "count": 1, "mode": "a", "items": ["x"], "media": {"library": "H5P.Video 1.5"}}`, []string{
+			"warning media.library invalid_value"}},
+		{"library not allowed", `{"title": "a", "count": 1, "mode": "a", "items": ["x"], "media": {"library": "H5P.Audio 1.5", "params": 1}}`, []string{
+			"error media.library invalid_value"}},
+	}
+
+	for _, tt := range validatorTests {
+		var params map[string]any
+		if err := json.Unmarshal([]byte(tt.params), &params); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		var got []string
+		for _, p := range v.Validate(sd, params).Problems {
+			got = append(got, string(p.Severity)+" "+p.Path+" "+p.Code)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestValidateTypedParams(t *testing.T) {
+	sd := loadMultiChoiceSemantics(t)
+	params := &schemas.MultiChoiceParams{
+		Question: "2+2?",
+		Answers:  []schemas.AnswerOption{{Text: "4", Correct: true}, {Text: "5"}},
+	}
+	if r := sd.Validate(params); !r.Valid() {
+		t.Errorf("typed params: %v", r.Err())
+	}
+	if r := sd.Validate([]any{}); r.Valid() {
+		t.Error("Expected an error for params that are not an object")
+	}
+}
@@ -0,0 +1,322 @@
+package server
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+
+	h5p "github.com/grokify/h5p-go"
+	"github.com/grokify/h5p-go/storage"
+)
+
+// PackageSource loads packages by content ID. storage.PackageStore
+// implements it.
+type PackageSource interface {
+	LoadPackage(ctx context.Context, id string) (*h5p.H5PPackage, error)
+}
+
+// PackageCache extracts packages to disk on first use and serves their
+// files from there, so a package is not read from its source for every
+// asset request. Once the extracted files take more than MaxBytes, the
+// least recently used packages are removed; files being served are only
+// deleted once closed. Packages are cached per tenant, see
+// storage.WithTenant, and loaded from Source with the tenant of the
+// request.
+type PackageCache struct {
+	Source PackageSource
+	// Dir holds the extracted packages, one temporary folder each.
+	Dir string
+	// MaxBytes is the size the cache is trimmed to. The most recently
+	// used package is kept even if it is larger. 0 means no limit.
+	MaxBytes int64
+
+	mu      sync.Mutex
+	entries map[cacheKey]*cacheEntry
+	lru     list.List // of *cacheEntry, most recently used first
+	size    int64
+}
+
+// cacheKey identifies a package of a tenant.
+type cacheKey struct {
+	tenant, id string
+}
+
+type cacheEntry struct {
+	key   cacheKey
+	dir   string
+	size  int64
+	ready chan struct{} // closed once extracted
+	err   error
+	elem  *list.Element
+	// refs counts open files and requests waiting for the extraction;
+	// an evicted entry's folder is removed when it drops to 0.
+	refs    int
+	evicted bool
+}
+
+func NewPackageCache(src PackageSource, dir string, maxBytes int64) *PackageCache {
+	return &PackageCache{Source: src, Dir: dir, MaxBytes: maxBytes}
+}
+
+// Open opens a file of the extracted package by its path in the archive,
+// such as "content/images/photo.jpg", extracting the package first if it
+// is not cached. The returned file also implements io.Seeker.
+func (c *PackageCache) Open(ctx context.Context, id, name string) (fs.File, error) {
+	return c.open(ctx, id, name)
+}
+
+func (c *PackageCache) open(ctx context.Context, id, name string) (*cachedFile, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	e, err := c.acquire(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	// OpenInRoot also refuses symlinks escaping the package folder.
+	f, err := os.OpenInRoot(e.dir, filepath.FromSlash(name))
+	if err != nil {
+		c.release(e)
+		return nil, err
+	}
+	return &cachedFile{File: f, release: func() { c.release(e) }}, nil
+}
+
+type cachedFile struct {
+	*os.File
+	once    sync.Once
+	release func()
+}
+
+func (f *cachedFile) Close() error {
+	err := f.File.Close()
+	f.once.Do(f.release)
+	return err
+}
+
+// acquire returns the cached entry of a package, extracting it if needed,
+// and holds a reference to it until release.
+func (c *PackageCache) acquire(ctx context.Context, id string) (*cacheEntry, error) {
+	c.mu.Lock()
+	if c.entries == nil {
+		c.entries = map[cacheKey]*cacheEntry{}
+	}
+	key := cacheKey{storage.Tenant(ctx), id}
+	e, ok := c.entries[key]
+	if ok {
+		e.refs++
+		if e.elem != nil {
+			c.lru.MoveToFront(e.elem)
+		}
+		c.mu.Unlock()
+	} else {
+		e = &cacheEntry{key: key, ready: make(chan struct{}), refs: 1}
+		c.entries[key] = e
+		c.mu.Unlock()
+		// Finish the extraction for later requests even if this one is
+		// cancelled.
+		c.extract(context.WithoutCancel(ctx), e)
+	}
+
+	select {
+	case <-e.ready:
+	case <-ctx.Done():
+		c.release(e)
+		return nil, ctx.Err()
+	}
+	if e.err != nil {
+		c.release(e)
+		return nil, e.err
+	}
+	return e, nil
+}
+
+func (c *PackageCache) extract(ctx context.Context, e *cacheEntry) {
+	e.dir, e.size, e.err = c.extractPackage(ctx, e.key.id)
+
+	c.mu.Lock()
+	var evicted []string
+	switch {
+	case e.err != nil:
+		if c.entries[e.key] == e {
+			delete(c.entries, e.key)
+		}
+	case c.entries[e.key] != e:
+		// Invalidated while extracting.
+		e.evicted = true
+	default:
+		e.elem = c.lru.PushFront(e)
+		c.size += e.size
+		evicted = c.evict()
+	}
+	close(e.ready)
+	c.mu.Unlock()
+	removeAll(evicted)
+}
+
+func (c *PackageCache) extractPackage(ctx context.Context, id string) (dir string, size int64, err error) {
+	pkg, err := c.Source.LoadPackage(ctx, id)
+	if err != nil {
+		return "", 0, err
+	}
+	defer pkg.Close()
+	if err := os.MkdirAll(c.Dir, 0750); err != nil {
+		return "", 0, err
+	}
+	dir, err = os.MkdirTemp(c.Dir, "pkg-")
+	if err != nil {
+		return "", 0, err
+	}
+	if err := pkg.ExtractToDirContext(ctx, dir, nil); err != nil {
+		os.RemoveAll(dir)
+		return "", 0, err
+	}
+	err = filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		size += info.Size()
+		return nil
+	})
+	if err != nil {
+		os.RemoveAll(dir)
+		return "", 0, err
+	}
+	return dir, size, nil
+}
+
+// evict drops least recently used entries until the cache fits MaxBytes,
+// returning the folders that can be removed now. c.mu must be held.
+func (c *PackageCache) evict() []string {
+	var dirs []string
+	for c.MaxBytes > 0 && c.size > c.MaxBytes && c.lru.Len() > 1 {
+		if dir := c.drop(c.lru.Back().Value.(*cacheEntry)); dir != "" {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// drop removes an entry from the cache, returning its folder if no file
+// of it is open. c.mu must be held.
+func (c *PackageCache) drop(e *cacheEntry) string {
+	if c.entries[e.key] == e {
+		delete(c.entries, e.key)
+	}
+	if e.elem != nil {
+		c.lru.Remove(e.elem)
+		e.elem = nil
+		c.size -= e.size
+	}
+	e.evicted = true
+	if e.refs == 0 {
+		return e.dir
+	}
+	return ""
+}
+
+func (c *PackageCache) release(e *cacheEntry) {
+	c.mu.Lock()
+	e.refs--
+	remove := e.evicted && e.refs == 0 && e.dir != ""
+	c.mu.Unlock()
+	if remove {
+		os.RemoveAll(e.dir)
+	}
+}
+
+// Invalidate drops a package of the tenant of ctx from the cache, for
+// when it is updated or deleted in the source.
+func (c *PackageCache) Invalidate(ctx context.Context, id string) {
+	key := cacheKey{storage.Tenant(ctx), id}
+	c.mu.Lock()
+	var dir string
+	if e, ok := c.entries[key]; ok {
+		if e.elem != nil {
+			dir = c.drop(e)
+		} else {
+			// Still extracting: extract marks it evicted when done.
+			delete(c.entries, key)
+		}
+	}
+	c.mu.Unlock()
+	removeAll([]string{dir})
+}
+
+// Clear drops all packages of all tenants from the cache.
+func (c *PackageCache) Clear() {
+	c.mu.Lock()
+	var dirs []string
+	for c.lru.Len() > 0 {
+		if dir := c.drop(c.lru.Front().Value.(*cacheEntry)); dir != "" {
+			dirs = append(dirs, dir)
+		}
+	}
+	c.mu.Unlock()
+	removeAll(dirs)
+}
+
+// Size returns the bytes taken by the extracted packages.
+func (c *PackageCache) Size() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.size
+}
+
+// Len returns the number of extracted packages.
+func (c *PackageCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+func removeAll(dirs []string) {
+	for _, dir := range dirs {
+		if dir != "" {
+			os.RemoveAll(dir)
+		}
+	}
+}
+
+// ServeHTTP serves the content files of packages, at the URLs AddContent
+// gives the player. Register it with a pattern holding the wildcards
+// contentId and path:
+//
+//	mux.Handle("GET /h5p/content/{contentId}/{path...}", cache)
+func (c *PackageCache) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f, err := c.open(r.Context(), r.PathValue("contentId"), h5p.ContentDir+"/"+r.PathValue("path"))
+	if err != nil {
+		serveError(w, err)
+		return
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		serveError(w, err)
+		return
+	}
+	if info.IsDir() {
+		http.NotFound(w, r)
+		return
+	}
+	http.ServeContent(w, r, info.Name(), info.ModTime(), f)
+}
+
+func serveError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, fs.ErrNotExist), errors.Is(err, storage.ErrNotFound), errors.Is(err, storage.ErrInvalidKey):
+		http.Error(w, "404 page not found", http.StatusNotFound)
+	case errors.Is(err, context.Canceled):
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,142 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	h5p "github.com/grokify/h5p-go"
+	"github.com/grokify/h5p-go/storage"
+)
+
+// countingSource counts the packages loaded from a store.
+type countingSource struct {
+	storage.PackageStore
+	mu    sync.Mutex
+	loads map[string]int
+}
+
+func (s *countingSource) LoadPackage(ctx context.Context, id string) (*h5p.H5PPackage, error) {
+	s.mu.Lock()
+	s.loads[id]++
+	s.mu.Unlock()
+	return s.PackageStore.LoadPackage(ctx, id)
+}
+
+func testCache(t *testing.T, maxBytes int64) (*PackageCache, *countingSource) {
+	t.Helper()
+	store := storage.NewMemoryStore()
+	for _, id := range []string{"1", "2", "3"} {
+		pkg := testPackage()
+		if err := pkg.AddContentAsset("images/photo.jpg", []byte(strings.Repeat(id, 1000)), ""); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.SavePackage(context.Background(), id, pkg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	src := &countingSource{PackageStore: store, loads: map[string]int{}}
+	return NewPackageCache(src, t.TempDir(), maxBytes), src
+}
+
+func TestPackageCacheServe(t *testing.T) {
+	c, src := testCache(t, 0)
+	mux := http.NewServeMux()
+	mux.Handle("GET /h5p/content/{contentId}/{path...}", c)
+
+	for range 3 {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/h5p/content/2/images/photo.jpg", nil))
+		if rec.Code != http.StatusOK || rec.Body.String() != strings.Repeat("2", 1000) {
+			t.Fatalf("Unexpected response %d %.20q", rec.Code, rec.Body.String())
+		}
+		if ct := rec.Header().Get("Content-Type"); ct != "image/jpeg" {
+			t.Errorf("Expected image/jpeg, got %q", ct)
+		}
+	}
+	if src.loads["2"] != 1 || c.Len() != 1 || c.Size() < 1000 {
+		t.Errorf("Expected one extraction, got %d loads, %d entries of %d bytes", src.loads["2"], c.Len(), c.Size())
+	}
+
+	for _, path := range []string{
+		"/h5p/content/2/missing.jpg",
+		"/h5p/content/9/images/photo.jpg",
+		"/h5p/content/2/images",
+		"/h5p/content/2/..%2Fh5p.json",
+	} {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("Expected 404 for %s, got %d", path, rec.Code)
+		}
+	}
+}
+
+func TestPackageCacheEviction(t *testing.T) {
+	c, src := testCache(t, 1)
+	ctx := context.Background()
+
+	f, err := c.Open(ctx, "1", "content/images/photo.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := c.entries[cacheKey{id: "1"}].dir
+	for _, id := range []string{"2", "3"} {
+		g, err := c.Open(ctx, id, "h5p.json")
+		if err != nil {
+			t.Fatal(err)
+		}
+		g.Close()
+	}
+	if c.Len() != 1 || c.entries[cacheKey{id: "3"}] == nil {
+		t.Errorf("Expected only the latest package cached, got %d", c.Len())
+	}
+	// The evicted package stays on disk while its file is open.
+	data, err := io.ReadAll(f)
+	if err != nil || len(data) != 1000 {
+		t.Errorf("Expected to read the open file, got %d bytes, %v", len(data), err)
+	}
+	f.Close()
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("Expected the evicted folder removed once closed, got %v", err)
+	}
+
+	if _, err := c.Open(ctx, "1", "h5p.json"); err != nil {
+		t.Fatal(err)
+	}
+	if src.loads["1"] != 2 {
+		t.Errorf("Expected an evicted package extracted again, got %d loads", src.loads["1"])
+	}
+
+	c.Invalidate(ctx, "1")
+	c.Clear()
+	if c.Len() != 0 || c.Size() != 0 {
+		t.Errorf("Expected an empty cache, got %d entries of %d bytes", c.Len(), c.Size())
+	}
+}
+
+func TestPackageCacheConcurrent(t *testing.T) {
+	c, src := testCache(t, 0)
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			f, err := c.Open(context.Background(), "3", "content/content.json")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			f.Close()
+		}()
+	}
+	wg.Wait()
+	if src.loads["3"] != 1 {
+		t.Errorf("Expected concurrent requests to share one extraction, got %d", src.loads["3"])
+	}
+}
@@ -0,0 +1,687 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif" // decoders for the sizes of uploaded images
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"maps"
+	"mime"
+	"net/http"
+	"path"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	h5p "github.com/grokify/h5p-go"
+	"github.com/grokify/h5p-go/storage"
+)
+
+var ErrInvalidContent = errors.New("invalid content")
+
+// Editor endpoints, appended to EditorIntegration.AjaxPath.
+const (
+	ActionLibraries = "libraries"
+	ActionFiles     = "files"
+	ActionSave      = "save"
+)
+
+// DefaultMaxUploadSize is the upload limit of Editor in bytes.
+const DefaultMaxUploadSize = 64 << 20
+
+// tmpPrefix is the key prefix of uploads in Editor.Temp.
+const tmpPrefix = "editor-tmp/"
+
+// tempKey returns the key of an upload in Editor.Temp, under the prefix of
+// the tenant of ctx.
+func tempKey(ctx context.Context, name string) (string, error) {
+	tenant, err := storage.TenantPrefix(ctx)
+	if err != nil {
+		return "", err
+	}
+	return tenant + tmpPrefix + name, nil
+}
+
+// EditorIntegration is H5PIntegration.editor, the settings of the
+// h5p-editor-php-library JavaScript.
+type EditorIntegration struct {
+	// FilesPath is the URL of the content files of the edited content.
+	FilesPath string `json:"filesPath"`
+	// AjaxPath is the URL the endpoints of Editor are appended to, ending
+	// in a slash, e.g. "/h5p/editor/ajax/".
+	AjaxPath string `json:"ajaxPath"`
+	// LibraryURL is the URL of the h5p-editor-php-library folder.
+	LibraryURL string `json:"libraryUrl"`
+	Assets     Assets `json:"assets"`
+	// APIVersion is the core API the editor offers content types.
+	APIVersion    h5p.CoreAPI `json:"apiVersion"`
+	NodeVersionID string      `json:"nodeVersionId"`
+	Language      string      `json:"language"`
+}
+
+// fieldMimeTypes lists the MIME types accepted by the file fields of
+// semantics, as checked by H5peditorFile.
+var fieldMimeTypes = map[string][]string{
+	"image": {"image/png", "image/jpeg", "image/gif"},
+	"video": {"video/mp4", "video/webm", "video/ogg"},
+	"audio": {"audio/mpeg", "audio/mp3", "audio/mp4", "audio/ogg", "audio/wav", "audio/x-wav", "audio/webm"},
+}
+
+// fieldDirs are the content folders of uploads by field type.
+var fieldDirs = map[string]string{
+	"image": "images",
+	"video": "videos",
+	"audio": "audios",
+	"file":  "files",
+}
+
+// AllowedExtensions are the extensions H5PCore accepts for files by
+// default, which also bounds the uploads of "file" fields. It is
+// h5p.AllowedContentFileExtensions unless replaced.
+var AllowedExtensions = h5p.AllowedContentFileExtensions
+
+// Editor serves the AJAX endpoints of the official H5P editor, backed by
+// the storage interfaces. Register it with a pattern holding the wildcard
+// action, and set EditorIntegration.AjaxPath to the path before it:
+//
+//	mux.Handle("/h5p/editor/ajax/{action}", editor)
+//
+// The actions are:
+//
+//   - libraries: without query, the latest version of each runnable
+//     library; POSTed libraries[] names, those libraries, failing if any
+//     is invalid or missing; with the
+//     machineName, majorVersion and minorVersion query, the semantics,
+//     translations and editor assets of a library.
+//   - files: uploads a file for a field, keeping it in Temp until the
+//     content is saved.
+//   - save: saves the POSTed form fields library and parameters, as
+//     written by the editor, as content id; see SaveContent.
+type Editor struct {
+	Libraries storage.LibraryStorage
+	Packages  storage.PackageStore
+	// Temp keeps uploads until the content using them is saved.
+	Temp storage.Bucket
+	// LibraryURL is the path library folders are served under, such as
+	// Settings.URL + "/libraries".
+	LibraryURL  string
+	CacheBuster string
+	// MaxUploadSize defaults to DefaultMaxUploadSize.
+	MaxUploadSize int64
+	// Events, if set, receives content created, updated and deleted
+	// events.
+	Events *EventBus
+}
+
+func (e *Editor) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.PathValue("action") {
+	case ActionLibraries:
+		if r.URL.Query().Has("machineName") {
+			e.serveLibrary(w, r)
+		} else {
+			e.serveLibraries(w, r)
+		}
+	case ActionFiles:
+		e.serveUpload(w, r)
+	case ActionSave:
+		e.serveSave(w, r)
+	default:
+		ajaxError(w, http.StatusNotFound, "unknown action")
+	}
+}
+
+// EditorLibraryInfo is an entry of the libraries list.
+type EditorLibraryInfo struct {
+	UberName         string                `json:"uberName"`
+	Name             string                `json:"name"`
+	MajorVersion     int                   `json:"majorVersion"`
+	MinorVersion     int                   `json:"minorVersion"`
+	Title            string                `json:"title"`
+	Runnable         h5p.BoolInt           `json:"runnable"`
+	Restricted       bool                  `json:"restricted"`
+	MetadataSettings *h5p.MetadataSettings `json:"metadataSettings"`
+}
+
+// EditorLibraryData is the semantics and editor assets of a library.
+type EditorLibraryData struct {
+	Name    string `json:"name"`
+	Version struct {
+		Major int `json:"major"`
+		Minor int `json:"minor"`
+	} `json:"version"`
+	Title     string `json:"title"`
+	Semantics any    `json:"semantics"`
+	// Language is the JSON of the library's language file for the
+	// requested language, which the editor parses, or nil.
+	Language        *string  `json:"language"`
+	DefaultLanguage *string  `json:"defaultLanguage"`
+	Languages       []string `json:"languages"`
+	// JavaScript and CSS are the URLs of the preloaded files of the
+	// library and its preloaded and editor dependencies, dependencies
+	// first.
+	JavaScript []string `json:"javascript"`
+	CSS        []string `json:"css"`
+	// Translations holds the language file JSON of the editor widgets by
+	// machine name.
+	Translations map[string]string `json:"translations"`
+}
+
+func libraryInfo(lib *h5p.Library) EditorLibraryInfo {
+	def := lib.Definition
+	return EditorLibraryInfo{
+		UberName:         fmt.Sprintf("%s %d.%d", def.MachineName, def.MajorVersion, def.MinorVersion),
+		Name:             def.MachineName,
+		MajorVersion:     def.MajorVersion,
+		MinorVersion:     def.MinorVersion,
+		Title:            def.Title,
+		Runnable:         def.Runnable,
+		MetadataSettings: def.MetadataSettings,
+	}
+}
+
+func (e *Editor) serveLibraries(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	list := []EditorLibraryInfo{}
+	if r.Method == http.MethodPost {
+		if err := r.ParseForm(); err != nil {
+			ajaxError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		for _, name := range r.PostForm["libraries[]"] {
+			dep, err := h5p.ParseLibraryString(name)
+			if err != nil {
+				ajaxError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			lib, err := e.Libraries.LoadLibrary(ctx, folderName(dep))
+			switch {
+			case errors.Is(err, storage.ErrNotFound):
+				ajaxError(w, http.StatusNotFound, fmt.Sprintf("library %s: %v", name, err))
+				return
+			case err != nil:
+				ajaxError(w, http.StatusInternalServerError, fmt.Sprintf("library %s: %v", name, err))
+				return
+			}
+			list = append(list, libraryInfo(lib))
+		}
+		writeJSON(w, http.StatusOK, list)
+		return
+	}
+
+	libs, err := e.latestRunnable(ctx)
+	if err != nil {
+		ajaxError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	for _, lib := range libs {
+		list = append(list, libraryInfo(lib))
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+// latestRunnable returns the latest version of each runnable library,
+// sorted by title.
+func (e *Editor) latestRunnable(ctx context.Context) ([]*h5p.Library, error) {
+	folders, err := e.Libraries.ListLibraries(ctx)
+	if err != nil {
+		return nil, err
+	}
+	latest := map[string]*h5p.Library{}
+	for _, folder := range folders {
+		lib, err := e.Libraries.LoadLibrary(ctx, folder)
+		if err != nil {
+			return nil, err
+		}
+		def := lib.Definition
+		if !def.Runnable {
+			continue
+		}
+		if cur, ok := latest[def.MachineName]; ok {
+			cd := cur.Definition
+			if cd.MajorVersion > def.MajorVersion || cd.MajorVersion == def.MajorVersion && cd.MinorVersion >= def.MinorVersion {
+				continue
+			}
+		}
+		latest[def.MachineName] = lib
+	}
+	libs := slices.Collect(maps.Values(latest))
+	sort.Slice(libs, func(i, j int) bool {
+		if libs[i].Definition.Title != libs[j].Definition.Title {
+			return libs[i].Definition.Title < libs[j].Definition.Title
+		}
+		return libs[i].Definition.MachineName < libs[j].Definition.MachineName
+	})
+	return libs, nil
+}
+
+func (e *Editor) serveLibrary(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	dep, err := h5p.ParseLibraryString(q.Get("machineName") + " " + q.Get("majorVersion") + "." + q.Get("minorVersion"))
+	if err != nil {
+		ajaxError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	data, err := e.LibraryData(r.Context(), dep, q.Get("languageCode"))
+	switch {
+	case errors.Is(err, storage.ErrNotFound):
+		ajaxError(w, http.StatusNotFound, err.Error())
+	case err != nil:
+		ajaxError(w, http.StatusInternalServerError, err.Error())
+	default:
+		writeJSON(w, http.StatusOK, data)
+	}
+}
+
+// LibraryData returns the semantics and editor assets of a library, with
+// its language file for languageCode, if there is one.
+func (e *Editor) LibraryData(ctx context.Context, dep h5p.LibraryDependency, languageCode string) (*EditorLibraryData, error) {
+	libs, err := e.closure(ctx, []h5p.LibraryDependency{dep}, true)
+	if err != nil {
+		return nil, err
+	}
+	lib := libs[len(libs)-1]
+	data := &EditorLibraryData{
+		Name:         dep.MachineName,
+		Title:        lib.Definition.Title,
+		Semantics:    lib.Semantics,
+		Languages:    []string{},
+		JavaScript:   []string{},
+		CSS:          []string{},
+		Translations: map[string]string{},
+	}
+	data.Version.Major, data.Version.Minor = dep.MajorVersion, dep.MinorVersion
+	if data.Semantics == nil {
+		data.Semantics = []any{}
+	}
+
+	data.Languages = append(data.Languages, lib.Languages()...)
+	if languageCode != "" {
+		if b, err := lib.ReadFile("language/" + languageCode + ".json"); err == nil {
+			s := string(b)
+			data.Language = &s
+		}
+	}
+
+	for _, l := range libs {
+		base := strings.TrimSuffix(e.LibraryURL, "/") + "/" + l.MachineName + "/"
+		for _, f := range l.Definition.PreloadedJs {
+			data.JavaScript = append(data.JavaScript, base+f.Path+e.CacheBuster)
+		}
+		for _, f := range l.Definition.PreloadedCss {
+			data.CSS = append(data.CSS, base+f.Path+e.CacheBuster)
+		}
+		if languageCode != "" && l != lib && strings.HasPrefix(l.Definition.MachineName, "H5PEditor.") {
+			if b, err := l.ReadFile("language/" + languageCode + ".json"); err == nil {
+				data.Translations[l.Definition.MachineName] = string(b)
+			}
+		}
+	}
+	return data, nil
+}
+
+// closure loads the libraries deps depend on from Libraries, see
+// resolveLibraries.
+func (e *Editor) closure(ctx context.Context, deps []h5p.LibraryDependency, editor bool) ([]*h5p.Library, error) {
+	return resolveLibraries(deps, editor, func(dep h5p.LibraryDependency) (*h5p.Library, error) {
+		return e.Libraries.LoadLibrary(ctx, folderName(dep))
+	})
+}
+
+// resolveLibraries returns the libraries deps depend on, dependencies
+// first and each once, as returned by load. Preloaded and dynamic
+// dependencies are followed, and editor dependencies if editor is set.
+func resolveLibraries(deps []h5p.LibraryDependency, editor bool, load func(h5p.LibraryDependency) (*h5p.Library, error)) ([]*h5p.Library, error) {
+	var libs []*h5p.Library
+	seen := map[string]bool{}
+	var visit func(dep h5p.LibraryDependency) error
+	visit = func(dep h5p.LibraryDependency) error {
+		folder := folderName(dep)
+		if seen[folder] {
+			return nil
+		}
+		seen[folder] = true
+		lib, err := load(dep)
+		if err != nil {
+			return fmt.Errorf("library %s: %w", dep, err)
+		}
+		next := slices.Concat(lib.Definition.Dependencies, lib.Definition.DynamicDependencies)
+		if editor {
+			next = append(next, lib.Definition.EditorDependencies...)
+		}
+		for _, d := range next {
+			if err := visit(d); err != nil {
+				return err
+			}
+		}
+		libs = append(libs, lib)
+		return nil
+	}
+	for _, dep := range deps {
+		if err := visit(dep); err != nil {
+			return nil, err
+		}
+	}
+	return libs, nil
+}
+
+func folderName(dep h5p.LibraryDependency) string {
+	return fmt.Sprintf("%s-%d.%d", dep.MachineName, dep.MajorVersion, dep.MinorVersion)
+}
+
+// UploadResult is the response to an upload. Path is relative to the
+// content folder and ends in "#tmp" until the content is saved.
+type UploadResult struct {
+	Path   string `json:"path"`
+	Mime   string `json:"mime"`
+	Width  int    `json:"width,omitempty"`
+	Height int    `json:"height,omitempty"`
+}
+
+var unsafeNameChars = regexp.MustCompile(`[^a-z0-9_-]+`)
+
+func (e *Editor) serveUpload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		ajaxError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	limit := e.MaxUploadSize
+	if limit <= 0 {
+		limit = DefaultMaxUploadSize
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, limit)
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		ajaxError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	defer file.Close()
+	var field struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal([]byte(r.FormValue("field")), &field); err != nil {
+		ajaxError(w, http.StatusBadRequest, "invalid field")
+		return
+	}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		ajaxError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := uploadResult(field.Type, header.Filename, data)
+	if err != nil {
+		ajaxError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	key, err := tempKey(r.Context(), strings.TrimSuffix(result.Path, "#tmp"))
+	if err != nil {
+		ajaxError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := e.Temp.Put(r.Context(), key, bytes.NewReader(data)); err != nil {
+		ajaxError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// uploadResult checks an upload for a field of the type and names it.
+func uploadResult(fieldType, filename string, data []byte) (*UploadResult, error) {
+	dir, ok := fieldDirs[fieldType]
+	if !ok {
+		return nil, fmt.Errorf("%w: field type %q", ErrInvalidContent, fieldType)
+	}
+	ext := strings.ToLower(path.Ext(filename))
+	if !slices.Contains(AllowedExtensions, strings.TrimPrefix(ext, ".")) {
+		return nil, fmt.Errorf("%w: file extension %q is not allowed", ErrInvalidContent, ext)
+	}
+	mimeType, _, _ := mime.ParseMediaType(mime.TypeByExtension(ext))
+	if mimeType == "" {
+		mimeType = http.DetectContentType(data)
+	}
+	if allowed, ok := fieldMimeTypes[fieldType]; ok && !slices.Contains(allowed, mimeType) {
+		return nil, fmt.Errorf("%w: %s is not a valid %s", ErrInvalidContent, mimeType, fieldType)
+	}
+
+	result := &UploadResult{Mime: mimeType}
+	if fieldType == "image" {
+		cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("%w: file is not an image", ErrInvalidContent)
+		}
+		result.Width, result.Height = cfg.Width, cfg.Height
+	}
+
+	base := unsafeNameChars.ReplaceAllString(strings.ToLower(strings.TrimSuffix(path.Base(filename), path.Ext(filename))), "-")
+	base = strings.Trim(base, "-")
+	if base == "" {
+		base = fieldType
+	}
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return nil, err
+	}
+	result.Path = dir + "/" + base + "-" + hex.EncodeToString(suffix) + ext + "#tmp"
+	return result, nil
+}
+
+func (e *Editor) serveSave(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		ajaxError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	id := r.FormValue("id")
+	_, err := e.SaveContent(r.Context(), id, r.FormValue("library"), r.FormValue("parameters"))
+	switch {
+	case errors.Is(err, ErrInvalidContent), errors.Is(err, storage.ErrInvalidKey):
+		ajaxError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, storage.ErrNotFound):
+		ajaxError(w, http.StatusUnprocessableEntity, err.Error())
+	case err != nil:
+		ajaxError(w, http.StatusInternalServerError, err.Error())
+	default:
+		ajaxSuccess(w, map[string]string{"id": id})
+	}
+}
+
+// SaveContent builds a package from what the editor wrote and saves it
+// to Packages as id. library is the main library, such as
+// "H5P.MultiChoice 1.16", and parameters the JSON of the params and
+// metadata, {"params": ..., "metadata": ...}. The package holds the
+// libraries the content uses with their dependencies, uploads referenced
+// with "#tmp" paths, which are then removed from Temp, and the files the
+// previous version of the content still references. It publishes
+// EventContentCreated or EventContentUpdated.
+func (e *Editor) SaveContent(ctx context.Context, id, library, parameters string) (*h5p.H5PPackage, error) {
+	main, err := h5p.ParseLibraryString(library)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidContent, err)
+	}
+	var doc struct {
+		Params   json.RawMessage      `json:"params"`
+		Metadata *h5p.ContentMetadata `json:"metadata"`
+	}
+	var params map[string]any
+	if err := json.Unmarshal([]byte(parameters), &doc); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidContent, err)
+	}
+	if err := json.Unmarshal(doc.Params, &params); err != nil || params == nil {
+		return nil, fmt.Errorf("%w: params must be an object", ErrInvalidContent)
+	}
+	meta := doc.Metadata
+	if meta == nil {
+		meta = &h5p.ContentMetadata{}
+	}
+
+	refs := &editorRefs{tmp: map[string]bool{}, files: map[string]bool{}}
+	refs.walk(params)
+	deps := []h5p.LibraryDependency{main}
+	for _, dep := range refs.libraries {
+		if !slices.Contains(deps, dep) {
+			deps = append(deps, dep)
+		}
+	}
+	libs, err := e.closure(ctx, deps, false)
+	if err != nil {
+		return nil, err
+	}
+
+	pkg := h5p.NewH5PPackage()
+	def := &h5p.PackageDefinition{
+		Title:                 meta.Title,
+		Language:              "und",
+		MainLibrary:           main.MachineName,
+		License:               meta.License,
+		LicenseVersion:        meta.LicenseVersion,
+		LicenseExtras:         meta.LicenseExtras,
+		Source:                meta.Source,
+		YearFrom:              meta.YearFrom,
+		YearTo:                meta.YearTo,
+		DefaultLanguage:       meta.DefaultLanguage,
+		Authors:               meta.Authors,
+		PreloadedDependencies: deps,
+	}
+	if def.Title == "" {
+		def.Title = "Untitled"
+	}
+	if def.DefaultLanguage != "" {
+		def.Language = def.DefaultLanguage
+	}
+	for _, lib := range libs {
+		if lib.Definition.MachineName == main.MachineName {
+			def.EmbedTypes = lib.Definition.EmbedTypes
+		}
+		pkg.AddLibrary(lib)
+	}
+	if len(def.EmbedTypes) == 0 {
+		def.EmbedTypes = []string{EmbedDiv}
+	}
+	pkg.SetPackageDefinition(def)
+	pkg.SetContent(&h5p.Content{Params: params})
+
+	prev, err := e.Packages.LoadPackage(ctx, id)
+	switch {
+	case errors.Is(err, storage.ErrNotFound):
+	case err != nil:
+		return nil, err
+	default:
+		defer prev.Close()
+	}
+	if err := e.addContentFiles(ctx, pkg, prev, refs); err != nil {
+		return nil, err
+	}
+	if err := e.Packages.SavePackage(ctx, id, pkg); err != nil {
+		return nil, err
+	}
+	for name := range refs.tmp {
+		key, err := tempKey(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		if err := e.Temp.Delete(ctx, key); err != nil {
+			return nil, err
+		}
+	}
+	event := EventContentUpdated
+	if prev == nil {
+		event = EventContentCreated
+	}
+	e.Events.Publish(ctx, Event{Type: event, ContentID: id, Title: def.Title})
+	return pkg, nil
+}
+
+// DeleteContent deletes content from Packages.
+func (e *Editor) DeleteContent(ctx context.Context, id string) error {
+	if err := e.Packages.DeletePackage(ctx, id); err != nil {
+		return err
+	}
+	e.Events.Publish(ctx, Event{Type: EventContentDeleted, ContentID: id})
+	return nil
+}
+
+// addContentFiles adds the uploads and the files of the previous version
+// of the content, if any, that the params reference.
+func (e *Editor) addContentFiles(ctx context.Context, pkg, prev *h5p.H5PPackage, refs *editorRefs) error {
+	for name := range refs.tmp {
+		key, err := tempKey(ctx, name)
+		if err != nil {
+			return err
+		}
+		rc, err := e.Temp.Get(ctx, key)
+		if err != nil {
+			return fmt.Errorf("%w: upload %s: %v", ErrInvalidContent, name, err)
+		}
+		// The upload is read now, as SaveContent removes it from Temp.
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return fmt.Errorf("upload %s: %w", name, err)
+		}
+		if err := pkg.AddContentAsset(name, data, ""); err != nil {
+			return err
+		}
+	}
+	if prev == nil {
+		return nil
+	}
+	for name := range refs.files {
+		cf, ok := prev.ContentFiles[name]
+		if !ok || refs.tmp[name] {
+			continue
+		}
+		data, err := cf.Bytes()
+		if err != nil {
+			return err
+		}
+		if err := pkg.AddContentAsset(name, data, cf.Mime); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// editorRefs collects what params written by the editor refer to.
+type editorRefs struct {
+	libraries []h5p.LibraryDependency
+	// tmp holds the uploads, files the other local files, by path in the
+	// content folder.
+	tmp   map[string]bool
+	files map[string]bool
+}
+
+// walk records the "library" and "path" references of params, removing
+// the "#tmp" suffix of uploads.
+func (refs *editorRefs) walk(v any) {
+	switch t := v.(type) {
+	case map[string]any:
+		if s, ok := t["library"].(string); ok {
+			if dep, err := h5p.ParseLibraryString(s); err == nil {
+				refs.libraries = append(refs.libraries, dep)
+			}
+		}
+		if p, ok := t["path"].(string); ok && !strings.Contains(p, "://") && !strings.HasPrefix(p, "data:") {
+			if name, ok := strings.CutSuffix(p, "#tmp"); ok {
+				t["path"] = name
+				refs.tmp[name] = true
+			} else if p != "" {
+				refs.files[strings.TrimPrefix(p, h5p.ContentDir+"/")] = true
+			}
+		}
+		for _, k := range slices.Sorted(maps.Keys(t)) {
+			refs.walk(t[k])
+		}
+	case []any:
+		for _, item := range t {
+			refs.walk(item)
+		}
+	}
+}
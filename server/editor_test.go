@@ -0,0 +1,241 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"image"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	h5p "github.com/grokify/h5p-go"
+	"github.com/grokify/h5p-go/storage"
+)
+
+func testEditor(t *testing.T) (*Editor, *http.ServeMux) {
+	t.Helper()
+	store := storage.NewMemoryStore()
+	libs := []*h5p.Library{
+		{
+			MachineName: "H5P.MultiChoice-1.16",
+			Definition: &h5p.LibraryDefinition{
+				Title: "Multiple Choice", MachineName: "H5P.MultiChoice", MajorVersion: 1, MinorVersion: 16, Runnable: true,
+				EmbedTypes:         []string{EmbedIframe},
+				PreloadedJs:        []h5p.FileReference{{Path: "js/multichoice.js"}},
+				Dependencies:       []h5p.LibraryDependency{{MachineName: "H5P.Question", MajorVersion: 1, MinorVersion: 5}},
+				EditorDependencies: []h5p.LibraryDependency{{MachineName: "H5PEditor.VerticalTabs", MajorVersion: 1, MinorVersion: 3}},
+			},
+			Semantics: []any{map[string]any{"name": "question", "type": "text"}},
+			Files: map[string][]byte{
+				"js/multichoice.js": []byte("//"),
+				"language/de.json":  []byte(`{"semantics":[]}`),
+			},
+		},
+		{
+			MachineName: "H5P.MultiChoice-1.14",
+			Definition:  &h5p.LibraryDefinition{Title: "Multiple Choice", MachineName: "H5P.MultiChoice", MajorVersion: 1, MinorVersion: 14, Runnable: true},
+		},
+		{
+			MachineName: "H5P.Question-1.5",
+			Definition: &h5p.LibraryDefinition{
+				Title: "Question", MachineName: "H5P.Question", MajorVersion: 1, MinorVersion: 5,
+				PreloadedJs:  []h5p.FileReference{{Path: "scripts/question.js"}},
+				PreloadedCss: []h5p.FileReference{{Path: "styles/question.css"}},
+			},
+		},
+		{
+			MachineName: "H5PEditor.VerticalTabs-1.3",
+			Definition: &h5p.LibraryDefinition{
+				Title: "Vertical Tabs", MachineName: "H5PEditor.VerticalTabs", MajorVersion: 1, MinorVersion: 3,
+				PreloadedJs: []h5p.FileReference{{Path: "vertical-tabs.js"}},
+			},
+			Files: map[string][]byte{"vertical-tabs.js": []byte("//"), "language/de.json": []byte(`{"libraryStrings":{}}`)},
+		},
+	}
+	for _, lib := range libs {
+		if err := store.SaveLibrary(context.Background(), lib); err != nil {
+			t.Fatal(err)
+		}
+	}
+	e := &Editor{Libraries: store, Packages: store, Temp: storage.NewMemoryBucket(), LibraryURL: "/h5p/libraries", CacheBuster: "?v=1"}
+	mux := http.NewServeMux()
+	mux.Handle("/h5p/editor/ajax/{action}", e)
+	return e, mux
+}
+
+func TestEditorLibraries(t *testing.T) {
+	_, mux := testEditor(t)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/h5p/editor/ajax/libraries", nil))
+	var list []EditorLibraryInfo
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].UberName != "H5P.MultiChoice 1.16" || !list[0].Runnable {
+		t.Errorf("Expected the latest runnable library only, got %+v", list)
+	}
+
+	postLibraries := func(names ...string) *httptest.ResponseRecorder {
+		form := url.Values{"libraries[]": names}
+		req := httptest.NewRequest(http.MethodPost, "/h5p/editor/ajax/libraries", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+	rec = postLibraries("H5P.MultiChoice 1.14")
+	list = nil
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil || len(list) != 1 || list[0].MinorVersion != 14 {
+		t.Errorf("Expected the requested library, got %s", rec.Body)
+	}
+	if rec := postLibraries("H5P.MultiChoice 1.14", "H5P.Missing 1.0"); rec.Code != http.StatusNotFound || !strings.Contains(rec.Body.String(), "H5P.Missing 1.0") {
+		t.Errorf("Expected the missing library reported, got %d %s", rec.Code, rec.Body)
+	}
+	if rec := postLibraries("H5P.MultiChoice"); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected an invalid library name refused, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet,
+		"/h5p/editor/ajax/libraries?machineName=H5P.MultiChoice&majorVersion=1&minorVersion=16&languageCode=de", nil))
+	var data EditorLibraryData
+	if err := json.Unmarshal(rec.Body.Bytes(), &data); err != nil {
+		t.Fatal(err)
+	}
+	wantJS := []string{
+		"/h5p/libraries/H5P.Question-1.5/scripts/question.js?v=1",
+		"/h5p/libraries/H5PEditor.VerticalTabs-1.3/vertical-tabs.js?v=1",
+		"/h5p/libraries/H5P.MultiChoice-1.16/js/multichoice.js?v=1",
+	}
+	if !reflect.DeepEqual(data.JavaScript, wantJS) || len(data.CSS) != 1 {
+		t.Errorf("Unexpected assets %v %v", data.JavaScript, data.CSS)
+	}
+	if data.Language == nil || *data.Language != `{"semantics":[]}` || !reflect.DeepEqual(data.Languages, []string{"de"}) {
+		t.Errorf("Unexpected language %v %v", data.Language, data.Languages)
+	}
+	if data.Translations["H5PEditor.VerticalTabs"] == "" || data.Version.Minor != 16 || data.Semantics == nil {
+		t.Errorf("Unexpected library data %+v", data)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/h5p/editor/ajax/libraries?machineName=H5P.Missing&majorVersion=1&minorVersion=0", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for a missing library, got %d", rec.Code)
+	}
+}
+
+func upload(t *testing.T, mux *http.ServeMux, fieldType, filename string, data []byte) *httptest.ResponseRecorder {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	mw.WriteField("field", `{"name":"file","type":"`+fieldType+`"}`)
+	fw, _ := mw.CreateFormFile("file", filename)
+	fw.Write(data)
+	mw.Close()
+	req := httptest.NewRequest(http.MethodPost, "/h5p/editor/ajax/files", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestEditorUploadAndSave(t *testing.T) {
+	e, mux := testEditor(t)
+	ctx := context.Background()
+	var events []EventType
+	e.Events = NewEventBus()
+	e.Events.Subscribe(func(_ context.Context, ev Event) { events = append(events, ev.Type) })
+
+	var img bytes.Buffer
+	png.Encode(&img, image.NewRGBA(image.Rect(0, 0, 3, 2)))
+	rec := upload(t, mux, "image", "My Photo.PNG", img.Bytes())
+	var result UploadResult
+	if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("Upload failed: %d %s", rec.Code, rec.Body)
+	}
+	if !strings.HasPrefix(result.Path, "images/my-photo-") || !strings.HasSuffix(result.Path, ".png#tmp") ||
+		result.Mime != "image/png" || result.Width != 3 || result.Height != 2 {
+		t.Errorf("Unexpected upload result %+v", result)
+	}
+	if rec := upload(t, mux, "image", "notes.txt", []byte("hi")); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected a text file refused for an image field, got %d", rec.Code)
+	}
+	if rec := upload(t, mux, "file", "run.exe", []byte("MZ")); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected an executable refused, got %d", rec.Code)
+	}
+
+	params := `{"params":{"question":"2 + 2?","media":{"type":{"library":"H5P.Image 1.1","params":{"file":{"path":"` + result.Path + `"}}}}},` +
+		`"metadata":{"title":"Sums","license":"CC BY"}}`
+	if _, err := e.SaveContent(ctx, "7", "H5P.MultiChoice 1.16", params); err == nil || !strings.Contains(err.Error(), "H5P.Image 1.1") {
+		t.Errorf("Expected the missing sub-content library reported, got %v", err)
+	}
+
+	params = strings.Replace(params, `"H5P.Image 1.1"`, `"H5P.Question 1.5"`, 1)
+	form := url.Values{"id": {"7"}, "library": {"H5P.MultiChoice 1.16"}, "parameters": {params}}
+	req := httptest.NewRequest(http.MethodPost, "/h5p/editor/ajax/save", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Save failed: %d %s", rec.Code, rec.Body)
+	}
+
+	pkg, err := e.Packages.LoadPackage(ctx, "7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	name := strings.TrimSuffix(result.Path, "#tmp")
+	def := pkg.PackageDefinition
+	if def.Title != "Sums" || def.License != "CC BY" || !reflect.DeepEqual(def.EmbedTypes, []string{EmbedIframe}) || len(pkg.Libraries) != 2 {
+		t.Errorf("Unexpected package %+v with %d libraries", def, len(pkg.Libraries))
+	}
+	if _, ok := pkg.ContentFiles[name]; !ok {
+		t.Errorf("Expected the upload in the package, got %v", pkg.ContentFileNames())
+	}
+	if _, err := e.Temp.Get(ctx, tmpPrefix+name); err == nil {
+		t.Error("Expected the upload removed from Temp")
+	}
+
+	// Saving again keeps the file, now referenced without #tmp.
+	params = strings.Replace(params, result.Path, name, 1)
+	params = strings.Replace(params, "2 + 2?", "3 + 3?", 1)
+	pkg, err = e.SaveContent(ctx, "7", "H5P.MultiChoice 1.16", params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := pkg.ContentFiles[name]; !ok {
+		t.Errorf("Expected the previous file kept, got %v", pkg.ContentFileNames())
+	}
+
+	// The returned package holds the upload even though Temp no longer does.
+	rec = upload(t, mux, "image", "other.png", img.Bytes())
+	var other UploadResult
+	if err := json.Unmarshal(rec.Body.Bytes(), &other); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("Upload failed: %d %s", rec.Code, rec.Body)
+	}
+	pkg, err = e.SaveContent(ctx, "7", "H5P.MultiChoice 1.16", strings.Replace(params, name, other.Path, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cf, ok := pkg.ContentFiles[strings.TrimSuffix(other.Path, "#tmp")]
+	if !ok {
+		t.Fatalf("Expected the upload in the package, got %v", pkg.ContentFileNames())
+	}
+	if data, err := cf.Bytes(); err != nil || !bytes.Equal(data, img.Bytes()) {
+		t.Errorf("Expected the upload readable from the returned package, got %d bytes, %v", len(data), err)
+	}
+
+	if err := e.DeleteContent(ctx, "7"); err != nil {
+		t.Fatal(err)
+	}
+	if want := []EventType{EventContentCreated, EventContentUpdated, EventContentUpdated, EventContentDeleted}; !reflect.DeepEqual(events, want) {
+		t.Errorf("Expected events %v, got %v", want, events)
+	}
+}
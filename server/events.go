@@ -0,0 +1,108 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/grokify/h5p-go/storage"
+)
+
+// EventType names a content lifecycle event.
+type EventType string
+
+const (
+	EventContentCreated  EventType = "content.created"
+	EventContentUpdated  EventType = "content.updated"
+	EventContentDeleted  EventType = "content.deleted"
+	EventAttemptFinished EventType = "attempt.finished"
+	EventPackageExported EventType = "package.exported"
+)
+
+// Event is published on an EventBus by the handlers of this package.
+type Event struct {
+	// ID is unique per event; receivers can use it to drop duplicates.
+	ID        string    `json:"id"`
+	Type      EventType `json:"type"`
+	Time      time.Time `json:"time"`
+	ContentID string    `json:"contentId"`
+	// Tenant is the site of the content, see storage.WithTenant; empty for
+	// the default site.
+	Tenant string `json:"tenant,omitempty"`
+	// UserID is the user who caused the event, if known.
+	UserID string `json:"userId,omitempty"`
+	// Title is the title of the content, for content events.
+	Title string `json:"title,omitempty"`
+	// Attempt is set for EventAttemptFinished.
+	Attempt *Attempt `json:"attempt,omitempty"`
+}
+
+// EventFunc handles an event. It runs on the publishing goroutine, so
+// slow work such as network calls should be done asynchronously, as
+// WebhookDispatcher does.
+type EventFunc func(ctx context.Context, e Event)
+
+// EventBus passes events from the handlers to subscribers, so an LMS
+// integration can react to content changes and results without polling.
+// A nil *EventBus drops all events.
+type EventBus struct {
+	mu   sync.RWMutex
+	subs []*subscription
+}
+
+type subscription struct {
+	fn    EventFunc
+	types []EventType
+}
+
+func NewEventBus() *EventBus {
+	return &EventBus{}
+}
+
+// Subscribe calls fn for events of the given types, or of all types if
+// none are given, until the returned function is called.
+func (b *EventBus) Subscribe(fn EventFunc, types ...EventType) (unsubscribe func()) {
+	s := &subscription{fn: fn, types: types}
+	b.mu.Lock()
+	b.subs = append(b.subs, s)
+	b.mu.Unlock()
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.subs = slices.DeleteFunc(b.subs, func(x *subscription) bool { return x == s })
+	}
+}
+
+// Publish sets the ID and Time of e if empty, and the Tenant to the tenant
+// of ctx, and passes it to the subscribers, in the order they subscribed.
+func (b *EventBus) Publish(ctx context.Context, e Event) {
+	if b == nil {
+		return
+	}
+	if e.ID == "" {
+		e.ID = newEventID()
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+	if e.Tenant == "" {
+		e.Tenant = storage.Tenant(ctx)
+	}
+	b.mu.RLock()
+	subs := slices.Clone(b.subs)
+	b.mu.RUnlock()
+	for _, s := range subs {
+		if len(s.types) == 0 || slices.Contains(s.types, e.Type) {
+			s.fn(ctx, e)
+		}
+	}
+}
+
+func newEventID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,115 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/grokify/h5p-go/storage"
+)
+
+func TestEventBus(t *testing.T) {
+	bus := NewEventBus()
+	var all, attempts []Event
+	bus.Subscribe(func(_ context.Context, e Event) { all = append(all, e) })
+	unsubscribe := bus.Subscribe(func(_ context.Context, e Event) { attempts = append(attempts, e) }, EventAttemptFinished)
+
+	h := &SetFinishedHandler{Store: NewMemoryAttemptStore(), User: func(*http.Request) string { return "u1" }, Events: bus}
+	form := url.Values{"contentId": {"7"}, "score": {"3"}, "maxScore": {"4"}, "opened": {"1000"}, "finished": {"1060"}}
+	req := httptest.NewRequest(http.MethodPost, "/ajax/set-finished", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	bus.Publish(context.Background(), Event{Type: EventContentDeleted, ContentID: "7"})
+
+	if len(all) != 2 || len(attempts) != 1 {
+		t.Fatalf("Expected 2 events and 1 attempt, got %d and %d", len(all), len(attempts))
+	}
+	if e := attempts[0]; e.ContentID != "7" || e.UserID != "u1" || e.Attempt == nil || e.Attempt.Score != 3 || e.ID == "" || e.Time.IsZero() {
+		t.Errorf("Unexpected attempt event %+v", e)
+	}
+	if all[0].ID == all[1].ID {
+		t.Error("Expected unique event IDs")
+	}
+
+	unsubscribe()
+	bus.Publish(context.Background(), Event{Type: EventAttemptFinished})
+	if len(attempts) != 1 || len(all) != 3 {
+		t.Errorf("Expected no events after unsubscribing, got %d", len(attempts))
+	}
+
+	bus.Publish(storage.WithTenant(context.Background(), "school"), Event{Type: EventContentCreated})
+	if e := all[len(all)-1]; e.Tenant != "school" {
+		t.Errorf("Expected the tenant of the context in the event, got %q", e.Tenant)
+	}
+
+	var nilBus *EventBus
+	nilBus.Publish(context.Background(), Event{Type: EventContentCreated})
+}
+
+func TestWebhookDispatcher(t *testing.T) {
+	var mu sync.Mutex
+	var received []Event
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !VerifySignature("s3cret", body, r.Header.Get(HeaderSignature)) {
+			http.Error(w, "bad signature", http.StatusUnauthorized)
+			return
+		}
+		// Fail the first delivery to exercise retries.
+		if calls.Add(1) == 1 {
+			http.Error(w, "busy", http.StatusServiceUnavailable)
+			return
+		}
+		var e Event
+		json.Unmarshal(body, &e)
+		if r.Header.Get(HeaderEvent) != string(e.Type) || r.Header.Get(HeaderDelivery) != e.ID {
+			http.Error(w, "bad headers", http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		received = append(received, e)
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	var failed []error
+	d := NewWebhookDispatcher(
+		Webhook{URL: srv.URL, Secret: "s3cret", Types: []EventType{EventContentCreated}},
+		Webhook{URL: srv.URL, Secret: "wrong"},
+		Webhook{URL: srv.URL, Secret: "s3cret", Tenants: []string{"school"}},
+	)
+	d.Backoff = time.Millisecond
+	d.OnError = func(_ Webhook, _ Event, err error) {
+		mu.Lock()
+		failed = append(failed, err)
+		mu.Unlock()
+	}
+	bus := NewEventBus()
+	bus.Subscribe(d.Handle)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	bus.Publish(ctx, Event{Type: EventContentCreated, ContentID: "7", Title: "Quiz"})
+	cancel()
+	d.Wait()
+
+	if len(received) != 1 || received[0].ContentID != "7" || received[0].Title != "Quiz" {
+		t.Errorf("Expected the event delivered once after a retry, got %+v", received)
+	}
+	// The hook with the wrong secret gets 401, which is not retried.
+	if len(failed) != 1 || !errors.Is(failed[0], ErrWebhookStatus) {
+		t.Errorf("Expected one failed delivery, got %v", failed)
+	}
+	if n := calls.Load(); n != 2 {
+		t.Errorf("Expected 2 signed requests, got %d", n)
+	}
+}
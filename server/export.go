@@ -0,0 +1,128 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+
+	h5p "github.com/grokify/h5p-go"
+	"github.com/grokify/h5p-go/storage"
+)
+
+var ErrExportDisabled = errors.New("export is disabled for this content")
+
+// ExportHandler serves stored content as .h5p downloads, at the ExportURL
+// given to AddContent. Register it with a pattern holding the wildcard
+// contentId:
+//
+//	mux.Handle("GET /h5p/exports/{contentId}", exports)
+//
+// The archive is rebuilt from the content and its resolved libraries, and
+// streamed to the client as it is written.
+type ExportHandler struct {
+	Packages storage.PackageStore
+	// Libraries, if set, supplies the libraries of exports, so content is
+	// downloaded with the versions installed on the server. Libraries not
+	// installed are taken from the stored package.
+	Libraries storage.LibraryStorage
+	// DisplayOptions, if set, returns the display options of content;
+	// downloads of content without the Export option are refused.
+	DisplayOptions func(ctx context.Context, id string) (DisplayOptions, error)
+	// WriteOptions configures compression.
+	WriteOptions h5p.WriteOptions
+	// Events, if set, receives an EventPackageExported per completed
+	// download.
+	Events *EventBus
+}
+
+func (h *ExportHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id := r.PathValue("contentId")
+	if h.DisplayOptions != nil {
+		opts, err := h.DisplayOptions(ctx, id)
+		if err != nil {
+			serveError(w, err)
+			return
+		}
+		if !opts.Export {
+			http.Error(w, ErrExportDisabled.Error(), http.StatusForbidden)
+			return
+		}
+	}
+
+	pkg, err := h.Packages.LoadPackage(ctx, id)
+	if err != nil {
+		serveError(w, err)
+		return
+	}
+	defer pkg.Close()
+	export, err := h.Export(ctx, pkg)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": ExportFilename(pkg, id)}))
+	w.Header().Set("Cache-Control", "no-store")
+	// Errors past this point cannot change the status; the client sees a
+	// truncated archive.
+	if _, err := export.WriteToContext(ctx, w, h.WriteOptions); err != nil {
+		return
+	}
+	h.Events.Publish(ctx, Event{Type: EventPackageExported, ContentID: id, Title: pkg.PackageDefinition.Title})
+}
+
+// Export returns a package of the content of pkg with the libraries its
+// h5p.json preloadedDependencies resolve to, leaving out libraries the
+// content does not use. The definition, content and files are shared with
+// pkg; the integrity manifest is dropped, as libraries may differ.
+func (h *ExportHandler) Export(ctx context.Context, pkg *h5p.H5PPackage) (*h5p.H5PPackage, error) {
+	if pkg.PackageDefinition == nil || pkg.Content == nil {
+		return nil, ErrNoContent
+	}
+	libs, err := resolveLibraries(pkg.PackageDefinition.PreloadedDependencies, false, func(dep h5p.LibraryDependency) (*h5p.Library, error) {
+		if h.Libraries != nil {
+			lib, err := h.Libraries.LoadLibrary(ctx, folderName(dep))
+			if !errors.Is(err, storage.ErrNotFound) {
+				return lib, err
+			}
+		}
+		if lib := pkg.GetLibrary(dep.MachineName, dep.MajorVersion, dep.MinorVersion); lib != nil {
+			return lib, nil
+		}
+		return nil, storage.ErrNotFound
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	export := h5p.NewH5PPackage()
+	export.SetPackageDefinition(pkg.PackageDefinition)
+	export.SetContent(pkg.Content)
+	export.ContentFiles = pkg.ContentFiles
+	export.ExtraFiles = pkg.ExtraFiles
+	for _, lib := range libs {
+		export.AddLibrary(lib)
+	}
+	return export, nil
+}
+
+// ExportFilename returns the download name H5P gives content, its title
+// as a slug followed by the id, e.g. "my-quiz-7.h5p".
+func ExportFilename(pkg *h5p.H5PPackage, id string) string {
+	slug := ""
+	if pkg.PackageDefinition != nil {
+		slug = strings.Trim(unsafeNameChars.ReplaceAllString(strings.ToLower(pkg.PackageDefinition.Title), "-"), "-")
+	}
+	if len(slug) > 60 {
+		slug = strings.TrimRight(slug[:60], "-")
+	}
+	if slug == "" {
+		slug = "interactive"
+	}
+	return fmt.Sprintf("%s-%s.h5p", slug, id)
+}
@@ -0,0 +1,77 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	h5p "github.com/grokify/h5p-go"
+	"github.com/grokify/h5p-go/storage"
+)
+
+func TestExportHandler(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewMemoryStore()
+	pkg := testPackage()
+	pkg.AddLibrary(&h5p.Library{
+		MachineName: "H5P.Unused-1.0",
+		Definition:  &h5p.LibraryDefinition{MachineName: "H5P.Unused", MajorVersion: 1},
+	})
+	if err := store.SavePackage(ctx, "7", pkg); err != nil {
+		t.Fatal(err)
+	}
+	// An installed patch release replaces the one the content came with.
+	installed := &h5p.Library{
+		MachineName: "H5P.Question-1.5",
+		Definition:  &h5p.LibraryDefinition{Title: "Question", MachineName: "H5P.Question", MajorVersion: 1, MinorVersion: 5, PatchVersion: 9},
+	}
+	if err := store.SaveLibrary(ctx, installed); err != nil {
+		t.Fatal(err)
+	}
+
+	export := map[string]bool{"7": true}
+	h := &ExportHandler{
+		Packages:  store,
+		Libraries: store,
+		DisplayOptions: func(_ context.Context, id string) (DisplayOptions, error) {
+			return DisplayOptions{Export: export[id]}, nil
+		},
+	}
+	mux := http.NewServeMux()
+	mux.Handle("GET /h5p/exports/{contentId}", h)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/h5p/exports/7", nil))
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/zip" {
+		t.Fatalf("Unexpected response %d %s", rec.Code, rec.Body)
+	}
+	if cd := rec.Header().Get("Content-Disposition"); cd != `attachment; filename=quiz-co-7.h5p` {
+		t.Errorf("Unexpected disposition %q", cd)
+	}
+	got, err := h5p.LoadH5PPackageFromReader(bytes.NewReader(rec.Body.Bytes()), int64(rec.Body.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Libraries) != 2 || got.GetLibrary("H5P.Unused", 1, 0) != nil {
+		t.Errorf("Expected only the used libraries, got %d", len(got.Libraries))
+	}
+	if lib := got.GetLibrary("H5P.Question", 1, 5); lib == nil || lib.Definition.PatchVersion != 9 {
+		t.Error("Expected the installed library exported")
+	}
+
+	export["7"] = false
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/h5p/exports/7", nil))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 with export disabled, got %d", rec.Code)
+	}
+
+	h.DisplayOptions = nil
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/h5p/exports/8", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for missing content, got %d", rec.Code)
+	}
+}
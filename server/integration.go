@@ -0,0 +1,231 @@
+// Package server runs H5P content from a Go backend with the official H5P
+// core JavaScript of h5p-php-library. It renders player pages with the
+// H5PIntegration settings the core reads, the way the PHP platforms do.
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"maps"
+)
+
+// Embed types of content.
+const (
+	EmbedDiv    = "div"
+	EmbedIframe = "iframe"
+)
+
+var ErrNoContent = errors.New("package has no content")
+
+// CoreScripts and CoreStyles are the h5p-php-library files the player
+// loads, relative to its folder, as listed by H5PCore as of version 1.26.
+var (
+	CoreScripts = []string{
+		"js/jquery.js",
+		"js/h5p.js",
+		"js/h5p-event-dispatcher.js",
+		"js/h5p-x-api-event.js",
+		"js/h5p-x-api.js",
+		"js/h5p-content-type.js",
+		"js/h5p-confirmation-dialog.js",
+		"js/h5p-action-bar.js",
+		"js/request-queue.js",
+		"js/h5p-tooltip.js",
+	}
+	CoreStyles = []string{
+		"styles/h5p.css",
+		"styles/h5p-confirmation-dialog.css",
+		"styles/h5p-core-button.css",
+		"styles/h5p-tooltip.css",
+	}
+)
+
+// DefaultL10n holds the English strings of the H5P core UI, sent as
+// H5PIntegration.l10n.H5P.
+var DefaultL10n = map[string]string{
+	"fullscreen":                    "Fullscreen",
+	"disableFullscreen":             "Disable fullscreen",
+	"download":                      "Download",
+	"copyrights":                    "Rights of use",
+	"embed":                         "Embed",
+	"size":                          "Size",
+	"showAdvanced":                  "Show advanced",
+	"hideAdvanced":                  "Hide advanced",
+	"advancedHelp":                  "Include this script on your website if you want dynamic sizing of the embedded content:",
+	"copyrightInformation":          "Rights of use",
+	"close":                         "Close",
+	"title":                         "Title",
+	"author":                        "Author",
+	"year":                          "Year",
+	"source":                        "Source",
+	"license":                       "License",
+	"thumbnail":                     "Thumbnail",
+	"noCopyrights":                  "No copyright information available for this content.",
+	"reuse":                         "Reuse",
+	"reuseContent":                  "Reuse Content",
+	"reuseDescription":              "Reuse this content.",
+	"downloadDescription":           "Download this content as a H5P file.",
+	"copyrightsDescription":         "View copyright information for this content.",
+	"embedDescription":              "View the embed code for this content.",
+	"h5pDescription":                "Visit H5P.org to check out more cool content.",
+	"contentChanged":                "This content has changed since you last used it.",
+	"startingOver":                  "You'll be starting over.",
+	"by":                            "by",
+	"showMore":                      "Show more",
+	"showLess":                      "Show less",
+	"subLevel":                      "Sublevel",
+	"confirmDialogHeader":           "Confirm action",
+	"confirmDialogBody":             "Please confirm that you wish to proceed. This action is not reversible.",
+	"cancelLabel":                   "Cancel",
+	"confirmLabel":                  "Confirm",
+	"licenseU":                      "Undisclosed",
+	"licenseCCBY":                   "Attribution",
+	"licenseCCBYSA":                 "Attribution-ShareAlike",
+	"licenseCCBYND":                 "Attribution-NoDerivs",
+	"licenseCCBYNC":                 "Attribution-NonCommercial",
+	"licenseCCBYNCSA":               "Attribution-NonCommercial-ShareAlike",
+	"licenseCCBYNCND":               "Attribution-NonCommercial-NoDerivs",
+	"licenseCC40":                   "4.0 International",
+	"licenseCC30":                   "3.0 Unported",
+	"licenseCC25":                   "2.5 Generic",
+	"licenseCC20":                   "2.0 Generic",
+	"licenseCC10":                   "1.0 Generic",
+	"licenseGPL":                    "General Public License",
+	"licenseV3":                     "Version 3",
+	"licenseV2":                     "Version 2",
+	"licenseV1":                     "Version 1",
+	"licensePD":                     "Public Domain",
+	"licenseCC010":                  "CC0 1.0 Universal (CC0 1.0) Public Domain Dedication",
+	"licensePDM":                    "Public Domain Mark",
+	"licenseC":                      "Copyright",
+	"contentType":                   "Content Type",
+	"licenseExtras":                 "License Extras",
+	"changes":                       "Changelog",
+	"contentCopied":                 "Content is copied to the clipboard",
+	"connectionLost":                "Connection lost. Results will be stored and sent when you regain connection.",
+	"connectionReestablished":       "Connection reestablished.",
+	"resubmitScores":                "Attempting to submit stored results.",
+	"offlineDialogHeader":           "Your connection to the server was lost",
+	"offlineDialogBody":             "We were unable to send information about your completion of this task. Please check your internet connection.",
+	"offlineDialogRetryMessage":     "Retrying in :num....",
+	"offlineDialogRetryButtonLabel": "Retry now",
+	"offlineSuccessfulSubmit":       "Successfully submitted results.",
+}
+
+// Integration is the H5PIntegration object the H5P core reads its
+// settings and the content to show from.
+type Integration struct {
+	BaseURL            string `json:"baseUrl"`
+	URL                string `json:"url"`
+	PostUserStatistics bool   `json:"postUserStatistics"`
+	AjaxPath           string `json:"ajaxPath"`
+	Ajax               Ajax   `json:"ajax"`
+	// SaveFreq is how often, in seconds, the player saves the user's
+	// state; 0 turns saving off.
+	SaveFreq           SaveFreq                     `json:"saveFreq"`
+	SiteURL            string                       `json:"siteUrl"`
+	L10n               map[string]map[string]string `json:"l10n"`
+	User               *User                        `json:"user,omitempty"`
+	HubIsEnabled       bool                         `json:"hubIsEnabled"`
+	ReportingIsEnabled bool                         `json:"reportingIsEnabled"`
+	CrossOrigin        string                       `json:"crossorigin,omitempty"`
+	LibraryConfig      map[string]any               `json:"libraryConfig,omitempty"`
+	PluginCacheBuster  string                       `json:"pluginCacheBuster"`
+	LibraryURL         string                       `json:"libraryUrl"`
+	Core               Assets                       `json:"core"`
+	// LoadedJS and LoadedCSS list the assets already on the page, which
+	// the core does not load again.
+	LoadedJS  []string                    `json:"loadedJs"`
+	LoadedCSS []string                    `json:"loadedCss"`
+	Contents  map[string]*ContentSettings `json:"contents"`
+	// Editor is set on pages embedding the H5P editor.
+	Editor *EditorIntegration `json:"editor,omitempty"`
+}
+
+// Ajax holds the endpoints the player posts results and user data to.
+// ContentUserData contains the placeholders :contentId, :dataType and
+// :subContentId.
+type Ajax struct {
+	SetFinished     string `json:"setFinished"`
+	ContentUserData string `json:"contentUserData"`
+}
+
+// SaveFreq is encoded as false when 0, as the core expects.
+type SaveFreq int
+
+func (f SaveFreq) MarshalJSON() ([]byte, error) {
+	if f <= 0 {
+		return []byte("false"), nil
+	}
+	return json.Marshal(int(f))
+}
+
+// User identifies the learner in xAPI statements.
+type User struct {
+	Name string `json:"name"`
+	Mail string `json:"mail"`
+}
+
+// Assets lists script and style URLs.
+type Assets struct {
+	Scripts []string `json:"scripts"`
+	Styles  []string `json:"styles"`
+}
+
+// Settings configures the site-wide part of H5PIntegration.
+type Settings struct {
+	// BaseURL is the site origin, e.g. "https://example.com".
+	BaseURL string
+	// URL is the path H5P files are served under: library folders at
+	// URL/libraries/<folder>/ and the files of content at
+	// URL/content/<id>/.
+	URL string
+	// CoreURL is the path of the h5p-php-library folder holding the core
+	// js and styles folders.
+	CoreURL string
+	// SetFinishedURL and ContentUserDataURL are the result and user data
+	// endpoints, see Ajax.
+	SetFinishedURL     string
+	ContentUserDataURL string
+	// SaveFreq is how often to save the user's state, in seconds.
+	SaveFreq int
+	// PostUserStatistics sends results to SetFinishedURL.
+	PostUserStatistics bool
+	User               *User
+	// L10n overrides and adds to DefaultL10n.
+	L10n        map[string]string
+	CrossOrigin string
+	// CacheBuster is appended to asset URLs, e.g. "?v=1.26".
+	CacheBuster string
+}
+
+// NewIntegration returns settings without content; add it with
+// AddContent.
+func NewIntegration(s Settings) *Integration {
+	l10n := maps.Clone(DefaultL10n)
+	maps.Copy(l10n, s.L10n)
+	in := &Integration{
+		BaseURL:            s.BaseURL,
+		URL:                s.URL,
+		PostUserStatistics: s.PostUserStatistics,
+		AjaxPath:           s.SetFinishedURL,
+		Ajax:               Ajax{SetFinished: s.SetFinishedURL, ContentUserData: s.ContentUserDataURL},
+		SaveFreq:           SaveFreq(s.SaveFreq),
+		SiteURL:            s.BaseURL,
+		L10n:               map[string]map[string]string{"H5P": l10n},
+		User:               s.User,
+		CrossOrigin:        s.CrossOrigin,
+		PluginCacheBuster:  s.CacheBuster,
+		LibraryURL:         s.CoreURL + "/js",
+		LoadedJS:           []string{},
+		LoadedCSS:          []string{},
+		Contents:           map[string]*ContentSettings{},
+	}
+	for _, name := range CoreScripts {
+		in.Core.Scripts = append(in.Core.Scripts, s.CoreURL+"/"+name+s.CacheBuster)
+	}
+	for _, name := range CoreStyles {
+		in.Core.Styles = append(in.Core.Styles, s.CoreURL+"/"+name+s.CacheBuster)
+	}
+	return in
+}
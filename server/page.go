@@ -0,0 +1,61 @@
+package server
+
+import (
+	"html/template"
+	"io"
+	"slices"
+)
+
+var pageTemplate = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+{{- range .Styles}}
+<link rel="stylesheet" href="{{.}}">
+{{- end}}
+<script>
+H5PIntegration = {{.Integration}};
+</script>
+{{- range .Scripts}}
+<script src="{{.}}"></script>
+{{- end}}
+</head>
+<body>
+{{- range .Contents}}
+{{.}}
+{{- end}}
+</body>
+</html>
+`))
+
+type pageData struct {
+	Title       string
+	Styles      []string
+	Scripts     []string
+	Integration *Integration
+	Contents    []template.HTML
+}
+
+// WritePage writes a complete HTML page playing all added content, in the
+// order of their IDs. The page loads the core assets and the assets of
+// div-embedded content; use ContentHTML to place content in pages of your
+// own instead.
+func (in *Integration) WritePage(w io.Writer, title string) error {
+	data := pageData{
+		Title:       title,
+		Styles:      append(slices.Clone(in.Core.Styles), in.LoadedCSS...),
+		Scripts:     append(slices.Clone(in.Core.Scripts), in.LoadedJS...),
+		Integration: in,
+	}
+	ids := make([]string, 0, len(in.Contents))
+	for _, cs := range in.Contents {
+		ids = append(ids, cs.MainID)
+	}
+	slices.Sort(ids)
+	for _, id := range ids {
+		data.Contents = append(data.Contents, template.HTML(in.ContentHTML(id)))
+	}
+	return pageTemplate.Execute(w, data)
+}
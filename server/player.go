@@ -0,0 +1,198 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"slices"
+	"strings"
+
+	h5p "github.com/grokify/h5p-go"
+)
+
+// ContentSettings is an entry of H5PIntegration.contents, keyed by
+// "cid-<id>".
+type ContentSettings struct {
+	// Library is the main library, e.g. "H5P.MultiChoice 1.16".
+	Library string `json:"library"`
+	// JSONContent is content.json as a string.
+	JSONContent     string               `json:"jsonContent"`
+	FullScreen      bool                 `json:"fullScreen"`
+	ExportURL       string               `json:"exportUrl"`
+	EmbedCode       string               `json:"embedCode"`
+	ResizeCode      string               `json:"resizeCode"`
+	MainID          string               `json:"mainId"`
+	URL             string               `json:"url"`
+	Title           string               `json:"title"`
+	ContentUserData []map[string]any     `json:"contentUserData"`
+	DisplayOptions  DisplayOptions       `json:"displayOptions"`
+	Metadata        *h5p.ContentMetadata `json:"metadata"`
+	// Scripts and Styles are the library files the player loads into the
+	// iframe of iframe-embedded content.
+	Scripts []string `json:"scripts,omitempty"`
+	Styles  []string `json:"styles,omitempty"`
+
+	embedType string
+}
+
+// DisplayOptions selects the frame and the buttons of the action bar
+// below the content.
+type DisplayOptions struct {
+	Frame     bool `json:"frame"`
+	Export    bool `json:"export"`
+	Embed     bool `json:"embed"`
+	Copyright bool `json:"copyright"`
+	Icon      bool `json:"icon"`
+	Copy      bool `json:"copy"`
+}
+
+// ContentOptions describes content added with AddContent.
+type ContentOptions struct {
+	// ID identifies the content in the page and in the URLs of its files
+	// and AJAX requests.
+	ID string
+	// EmbedType is EmbedIframe or EmbedDiv. By default content is put in
+	// an iframe unless its libraries only allow div.
+	EmbedType      string
+	DisplayOptions DisplayOptions
+	// ExportURL is where the .h5p file is downloaded from, for the
+	// download button.
+	ExportURL string
+	// EmbedCode and ResizeCode are shown by the embed button, see the
+	// embedcode package.
+	EmbedCode  string
+	ResizeCode string
+	// State is the user's saved state of the content, as previously
+	// posted to the contentUserData endpoint, or "" to start over.
+	State string
+}
+
+// AddContent adds the content of pkg to the page. Its libraries must be
+// served at Settings.URL/libraries/<folder>/ and its content files at
+// Settings.URL/content/<id>/.
+func (in *Integration) AddContent(pkg *h5p.H5PPackage, opts ContentOptions) error {
+	def := pkg.PackageDefinition
+	if def == nil || pkg.Content == nil {
+		return ErrNoContent
+	}
+	i := slices.IndexFunc(def.PreloadedDependencies, func(d h5p.LibraryDependency) bool { return d.MachineName == def.MainLibrary })
+	if i < 0 {
+		return fmt.Errorf("%w: main library %s is not a preloaded dependency", ErrNoContent, def.MainLibrary)
+	}
+	libs, err := pkg.ResolveDependencies()
+	if err != nil {
+		return err
+	}
+	params, err := json.Marshal(pkg.Content)
+	if err != nil {
+		return fmt.Errorf("failed to encode content: %w", err)
+	}
+
+	cs := &ContentSettings{
+		Library:         def.PreloadedDependencies[i].String(),
+		JSONContent:     string(params),
+		ExportURL:       opts.ExportURL,
+		EmbedCode:       opts.EmbedCode,
+		ResizeCode:      opts.ResizeCode,
+		MainID:          opts.ID,
+		URL:             in.URL + "/content/" + opts.ID,
+		Title:           def.Title,
+		ContentUserData: []map[string]any{{"state": false}},
+		DisplayOptions:  opts.DisplayOptions,
+		Metadata: &h5p.ContentMetadata{
+			Title:           def.Title,
+			License:         def.License,
+			LicenseVersion:  def.LicenseVersion,
+			LicenseExtras:   def.LicenseExtras,
+			Authors:         def.Authors,
+			Source:          def.Source,
+			YearFrom:        def.YearFrom,
+			YearTo:          def.YearTo,
+			DefaultLanguage: def.DefaultLanguage,
+		},
+		embedType: embedType(opts.EmbedType, def.EmbedTypes),
+	}
+	if opts.State != "" {
+		cs.ContentUserData[0]["state"] = opts.State
+	}
+
+	// Libraries listed in dropLibraryCss have their styles replaced by
+	// those of the library dropping them.
+	drop := map[string]bool{}
+	for _, lib := range libs {
+		if lib.Definition == nil {
+			continue
+		}
+		if lib.Definition.MachineName == def.MainLibrary {
+			cs.FullScreen = bool(lib.Definition.Fullscreen)
+		}
+		for _, ref := range lib.Definition.DropLibraryCss {
+			drop[ref.MachineName] = true
+		}
+	}
+	for _, lib := range libs {
+		if lib.Definition == nil {
+			continue
+		}
+		base := in.URL + "/libraries/" + lib.MachineName + "/"
+		for _, f := range lib.Definition.PreloadedJs {
+			cs.Scripts = append(cs.Scripts, base+f.Path+in.PluginCacheBuster)
+		}
+		if drop[lib.Definition.MachineName] {
+			continue
+		}
+		for _, f := range lib.Definition.PreloadedCss {
+			cs.Styles = append(cs.Styles, base+f.Path+in.PluginCacheBuster)
+		}
+	}
+
+	// Div-embedded content runs in the page, so its assets are loaded with
+	// it and listed as loaded; iframes load them from the settings.
+	if cs.embedType == EmbedDiv {
+		for _, s := range cs.Scripts {
+			if !slices.Contains(in.LoadedJS, s) {
+				in.LoadedJS = append(in.LoadedJS, s)
+			}
+		}
+		for _, s := range cs.Styles {
+			if !slices.Contains(in.LoadedCSS, s) {
+				in.LoadedCSS = append(in.LoadedCSS, s)
+			}
+		}
+		cs.Scripts, cs.Styles = nil, nil
+	}
+	in.Contents["cid-"+opts.ID] = cs
+	return nil
+}
+
+// embedType picks the embed type the way H5PCore::determineEmbedType does:
+// the requested type if the content allows it, otherwise div if allowed,
+// otherwise iframe.
+func embedType(requested string, allowed []string) string {
+	t := EmbedIframe
+	if strings.EqualFold(requested, EmbedDiv) {
+		t = EmbedDiv
+	}
+	if len(allowed) == 0 || slices.Contains(allowed, t) {
+		return t
+	}
+	if slices.Contains(allowed, EmbedDiv) {
+		return EmbedDiv
+	}
+	return EmbedIframe
+}
+
+// ContentHTML returns the element the H5P core replaces with the content
+// of the given ID, or "" if it was not added.
+func (in *Integration) ContentHTML(id string) string {
+	cs, ok := in.Contents["cid-"+id]
+	if !ok {
+		return ""
+	}
+	id = html.EscapeString(id)
+	if cs.embedType == EmbedDiv {
+		return fmt.Sprintf(`<div class="h5p-content" data-content-id="%s"></div>`, id)
+	}
+	return fmt.Sprintf(`<div class="h5p-iframe-wrapper"><iframe id="h5p-iframe-%s" class="h5p-iframe" data-content-id="%s" style="height:1px" src="about:blank" frameBorder="0" scrolling="no" title="%s"></iframe></div>`,
+		id, id, html.EscapeString(cs.Title))
+}
@@ -0,0 +1,168 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	h5p "github.com/grokify/h5p-go"
+)
+
+func testPackage(embedTypes ...string) *h5p.H5PPackage {
+	pkg := h5p.NewH5PPackage()
+	pkg.SetPackageDefinition(&h5p.PackageDefinition{
+		Title:                 "Quiz & <Co>",
+		Language:              "en",
+		MainLibrary:           "H5P.MultiChoice",
+		EmbedTypes:            embedTypes,
+		License:               "CC BY",
+		PreloadedDependencies: []h5p.LibraryDependency{{MachineName: "H5P.MultiChoice", MajorVersion: 1, MinorVersion: 16}},
+	})
+	pkg.SetContent(&h5p.Content{Params: map[string]any{"question": "<p>2 + 2?</p>"}})
+	pkg.AddLibrary(&h5p.Library{
+		MachineName: "H5P.MultiChoice-1.16",
+		Definition: &h5p.LibraryDefinition{
+			MachineName: "H5P.MultiChoice", MajorVersion: 1, MinorVersion: 16, Runnable: true, Fullscreen: true,
+			PreloadedJs:    []h5p.FileReference{{Path: "js/multichoice.js"}},
+			PreloadedCss:   []h5p.FileReference{{Path: "css/multichoice.css"}},
+			DropLibraryCss: []h5p.LibraryReference{{MachineName: "H5P.Question"}},
+			Dependencies:   []h5p.LibraryDependency{{MachineName: "H5P.Question", MajorVersion: 1, MinorVersion: 5}},
+		},
+	})
+	pkg.AddLibrary(&h5p.Library{
+		MachineName: "H5P.Question-1.5",
+		Definition: &h5p.LibraryDefinition{
+			MachineName: "H5P.Question", MajorVersion: 1, MinorVersion: 5,
+			PreloadedJs:  []h5p.FileReference{{Path: "scripts/question.js"}},
+			PreloadedCss: []h5p.FileReference{{Path: "styles/question.css"}},
+		},
+	})
+	return pkg
+}
+
+func testIntegration() *Integration {
+	return NewIntegration(Settings{
+		BaseURL:            "https://example.com",
+		URL:                "/h5p",
+		CoreURL:            "/h5p/core",
+		SetFinishedURL:     "/h5p/ajax/setFinished",
+		ContentUserDataURL: "/h5p/ajax/contentUserData/:contentId/:dataType/:subContentId",
+		L10n:               map[string]string{"fullscreen": "Vollbild"},
+		CacheBuster:        "?v=1",
+	})
+}
+
+func TestAddContentIframe(t *testing.T) {
+	in := testIntegration()
+	if err := in.AddContent(testPackage(), ContentOptions{ID: "7", State: `{"answers":[0]}`}); err != nil {
+		t.Fatal(err)
+	}
+	cs := in.Contents["cid-7"]
+	if cs == nil {
+		t.Fatalf("Expected content cid-7, got %v", in.Contents)
+	}
+	if cs.Library != "H5P.MultiChoice 1.16" || cs.JSONContent != `{"question":"\u003cp\u003e2 + 2?\u003c/p\u003e"}` || !cs.FullScreen {
+		t.Errorf("Unexpected settings %+v", cs)
+	}
+	if cs.URL != "/h5p/content/7" || cs.Metadata.License != "CC BY" || cs.ContentUserData[0]["state"] != `{"answers":[0]}` {
+		t.Errorf("Unexpected settings %+v", cs)
+	}
+	wantScripts := []string{"/h5p/libraries/H5P.Question-1.5/scripts/question.js?v=1", "/h5p/libraries/H5P.MultiChoice-1.16/js/multichoice.js?v=1"}
+	if !reflect.DeepEqual(cs.Scripts, wantScripts) {
+		t.Errorf("Expected scripts %v, got %v", wantScripts, cs.Scripts)
+	}
+	// The styles of H5P.Question are dropped by H5P.MultiChoice.
+	if want := []string{"/h5p/libraries/H5P.MultiChoice-1.16/css/multichoice.css?v=1"}; !reflect.DeepEqual(cs.Styles, want) {
+		t.Errorf("Expected styles %v, got %v", want, cs.Styles)
+	}
+	if len(in.LoadedJS) != 0 {
+		t.Errorf("Expected no assets loaded in the page, got %v", in.LoadedJS)
+	}
+	if html := in.ContentHTML("7"); !strings.Contains(html, `<iframe id="h5p-iframe-7"`) || !strings.Contains(html, `title="Quiz &amp; &lt;Co&gt;"`) {
+		t.Errorf("Unexpected content HTML %s", html)
+	}
+}
+
+func TestAddContentDiv(t *testing.T) {
+	in := testIntegration()
+	if err := in.AddContent(testPackage("div"), ContentOptions{ID: "1", EmbedType: EmbedIframe}); err != nil {
+		t.Fatal(err)
+	}
+	cs := in.Contents["cid-1"]
+	if cs.Scripts != nil || len(in.LoadedJS) != 2 || len(in.LoadedCSS) != 1 {
+		t.Errorf("Expected the assets loaded in the page, got %v %v %v", cs.Scripts, in.LoadedJS, in.LoadedCSS)
+	}
+	if cs.ContentUserData[0]["state"] != false {
+		t.Errorf("Expected no state, got %v", cs.ContentUserData)
+	}
+	if html := in.ContentHTML("1"); html != `<div class="h5p-content" data-content-id="1"></div>` {
+		t.Errorf("Unexpected content HTML %s", html)
+	}
+
+	if err := in.AddContent(h5p.NewH5PPackage(), ContentOptions{ID: "2"}); !errors.Is(err, ErrNoContent) {
+		t.Errorf("Expected ErrNoContent, got %v", err)
+	}
+}
+
+func TestEmbedType(t *testing.T) {
+	tests := []struct {
+		requested string
+		allowed   []string
+		want      string
+	}{
+		{"", nil, EmbedIframe},
+		{"div", nil, EmbedDiv},
+		{"div", []string{"iframe"}, EmbedIframe},
+		{"iframe", []string{"div"}, EmbedDiv},
+		{"", []string{"div", "iframe"}, EmbedIframe},
+	}
+	for _, tt := range tests {
+		if got := embedType(tt.requested, tt.allowed); got != tt.want {
+			t.Errorf("embedType(%q, %v) = %q, want %q", tt.requested, tt.allowed, got, tt.want)
+		}
+	}
+}
+
+func TestWritePage(t *testing.T) {
+	in := testIntegration()
+	if err := in.AddContent(testPackage(), ContentOptions{ID: "7"}); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := in.WritePage(&buf, "Quiz"); err != nil {
+		t.Fatal(err)
+	}
+	page := buf.String()
+	for _, want := range []string{
+		`<link rel="stylesheet" href="/h5p/core/styles/h5p.css?v=1">`,
+		`<script src="/h5p/core/js/h5p.js?v=1"></script>`,
+		`<iframe id="h5p-iframe-7"`,
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("Expected %s in page:\n%s", want, page)
+		}
+	}
+
+	start := strings.Index(page, "H5PIntegration = ")
+	end := strings.Index(page, ";\n</script>")
+	if start < 0 || end < start {
+		t.Fatalf("No H5PIntegration in page:\n%s", page)
+	}
+	var settings map[string]any
+	if err := json.Unmarshal([]byte(page[start+len("H5PIntegration = "):end]), &settings); err != nil {
+		t.Fatalf("Invalid H5PIntegration: %v", err)
+	}
+	if settings["saveFreq"] != false || settings["libraryUrl"] != "/h5p/core/js" {
+		t.Errorf("Unexpected settings %v", settings)
+	}
+	l10n := settings["l10n"].(map[string]any)["H5P"].(map[string]any)
+	if l10n["fullscreen"] != "Vollbild" || l10n["close"] != "Close" {
+		t.Errorf("Unexpected l10n %v", l10n)
+	}
+	if _, ok := settings["contents"].(map[string]any)["cid-7"]; !ok {
+		t.Errorf("Expected cid-7 in contents, got %v", settings["contents"])
+	}
+}
@@ -0,0 +1,242 @@
+package server
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/grokify/h5p-go/storage"
+)
+
+var ErrInvalidAttempt = errors.New("invalid attempt")
+
+// Attempt is a result the player posts to the setFinished endpoint when a
+// user completes content.
+type Attempt struct {
+	ContentID string    `json:"contentId"`
+	UserID    string    `json:"userId"`
+	Score     int       `json:"score"`
+	MaxScore  int       `json:"maxScore"`
+	Opened    time.Time `json:"opened"`
+	Finished  time.Time `json:"finished"`
+	// Time is the time spent as reported by the content type, which most
+	// leave out.
+	Time time.Duration `json:"time,omitempty"`
+}
+
+// Scaled returns the score from 0 to 1, or 0 if MaxScore is 0.
+func (a Attempt) Scaled() float64 {
+	if a.MaxScore <= 0 {
+		return 0
+	}
+	return float64(a.Score) / float64(a.MaxScore)
+}
+
+// Duration returns the time from opening to finishing the content.
+func (a Attempt) Duration() time.Duration {
+	return a.Finished.Sub(a.Opened)
+}
+
+// Validate checks that the attempt identifies content and a user and has
+// a score and times in range.
+func (a Attempt) Validate() error {
+	switch {
+	case a.ContentID == "" || a.UserID == "":
+		return fmt.Errorf("%w: content and user are required", ErrInvalidAttempt)
+	case a.MaxScore < 0 || a.Score < 0 || a.Score > a.MaxScore:
+		return fmt.Errorf("%w: score %d of %d", ErrInvalidAttempt, a.Score, a.MaxScore)
+	case a.Opened.Unix() <= 0 || a.Finished.Before(a.Opened):
+		return fmt.Errorf("%w: opened %s, finished %s", ErrInvalidAttempt, a.Opened, a.Finished)
+	}
+	return nil
+}
+
+// AttemptFilter selects attempts; empty fields match all.
+type AttemptFilter struct {
+	ContentID string
+	UserID    string
+}
+
+func (f AttemptFilter) match(a *Attempt) bool {
+	return (f.ContentID == "" || f.ContentID == a.ContentID) && (f.UserID == "" || f.UserID == a.UserID)
+}
+
+// AttemptStore keeps the attempts of users.
+type AttemptStore interface {
+	Add(ctx context.Context, a *Attempt) error
+	// List returns the matching attempts in the order they were added.
+	List(ctx context.Context, f AttemptFilter) ([]Attempt, error)
+}
+
+// MemoryAttemptStore is an AttemptStore in memory. The attempts of each
+// tenant, see storage.WithTenant, are kept apart.
+type MemoryAttemptStore struct {
+	mu       sync.RWMutex
+	attempts []tenantAttempt
+}
+
+type tenantAttempt struct {
+	tenant string
+	Attempt
+}
+
+func NewMemoryAttemptStore() *MemoryAttemptStore {
+	return &MemoryAttemptStore{}
+}
+
+func (s *MemoryAttemptStore) Add(ctx context.Context, a *Attempt) error {
+	if err := a.Validate(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attempts = append(s.attempts, tenantAttempt{storage.Tenant(ctx), *a})
+	return nil
+}
+
+func (s *MemoryAttemptStore) List(ctx context.Context, f AttemptFilter) ([]Attempt, error) {
+	tenant := storage.Tenant(ctx)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var out []Attempt
+	for i := range s.attempts {
+		if a := &s.attempts[i]; a.tenant == tenant && f.match(&a.Attempt) {
+			out = append(out, a.Attempt)
+		}
+	}
+	return out, nil
+}
+
+// BestAttempt returns the attempt with the highest scaled score, the
+// earliest finished of equal ones, or nil if there are none.
+func BestAttempt(attempts []Attempt) *Attempt {
+	var best *Attempt
+	for i := range attempts {
+		a := &attempts[i]
+		if best == nil || a.Scaled() > best.Scaled() || (a.Scaled() == best.Scaled() && a.Finished.Before(best.Finished)) {
+			best = a
+		}
+	}
+	return best
+}
+
+// LatestAttempt returns the last finished attempt, or nil if there are
+// none.
+func LatestAttempt(attempts []Attempt) *Attempt {
+	var latest *Attempt
+	for i := range attempts {
+		if a := &attempts[i]; latest == nil || !a.Finished.Before(latest.Finished) {
+			latest = a
+		}
+	}
+	return latest
+}
+
+// GradebookEntry summarizes the attempts of a user at content.
+type GradebookEntry struct {
+	ContentID string   `json:"contentId"`
+	UserID    string   `json:"userId"`
+	Attempts  int      `json:"attempts"`
+	Best      *Attempt `json:"best"`
+	Latest    *Attempt `json:"latest"`
+}
+
+// Gradebook groups attempts by content and user, sorted by content ID and
+// then user ID.
+func Gradebook(attempts []Attempt) []GradebookEntry {
+	type key struct{ content, user string }
+	groups := map[key][]Attempt{}
+	for _, a := range attempts {
+		k := key{a.ContentID, a.UserID}
+		groups[k] = append(groups[k], a)
+	}
+	entries := make([]GradebookEntry, 0, len(groups))
+	for k, g := range groups {
+		entries = append(entries, GradebookEntry{
+			ContentID: k.content,
+			UserID:    k.user,
+			Attempts:  len(g),
+			Best:      BestAttempt(g),
+			Latest:    LatestAttempt(g),
+		})
+	}
+	slices.SortFunc(entries, func(a, b GradebookEntry) int {
+		return cmp.Or(cmp.Compare(a.ContentID, b.ContentID), cmp.Compare(a.UserID, b.UserID))
+	})
+	return entries
+}
+
+// SetFinishedHandler serves the setFinished AJAX endpoint, which the
+// player posts to when Settings.PostUserStatistics is set. It records the
+// form fields contentId, score, maxScore, opened and finished, as Unix
+// times in seconds, and time as an attempt of the requesting user.
+type SetFinishedHandler struct {
+	Store AttemptStore
+	User  UserFunc
+	// Events, if set, receives an EventAttemptFinished per attempt.
+	Events *EventBus
+}
+
+func (h *SetFinishedHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		ajaxError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	user := h.User(r)
+	if user == "" {
+		ajaxError(w, http.StatusUnauthorized, "not logged in")
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		ajaxError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	a, err := parseAttempt(r, user)
+	if err == nil {
+		err = a.Validate()
+	}
+	if err != nil {
+		ajaxError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := h.Store.Add(r.Context(), a); err != nil {
+		ajaxError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.Events.Publish(r.Context(), Event{Type: EventAttemptFinished, ContentID: a.ContentID, UserID: user, Attempt: a})
+	ajaxSuccess(w, nil)
+}
+
+func parseAttempt(r *http.Request, user string) (*Attempt, error) {
+	// Scores are whole numbers in H5P, but some content types post
+	// fractions, which are rounded.
+	var ints [5]int64
+	for i, name := range []string{"score", "maxScore", "opened", "finished", "time"} {
+		v := r.PostForm.Get(name)
+		if v == "" && name == "time" {
+			continue
+		}
+		n, err := strconv.ParseFloat(v, 64)
+		if err != nil || math.IsNaN(n) || math.IsInf(n, 0) {
+			return nil, fmt.Errorf("%w: %s %q is not a number", ErrInvalidAttempt, name, v)
+		}
+		ints[i] = int64(math.Round(n))
+	}
+	return &Attempt{
+		ContentID: r.PostForm.Get("contentId"),
+		UserID:    user,
+		Score:     int(ints[0]),
+		MaxScore:  int(ints[1]),
+		Opened:    time.Unix(ints[2], 0).UTC(),
+		Finished:  time.Unix(ints[3], 0).UTC(),
+		Time:      time.Duration(ints[4]) * time.Second,
+	}, nil
+}
@@ -0,0 +1,118 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func attempt(content, user string, score, maxScore int, finished int64) Attempt {
+	return Attempt{
+		ContentID: content,
+		UserID:    user,
+		Score:     score,
+		MaxScore:  maxScore,
+		Opened:    time.Unix(finished-60, 0),
+		Finished:  time.Unix(finished, 0),
+	}
+}
+
+func TestAttemptValidate(t *testing.T) {
+	if err := attempt("7", "u1", 3, 4, 1000).Validate(); err != nil {
+		t.Errorf("Expected valid attempt, got %v", err)
+	}
+	for _, a := range []Attempt{
+		attempt("", "u1", 1, 2, 1000),
+		attempt("7", "u1", 3, 2, 1000),
+		attempt("7", "u1", -1, 2, 1000),
+		{ContentID: "7", UserID: "u1", Finished: time.Unix(1000, 0)},
+	} {
+		if err := a.Validate(); !errors.Is(err, ErrInvalidAttempt) {
+			t.Errorf("Expected ErrInvalidAttempt for %+v, got %v", a, err)
+		}
+	}
+}
+
+func TestGradebook(t *testing.T) {
+	attempts := []Attempt{
+		attempt("7", "u2", 1, 4, 1000),
+		attempt("7", "u1", 2, 4, 1000),
+		attempt("7", "u1", 4, 4, 2000),
+		attempt("7", "u1", 4, 4, 3000),
+		attempt("7", "u1", 1, 4, 4000),
+		attempt("3", "u1", 0, 0, 1000),
+	}
+	if best := BestAttempt(attempts[1:5]); best.Finished.Unix() != 2000 {
+		t.Errorf("Expected the first full score as best, got %+v", best)
+	}
+	if latest := LatestAttempt(attempts[1:5]); latest.Score != 1 {
+		t.Errorf("Expected the last attempt as latest, got %+v", latest)
+	}
+	if BestAttempt(nil) != nil || LatestAttempt(nil) != nil {
+		t.Error("Expected nil without attempts")
+	}
+
+	book := Gradebook(attempts)
+	if len(book) != 3 {
+		t.Fatalf("Expected 3 entries, got %+v", book)
+	}
+	if book[0].ContentID != "3" || book[1].UserID != "u1" || book[2].UserID != "u2" {
+		t.Errorf("Unexpected order %+v", book)
+	}
+	if e := book[1]; e.Attempts != 4 || e.Best.Score != 4 || e.Latest.Score != 1 {
+		t.Errorf("Unexpected entry %+v", e)
+	}
+}
+
+func TestSetFinishedHandler(t *testing.T) {
+	store := NewMemoryAttemptStore()
+	h := &SetFinishedHandler{Store: store, User: func(r *http.Request) string { return r.Header.Get("X-User") }}
+	post := func(user string, form url.Values) int {
+		req := httptest.NewRequest("POST", "/ajax/set-finished", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("X-User", user)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	form := url.Values{"contentId": {"7"}, "score": {"3"}, "maxScore": {"4"}, "opened": {"1000"}, "finished": {"1090"}}
+	if code := post("u1", form); code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", code)
+	}
+	form.Set("score", "2.6")
+	form.Set("time", "45")
+	if code := post("u2", form); code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", code)
+	}
+	got, err := store.List(context.Background(), AttemptFilter{ContentID: "7"})
+	if err != nil || len(got) != 2 {
+		t.Fatalf("Expected 2 attempts, got %+v, %v", got, err)
+	}
+	if a := got[0]; a.UserID != "u1" || a.Score != 3 || a.Duration() != 90*time.Second || a.Scaled() != 0.75 {
+		t.Errorf("Unexpected attempt %+v", a)
+	}
+	if a := got[1]; a.Score != 3 || a.Time != 45*time.Second {
+		t.Errorf("Expected a rounded score and time, got %+v", a)
+	}
+	if got, _ := store.List(context.Background(), AttemptFilter{UserID: "u2"}); len(got) != 1 {
+		t.Errorf("Expected 1 attempt of u2, got %+v", got)
+	}
+
+	form.Set("score", "5")
+	if code := post("u1", form); code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a score above the maximum, got %d", code)
+	}
+	form.Set("score", "x")
+	if code := post("u1", form); code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a non-numeric score, got %d", code)
+	}
+	if code := post("", form); code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without a user, got %d", code)
+	}
+}
@@ -0,0 +1,52 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/grokify/h5p-go/storage"
+)
+
+// TenantFunc returns the tenant of a request, one of several isolated H5P
+// sites served by a process, or "" for the default site.
+type TenantFunc func(r *http.Request) string
+
+// TenantHandler serves requests with the tenant returned by tenant in
+// their context, see storage.WithTenant, so the stores, caches and events
+// used by h keep the libraries, content and user data of each tenant
+// apart. Requests for an invalid tenant get 404.
+//
+// To take the tenant from a wildcard of the path, wrap the handlers
+// registered with it:
+//
+//	mux.Handle("GET /sites/{tenant}/h5p/content/{contentId}/{path...}",
+//		server.TenantHandler(func(r *http.Request) string { return r.PathValue("tenant") }, cache))
+func TenantHandler(tenant TenantFunc, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t := tenant(r)
+		if err := storage.CheckTenant(t); err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		h.ServeHTTP(w, r.WithContext(storage.WithTenant(r.Context(), t)))
+	})
+}
+
+// HostTenant returns a TenantFunc taking the tenant from the subdomain of
+// domain a request is for, e.g. "school" for school.example.com if domain
+// is "example.com". Requests for domain itself, or for other hosts, are
+// for the default site.
+func HostTenant(domain string) TenantFunc {
+	suffix := "." + strings.ToLower(domain)
+	return func(r *http.Request) string {
+		host := r.Host
+		if i := strings.LastIndexByte(host, ':'); i >= 0 && !strings.Contains(host[i:], "]") {
+			host = host[:i]
+		}
+		sub, ok := strings.CutSuffix(strings.ToLower(host), suffix)
+		if !ok || strings.Contains(sub, ".") {
+			return ""
+		}
+		return sub
+	}
+}
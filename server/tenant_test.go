@@ -0,0 +1,86 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grokify/h5p-go/storage"
+)
+
+func TestHostTenant(t *testing.T) {
+	tenant := HostTenant("example.com")
+	for host, want := range map[string]string{
+		"school.example.com":      "school",
+		"School.Example.com:8080": "school",
+		"example.com":             "",
+		"a.b.example.com":         "",
+		"school.example.org":      "",
+	} {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Host = host
+		if got := tenant(r); got != want {
+			t.Errorf("Expected tenant %q for %s, got %q", want, host, got)
+		}
+	}
+}
+
+func TestTenantHandler(t *testing.T) {
+	c, _ := testCache(t, 0)
+	school := storage.WithTenant(context.Background(), "school")
+	pkg := testPackage()
+	if err := pkg.AddContentAsset("images/photo.jpg", []byte("school"), ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Source.(*countingSource).SavePackage(school, "2", pkg); err != nil {
+		t.Fatal(err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("GET /sites/{tenant}/h5p/content/{contentId}/{path...}", TenantHandler(func(r *http.Request) string {
+		return r.PathValue("tenant")
+	}, c))
+	mux.Handle("GET /h5p/content/{contentId}/{path...}", c)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+	if rec := get("/h5p/content/2/images/photo.jpg"); rec.Code != http.StatusOK || rec.Body.Len() != 1000 {
+		t.Errorf("Expected the package of the default site, got %d %.20q", rec.Code, rec.Body)
+	}
+	if rec := get("/sites/school/h5p/content/2/images/photo.jpg"); rec.Body.String() != "school" {
+		t.Errorf("Expected the package of the tenant, got %d %.20q", rec.Code, rec.Body)
+	}
+	if rec := get("/sites/other/h5p/content/2/images/photo.jpg"); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for a tenant without the package, got %d", rec.Code)
+	}
+	if rec := get("/sites/a%5Cb/h5p/content/2/images/photo.jpg"); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an invalid tenant, got %d", rec.Code)
+	}
+	if c.Len() != 2 {
+		t.Errorf("Expected the packages cached per tenant, got %d entries", c.Len())
+	}
+
+	c.Invalidate(school, "2")
+	if c.Len() != 1 {
+		t.Errorf("Expected the package of the tenant invalidated only, got %d entries", c.Len())
+	}
+}
+
+func TestMemoryAttemptStoreTenants(t *testing.T) {
+	s := NewMemoryAttemptStore()
+	ctx := context.Background()
+	school := storage.WithTenant(ctx, "school")
+	a := attempt("7", "u1", 3, 4, 1000)
+	if err := s.Add(school, &a); err != nil {
+		t.Fatal(err)
+	}
+	if list, err := s.List(ctx, AttemptFilter{}); err != nil || len(list) != 0 {
+		t.Errorf("Expected no attempts for the default site, got %v, %v", list, err)
+	}
+	if list, err := s.List(school, AttemptFilter{ContentID: "7"}); err != nil || len(list) != 1 {
+		t.Errorf("Expected the attempt of the tenant, got %v, %v", list, err)
+	}
+}
@@ -0,0 +1,309 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/grokify/h5p-go/storage"
+)
+
+var (
+	ErrUserDataNotFound = errors.New("content user data not found")
+	ErrInvalidKey       = errors.New("invalid content user data key")
+)
+
+// UserDataKey identifies saved data of a user for content. DataType is
+// chosen by the content type, "state" for the resume state; SubContentID
+// is empty for the main content.
+type UserDataKey struct {
+	ContentID    string
+	SubContentID string
+	UserID       string
+	DataType     string
+}
+
+// Validate checks that the key parts are set, except SubContentID, and are
+// safe to use as file names.
+func (k UserDataKey) Validate() error {
+	parts := []string{k.ContentID, k.UserID, k.DataType}
+	if k.SubContentID != "" {
+		parts = append(parts, k.SubContentID)
+	}
+	for _, p := range parts {
+		if p == "" || p == "." || p == ".." || strings.ContainsAny(p, `/\`) {
+			return fmt.Errorf("%w: %+v", ErrInvalidKey, k)
+		}
+	}
+	return nil
+}
+
+// UserData is data a content type saved, as the string it posted.
+type UserData struct {
+	Data string `json:"data"`
+	// Preload asks for the data to be passed in the content settings on
+	// the next page load.
+	Preload bool `json:"preload"`
+	// Invalidate asks for the data to be deleted when the content changes.
+	Invalidate bool `json:"invalidate"`
+}
+
+// ContentUserDataStore keeps the data the player saves for users, such as
+// the state to resume content from.
+type ContentUserDataStore interface {
+	// Get returns ErrUserDataNotFound if there is no data for key.
+	Get(ctx context.Context, key UserDataKey) (*UserData, error)
+	Set(ctx context.Context, key UserDataKey, data *UserData) error
+	// Delete succeeds if there is no data for key.
+	Delete(ctx context.Context, key UserDataKey) error
+}
+
+// MemoryUserDataStore is a ContentUserDataStore in memory, for tests and
+// single-process servers that need not keep data over restarts. The data
+// of each tenant, see storage.WithTenant, is kept apart.
+type MemoryUserDataStore struct {
+	mu   sync.RWMutex
+	data map[tenantUserDataKey]UserData
+}
+
+type tenantUserDataKey struct {
+	tenant string
+	UserDataKey
+}
+
+func NewMemoryUserDataStore() *MemoryUserDataStore {
+	return &MemoryUserDataStore{data: map[tenantUserDataKey]UserData{}}
+}
+
+func (s *MemoryUserDataStore) Get(ctx context.Context, key UserDataKey) (*UserData, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	d, ok := s.data[tenantUserDataKey{storage.Tenant(ctx), key}]
+	if !ok {
+		return nil, ErrUserDataNotFound
+	}
+	return &d, nil
+}
+
+func (s *MemoryUserDataStore) Set(ctx context.Context, key UserDataKey, data *UserData) error {
+	if err := key.Validate(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data[tenantUserDataKey{storage.Tenant(ctx), key}] = *data
+	return nil
+}
+
+func (s *MemoryUserDataStore) Delete(ctx context.Context, key UserDataKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.data, tenantUserDataKey{storage.Tenant(ctx), key})
+	return nil
+}
+
+// FileUserDataStore is a ContentUserDataStore keeping each entry in a JSON
+// file, Dir/<content>/<user>/<sub-content or 0>/<data type>.json. The data
+// of tenants other than the default one, see storage.WithTenant, is kept
+// in the same layout below Dir/tenants/<tenant>/.
+type FileUserDataStore struct {
+	Dir string
+}
+
+func NewFileUserDataStore(dir string) *FileUserDataStore {
+	return &FileUserDataStore{Dir: dir}
+}
+
+func (s *FileUserDataStore) path(ctx context.Context, key UserDataKey) (string, error) {
+	if err := key.Validate(); err != nil {
+		return "", err
+	}
+	tenant, err := storage.TenantPrefix(ctx)
+	if err != nil {
+		return "", err
+	}
+	sub := key.SubContentID
+	if sub == "" {
+		sub = "0"
+	}
+	return filepath.Join(s.Dir, filepath.FromSlash(tenant), key.ContentID, key.UserID, sub, key.DataType+".json"), nil
+}
+
+func (s *FileUserDataStore) Get(ctx context.Context, key UserDataKey) (*UserData, error) {
+	name, err := s.path(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := os.ReadFile(name)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrUserDataNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	var d UserData
+	if err := json.Unmarshal(raw, &d); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	return &d, nil
+}
+
+// Set writes the entry to a temporary file and renames it, so a failed
+// write keeps the previous data.
+func (s *FileUserDataStore) Set(ctx context.Context, key UserDataKey, data *UserData) error {
+	name, err := s.path(ctx, key)
+	if err != nil {
+		return err
+	}
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(name), ".userdata-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(raw); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), name)
+}
+
+func (s *FileUserDataStore) Delete(ctx context.Context, key UserDataKey) error {
+	name, err := s.path(ctx, key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(name); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// PreloadedState returns the state of content saved for preloading, for
+// ContentOptions.State, or "" if there is none.
+func PreloadedState(ctx context.Context, store ContentUserDataStore, contentID, userID string) (string, error) {
+	d, err := store.Get(ctx, UserDataKey{ContentID: contentID, UserID: userID, DataType: "state"})
+	if errors.Is(err, ErrUserDataNotFound) || (err == nil && !d.Preload) {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+	return d.Data, nil
+}
+
+// UserFunc returns the ID of the user making a request, or "" if the user
+// is not logged in.
+type UserFunc func(r *http.Request) string
+
+// ContentUserDataHandler serves the contentUserData AJAX endpoint of the
+// player. Register it with a pattern holding the wildcards contentId,
+// dataType and subContentId, and set Settings.ContentUserDataURL to the
+// same path with the placeholders :contentId, :dataType and :subContentId:
+//
+//	mux.Handle("/h5p/ajax/content-user-data/{contentId}/{dataType}/{subContentId}", h)
+//
+// GET responds with the saved data, or false if there is none. POST saves
+// the form fields data, preload and invalidate, or deletes the data if
+// data is "0", which the player posts to reset content.
+type ContentUserDataHandler struct {
+	Store ContentUserDataStore
+	User  UserFunc
+}
+
+func (h *ContentUserDataHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user := h.User(r)
+	if user == "" {
+		ajaxError(w, http.StatusUnauthorized, "not logged in")
+		return
+	}
+	key := UserDataKey{
+		ContentID:    r.PathValue("contentId"),
+		SubContentID: r.PathValue("subContentId"),
+		UserID:       user,
+		DataType:     r.PathValue("dataType"),
+	}
+	// The player sends 0 for the main content.
+	if key.SubContentID == "0" {
+		key.SubContentID = ""
+	}
+	if err := key.Validate(); err != nil {
+		ajaxError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		d, err := h.Store.Get(r.Context(), key)
+		switch {
+		case errors.Is(err, ErrUserDataNotFound):
+			ajaxSuccess(w, false)
+		case err != nil:
+			ajaxError(w, http.StatusInternalServerError, err.Error())
+		default:
+			ajaxSuccess(w, d.Data)
+		}
+	case http.MethodPost:
+		if err := r.ParseForm(); err != nil {
+			ajaxError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		data, ok := r.PostForm["data"]
+		if !ok {
+			ajaxError(w, http.StatusBadRequest, "missing data")
+			return
+		}
+		var err error
+		if data[0] == "0" {
+			err = h.Store.Delete(r.Context(), key)
+		} else {
+			err = h.Store.Set(r.Context(), key, &UserData{
+				Data:       data[0],
+				Preload:    r.PostForm.Get("preload") == "1",
+				Invalidate: r.PostForm.Get("invalidate") == "1",
+			})
+		}
+		if err != nil {
+			ajaxError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		ajaxSuccess(w, nil)
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		ajaxError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// ajaxSuccess and ajaxError write the responses of H5PCore::ajaxSuccess
+// and H5PCore::ajaxError the player expects.
+func ajaxSuccess(w http.ResponseWriter, data any) {
+	resp := map[string]any{"success": true}
+	if data != nil {
+		resp["data"] = data
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func ajaxError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]any{"success": false, "message": message})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
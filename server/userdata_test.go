@@ -0,0 +1,124 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/grokify/h5p-go/storage"
+)
+
+func testUserDataStore(t *testing.T, s ContentUserDataStore) {
+	t.Helper()
+	ctx := context.Background()
+	key := UserDataKey{ContentID: "7", UserID: "u1", DataType: "state"}
+	if _, err := s.Get(ctx, key); !errors.Is(err, ErrUserDataNotFound) {
+		t.Errorf("Expected ErrUserDataNotFound, got %v", err)
+	}
+	if err := s.Set(ctx, key, &UserData{Data: `{"answers":[1]}`, Preload: true}); err != nil {
+		t.Fatal(err)
+	}
+	sub := key
+	sub.SubContentID = "q1"
+	if err := s.Set(ctx, sub, &UserData{Data: "x"}); err != nil {
+		t.Fatal(err)
+	}
+	d, err := s.Get(ctx, key)
+	if err != nil || d.Data != `{"answers":[1]}` || !d.Preload || d.Invalidate {
+		t.Errorf("Unexpected data %+v, %v", d, err)
+	}
+	if state, err := PreloadedState(ctx, s, "7", "u1"); err != nil || state != `{"answers":[1]}` {
+		t.Errorf("Unexpected preloaded state %q, %v", state, err)
+	}
+	if err := s.Delete(ctx, key); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Delete(ctx, key); err != nil {
+		t.Errorf("Expected deleting missing data to succeed, got %v", err)
+	}
+	if _, err := s.Get(ctx, key); !errors.Is(err, ErrUserDataNotFound) {
+		t.Errorf("Expected ErrUserDataNotFound after delete, got %v", err)
+	}
+	if d, err := s.Get(ctx, sub); err != nil || d.Data != "x" {
+		t.Errorf("Expected sub-content data kept, got %+v, %v", d, err)
+	}
+	if _, err := s.Get(storage.WithTenant(ctx, "school"), sub); !errors.Is(err, ErrUserDataNotFound) {
+		t.Errorf("Expected the data of the default site hidden from a tenant, got %v", err)
+	}
+	bad := UserDataKey{ContentID: "..", UserID: "u1", DataType: "state"}
+	if err := s.Set(ctx, bad, &UserData{Data: "x"}); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("Expected ErrInvalidKey, got %v", err)
+	}
+}
+
+func TestMemoryUserDataStore(t *testing.T) {
+	testUserDataStore(t, NewMemoryUserDataStore())
+}
+
+func TestFileUserDataStore(t *testing.T) {
+	testUserDataStore(t, NewFileUserDataStore(t.TempDir()))
+}
+
+func TestContentUserDataHandler(t *testing.T) {
+	store := NewMemoryUserDataStore()
+	mux := http.NewServeMux()
+	mux.Handle("/ajax/content-user-data/{contentId}/{dataType}/{subContentId}", &ContentUserDataHandler{
+		Store: store,
+		User:  func(r *http.Request) string { return r.Header.Get("X-User") },
+	})
+	do := func(method, path, user string, form url.Values) (int, map[string]any) {
+		t.Helper()
+		req := httptest.NewRequest(method, path, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if user != "" {
+			req.Header.Set("X-User", user)
+		}
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		var resp map[string]any
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("Invalid response %q: %v", rec.Body, err)
+		}
+		return rec.Code, resp
+	}
+
+	const path = "/ajax/content-user-data/7/state/0"
+	if code, resp := do("GET", path, "u1", nil); code != 200 || resp["success"] != true || resp["data"] != false {
+		t.Errorf("Expected no data, got %d %v", code, resp)
+	}
+	form := url.Values{"data": {`{"progress":2}`}, "preload": {"1"}, "invalidate": {"1"}}
+	if code, resp := do("POST", path, "u1", form); code != 200 || resp["success"] != true {
+		t.Errorf("Expected saving to succeed, got %d %v", code, resp)
+	}
+	d, err := store.Get(context.Background(), UserDataKey{ContentID: "7", UserID: "u1", DataType: "state"})
+	if err != nil || *d != (UserData{Data: `{"progress":2}`, Preload: true, Invalidate: true}) {
+		t.Errorf("Unexpected stored data %+v, %v", d, err)
+	}
+	if _, resp := do("GET", path, "u1", nil); resp["data"] != `{"progress":2}` {
+		t.Errorf("Expected the saved data, got %v", resp)
+	}
+	if _, resp := do("GET", path, "u2", nil); resp["data"] != false {
+		t.Errorf("Expected no data for another user, got %v", resp)
+	}
+	if code, _ := do("POST", path, "u1", url.Values{"data": {"0"}}); code != 200 {
+		t.Errorf("Expected reset to succeed, got %d", code)
+	}
+	if _, resp := do("GET", path, "u1", nil); resp["data"] != false {
+		t.Errorf("Expected data deleted, got %v", resp)
+	}
+
+	if code, resp := do("GET", path, "", nil); code != http.StatusUnauthorized || resp["success"] != false {
+		t.Errorf("Expected 401 without a user, got %d %v", code, resp)
+	}
+	if code, _ := do("POST", path, "u1", url.Values{}); code != http.StatusBadRequest {
+		t.Errorf("Expected 400 without data, got %d", code)
+	}
+	if code, _ := do("DELETE", path, "u1", nil); code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405, got %d", code)
+	}
+}
@@ -0,0 +1,166 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Headers of webhook requests.
+const (
+	HeaderEvent     = "X-H5P-Event"
+	HeaderDelivery  = "X-H5P-Delivery"
+	HeaderSignature = "X-H5P-Signature"
+)
+
+var ErrWebhookStatus = errors.New("unexpected webhook response status")
+
+// Webhook is an endpoint events are posted to as JSON.
+type Webhook struct {
+	URL string
+	// Secret, if set, signs the body with HMAC-SHA256 in the
+	// X-H5P-Signature header as "sha256=<hex>"; see VerifySignature.
+	Secret string
+	// Types lists the events to send; all if empty.
+	Types []EventType
+	// Tenants lists the tenants whose events are sent, "" standing for the
+	// default site; all if empty.
+	Tenants []string
+}
+
+// WebhookDispatcher posts events to webhooks in the background, retrying
+// failed deliveries. Subscribe its Handle method to an EventBus:
+//
+//	bus.Subscribe(dispatcher.Handle)
+type WebhookDispatcher struct {
+	Hooks []Webhook
+	// Client defaults to http.DefaultClient.
+	Client *http.Client
+	// MaxAttempts defaults to 3; Backoff, the wait before the first
+	// retry, defaults to one second and doubles with each retry.
+	MaxAttempts int
+	Backoff     time.Duration
+	// OnError, if set, is called for deliveries that failed for good.
+	OnError func(hook Webhook, e Event, err error)
+
+	wg sync.WaitGroup
+}
+
+func NewWebhookDispatcher(hooks ...Webhook) *WebhookDispatcher {
+	return &WebhookDispatcher{Hooks: hooks}
+}
+
+// Handle starts delivering e to the webhooks subscribed to its type and
+// tenant and returns without waiting. Deliveries outlive the cancellation of ctx.
+func (d *WebhookDispatcher) Handle(ctx context.Context, e Event) {
+	ctx = context.WithoutCancel(ctx)
+	for _, hook := range d.Hooks {
+		if (len(hook.Types) > 0 && !slices.Contains(hook.Types, e.Type)) ||
+			(len(hook.Tenants) > 0 && !slices.Contains(hook.Tenants, e.Tenant)) {
+			continue
+		}
+		d.wg.Add(1)
+		go func() {
+			defer d.wg.Done()
+			if err := d.Deliver(ctx, hook, e); err != nil && d.OnError != nil {
+				d.OnError(hook, e, err)
+			}
+		}()
+	}
+}
+
+// Wait blocks until the deliveries started have finished, for shutdown.
+func (d *WebhookDispatcher) Wait() {
+	d.wg.Wait()
+}
+
+// Deliver posts e to hook, retrying network errors and 5xx and 429
+// responses.
+func (d *WebhookDispatcher) Deliver(ctx context.Context, hook Webhook, e Event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	attempts := d.MaxAttempts
+	if attempts <= 0 {
+		attempts = 3
+	}
+	backoff := d.Backoff
+	if backoff <= 0 {
+		backoff = time.Second
+	}
+	for i := 1; ; i++ {
+		retry, err := d.post(ctx, hook, e, body)
+		if err == nil || !retry || i >= attempts {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+func (d *WebhookDispatcher) post(ctx context.Context, hook Webhook, e Event, body []byte) (retry bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, string(e.Type))
+	req.Header.Set(HeaderDelivery, e.ID)
+	if hook.Secret != "" {
+		req.Header.Set(HeaderSignature, Signature(hook.Secret, body))
+	}
+	client := d.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+	if resp.StatusCode/100 == 2 {
+		return false, nil
+	}
+	retry = resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+	return retry, fmt.Errorf("%w: %s %s: %s", ErrWebhookStatus, e.Type, hook.URL, resp.Status)
+}
+
+// Signature returns the X-H5P-Signature header of a body.
+func Signature(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature reports whether header is the signature of body, for
+// receivers of webhooks.
+func VerifySignature(secret string, body []byte, header string) bool {
+	got, ok := strings.CutPrefix(header, "sha256=")
+	if !ok {
+		return false
+	}
+	sum, err := hex.DecodeString(got)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(sum, mac.Sum(nil))
+}
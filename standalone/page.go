@@ -0,0 +1,104 @@
+package standalone
+
+import "html/template"
+
+var pageTemplate = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+</head>
+<body>
+<div id="h5p-container"></div>
+{{- if .Files}}
+<script>
+var H5P_STANDALONE_FILES = {{.Files}};
+{{.Bootstrap}}
+</script>
+<script>
+{{.Main}}
+</script>
+{{- else}}
+<script src="{{.Assets}}/main.bundle.js"></script>
+{{- end}}
+<script>
+new H5PStandalone.H5P(document.getElementById("h5p-container"), {{.Options}});
+</script>
+</body>
+</html>
+`))
+
+// bootstrapJS serves H5P_STANDALONE_FILES, a map of relative path to
+// [mime type, base64 data], to the player: fetch and XMLHttpRequest
+// requests and the src/href of elements and of HTML written into frames are
+// redirected to blob URLs, in the page and in the frames the player creates.
+const bootstrapJS = `(function () {
+  var files = H5P_STANDALONE_FILES, byURL = {}, blobs = {};
+  Object.keys(files).forEach(function (p) { byURL[new URL(p, location.href).href] = p; });
+  function blobURL(u) {
+    var p;
+    try { p = byURL[new URL(String(u), location.href).href.split(/[?#]/)[0]]; } catch (e) { return null; }
+    if (!p) { return null; }
+    if (!blobs[p]) {
+      var bin = atob(files[p][1]), bytes = new Uint8Array(bin.length);
+      for (var i = 0; i < bin.length; i++) { bytes[i] = bin.charCodeAt(i); }
+      blobs[p] = URL.createObjectURL(new Blob([bytes], { type: files[p][0] }));
+    }
+    return blobs[p];
+  }
+  function rewriteHTML(html) {
+    return String(html).replace(/(\s(?:src|href)\s*=\s*)(["'])([^"']*)\2/gi, function (m, attr, q, u) {
+      var b = blobURL(u);
+      return b ? attr + q + b + q : m;
+    });
+  }
+  function wrapSetter(proto, prop, fn) {
+    var d = proto && Object.getOwnPropertyDescriptor(proto, prop);
+    if (!d || !d.set) { return; }
+    Object.defineProperty(proto, prop, {
+      configurable: true, enumerable: d.enumerable, get: d.get,
+      set: function (v) { d.set.call(this, fn(v)); }
+    });
+  }
+  function patch(win) {
+    if (win.__h5pStandalone) { return; }
+    win.__h5pStandalone = true;
+    var fetch = win.fetch;
+    win.fetch = function (input, init) {
+      return fetch.call(win, (typeof input === "string" && blobURL(input)) || input, init);
+    };
+    var open = win.XMLHttpRequest.prototype.open;
+    win.XMLHttpRequest.prototype.open = function () {
+      var args = Array.prototype.slice.call(arguments);
+      args[1] = blobURL(args[1]) || args[1];
+      return open.apply(this, args);
+    };
+    var toBlob = function (v) { return blobURL(v) || v; };
+    [["HTMLScriptElement", "src"], ["HTMLLinkElement", "href"], ["HTMLImageElement", "src"],
+     ["HTMLMediaElement", "src"], ["HTMLSourceElement", "src"], ["HTMLIFrameElement", "src"]].forEach(function (e) {
+      wrapSetter(win[e[0]] && win[e[0]].prototype, e[1], toBlob);
+    });
+    wrapSetter(win.HTMLIFrameElement.prototype, "srcdoc", rewriteHTML);
+    var setAttribute = win.Element.prototype.setAttribute;
+    win.Element.prototype.setAttribute = function (name, value) {
+      return setAttribute.call(this, name, /^(src|href)$/i.test(name) ? toBlob(value) : value);
+    };
+    var write = win.Document.prototype.write;
+    win.Document.prototype.write = function () {
+      return write.apply(this, Array.prototype.map.call(arguments, rewriteHTML));
+    };
+    ["contentWindow", "contentDocument"].forEach(function (prop) {
+      var d = Object.getOwnPropertyDescriptor(win.HTMLIFrameElement.prototype, prop);
+      Object.defineProperty(win.HTMLIFrameElement.prototype, prop, {
+        configurable: true, enumerable: d.enumerable,
+        get: function () {
+          var frame = d.get.call(this);
+          try { patch(prop === "contentWindow" ? frame : frame.defaultView); } catch (e) {}
+          return frame;
+        }
+      });
+    });
+  }
+  patch(window);
+})();`
@@ -0,0 +1,279 @@
+// Package standalone exports H5P packages for playback without an H5P
+// server, using the h5p-standalone player
+// (https://github.com/tunapanda/h5p-standalone). WriteDir produces a folder
+// for static hosting; WriteHTML produces a single HTML file holding the
+// player, the package and a bootstrap that serves both from memory, so the
+// content can be previewed straight from disk or sent as an attachment.
+//
+// The player itself is not bundled: pass the dist folder of an
+// h5p-standalone release as Options.Player, or a URL hosting it as
+// Options.PlayerURL.
+package standalone
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	h5p "github.com/grokify/h5p-go"
+)
+
+// Files of the h5p-standalone dist folder and the layout of the export.
+const (
+	MainBundle  = "main.bundle.js"
+	FrameBundle = "frame.bundle.js"
+	FrameCSS    = "styles/h5p.css"
+	IndexFile   = "index.html"
+	AssetsDir   = "assets"
+	ContentDir  = "h5p"
+)
+
+var (
+	ErrNoPlayer          = errors.New("h5p-standalone player files are required")
+	ErrMissingPlayerFile = errors.New("h5p-standalone player file missing")
+)
+
+var cssURL = regexp.MustCompile(`url\(\s*(['"]?)([^'")]+)(['"]?)\s*\)`)
+
+// extraTypes covers file types missing from the mime package built-ins.
+var extraTypes = map[string]string{
+	".woff":  "font/woff",
+	".woff2": "font/woff2",
+	".ttf":   "font/ttf",
+	".otf":   "font/otf",
+	".eot":   "application/vnd.ms-fontobject",
+	".mp3":   "audio/mpeg",
+	".ogg":   "audio/ogg",
+	".wav":   "audio/wav",
+	".mp4":   "video/mp4",
+	".webm":  "video/webm",
+}
+
+// Options controls the export.
+type Options struct {
+	// Player holds the h5p-standalone dist files: MainBundle, FrameBundle
+	// and FrameCSS with the fonts it references.
+	Player fs.FS
+
+	// PlayerURL is used by WriteDir instead of copying Player, e.g. a CDN
+	// path of an h5p-standalone release. It must end in the dist folder.
+	PlayerURL string
+
+	// Title is the page title. It defaults to the h5p.json title.
+	Title string
+
+	// FullScreen, Download, Copyright and Embed show the matching buttons
+	// of the player frame.
+	FullScreen bool
+	Download   bool
+	Copyright  bool
+	Embed      bool
+}
+
+// WriteDir writes dir/index.html, the player in dir/assets (unless
+// PlayerURL is set) and the extracted package in dir/h5p. The folder must
+// be served over HTTP; use WriteHTML to open content from disk.
+func WriteDir(pkg *h5p.H5PPackage, dir string, opts Options) error {
+	return WriteDirContext(context.Background(), pkg, dir, opts)
+}
+
+// WriteDirContext is WriteDir that stops when ctx is cancelled.
+func WriteDirContext(ctx context.Context, pkg *h5p.H5PPackage, dir string, opts Options) error {
+	assets := opts.PlayerURL
+	if assets == "" {
+		if err := checkPlayer(opts.Player); err != nil {
+			return err
+		}
+		assets = AssetsDir
+		err := fs.WalkDir(opts.Player, ".", func(name string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			data, err := fs.ReadFile(opts.Player, name)
+			if err != nil {
+				return err
+			}
+			return writeFile(filepath.Join(dir, AssetsDir, filepath.FromSlash(name)), data)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to copy player: %w", err)
+		}
+	}
+	if err := pkg.ExtractToDirContext(ctx, filepath.Join(dir, ContentDir), nil); err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := writePage(&buf, pkg, opts, strings.TrimSuffix(assets, "/"), nil, ""); err != nil {
+		return err
+	}
+	return writeFile(filepath.Join(dir, IndexFile), buf.Bytes())
+}
+
+// WriteHTML writes a single self-contained HTML page. Player is required
+// and PlayerURL is ignored. CSS url() references are inlined as data URIs.
+func WriteHTML(w io.Writer, pkg *h5p.H5PPackage, opts Options) error {
+	if err := checkPlayer(opts.Player); err != nil {
+		return err
+	}
+	files := map[string][]byte{}
+	err := fs.WalkDir(opts.Player, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || name == MainBundle {
+			return err
+		}
+		data, err := fs.ReadFile(opts.Player, name)
+		files[AssetsDir+"/"+name] = data
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read player: %w", err)
+	}
+
+	var archive bytes.Buffer
+	if _, err := pkg.WriteTo(&archive); err != nil {
+		return err
+	}
+	zr, err := zip.NewReader(bytes.NewReader(archive.Bytes()), int64(archive.Len()))
+	if err != nil {
+		return err
+	}
+	for _, f := range zr.File {
+		if strings.HasSuffix(f.Name, "/") {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+		files[ContentDir+"/"+f.Name] = data
+	}
+
+	virtual := make(map[string][2]string, len(files))
+	for name, data := range files {
+		if path.Ext(name) == ".css" {
+			data = inlineCSS(name, data, files)
+		}
+		virtual[name] = [2]string{typeByName(name), base64.StdEncoding.EncodeToString(data)}
+	}
+	main, err := fs.ReadFile(opts.Player, MainBundle)
+	if err != nil {
+		return err
+	}
+	return writePage(w, pkg, opts, AssetsDir, virtual, string(main))
+}
+
+func checkPlayer(player fs.FS) error {
+	if player == nil {
+		return ErrNoPlayer
+	}
+	for _, name := range []string{MainBundle, FrameBundle, FrameCSS} {
+		if _, err := fs.Stat(player, name); err != nil {
+			return fmt.Errorf("%w: %s", ErrMissingPlayerFile, name)
+		}
+	}
+	return nil
+}
+
+// inlineCSS replaces relative url() references of the CSS file name with
+// data URIs of the referenced files, as blob URLs cannot resolve them.
+func inlineCSS(name string, data []byte, files map[string][]byte) []byte {
+	return cssURL.ReplaceAllFunc(data, func(m []byte) []byte {
+		ref := string(cssURL.FindSubmatch(m)[2])
+		if strings.Contains(ref, ":") || strings.HasPrefix(ref, "/") || strings.HasPrefix(ref, "#") {
+			return m
+		}
+		target := path.Join(path.Dir(name), strings.SplitN(strings.SplitN(ref, "?", 2)[0], "#", 2)[0])
+		content, ok := files[target]
+		if !ok {
+			return m
+		}
+		return []byte(`url("data:` + typeByName(target) + `;base64,` + base64.StdEncoding.EncodeToString(content) + `")`)
+	})
+}
+
+func typeByName(name string) string {
+	ext := strings.ToLower(path.Ext(name))
+	if t, ok := extraTypes[ext]; ok {
+		return t
+	}
+	if t := mime.TypeByExtension(ext); t != "" {
+		return t
+	}
+	return "application/octet-stream"
+}
+
+// playerOptions are the h5p-standalone constructor options.
+type playerOptions struct {
+	H5PJSONPath string `json:"h5pJsonPath"`
+	FrameJS     string `json:"frameJs"`
+	FrameCSS    string `json:"frameCss"`
+	Frame       bool   `json:"frame"`
+	FullScreen  bool   `json:"fullScreen"`
+	Export      bool   `json:"export"`
+	Copyright   bool   `json:"copyright"`
+	Embed       bool   `json:"embed"`
+}
+
+type pageData struct {
+	Title     string
+	Assets    string
+	Options   template.JS
+	Files     template.JS
+	Main      template.JS
+	Bootstrap template.JS
+}
+
+func writePage(w io.Writer, pkg *h5p.H5PPackage, opts Options, assets string, files map[string][2]string, main string) error {
+	title := opts.Title
+	if title == "" && pkg.PackageDefinition != nil {
+		title = pkg.PackageDefinition.Title
+	}
+	options, err := json.Marshal(playerOptions{
+		H5PJSONPath: ContentDir,
+		FrameJS:     assets + "/" + FrameBundle,
+		FrameCSS:    assets + "/" + FrameCSS,
+		Frame:       opts.FullScreen || opts.Download || opts.Copyright || opts.Embed,
+		FullScreen:  opts.FullScreen,
+		Export:      opts.Download,
+		Copyright:   opts.Copyright,
+		Embed:       opts.Embed,
+	})
+	if err != nil {
+		return err
+	}
+	data := pageData{Title: title, Assets: assets, Options: template.JS(options)}
+	if files != nil {
+		encoded, err := json.Marshal(files)
+		if err != nil {
+			return err
+		}
+		data.Files = template.JS(encoded)
+		data.Main = template.JS(strings.ReplaceAll(main, "</script", `<\/script`))
+		data.Bootstrap = template.JS(bootstrapJS)
+	}
+	return pageTemplate.Execute(w, data)
+}
+
+func writeFile(name string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(name), 0750); err != nil {
+		return err
+	}
+	return os.WriteFile(name, data, 0600)
+}
@@ -0,0 +1,136 @@
+package standalone
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	h5p "github.com/grokify/h5p-go"
+)
+
+func testPlayer() fstest.MapFS {
+	return fstest.MapFS{
+		MainBundle:       {Data: []byte(`var H5PStandalone = {H5P: function () {}}; // "</script>"`)},
+		FrameBundle:      {Data: []byte("var H5P = {};")},
+		FrameCSS:         {Data: []byte(`@font-face { src: url("../fonts/h5p.woff?v=1") } .x { background: url(data:image/png;base64,AA==) }`)},
+		"fonts/h5p.woff": {Data: []byte("WOFF")},
+	}
+}
+
+func testPackage(t *testing.T) *h5p.H5PPackage {
+	t.Helper()
+	qs, err := h5p.NewQuestionSetBuilder().
+		SetTitle("Quiz").
+		AddMultipleChoiceQuestion("2 + 2?", []h5p.Answer{h5p.CreateAnswer("4", true), h5p.CreateAnswer("5", false)}).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg := h5p.NewH5PPackage()
+	pkg.SetPackageDefinition(&h5p.PackageDefinition{
+		Title:                 "Quiz & <Co>",
+		Language:              "en",
+		MainLibrary:           "H5P.QuestionSet",
+		EmbedTypes:            []string{"iframe"},
+		PreloadedDependencies: []h5p.LibraryDependency{{MachineName: "H5P.QuestionSet", MajorVersion: 1, MinorVersion: 20}},
+	})
+	pkg.SetContent(&h5p.Content{QuestionSet: qs})
+	return pkg
+}
+
+func TestWriteDir(t *testing.T) {
+	dir := t.TempDir()
+	if err := WriteDir(testPackage(t), dir, Options{}); !errors.Is(err, ErrNoPlayer) {
+		t.Errorf("Expected ErrNoPlayer, got %v", err)
+	}
+	player := testPlayer()
+	delete(player, FrameCSS)
+	if err := WriteDir(testPackage(t), dir, Options{Player: player}); !errors.Is(err, ErrMissingPlayerFile) {
+		t.Errorf("Expected ErrMissingPlayerFile, got %v", err)
+	}
+
+	if err := WriteDir(testPackage(t), dir, Options{Player: testPlayer(), FullScreen: true}); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{IndexFile, "assets/main.bundle.js", "assets/fonts/h5p.woff", "h5p/h5p.json", "h5p/content/content.json"} {
+		if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(name))); err != nil {
+			t.Errorf("missing %s: %v", name, err)
+		}
+	}
+	index, err := os.ReadFile(filepath.Join(dir, IndexFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`<title>Quiz &amp; &lt;Co&gt;</title>`,
+		`<script src="assets/main.bundle.js"></script>`,
+		`"h5pJsonPath":"h5p","frameJs":"assets/frame.bundle.js","frameCss":"assets/styles/h5p.css","frame":true,"fullScreen":true`,
+	} {
+		if !strings.Contains(string(index), want) {
+			t.Errorf("index.html lacks %s:\n%s", want, index)
+		}
+	}
+
+	cdn := t.TempDir()
+	if err := WriteDir(testPackage(t), cdn, Options{PlayerURL: "https://cdn.example.com/h5p-standalone/dist/"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(cdn, AssetsDir)); !os.IsNotExist(err) {
+		t.Error("player copied despite PlayerURL")
+	}
+	index, _ = os.ReadFile(filepath.Join(cdn, IndexFile))
+	if !strings.Contains(string(index), `src="https://cdn.example.com/h5p-standalone/dist/main.bundle.js"`) {
+		t.Errorf("index.html does not load the player from PlayerURL:\n%s", index)
+	}
+}
+
+func TestWriteHTML(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteHTML(&buf, testPackage(t), Options{PlayerURL: "https://cdn.example.com/"}); !errors.Is(err, ErrNoPlayer) {
+		t.Errorf("Expected ErrNoPlayer, got %v", err)
+	}
+	if err := WriteHTML(&buf, testPackage(t), Options{Player: testPlayer(), Title: "Preview"}); err != nil {
+		t.Fatal(err)
+	}
+	page := buf.String()
+	if strings.Contains(page, `"</script>"`) || !strings.Contains(page, `"<\/script>"`) {
+		t.Error("main bundle not escaped for inlining")
+	}
+	if strings.Contains(page, `src="assets/main.bundle.js"`) {
+		t.Error("single page references the player file")
+	}
+
+	m := regexp.MustCompile(`var H5P_STANDALONE_FILES = (.*);\n`).FindStringSubmatch(page)
+	if m == nil {
+		t.Fatal("file map not found")
+	}
+	var files map[string][2]string
+	if err := json.Unmarshal([]byte(m[1]), &files); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := files["assets/"+MainBundle]; ok {
+		t.Error("main bundle also stored in the file map")
+	}
+	for _, name := range []string{"assets/frame.bundle.js", "h5p/h5p.json", "h5p/content/content.json"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("file map lacks %s", name)
+		}
+	}
+	css, _ := base64.StdEncoding.DecodeString(files["assets/"+FrameCSS][1])
+	if want := `url("data:font/woff;base64,` + base64.StdEncoding.EncodeToString([]byte("WOFF")) + `")`; !strings.Contains(string(css), want) {
+		t.Errorf("font not inlined: %s", css)
+	}
+	if !strings.Contains(string(css), "url(data:image/png;base64,AA==)") {
+		t.Errorf("data URI rewritten: %s", css)
+	}
+	if files["h5p/h5p.json"][0] != "application/json" {
+		t.Errorf("h5p.json type = %q", files["h5p/h5p.json"][0])
+	}
+}
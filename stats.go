@@ -0,0 +1,298 @@
+package h5p
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/grokify/h5p-go/licenses"
+)
+
+// QuestionLibraries lists the machine names of the content types Stats
+// counts as questions, as main content or as sub-content. Callers may add
+// others.
+var QuestionLibraries = map[string]bool{
+	"H5P.Blanks":               true,
+	"H5P.DragQuestion":         true,
+	"H5P.DragText":             true,
+	"H5P.Essay":                true,
+	"H5P.ImageHotspotQuestion": true,
+	"H5P.MarkTheWords":         true,
+	"H5P.MultiChoice":          true,
+	"H5P.SingleChoiceSet":      true,
+	"H5P.Summary":              true,
+	"H5P.TrueFalse":            true,
+}
+
+// nonTextKeys are params keys whose values are not read by learners:
+// identifiers, media references and the groups of settings and UI labels
+// content types keep next to their content. Their strings are left out of
+// word counts.
+var nonTextKeys = map[string]bool{
+	"library":      true,
+	"subContentId": true,
+	"metadata":     true,
+	"path":         true,
+	"mime":         true,
+	"copyright":    true,
+	"behaviour":    true,
+	"l10n":         true,
+	"UI":           true,
+	"a11y":         true,
+	"confirmCheck": true,
+	"confirmRetry": true,
+	"texts":        true,
+	"correct":      true,
+}
+
+// StatsOptions tunes the completion time estimate of Stats. Zero values
+// use the defaults.
+type StatsOptions struct {
+	// WordsPerMinute is the reading speed, 200 by default.
+	WordsPerMinute int
+	// AnswerTime is the time taken to consider an answer option or fill a
+	// gap, 5 seconds by default.
+	AnswerTime time.Duration
+}
+
+// ContentStats summarizes the content of one or more packages.
+type ContentStats struct {
+	Packages  int `json:"packages"`
+	Questions int `json:"questions"`
+	// QuestionsByLibrary counts the questions by machine name.
+	QuestionsByLibrary map[string]int `json:"questionsByLibrary"`
+	// Answers is the total of QuestionStats.Answers.
+	Answers int `json:"answers"`
+	// Words counts the words of the content text, without UI labels.
+	Words int `json:"words"`
+	// EstimatedMinutes is the time to read the text and consider every
+	// answer, see StatsOptions.
+	EstimatedMinutes float64    `json:"estimatedMinutes"`
+	Media            MediaStats `json:"media"`
+	// Licenses counts the license labels, e.g. "CC BY 4.0", of h5p.json,
+	// sub-content metadata and media copyright.
+	Licenses map[string]int `json:"licenses"`
+	// Readability measures the text of ExtractText, for content types
+	// with known semantics.
+	Readability Readability `json:"readability"`
+	// Sections measures the parts of the content, such as the questions
+	// of a question set, see ExtractText. Add leaves them out.
+	Sections []SectionStats `json:"sections,omitempty"`
+}
+
+// SectionStats measures a part of the content, see TextSection.
+type SectionStats struct {
+	Path    string `json:"path"`
+	Library string `json:"library,omitempty"`
+	// Answers is the number of answers of a question, see QuestionStats.
+	Answers int `json:"answers,omitempty"`
+	// EstimatedMinutes is the time to read the text and consider every
+	// answer, see StatsOptions.
+	EstimatedMinutes float64     `json:"estimatedMinutes"`
+	Readability      Readability `json:"readability"`
+}
+
+// MediaStats counts the content files and their sizes in bytes.
+type MediaStats struct {
+	Files int   `json:"files"`
+	Size  int64 `json:"size"`
+	// ByType breaks the files down by the type part of their MIME type,
+	// e.g. "image" or "video".
+	ByType map[string]FileCount `json:"byType"`
+}
+
+// FileCount is a number of files and their total size in bytes.
+type FileCount struct {
+	Files int   `json:"files"`
+	Size  int64 `json:"size"`
+}
+
+// NewContentStats returns empty stats, for adding up the stats of several
+// packages with Add.
+func NewContentStats() *ContentStats {
+	return &ContentStats{
+		QuestionsByLibrary: map[string]int{},
+		Media:              MediaStats{ByType: map[string]FileCount{}},
+		Licenses:           map[string]int{},
+	}
+}
+
+// AverageAnswers returns the mean number of answers per question.
+func (s *ContentStats) AverageAnswers() float64 {
+	if s.Questions == 0 {
+		return 0
+	}
+	return float64(s.Answers) / float64(s.Questions)
+}
+
+// EstimatedTime returns EstimatedMinutes as a duration.
+func (s *ContentStats) EstimatedTime() time.Duration {
+	return time.Duration(s.EstimatedMinutes * float64(time.Minute)).Round(time.Second)
+}
+
+// Add adds the counts of other to s.
+func (s *ContentStats) Add(other *ContentStats) {
+	s.Packages += other.Packages
+	s.Questions += other.Questions
+	s.Answers += other.Answers
+	s.Words += other.Words
+	s.EstimatedMinutes += other.EstimatedMinutes
+	s.Media.Files += other.Media.Files
+	s.Media.Size += other.Media.Size
+	for name, n := range other.QuestionsByLibrary {
+		s.QuestionsByLibrary[name] += n
+	}
+	for kind, c := range other.Media.ByType {
+		total := s.Media.ByType[kind]
+		total.Files += c.Files
+		total.Size += c.Size
+		s.Media.ByType[kind] = total
+	}
+	for label, n := range other.Licenses {
+		s.Licenses[label] += n
+	}
+	s.Readability.Add(other.Readability)
+}
+
+// Stats analyzes the content of the package: its questions and their
+// answers, the words learners read, the content files and the licenses.
+// Questions are the main content and sub-content whose library is listed
+// in QuestionLibraries.
+func (pkg *H5PPackage) Stats(opts StatsOptions) (*ContentStats, error) {
+	if opts.WordsPerMinute <= 0 {
+		opts.WordsPerMinute = 200
+	}
+	if opts.AnswerTime <= 0 {
+		opts.AnswerTime = 5 * time.Second
+	}
+	s := NewContentStats()
+	s.Packages = 1
+
+	if def := pkg.PackageDefinition; def != nil {
+		s.addLicense(def.License, def.LicenseVersion)
+		if pkg.Content != nil {
+			params, err := toGenericJSON(pkg.Content)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read content params: %w", err)
+			}
+			if obj, ok := params.(map[string]any); ok {
+				main := def.MainLibrary
+				if i := slices.IndexFunc(def.PreloadedDependencies, func(d LibraryDependency) bool { return d.MachineName == main }); i >= 0 {
+					main = def.PreloadedDependencies[i].String()
+				}
+				s.addContent(main, obj)
+				if err := s.addSections(pkg, main, obj, opts); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	for _, name := range pkg.ContentFileNames() {
+		data, err := pkg.ContentFiles[name].Bytes()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read content file %s: %w", name, err)
+		}
+		kind, _, _ := strings.Cut(pkg.ContentFiles[name].Mime, "/")
+		if kind == "" {
+			kind = "other"
+		}
+		c := s.Media.ByType[kind]
+		c.Files++
+		c.Size += int64(len(data))
+		s.Media.ByType[kind] = c
+		s.Media.Files++
+		s.Media.Size += int64(len(data))
+	}
+
+	minutes := float64(s.Words)/float64(opts.WordsPerMinute) + float64(s.Answers)*opts.AnswerTime.Minutes()
+	s.EstimatedMinutes = math.Round(minutes*100) / 100
+	return s, nil
+}
+
+// addSections measures the text of the content of library with the given
+// params, and of its parts.
+func (s *ContentStats) addSections(pkg *H5PPackage, library string, params map[string]any, opts StatsOptions) error {
+	sections, err := ExtractText(pkg)
+	if errors.Is(err, ErrNoSemantics) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to extract text: %w", err)
+	}
+	for _, sec := range sections {
+		ss := SectionStats{Path: sec.Path, Library: sec.Library, Readability: MeasureReadability(sec.Text)}
+		q := Question{Library: library, Params: params}
+		if sec.Path != "" {
+			sub, _ := genericValueAt(params, sec.Path).(map[string]any)
+			q = Question{Library: sec.Library, Params: sub["params"]}
+		}
+		if QuestionLibraries[q.MachineName()] {
+			ss.Answers = q.Stats().Answers
+		}
+		minutes := float64(ss.Readability.Words)/float64(opts.WordsPerMinute) + float64(ss.Answers)*opts.AnswerTime.Minutes()
+		ss.EstimatedMinutes = math.Round(minutes*100) / 100
+		s.Readability.Add(ss.Readability)
+		s.Sections = append(s.Sections, ss)
+	}
+	return nil
+}
+
+// addContent counts the content of library with the given params, and the
+// sub-content in them.
+func (s *ContentStats) addContent(library string, params map[string]any) {
+	q := Question{Library: library, Params: params}
+	if name := q.MachineName(); QuestionLibraries[name] {
+		s.Questions++
+		s.QuestionsByLibrary[name]++
+		s.Answers += q.Stats().Answers
+	}
+	s.addParams(params)
+}
+
+// addParams counts the words, sub-content and media licenses in v.
+func (s *ContentStats) addParams(v any) {
+	switch t := v.(type) {
+	case string:
+		s.Words += len(strings.Fields(plainText(t)))
+	case []any:
+		for _, child := range t {
+			s.addParams(child)
+		}
+	case map[string]any:
+		if copyright, ok := t["copyright"].(map[string]any); ok {
+			license, _ := copyright["license"].(string)
+			version, _ := copyright["version"].(string)
+			s.addLicense(license, version)
+		}
+		library, _ := t["library"].(string)
+		params, isContent := t["params"].(map[string]any)
+		if isContent && library != "" {
+			if metadata, ok := t["metadata"].(map[string]any); ok {
+				license, _ := metadata["license"].(string)
+				version, _ := metadata["licenseVersion"].(string)
+				s.addLicense(license, version)
+			}
+			s.addContent(library, params)
+			return
+		}
+		for key, child := range t {
+			if !nonTextKeys[key] {
+				s.addParams(child)
+			}
+		}
+	}
+}
+
+func (s *ContentStats) addLicense(id, version string) {
+	if id == "" {
+		return
+	}
+	label := id
+	if l, ok := licenses.Lookup(id); ok {
+		label = l.Label(version)
+	}
+	s.Licenses[label]++
+}
@@ -0,0 +1,116 @@
+package h5p
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPackageStats(t *testing.T) {
+	pkg := NewH5PPackage()
+	pkg.SetPackageDefinition(&PackageDefinition{
+		Title:                 "Quiz",
+		MainLibrary:           "H5P.QuestionSet",
+		License:               "CC BY",
+		LicenseVersion:        "4.0",
+		PreloadedDependencies: []LibraryDependency{dep("H5P.QuestionSet", 1, 20)},
+	})
+	pkg.SetContent(&Content{Params: map[string]any{
+		"introPage": map[string]any{"introduction": "<p>Answer these two questions.</p>"},
+		"texts":     map[string]any{"finishButton": "Finish"},
+		"questions": []any{
+			map[string]any{
+				"library":      "H5P.MultiChoice 1.16",
+				"subContentId": "q1",
+				"metadata":     map[string]any{"title": "Capital", "license": "U"},
+				"params": map[string]any{
+					"question": "<p>What is the capital of France?</p>",
+					"answers": []any{
+						map[string]any{"text": "Paris", "correct": true},
+						map[string]any{"text": "Rome", "correct": false},
+						map[string]any{"text": "Madrid", "correct": false},
+					},
+					"UI":        map[string]any{"checkAnswerButton": "Check"},
+					"behaviour": map[string]any{"type": "auto"},
+					"media": map[string]any{"type": map[string]any{
+						"library": "H5P.Image 1.1",
+						"params": map[string]any{
+							"alt":  "Eiffel tower",
+							"file": map[string]any{"path": "images/tower.jpg", "mime": "image/jpeg", "copyright": map[string]any{"license": "CC BY-SA", "version": "4.0"}},
+						},
+					}},
+				},
+			},
+			map[string]any{
+				"library": "H5P.TrueFalse 1.8",
+				"params":  map[string]any{"question": "The sun is a star.", "correct": "true"},
+			},
+		},
+	}})
+	pkg.AddContentAsset("images/tower.jpg", make([]byte, 1000), "")
+	pkg.AddContentAsset("audio/hint.mp3", make([]byte, 500), "")
+
+	s, err := pkg.Stats(StatsOptions{WordsPerMinute: 60, AnswerTime: 6 * time.Second})
+	if err != nil {
+		t.Fatalf("Failed to compute stats: %v", err)
+	}
+	if s.Questions != 2 || !reflect.DeepEqual(s.QuestionsByLibrary, map[string]int{"H5P.MultiChoice": 1, "H5P.TrueFalse": 1}) {
+		t.Errorf("Unexpected question counts %d %v", s.Questions, s.QuestionsByLibrary)
+	}
+	if s.Answers != 5 || s.AverageAnswers() != 2.5 {
+		t.Errorf("Expected 5 answers, 2.5 per question, got %d, %v", s.Answers, s.AverageAnswers())
+	}
+	// Introduction 4, question 6, answers 3, alt text 2, true/false 5.
+	if s.Words != 20 {
+		t.Errorf("Expected 20 words, got %d", s.Words)
+	}
+	// 20 words at 60 per minute and 5 answers of 6 seconds each, rounded.
+	if s.EstimatedMinutes != 0.83 || s.EstimatedTime() != 50*time.Second {
+		t.Errorf("Expected 0.83 minutes, got %v (%v)", s.EstimatedMinutes, s.EstimatedTime())
+	}
+	wantMedia := MediaStats{Files: 2, Size: 1500, ByType: map[string]FileCount{"image": {1, 1000}, "audio": {1, 500}}}
+	if !reflect.DeepEqual(s.Media, wantMedia) {
+		t.Errorf("Expected media %+v, got %+v", wantMedia, s.Media)
+	}
+	wantLicenses := map[string]int{"CC BY 4.0": 1, "CC BY-SA 4.0": 1, "Undisclosed": 1}
+	if !reflect.DeepEqual(s.Licenses, wantLicenses) {
+		t.Errorf("Expected licenses %v, got %v", wantLicenses, s.Licenses)
+	}
+
+	// The title and introduction, then a section per question.
+	if len(s.Sections) != 3 || s.Sections[1].Path != "questions[0]" || s.Sections[1].Answers != 3 || s.Sections[2].Library != "H5P.TrueFalse 1.8" {
+		t.Fatalf("Unexpected sections %+v", s.Sections)
+	}
+	// Question 6 and answers 3 words, and 3 answers. The alt text is left
+	// out, as H5P.Image has no semantics in the package or package schemas.
+	if q := s.Sections[1]; q.Readability.Words != 9 || q.EstimatedMinutes != 0.45 {
+		t.Errorf("Unexpected question stats %+v", q)
+	}
+	if s.Readability.Words != 19 || s.Readability.GradeLevel == 0 {
+		t.Errorf("Unexpected readability %+v", s.Readability)
+	}
+
+	total := NewContentStats()
+	total.Add(s)
+	total.Add(s)
+	if total.Packages != 2 || total.Questions != 4 || total.Media.ByType["image"].Size != 2000 || total.Licenses["CC BY 4.0"] != 2 || total.Readability.Words != 38 {
+		t.Errorf("Unexpected totals %+v", total)
+	}
+}
+
+func TestPackageStatsSingleQuestion(t *testing.T) {
+	pkg := NewH5PPackage()
+	pkg.SetPackageDefinition(&PackageDefinition{
+		MainLibrary:           "H5P.Blanks",
+		PreloadedDependencies: []LibraryDependency{dep("H5P.Blanks", 1, 14)},
+	})
+	pkg.SetContent(&Content{Params: map[string]any{"questions": []any{"<p>*Paris* is in *France*.</p>"}}})
+
+	s, err := pkg.Stats(StatsOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Questions != 1 || s.QuestionsByLibrary["H5P.Blanks"] != 1 || s.Answers != 2 {
+		t.Errorf("Expected the main content to count as a question with 2 gaps, got %+v", s)
+	}
+}
@@ -0,0 +1,211 @@
+// Package storage keeps H5P packages and libraries behind interfaces, so the
+// serving and editor code can run against local disk, memory or cloud
+// object storage alike.
+//
+// PackageStore and LibraryStorage are implemented by Store on top of a
+// Bucket, a flat key-value blob store. DirBucket keeps blobs in a directory,
+// MemoryBucket in memory and S3Bucket in an S3-compatible object store,
+// such as Amazon S3, Google Cloud Storage through its XML API, or MinIO.
+// Other providers are supported by implementing Bucket.
+//
+// A process serving several isolated H5P sites passes the site of each
+// request as a tenant in its context, see WithTenant; Store keeps the data
+// of each tenant under its own key prefix.
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrNotFound   = errors.New("not found")
+	ErrInvalidKey = errors.New("invalid key")
+)
+
+// Bucket is a flat store of blobs by key. Keys are slash-separated
+// relative paths such as "libraries/H5P.MultiChoice-1.16/library.json".
+type Bucket interface {
+	// Get returns ErrNotFound if there is no blob with the key.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Put stores a blob, replacing any blob with the same key.
+	Put(ctx context.Context, key string, r io.Reader) error
+	// Delete succeeds if there is no blob with the key.
+	Delete(ctx context.Context, key string) error
+	// List returns the sorted keys starting with prefix.
+	List(ctx context.Context, prefix string) ([]string, error)
+}
+
+// CheckKey reports ErrInvalidKey for keys that are not clean relative
+// slash-separated paths, which could escape a DirBucket.
+func CheckKey(key string) error {
+	if key == "" || strings.Contains(key, `\`) || !fs.ValidPath(key) {
+		return fmt.Errorf("%w: %q", ErrInvalidKey, key)
+	}
+	return nil
+}
+
+// MemoryBucket is a Bucket in memory, for tests and for servers whose
+// content is loaded at startup.
+type MemoryBucket struct {
+	mu    sync.RWMutex
+	blobs map[string][]byte
+}
+
+func NewMemoryBucket() *MemoryBucket {
+	return &MemoryBucket{blobs: map[string][]byte{}}
+}
+
+func (b *MemoryBucket) Get(_ context.Context, key string) (io.ReadCloser, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	data, ok := b.blobs[key]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (b *MemoryBucket) Put(_ context.Context, key string, r io.Reader) error {
+	if err := CheckKey(key); err != nil {
+		return err
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.blobs[key] = data
+	return nil
+}
+
+func (b *MemoryBucket) Delete(_ context.Context, key string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.blobs, key)
+	return nil
+}
+
+func (b *MemoryBucket) List(_ context.Context, prefix string) ([]string, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	var keys []string
+	for key := range b.blobs {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	return keys, nil
+}
+
+// DirBucket is a Bucket keeping each blob in a file below Dir.
+type DirBucket struct {
+	Dir string
+}
+
+func NewDirBucket(dir string) *DirBucket {
+	return &DirBucket{Dir: dir}
+}
+
+func (b *DirBucket) path(key string) (string, error) {
+	if err := CheckKey(key); err != nil {
+		return "", err
+	}
+	return filepath.Join(b.Dir, filepath.FromSlash(key)), nil
+}
+
+func (b *DirBucket) Get(_ context.Context, key string) (io.ReadCloser, error) {
+	name, err := b.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(name)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+	return f, err
+}
+
+// Put writes the blob to a temporary file and renames it, so readers never
+// see a partial blob.
+func (b *DirBucket) Put(_ context.Context, key string, r io.Reader) error {
+	name, err := b.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(name), ".put-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), name)
+}
+
+func (b *DirBucket) Delete(_ context.Context, key string) error {
+	name, err := b.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(name); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (b *DirBucket) List(ctx context.Context, prefix string) ([]string, error) {
+	// Walk only the directory holding the prefix.
+	root := path.Dir(prefix + "x")
+	if root != "." {
+		if err := CheckKey(root); err != nil {
+			return nil, err
+		}
+	}
+	var keys []string
+	err := filepath.WalkDir(filepath.Join(b.Dir, filepath.FromSlash(root)), func(name string, d fs.DirEntry, err error) error {
+		if errors.Is(err, os.ErrNotExist) {
+			return fs.SkipAll
+		}
+		if err != nil || d.IsDir() {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(b.Dir, name)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if strings.HasPrefix(key, prefix) && !strings.HasPrefix(path.Base(key), ".put-") {
+			keys = append(keys, key)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	slices.Sort(keys)
+	return keys, nil
+}
@@ -0,0 +1,357 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	h5p "github.com/grokify/h5p-go"
+)
+
+// Key prefixes of the Store layout.
+const (
+	packagesPrefix  = "packages/"
+	contentPrefix   = "content/"
+	librariesPrefix = "libraries/"
+)
+
+// PackageStore keeps uploaded H5P packages by content ID.
+type PackageStore interface {
+	// SavePackage stores the package, replacing any package with the id.
+	SavePackage(ctx context.Context, id string, pkg *h5p.H5PPackage) error
+	// LoadPackage returns ErrNotFound if there is no package with the id.
+	LoadPackage(ctx context.Context, id string) (*h5p.H5PPackage, error)
+	// OpenContentFile opens an asset of the content folder, such as
+	// "images/photo.jpg", for serving.
+	OpenContentFile(ctx context.Context, id, name string) (io.ReadCloser, error)
+	DeletePackage(ctx context.Context, id string) error
+	// ListPackages returns the sorted content IDs.
+	ListPackages(ctx context.Context) ([]string, error)
+}
+
+// LibraryStorage keeps installed libraries by folder name, such as
+// "H5P.MultiChoice-1.16".
+type LibraryStorage interface {
+	// SaveLibrary stores the library, replacing any library in the folder.
+	SaveLibrary(ctx context.Context, lib *h5p.Library) error
+	// LoadLibrary returns the library with its definition and semantics;
+	// its other files are read on demand. It returns ErrNotFound if the
+	// library is not installed.
+	LoadLibrary(ctx context.Context, folder string) (*h5p.Library, error)
+	// OpenLibraryFile opens a library file, such as "js/multichoice.js",
+	// for serving.
+	OpenLibraryFile(ctx context.Context, folder, name string) (io.ReadCloser, error)
+	DeleteLibrary(ctx context.Context, folder string) error
+	// ListLibraries returns the sorted folder names.
+	ListLibraries(ctx context.Context) ([]string, error)
+}
+
+// Store implements PackageStore and LibraryStorage on a Bucket. Packages
+// are kept as archives under packages/<id>.h5p, with their content assets
+// extracted under content/<id>/ for serving, and libraries are kept
+// unpacked under libraries/<folder>/. The data of a tenant other than the
+// default one, see WithTenant, has the same layout under tenants/<tenant>/.
+type Store struct {
+	Bucket Bucket
+}
+
+var (
+	_ PackageStore   = (*Store)(nil)
+	_ LibraryStorage = (*Store)(nil)
+)
+
+func NewStore(b Bucket) *Store {
+	return &Store{Bucket: b}
+}
+
+// NewDirStore returns a Store keeping its files below dir.
+func NewDirStore(dir string) *Store {
+	return NewStore(NewDirBucket(dir))
+}
+
+// NewMemoryStore returns a Store in memory.
+func NewMemoryStore() *Store {
+	return NewStore(NewMemoryBucket())
+}
+
+// checkName reports ErrInvalidKey for IDs and folder names that are not a
+// single path element.
+func checkName(name string) error {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("%w: %q", ErrInvalidKey, name)
+	}
+	return nil
+}
+
+// prefix returns the key prefix p of the layout within the keys of the
+// tenant of ctx.
+func (s *Store) prefix(ctx context.Context, p string) (string, error) {
+	tenant, err := TenantPrefix(ctx)
+	if err != nil {
+		return "", err
+	}
+	return tenant + p, nil
+}
+
+func (s *Store) SavePackage(ctx context.Context, id string, pkg *h5p.H5PPackage) error {
+	if err := checkName(id); err != nil {
+		return err
+	}
+	packages, err := s.prefix(ctx, packagesPrefix)
+	if err != nil {
+		return err
+	}
+	content, err := s.prefix(ctx, contentPrefix+id+"/")
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if _, err := pkg.WriteToContext(ctx, &buf, h5p.WriteOptions{}); err != nil {
+		return err
+	}
+	// Drop the assets of a previous version first.
+	if err := s.deletePrefix(ctx, content); err != nil {
+		return err
+	}
+	for _, name := range pkg.ContentFileNames() {
+		if err := s.putFile(ctx, content+name, pkg.ContentFiles[name]); err != nil {
+			return err
+		}
+	}
+	return s.Bucket.Put(ctx, packages+id+".h5p", &buf)
+}
+
+func (s *Store) putFile(ctx context.Context, key string, cf *h5p.ContentFile) error {
+	rc, err := cf.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return s.Bucket.Put(ctx, key, rc)
+}
+
+// LoadPackage reads the archive into memory and loads it.
+func (s *Store) LoadPackage(ctx context.Context, id string) (*h5p.H5PPackage, error) {
+	if err := checkName(id); err != nil {
+		return nil, err
+	}
+	packages, err := s.prefix(ctx, packagesPrefix)
+	if err != nil {
+		return nil, err
+	}
+	data, err := s.read(ctx, packages+id+".h5p")
+	if err != nil {
+		return nil, err
+	}
+	return h5p.NewPackageLoader().LoadReaderContext(ctx, bytes.NewReader(data), int64(len(data)))
+}
+
+func (s *Store) OpenContentFile(ctx context.Context, id, name string) (io.ReadCloser, error) {
+	if err := checkName(id); err != nil {
+		return nil, err
+	}
+	content, err := s.prefix(ctx, contentPrefix+id+"/")
+	if err != nil {
+		return nil, err
+	}
+	return s.Bucket.Get(ctx, content+name)
+}
+
+func (s *Store) DeletePackage(ctx context.Context, id string) error {
+	if err := checkName(id); err != nil {
+		return err
+	}
+	packages, err := s.prefix(ctx, packagesPrefix)
+	if err != nil {
+		return err
+	}
+	content, err := s.prefix(ctx, contentPrefix+id+"/")
+	if err != nil {
+		return err
+	}
+	if err := s.deletePrefix(ctx, content); err != nil {
+		return err
+	}
+	return s.Bucket.Delete(ctx, packages+id+".h5p")
+}
+
+func (s *Store) ListPackages(ctx context.Context) ([]string, error) {
+	packages, err := s.prefix(ctx, packagesPrefix)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := s.Bucket.List(ctx, packages)
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, key := range keys {
+		id, ok := strings.CutSuffix(strings.TrimPrefix(key, packages), ".h5p")
+		if ok && !strings.Contains(id, "/") {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// SaveLibrary writes library.json and semantics.json from the definition
+// and semantics, and the library files as they are.
+func (s *Store) SaveLibrary(ctx context.Context, lib *h5p.Library) error {
+	if err := checkName(lib.MachineName); err != nil {
+		return err
+	}
+	if lib.Definition == nil {
+		return fmt.Errorf("library %s has no definition", lib.MachineName)
+	}
+	prefix, err := s.prefix(ctx, librariesPrefix+lib.MachineName+"/")
+	if err != nil {
+		return err
+	}
+	if err := s.deletePrefix(ctx, prefix); err != nil {
+		return err
+	}
+	for _, name := range lib.FileNames() {
+		rc, err := lib.OpenFile(name)
+		if err != nil {
+			return err
+		}
+		err = s.Bucket.Put(ctx, prefix+name, rc)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	if lib.Semantics != nil {
+		if err := s.putJSON(ctx, prefix+"semantics.json", lib.Semantics); err != nil {
+			return err
+		}
+	}
+	// library.json goes last, so a library is only listed once complete.
+	return s.putJSON(ctx, prefix+"library.json", lib.Definition)
+}
+
+func (s *Store) LoadLibrary(ctx context.Context, folder string) (*h5p.Library, error) {
+	if err := checkName(folder); err != nil {
+		return nil, err
+	}
+	prefix, err := s.prefix(ctx, librariesPrefix+folder+"/")
+	if err != nil {
+		return nil, err
+	}
+	data, err := s.read(ctx, prefix+"library.json")
+	if err != nil {
+		return nil, err
+	}
+	lib := &h5p.Library{MachineName: folder, Definition: &h5p.LibraryDefinition{}}
+	if err := json.Unmarshal(data, lib.Definition); err != nil {
+		return nil, fmt.Errorf("library %s: library.json: %w", folder, err)
+	}
+	switch data, err := s.read(ctx, prefix+"semantics.json"); {
+	case errors.Is(err, ErrNotFound):
+	case err != nil:
+		return nil, err
+	default:
+		if err := json.Unmarshal(data, &lib.Semantics); err != nil {
+			return nil, fmt.Errorf("library %s: semantics.json: %w", folder, err)
+		}
+	}
+
+	keys, err := s.Bucket.List(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range keys {
+		name := strings.TrimPrefix(key, prefix)
+		if name == "library.json" || name == "semantics.json" {
+			continue
+		}
+		if lib.LazyFiles == nil {
+			lib.LazyFiles = map[string]h5p.FileOpener{}
+		}
+		lib.LazyFiles[name] = func() (io.ReadCloser, error) {
+			return s.Bucket.Get(context.WithoutCancel(ctx), key)
+		}
+	}
+	return lib, nil
+}
+
+func (s *Store) OpenLibraryFile(ctx context.Context, folder, name string) (io.ReadCloser, error) {
+	if err := checkName(folder); err != nil {
+		return nil, err
+	}
+	prefix, err := s.prefix(ctx, librariesPrefix+folder+"/")
+	if err != nil {
+		return nil, err
+	}
+	return s.Bucket.Get(ctx, prefix+name)
+}
+
+func (s *Store) DeleteLibrary(ctx context.Context, folder string) error {
+	if err := checkName(folder); err != nil {
+		return err
+	}
+	prefix, err := s.prefix(ctx, librariesPrefix+folder+"/")
+	if err != nil {
+		return err
+	}
+	// library.json goes first, so a partly deleted library is not listed.
+	if err := s.Bucket.Delete(ctx, prefix+"library.json"); err != nil {
+		return err
+	}
+	return s.deletePrefix(ctx, prefix)
+}
+
+// ListLibraries lists the folders holding a library.json.
+func (s *Store) ListLibraries(ctx context.Context) ([]string, error) {
+	libraries, err := s.prefix(ctx, librariesPrefix)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := s.Bucket.List(ctx, libraries)
+	if err != nil {
+		return nil, err
+	}
+	var folders []string
+	for _, key := range keys {
+		dir, file := path.Split(strings.TrimPrefix(key, libraries))
+		if file == "library.json" && dir != "" && !strings.Contains(dir[:len(dir)-1], "/") {
+			folders = append(folders, dir[:len(dir)-1])
+		}
+	}
+	return folders, nil
+}
+
+func (s *Store) read(ctx context.Context, key string) ([]byte, error) {
+	rc, err := s.Bucket.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+func (s *Store) putJSON(ctx context.Context, key string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return s.Bucket.Put(ctx, key, bytes.NewReader(data))
+}
+
+func (s *Store) deletePrefix(ctx context.Context, prefix string) error {
+	keys, err := s.Bucket.List(ctx, prefix)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if err := s.Bucket.Delete(ctx, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
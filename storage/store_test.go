@@ -0,0 +1,178 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	h5p "github.com/grokify/h5p-go"
+)
+
+func testPackage() *h5p.H5PPackage {
+	pkg := h5p.NewH5PPackage()
+	pkg.SetPackageDefinition(&h5p.PackageDefinition{
+		Title:                 "Quiz",
+		Language:              "en",
+		MainLibrary:           "H5P.MultiChoice",
+		PreloadedDependencies: []h5p.LibraryDependency{{MachineName: "H5P.MultiChoice", MajorVersion: 1, MinorVersion: 16}},
+	})
+	pkg.SetContent(&h5p.Content{Params: map[string]any{"question": "<p>2 + 2?</p>"}})
+	pkg.AddLibrary(testLibrary())
+	if err := pkg.AddContentAsset("images/photo.jpg", []byte("jpeg"), ""); err != nil {
+		panic(err)
+	}
+	return pkg
+}
+
+func testLibrary() *h5p.Library {
+	return &h5p.Library{
+		MachineName: "H5P.MultiChoice-1.16",
+		Definition: &h5p.LibraryDefinition{
+			Title: "Multiple Choice", MachineName: "H5P.MultiChoice", MajorVersion: 1, MinorVersion: 16, Runnable: true,
+			PreloadedJs: []h5p.FileReference{{Path: "js/multichoice.js"}},
+		},
+		Semantics: []any{map[string]any{"name": "question", "type": "text"}},
+		Files:     map[string][]byte{"js/multichoice.js": []byte("H5P.MultiChoice = {};")},
+	}
+}
+
+func readAll(t *testing.T, rc io.ReadCloser, err error) string {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func testStore(t *testing.T, s *Store) {
+	ctx := context.Background()
+
+	if err := s.SavePackage(ctx, "42", testPackage()); err != nil {
+		t.Fatal(err)
+	}
+	pkg, err := s.LoadPackage(ctx, "42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pkg.PackageDefinition.Title != "Quiz" || len(pkg.Libraries) != 1 {
+		t.Errorf("Unexpected package %+v", pkg.PackageDefinition)
+	}
+	rc, err := s.OpenContentFile(ctx, "42", "images/photo.jpg")
+	if got := readAll(t, rc, err); got != "jpeg" {
+		t.Errorf("Expected the content asset, got %q", got)
+	}
+	if ids, err := s.ListPackages(ctx); err != nil || !reflect.DeepEqual(ids, []string{"42"}) {
+		t.Errorf("Expected [42], got %v, %v", ids, err)
+	}
+	if err := s.DeletePackage(ctx, "42"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.LoadPackage(ctx, "42"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound after delete, got %v", err)
+	}
+	if _, err := s.OpenContentFile(ctx, "42", "images/photo.jpg"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected the assets deleted, got %v", err)
+	}
+
+	if err := s.SaveLibrary(ctx, testLibrary()); err != nil {
+		t.Fatal(err)
+	}
+	lib, err := s.LoadLibrary(ctx, "H5P.MultiChoice-1.16")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lib.Definition.Title != "Multiple Choice" || lib.Semantics == nil {
+		t.Errorf("Unexpected library %+v", lib.Definition)
+	}
+	if names := lib.FileNames(); !reflect.DeepEqual(names, []string{"js/multichoice.js"}) {
+		t.Errorf("Expected the script only, got %v", names)
+	}
+	if data, err := lib.ReadFile("js/multichoice.js"); err != nil || !strings.HasPrefix(string(data), "H5P.MultiChoice") {
+		t.Errorf("Unexpected lazy file %q, %v", data, err)
+	}
+	rc, err = s.OpenLibraryFile(ctx, "H5P.MultiChoice-1.16", "library.json")
+	if got := readAll(t, rc, err); !strings.Contains(got, `"machineName": "H5P.MultiChoice"`) {
+		t.Errorf("Unexpected library.json %s", got)
+	}
+	if folders, err := s.ListLibraries(ctx); err != nil || !reflect.DeepEqual(folders, []string{"H5P.MultiChoice-1.16"}) {
+		t.Errorf("Expected one library, got %v, %v", folders, err)
+	}
+	if err := s.DeleteLibrary(ctx, "H5P.MultiChoice-1.16"); err != nil {
+		t.Fatal(err)
+	}
+	if folders, err := s.ListLibraries(ctx); err != nil || len(folders) != 0 {
+		t.Errorf("Expected no libraries, got %v, %v", folders, err)
+	}
+	if _, err := s.Bucket.Get(ctx, "libraries/H5P.MultiChoice-1.16/js/multichoice.js"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected the library files deleted, got %v", err)
+	}
+
+	for _, id := range []string{"", "..", "a/b"} {
+		if err := s.SavePackage(ctx, id, testPackage()); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("Expected ErrInvalidKey for %q, got %v", id, err)
+		}
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, NewMemoryStore())
+}
+
+func TestDirStore(t *testing.T) {
+	testStore(t, NewDirStore(t.TempDir()))
+}
+
+func TestStoreTenants(t *testing.T) {
+	s := NewMemoryStore()
+	ctx := context.Background()
+	school := WithTenant(ctx, "school")
+	if err := s.SavePackage(school, "42", testPackage()); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SaveLibrary(school, testLibrary()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.LoadPackage(ctx, "42"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected the package of a tenant hidden from the default site, got %v", err)
+	}
+	if ids, err := s.ListPackages(WithTenant(ctx, "other")); err != nil || len(ids) != 0 {
+		t.Errorf("Expected no packages for another tenant, got %v, %v", ids, err)
+	}
+	if folders, err := s.ListLibraries(ctx); err != nil || len(folders) != 0 {
+		t.Errorf("Expected no libraries for the default site, got %v, %v", folders, err)
+	}
+	if ids, err := s.ListPackages(school); err != nil || !reflect.DeepEqual(ids, []string{"42"}) {
+		t.Errorf("Expected [42], got %v, %v", ids, err)
+	}
+	rc, err := s.OpenContentFile(school, "42", "images/photo.jpg")
+	if got := readAll(t, rc, err); got != "jpeg" {
+		t.Errorf("Expected the content asset, got %q", got)
+	}
+	if _, err := s.Bucket.Get(ctx, "tenants/school/libraries/H5P.MultiChoice-1.16/library.json"); err != nil {
+		t.Errorf("Expected the library under the tenant prefix, got %v", err)
+	}
+	if _, err := s.LoadPackage(WithTenant(ctx, ".."), "42"); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("Expected ErrInvalidKey for an invalid tenant, got %v", err)
+	}
+}
+
+func TestDirBucketRejectsTraversal(t *testing.T) {
+	b := NewDirBucket(t.TempDir())
+	ctx := context.Background()
+	for _, key := range []string{"../escape", "/abs", "a/../b", `a\b`} {
+		if err := b.Put(ctx, key, strings.NewReader("x")); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("Expected ErrInvalidKey for %q, got %v", key, err)
+		}
+	}
+	if keys, err := b.List(ctx, "missing/"); err != nil || len(keys) != 0 {
+		t.Errorf("Expected no keys in a missing directory, got %v, %v", keys, err)
+	}
+}
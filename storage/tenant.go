@@ -0,0 +1,48 @@
+package storage
+
+import (
+	"context"
+)
+
+// tenantsPrefix is the key prefix of the data of tenants other than the
+// default one.
+const tenantsPrefix = "tenants/"
+
+type tenantKey struct{}
+
+// WithTenant returns a context for requests of a tenant, one of several
+// isolated H5P sites served by a process. Store and the stores of package
+// server keep the libraries, content and user data of each tenant apart.
+// The empty tenant is the default site, whose data is kept as if there
+// were no tenants.
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// Tenant returns the tenant of ctx, or "" for the default site.
+func Tenant(ctx context.Context) string {
+	tenant, _ := ctx.Value(tenantKey{}).(string)
+	return tenant
+}
+
+// CheckTenant reports ErrInvalidKey for tenants that are not a single path
+// element. The empty tenant is valid.
+func CheckTenant(tenant string) error {
+	if tenant == "" {
+		return nil
+	}
+	return checkName(tenant)
+}
+
+// TenantPrefix returns the prefix of the keys of the tenant of ctx,
+// "tenants/<tenant>/", or "" for the default site.
+func TenantPrefix(ctx context.Context) (string, error) {
+	tenant := Tenant(ctx)
+	if tenant == "" {
+		return "", nil
+	}
+	if err := checkName(tenant); err != nil {
+		return "", err
+	}
+	return tenantsPrefix + tenant + "/", nil
+}
@@ -0,0 +1,128 @@
+package h5p
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/grokify/h5p-go/schemas"
+)
+
+// UnknownFieldsError lists JSON fields that the package types do not model
+// and that would be dropped when the data is written back.
+type UnknownFieldsError struct {
+	// Fields holds JSON paths, prefixed with the file name when loading a
+	// package, e.g. "h5p.json: metaKeywords".
+	Fields []string
+}
+
+func (e *UnknownFieldsError) Error() string {
+	return fmt.Sprintf("%d unknown field(s): %s", len(e.Fields), strings.Join(e.Fields, ", "))
+}
+
+// FromJSONStrict is FromJSON that fails with an *UnknownFieldsError if data
+// contains fields QuestionSet does not model. Question params are not
+// checked as they are kept verbatim.
+func FromJSONStrict(data []byte) (*QuestionSet, error) {
+	unknown, err := unknownFields(data, reflect.TypeOf(QuestionSet{}))
+	if err != nil {
+		return nil, err
+	}
+	if len(unknown) > 0 {
+		return nil, &UnknownFieldsError{Fields: unknown}
+	}
+	return FromJSON(data)
+}
+
+// definitionType returns the type a package definition file is decoded
+// into, or nil if the file is kept verbatim. content/content.json is only
+// decoded into Content in the {"questionSet": ...} form.
+func definitionType(name string) reflect.Type {
+	switch {
+	case name == "h5p.json":
+		return reflect.TypeOf(PackageDefinition{})
+	case name == "content/content.json":
+		return reflect.TypeOf(Content{})
+	case name == ManifestFile:
+		return reflect.TypeOf(Manifest{})
+	case isLibraryDefinitionFile(name, "library.json"):
+		return reflect.TypeOf(LibraryDefinition{})
+	}
+	return nil
+}
+
+// unknownFields decodes data generically and returns the sorted JSON paths
+// of object keys that have no matching field in t.
+func unknownFields(data []byte, t reflect.Type) ([]string, error) {
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	var unknown []string
+	walkUnknownFields(v, t, "", &unknown)
+	sort.Strings(unknown)
+	return unknown, nil
+}
+
+func walkUnknownFields(v any, t reflect.Type, path string, unknown *[]string) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return
+		}
+		fields := jsonFields(t)
+		for key, child := range obj {
+			ft, ok := fields[key]
+			if !ok {
+				// encoding/json matches keys case-insensitively.
+				for name, f := range fields {
+					if strings.EqualFold(name, key) {
+						ft, ok = f, true
+						break
+					}
+				}
+			}
+			if !ok {
+				*unknown = append(*unknown, schemas.JoinPath(path, key))
+				continue
+			}
+			walkUnknownFields(child, ft, schemas.JoinPath(path, key), unknown)
+		}
+	case reflect.Slice, reflect.Array:
+		arr, _ := v.([]any)
+		for i, child := range arr {
+			walkUnknownFields(child, t.Elem(), schemas.IndexPath(path, i), unknown)
+		}
+	case reflect.Map:
+		obj, _ := v.(map[string]any)
+		for key, child := range obj {
+			walkUnknownFields(child, t.Elem(), schemas.JoinPath(path, key), unknown)
+		}
+	}
+}
+
+// jsonFields maps the JSON names of the exported fields of struct type t to
+// their types, following encoding/json naming rules.
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+	for _, f := range reflect.VisibleFields(t) {
+		if !f.IsExported() || f.Anonymous {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		switch name {
+		case "-":
+			continue
+		case "":
+			name = f.Name
+		}
+		fields[name] = f.Type
+	}
+	return fields
+}
@@ -0,0 +1,98 @@
+package h5p
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+func TestFromJSONStrict(t *testing.T) {
+	data := []byte(`{
+		"title": "Quiz",
+		"progressType": "dots",
+		"disableBackwardsNavigation": true,
+		"questions": [
+			{"library": "H5P.MultiChoice 1.16", "params": {"anything": 1}, "weight": 2}
+		],
+		"overallFeedback": [{"from": 0, "to": 100, "text": "ok", "emoji": ":)"}]
+	}`)
+
+	_, err := FromJSONStrict(data)
+	var unknown *UnknownFieldsError
+	if !errors.As(err, &unknown) {
+		t.Fatalf("Expected *UnknownFieldsError, got %v", err)
+	}
+	want := []string{"disableBackwardsNavigation", "overallFeedback[0].emoji", "questions[0].weight"}
+	if !reflect.DeepEqual(unknown.Fields, want) {
+		t.Errorf("Expected unknown fields %v, got %v", want, unknown.Fields)
+	}
+
+	qs, err := FromJSONStrict([]byte(`{"title":"Quiz","questions":[{"library":"H5P.MultiChoice 1.16","params":{"x":1}}]}`))
+	if err != nil {
+		t.Fatalf("Expected modelled JSON to decode, got %v", err)
+	}
+	if qs.Title != "Quiz" || len(qs.Questions) != 1 {
+		t.Errorf("Unexpected question set: %+v", qs)
+	}
+
+	if _, err := FromJSONStrict([]byte(`{`)); err == nil {
+		t.Error("Expected syntax error")
+	}
+}
+
+func TestPackageLoaderStrict(t *testing.T) {
+	files := map[string][]byte{
+		"h5p.json":             []byte(`{"title":"Test","mainLibrary":"H5P.QuestionSet","metaKeywords":"x"}`),
+		"content/content.json": []byte(`{"questionSet":{"title":"Quiz","textualProgress":"1 of 2"}}`),
+		"H5P.Foo-1.0/library.json": []byte(
+			`{"machineName":"H5P.Foo","majorVersion":1,"minorVersion":0,"customField":true}`),
+	}
+	want := []string{
+		"h5p.json: metaKeywords",
+		"content/content.json: questionSet.textualProgress",
+		"H5P.Foo-1.0/library.json: customField",
+	}
+
+	zipPath := writeTestZip(t, files)
+	if _, err := LoadH5PPackage(zipPath); err != nil {
+		t.Fatalf("Expected lenient load to succeed, got %v", err)
+	}
+
+	_, err := LoadH5PPackageStrict(zipPath)
+	var unknown *UnknownFieldsError
+	if !errors.As(err, &unknown) {
+		t.Fatalf("Expected *UnknownFieldsError, got %v", err)
+	}
+	for _, w := range want {
+		if !containsString(unknown.Fields, w) {
+			t.Errorf("Expected unknown field %q in %v", w, unknown.Fields)
+		}
+	}
+
+	fsys := fstest.MapFS{}
+	for name, data := range files {
+		fsys[name] = &fstest.MapFile{Data: data}
+	}
+	loader := NewPackageLoader()
+	loader.Strict = true
+	if _, err := loader.LoadFS(fsys); !errors.As(err, &unknown) || len(unknown.Fields) != len(want) {
+		t.Errorf("Expected %d unknown fields from FS, got %v", len(want), err)
+	}
+}
+
+func TestPackageLoaderStrictContentParams(t *testing.T) {
+	fsys := fstest.MapFS{
+		"h5p.json":             {Data: []byte(`{"title":"Test","mainLibrary":"H5P.MultiChoice"}`)},
+		"content/content.json": {Data: []byte(`{"question":"<p>2 + 2?</p>","answers":[{"text":"4","correct":true}]}`)},
+	}
+	loader := NewPackageLoader()
+	loader.Strict = true
+	pkg, err := loader.LoadFS(fsys)
+	if err != nil {
+		t.Fatalf("Expected content params accepted in strict mode, got %v", err)
+	}
+	if params, ok := pkg.Content.Params.(map[string]any); !ok || params["question"] == nil {
+		t.Errorf("Unexpected content %+v", pkg.Content)
+	}
+}
@@ -0,0 +1,80 @@
+package h5p
+
+import (
+	"crypto/rand"
+	"fmt"
+
+	"github.com/grokify/h5p-go/licenses"
+)
+
+// ContentMetadata is the metadata block the H5P editor writes for each
+// sub-content, such as a question inside a question set.
+type ContentMetadata struct {
+	Title           string           `json:"title,omitempty"`
+	ContentType     string           `json:"contentType,omitempty"`
+	License         string           `json:"license,omitempty"`
+	LicenseVersion  string           `json:"licenseVersion,omitempty"`
+	LicenseExtras   string           `json:"licenseExtras,omitempty"`
+	Authors         []Author         `json:"authors,omitempty"`
+	AuthorComments  string           `json:"authorComments,omitempty"`
+	Source          string           `json:"source,omitempty"`
+	YearFrom        int              `json:"yearFrom,omitempty"`
+	YearTo          int              `json:"yearTo,omitempty"`
+	ExtraTitle      string           `json:"extraTitle,omitempty"`
+	DefaultLanguage string           `json:"defaultLanguage,omitempty"`
+	Changes         []MetadataChange `json:"changes,omitempty"`
+	// Tags are keywords for organizing questions, e.g. to split a question
+	// set by topic. They are not part of H5P core metadata, which ignores
+	// them.
+	Tags []string `json:"tags,omitempty"`
+	// Difficulty grades questions for assembling exams, e.g.
+	// DifficultyEasy. Like Tags, it is not part of H5P core metadata.
+	Difficulty string `json:"difficulty,omitempty"`
+}
+
+// MetadataChange is an entry of the change log in a metadata block.
+type MetadataChange struct {
+	Date   string `json:"date,omitempty"`
+	Author string `json:"author,omitempty"`
+	Log    string `json:"log,omitempty"`
+}
+
+// LicenseMetadata returns the license metadata of the block, see
+// licenses.Attribution.
+func (m *ContentMetadata) LicenseMetadata() licenses.Metadata {
+	lm := licenses.Metadata{
+		Title:          m.Title,
+		Source:         m.Source,
+		YearFrom:       m.YearFrom,
+		YearTo:         m.YearTo,
+		License:        m.License,
+		LicenseVersion: m.LicenseVersion,
+	}
+	for _, a := range m.Authors {
+		lm.Authors = append(lm.Authors, licenses.Author{Name: a.Name, Role: a.Role})
+	}
+	return lm
+}
+
+// NewSubContentID returns a random version 4 UUID for use as a
+// subContentId. LMSs use it to attribute xAPI statements to individual
+// questions, so it must stay stable once content is published.
+func NewSubContentID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(fmt.Sprintf("h5p: failed to generate subContentId: %v", err))
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// EnsureSubContentIDs assigns a new subContentId to every question without
+// one. Existing IDs are kept.
+func (qs *QuestionSet) EnsureSubContentIDs() {
+	for i := range qs.Questions {
+		if qs.Questions[i].SubContentID == "" {
+			qs.Questions[i].SubContentID = NewSubContentID()
+		}
+	}
+}
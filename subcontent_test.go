@@ -0,0 +1,71 @@
+package h5p
+
+import (
+	"encoding/json"
+	"regexp"
+	"testing"
+)
+
+var uuidV4Pattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+func TestNewSubContentID(t *testing.T) {
+	seen := map[string]bool{}
+	for i := 0; i < 100; i++ {
+		id := NewSubContentID()
+		if !uuidV4Pattern.MatchString(id) {
+			t.Fatalf("Expected version 4 UUID, got %s", id)
+		}
+		if seen[id] {
+			t.Fatalf("Duplicate subContentId %s", id)
+		}
+		seen[id] = true
+	}
+}
+
+func TestSubContentMetadataRoundTrip(t *testing.T) {
+	qs, err := NewQuestionSetBuilder().
+		AddMultipleChoiceQuestion("Capital of France?", []Answer{CreateAnswer("Paris", true)}).
+		Build()
+	if err != nil {
+		t.Fatalf("Failed to build question set: %v", err)
+	}
+	qs.Questions = append(qs.Questions, Question{Library: "H5P.TrueFalse 1.8", Params: map[string]any{}})
+	qs.EnsureSubContentIDs()
+
+	first := qs.Questions[0]
+	if !uuidV4Pattern.MatchString(first.SubContentID) || !uuidV4Pattern.MatchString(qs.Questions[1].SubContentID) {
+		t.Fatalf("Expected subContentIds on all questions, got %q and %q", first.SubContentID, qs.Questions[1].SubContentID)
+	}
+	if first.Metadata == nil || first.Metadata.ContentType != "Multiple Choice" {
+		t.Errorf("Expected MultiChoice metadata, got %+v", first.Metadata)
+	}
+
+	editorJSON := []byte(`{"questions":[{"library":"H5P.MultiChoice 1.16","params":{},
+		"subContentId":"0f6c2b3a-1d2e-4f50-8a9b-0c1d2e3f4a5b",
+		"metadata":{"title":"Capitals","license":"CC BY","licenseVersion":"4.0",
+		"authors":[{"name":"Jane","role":"Author"}],"contentType":"Multiple Choice"}}]}`)
+	loaded, err := FromJSON(editorJSON)
+	if err != nil {
+		t.Fatalf("Failed to parse question set: %v", err)
+	}
+	loaded.EnsureSubContentIDs()
+	q := loaded.Questions[0]
+	if q.SubContentID != "0f6c2b3a-1d2e-4f50-8a9b-0c1d2e3f4a5b" {
+		t.Errorf("Existing subContentId was not preserved: %s", q.SubContentID)
+	}
+	if q.Metadata.License != "CC BY" || len(q.Metadata.Authors) != 1 {
+		t.Errorf("Metadata not parsed: %+v", q.Metadata)
+	}
+
+	out, err := loaded.ToJSON()
+	if err != nil {
+		t.Fatalf("Failed to marshal question set: %v", err)
+	}
+	var roundTrip QuestionSet
+	if err := json.Unmarshal(out, &roundTrip); err != nil {
+		t.Fatalf("Failed to unmarshal question set: %v", err)
+	}
+	if roundTrip.Questions[0].SubContentID != q.SubContentID || roundTrip.Questions[0].Metadata.Title != "Capitals" {
+		t.Error("subContentId or metadata lost on round-trip")
+	}
+}
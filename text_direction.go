@@ -0,0 +1,230 @@
+package h5p
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/grokify/h5p-go/schemas"
+	"github.com/grokify/h5p-go/semantics"
+)
+
+// Text directions, the values of the HTML dir attribute.
+const (
+	DirectionLTR = "ltr"
+	DirectionRTL = "rtl"
+)
+
+// CodeMixedDirection reports text mixing right-to-left and left-to-right
+// scripts without markup telling their direction.
+const CodeMixedDirection = "mixed_direction"
+
+var ErrInvalidDirection = errors.New("invalid text direction")
+
+// RTLLanguages lists the languages written right to left.
+var RTLLanguages = []string{"ar", "ckb", "dv", "fa", "he", "ps", "sd", "ug", "ur", "yi"}
+
+// rtlScripts are the BCP-47 script subtags of right-to-left scripts.
+var rtlScripts = []string{"adlm", "arab", "hebr", "nkoo", "rohg", "syrc", "thaa"}
+
+// LanguageDirection returns DirectionRTL for language codes such as "ar",
+// "he-IL" or "az-Arab" written right to left, and DirectionLTR otherwise.
+func LanguageDirection(code string) string {
+	parts := strings.FieldsFunc(strings.ToLower(code), func(r rune) bool { return r == '-' || r == '_' })
+	if len(parts) == 0 {
+		return DirectionLTR
+	}
+	if len(parts) > 1 && len(parts[1]) == 4 {
+		if slices.Contains(rtlScripts, parts[1]) {
+			return DirectionRTL
+		}
+		return DirectionLTR
+	}
+	if slices.Contains(RTLLanguages, parts[0]) {
+		return DirectionRTL
+	}
+	return DirectionLTR
+}
+
+func checkDirection(dir string) error {
+	if dir != DirectionLTR && dir != DirectionRTL {
+		return fmt.Errorf("%w: %q", ErrInvalidDirection, dir)
+	}
+	return nil
+}
+
+// dirAttrPattern matches a dir attribute in markup.
+var dirAttrPattern = regexp.MustCompile(`(?i)<[a-z][^>]*\sdir\s*=`)
+
+// WrapDirection returns html inside a div with the dir attribute, e.g.
+// <div dir="rtl">...</div>. Empty html and html whose first element has a
+// dir attribute are returned as they are.
+func WrapDirection(html, dir string) string {
+	trimmed := strings.TrimSpace(html)
+	if trimmed == "" {
+		return html
+	}
+	if loc := dirAttrPattern.FindStringIndex(trimmed); loc != nil && loc[0] == 0 {
+		return html
+	}
+	return `<div dir="` + dir + `">` + html + `</div>`
+}
+
+// builtinSemanticsBytes holds the semantics shipped in package schemas,
+// keyed by library machine name.
+var builtinSemanticsBytes = map[string][]byte{
+	"H5P.Accordion":          schemas.AccordionSemanticsBytes,
+	"H5P.Blanks":             schemas.BlanksSemanticsBytes,
+	"H5P.Column":             schemas.ColumnSemanticsBytes,
+	"H5P.CoursePresentation": schemas.CoursePresentationSemanticsBytes,
+	"H5P.Crossword":          schemas.CrosswordSemanticsBytes,
+	"H5P.Dialogcards":        schemas.DialogCardsSemanticsBytes,
+	"H5P.DragQuestion":       schemas.DragQuestionSemanticsBytes,
+	"H5P.DragText":           schemas.DragTextSemanticsBytes,
+	"H5P.Essay":              schemas.EssaySemanticsBytes,
+	"H5P.ImageHotspots":      schemas.ImageHotspotsSemanticsBytes,
+	"H5P.InteractiveVideo":   schemas.InteractiveVideoSemanticsBytes,
+	"H5P.MarkTheWords":       schemas.MarkTheWordsSemanticsBytes,
+	"H5P.MemoryGame":         schemas.MemoryGameSemanticsBytes,
+	"H5P.MultiChoice":        schemas.MultiChoiceSemanticsBytes,
+	"H5P.Questionnaire":      schemas.QuestionnaireSemanticsBytes,
+	"H5P.QuestionSet":        schemas.QuestionSetSemanticsBytes,
+	"H5P.SingleChoiceSet":    schemas.SingleChoiceSetSemanticsBytes,
+	"H5P.Summary":            schemas.SummarySemanticsBytes,
+	"H5P.TrueFalse":          schemas.TrueFalseSemanticsBytes,
+}
+
+// builtinSemanticsCache holds the parsed builtinSemanticsBytes.
+var builtinSemanticsCache sync.Map
+
+// builtinSemantics returns the semantics shipped in package schemas for a
+// library string such as "H5P.MultiChoice 1.16", whatever its version.
+// The result is shared and must not be modified.
+func builtinSemantics(library string) (semantics.SemanticDefinition, bool) {
+	name, _, _ := strings.Cut(library, " ")
+	if sd, ok := builtinSemanticsCache.Load(name); ok {
+		return sd.(semantics.SemanticDefinition), true
+	}
+	data, ok := builtinSemanticsBytes[name]
+	if !ok {
+		return nil, false
+	}
+	var sd semantics.SemanticDefinition
+	if err := json.Unmarshal(data, &sd); err != nil {
+		return nil, false
+	}
+	builtinSemanticsCache.Store(name, sd)
+	return sd, true
+}
+
+// questionSetStrings returns the translatable strings of qs, including
+// those of questions of content types with semantics in package schemas.
+// The introduction, kept outside the introPage group of the semantics, is
+// added as an html field.
+func (qs *QuestionSet) questionSetStrings() (semantics.StringExtractor, semantics.SemanticDefinition, []semantics.TranslatableString, error) {
+	sd, _ := builtinSemantics(questionSetLibrary)
+	e := semantics.StringExtractor{Resolve: builtinSemantics, Library: questionSetLibrary}
+	strs, err := e.Extract(sd, qs)
+	if err != nil {
+		return e, nil, nil, err
+	}
+	if qs.Introduction != "" {
+		strs = append(strs, semantics.TranslatableString{Key: "introduction", Text: qs.Introduction, Library: questionSetLibrary, HTML: true})
+	}
+	return e, sd, strs, nil
+}
+
+// WithTextDirection returns a copy of qs whose HTML texts are wrapped in an
+// element with the dir attribute of dir, see WrapDirection, so they show
+// in that direction whatever the direction of the page embedding the
+// content. Texts of questions whose content type has no semantics in
+// package schemas, and plain text fields, are left as they are.
+func (qs *QuestionSet) WithTextDirection(dir string) (*QuestionSet, error) {
+	if err := checkDirection(dir); err != nil {
+		return nil, err
+	}
+	e, sd, strs, err := qs.questionSetStrings()
+	if err != nil {
+		return nil, err
+	}
+	wrapped := map[string]string{}
+	for _, s := range strs {
+		if s.HTML {
+			wrapped[s.Key] = WrapDirection(s.Text, dir)
+		}
+	}
+	params, err := e.Apply(sd, qs, wrapped)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+	var out QuestionSet
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, err
+	}
+	if intro, ok := wrapped["introduction"]; ok {
+		out.Introduction = intro
+	}
+	return &out, nil
+}
+
+// validateTextDirection warns about texts mixing right-to-left and
+// left-to-right scripts without a dir attribute, bdi or bdo element or
+// Unicode direction marks, which browsers may show garbled.
+func (qs *QuestionSet) validateTextDirection(r *ValidationResult) {
+	_, _, strs, err := qs.questionSetStrings()
+	if err != nil {
+		return
+	}
+	for _, s := range strs {
+		if mixedDirection(s.Text) && !hasDirectionMarkup(s.Text) {
+			r.AddWarning(s.Key, CodeMixedDirection, "text mixes right-to-left and left-to-right scripts without direction markup")
+		}
+	}
+}
+
+// markupPattern matches HTML tags and entities and placeholders such as
+// :num or @total, which are in Latin script whatever the text.
+var markupPattern = regexp.MustCompile(`<[^>]*>|&[a-zA-Z#0-9]+;|[:@%][a-zA-Z]+`)
+
+var rtlRanges = []*unicode.RangeTable{unicode.Arabic, unicode.Hebrew, unicode.Nko, unicode.Syriac, unicode.Thaana}
+
+// mixedDirection reports whether the text of s has letters of both
+// right-to-left and left-to-right scripts.
+func mixedDirection(s string) bool {
+	var rtl, ltr bool
+	for _, r := range markupPattern.ReplaceAllString(s, " ") {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		if unicode.In(r, rtlRanges...) {
+			rtl = true
+		} else {
+			ltr = true
+		}
+		if rtl && ltr {
+			return true
+		}
+	}
+	return false
+}
+
+// hasDirectionMarkup reports whether s sets the direction of its text.
+func hasDirectionMarkup(s string) bool {
+	if dirAttrPattern.MatchString(s) || strings.Contains(strings.ToLower(s), "<bdi") || strings.Contains(strings.ToLower(s), "<bdo") {
+		return true
+	}
+	return strings.ContainsFunc(s, func(r rune) bool {
+		// LRM, RLM, ALM, embeddings, overrides and isolates.
+		return r == '\u200e' || r == '\u200f' || r == '\u061c' ||
+			(r >= '\u202a' && r <= '\u202e') || (r >= '\u2066' && r <= '\u2069')
+	})
+}
@@ -0,0 +1,88 @@
+package h5p
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestLanguageDirection(t *testing.T) {
+	for code, want := range map[string]string{
+		"ar": DirectionRTL, "he-IL": DirectionRTL, "fa_IR": DirectionRTL, "az-Arab": DirectionRTL,
+		"en": DirectionLTR, "de-AT": DirectionLTR, "ku-Latn": DirectionLTR, "und": DirectionLTR, "": DirectionLTR,
+	} {
+		if got := LanguageDirection(code); got != want {
+			t.Errorf("LanguageDirection(%q) = %s, want %s", code, got, want)
+		}
+	}
+}
+
+func TestWrapDirection(t *testing.T) {
+	for html, want := range map[string]string{
+		"<p>مرحبا</p>":                      `<div dir="rtl"><p>مرحبا</p></div>`,
+		`<p dir="rtl">مرحبا</p>`:            `<p dir="rtl">مرحبا</p>`,
+		"":                                  "",
+		`<p>a <span dir="ltr">b</span></p>`: `<div dir="rtl"><p>a <span dir="ltr">b</span></p></div>`,
+	} {
+		if got := WrapDirection(html, DirectionRTL); got != want {
+			t.Errorf("WrapDirection(%q) = %q, want %q", html, got, want)
+		}
+	}
+}
+
+func TestQuestionSetTextDirection(t *testing.T) {
+	qs, err := NewQuestionSetBuilder().
+		SetTitle("اختبار").
+		SetIntroduction("<p>مرحبا</p>").
+		AddMultipleChoiceQuestion("<p>ما هي عاصمة فرنسا؟</p>", []Answer{CreateAnswer("باريس", true)}).
+		AddTrueFalseQuestion("<p>HTML لغة برمجة</p>", false, nil).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := qs.ValidateAll()
+	warnings := r.Warnings()
+	if len(warnings) != 1 || warnings[0].Path != "questions[1].params.question" || warnings[0].Code != CodeMixedDirection {
+		t.Errorf("Expected a mixed direction warning for the second question, got %v", r.Problems)
+	}
+
+	pkg, err := BuildQuestionSetPackage(qs, WithPackageLanguage("ar"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	built, err := pkg.QuestionSet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if built.Introduction != `<div dir="rtl"><p>مرحبا</p></div>` {
+		t.Errorf("Expected the introduction wrapped, got %q", built.Introduction)
+	}
+	var params struct {
+		Question string `json:"question"`
+		Answers  []struct {
+			Text string `json:"text"`
+		} `json:"answers"`
+	}
+	if err := built.Questions[0].DecodeParams(&params); err != nil {
+		t.Fatal(err)
+	}
+	if params.Question != `<div dir="rtl"><p>ما هي عاصمة فرنسا؟</p></div>` || !strings.Contains(params.Answers[0].Text, `dir="rtl"`) {
+		t.Errorf("Expected the question texts wrapped, got %+v", params)
+	}
+	if built.Questions[1].SubContentID != qs.Questions[1].SubContentID {
+		t.Error("Expected the questions kept")
+	}
+	if r := built.ValidateAll(); len(r.Warnings()) != 0 {
+		t.Errorf("Expected no warnings once wrapped, got %v", r.Problems)
+	}
+
+	if pkg, err := BuildQuestionSetPackage(qs, WithPackageLanguage("ar"), WithTextDirection(DirectionLTR)); err != nil {
+		t.Fatal(err)
+	} else if built, _ := pkg.QuestionSet(); built.Introduction != "<p>مرحبا</p>" {
+		t.Errorf("Expected no wrapping for DirectionLTR, got %q", built.Introduction)
+	}
+	if _, err := BuildQuestionSetPackage(qs, WithTextDirection("up")); !errors.Is(err, ErrInvalidDirection) {
+		t.Errorf("Expected ErrInvalidDirection, got %v", err)
+	}
+}
@@ -0,0 +1,188 @@
+package h5p
+
+import (
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/grokify/h5p-go/semantics"
+)
+
+// TextSection is the plain text of a part of content, such as a question
+// of a question set or a slide of a presentation, for full-text search
+// indexing.
+type TextSection struct {
+	// Path is the JSON path of the part in the params, e.g. "questions[2]"
+	// or "presentation.slides[0]", or "" for the texts outside parts such
+	// as the title and introduction.
+	Path string `json:"path"`
+	// Library is the library of the part, if it is sub-content.
+	Library string `json:"library,omitempty"`
+	// Text is the normalized plain text, one line per field.
+	Text string `json:"text"`
+}
+
+// ExtractText returns the plain text of the content of a package, split
+// into the parts of the content: each sub-content of the main library,
+// with its own sub-content, is a part. The texts are the values of the
+// text and html fields of the semantics, taken from the package libraries
+// or, for content types in package schemas, the built-in semantics. Labels
+// learners do not search for are left out: common fields, the groups of
+// settings and UI labels kept out of Stats word counts, and fields named
+// like button texts and labels, e.g. "retryButtonText". The first section
+// holds the title of h5p.json and the texts outside parts, and is left out
+// if empty.
+func ExtractText(pkg *H5PPackage) ([]TextSection, error) {
+	if pkg.Content == nil {
+		return nil, nil
+	}
+	e, sd, err := pkg.stringExtractor()
+	if err != nil {
+		def := pkg.PackageDefinition
+		if def == nil {
+			return nil, err
+		}
+		var ok bool
+		if sd, ok = builtinSemantics(def.MainLibrary); !ok {
+			return nil, err
+		}
+		e = semantics.StringExtractor{Library: def.MainLibrary}
+	}
+	e.Resolve = pkg.textResolver()
+	strs, err := e.Extract(sd, pkg.Content)
+	if err != nil {
+		return nil, err
+	}
+	params, err := toGenericJSON(pkg.Content)
+	if err != nil {
+		return nil, err
+	}
+	// QuestionSet params of this package keep the introduction outside
+	// the introPage group of the semantics, see QuestionSet.PlainText.
+	if name, _, _ := strings.Cut(e.Library, " "); name == questionSetLibrary {
+		if obj, ok := params.(map[string]any); ok {
+			if intro, ok := obj["introduction"].(string); ok && intro != "" {
+				strs = append(strs, semantics.TranslatableString{Key: "introduction", Text: intro, Library: e.Library, HTML: true})
+			}
+		}
+	}
+	var title string
+	if pkg.PackageDefinition != nil {
+		title = pkg.PackageDefinition.Title
+	}
+	return textSections(title, e.Library, strs, params), nil
+}
+
+// PlainText returns the plain text of qs: the title and introduction, and
+// a section per question, see ExtractText. Questions of content types
+// without semantics in package schemas have no text.
+func (qs *QuestionSet) PlainText() ([]TextSection, error) {
+	_, _, strs, err := qs.questionSetStrings()
+	if err != nil {
+		return nil, err
+	}
+	params, err := toGenericJSON(qs)
+	if err != nil {
+		return nil, err
+	}
+	return textSections(qs.Title, questionSetLibrary, strs, params), nil
+}
+
+// textResolver returns the semantics of a library string from the package
+// libraries or, for content types in package schemas, the built-in
+// semantics.
+func (pkg *H5PPackage) textResolver() func(string) (semantics.SemanticDefinition, bool) {
+	return func(library string) (semantics.SemanticDefinition, bool) {
+		if sd, ok := pkg.LibrarySemantics(library); ok {
+			return sd, true
+		}
+		return builtinSemantics(library)
+	}
+}
+
+// sectionIndexPattern matches the first list index of a string key.
+var sectionIndexPattern = regexp.MustCompile(`^[^\[]*\[\d+\]`)
+
+// textSections groups the searchable strings by part. A part is the first
+// list item in the key of a string of sub-content.
+func textSections(title, library string, strs []semantics.TranslatableString, params any) []TextSection {
+	var main []string
+	if t := plainText(title); t != "" {
+		main = append(main, t)
+	}
+	var sections []TextSection
+	index := map[string]int{}
+	for _, s := range strs {
+		if !searchableString(s) {
+			continue
+		}
+		text := plainText(s.Text)
+		if text == "" {
+			continue
+		}
+		part := sectionIndexPattern.FindString(s.Key)
+		if part == "" || s.Library == library {
+			// The title of h5p.json is often repeated in the params.
+			if !slices.Contains(main, text) {
+				main = append(main, text)
+			}
+			continue
+		}
+		i, ok := index[part]
+		if !ok {
+			i = len(sections)
+			index[part] = i
+			lib, _ := genericValueAt(params, part+".library").(string)
+			sections = append(sections, TextSection{Path: part, Library: lib})
+		}
+		if sections[i].Text != "" {
+			sections[i].Text += "\n"
+		}
+		sections[i].Text += text
+	}
+	if len(main) > 0 {
+		sections = append([]TextSection{{Text: strings.Join(main, "\n")}}, sections...)
+	}
+	return sections
+}
+
+// searchableString reports whether s is content text rather than a label.
+func searchableString(s semantics.TranslatableString) bool {
+	if s.Common {
+		return false
+	}
+	segments := strings.Split(s.Key, ".")
+	for _, seg := range segments {
+		name, _, _ := strings.Cut(seg, "[")
+		if nonTextKeys[name] {
+			return false
+		}
+	}
+	last, _, _ := strings.Cut(segments[len(segments)-1], "[")
+	return !strings.HasSuffix(last, "ButtonText") && !strings.HasSuffix(last, "Button") && !strings.HasSuffix(last, "Label")
+}
+
+// genericValueAt returns the value at a JSON path such as "a.b[0].c" in
+// generic JSON, or nil.
+func genericValueAt(v any, jsonPath string) any {
+	for _, seg := range strings.Split(jsonPath, ".") {
+		name, rest, _ := strings.Cut(seg, "[")
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return nil
+		}
+		v = obj[name]
+		for rest != "" {
+			idx, after, _ := strings.Cut(rest, "]")
+			i, err := strconv.Atoi(idx)
+			list, ok := v.([]any)
+			if err != nil || !ok || i < 0 || i >= len(list) {
+				return nil
+			}
+			v = list[i]
+			rest = strings.TrimPrefix(after, "[")
+		}
+	}
+	return v
+}
@@ -0,0 +1,76 @@
+package h5p
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestQuestionSetPlainText(t *testing.T) {
+	qs, err := NewQuestionSetBuilder().
+		SetTitle("Capitals").
+		SetIntroduction("<p>Test your <strong>geography</strong> &amp; more.</p>").
+		AddMultipleChoiceQuestion("<p>Capital of   France?</p>", []Answer{CreateAnswer("Paris", true), CreateAnswer("Lyon", false)}).
+		AddTrueFalseQuestion("Berlin is in Germany.", true, nil).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sections, err := qs.PlainText()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sections) != 3 {
+		t.Fatalf("Expected 3 sections, got %+v", sections)
+	}
+	if s := sections[0]; s.Path != "" || s.Text != "Capitals\nTest your geography & more." {
+		t.Errorf("Unexpected main section %+v", s)
+	}
+	if s := sections[1]; s.Path != "questions[0]" || !strings.HasPrefix(s.Library, "H5P.MultiChoice ") || s.Text != "Capital of France?\nParis\nLyon" {
+		t.Errorf("Unexpected question section %+v", s)
+	}
+	if s := sections[2]; s.Path != "questions[1]" || s.Text != "Berlin is in Germany." {
+		t.Errorf("Unexpected question section %+v", s)
+	}
+}
+
+func TestExtractText(t *testing.T) {
+	pkg := loadTestPackage(t)
+	sections, err := ExtractText(pkg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sections) != 1 {
+		t.Fatalf("Expected a single section, got %+v", sections)
+	}
+	text := sections[0].Text
+	for _, want := range []string{"What is the capital of France?", "Madrid", "Berlin is not the capital of France."} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected %q in %q", want, text)
+		}
+	}
+	if strings.Contains(text, "Check") || strings.Contains(text, "Retry") {
+		t.Errorf("Expected no UI labels in %q", text)
+	}
+}
+
+func TestExtractTextQuestionSet(t *testing.T) {
+	qs, err := NewQuestionSetBuilder().
+		SetTitle("Capitals").
+		SetIntroduction("<p>Test your geography.</p>").
+		AddTrueFalseQuestion("Berlin is in Germany.", true, nil).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg, err := BuildQuestionSetPackage(qs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sections, err := ExtractText(pkg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sections) != 2 || sections[0].Text != "Capitals\nTest your geography." || sections[1].Text != "Berlin is in Germany." {
+		t.Errorf("Unexpected sections %+v", sections)
+	}
+}
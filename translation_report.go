@@ -0,0 +1,157 @@
+package h5p
+
+import (
+	"errors"
+	"io/fs"
+
+	"github.com/grokify/h5p-go/semantics"
+)
+
+// TranslationReport lists the texts a translation lacks, by key: the
+// translation paths of MergeTranslation for library language files, the
+// keys of ExtractStrings for content.
+type TranslationReport struct {
+	// Language is the language of the translation, if known.
+	Language string `json:"language,omitempty"`
+	// Total is the number of texts to translate.
+	Total int `json:"total"`
+	// Missing lists the texts without a translation.
+	Missing []string `json:"missing,omitempty"`
+	// Untranslated lists the texts whose translation equals the source.
+	Untranslated []string `json:"untranslated,omitempty"`
+}
+
+// Translated returns the number of translated texts.
+func (r *TranslationReport) Translated() int {
+	return r.Total - len(r.Missing) - len(r.Untranslated)
+}
+
+// Complete reports whether every text is translated.
+func (r *TranslationReport) Complete() bool {
+	return r.Translated() == r.Total
+}
+
+// Percent returns the share of translated texts, 100 if there are none to
+// translate.
+func (r *TranslationReport) Percent() float64 {
+	if r.Total == 0 {
+		return 100
+	}
+	return 100 * float64(r.Translated()) / float64(r.Total)
+}
+
+func (r *TranslationReport) check(key, source, translation string) {
+	if source == "" {
+		return
+	}
+	r.Total++
+	switch translation {
+	case "":
+		r.Missing = append(r.Missing, key)
+	case source:
+		r.Untranslated = append(r.Untranslated, key)
+	}
+}
+
+// LibraryTranslationReport compares the language file of code against the
+// labels, descriptions and default texts of the library's semantics. A
+// library without the language file has all its texts missing.
+func LibraryTranslationReport(lib *Library, code string) (*TranslationReport, error) {
+	sd, err := lib.SemanticDefinition()
+	if err != nil {
+		return nil, err
+	}
+	t, err := lib.Translation(code)
+	if errors.Is(err, fs.ErrNotExist) {
+		t = &LibraryTranslation{}
+	} else if err != nil {
+		return nil, err
+	}
+	r := &TranslationReport{Language: code}
+	r.fields("", sd, t.Semantics)
+	return r, nil
+}
+
+func (r *TranslationReport) fields(path string, fields []semantics.Field, translations []TranslationField) {
+	for i := range fields {
+		var t *TranslationField
+		if i < len(translations) {
+			t = &translations[i]
+		}
+		p := fields[i].Name
+		if path != "" {
+			p = path + semantics.PathSeparator + p
+		}
+		r.field(p, &fields[i], t)
+	}
+}
+
+func (r *TranslationReport) field(path string, f *semantics.Field, t *TranslationField) {
+	if t == nil {
+		t = &TranslationField{}
+	}
+	key := func(attr string) string { return path + semantics.PathSeparator + attr }
+	r.check(key("label"), f.Label, t.Label)
+	r.check(key("description"), f.Description, t.Description)
+	r.check(key("placeholder"), f.Placeholder, t.Placeholder)
+	r.check(key("entity"), f.Entity, t.Entity)
+	if s, ok := f.Default.(string); ok && (f.Type == "text" || f.Type == "html") {
+		translated, _ := t.Default.(string)
+		r.check(key("default"), s, translated)
+	}
+	if f.Type == "select" {
+		for i, o := range f.GetSelectOptions() {
+			var translated string
+			if i < len(t.Options) {
+				translated = t.Options[i].Label
+			}
+			r.check(key("options")+semantics.PathSeparator+o.Value, o.Label, translated)
+		}
+	}
+	switch {
+	case f.Type == "group":
+		r.fields(path, f.Fields, t.Fields)
+	case f.Type == "list" && f.Field != nil:
+		item := t.Field
+		if item == nil {
+			item = &TranslationField{}
+		}
+		if f.Field.Type != "group" {
+			r.field(path+semantics.PathSeparator+f.Field.Name, f.Field, item)
+			return
+		}
+		// The fields of a group item are named as if the list were not
+		// there, like in FieldByPath.
+		group := *f.Field
+		group.Fields = nil
+		r.field(path+semantics.PathSeparator+f.Field.Name, &group, item)
+		r.fields(path, f.Field.Fields, item.Fields)
+	}
+}
+
+// ContentTranslationReport compares the strings of translated, a
+// translation of the content of pkg, against those of pkg, see
+// ExtractStrings. Strings of pkg that translated does not have are
+// missing, as are those it leaves empty.
+func (pkg *H5PPackage) ContentTranslationReport(translated *H5PPackage) (*TranslationReport, error) {
+	source, err := pkg.ExtractStrings()
+	if err != nil {
+		return nil, err
+	}
+	target, err := translated.ExtractStrings()
+	if err != nil {
+		return nil, err
+	}
+	texts := make(map[string]string, len(target))
+	for _, s := range target {
+		texts[s.Key] = s.Text
+	}
+	r := &TranslationReport{}
+	if translated.PackageDefinition != nil {
+		r.Language = translated.PackageDefinition.Language
+	}
+	for _, s := range source {
+		r.check(s.Key, s.Text, texts[s.Key])
+	}
+	return r, nil
+}
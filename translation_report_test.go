@@ -0,0 +1,73 @@
+package h5p
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLibraryTranslationReport(t *testing.T) {
+	lib := newTestLanguageLibrary(t)
+	r, err := LibraryTranslationReport(lib, "de")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Total != 16 || r.Translated() != 4 || len(r.Untranslated) != 0 || r.Complete() {
+		t.Errorf("Unexpected report %+v", r)
+	}
+
+	fr, err := LibraryTranslationReport(lib, "fr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fr.Total != 16 || len(fr.Missing) != 16 {
+		t.Errorf("Expected every text missing without a language file, got %+v", fr)
+	}
+
+	// The report keys are the paths MergeTranslation takes.
+	overrides := map[string]string{}
+	for _, key := range r.Missing {
+		overrides[key] = "übersetzt"
+	}
+	overrides["UI.checkAnswerButton.default"] = "Check"
+	if err := MergeTranslation(lib, "de", overrides); err != nil {
+		t.Fatal(err)
+	}
+	r, err = LibraryTranslationReport(lib, "de")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Missing) != 0 || !reflect.DeepEqual(r.Untranslated, []string{"UI.checkAnswerButton.default"}) || r.Percent() <= 90 {
+		t.Errorf("Unexpected report after merging %+v", r)
+	}
+}
+
+func TestContentTranslationReport(t *testing.T) {
+	pkg := loadTestPackage(t)
+	readTestJSON(t, "testdata/content.json", &pkg.Content)
+	readTestJSON(t, "schemas/multichoice_semantics.json", &pkg.GetLibrary("H5P.MultiChoice", 1, 16).Semantics)
+	strs, err := pkg.ExtractStrings()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	translations := map[string]string{}
+	for _, s := range strs[1:] {
+		translations[s.Key] = "[" + s.Text + "]"
+	}
+	translations[strs[len(strs)-1].Key] = strs[len(strs)-1].Text
+	params, err := pkg.ApplyStrings(translations)
+	if err != nil {
+		t.Fatal(err)
+	}
+	delete(params, strs[0].Key)
+	translated := deepCopy(pkg)
+	translated.SetContent(&Content{Params: params})
+
+	r, err := pkg.ContentTranslationReport(translated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Total != len(strs) || !reflect.DeepEqual(r.Missing, []string{strs[0].Key}) || !reflect.DeepEqual(r.Untranslated, []string{strs[len(strs)-1].Key}) {
+		t.Errorf("Unexpected report %+v", r)
+	}
+}
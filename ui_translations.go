@@ -0,0 +1,92 @@
+package h5p
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/grokify/h5p-go/schemas"
+)
+
+var ErrNoTranslations = errors.New("no UI translations for locale")
+
+//go:embed translations/*.json
+var translationFiles embed.FS
+
+// UITranslationSet holds the user interface texts of the question set and
+// of question content types for a locale.
+type UITranslationSet struct {
+	MultiChoice *schemas.UITranslations `json:"multiChoice,omitempty"`
+	TrueFalse   *schemas.TrueFalseL10n  `json:"trueFalse,omitempty"`
+	Texts       *QuestionSetTexts       `json:"texts,omitempty"`
+}
+
+// DefaultUITranslations returns the translations shipped for locale, e.g.
+// "de" or "pt-BR", falling back from a regional locale to its language.
+// See UITranslationLocales for the locales available.
+func DefaultUITranslations(locale string) (*UITranslationSet, error) {
+	locale = strings.ToLower(strings.ReplaceAll(locale, "_", "-"))
+	for _, name := range []string{locale, strings.SplitN(locale, "-", 2)[0]} {
+		data, err := translationFiles.ReadFile("translations/" + name + ".json")
+		if err != nil {
+			continue
+		}
+		var t UITranslationSet
+		if err := json.Unmarshal(data, &t); err != nil {
+			return nil, fmt.Errorf("translations %s: %w", name, err)
+		}
+		return &t, nil
+	}
+	return nil, fmt.Errorf("%w: %q", ErrNoTranslations, locale)
+}
+
+// UITranslationLocales returns the sorted locales DefaultUITranslations has
+// translations for.
+func UITranslationLocales() []string {
+	entries, _ := translationFiles.ReadDir("translations")
+	locales := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		locales = append(locales, strings.TrimSuffix(entry.Name(), ".json"))
+	}
+	return locales
+}
+
+// Apply sets the texts of the question set and its questions that have
+// none, leaving texts set before as they are. Generic params get the
+// texts as their UI or l10n field.
+func (t *UITranslationSet) Apply(qs *QuestionSet) {
+	if qs.Texts == nil && t.Texts != nil {
+		texts := *t.Texts
+		qs.Texts = &texts
+	}
+	for i := range qs.Questions {
+		q := &qs.Questions[i]
+		switch p := q.Params.(type) {
+		case *schemas.MultiChoiceParams:
+			if p.UI == nil && t.MultiChoice != nil {
+				ui := *t.MultiChoice
+				p.UI = &ui
+			}
+		case *schemas.TrueFalseParams:
+			if p.L10n == nil && t.TrueFalse != nil {
+				l10n := *t.TrueFalse
+				p.L10n = &l10n
+			}
+		case map[string]any:
+			switch q.MachineName() {
+			case "H5P.MultiChoice":
+				if _, ok := p["UI"]; !ok && t.MultiChoice != nil {
+					ui := *t.MultiChoice
+					p["UI"] = &ui
+				}
+			case "H5P.TrueFalse":
+				if _, ok := p["l10n"]; !ok && t.TrueFalse != nil {
+					l10n := *t.TrueFalse
+					p["l10n"] = &l10n
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package h5p
+
+import (
+	"errors"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/grokify/h5p-go/schemas"
+)
+
+func TestDefaultUITranslations(t *testing.T) {
+	locales := UITranslationLocales()
+	for _, want := range []string{"ar", "de", "en", "es", "fr", "pt", "zh"} {
+		if !slices.Contains(locales, want) {
+			t.Errorf("Expected translations for %s, got %v", want, locales)
+		}
+	}
+	// Every locale translates every text.
+	for _, locale := range locales {
+		set, err := DefaultUITranslations(locale)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, v := range []any{set.MultiChoice, set.TrueFalse, set.Texts} {
+			rv := reflect.ValueOf(v).Elem()
+			for i := range rv.NumField() {
+				if rv.Field(i).Kind() == reflect.String && rv.Field(i).String() == "" {
+					t.Errorf("%s: %s.%s is not translated", locale, rv.Type().Name(), rv.Type().Field(i).Name)
+				}
+			}
+		}
+	}
+
+	set, err := DefaultUITranslations("pt_BR")
+	if err != nil || set.Texts.NextButton != "Próxima pergunta" {
+		t.Errorf("Expected pt_BR to fall back to pt, got %v", err)
+	}
+	if _, err := DefaultUITranslations("und"); !errors.Is(err, ErrNoTranslations) {
+		t.Errorf("Expected ErrNoTranslations, got %v", err)
+	}
+}
+
+func TestBuildQuestionSetPackageTranslations(t *testing.T) {
+	qs, err := NewQuestionSetBuilder().
+		SetTitle("Quiz").
+		SetTexts(&QuestionSetTexts{FinishButton: "Fertig"}).
+		AddTrueFalseQuestion("Paris est en France.", true, nil).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg, err := BuildQuestionSetPackage(qs, WithPackageLanguage("fr"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	built, err := pkg.QuestionSet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if built.Texts == nil || built.Texts.FinishButton != "Fertig" {
+		t.Errorf("Expected texts set before kept, got %+v", built.Texts)
+	}
+	var params struct {
+		L10n map[string]string `json:"l10n"`
+	}
+	if err := built.Questions[0].DecodeParams(&params); err != nil {
+		t.Fatal(err)
+	}
+	if params.L10n["trueText"] != "Vrai" {
+		t.Errorf("Expected French labels, got %v", params.L10n)
+	}
+	if p, ok := qs.Questions[0].Params.(*schemas.TrueFalseParams); !ok || p.L10n != nil || qs.Texts.NextButton != "" {
+		t.Error("Expected the question set left unchanged")
+	}
+}
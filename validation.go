@@ -0,0 +1,41 @@
+package h5p
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/grokify/h5p-go/schemas"
+)
+
+// Validation types are shared with the schemas package so typed params can
+// report problems in the same form.
+type (
+	Severity         = schemas.Severity
+	ValidationError  = schemas.ValidationError
+	ValidationResult = schemas.ValidationResult
+)
+
+const (
+	SeverityError   = schemas.SeverityError
+	SeverityWarning = schemas.SeverityWarning
+)
+
+// multiChoiceParams returns the params of a MultiChoice question, decoding
+// generic params as loaded from content.json. ok is false for other
+// libraries.
+func (q *Question) multiChoiceParams() (params *schemas.MultiChoiceParams, ok bool, err error) {
+	if p, isTyped := q.Params.(*schemas.MultiChoiceParams); isTyped {
+		if p == nil {
+			return nil, true, errors.New("params are missing")
+		}
+		return p, true, nil
+	}
+	if !strings.HasPrefix(q.Library, "H5P.MultiChoice ") {
+		return nil, false, nil
+	}
+	params = &schemas.MultiChoiceParams{}
+	if err := q.DecodeParams(params); err != nil {
+		return nil, true, err
+	}
+	return params, true, nil
+}
@@ -0,0 +1,149 @@
+package h5p
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/grokify/h5p-go/schemas"
+)
+
+// Quality rules whose severity is set by a ValidationProfile. The rule name
+// is also the code of the problems it reports.
+const (
+	// RuleFeedbackCoverage reports scores from 0 to 100 that no overall
+	// feedback range covers.
+	RuleFeedbackCoverage = "feedback_coverage"
+	// RuleEmptyIntroduction reports an intro page without introduction text.
+	RuleEmptyIntroduction = "empty_introduction"
+	// RuleDeprecatedLibrary reports questions using a library version older
+	// than listed in MinimumLibraryVersions.
+	RuleDeprecatedLibrary = "deprecated_library"
+	// RuleDuplicateAnswer reports answers of a MultiChoice or
+	// SingleChoiceSet question that have the same text once HTML and
+	// whitespace are normalized.
+	RuleDuplicateAnswer = "duplicate_answer"
+	// RuleConflictingAnswers reports "single" type MultiChoice questions
+	// that mark more than one answer correct.
+	RuleConflictingAnswers = "conflicting_answers"
+)
+
+// MinimumLibraryVersions lists the oldest non-deprecated major.minor
+// version of common content type libraries, used by RuleDeprecatedLibrary.
+var MinimumLibraryVersions = map[string]LibraryVersion{
+	"H5P.Blanks":       {Major: 1, Minor: 14},
+	"H5P.DragText":     {Major: 1, Minor: 10},
+	"H5P.MarkTheWords": {Major: 1, Minor: 11},
+	"H5P.MultiChoice":  {Major: 1, Minor: 16},
+	"H5P.QuestionSet":  {Major: 1, Minor: 20},
+	"H5P.TrueFalse":    {Major: 1, Minor: 8},
+}
+
+// ValidationProfile sets the severity of each quality rule. Rules missing
+// from Rules are ignored. Structural problems are always errors.
+type ValidationProfile struct {
+	Name  string
+	Rules map[string]Severity
+}
+
+// StrictProfile treats every quality rule as an error, e.g. for CI
+// pipelines gating publication.
+func StrictProfile() ValidationProfile {
+	return ValidationProfile{Name: "strict", Rules: map[string]Severity{
+		RuleFeedbackCoverage:   SeverityError,
+		RuleEmptyIntroduction:  SeverityError,
+		RuleDeprecatedLibrary:  SeverityError,
+		RuleDuplicateAnswer:    SeverityError,
+		RuleConflictingAnswers: SeverityError,
+	}}
+}
+
+// StandardProfile reports every quality rule as a warning.
+func StandardProfile() ValidationProfile {
+	return ValidationProfile{Name: "standard", Rules: map[string]Severity{
+		RuleFeedbackCoverage:   SeverityWarning,
+		RuleEmptyIntroduction:  SeverityWarning,
+		RuleDeprecatedLibrary:  SeverityWarning,
+		RuleDuplicateAnswer:    SeverityWarning,
+		RuleConflictingAnswers: SeverityWarning,
+	}}
+}
+
+// LenientProfile ignores all quality rules, e.g. for interactive editing.
+func LenientProfile() ValidationProfile {
+	return ValidationProfile{Name: "lenient"}
+}
+
+// check records a problem for rule at the severity the profile assigns.
+func (p ValidationProfile) check(r *ValidationResult, rule, path, format string, args ...any) {
+	switch p.Rules[rule] {
+	case SeverityError:
+		r.AddError(path, rule, format, args...)
+	case SeverityWarning:
+		r.AddWarning(path, rule, format, args...)
+	}
+}
+
+// ValidateWithProfile is ValidateAll plus the quality rules enabled by
+// profile.
+func (qs *QuestionSet) ValidateWithProfile(profile ValidationProfile) *ValidationResult {
+	r := qs.ValidateAll()
+
+	if qs.ShowIntroPage && strings.TrimSpace(qs.Introduction) == "" {
+		profile.check(r, RuleEmptyIntroduction, "introduction", "intro page is shown but introduction is empty")
+	}
+
+	if len(qs.OverallFeedback) == 0 {
+		profile.check(r, RuleFeedbackCoverage, "overallFeedback", "no overall feedback is defined")
+	} else if gaps := feedbackGaps(qs.OverallFeedback); len(gaps) > 0 {
+		profile.check(r, RuleFeedbackCoverage, "overallFeedback", "scores %s have no feedback", strings.Join(gaps, ", "))
+	}
+
+	for i := range qs.Questions {
+		q := &qs.Questions[i]
+		profile.checkAnswers(r, schemas.IndexPath("questions", i), q)
+		dep, err := ParseLibraryString(q.Library)
+		if err != nil {
+			continue
+		}
+		if minVersion, ok := MinimumLibraryVersions[dep.MachineName]; ok &&
+			(LibraryVersion{Major: dep.MajorVersion, Minor: dep.MinorVersion}).Compare(minVersion) < 0 {
+			profile.check(r, RuleDeprecatedLibrary, schemas.JoinPath(schemas.IndexPath("questions", i), "library"),
+				"%s is deprecated, use %s %d.%d or later", q.Library, dep.MachineName, minVersion.Major, minVersion.Minor)
+		}
+	}
+
+	return r
+}
+
+// feedbackGaps returns the score ranges from 0 to 100 not covered by any
+// feedback range, e.g. "51-79".
+func feedbackGaps(ranges []FeedbackRange) []string {
+	sorted := append([]FeedbackRange(nil), ranges...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].From < sorted[j].From })
+
+	var gaps []string
+	addGap := func(from, to int) {
+		if from == to {
+			gaps = append(gaps, fmt.Sprint(from))
+		} else {
+			gaps = append(gaps, fmt.Sprintf("%d-%d", from, to))
+		}
+	}
+	next := 0
+	for _, fr := range sorted {
+		if next > 100 {
+			break
+		}
+		if fr.From > next {
+			addGap(next, min(fr.From-1, 100))
+		}
+		if fr.To+1 > next {
+			next = fr.To + 1
+		}
+	}
+	if next <= 100 {
+		addGap(next, 100)
+	}
+	return gaps
+}
@@ -0,0 +1,102 @@
+package h5p
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/grokify/h5p-go/schemas"
+)
+
+func TestValidateWithProfile(t *testing.T) {
+	params := &schemas.MultiChoiceParams{
+		Question: "Capital of France?",
+		Answers:  []schemas.AnswerOption{{Text: "Paris", Correct: true}},
+	}
+	qs := &QuestionSet{
+		ShowIntroPage:   true,
+		Questions:       []Question{{Library: "H5P.MultiChoice 1.14", Params: params}},
+		OverallFeedback: []FeedbackRange{{From: 0, To: 50}, {From: 80, To: 99}},
+	}
+
+	rules := func(problems []ValidationError) map[string]bool {
+		m := map[string]bool{}
+		for _, p := range problems {
+			m[p.Code] = true
+		}
+		return m
+	}
+	all := map[string]bool{RuleFeedbackCoverage: true, RuleEmptyIntroduction: true, RuleDeprecatedLibrary: true}
+
+	strict := qs.ValidateWithProfile(StrictProfile())
+	if got := rules(strict.Errors()); !reflect.DeepEqual(got, all) {
+		t.Errorf("Expected all rules as errors with strict profile, got %v", strict.Problems)
+	}
+
+	standard := qs.ValidateWithProfile(StandardProfile())
+	if !standard.Valid() {
+		t.Errorf("Expected standard profile to only warn, got %v", standard.Errors())
+	}
+	if got := rules(standard.Warnings()); !reflect.DeepEqual(got, all) {
+		t.Errorf("Expected all rules as warnings with standard profile, got %v", standard.Problems)
+	}
+
+	if lenient := qs.ValidateWithProfile(LenientProfile()); len(lenient.Problems) != 0 {
+		t.Errorf("Expected no problems with lenient profile, got %v", lenient.Problems)
+	}
+}
+
+func TestFeedbackGaps(t *testing.T) {
+	var gapTests = []struct {
+		ranges []FeedbackRange
+		want   []string
+	}{
+		{[]FeedbackRange{{From: 0, To: 100}}, nil},
+		{[]FeedbackRange{{From: 51, To: 100}, {From: 0, To: 50}}, nil},
+		{[]FeedbackRange{{From: 0, To: 50}, {From: 80, To: 99}}, []string{"51-79", "100"}},
+		{[]FeedbackRange{{From: 10, To: 60}, {From: 20, To: 30}}, []string{"0-9", "61-100"}},
+	}
+
+	for _, tt := range gapTests {
+		if got := feedbackGaps(tt.ranges); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("feedbackGaps(%v) = %v, want %v", tt.ranges, got, tt.want)
+		}
+	}
+}
+
+func TestValidateWithProfileAnswerRules(t *testing.T) {
+	qs := &QuestionSet{
+		OverallFeedback: []FeedbackRange{{From: 0, To: 100}},
+		Questions: []Question{
+			{Library: "H5P.MultiChoice 1.16", Params: &schemas.MultiChoiceParams{
+				Question: "Capital of France?",
+				Answers: []schemas.AnswerOption{
+					{Text: "<p>Paris</p>", Correct: true},
+					{Text: "  paris&nbsp;", Correct: true},
+					{Text: "Lyon"},
+				},
+				Behaviour: &schemas.Behaviour{Type: "single"},
+			}},
+			{Library: "H5P.SingleChoiceSet 1.11", Params: map[string]any{
+				"choices": []any{
+					map[string]any{"question": "2+2?", "answers": []any{"<p>4</p>", "<p>5</p>"}},
+					map[string]any{"question": "3+3?", "answers": []any{"<p>6</p>", "<strong>6</strong>"}},
+				},
+			}},
+		},
+	}
+
+	r := qs.ValidateWithProfile(StrictProfile())
+	want := map[string]string{
+		"questions[0].params.answers[1]":            RuleDuplicateAnswer,
+		"questions[0].params.behaviour.type":        RuleConflictingAnswers,
+		"questions[1].params.choices[1].answers[1]": RuleDuplicateAnswer,
+	}
+	if len(r.Problems) != len(want) {
+		t.Errorf("Expected %d problems, got %v", len(want), r.Problems)
+	}
+	for _, p := range r.Problems {
+		if want[p.Path] != p.Code {
+			t.Errorf("Unexpected problem %+v", p)
+		}
+	}
+}
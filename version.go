@@ -0,0 +1,117 @@
+package h5p
+
+import (
+	"cmp"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// LibraryVersion is a library version in major.minor.patch form.
+type LibraryVersion struct {
+	Major int `json:"majorVersion"`
+	Minor int `json:"minorVersion"`
+	Patch int `json:"patchVersion"`
+}
+
+// ParseLibraryVersion parses "Major.Minor" or "Major.Minor.Patch".
+func ParseLibraryVersion(s string) (LibraryVersion, error) {
+	parts := strings.Split(s, ".")
+	if len(parts) < 2 || len(parts) > 3 {
+		return LibraryVersion{}, fmt.Errorf("library version %q must have the form Major.Minor[.Patch]", s)
+	}
+	nums := make([]int, 3)
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return LibraryVersion{}, fmt.Errorf("invalid library version %q", s)
+		}
+		nums[i] = n
+	}
+	return LibraryVersion{Major: nums[0], Minor: nums[1], Patch: nums[2]}, nil
+}
+
+// String returns the version in "Major.Minor.Patch" form.
+func (v LibraryVersion) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// Compare returns -1, 0 or +1 depending on whether v is lower than, equal to
+// or higher than other.
+func (v LibraryVersion) Compare(other LibraryVersion) int {
+	if c := cmp.Compare(v.Major, other.Major); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(v.Minor, other.Minor); c != 0 {
+		return c
+	}
+	return cmp.Compare(v.Patch, other.Patch)
+}
+
+// LatestLibraryString returns the library string of the newest known
+// version of machineName listed in LatestLibraryVersions, e.g.
+// "H5P.MultiChoice 1.16". ok is false for unknown libraries.
+func LatestLibraryString(machineName string) (s string, ok bool) {
+	v, ok := LatestLibraryVersions[machineName]
+	if !ok {
+		return "", false
+	}
+	return fmt.Sprintf("%s %d.%d", machineName, v.Major, v.Minor), true
+}
+
+// Version returns the library version declared in library.json.
+func (ld *LibraryDefinition) Version() LibraryVersion {
+	return LibraryVersion{Major: ld.MajorVersion, Minor: ld.MinorVersion, Patch: ld.PatchVersion}
+}
+
+// Satisfies reports whether def can be used for dep: the machine names and
+// major versions match and def has at least the required minor version.
+// H5P keeps minor versions backwards compatible, so a higher minor version
+// can stand in for a lower one.
+func Satisfies(dep LibraryDependency, def LibraryDefinition) bool {
+	return def.MachineName == dep.MachineName &&
+		def.MajorVersion == dep.MajorVersion &&
+		def.MinorVersion >= dep.MinorVersion
+}
+
+// HighestCompatible returns the highest version among defs that satisfies
+// dep, preferring the highest minor and then patch version.
+func HighestCompatible(dep LibraryDependency, defs []LibraryDefinition) (LibraryDefinition, bool) {
+	var best LibraryDefinition
+	found := false
+	for _, def := range defs {
+		if Satisfies(dep, def) && (!found || def.Version().Compare(best.Version()) > 0) {
+			best, found = def, true
+		}
+	}
+	return best, found
+}
+
+// FindCompatibleLibrary returns the library in the package with the highest
+// version that satisfies dep, or nil.
+func (pkg *H5PPackage) FindCompatibleLibrary(dep LibraryDependency) *Library {
+	var best *Library
+	var bestVersion LibraryVersion
+	for _, lib := range pkg.Libraries {
+		def, ok := lib.versionDefinition()
+		if !ok || !Satisfies(dep, def) {
+			continue
+		}
+		if best == nil || def.Version().Compare(bestVersion) > 0 {
+			best, bestVersion = lib, def.Version()
+		}
+	}
+	return best
+}
+
+// versionDefinition returns the identity of the library as a definition
+// holding only the machine name and version, for use with Satisfies.
+func (lib *Library) versionDefinition() (LibraryDefinition, bool) {
+	name, major, minor, ok := lib.identity()
+	return LibraryDefinition{
+		MachineName:  name,
+		MajorVersion: major,
+		MinorVersion: minor,
+		PatchVersion: lib.patchVersion(),
+	}, ok
+}
@@ -0,0 +1,80 @@
+package h5p
+
+import "testing"
+
+func TestParseLibraryVersion(t *testing.T) {
+	var versionTests = []struct {
+		in   string
+		want LibraryVersion
+		ok   bool
+	}{
+		{"1.16", LibraryVersion{1, 16, 0}, true},
+		{"1.16.3", LibraryVersion{1, 16, 3}, true},
+		{"1", LibraryVersion{}, false},
+		{"1.x", LibraryVersion{}, false},
+		{"1.2.3.4", LibraryVersion{}, false},
+		{"-1.2", LibraryVersion{}, false},
+	}
+
+	for _, tt := range versionTests {
+		got, err := ParseLibraryVersion(tt.in)
+		if (err == nil) != tt.ok {
+			t.Errorf("ParseLibraryVersion(%q) error = %v, want ok=%v", tt.in, err, tt.ok)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseLibraryVersion(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+
+	if c := (LibraryVersion{1, 16, 3}).Compare(LibraryVersion{1, 9, 10}); c != 1 {
+		t.Errorf("Expected 1.16.3 > 1.9.10, got %d", c)
+	}
+}
+
+func TestSatisfiesAndHighestCompatible(t *testing.T) {
+	defs := []LibraryDefinition{
+		{MachineName: "H5P.MultiChoice", MajorVersion: 1, MinorVersion: 14, PatchVersion: 9},
+		{MachineName: "H5P.MultiChoice", MajorVersion: 1, MinorVersion: 16, PatchVersion: 2},
+		{MachineName: "H5P.MultiChoice", MajorVersion: 1, MinorVersion: 16, PatchVersion: 4},
+		{MachineName: "H5P.MultiChoice", MajorVersion: 2, MinorVersion: 0},
+		{MachineName: "H5P.TrueFalse", MajorVersion: 1, MinorVersion: 20},
+	}
+
+	req := dep("H5P.MultiChoice", 1, 15)
+	if Satisfies(req, defs[0]) {
+		t.Error("Lower minor version should not satisfy dependency")
+	}
+	if !Satisfies(req, defs[1]) {
+		t.Error("Higher minor version should satisfy dependency")
+	}
+	if Satisfies(req, defs[3]) || Satisfies(req, defs[4]) {
+		t.Error("Other major versions and libraries should not satisfy dependency")
+	}
+
+	best, ok := HighestCompatible(req, defs)
+	if !ok || best.Version() != (LibraryVersion{1, 16, 4}) {
+		t.Errorf("Expected 1.16.4 as highest compatible, got %v (ok=%v)", best.Version(), ok)
+	}
+	if _, ok := HighestCompatible(dep("H5P.MultiChoice", 1, 17), defs); ok {
+		t.Error("Expected no compatible version for 1.17")
+	}
+}
+
+func TestResolveDependenciesUsesCompatibleMinor(t *testing.T) {
+	pkg := NewH5PPackage()
+	pkg.SetPackageDefinition(&PackageDefinition{
+		PreloadedDependencies: []LibraryDependency{dep("H5P.A", 1, 0)},
+	})
+	pkg.AddLibrary(newTestLibrary("H5P.A", 1, 0, dep("H5P.B", 1, 2)))
+	pkg.AddLibrary(newTestLibrary("H5P.B", 1, 3))
+	pkg.AddLibrary(newTestLibrary("H5P.B", 1, 5))
+
+	order, err := pkg.ResolveDependencies()
+	if err != nil {
+		t.Fatalf("Failed to resolve dependencies: %v", err)
+	}
+	if len(order) != 2 || order[0].MachineName != "H5P.B-1.5" {
+		t.Errorf("Expected H5P.B-1.5 to satisfy H5P.B 1.2, got %v", order)
+	}
+}
@@ -0,0 +1,168 @@
+package h5p
+
+import (
+	"archive/zip"
+	"compress/flate"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+)
+
+// DefaultStoreExtensions lists already-compressed media formats that gain
+// nothing from deflate and are cheaper to store uncompressed.
+var DefaultStoreExtensions = []string{
+	".gif", ".jpeg", ".jpg", ".png", ".webp",
+	".m4a", ".mp3", ".ogg", ".wav", ".weba",
+	".mp4", ".ogv", ".webm",
+	".woff", ".woff2", ".zip",
+}
+
+// ErrFileExtension is returned for package files with an extension that is
+// not allowed.
+var ErrFileExtension = errors.New("file extension not allowed")
+
+// WriteOptions controls how package archives are compressed.
+type WriteOptions struct {
+	// CompressionLevel is the compress/flate level used for deflated
+	// entries, from flate.BestSpeed to flate.BestCompression. Zero uses
+	// flate.DefaultCompression.
+	CompressionLevel int
+
+	// StoreExtensions lists file extensions, including the dot, written
+	// without compression. Matching is case-insensitive.
+	StoreExtensions []string
+
+	// StoreAll writes every entry without compression.
+	StoreAll bool
+
+	// AllowedExtensions, if set, lists the lowercase file extensions,
+	// without the dot, allowed in the package, e.g.
+	// AllowedContentFileExtensions; library folders also allow
+	// AllowedLibraryFileExtensions. A package with other files fails to
+	// write with ErrFileExtension before any entry is written, rather than
+	// when a platform refuses to import it. WriteTo and CreateZipFile
+	// always check AllowedContentFileExtensions; options without
+	// AllowedExtensions write any file.
+	AllowedExtensions []string
+
+	// DisallowedFile, if set, is called with each file outside
+	// AllowedExtensions instead of failing the write, e.g. to log a
+	// warning.
+	DisallowedFile func(name string)
+
+	// Progress, if set, is called after each entry is written.
+	Progress ProgressFunc
+}
+
+// DefaultWriteOptions deflates text files at the default level, stores
+// media listed in DefaultStoreExtensions and rejects files outside
+// AllowedContentFileExtensions.
+func DefaultWriteOptions() WriteOptions {
+	return WriteOptions{StoreExtensions: DefaultStoreExtensions, AllowedExtensions: AllowedContentFileExtensions}
+}
+
+// CreateZipFileWithOptions is CreateZipFile with configurable compression.
+func (pkg *H5PPackage) CreateZipFileWithOptions(outputPath string, opts WriteOptions) error {
+	return pkg.CreateZipFileContext(context.Background(), outputPath, opts)
+}
+
+// CreateZipFileContext is CreateZipFileWithOptions that stops writing when
+// ctx is cancelled. The partially written file is left in place.
+func (pkg *H5PPackage) CreateZipFileContext(ctx context.Context, outputPath string, opts WriteOptions) error {
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return fmt.Errorf("failed to create zip file: %w", err)
+	}
+
+	if _, err := pkg.WriteToContext(ctx, file, opts); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// WriteToWithOptions is WriteTo with configurable compression.
+func (pkg *H5PPackage) WriteToWithOptions(w io.Writer, opts WriteOptions) (int64, error) {
+	return pkg.WriteToContext(context.Background(), w, opts)
+}
+
+// WriteToContext is WriteToWithOptions that stops writing when ctx is
+// cancelled, returning the context error.
+func (pkg *H5PPackage) WriteToContext(ctx context.Context, w io.Writer, opts WriteOptions) (int64, error) {
+	if opts.AllowedExtensions != nil {
+		if names := pkg.DisallowedFiles(opts.AllowedExtensions); len(names) > 0 {
+			if opts.DisallowedFile == nil {
+				return 0, fmt.Errorf("%w: %s", ErrFileExtension, strings.Join(names, ", "))
+			}
+			for _, name := range names {
+				opts.DisallowedFile(name)
+			}
+		}
+	}
+
+	cw := &countingWriter{w: w}
+	zipWriter := zip.NewWriter(cw)
+	if opts.CompressionLevel != 0 {
+		level := opts.CompressionLevel
+		if level < flate.HuffmanOnly || level > flate.BestCompression {
+			return 0, fmt.Errorf("invalid compression level: %d", level)
+		}
+		zipWriter.RegisterCompressor(zip.Deflate, func(out io.Writer) (io.WriteCloser, error) {
+			return flate.NewWriter(out, level)
+		})
+	}
+
+	if err := pkg.writeToZip(ctx, zipWriter, opts); err != nil {
+		return cw.n, fmt.Errorf("failed to write package to zip: %w", err)
+	}
+	if err := zipWriter.Close(); err != nil {
+		return cw.n, fmt.Errorf("failed to finalize zip: %w", err)
+	}
+
+	return cw.n, nil
+}
+
+// method returns the zip compression method for the named entry.
+func (opts WriteOptions) method(name string) uint16 {
+	if opts.StoreAll {
+		return zip.Store
+	}
+	ext := strings.ToLower(path.Ext(name))
+	for _, e := range opts.StoreExtensions {
+		if strings.ToLower(e) == ext {
+			return zip.Store
+		}
+	}
+	return zip.Deflate
+}
+
+// DisallowedFiles returns the package paths of the content, library and
+// extra files whose extensions are not in allowed, which lists lowercase
+// extensions without the dot. Library files may also have
+// AllowedLibraryFileExtensions.
+func (pkg *H5PPackage) DisallowedFiles(allowed []string) []string {
+	var names []string
+	for _, name := range pkg.ContentFileNames() {
+		if !allowedExtension(name, allowed) {
+			names = append(names, ContentDir+"/"+name)
+		}
+	}
+	for _, lib := range pkg.Libraries {
+		for _, name := range lib.FileNames() {
+			if !allowedExtension(name, allowed, AllowedLibraryFileExtensions) {
+				names = append(names, lib.MachineName+"/"+name)
+			}
+		}
+	}
+	for _, name := range sortedFileNames(pkg.ExtraFiles) {
+		if !allowedExtension(name, allowed) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,103 @@
+package h5p
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/flate"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWriteToWithOptions(t *testing.T) {
+	pkg := loadTestPackage(t)
+	if err := pkg.AddContentAsset("images/photo.png", bytes.Repeat([]byte("a"), 4096), ""); err != nil {
+		t.Fatalf("Failed to add asset: %v", err)
+	}
+
+	methods := func(opts WriteOptions) map[string]uint16 {
+		var buf bytes.Buffer
+		if _, err := pkg.WriteToWithOptions(&buf, opts); err != nil {
+			t.Fatalf("Failed to write package: %v", err)
+		}
+		zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		if err != nil {
+			t.Fatalf("Failed to read package: %v", err)
+		}
+		m := map[string]uint16{}
+		for _, f := range zr.File {
+			m[f.Name] = f.Method
+		}
+		return m
+	}
+
+	m := methods(DefaultWriteOptions())
+	if m["content/images/photo.png"] != zip.Store {
+		t.Error("Expected PNG to be stored uncompressed")
+	}
+	if m["h5p.json"] != zip.Deflate {
+		t.Error("Expected h5p.json to be deflated")
+	}
+
+	for name, method := range methods(WriteOptions{StoreAll: true}) {
+		if method != zip.Store {
+			t.Errorf("Expected %s to be stored with StoreAll", name)
+		}
+	}
+
+	m = methods(WriteOptions{CompressionLevel: flate.BestCompression, StoreExtensions: []string{".JSON"}})
+	for name, method := range m {
+		if strings.HasSuffix(name, ".json") && method != zip.Store {
+			t.Errorf("Expected %s to be stored", name)
+		}
+	}
+	if m["content/images/photo.png"] != zip.Deflate {
+		t.Error("Expected PNG to be deflated when not listed")
+	}
+}
+
+func TestWriteToWithOptionsInvalidLevel(t *testing.T) {
+	pkg := NewH5PPackage()
+	var buf bytes.Buffer
+	if _, err := pkg.WriteToWithOptions(&buf, WriteOptions{CompressionLevel: 42}); err == nil {
+		t.Error("Expected error for invalid compression level")
+	}
+}
+
+func TestWriteAllowedExtensions(t *testing.T) {
+	pkg := NewH5PPackage()
+	pkg.SetPackageDefinition(&PackageDefinition{Title: "Files", MainLibrary: "H5P.Image"})
+	if err := pkg.AddContentAsset("images/a.PNG", []byte("png"), ""); err != nil {
+		t.Fatal(err)
+	}
+	// AddContentAsset refuses the file, but content files can also be set
+	// directly.
+	pkg.ContentFiles["files/run.exe"] = &ContentFile{Data: []byte("MZ")}
+	pkg.AddLibrary(&Library{MachineName: "H5P.Image-1.1", Files: map[string][]byte{"image.js": []byte("x"), "upgrades.php": []byte("<?php")}})
+
+	disallowed := pkg.DisallowedFiles(AllowedContentFileExtensions)
+	if !reflect.DeepEqual(disallowed, []string{"content/files/run.exe", "H5P.Image-1.1/upgrades.php"}) {
+		t.Errorf("Unexpected disallowed files %v", disallowed)
+	}
+
+	var buf bytes.Buffer
+	n, err := pkg.WriteToWithOptions(&buf, DefaultWriteOptions())
+	if !errors.Is(err, ErrFileExtension) || !strings.Contains(err.Error(), "run.exe") || n != 0 || buf.Len() != 0 {
+		t.Errorf("Expected the write to fail before writing, got %d bytes: %v", n, err)
+	}
+
+	var warned []string
+	opts := DefaultWriteOptions()
+	opts.DisallowedFile = func(name string) { warned = append(warned, name) }
+	if _, err := pkg.WriteToWithOptions(&buf, opts); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(warned, disallowed) {
+		t.Errorf("Expected warnings for %v, got %v", disallowed, warned)
+	}
+	if _, err := pkg.WriteToWithOptions(io.Discard, WriteOptions{}); err != nil {
+		t.Errorf("Expected no check without AllowedExtensions, got %v", err)
+	}
+}
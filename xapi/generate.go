@@ -0,0 +1,331 @@
+package xapi
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+
+	h5p "github.com/grokify/h5p-go"
+	"github.com/grokify/h5p-go/schemas"
+)
+
+// Machine names of the libraries with interaction definitions.
+const (
+	MultiChoiceLibrary = "H5P.MultiChoice"
+	TrueFalseLibrary   = "H5P.TrueFalse"
+	BlanksLibrary      = "H5P.Blanks"
+	DragTextLibrary    = "H5P.DragText"
+	EssayLibrary       = "H5P.Essay"
+	QuestionSetLibrary = "H5P.QuestionSet"
+
+	// BlankPlaceholder replaces blanks in fill-in descriptions, as in H5P.
+	BlankPlaceholder = "__________"
+)
+
+var (
+	ErrUnsupportedQuestion = errors.New("question type has no xAPI interaction definition")
+	ErrNoActivityID        = errors.New("activity id is required")
+	ErrNoSubContentID      = errors.New("question has no subContentId")
+	ErrQuestionNotFound    = errors.New("question not found")
+)
+
+var (
+	blankMarker = regexp.MustCompile(`\*[^*]+\*`)
+	htmlTag     = regexp.MustCompile(`<[^>]*>`)
+)
+
+// Options controls statement generation.
+type Options struct {
+	// ActivityID is the IRI of the question set, usually the URL it is
+	// played at. Questions use ActivityID?subContentId=<id>, as H5P does.
+	ActivityID string
+
+	// ContentID is the local content id sent in the
+	// ExtensionLocalContentID context extension, if set.
+	ContentID string
+
+	// Language is the language map key. It defaults to "en-US".
+	Language string
+
+	// Actor is set on every statement.
+	Actor *Agent
+
+	// SkipUnsupported omits questions other than MultiChoice, TrueFalse,
+	// Blanks, DragText and Essay instead of failing with
+	// ErrUnsupportedQuestion.
+	SkipUnsupported bool
+}
+
+// Generator builds statements for one question set.
+type Generator struct {
+	opts      Options
+	set       Activity
+	category  Activity
+	questions []questionActivity
+}
+
+type questionActivity struct {
+	subContentID string
+	activity     Activity
+	category     Activity
+}
+
+// NewGenerator derives the activities of qs and its questions. Every
+// question needs a subContentId; see h5p.QuestionSet.EnsureSubContentIDs.
+func NewGenerator(qs *h5p.QuestionSet, opts Options) (*Generator, error) {
+	if opts.ActivityID == "" {
+		return nil, ErrNoActivityID
+	}
+	if opts.Language == "" {
+		opts.Language = "en-US"
+	}
+	library, _ := h5p.LatestLibraryString(QuestionSetLibrary)
+	g := &Generator{
+		opts: opts,
+		set: Activity{
+			ObjectType: "Activity",
+			ID:         opts.ActivityID,
+			Definition: &ActivityDefinition{
+				Name:            opts.text(cmp.Or(plainText(qs.Title), QuestionSetLibrary)),
+				Type:            ActivityInteraction,
+				InteractionType: InteractionCompound,
+			},
+		},
+		category: libraryActivity(library),
+	}
+	if intro := plainText(qs.Introduction); intro != "" {
+		g.set.Definition.Description = opts.text(intro)
+	}
+	for i := range qs.Questions {
+		q := &qs.Questions[i]
+		def, err := opts.definition(q)
+		if err == nil && q.SubContentID == "" {
+			err = ErrNoSubContentID
+		}
+		if err != nil {
+			if opts.SkipUnsupported && errors.Is(err, ErrUnsupportedQuestion) {
+				continue
+			}
+			return nil, fmt.Errorf("question %d: %w", i+1, err)
+		}
+		def.Extensions = map[string]any{ExtensionSubContentID: q.SubContentID}
+		g.questions = append(g.questions, questionActivity{
+			subContentID: q.SubContentID,
+			activity: Activity{
+				ObjectType: "Activity",
+				ID:         opts.ActivityID + "?subContentId=" + q.SubContentID,
+				Definition: def,
+			},
+			category: libraryActivity(q.Library),
+		})
+	}
+	return g, nil
+}
+
+// QuestionSet returns the activity of the question set.
+func (g *Generator) QuestionSet() Activity {
+	return g.set
+}
+
+// Questions returns the activities of the supported questions, in order.
+func (g *Generator) Questions() []Activity {
+	activities := make([]Activity, len(g.questions))
+	for i, q := range g.questions {
+		activities[i] = q.activity
+	}
+	return activities
+}
+
+// Question returns the activity of the question with subContentID.
+func (g *Generator) Question(subContentID string) (Activity, bool) {
+	if q := g.question(subContentID); q != nil {
+		return q.activity, true
+	}
+	return Activity{}, false
+}
+
+func (g *Generator) question(subContentID string) *questionActivity {
+	for i := range g.questions {
+		if g.questions[i].subContentID == subContentID {
+			return &g.questions[i]
+		}
+	}
+	return nil
+}
+
+// SetStatement returns a statement about the question set.
+func (g *Generator) SetStatement(verb Verb, result *Result) Statement {
+	return Statement{
+		Actor:   g.opts.Actor,
+		Verb:    verb,
+		Object:  g.set,
+		Result:  result,
+		Context: g.context(nil, g.category),
+	}
+}
+
+// QuestionStatement returns a statement about the question with
+// subContentID, with the question set as its parent.
+func (g *Generator) QuestionStatement(subContentID string, verb Verb, result *Result) (Statement, error) {
+	q := g.question(subContentID)
+	if q == nil {
+		return Statement{}, fmt.Errorf("%w: subContentId %s", ErrQuestionNotFound, subContentID)
+	}
+	return Statement{
+		Actor:   g.opts.Actor,
+		Verb:    verb,
+		Object:  q.activity,
+		Result:  result,
+		Context: g.context(&g.set, q.category),
+	}, nil
+}
+
+// Graded returns the passed or failed statement for a question set score,
+// comparing the scaled score with the pass percentage.
+func (g *Generator) Graded(raw, max float64, passPercentage int) Statement {
+	result := NewResult(raw, max)
+	success := result.Score.Scaled*100 >= float64(passPercentage)
+	result.Success = &success
+	verb := Failed
+	if success {
+		verb = Passed
+	}
+	return g.SetStatement(verb, result)
+}
+
+// Expected returns the statements a full attempt produces, without
+// results: attempted for the set, answered for each question and completed
+// for the set. Passed or failed follows depending on the score; see Graded.
+func (g *Generator) Expected() []Statement {
+	statements := []Statement{g.SetStatement(Attempted, nil)}
+	for _, q := range g.questions {
+		s, _ := g.QuestionStatement(q.subContentID, Answered, nil)
+		statements = append(statements, s)
+	}
+	return append(statements, g.SetStatement(Completed, nil))
+}
+
+func (g *Generator) context(parent *Activity, category Activity) *Context {
+	ctx := &Context{ContextActivities: &ContextActivities{}}
+	if parent != nil {
+		ctx.ContextActivities.Parent = []Activity{{ObjectType: "Activity", ID: parent.ID}}
+	}
+	if category.ID != "" {
+		ctx.ContextActivities.Category = []Activity{category}
+	}
+	if g.opts.ContentID != "" {
+		ctx.Extensions = map[string]any{ExtensionLocalContentID: g.opts.ContentID}
+	}
+	return ctx
+}
+
+// libraryActivity returns the category activity of a library string such
+// as "H5P.MultiChoice 1.16".
+func libraryActivity(library string) Activity {
+	if library == "" {
+		return Activity{}
+	}
+	return Activity{ObjectType: "Activity", ID: libraryActivityBase + strings.Replace(library, " ", "-", 1)}
+}
+
+// definition returns the interaction definition of a question.
+func (opts Options) definition(q *h5p.Question) (*ActivityDefinition, error) {
+	def := &ActivityDefinition{Type: ActivityInteraction}
+	if q.Metadata != nil && q.Metadata.Title != "" {
+		def.Name = opts.text(q.Metadata.Title)
+	}
+	var err error
+	switch q.MachineName() {
+	case MultiChoiceLibrary:
+		var p schemas.MultiChoiceParams
+		if err = q.DecodeParams(&p); err == nil {
+			def.InteractionType = InteractionChoice
+			def.Description = opts.text(plainText(p.Question))
+			var correct []string
+			for i, a := range p.Answers {
+				id := strconv.Itoa(i)
+				def.Choices = append(def.Choices, InteractionComponent{ID: id, Description: opts.text(plainText(a.Text))})
+				if a.Correct {
+					correct = append(correct, id)
+				}
+			}
+			def.CorrectResponsesPattern = []string{strings.Join(correct, ResponseSeparator)}
+		}
+	case TrueFalseLibrary:
+		var p schemas.TrueFalseParams
+		if err = q.DecodeParams(&p); err == nil {
+			def.InteractionType = InteractionTrueFalse
+			def.Description = opts.text(plainText(p.Question))
+			def.CorrectResponsesPattern = []string{strconv.FormatBool(p.IsTrue())}
+		}
+	case BlanksLibrary:
+		var p schemas.BlanksParams
+		if err = q.DecodeParams(&p); err == nil {
+			text := strings.Join(p.Questions, "\n")
+			var answers []string
+			for _, b := range schemas.ParseBlanks(text) {
+				if len(b.Answers) > 0 {
+					answers = append(answers, b.Answers[0])
+				}
+			}
+			caseMatters := p.Behaviour != nil && p.Behaviour.CaseSensitive
+			def.InteractionType = InteractionFillIn
+			def.Description = opts.text(fillInDescription(p.Text, text))
+			def.CorrectResponsesPattern = []string{fillInPattern(answers, caseMatters)}
+		}
+	case DragTextLibrary:
+		var p schemas.DragTextParams
+		if err = q.DecodeParams(&p); err == nil {
+			var answers []string
+			for _, d := range schemas.ParseDraggables(p.TextField) {
+				answers = append(answers, d.Answer)
+			}
+			def.InteractionType = InteractionFillIn
+			def.Description = opts.text(fillInDescription(p.TaskDescription, p.TextField))
+			def.CorrectResponsesPattern = []string{fillInPattern(answers, true)}
+		}
+	case EssayLibrary:
+		var p schemas.EssayParams
+		if err = q.DecodeParams(&p); err == nil {
+			def.InteractionType = InteractionLongFillIn
+			def.Description = opts.text(plainText(p.TaskDescription))
+		}
+	default:
+		err = fmt.Errorf("%w: %s", ErrUnsupportedQuestion, q.Library)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return def, nil
+}
+
+func (opts Options) text(s string) LanguageMap {
+	return LanguageMap{opts.Language: s}
+}
+
+// fillInDescription returns the task and text with each blank replaced by
+// BlankPlaceholder.
+func fillInDescription(task, text string) string {
+	text = plainText(blankMarker.ReplaceAllString(text, BlankPlaceholder))
+	if task = plainText(task); task != "" {
+		return task + "\n" + text
+	}
+	return text
+}
+
+func fillInPattern(answers []string, caseMatters bool) string {
+	prefix := caseMattersFalse
+	if caseMatters {
+		prefix = caseMattersTrue
+	}
+	return prefix + strings.Join(answers, ResponseSeparator)
+}
+
+// plainText strips HTML tags and entities, as statement text is not HTML.
+func plainText(s string) string {
+	return strings.TrimSpace(html.UnescapeString(htmlTag.ReplaceAllString(s, "")))
+}
@@ -0,0 +1,96 @@
+package xapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidStatement = errors.New("invalid xAPI statement")
+	ErrInvalidDuration  = errors.New("invalid ISO 8601 duration")
+)
+
+var durationPattern = regexp.MustCompile(`^P(?:(\d+(?:\.\d+)?)D)?(?:T(?:(\d+(?:\.\d+)?)H)?(?:(\d+(?:\.\d+)?)M)?(?:(\d+(?:\.\d+)?)S)?)?$`)
+
+// ParseStatements parses a statement, an array of statements or an LRS
+// statement result ({"statements": [...]}).
+func ParseStatements(data []byte) ([]Statement, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil, fmt.Errorf("%w: empty input", ErrInvalidStatement)
+	}
+	if data[0] == '[' {
+		var statements []Statement
+		if err := json.Unmarshal(data, &statements); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidStatement, err)
+		}
+		return statements, nil
+	}
+	var wrapper struct {
+		Statements []Statement `json:"statements"`
+		Statement
+	}
+	if err := json.Unmarshal(data, &wrapper); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidStatement, err)
+	}
+	if wrapper.Statements != nil {
+		return wrapper.Statements, nil
+	}
+	if wrapper.Verb.ID == "" {
+		return nil, fmt.Errorf("%w: missing verb", ErrInvalidStatement)
+	}
+	return []Statement{wrapper.Statement}, nil
+}
+
+// SubContentID returns the subContentId of the statement's object, from
+// the H5P extension or the ?subContentId= query of the activity id, or ""
+// for statements about the whole content.
+func (s *Statement) SubContentID() string {
+	if def := s.Object.Definition; def != nil {
+		if id, ok := def.Extensions[ExtensionSubContentID].(string); ok && id != "" {
+			return id
+		}
+	}
+	if _, query, ok := strings.Cut(s.Object.ID, "?"); ok {
+		if values, err := url.ParseQuery(query); err == nil {
+			return values.Get("subContentId")
+		}
+	}
+	return ""
+}
+
+// ParseDuration parses an ISO 8601 duration as sent in result.duration,
+// e.g. "PT1M30.5S". Years, months and weeks are not supported.
+func ParseDuration(s string) (time.Duration, error) {
+	m := durationPattern.FindStringSubmatch(s)
+	if m == nil || s == "P" || strings.HasSuffix(s, "T") {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidDuration, s)
+	}
+	units := []time.Duration{24 * time.Hour, time.Hour, time.Minute, time.Second}
+	var d time.Duration
+	for i, unit := range units {
+		if m[i+1] == "" {
+			continue
+		}
+		f, err := strconv.ParseFloat(m[i+1], 64)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %q", ErrInvalidDuration, s)
+		}
+		d += time.Duration(math.Round(f * float64(unit)))
+	}
+	return d, nil
+}
+
+// FormatDuration formats d as an ISO 8601 duration with hundredths of a
+// second, as H5P does, e.g. "PT90.5S".
+func FormatDuration(d time.Duration) string {
+	return "PT" + strconv.FormatFloat(d.Round(10*time.Millisecond).Seconds(), 'f', -1, 64) + "S"
+}
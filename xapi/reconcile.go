@@ -0,0 +1,206 @@
+package xapi
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	h5p "github.com/grokify/h5p-go"
+	"github.com/grokify/h5p-go/schemas"
+)
+
+// Attempt is a learner's attempt at a question set, rebuilt from the
+// statements its player sent.
+type Attempt struct {
+	Actor *Agent
+
+	// Score, Success and Duration come from the last result reported for
+	// the question set itself.
+	Score    *Score
+	Success  *bool
+	Duration time.Duration
+
+	// Questions holds the last answer to each question, in question set
+	// order. Unanswered questions are left out.
+	Questions []QuestionResult
+
+	// Unmatched holds statements with results for subContentIds that are
+	// not in the question set.
+	Unmatched []Statement
+}
+
+// QuestionResult is the answer to one question.
+type QuestionResult struct {
+	SubContentID string
+
+	// Index is the position of Question in QuestionSet.Questions.
+	Index    int
+	Question *h5p.Question
+
+	// Response is the raw result.response; Responses splits it on "[,]".
+	Response  string
+	Responses []string
+
+	Score     *Score
+	Success   *bool
+	Duration  time.Duration
+	Timestamp time.Time
+
+	// Correct is the grading of Response against the question params, or
+	// nil if the question type is not graded here (e.g. Essay).
+	Correct *bool
+
+	Statement Statement
+}
+
+// Mismatch reports whether the player's success flag disagrees with
+// grading Response against the question params, which points at edited
+// content or a tampered statement.
+func (r *QuestionResult) Mismatch() bool {
+	return r.Success != nil && r.Correct != nil && *r.Success != *r.Correct
+}
+
+// Reconcile matches statements against the question set they came from via
+// subContentId. Only statements carrying a result are used; later
+// statements replace earlier ones for the same question.
+func Reconcile(qs *h5p.QuestionSet, statements []Statement) (*Attempt, error) {
+	index := map[string]int{}
+	for i, q := range qs.Questions {
+		if q.SubContentID != "" {
+			index[q.SubContentID] = i
+		}
+	}
+
+	attempt := &Attempt{}
+	answers := map[int]QuestionResult{}
+	for _, s := range statements {
+		if attempt.Actor == nil {
+			attempt.Actor = s.Actor
+		}
+		if s.Result == nil {
+			continue
+		}
+		duration, timestamp, err := statementTimes(&s)
+		if err != nil {
+			return nil, err
+		}
+		id := s.SubContentID()
+		if id == "" {
+			attempt.Score = s.Result.Score
+			attempt.Success = s.Result.Success
+			attempt.Duration = duration
+			continue
+		}
+		i, ok := index[id]
+		if !ok {
+			attempt.Unmatched = append(attempt.Unmatched, s)
+			continue
+		}
+		r := QuestionResult{
+			SubContentID: id,
+			Index:        i,
+			Question:     &qs.Questions[i],
+			Response:     s.Result.Response,
+			Score:        s.Result.Score,
+			Success:      s.Result.Success,
+			Duration:     duration,
+			Timestamp:    timestamp,
+			Statement:    s,
+		}
+		if r.Response != "" {
+			r.Responses = strings.Split(r.Response, ResponseSeparator)
+		}
+		if r.Correct, err = grade(r.Question, r.Responses); err != nil {
+			return nil, fmt.Errorf("question %d: %w", i+1, err)
+		}
+		answers[i] = r
+	}
+
+	for i := range qs.Questions {
+		if r, ok := answers[i]; ok {
+			attempt.Questions = append(attempt.Questions, r)
+		}
+	}
+	return attempt, nil
+}
+
+func statementTimes(s *Statement) (time.Duration, time.Time, error) {
+	var duration time.Duration
+	var timestamp time.Time
+	var err error
+	if s.Result.Duration != "" {
+		if duration, err = ParseDuration(s.Result.Duration); err != nil {
+			return 0, time.Time{}, err
+		}
+	}
+	if s.Timestamp != "" {
+		if timestamp, err = time.Parse(time.RFC3339Nano, s.Timestamp); err != nil {
+			return 0, time.Time{}, fmt.Errorf("%w: timestamp %q", ErrInvalidStatement, s.Timestamp)
+		}
+	}
+	return duration, timestamp, nil
+}
+
+// grade checks responses against the question params. It returns nil for
+// question types without a single correct response.
+func grade(q *h5p.Question, responses []string) (*bool, error) {
+	var correct bool
+	switch q.MachineName() {
+	case MultiChoiceLibrary:
+		var p schemas.MultiChoiceParams
+		if err := q.DecodeParams(&p); err != nil {
+			return nil, err
+		}
+		var want []string
+		for i, a := range p.Answers {
+			if a.Correct {
+				want = append(want, strconv.Itoa(i))
+			}
+		}
+		got := slices.Clone(responses)
+		slices.Sort(got)
+		correct = slices.Equal(got, want)
+	case TrueFalseLibrary:
+		var p schemas.TrueFalseParams
+		if err := q.DecodeParams(&p); err != nil {
+			return nil, err
+		}
+		correct = len(responses) == 1 && responses[0] == strconv.FormatBool(p.IsTrue())
+	case BlanksLibrary:
+		var p schemas.BlanksParams
+		if err := q.DecodeParams(&p); err != nil {
+			return nil, err
+		}
+		blanks := schemas.ParseBlanks(strings.Join(p.Questions, "\n"))
+		caseSensitive := p.Behaviour != nil && p.Behaviour.CaseSensitive
+		correct = len(responses) == len(blanks)
+		for i := 0; correct && i < len(blanks); i++ {
+			correct = slices.ContainsFunc(blanks[i].Answers, func(a string) bool {
+				return matches(a, responses[i], caseSensitive)
+			})
+		}
+	case DragTextLibrary:
+		var p schemas.DragTextParams
+		if err := q.DecodeParams(&p); err != nil {
+			return nil, err
+		}
+		draggables := schemas.ParseDraggables(p.TextField)
+		correct = len(responses) == len(draggables)
+		for i := 0; correct && i < len(draggables); i++ {
+			correct = matches(draggables[i].Answer, responses[i], true)
+		}
+	default:
+		return nil, nil
+	}
+	return &correct, nil
+}
+
+func matches(answer, response string, caseSensitive bool) bool {
+	answer, response = strings.TrimSpace(answer), strings.TrimSpace(response)
+	if caseSensitive {
+		return answer == response
+	}
+	return strings.EqualFold(answer, response)
+}
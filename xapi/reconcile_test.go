@@ -0,0 +1,150 @@
+package xapi
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+var durationTests = []struct {
+	in   string
+	want time.Duration
+}{
+	{"PT12.5S", 12500 * time.Millisecond},
+	{"PT1M30S", 90 * time.Second},
+	{"PT1H", time.Hour},
+	{"P1DT2H", 26 * time.Hour},
+	{"PT0.01S", 10 * time.Millisecond},
+}
+
+func TestParseDuration(t *testing.T) {
+	for _, tt := range durationTests {
+		got, err := ParseDuration(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseDuration(%q) = %v, %v, want %v", tt.in, got, err, tt.want)
+		}
+	}
+	for _, in := range []string{"", "P", "PT", "12S", "PT1X", "P1Y"} {
+		if _, err := ParseDuration(in); !errors.Is(err, ErrInvalidDuration) {
+			t.Errorf("ParseDuration(%q) error = %v, want ErrInvalidDuration", in, err)
+		}
+	}
+	if got := FormatDuration(90*time.Second + 504*time.Millisecond); got != "PT90.5S" {
+		t.Errorf("FormatDuration = %q", got)
+	}
+}
+
+func TestParseStatements(t *testing.T) {
+	single := `{"verb": {"id": "http://adlnet.gov/expapi/verbs/answered"}, "object": {"id": "https://x/1?subContentId=abc"}}`
+	for _, data := range []string{single, "[" + single + "]", `{"statements": [` + single + `], "more": ""}`} {
+		statements, err := ParseStatements([]byte(data))
+		if err != nil {
+			t.Fatalf("ParseStatements(%s): %v", data, err)
+		}
+		if len(statements) != 1 || statements[0].SubContentID() != "abc" {
+			t.Errorf("ParseStatements(%s) = %+v", data, statements)
+		}
+	}
+	for _, data := range []string{"", "{}", "{"} {
+		if _, err := ParseStatements([]byte(data)); !errors.Is(err, ErrInvalidStatement) {
+			t.Errorf("ParseStatements(%q) error = %v, want ErrInvalidStatement", data, err)
+		}
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	qs := testQuestionSet()
+	g, err := NewGenerator(qs, Options{ActivityID: testActivityID, Actor: &Agent{Name: "Ada"}, SkipUnsupported: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	answer := func(id, response string, success bool, duration string) Statement {
+		result := NewResult(0, 1)
+		if success {
+			result = NewResult(1, 1)
+		}
+		result.Response, result.Duration = response, duration
+		s, err := g.QuestionStatement(id, Answered, result)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	statements := []Statement{
+		g.SetStatement(Attempted, nil),
+		answer("tf", "true", true, "PT3S"),
+		answer("tf", "false", true, "PT4.2S"), // retry replaces the first answer
+		answer("mc", "2[,]0", true, "PT10S"),
+		answer("bl", "Berlin[,]wien", true, ""), // Blanks is case sensitive here
+		answer("dt", "Seine[,]Thames", true, ""),
+		answer("es", "The EU is...", false, ""),
+		g.Graded(4, 5, 50),
+	}
+	stray := answer("mc", "0", false, "")
+	stray.Object.ID = testActivityID + "?subContentId=gone"
+	stray.Object.Definition = nil
+	statements = append(statements, stray)
+
+	// Round trip through JSON as statements arrive from an LRS.
+	data, err := json.Marshal(statements)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := ParseStatements(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	attempt, err := Reconcile(qs, parsed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if attempt.Actor == nil || attempt.Actor.Name != "Ada" {
+		t.Errorf("actor = %+v", attempt.Actor)
+	}
+	if attempt.Score == nil || attempt.Score.Raw != 4 || !*attempt.Success {
+		t.Errorf("set score = %+v, success %v", attempt.Score, attempt.Success)
+	}
+	if len(attempt.Unmatched) != 1 || attempt.Unmatched[0].SubContentID() != "gone" {
+		t.Errorf("unmatched = %+v", attempt.Unmatched)
+	}
+
+	want := []struct {
+		id       string
+		index    int
+		correct  *bool
+		mismatch bool
+	}{
+		{"mc", 0, ptr(true), false},
+		{"tf", 1, ptr(true), false},
+		{"bl", 2, ptr(false), true},
+		{"dt", 3, ptr(true), false},
+		{"es", 4, nil, false},
+	}
+	if len(attempt.Questions) != len(want) {
+		t.Fatalf("Expected %d question results, got %d", len(want), len(attempt.Questions))
+	}
+	for i, w := range want {
+		r := attempt.Questions[i]
+		if r.SubContentID != w.id || r.Index != w.index || r.Question != &qs.Questions[w.index] {
+			t.Errorf("result %d = %s at %d", i, r.SubContentID, r.Index)
+		}
+		if (r.Correct == nil) != (w.correct == nil) || (r.Correct != nil && *r.Correct != *w.correct) {
+			t.Errorf("%s: correct = %v, want %v", w.id, r.Correct, w.correct)
+		}
+		if r.Mismatch() != w.mismatch {
+			t.Errorf("%s: mismatch = %v", w.id, r.Mismatch())
+		}
+	}
+	if tf := attempt.Questions[1]; tf.Response != "false" || tf.Duration != 4200*time.Millisecond {
+		t.Errorf("tf = %q in %v", tf.Response, tf.Duration)
+	}
+
+	bad := answer("tf", "true", true, "3 seconds")
+	if _, err := Reconcile(qs, []Statement{bad}); !errors.Is(err, ErrInvalidDuration) {
+		t.Errorf("Expected ErrInvalidDuration, got %v", err)
+	}
+}
+
+func ptr(b bool) *bool { return &b }
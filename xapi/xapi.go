@@ -0,0 +1,147 @@
+// Package xapi builds the xAPI (Experience API) statements an H5P player
+// sends for question sets and their questions: attempted, answered,
+// completed, passed and failed, with interaction definitions derived from
+// the typed question params so a backend can pre-register them. Statements
+// received back from players are parsed and matched against the question set
+// with Reconcile.
+package xapi
+
+// Activity types and H5P extension IRIs.
+const (
+	ActivityInteraction     = "http://adlnet.gov/expapi/activities/cmi.interaction"
+	ExtensionSubContentID   = "http://h5p.org/x-api/h5p-subContentId"
+	ExtensionLocalContentID = "http://h5p.org/x-api/h5p-local-content-id"
+	libraryActivityBase     = "http://h5p.org/libraries/"
+)
+
+// Interaction types used for H5P questions.
+const (
+	InteractionChoice     = "choice"
+	InteractionTrueFalse  = "true-false"
+	InteractionFillIn     = "fill-in"
+	InteractionLongFillIn = "long-fill-in"
+	InteractionCompound   = "compound"
+	ResponseSeparator     = "[,]"
+	caseMattersFalse      = "{case_matters=false}"
+	caseMattersTrue       = "{case_matters=true}"
+	verbBase              = "http://adlnet.gov/expapi/verbs/"
+)
+
+// Verbs sent by H5P content.
+var (
+	Attempted = newVerb("attempted")
+	Answered  = newVerb("answered")
+	Completed = newVerb("completed")
+	Passed    = newVerb("passed")
+	Failed    = newVerb("failed")
+)
+
+// LanguageMap maps language tags such as "en-US" to text.
+type LanguageMap map[string]string
+
+// Statement is an xAPI statement.
+type Statement struct {
+	ID        string   `json:"id,omitempty"`
+	Actor     *Agent   `json:"actor,omitempty"`
+	Verb      Verb     `json:"verb"`
+	Object    Activity `json:"object"`
+	Result    *Result  `json:"result,omitempty"`
+	Context   *Context `json:"context,omitempty"`
+	Timestamp string   `json:"timestamp,omitempty"`
+}
+
+// Agent identifies the learner. One of Mbox, MboxSHA1Sum, OpenID or
+// Account should be set.
+type Agent struct {
+	ObjectType  string   `json:"objectType,omitempty"`
+	Name        string   `json:"name,omitempty"`
+	Mbox        string   `json:"mbox,omitempty"`
+	MboxSHA1Sum string   `json:"mbox_sha1sum,omitempty"`
+	OpenID      string   `json:"openid,omitempty"`
+	Account     *Account `json:"account,omitempty"`
+}
+
+// Account is an agent account on a system such as an LMS.
+type Account struct {
+	HomePage string `json:"homePage"`
+	Name     string `json:"name"`
+}
+
+// Verb is the action of a statement.
+type Verb struct {
+	ID      string      `json:"id"`
+	Display LanguageMap `json:"display,omitempty"`
+}
+
+func newVerb(name string) Verb {
+	return Verb{ID: verbBase + name, Display: LanguageMap{"en-US": name}}
+}
+
+// Activity is the object of a statement.
+type Activity struct {
+	ObjectType string              `json:"objectType,omitempty"`
+	ID         string              `json:"id"`
+	Definition *ActivityDefinition `json:"definition,omitempty"`
+}
+
+// ActivityDefinition describes an activity, including the interaction
+// details of a question.
+type ActivityDefinition struct {
+	Name                    LanguageMap            `json:"name,omitempty"`
+	Description             LanguageMap            `json:"description,omitempty"`
+	Type                    string                 `json:"type,omitempty"`
+	InteractionType         string                 `json:"interactionType,omitempty"`
+	CorrectResponsesPattern []string               `json:"correctResponsesPattern,omitempty"`
+	Choices                 []InteractionComponent `json:"choices,omitempty"`
+	Extensions              map[string]any         `json:"extensions,omitempty"`
+}
+
+// InteractionComponent is a choice of a choice interaction.
+type InteractionComponent struct {
+	ID          string      `json:"id"`
+	Description LanguageMap `json:"description,omitempty"`
+}
+
+// Result is the outcome of a statement.
+type Result struct {
+	Score      *Score         `json:"score,omitempty"`
+	Success    *bool          `json:"success,omitempty"`
+	Completion *bool          `json:"completion,omitempty"`
+	Response   string         `json:"response,omitempty"`
+	Duration   string         `json:"duration,omitempty"`
+	Extensions map[string]any `json:"extensions,omitempty"`
+}
+
+// Score is a result score. Scaled is Raw/Max.
+type Score struct {
+	Scaled float64 `json:"scaled"`
+	Raw    float64 `json:"raw"`
+	Min    float64 `json:"min"`
+	Max    float64 `json:"max"`
+}
+
+// NewResult returns a completed result scoring raw out of max. Success is
+// true for full marks, as H5P questions report it.
+func NewResult(raw, max float64) *Result {
+	score := &Score{Raw: raw, Max: max}
+	if max > 0 {
+		score.Scaled = raw / max
+	}
+	success, completion := raw >= max, true
+	return &Result{Score: score, Success: &success, Completion: &completion}
+}
+
+// Context holds the activities a statement belongs to.
+type Context struct {
+	ContextActivities *ContextActivities `json:"contextActivities,omitempty"`
+	Extensions        map[string]any     `json:"extensions,omitempty"`
+}
+
+// ContextActivities relates a statement to parent and category activities.
+// H5P uses the category for the library, e.g.
+// http://h5p.org/libraries/H5P.MultiChoice-1.16.
+type ContextActivities struct {
+	Parent   []Activity `json:"parent,omitempty"`
+	Grouping []Activity `json:"grouping,omitempty"`
+	Category []Activity `json:"category,omitempty"`
+}
@@ -0,0 +1,194 @@
+package xapi
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	h5p "github.com/grokify/h5p-go"
+	"github.com/grokify/h5p-go/schemas"
+)
+
+const testActivityID = "https://lms.example.com/h5p/42"
+
+func testQuestionSet() *h5p.QuestionSet {
+	return &h5p.QuestionSet{
+		Title:          "Geography",
+		PassPercentage: 50,
+		Questions: []h5p.Question{
+			{
+				Library:      "H5P.MultiChoice 1.16",
+				SubContentID: "mc",
+				Metadata:     &h5p.ContentMetadata{Title: "Capitals"},
+				Params: &schemas.MultiChoiceParams{
+					Question: "<p>Capitals of France and Italy?</p>",
+					Answers: []schemas.AnswerOption{
+						{Text: "Paris", Correct: true},
+						{Text: "Lyon &amp; <b>Nice</b>"},
+						{Text: "Rome", Correct: true},
+					},
+				},
+			},
+			{
+				Library:      "H5P.TrueFalse 1.8",
+				SubContentID: "tf",
+				Params:       map[string]any{"question": "Rome is in Spain.", "correct": "false"},
+			},
+			{
+				Library:      "H5P.Blanks 1.14",
+				SubContentID: "bl",
+				Params: &schemas.BlanksParams{
+					Questions: []string{"<p>Germany: *Berlin*, Austria: *Vienna/Wien*</p>"},
+					Behaviour: &schemas.BlanksBehaviour{CaseSensitive: true},
+				},
+			},
+			{
+				Library:      "H5P.DragText 1.10",
+				SubContentID: "dt",
+				Params:       &schemas.DragTextParams{TaskDescription: "Drag the rivers.", TextField: "Paris: *Seine*\nLondon: *Thames:UK*"},
+			},
+			{
+				Library:      "H5P.Essay 1.5",
+				SubContentID: "es",
+				Params:       &schemas.EssayParams{TaskDescription: "<p>Describe the EU.</p>"},
+			},
+			{Library: "H5P.ImageHotspotQuestion 1.8", SubContentID: "hs", Params: map[string]any{}},
+		},
+	}
+}
+
+func TestNewGenerator(t *testing.T) {
+	if _, err := NewGenerator(testQuestionSet(), Options{}); !errors.Is(err, ErrNoActivityID) {
+		t.Errorf("Expected ErrNoActivityID, got %v", err)
+	}
+	if _, err := NewGenerator(testQuestionSet(), Options{ActivityID: testActivityID}); !errors.Is(err, ErrUnsupportedQuestion) {
+		t.Errorf("Expected ErrUnsupportedQuestion, got %v", err)
+	}
+	qs := testQuestionSet()
+	qs.Questions[0].SubContentID = ""
+	if _, err := NewGenerator(qs, Options{ActivityID: testActivityID, SkipUnsupported: true}); !errors.Is(err, ErrNoSubContentID) {
+		t.Errorf("Expected ErrNoSubContentID, got %v", err)
+	}
+}
+
+var definitionTests = []struct {
+	subContentID    string
+	interactionType string
+	description     string
+	pattern         string
+	choices         int
+}{
+	{"mc", InteractionChoice, "Capitals of France and Italy?", "0[,]2", 3},
+	{"tf", InteractionTrueFalse, "Rome is in Spain.", "false", 0},
+	{"bl", InteractionFillIn, "Germany: __________, Austria: __________", "{case_matters=true}Berlin[,]Vienna", 0},
+	{"dt", InteractionFillIn, "Drag the rivers.\nParis: __________\nLondon: __________", "{case_matters=true}Seine[,]Thames", 0},
+	{"es", InteractionLongFillIn, "Describe the EU.", "", 0},
+}
+
+func TestQuestionDefinitions(t *testing.T) {
+	g, err := NewGenerator(testQuestionSet(), Options{ActivityID: testActivityID, SkipUnsupported: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(g.Questions()); n != len(definitionTests) {
+		t.Fatalf("Expected %d questions, got %d", len(definitionTests), n)
+	}
+	for _, tt := range definitionTests {
+		a, ok := g.Question(tt.subContentID)
+		if !ok {
+			t.Errorf("%s: question not found", tt.subContentID)
+			continue
+		}
+		def := a.Definition
+		if a.ID != testActivityID+"?subContentId="+tt.subContentID {
+			t.Errorf("%s: id = %q", tt.subContentID, a.ID)
+		}
+		if def.InteractionType != tt.interactionType {
+			t.Errorf("%s: interactionType = %q, want %q", tt.subContentID, def.InteractionType, tt.interactionType)
+		}
+		if got := def.Description["en-US"]; got != tt.description {
+			t.Errorf("%s: description = %q, want %q", tt.subContentID, got, tt.description)
+		}
+		var pattern string
+		if len(def.CorrectResponsesPattern) > 0 {
+			pattern = def.CorrectResponsesPattern[0]
+		}
+		if pattern != tt.pattern {
+			t.Errorf("%s: correctResponsesPattern = %q, want %q", tt.subContentID, pattern, tt.pattern)
+		}
+		if len(def.Choices) != tt.choices {
+			t.Errorf("%s: %d choices, want %d", tt.subContentID, len(def.Choices), tt.choices)
+		}
+		if def.Extensions[ExtensionSubContentID] != tt.subContentID {
+			t.Errorf("%s: extensions = %v", tt.subContentID, def.Extensions)
+		}
+	}
+
+	mc, _ := g.Question("mc")
+	if got := mc.Definition.Choices[1].Description["en-US"]; got != "Lyon & Nice" {
+		t.Errorf("choice description = %q", got)
+	}
+	if got := mc.Definition.Name["en-US"]; got != "Capitals" {
+		t.Errorf("name = %q", got)
+	}
+}
+
+func TestStatements(t *testing.T) {
+	actor := &Agent{Name: "Ada", Mbox: "mailto:ada@example.com"}
+	g, err := NewGenerator(testQuestionSet(), Options{ActivityID: testActivityID, ContentID: "42", Actor: actor, SkipUnsupported: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := g.Expected()
+	var verbs []string
+	for _, s := range expected {
+		verbs = append(verbs, s.Verb.Display["en-US"])
+		if s.Actor != actor {
+			t.Errorf("%s: actor not set", s.Verb.ID)
+		}
+	}
+	want := []string{"attempted", "answered", "answered", "answered", "answered", "answered", "completed"}
+	if !reflect.DeepEqual(verbs, want) {
+		t.Errorf("Expected verbs %v, got %v", want, verbs)
+	}
+
+	s, err := g.QuestionStatement("tf", Answered, NewResult(1, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := s.Context.ContextActivities
+	if ctx.Parent[0].ID != testActivityID || ctx.Category[0].ID != "http://h5p.org/libraries/H5P.TrueFalse-1.8" {
+		t.Errorf("context activities = %+v", ctx)
+	}
+	if s.Context.Extensions[ExtensionLocalContentID] != "42" {
+		t.Errorf("context extensions = %v", s.Context.Extensions)
+	}
+	if !*s.Result.Success || s.Result.Score.Scaled != 1 {
+		t.Errorf("result = %+v", s.Result)
+	}
+	if _, err := g.QuestionStatement("hs", Answered, nil); !errors.Is(err, ErrQuestionNotFound) {
+		t.Errorf("Expected ErrQuestionNotFound for a skipped question, got %v", err)
+	}
+
+	if s := g.Graded(2, 5, 50); s.Verb.ID != Failed.ID || *s.Result.Success {
+		t.Errorf("Graded(2, 5) = %s, success %v", s.Verb.ID, *s.Result.Success)
+	}
+	s = g.Graded(3, 5, 50)
+	if s.Verb.ID != "http://adlnet.gov/expapi/verbs/passed" {
+		t.Errorf("Graded(3, 5) = %s", s.Verb.ID)
+	}
+
+	data, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Statement
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Object.Definition.InteractionType != InteractionCompound || decoded.Result.Score.Raw != 3 {
+		t.Errorf("decoded statement = %+v", decoded)
+	}
+}
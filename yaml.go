@@ -0,0 +1,59 @@
+package h5p
+
+import (
+	"gopkg.in/yaml.v3"
+
+	"github.com/grokify/h5p-go/internal/yamljson"
+)
+
+// YAML documents use the JSON field names and rules: a QuestionSet written
+// with ToYAML and read back with FromYAML matches the ToJSON/FromJSON round
+// trip, and yaml.Marshal/yaml.Unmarshal give the same result.
+
+// ToYAML returns the question set as YAML.
+func (qs *QuestionSet) ToYAML() ([]byte, error) {
+	return yamljson.Marshal(qs)
+}
+
+// FromYAML parses a question set from YAML.
+func FromYAML(data []byte) (*QuestionSet, error) {
+	var qs QuestionSet
+	if err := yamljson.Unmarshal(data, &qs); err != nil {
+		return nil, err
+	}
+	return &qs, nil
+}
+
+// MarshalYAML implements yaml.Marshaler.
+func (qs QuestionSet) MarshalYAML() (any, error) {
+	return yamljson.Node(qs)
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (qs *QuestionSet) UnmarshalYAML(n *yaml.Node) error {
+	return yamljson.DecodeNode(n, qs)
+}
+
+// ToYAML returns the package definition (h5p.json) as YAML.
+func (def *PackageDefinition) ToYAML() ([]byte, error) {
+	return yamljson.Marshal(def)
+}
+
+// PackageDefinitionFromYAML parses a package definition from YAML.
+func PackageDefinitionFromYAML(data []byte) (*PackageDefinition, error) {
+	var def PackageDefinition
+	if err := yamljson.Unmarshal(data, &def); err != nil {
+		return nil, err
+	}
+	return &def, nil
+}
+
+// MarshalYAML implements yaml.Marshaler.
+func (def PackageDefinition) MarshalYAML() (any, error) {
+	return yamljson.Node(def)
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (def *PackageDefinition) UnmarshalYAML(n *yaml.Node) error {
+	return yamljson.DecodeNode(n, def)
+}
@@ -0,0 +1,132 @@
+package h5p
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/grokify/h5p-go/schemas"
+)
+
+func TestQuestionSetYAMLRoundTrip(t *testing.T) {
+	qs, err := NewQuestionSetBuilder().
+		SetTitle("YAML Quiz").
+		SetPassPercentage(60).
+		AddMultipleChoiceQuestion("<p>Pick one:\nwhich?</p>", []Answer{
+			CreateAnswer("yes", true),
+			CreateAnswer("2024-01-01", false),
+		}).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := qs.ToYAML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "passPercentage: 60") {
+		t.Errorf("YAML does not use JSON field names:\n%s", data)
+	}
+
+	got, err := FromYAML(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Params decode generically either way, so compare with the JSON trip.
+	jsonData, err := qs.ToJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	viaJSON, err := FromJSON(jsonData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := viaJSON.ToJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotJSON, err := got.ToJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(gotJSON) != string(want) {
+		t.Errorf("YAML round trip changed the question set:\ngot  %s\nwant %s", gotJSON, want)
+	}
+
+	// yaml.Marshal goes through the same path.
+	direct, err := yaml.Marshal(qs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var viaYAML QuestionSet
+	if err := yaml.Unmarshal(direct, &viaYAML); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(&viaYAML, got) {
+		t.Errorf("yaml.Unmarshal = %+v, want %+v", viaYAML, got)
+	}
+}
+
+func TestFromYAMLAuthoring(t *testing.T) {
+	src := `
+title: Capitals
+passPercentage: 50
+questions:
+  - library: H5P.MultiChoice 1.16
+    params:
+      question: Capital of France?
+      answers:
+        - text: Paris
+          correct: true
+        - text: Lyon
+          correct: false
+`
+	qs, err := FromYAML([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if qs.Title != "Capitals" || qs.PassPercentage != 50 || len(qs.Questions) != 1 {
+		t.Fatalf("FromYAML = %+v", qs)
+	}
+	var params schemas.MultiChoiceParams
+	if err := qs.Questions[0].DecodeParams(&params); err != nil {
+		t.Fatal(err)
+	}
+	if len(params.Answers) != 2 || !params.Answers[0].Correct {
+		t.Errorf("answers = %+v", params.Answers)
+	}
+
+	if _, err := FromYAML([]byte("title: [unclosed")); err == nil {
+		t.Error("FromYAML accepted invalid YAML")
+	}
+}
+
+func TestPackageDefinitionYAML(t *testing.T) {
+	def := &PackageDefinition{
+		Title:       "Quiz",
+		Language:    "en",
+		MainLibrary: "H5P.QuestionSet",
+		EmbedTypes:  []string{"iframe"},
+		YearFrom:    2024,
+		PreloadedDependencies: []LibraryDependency{
+			{MachineName: "H5P.QuestionSet", MajorVersion: 1, MinorVersion: 20},
+		},
+	}
+	data, err := def.ToYAML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "license") {
+		t.Errorf("omitempty fields written to YAML:\n%s", data)
+	}
+	got, err := PackageDefinitionFromYAML(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, def) {
+		t.Errorf("PackageDefinitionFromYAML = %+v, want %+v", got, def)
+	}
+}